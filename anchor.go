@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/lint"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// splitAnchor splits the heading anchor off an argument like
+// README.md#installation or a URL with a fragment. Arguments naming an
+// existing file are left alone, even if they contain a #.
+func splitAnchor(arg string) (string, string) {
+	i := strings.LastIndex(arg, "#")
+	if i < 0 {
+		return arg, ""
+	}
+	if _, err := os.Stat(arg); err == nil {
+		return arg, ""
+	}
+	return arg[:i], arg[i+1:]
+}
+
+// sectionFrom returns a document from the heading with the given anchor on.
+// Anchors are matched like GitHub generates them, so #installation finds the
+// "Installation" heading, and #usage-1 the second "Usage" heading.
+func sectionFrom(markdown, anchor string) (string, error) {
+	want := lint.Anchor(anchor)
+	src := []byte(markdown)
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(src))
+
+	anchors := lint.Anchors{}
+	start := -1
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if anchors.Next(string(h.Text(src))) == want && h.Lines().Len() > 0 { //nolint:staticcheck
+			start = bytes.LastIndexByte(src[:h.Lines().At(0).Start], '\n') + 1
+			return ast.WalkStop, nil
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	if start < 0 {
+		return "", fmt.Errorf("no heading for anchor #%s", anchor)
+	}
+	return markdown[start:], nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitAnchor(t *testing.T) {
+	dir := t.TempDir()
+	odd := filepath.Join(dir, "c#.md")
+	if err := os.WriteFile(odd, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		arg, path, anchor string
+	}{
+		{"README.md", "README.md", ""},
+		{"README.md#installation", "README.md", "installation"},
+		{"https://example.com/docs.md#usage", "https://example.com/docs.md", "usage"},
+		{"HEAD~1:README.md#usage", "HEAD~1:README.md", "usage"},
+		{odd, odd, ""},
+		{odd + "#usage", odd, "usage"},
+	} {
+		path, anchor := splitAnchor(tc.arg)
+		if path != tc.path || anchor != tc.anchor {
+			t.Errorf("%s: expected %q and %q, got %q and %q", tc.arg, tc.path, tc.anchor, path, anchor)
+		}
+	}
+}
+
+func TestSectionFrom(t *testing.T) {
+	doc := "# Glow\n\nIntro\n\n```sh\n# Usage\n```\n\n## Usage\n\nOne\n\nUsage\n-----\n\nTwo\n\n## Build `go` (1.21+)\n\nThree\n"
+
+	for _, tc := range []struct {
+		anchor, want string
+	}{
+		{"glow", doc},
+		{"usage", "## Usage\n\nOne\n\nUsage\n-----\n\nTwo\n\n## Build `go` (1.21+)\n\nThree\n"},
+		{"usage-1", "Usage\n-----\n\nTwo\n\n## Build `go` (1.21+)\n\nThree\n"},
+		{"Build-Go-121", "## Build `go` (1.21+)\n\nThree\n"},
+	} {
+		got, err := sectionFrom(doc, tc.anchor)
+		if err != nil {
+			t.Errorf("%s: %v", tc.anchor, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.anchor, tc.want, got)
+		}
+	}
+
+	if _, err := sectionFrom(doc, "usage-2"); err == nil {
+		t.Error("expected an error for a missing anchor")
+	}
+}
@@ -0,0 +1,203 @@
+// Package annotations keeps notes attached to lines of documents, such as
+// review comments on a design document.
+package annotations
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the annotations file in the data directory.
+const FileName = "annotations.json"
+
+// Annotation is a note attached to a range of lines of a rendered document.
+type Annotation struct {
+	// First and last line annotated, counted from 0, in a rendering of the
+	// document with Lines lines.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	Lines int `json:"lines"`
+	// Plain text of the annotated lines, used to find them again in a
+	// rendering at another width, and shown when listing annotations.
+	Text    string    `json:"text"`
+	Note    string    `json:"note"`
+	Created time.Time `json:"created"`
+}
+
+// Locate returns the first and last line the annotation is on in a
+// rendering of the document, given the plain text of its lines. The lines
+// are found by their text, closest to where they were, or where they'd be
+// if the text can't be found.
+func (a Annotation) Locate(lines []string) (start, end int) {
+	if len(lines) == 0 {
+		return 0, 0
+	}
+	guess := a.Start
+	if a.Lines > 0 && a.Lines != len(lines) {
+		guess = a.Start * len(lines) / a.Lines
+	}
+
+	start = -1
+	if first := a.firstLine(); first != "" {
+		for i, l := range lines {
+			if strings.TrimSpace(l) != first {
+				continue
+			}
+			if start < 0 || abs(i-guess) < abs(start-guess) {
+				start = i
+			}
+		}
+	}
+	if start < 0 {
+		start = guess
+	}
+	start = min(max(start, 0), len(lines)-1)
+	end = min(start+max(a.End-a.Start, 0), len(lines)-1)
+	return start, end
+}
+
+// firstLine returns the first line of the annotated text that isn't blank.
+func (a Annotation) firstLine() string {
+	for _, l := range strings.Split(a.Text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// Title returns the first line of the annotated text, shortened to fit in
+// listings.
+func (a Annotation) Title() string {
+	const maxTitle = 60
+	title := a.firstLine()
+	if r := []rune(title); len(r) > maxTitle {
+		title = string(r[:maxTitle-1]) + "…"
+	}
+	return title
+}
+
+func abs(n int) int {
+	return max(n, -n)
+}
+
+// document holds the annotations of a version of a document.
+type document struct {
+	// Path the document was last annotated at.
+	Path        string       `json:"path"`
+	Annotations []Annotation `json:"annotations"`
+}
+
+// Store is a set of annotations persisted to a JSON file, keyed by a hash
+// of the content of the documents, so that notes stay attached to the text
+// they were written about. It's safe for concurrent use.
+type Store struct {
+	path string
+
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+// Load reads the annotations stored at path. A missing file is treated as an
+// empty set.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, docs: map[string]*document{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read annotations: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.docs); err != nil {
+		return nil, fmt.Errorf("unable to parse annotations: %w", err)
+	}
+	return s, nil
+}
+
+// Hash returns the key annotations of a document with the given content are
+// stored under.
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16])
+}
+
+// List returns the annotations of a document, in the order of the lines
+// they're on.
+func (s *Store) List(content []byte) []Annotation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.docs[Hash(content)]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(d.Annotations)
+}
+
+// Add attaches an annotation to the document at path and saves the store.
+func (s *Store) Add(path string, content []byte, a Annotation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := Hash(content)
+	d, ok := s.docs[k]
+	if !ok {
+		d = &document{}
+		s.docs[k] = d
+	}
+	if abs, err := filepath.Abs(path); err == nil && path != "" {
+		path = abs
+	}
+	d.Path = path
+	if a.Created.IsZero() {
+		a.Created = time.Now()
+	}
+	d.Annotations = append(d.Annotations, a)
+	slices.SortStableFunc(d.Annotations, func(a, b Annotation) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return s.save()
+}
+
+// Remove removes the i-th annotation of a document, as listed by List, and
+// saves the store.
+func (s *Store) Remove(content []byte, i int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := Hash(content)
+	d, ok := s.docs[k]
+	if !ok || i < 0 || i >= len(d.Annotations) {
+		return fmt.Errorf("no annotation #%d", i+1)
+	}
+	d.Annotations = slices.Delete(d.Annotations, i, i+1)
+	if len(d.Annotations) == 0 {
+		delete(s.docs, k)
+	}
+	return s.save()
+}
+
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.docs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode annotations: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, b, 0o600); err != nil {
+		return fmt.Errorf("unable to write annotations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package annotations
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", FileName)
+	doc := []byte("# Design\n\nSome text.\n")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("/docs/design.md", doc, Annotation{Start: 4, End: 5, Text: "Some text.", Note: "Why?"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("/docs/design.md", doc, Annotation{Start: 1, End: 1, Text: "Design", Note: "Rename"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := s.List(doc)
+	if len(list) != 2 || list[0].Note != "Rename" || list[1].Note != "Why?" {
+		t.Fatalf("expected both annotations in line order, got %+v", list)
+	}
+	if list[0].Created.IsZero() {
+		t.Error("expected the time the annotation was created")
+	}
+	if got := s.List([]byte("# Design\n\nOther text.\n")); len(got) != 0 {
+		t.Errorf("expected no annotations of another version, got %+v", got)
+	}
+
+	if err := s.Remove(doc, 0); err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(doc); len(list) != 1 || list[0].Note != "Why?" {
+		t.Errorf("expected the first annotation to be removed, got %+v", list)
+	}
+	if err := s.Remove(doc, 3); err == nil {
+		t.Error("expected an error for a missing annotation")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	a := Annotation{Start: 4, End: 5, Lines: 8, Text: "  Some text\n  that wraps"}
+	for _, tt := range []struct {
+		name       string
+		lines      []string
+		start, end int
+	}{
+		{"same rendering", []string{"", "# Title", "", "", "Some text", "that wraps", "", ""}, 4, 5},
+		{"moved", []string{"", "# Title", "", "", "", "", "Some text", "that wraps"}, 6, 7},
+		{"closest", []string{"Some text", "", "", "", "", "Some text", "", "", "", "", "", "Some text"}, 5, 6},
+		{"scaled", []string{"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""}, 8, 9},
+		{"clamped", []string{"", "Some text"}, 1, 1},
+	} {
+		start, end := a.Locate(tt.lines)
+		if start != tt.start || end != tt.end {
+			t.Errorf("%s: expected lines %d-%d, got %d-%d", tt.name, tt.start, tt.end, start, end)
+		}
+	}
+}
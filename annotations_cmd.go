@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/douglas-larocca/glow/v2/annotations"
+	"github.com/spf13/cobra"
+)
+
+var annotationsCmd = &cobra.Command{
+	Use:   "annotations FILE",
+	Short: "List the annotations of a document",
+	Long: paragraph(fmt.Sprintf("\n%s the notes attached to lines of a document with %s in the TUI. Notes belong to a version of the document, so they're not listed once it's changed.",
+		keyword("List"), keyword("a"))),
+	Example: paragraph("glow annotations README.md"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("unable to read file: %w", err)
+		}
+		s, err := annotations.Load(dataPath(annotations.FileName))
+		if err != nil {
+			return err
+		}
+		list := s.List(content)
+		if len(list) == 0 {
+			fmt.Println("No annotations yet. Press a on the document in the TUI to add one.")
+			return nil
+		}
+		for i, a := range list {
+			lines := fmt.Sprintf("line %d", a.Start+1)
+			if a.End > a.Start {
+				lines = fmt.Sprintf("lines %d-%d", a.Start+1, a.End+1)
+			}
+			fmt.Printf("%3d  %s (%s)\n     %s\n", i+1, a.Title(), lines, a.Note)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// backgroundQueryTimeout is how long we wait for the terminal to answer the
+// background color query.
+const backgroundQueryTimeout = 500 * time.Millisecond
+
+// rgb is a terminal color with 16-bit channels, as reported by OSC 10/11.
+type rgb struct {
+	r, g, b uint16
+}
+
+// isDark reports whether the color is dark, based on its relative luminance.
+func (c rgb) isDark() bool {
+	l := 0.2126*float64(c.r) + 0.7152*float64(c.g) + 0.0722*float64(c.b)
+	return l < 0.5*0xffff
+}
+
+// detectBackground asks the terminal for its background color and tells
+// lipgloss whether it's dark, so the auto style picks the matching glamour
+// style. If the terminal doesn't answer, lipgloss keeps its own guess, which
+// is based on $COLORFGBG.
+func detectBackground() {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+	defer tty.Close() //nolint:errcheck
+
+	bg, err := getTerminalBackground(tty)
+	if err != nil {
+		return
+	}
+	lipgloss.SetHasDarkBackground(bg.isDark())
+}
+
+// getTerminalBackground gets the terminal's background color using an OSC 11
+// query. The query is followed by a device attributes request, which every
+// terminal answers, so we don't hang on terminals that ignore OSC 11.
+func getTerminalBackground(file *os.File) (rgb, error) {
+	// OSC 11 ; ? ST, then CSI c
+	return queryTerminal(file, "\x1b]11;?\x1b\\\x1b[c", backgroundQueryTimeout, readBackgroundResponse)
+}
+
+// readBackgroundResponse reads terminal input until the device attributes
+// response, ESC [ ? ... c, and parses the background color answered before.
+func readBackgroundResponse(r io.Reader) (rgb, error) {
+	var (
+		resp []byte
+		buf  [64]byte
+	)
+	for !isDeviceAttributesResponse(resp) {
+		n, err := r.Read(buf[:])
+		if err != nil {
+			return rgb{}, err
+		}
+		resp = append(resp, buf[:n]...)
+	}
+
+	return parseBackgroundResponse(string(resp))
+}
+
+// isDeviceAttributesResponse reports whether b ends with a primary device
+// attributes response.
+func isDeviceAttributesResponse(b []byte) bool {
+	i := bytes.LastIndex(b, []byte("\x1b[?"))
+	return i >= 0 && bytes.HasSuffix(b[i:], []byte("c"))
+}
+
+// parseBackgroundResponse extracts the color from an OSC 11 response:
+// ESC ] 11 ; rgb:RRRR/GGGG/BBBB, terminated by BEL or ST.
+func parseBackgroundResponse(resp string) (rgb, error) {
+	_, color, ok := strings.Cut(resp, "\x1b]11;rgb:")
+	if !ok {
+		return rgb{}, fmt.Errorf("invalid terminal response: %q", resp)
+	}
+	if i := strings.IndexAny(color, "\x07\x1b"); i >= 0 {
+		color = color[:i]
+	}
+
+	parts := strings.Split(color, "/")
+	if len(parts) != 3 {
+		return rgb{}, fmt.Errorf("invalid terminal response format: %q", color)
+	}
+
+	var channels [3]uint16
+	for i, p := range parts {
+		// Channels have 1 to 4 hex digits; scale them to 16 bits.
+		if p == "" || len(p) > 4 {
+			return rgb{}, fmt.Errorf("invalid terminal response format: %q", color)
+		}
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return rgb{}, err
+		}
+		maxValue := uint64(1)<<(4*len(p)) - 1
+		channels[i] = uint16(v * 0xffff / maxValue) //nolint:gosec
+	}
+
+	return rgb{channels[0], channels[1], channels[2]}, nil
+}
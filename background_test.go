@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestParseBackgroundResponse(t *testing.T) {
+	tt := []struct {
+		resp string
+		dark bool
+	}{
+		{"\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?62;22c", true},
+		{"\x1b]11;rgb:ffff/ffff/ffff\x07\x1b[?1;2c", false},
+		{"\x1b]11;rgb:fd/f6/e3\x1b\\", false},
+		{"\x1b]11;rgb:2828/2c2c/3434\x1b\\", true},
+	}
+	for _, tc := range tt {
+		c, err := parseBackgroundResponse(tc.resp)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.resp, err)
+		}
+		if c.isDark() != tc.dark {
+			t.Errorf("%q: expected dark=%v, got %v", tc.resp, tc.dark, c.isDark())
+		}
+	}
+
+	for _, resp := range []string{"\x1b[?62;22c", "\x1b]11;rgb:00/00\x07", "\x1b]11;rgb:zz/00/00\x07"} {
+		if _, err := parseBackgroundResponse(resp); err == nil {
+			t.Errorf("%q: expected an error", resp)
+		}
+	}
+
+	if !isDeviceAttributesResponse([]byte("\x1b]11;rgb:0/0/0\x07\x1b[?62;22c")) {
+		t.Error("expected device attributes response to be detected")
+	}
+	if isDeviceAttributesResponse([]byte("\x1b]11;rgb:0/0/0\x07")) {
+		t.Error("expected partial response not to be detected")
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// batchFile is the list of documents given with --batch, or - for stdin.
+	batchFile string
+	// jobs is how many documents --batch and --recursive render at once, or
+	// 0 for as many as there are CPUs.
+	jobs int
+)
+
+// readBatch reads a list of documents, one path or URL per line. Blank
+// lines are skipped.
+func readBatch(r io.Reader) ([]string, error) {
+	var docs []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			docs = append(docs, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read document list: %w", err)
+	}
+	return docs, nil
+}
+
+// executeBatch renders the documents listed in the batch file one after
+// another, each preceded by a header naming it, as a single document.
+func executeBatch(cmd *cobra.Command, w io.Writer) error {
+	list := io.Reader(os.Stdin)
+	if batchFile != "-" {
+		f, err := os.Open(batchFile)
+		if err != nil {
+			return fmt.Errorf("unable to open document list: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		list = f
+	}
+	docs, err := readBatch(list)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return errors.New("no documents listed")
+	}
+
+	if slices.Contains(docs, "-") {
+		return errors.New("unable to read stdin in batch mode")
+	}
+	outs, err := renderEach(len(docs), func(i int) (string, error) {
+		out, err := renderArg(cmd, docs[i])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", docs[i], err)
+		}
+		return out, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for i, out := range outs {
+		if len(docs) > 1 {
+			b.WriteString(recursiveHeader(docs[i]))
+		}
+		b.WriteString(out)
+	}
+
+	if pager || cmd.Flags().Changed("pager") {
+		return runPager(b.String())
+	}
+	if _, err := fmt.Fprint(w, b.String()); err != nil {
+		return fmt.Errorf("unable to write to writer: %w", err)
+	}
+	return nil
+}
+
+// renderArg renders the document given by a path or URL, as a document or
+// as code.
+func renderArg(cmd *cobra.Command, arg string) (string, error) {
+	src, err := sourceFromArg(cmd.Context(), arg)
+	if err != nil {
+		return "", err
+	}
+	defer src.reader.Close() //nolint:errcheck
+	content, err := readContent(src)
+	if err != nil {
+		return "", err
+	}
+
+	md := prepareMarkdown(src, content)
+	return renderFormat(formatSource(src, content), func() (string, error) {
+		if !src.isMarkdown() {
+			return renderCode(src.URL, string(content))
+		}
+		r, _, err := setupRenderer(src)
+		if err != nil {
+			return "", err
+		}
+		out, err := renderDocument(r, md)
+		if err != nil {
+			return "", fmt.Errorf("unable to render markdown: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// renderEach renders n documents with up to --jobs of them at once, and
+// returns their output in order. Once a document fails, no more are started,
+// and the error of the first failing one is returned, as if they had been
+// rendered one after another.
+func renderEach(n int, render func(i int) (string, error)) ([]string, error) {
+	workers := jobs
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, n)
+	if workers > 1 {
+		// Progress bars of parallel downloads would be drawn over each
+		// other.
+		downloadProgress = false
+		defer func() { downloadProgress = true }()
+	}
+
+	outs := make([]string, n)
+	errs := make([]error, n)
+	var (
+		wg     sync.WaitGroup
+		next   atomic.Int64
+		failed atomic.Bool
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Documents are started in order, so all those before a failing
+			// one are rendered.
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if outs[i], errs[i] = render(i); errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outs, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadBatch(t *testing.T) {
+	docs, err := readBatch(strings.NewReader("README.md\n\n  docs/guide.md  \r\nhttps://example.com/a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "docs/guide.md", "https://example.com/a.md"}
+	if strings.Join(docs, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %q, got %q", want, docs)
+	}
+}
+
+func TestRenderEach(t *testing.T) {
+	t.Cleanup(func() { jobs = 0 })
+	for _, j := range []int{0, 1, 3, 20} {
+		jobs = j
+		outs, err := renderEach(10, func(i int) (string, error) { return fmt.Sprint(i), nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(outs, ","); got != "0,1,2,3,4,5,6,7,8,9" {
+			t.Errorf("jobs %d: expected the output in order, got %s", j, got)
+		}
+
+		_, err = renderEach(10, func(i int) (string, error) {
+			if i == 4 || i == 7 {
+				return "", fmt.Errorf("document %d", i)
+			}
+			return "", nil
+		})
+		if err == nil || err.Error() != "document 4" {
+			t.Errorf("jobs %d: expected the error of the first failing document, got %v", j, err)
+		}
+	}
+
+	if _, err := renderEach(0, func(int) (string, error) { return "", errors.New("rendered") }); err != nil {
+		t.Errorf("expected nothing to be rendered, got %v", err)
+	}
+}
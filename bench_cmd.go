@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchFlags struct {
+		iterations int
+		cpuProfile string
+		memProfile string
+	}
+
+	benchCmd = &cobra.Command{
+		Use:   "bench SOURCE",
+		Short: "Time how long rendering a document takes",
+		Long: paragraph(fmt.Sprintf("\n%s a document several times and report how long setting up the renderer, preparing the markdown, rendering and writing the output take, and how much they allocate. The first run warms caches up and isn't counted. Use --cpuprofile and --memprofile to write pprof profiles, for use with %s.",
+			keyword("Render"), keyword("go tool pprof"))),
+		Example:           paragraph("glow bench README.md\nglow bench -n 50 --cpuprofile cpu.out big.md"),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMarkdownFiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if benchFlags.iterations < 1 {
+				return fmt.Errorf("invalid number of iterations: %d", benchFlags.iterations)
+			}
+			src, err := sourceFromArg(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			content, err := io.ReadAll(src.reader)
+			_ = src.reader.Close()
+			if err != nil {
+				return fmt.Errorf("unable to read from reader: %w", err)
+			}
+			return runBench(cmd.OutOrStdout(), src, content)
+		},
+	}
+)
+
+// benchPhases are the phases of rendering a document, in order.
+var benchPhases = []string{"setup", "parse", "render", "write"}
+
+// benchMeter measures the time and allocations of the phases of rendering a
+// document over several runs.
+type benchMeter struct {
+	last   time.Time
+	mem    runtime.MemStats
+	times  map[string][]time.Duration
+	allocs map[string]uint64
+	bytes  map[string]uint64
+}
+
+func newBenchMeter() *benchMeter {
+	return &benchMeter{
+		times:  map[string][]time.Duration{},
+		allocs: map[string]uint64{},
+		bytes:  map[string]uint64{},
+	}
+}
+
+// start starts measuring a phase.
+func (m *benchMeter) start() {
+	runtime.ReadMemStats(&m.mem)
+	m.last = time.Now()
+}
+
+// stage records the phase that just ended, and starts measuring the next.
+func (m *benchMeter) stage(name string) {
+	d := time.Since(m.last)
+	before := m.mem
+	runtime.ReadMemStats(&m.mem)
+	m.times[name] = append(m.times[name], d)
+	m.allocs[name] += m.mem.Mallocs - before.Mallocs
+	m.bytes[name] += m.mem.TotalAlloc - before.TotalAlloc
+	m.last = time.Now()
+}
+
+// runBench renders a document the given number of times, writing profiles
+// if asked to, and reports the results to w.
+func runBench(w io.Writer, src *source, content []byte) error {
+	if err := benchOnce(src, content, newBenchMeter()); err != nil {
+		return err
+	}
+
+	if benchFlags.cpuProfile != "" {
+		f, err := os.Create(benchFlags.cpuProfile)
+		if err != nil {
+			return fmt.Errorf("unable to create cpu profile: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("unable to start cpu profile: %w", err)
+		}
+	}
+	m := newBenchMeter()
+	for range benchFlags.iterations {
+		if err := benchOnce(src, content, m); err != nil {
+			pprof.StopCPUProfile()
+			return err
+		}
+	}
+	pprof.StopCPUProfile()
+
+	if benchFlags.memProfile != "" {
+		f, err := os.Create(benchFlags.memProfile)
+		if err != nil {
+			return fmt.Errorf("unable to create memory profile: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			return fmt.Errorf("unable to write memory profile: %w", err)
+		}
+	}
+
+	return writeBenchReport(w, src, content, m)
+}
+
+// benchOnce renders a document like glow does, writing the output nowhere.
+func benchOnce(src *source, content []byte, m *benchMeter) error {
+	m.start()
+	r, _, err := setupRenderer(src)
+	if err != nil {
+		return err
+	}
+	m.stage("setup")
+
+	md := prepareMarkdown(src, content)
+	m.stage("parse")
+
+	var out string
+	if src.isMarkdown() {
+		out, err = renderDocument(r, md)
+	} else {
+		out, err = renderCode(src.URL, string(content))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to render markdown: %w", err)
+	}
+	m.stage("render")
+
+	if _, err := io.WriteString(io.Discard, out); err != nil {
+		return fmt.Errorf("unable to write to writer: %w", err)
+	}
+	m.stage("write")
+	return nil
+}
+
+// writeBenchReport writes a table of the time and allocations per run of
+// each phase.
+func writeBenchReport(w io.Writer, src *source, content []byte, m *benchMeter) error {
+	name := src.URL
+	if name == "" {
+		name = "stdin"
+	}
+	runs := uint64(benchFlags.iterations)                                         //nolint:gosec
+	fmt.Fprintf(w, "%s: %s, %d lines, %d runs at width %d, color profile %s\n\n", //nolint:errcheck
+		name, humanize.Bytes(uint64(len(content))), strings.Count(string(content), "\n"), runs, width,
+		lipgloss.ColorProfile().Name())
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "phase\tmean\tmin\tmax\tallocs/op\tbytes/op\t") //nolint:errcheck
+	var total []time.Duration
+	var allocs, bytes uint64
+	row := func(phase string, times []time.Duration, allocs, bytes uint64) {
+		mean, lowest, highest := benchStats(times)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t\n", //nolint:errcheck
+			phase, benchDuration(mean), benchDuration(lowest), benchDuration(highest),
+			allocs/runs, humanize.Bytes(bytes/runs))
+	}
+	for _, phase := range benchPhases {
+		times := m.times[phase]
+		row(phase, times, m.allocs[phase], m.bytes[phase])
+		if total == nil {
+			total = make([]time.Duration, len(times))
+		}
+		for i, d := range times {
+			total[i] += d
+		}
+		allocs += m.allocs[phase]
+		bytes += m.bytes[phase]
+	}
+	row("total", total, allocs, bytes)
+	return tw.Flush() //nolint:wrapcheck
+}
+
+// benchStats returns the mean, minimum and maximum of durations.
+func benchStats(times []time.Duration) (mean, lowest, highest time.Duration) {
+	if len(times) == 0 {
+		return 0, 0, 0
+	}
+	lowest = times[0]
+	var sum time.Duration
+	for _, d := range times {
+		sum += d
+		lowest = min(lowest, d)
+		highest = max(highest, d)
+	}
+	return sum / time.Duration(len(times)), lowest, highest
+}
+
+// benchDuration formats a duration with a few significant digits.
+func benchDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond / 10).String()
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchStats(t *testing.T) {
+	mean, lowest, highest := benchStats([]time.Duration{3 * time.Millisecond, time.Millisecond, 5 * time.Millisecond})
+	if mean != 3*time.Millisecond || lowest != time.Millisecond || highest != 5*time.Millisecond {
+		t.Errorf("expected 3ms, 1ms and 5ms, got %v, %v and %v", mean, lowest, highest)
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	dir := t.TempDir()
+	saved := benchFlags
+	defer func() { benchFlags = saved }()
+	benchFlags.iterations = 2
+	benchFlags.memProfile = filepath.Join(dir, "mem.out")
+
+	var b bytes.Buffer
+	src := &source{URL: filepath.Join(dir, "doc.md")}
+	if err := runBench(&b, src, []byte("# Title\n\nSome *text*.\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"2 runs", "setup", "parse", "render", "write", "total", "allocs/op"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("expected %q in the report, got:\n%s", s, b.String())
+		}
+	}
+	if info, err := os.Stat(benchFlags.memProfile); err != nil || info.Size() == 0 {
+		t.Errorf("expected a memory profile, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package bookmarks stores bookmarked documents and headings on disk.
+package bookmarks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the bookmarks file in the config directory.
+const FileName = "bookmarks.json"
+
+// Bookmark points at a document, or at a heading within it.
+type Bookmark struct {
+	// Absolute path or URL of the document.
+	Path string `json:"path"`
+	// Heading text, without the leading #s. Empty for whole documents.
+	Heading string    `json:"heading,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Title returns a short, human-readable name for the bookmark.
+func (b Bookmark) Title() string {
+	if b.Heading == "" {
+		return b.Path
+	}
+	return b.Path + " › " + b.Heading
+}
+
+// Store is a list of bookmarks persisted to a JSON file. It's safe for
+// concurrent use.
+type Store struct {
+	path string
+
+	mu        sync.Mutex
+	bookmarks []Bookmark
+}
+
+// Load reads the bookmarks stored at path. A missing file is treated as an
+// empty list.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bookmarks: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.bookmarks); err != nil {
+		return nil, fmt.Errorf("unable to parse bookmarks: %w", err)
+	}
+	return s, nil
+}
+
+// List returns all bookmarks in the order they were added.
+func (s *Store) List() []Bookmark {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.bookmarks)
+}
+
+// Has reports whether the document or heading is bookmarked.
+func (s *Store) Has(path, heading string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index(path, heading) >= 0
+}
+
+// Toggle adds a bookmark for the document or heading, or removes it if it
+// already exists, and saves the store. It reports whether the bookmark now
+// exists.
+func (s *Store) Toggle(path, heading string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := false
+	if i := s.index(path, heading); i >= 0 {
+		s.bookmarks = slices.Delete(s.bookmarks, i, i+1)
+	} else {
+		s.bookmarks = append(s.bookmarks, Bookmark{
+			Path:    path,
+			Heading: heading,
+			Created: time.Now(),
+		})
+		added = true
+	}
+	return added, s.save()
+}
+
+// Remove deletes the bookmark at index i, as returned by List, and saves the
+// store.
+func (s *Store) Remove(i int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i < 0 || i >= len(s.bookmarks) {
+		return fmt.Errorf("no bookmark #%d", i+1)
+	}
+	s.bookmarks = slices.Delete(s.bookmarks, i, i+1)
+	return s.save()
+}
+
+func (s *Store) index(path, heading string) int {
+	return slices.IndexFunc(s.bookmarks, func(b Bookmark) bool {
+		return b.Path == path && b.Heading == heading
+	})
+}
+
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.bookmarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode bookmarks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, b, 0o600); err != nil {
+		return fmt.Errorf("unable to write bookmarks: %w", err)
+	}
+	return nil
+}
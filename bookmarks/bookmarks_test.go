@@ -0,0 +1,42 @@
+package bookmarks
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glow", FileName)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added, err := s.Toggle("/docs/a.md", ""); err != nil || !added {
+		t.Fatalf("expected bookmark to be added: %v", err)
+	}
+	if _, err := s.Toggle("/docs/a.md", "Usage"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.List()); got != 2 {
+		t.Fatalf("expected 2 bookmarks, got %d", got)
+	}
+	if !s.Has("/docs/a.md", "Usage") {
+		t.Error("expected heading bookmark to exist")
+	}
+
+	if added, err := s.Toggle("/docs/a.md", ""); err != nil || added {
+		t.Fatalf("expected bookmark to be removed: %v", err)
+	}
+	if err := s.Remove(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove(0); err == nil {
+		t.Error("expected an error removing from an empty store")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/douglas-larocca/glow/v2/bookmarks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bookmarksCmd = &cobra.Command{
+		Use:   "bookmarks",
+		Short: "List bookmarked documents",
+		Long: paragraph(fmt.Sprintf("\n%s the documents and headings bookmarked with %s in the TUI.",
+			keyword("List"), keyword("b"))),
+		Example: paragraph("glow bookmarks\nglow bookmarks open 2\nglow bookmarks rm 2"),
+		Args:    cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			s, err := bookmarks.Load(bookmarksFile())
+			if err != nil {
+				return err
+			}
+			list := s.List()
+			if len(list) == 0 {
+				fmt.Println("No bookmarks yet. Press b on a document in the TUI to add one.")
+				return nil
+			}
+			for i, b := range list {
+				fmt.Printf("%3d  %s\n", i+1, b.Title())
+			}
+			return nil
+		},
+	}
+
+	bookmarksOpenCmd = &cobra.Command{
+		Use:   "open N",
+		Short: "Render a bookmarked document",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, _, err := bookmarkArg(args[0])
+			if err != nil {
+				return err
+			}
+			if tui || cmd.Flags().Changed("tui") {
+				return runTUI(b.Path, "")
+			}
+			return executeArg(cmd, b.Path, cmd.OutOrStdout())
+		},
+	}
+
+	bookmarksRmCmd = &cobra.Command{
+		Use:     "rm N",
+		Aliases: []string{"remove"},
+		Short:   "Remove a bookmark",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			b, s, err := bookmarkArg(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := s.Toggle(b.Path, b.Heading); err != nil {
+				return err
+			}
+			fmt.Println("Removed bookmark:", b.Title())
+			return nil
+		},
+	}
+)
+
+// bookmarksFile returns the path of the bookmarks file, which lives next to
+// the config file.
+func bookmarksFile() string {
+	return filepath.Join(configDir(), bookmarks.FileName)
+}
+
+// bookmarkArg looks up a bookmark by its 1-based position in the list.
+func bookmarkArg(arg string) (bookmarks.Bookmark, *bookmarks.Store, error) {
+	s, err := bookmarks.Load(bookmarksFile())
+	if err != nil {
+		return bookmarks.Bookmark{}, nil, err
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return bookmarks.Bookmark{}, nil, errors.New("bookmarks are referred to by number, see glow bookmarks")
+	}
+	list := s.List()
+	if n < 1 || n > len(list) {
+		return bookmarks.Bookmark{}, nil, fmt.Errorf("no bookmark #%d", n)
+	}
+	return list[n-1], s, nil
+}
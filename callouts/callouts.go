@@ -0,0 +1,278 @@
+// Package callouts renders GitHub alerts and Obsidian callouts, blockquotes
+// starting with a marker like [!NOTE], as colored boxes.
+package callouts
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/douglas-larocca/glow/v2/placeholder"
+)
+
+// Style is the color and icon of a kind of callout.
+type Style struct {
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// DefaultStyles are the styles of the GitHub alert kinds, which the other
+// kinds of callouts fall back to.
+var DefaultStyles = map[string]Style{
+	"note":      {Color: "#4493F8", Icon: "ℹ"},
+	"tip":       {Color: "#3FB950", Icon: "✦"},
+	"important": {Color: "#AB7DF8", Icon: "‼"},
+	"warning":   {Color: "#D29922", Icon: "⚠"},
+	"caution":   {Color: "#F85149", Icon: "✖"},
+}
+
+// kinds maps the kinds of Obsidian callouts to the GitHub alert kind they
+// look like.
+var kinds = map[string]string{
+	"note":      "note",
+	"tip":       "tip",
+	"important": "important",
+	"warning":   "warning",
+	"caution":   "caution",
+	"abstract":  "note",
+	"summary":   "note",
+	"tldr":      "note",
+	"info":      "note",
+	"todo":      "note",
+	"quote":     "note",
+	"cite":      "note",
+	"hint":      "tip",
+	"success":   "tip",
+	"check":     "tip",
+	"done":      "tip",
+	"question":  "important",
+	"help":      "important",
+	"faq":       "important",
+	"example":   "important",
+	"attention": "warning",
+	"failure":   "caution",
+	"fail":      "caution",
+	"missing":   "caution",
+	"danger":    "caution",
+	"error":     "caution",
+	"bug":       "caution",
+}
+
+// Callout is a callout taken out of a document.
+type Callout struct {
+	// Kind as written, lowercased, e.g. "note" or "bug".
+	Kind  string
+	Title string
+	// Markdown of the callout's content.
+	Body string
+}
+
+var marker = placeholder.New("GLOWCALLOUT")
+
+var (
+	headerPattern = regexp.MustCompile(`^ {0,3}> ?\[!([A-Za-z]+)\][+-]? *(.*)$`)
+	quotePattern  = regexp.MustCompile(`^ {0,3}> ?`)
+	fencePattern  = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Extract takes the callouts out of a markdown document, leaving a
+// placeholder paragraph for each that Render replaces with the rendered
+// callout.
+func Extract(markdown string) (string, []Callout) {
+	if !strings.Contains(markdown, "[!") {
+		return markdown, nil
+	}
+
+	var (
+		out      strings.Builder
+		callouts []Callout
+		fence    string
+		current  *Callout
+		body     []string
+	)
+	finish := func() {
+		current.Body = strings.Join(body, "\n")
+		callouts = append(callouts, *current)
+		out.WriteString(marker.Paragraph(len(callouts) - 1))
+		current, body = nil, nil
+	}
+
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if current != nil {
+			if quotePattern.MatchString(trimmed) {
+				body = append(body, quotePattern.ReplaceAllString(trimmed, ""))
+				continue
+			}
+			finish()
+		}
+
+		if m := fencePattern.FindStringSubmatch(trimmed); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(trimmed) == m[1]:
+				fence = ""
+			}
+		}
+		if fence != "" {
+			out.WriteString(line)
+			continue
+		}
+
+		if m := headerPattern.FindStringSubmatch(trimmed); m != nil {
+			kind := strings.ToLower(m[1])
+			if _, ok := kinds[kind]; ok {
+				title := strings.TrimSpace(m[2])
+				if title == "" {
+					title = strings.ToUpper(kind[:1]) + kind[1:]
+				}
+				current = &Callout{Kind: kind, Title: title}
+				continue
+			}
+		}
+		out.WriteString(line)
+	}
+	if current != nil {
+		finish()
+	}
+	return out.String(), callouts
+}
+
+// Options configure how callouts are rendered.
+type Options struct {
+	// Width the document is rendered at. Defaults to 80.
+	Width int
+	// Style of the document, used for the content of callouts without its
+	// margins.
+	Style ansi.StyleConfig
+	// Further options of the renderer, e.g. the color profile.
+	Glamour []glamour.TermRendererOption
+	// Styles of callouts by kind, taking precedence over the defaults.
+	Styles map[string]Style
+}
+
+// Render replaces the placeholders left by Extract in rendered output with
+// the callouts, drawn as boxes in the color of their kind. Callouts are
+// indented as their placeholder.
+func Render(rendered string, callouts []Callout, opts Options) (string, error) {
+	if len(callouts) == 0 {
+		return rendered, nil
+	}
+	if opts.Width <= 0 {
+		opts.Width = 80
+	}
+
+	var err error
+	out := marker.Replace(rendered, len(callouts), func(n, indent int) string {
+		// Callouts span the document, within its margins.
+		width := opts.Width - indent
+		if opts.Style.Document.Margin != nil {
+			width -= int(*opts.Style.Document.Margin) //nolint:gosec
+		}
+		box, boxErr := renderCallout(callouts[n], max(width, 10), opts)
+		if boxErr != nil && err == nil {
+			err = boxErr
+		}
+		return box
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// renderCallout draws a callout as a box of the given width.
+func renderCallout(c Callout, width int, opts Options) (string, error) {
+	style := lookupStyle(c.Kind, opts.Styles)
+	color := lipgloss.Color(style.Color)
+
+	title := lipgloss.NewStyle().Foreground(color).Bold(true).Render(strings.TrimSpace(style.Icon + " " + c.Title))
+	content := title
+	if strings.TrimSpace(c.Body) != "" {
+		body, err := renderBody(c.Body, width-4, opts)
+		if err != nil {
+			return "", err
+		}
+		content += "\n" + body
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(width - 2).
+		Render(content), nil
+}
+
+// renderBody renders the content of a callout without the document's
+// margins.
+func renderBody(body string, width int, opts Options) (string, error) {
+	cfg := opts.Style
+	var margin uint
+	cfg.Document.Margin = &margin
+	cfg.Document.BlockPrefix = ""
+	cfg.Document.BlockSuffix = ""
+
+	r, err := glamour.NewTermRenderer(append([]glamour.TermRendererOption{
+		glamour.WithStyles(cfg),
+		glamour.WithWordWrap(width),
+	}, opts.Glamour...)...)
+	if err != nil {
+		return "", fmt.Errorf("unable to create renderer: %w", err)
+	}
+	out, err := r.Render(body)
+	if err != nil {
+		return "", fmt.Errorf("unable to render callout: %w", err)
+	}
+
+	lines := strings.Split(out, "\n")
+	for len(lines) > 0 && strings.TrimSpace(xansi.Strip(lines[0])) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(xansi.Strip(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// lookupStyle returns the style of a kind of callout, falling back to the
+// style of the GitHub alert kind it looks like.
+func lookupStyle(kind string, styles map[string]Style) Style {
+	base := DefaultStyles[kinds[kind]]
+	for _, k := range []string{kinds[kind], kind} {
+		if s, ok := styles[k]; ok {
+			if s.Color != "" {
+				base.Color = s.Color
+			}
+			if s.Icon != "" {
+				base.Icon = s.Icon
+			}
+		}
+	}
+	return base
+}
+
+// ParseStyles reads the callout styles of a glamour style file, set under a
+// "callouts" key by kind, e.g. {"callouts": {"note": {"color": "#00AAFF"}}}.
+func ParseStyles(styleJSON []byte) (map[string]Style, error) {
+	var cfg struct {
+		Callouts map[string]Style `json:"callouts"`
+	}
+	if err := json.Unmarshal(styleJSON, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse callout styles: %w", err)
+	}
+	styles := make(map[string]Style, len(cfg.Callouts))
+	for k, s := range cfg.Callouts {
+		styles[strings.ToLower(k)] = s
+	}
+	return styles, nil
+}
@@ -0,0 +1,77 @@
+package callouts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const doc = "# Doc\n\n" +
+	"> [!NOTE]\n> Read *this*.\n\n" +
+	"> [!bug] Known issue\n> - one\n> - two\n\n" +
+	"> [!unknown]\n> Just a quote.\n\n" +
+	"```md\n> [!TIP]\n> In a fence.\n```\n"
+
+func TestExtract(t *testing.T) {
+	out, got := Extract(doc)
+	want := []Callout{
+		{Kind: "note", Title: "Note", Body: "Read *this*."},
+		{Kind: "bug", Title: "Known issue", Body: "- one\n- two"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d callouts, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("callout %d: expected %+v, got %+v", i+1, want[i], got[i])
+		}
+	}
+	for _, s := range []string{marker.Text(0), marker.Text(1), "> [!unknown]", "> [!TIP]"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in %q", s, out)
+		}
+	}
+	if strings.Contains(out, "[!NOTE]") {
+		t.Errorf("expected the callout to be taken out, got %q", out)
+	}
+}
+
+func TestRender(t *testing.T) {
+	callouts := []Callout{{Kind: "warning", Title: "Careful", Body: "Hot *surface*."}}
+	rendered := "  Before\n  " + marker.Text(0) + "  \n  After"
+
+	out, err := Render(rendered, callouts, Options{Width: 40, Style: styles.NoTTYStyleConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(xansi.Strip(out), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected a box of 3 lines, got %q", out)
+	}
+	for _, l := range lines[1:5] {
+		if !strings.HasPrefix(l, "  ") || xansi.StringWidth(l) != 38 {
+			t.Errorf("expected an indented box line of width 38, got %q", l)
+		}
+	}
+	if !strings.Contains(lines[2], "⚠ Careful") || !strings.Contains(lines[3], "Hot *surface*.") {
+		t.Errorf("unexpected box: %q", out)
+	}
+}
+
+func TestStyles(t *testing.T) {
+	styles, err := ParseStyles([]byte(`{"document": {}, "callouts": {"Note": {"color": "#000000"}, "bug": {"icon": "B"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := lookupStyle("info", styles); s.Color != "#000000" || s.Icon != DefaultStyles["note"].Icon {
+		t.Errorf("expected info to fall back to note, got %+v", s)
+	}
+	if s := lookupStyle("bug", styles); s.Icon != "B" || s.Color != DefaultStyles["caution"].Color {
+		t.Errorf("expected bug to override the icon only, got %+v", s)
+	}
+	if _, err := ParseStyles([]byte("nope")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
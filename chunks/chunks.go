@@ -0,0 +1,185 @@
+// Package chunks renders large markdown documents a part at a time, so that
+// rendering them doesn't take memory in proportion to the whole document,
+// and their start can be shown before the rest is rendered.
+package chunks
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Size is the size documents are split at, give or take a block.
+const Size = 64 << 10
+
+var (
+	fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// Lines that may continue the block before them after a blank line:
+	// indented lines, list items, quotes, and definitions of a term.
+	continuationPattern = regexp.MustCompile(`^(?:[ \t]|[-*+>:]|\d{1,9}[.)]|$)`)
+	referencePattern    = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*\S`)
+)
+
+// spacer is a block that renders to nothing. Headings and paragraphs are
+// spaced differently at the start of a document, so parts after the first
+// start with one.
+const spacer = "<!-- -->\n\n"
+
+// Split splits a document into parts of about size bytes. Parts end at a
+// blank line followed by a new top-level block, outside of code blocks, so
+// that they render the same on their own. Documents without such places are
+// left whole.
+func Split(md string, size int) []string {
+	var (
+		parts []string
+		start int
+		fence string
+		blank bool
+	)
+	for pos := 0; pos < len(md); {
+		end := strings.IndexByte(md[pos:], '\n') + 1
+		if end == 0 {
+			end = len(md) - pos
+		}
+		line := strings.TrimRight(md[pos:pos+end], "\r\n")
+
+		switch m := fencePattern.FindStringSubmatch(line); {
+		case fence != "":
+			if m != nil && strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1] {
+				fence = ""
+			}
+		case m != nil:
+			fence = m[1]
+			fallthrough
+		default:
+			if blank && pos-start >= size && !continuationPattern.MatchString(line) {
+				parts = append(parts, md[start:pos])
+				start = pos
+			}
+		}
+		blank = fence == "" && strings.TrimSpace(line) == ""
+		pos += end
+	}
+	return append(parts, md[start:])
+}
+
+// references returns the reference link definitions of a document, by their
+// lower case label.
+func references(md string) map[string]string {
+	refs := map[string]string{}
+	fence := ""
+	for _, line := range strings.Split(md, "\n") {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if m := referencePattern.FindStringSubmatch(line); m != nil && fence == "" {
+			refs[strings.ToLower(m[1])] = strings.TrimRight(line, "\r") + "\n"
+		}
+	}
+	return refs
+}
+
+// Renderer renders the parts of a document one after another, so that they
+// join up as if the document had been rendered whole.
+type Renderer struct {
+	render func(string) (string, error)
+	parts  []string
+	next   int
+	refs   map[string]string
+
+	// Renderers wrap documents in a prefix and suffix, which are only kept
+	// at the start of the first part and the end of the last one.
+	prefix, suffix string
+}
+
+// NewRenderer returns a renderer of a document split into parts of about
+// size bytes. Documents are rendered whole if render wraps them in a way
+// that can't be told apart from their content.
+func NewRenderer(md string, size int, render func(string) (string, error)) (*Renderer, error) {
+	empty, err := render("")
+	if err != nil {
+		return nil, err
+	}
+	r := &Renderer{render: render, parts: []string{md}}
+	half := len(empty) / 2
+	if empty[:half] == empty[half:] {
+		r.prefix, r.suffix = empty[:half], empty[half:]
+		r.parts = Split(md, size)
+	}
+	if len(r.parts) > 1 {
+		r.refs = references(md)
+	}
+	return r, nil
+}
+
+// Done reports whether all parts were rendered.
+func (r *Renderer) Done() bool {
+	return r.next >= len(r.parts)
+}
+
+// Next renders the next part of the document.
+func (r *Renderer) Next() (string, error) {
+	if r.Done() {
+		return "", io.EOF
+	}
+	i := r.next
+	r.next++
+	if len(r.parts) == 1 {
+		return r.render(r.parts[0])
+	}
+
+	var b strings.Builder
+	if i > 0 {
+		b.WriteString(spacer)
+	}
+	b.WriteString(r.parts[i])
+	// Reference links may be defined in other parts.
+	lower := strings.ToLower(r.parts[i])
+	defs := false
+	for label, def := range r.refs {
+		if strings.Contains(lower, "["+label+"]") {
+			if !defs {
+				b.WriteString("\n\n")
+				defs = true
+			}
+			b.WriteString(def)
+		}
+	}
+
+	out, err := r.render(b.String())
+	if err != nil {
+		return "", err
+	}
+	if i > 0 {
+		out = strings.TrimPrefix(out, r.prefix)
+	}
+	if i < len(r.parts)-1 {
+		out = strings.TrimSuffix(out, r.suffix)
+	}
+	return out, nil
+}
+
+// Render renders a document a part at a time, writing each part as soon as
+// it's rendered.
+func Render(w io.Writer, md string, size int, render func(string) (string, error)) error {
+	r, err := NewRenderer(md, size, render)
+	if err != nil {
+		return err
+	}
+	for !r.Done() {
+		out, err := r.Next()
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+	return nil
+}
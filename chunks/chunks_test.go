@@ -0,0 +1,64 @@
+package chunks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	doc := "# One\n\ntext\n\n- item\n\n- loose item\n\n    indented\n\n" +
+		"````\ncode\n\n```\nstill code\n```\n\nmore\n````\n\n## Two\n\nlast\n"
+	want := []string{
+		"# One\n\n",
+		"text\n\n- item\n\n- loose item\n\n    indented\n\n",
+		"````\ncode\n\n```\nstill code\n```\n\nmore\n````\n\n",
+		"## Two\n\n",
+		"last\n",
+	}
+	if got := Split(doc, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := Split(doc, len(doc)); !reflect.DeepEqual(got, []string{doc}) {
+		t.Errorf("expected small documents to be whole, got %q", got)
+	}
+	if got := Split("", 1); !reflect.DeepEqual(got, []string{""}) {
+		t.Errorf("expected an empty part, got %q", got)
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	// Wraps documents like glamour, with a newline before and after.
+	var inputs []string
+	render := func(md string) (string, error) {
+		inputs = append(inputs, md)
+		return "\n" + strings.ToUpper(strings.TrimPrefix(md, spacer)) + "\n", nil
+	}
+	doc := "# One\n\nSee [Docs].\n\n## Two\n\n[docs]: https://example.com\n"
+
+	var b strings.Builder
+	if err := Render(&b, doc, 1, render); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"",
+		"# One\n\n",
+		spacer + "See [Docs].\n\n\n\n[docs]: https://example.com\n",
+		spacer + "## Two\n\n",
+		spacer + "[docs]: https://example.com\n\n\n[docs]: https://example.com\n",
+	}
+	if !reflect.DeepEqual(inputs, want) {
+		t.Errorf("expected the parts\n%q\ngot\n%q", want, inputs)
+	}
+	if got := b.String(); !strings.HasPrefix(got, "\n# ONE\n\nSEE") || strings.Count(got, "\n\n\n\n\n") > 0 {
+		t.Errorf("expected the parts to be joined without their wrapping, got %q", got)
+	}
+
+	r, err := NewRenderer(doc, 1, func(md string) (string, error) { return "<" + md + ">", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := r.Next(); err != nil || out != "<"+doc+">" || !r.Done() {
+		t.Errorf("expected documents to be rendered whole when parts can't be joined, got %q, %v", out, err)
+	}
+}
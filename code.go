@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/codethemes"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/muesli/termenv"
+)
+
+var codeLineNumber = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#6C6C6C"}).
+	Render
+
+// validateChromaTheme checks that the theme given with --chroma-theme exists.
+func validateChromaTheme(theme string) error {
+	if theme == "" {
+		return nil
+	}
+	if _, ok := chromastyles.Registry[theme]; !ok {
+		names := chromastyles.Names()
+		slices.Sort(names)
+		return fmt.Errorf("unknown chroma theme %q, expected one of: %s", theme, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// codeStyle returns the chroma style for highlighting code: the theme given
+// with --chroma-theme, or else the one of the glamour style. It returns nil
+// if code shouldn't be highlighted.
+func codeStyle() *chroma.Style {
+	if chromaTheme != "" {
+		return chromastyles.Get(chromaTheme)
+	}
+	cfg, err := utils.StyleConfig(style)
+	if err != nil {
+		return nil
+	}
+	if cfg.CodeBlock.Chroma != nil {
+		return chromaStyleFromConfig(cfg.CodeBlock.Chroma)
+	}
+	if cfg.CodeBlock.Theme != "" {
+		return chromastyles.Get(cfg.CodeBlock.Theme)
+	}
+	return nil
+}
+
+// chromaStyleFromConfig builds a chroma style from the colors of a glamour
+// style, the same way glamour does for code blocks.
+func chromaStyleFromConfig(c *ansi.Chroma) *chroma.Style {
+	entries := chroma.StyleEntries{}
+	for token, p := range map[chroma.TokenType]ansi.StylePrimitive{
+		chroma.Text:                c.Text,
+		chroma.Error:               c.Error,
+		chroma.Comment:             c.Comment,
+		chroma.CommentPreproc:      c.CommentPreproc,
+		chroma.Keyword:             c.Keyword,
+		chroma.KeywordReserved:     c.KeywordReserved,
+		chroma.KeywordNamespace:    c.KeywordNamespace,
+		chroma.KeywordType:         c.KeywordType,
+		chroma.Operator:            c.Operator,
+		chroma.Punctuation:         c.Punctuation,
+		chroma.Name:                c.Name,
+		chroma.NameBuiltin:         c.NameBuiltin,
+		chroma.NameTag:             c.NameTag,
+		chroma.NameAttribute:       c.NameAttribute,
+		chroma.NameClass:           c.NameClass,
+		chroma.NameConstant:        c.NameConstant,
+		chroma.NameDecorator:       c.NameDecorator,
+		chroma.NameException:       c.NameException,
+		chroma.NameFunction:        c.NameFunction,
+		chroma.NameOther:           c.NameOther,
+		chroma.Literal:             c.Literal,
+		chroma.LiteralNumber:       c.LiteralNumber,
+		chroma.LiteralDate:         c.LiteralDate,
+		chroma.LiteralString:       c.LiteralString,
+		chroma.LiteralStringEscape: c.LiteralStringEscape,
+		chroma.GenericDeleted:      c.GenericDeleted,
+		chroma.GenericEmph:         c.GenericEmph,
+		chroma.GenericInserted:     c.GenericInserted,
+		chroma.GenericStrong:       c.GenericStrong,
+		chroma.GenericSubheading:   c.GenericSubheading,
+		chroma.Background:          c.Background,
+	} {
+		var attrs []string
+		if p.Color != nil {
+			attrs = append(attrs, *p.Color)
+		}
+		if p.BackgroundColor != nil {
+			attrs = append(attrs, "bg:"+*p.BackgroundColor)
+		}
+		if p.Italic != nil && *p.Italic {
+			attrs = append(attrs, "italic")
+		}
+		if p.Bold != nil && *p.Bold {
+			attrs = append(attrs, "bold")
+		}
+		if p.Underline != nil && *p.Underline {
+			attrs = append(attrs, "underline")
+		}
+		entries[token] = strings.Join(attrs, " ")
+	}
+
+	s, err := chroma.NewStyle("glow", entries)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// renderCode highlights a source code file, adding line numbers when
+// --line-numbers is set. Lines are numbered as in the file, even when only a
+// range is rendered.
+func renderCode(name, code string) (string, error) {
+	code = selectedLines.apply(code)
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+
+	out := code
+	if lipgloss.ColorProfile() != termenv.Ascii {
+		lexer := lexers.Match(filepath.Base(name))
+		if lexer == nil {
+			lexer = lexers.Analyse(code)
+		}
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+		cs := codeStyle()
+		if theme := utils.CodeThemes(style, codeThemes).For(lexer.Config().Name); theme != "" {
+			cs = chromastyles.Get(theme)
+		}
+		if cs != nil {
+			it, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+			if err != nil {
+				return "", fmt.Errorf("unable to highlight code: %w", err)
+			}
+
+			var b strings.Builder
+			if err := codethemes.Formatter(lipgloss.ColorProfile()).Format(&b, cs, it); err != nil {
+				return "", fmt.Errorf("unable to highlight code: %w", err)
+			}
+			out = b.String()
+		}
+	}
+
+	if !showLineNumbers {
+		return out, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	first := max(selectedLines.start, 1)
+	digits := len(fmt.Sprint(first + len(lines) - 1))
+
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(codeLineNumber(fmt.Sprintf("%*d │ ", digits, first+i)))
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// glamourStyle returns the glamour style option, with the code block theme
+// replaced by the one given with --chroma-theme.
+func glamourStyle(isCode bool) glamour.TermRendererOption {
+	return glamourStyleNamed(style, isCode)
+}
+
+// glamourStyleNamed is glamourStyle for a style other than the configured
+// one.
+func glamourStyleNamed(name string, isCode bool) glamour.TermRendererOption {
+	if chromaTheme == "" {
+		return utils.GlamourStyle(name, isCode)
+	}
+	cfg, err := utils.StyleConfig(name)
+	if err != nil {
+		return utils.GlamourStyle(name, isCode)
+	}
+	cfg.CodeBlock.Chroma = nil
+	cfg.CodeBlock.Theme = chromaTheme
+	if isCode {
+		var margin uint
+		cfg.CodeBlock.Margin = &margin
+	}
+	return glamour.WithStyles(cfg)
+}
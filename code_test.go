@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestRenderCodeLineNumbers(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		showLineNumbers = false
+		selectedLines = lineRange{}
+	})
+
+	showLineNumbers = true
+	selectedLines = lineRange{start: 9, end: 10}
+
+	var code string
+	for i := 1; i <= 12; i++ {
+		code += "line\n"
+	}
+	out, err := renderCode("main.go", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := " 9 │ line\n10 │ line\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
+
+func TestValidateChromaTheme(t *testing.T) {
+	if err := validateChromaTheme("monokai"); err != nil {
+		t.Error(err)
+	}
+	if err := validateChromaTheme("no-such-theme"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
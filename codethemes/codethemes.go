@@ -0,0 +1,111 @@
+// Package codethemes highlights the fenced code blocks of some languages with
+// chroma themes of their own, like monokai for Go and github for diffs,
+// rather than with the theme of the document style.
+package codethemes
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/douglas-larocca/glow/v2/fences"
+	"github.com/muesli/termenv"
+)
+
+// Themes are chroma themes by language. Languages are matched by the lexer
+// they name, so "golang" finds the theme set for "go".
+type Themes map[string]string
+
+// Merge returns the themes of t, overridden by those of other.
+func (t Themes) Merge(other Themes) Themes {
+	if len(other) == 0 {
+		return t
+	}
+	merged := maps.Clone(t)
+	if merged == nil {
+		merged = Themes{}
+	}
+	maps.Copy(merged, other)
+	return merged
+}
+
+// For returns the theme of a language, or "" if it has none.
+func (t Themes) For(language string) string {
+	if len(t) == 0 || language == "" {
+		return ""
+	}
+	if theme, ok := t[language]; ok {
+		return theme
+	}
+	name := lexerName(language)
+	for lang, theme := range t {
+		if lexerName(lang) == name {
+			return theme
+		}
+	}
+	return ""
+}
+
+// lexerName returns the name of the lexer for a language, or the language
+// itself if chroma has no lexer for it.
+func lexerName(language string) string {
+	if l := lexers.Get(language); l != nil {
+		return strings.ToLower(l.Config().Name)
+	}
+	return strings.ToLower(language)
+}
+
+// Formatter returns the chroma formatter for a color profile.
+func Formatter(p termenv.Profile) chroma.Formatter {
+	switch p {
+	case termenv.TrueColor:
+		return formatters.TTY16m
+	case termenv.ANSI256:
+		return formatters.TTY256
+	case termenv.ANSI, termenv.Ascii:
+		return formatters.TTY16
+	}
+	return formatters.TTY16
+}
+
+// Highlight highlights the fenced code blocks whose language has a theme,
+// and turns them into ```ansi fences, so that their colors are passed through
+// rather than replaced by those of the document style. Blocks that fail to
+// highlight are left as they are.
+func Highlight(markdown string, themes Themes, f chroma.Formatter) string {
+	if len(themes) == 0 {
+		return markdown
+	}
+	return fences.Replace(markdown, func(b fences.Block) string {
+		if theme := themes.For(b.Language); theme != "" {
+			if highlighted, ok := highlight(b.Body, b.Language, theme, f); ok {
+				return b.Fence + "ansi\n" + highlighted + b.Fence + "\n"
+			}
+		}
+		return b.String()
+	})
+}
+
+// highlight highlights code in a language with a theme.
+func highlight(code, language, theme string, f chroma.Formatter) (string, bool) {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if err := f.Format(&b, chromastyles.Get(theme), it); err != nil {
+		return "", false
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out, true
+}
@@ -0,0 +1,60 @@
+package codethemes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+)
+
+func TestFor(t *testing.T) {
+	themes := Themes{"go": "monokai", "Diff": "github", "nolexer": "vim"}
+	for lang, want := range map[string]string{
+		"go":      "monokai",
+		"golang":  "monokai",
+		"diff":    "github",
+		"patch":   "github",
+		"nolexer": "vim",
+		"python":  "",
+		"":        "",
+	} {
+		if got := themes.For(lang); got != want {
+			t.Errorf("%q: expected %q, got %q", lang, want, got)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Themes{"go": "monokai", "diff": "github"}
+	merged := base.Merge(Themes{"go": "dracula"})
+	if merged["go"] != "dracula" || merged["diff"] != "github" {
+		t.Errorf("expected go to be overridden, got %v", merged)
+	}
+	if base["go"] != "monokai" {
+		t.Errorf("expected the themes merged into to be left as they were, got %v", base)
+	}
+	if got := Themes(nil).Merge(Themes{"go": "vim"}); got["go"] != "vim" {
+		t.Errorf("expected merging into no themes to work, got %v", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	doc := "# Code\n\n```go\npackage main\n```\n\n~~~~python\nx = 1\n~~~~\n\n```\nplain\n```\n"
+	out := Highlight(doc, Themes{"go": "monokai"}, formatters.TTY16m)
+
+	if strings.Contains(out, "```go") {
+		t.Errorf("expected the go block to be highlighted, got %q", out)
+	}
+	if !strings.Contains(out, "```ansi\n\x1b[") || !strings.Contains(out, "package") {
+		t.Errorf("expected an ansi fence with the highlighted code, got %q", out)
+	}
+	for _, s := range []string{"# Code\n\n", "~~~~python\nx = 1\n~~~~\n", "```\nplain\n```\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q to be left as it was in %q", s, out)
+		}
+	}
+
+	if got := Highlight(doc, nil, formatters.TTY16m); got != doc {
+		t.Errorf("expected no change without themes, got %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+// completeMarkdownFiles completes the arguments of commands taking documents
+// with markdown files and directories.
+func completeMarkdownFiles(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	exts := utils.MarkdownExtensions()
+	for i, ext := range exts {
+		exts[i] = strings.TrimPrefix(ext, ".")
+	}
+	return exts, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeSource completes the argument of the root command, which takes one
+// document, or several to open in tabs with --tui. Markdown files and
+// directories are listed here rather than left to the shell, which would take
+// the names of subcommands completed along with them for file extensions.
+func completeSource(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if tui, _ := cmd.Flags().GetBool("tui"); len(args) > 0 && !tui {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	dir, prefix := filepath.Split(toComplete)
+	entries, err := os.ReadDir(cmp.Or(utils.ExpandPath(dir), "."))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	exts := utils.MarkdownExtensions()
+	var paths []string
+	dirs := 0
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		switch {
+		case e.IsDir():
+			paths = append(paths, dir+name+string(os.PathSeparator))
+			dirs++
+		case slices.ContainsFunc(exts, func(ext string) bool { return strings.EqualFold(filepath.Ext(name), ext) }):
+			paths = append(paths, dir+name)
+		}
+	}
+	// Keep completing into a directory, rather than ending the argument.
+	if len(paths) == 1 && dirs == 1 {
+		return paths, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeStyles completes --style with the names of the built-in styles, and
+// the JSON styles in the current directory and the config directory. Paths
+// complete with any JSON file.
+func completeStyles(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.ContainsRune(toComplete, os.PathSeparator) || strings.HasPrefix(toComplete, "~") {
+		return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+	}
+
+	names := []string{styles.AutoStyle}
+	for name := range styles.DefaultStyles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// Styles in the config directory are found by name, see resolveStyle.
+	files, _ := filepath.Glob("*.json")
+	global, _ := filepath.Glob(filepath.Join(configDir(), "*.json"))
+	for _, f := range global {
+		if name := filepath.Base(f); !slices.Contains(files, name) {
+			files = append(files, name)
+		}
+	}
+	names = append(names, files...)
+
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			matches = append(matches, name)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestCompleteStyles(t *testing.T) {
+	dir := t.TempDir()
+	used := viper.ConfigFileUsed()
+	viper.SetConfigFile(filepath.Join(dir, "glow.yml"))
+	t.Cleanup(func() { viper.SetConfigFile(used) })
+	for _, name := range []string{"mine.json", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		toComplete string
+		want       []string
+		directive  cobra.ShellCompDirective
+	}{
+		{"d", []string{"dark", "dracula"}, cobra.ShellCompDirectiveNoFileComp},
+		{"mi", []string{"mine.json"}, cobra.ShellCompDirectiveNoFileComp},
+		{"styles/", []string{"json"}, cobra.ShellCompDirectiveFilterFileExt},
+	} {
+		got, directive := completeStyles(nil, nil, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.want) || directive != tc.directive {
+			t.Errorf("%q: expected %v (%d), got %v (%d)", tc.toComplete, tc.want, tc.directive, got, directive)
+		}
+	}
+
+	if got := resolveStyle("mine.json"); got != filepath.Join(dir, "mine.json") {
+		t.Errorf("expected the style in the config directory, got %s", got)
+	}
+	if got := resolveStyle("missing.json"); got != "missing.json" {
+		t.Errorf("expected a missing style to be left alone, got %s", got)
+	}
+}
+
+func TestCompleteSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"README.md", "notes.MARKDOWN", "main.go", "docs/guide.md", ".hidden/x.md"} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sep := string(os.PathSeparator)
+	prefix := dir + sep
+
+	for _, tc := range []struct {
+		toComplete string
+		want       []string
+		directive  cobra.ShellCompDirective
+	}{
+		{prefix, []string{prefix + "README.md", prefix + "docs" + sep, prefix + "notes.MARKDOWN"}, cobra.ShellCompDirectiveNoFileComp},
+		{prefix + "d", []string{prefix + "docs" + sep}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace},
+		{prefix + "docs" + sep, []string{prefix + "docs" + sep + "guide.md"}, cobra.ShellCompDirectiveNoFileComp},
+		{prefix + ".", []string{prefix + ".hidden" + sep}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace},
+	} {
+		got, directive := completeSource(rootCmd, nil, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.want) || directive != tc.directive {
+			t.Errorf("%q: expected %v (%d), got %v (%d)", tc.toComplete, tc.want, tc.directive, got, directive)
+		}
+	}
+	if _, directive := completeSource(rootCmd, []string{"README.md"}, ""); directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("expected no completion for a second document, got %d", directive)
+	}
+}
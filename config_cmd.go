@@ -1,16 +1,21 @@
 package main
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/x/editor"
+	"github.com/douglas-larocca/glow/v2/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 const defaultConfig = `# style name or JSON path (default "auto")
@@ -21,48 +26,337 @@ mouse: false
 pager: false
 # word-wrap at width
 width: 90
+# widest the terminal's width is wrapped at when width is 0, or 0 to use all
+# of it
+maxWidth: 120
+# columns of space left of documents
+margin: 0
+# center documents narrower than the terminal, in place of margin
+center: false
+# style output piped to a pager, as in glow README.md | less -R, for the
+# terminal the pager runs in
+forceTTY: false
+# show the word count, reading time, and counts of headings, links and code
+# blocks after documents, or in the status bar of the TUI
+stats: false
 # show all files, including hidden and ignored.
 all: false
-# spinner animation for streaming content (dots, dots2, line, star, boxBounce, etc.)
+# don't resume documents where you left off (TUI-mode only)
+noResume: false
+# how many recently opened documents to remember, or 0 to remember none
+historySize: 50
+# names of the README shown for a directory, in order of preference
+readmeNames: ["README.md", "README", "Readme.md", "Readme", "readme.md", "readme"]
+# directories of documentation, whose index.md or README is shown for a
+# directory without a README
+docRoots: ["docs", "doc", "wiki"]
+# age public keys documents stashed with --encrypt are encrypted for, instead
+# of a passphrase
+# stashRecipients: ["age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"]
+# age identity file encrypted documents of the stash are decrypted with,
+# instead of asking for their passphrase
+# stashIdentity: "~/.config/age/keys.txt"
+# don't write changes, like ticked off tasks, back to documents (TUI-mode only)
+readonly: false
+# open documents with every section folded under its heading (TUI-mode only)
+fold: false
+# animation shown while streaming content and downloading documents of
+# unknown size (dots, dots2, line, star, boxBounce, etc.), or none to hide
+# loaders
 spinner: "bouncingBall"
-# color for the spinner animation (any valid hex color)
+# color for the spinner animation (a hex color or an ANSI color)
 spinnerColor: "#ffffff"
+# timeout, retries and size limit for fetching remote documents; proxies are
+# taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+timeout: "30s"
+retries: 2
+maxDownload: "10MB"
+# largest document to read, local or remote (0 to disable)
+maxSize: "64MB"
+# only read remote documents from the cache of documents fetched before
+offline: false
+# which kinds of sources documents may be read from, for scripts passing
+# untrusted arguments to glow
+# security:
+#   allowNetwork: true
+#   allowFileUris: true
+#   allowGitRefs: true
+#   allowLocalFiles: true
+# custom spinner animations, usable by name with --spinner
+# spinners:
+#   pulse:
+#     interval: 120ms
+#     frames: ["∙", "●", "∙", " "]
+# syntax highlighting theme for code (default: from the style)
+# chromaTheme: "dracula"
+# syntax highlighting themes for the code of some languages
+# codeThemes:
+#   go: "monokai"
+#   diff: "github"
+# how to display mermaid diagrams (ascii, code, skip)
+mermaid: "ascii"
+# how to display LaTeX math (unicode, source, off)
+math: "unicode"
+# how to display tables wider than the output (wrap, scroll, records)
+wideTables: "wrap"
+# which code blocks keep their ANSI colors (fence for ansi code blocks only,
+# all, off)
+rawAnsi: "fence"
+# how to display images (box for their alt text and dimensions, ascii to add
+# a halftone, link)
+images: "box"
+# how to display YAML frontmatter (show, hide, only)
+frontmatter: "hide"
+# how to display links (inline, list, footnote); listed links can be opened
+# by number in the TUI
+links: "inline"
+# make links clickable with OSC 8 escape sequences (auto, always, never);
+# auto does so in terminals known to support them, but not in pagers
+hyperlinks: "auto"
+# how to render piped input as it arrives (line, llm)
+stream: "line"
+# how to read stdin (auto streams pipes and reads redirected files whole,
+# stream, buffer)
+stdinMode: "auto"
+# how much piped input to wait for before rendering it (line, word, chunk)
+streamGranularity: "line"
+# where to show piped input as it's rendered (alt for the alternate screen,
+# inline below the prompt, append to only ever add lines)
+streamScreen: "alt"
+# how long piped input must pause before partial lines are rendered
+# (default: 75ms with stream llm, 250ms with word granularity)
+# streamDebounce: "100ms"
+# most renders per second of piped input as it arrives, for slow terminals
+# (0 for no limit)
+streamMaxFps: 0
+# what the terminal can do (dumb, vt100, xterm, kitty): dumb and vt100 have
+# no alternate screen and draw boxes with ASCII (default: detect from $TERM)
+# termProfile: "vt100"
+# log debug messages and render timings
+debug: false
+# file to write the log to, or - for stderr (default: glow.log in the cache
+# directory)
+# logFile: "/tmp/glow.log"
+# custom keys for TUI actions (open, search, quit, lineNumbers, copy,
+# copyRendered, split, newTab, nextTab, prevTab, closeTab, finder, edit,
+# annotate, outline, tasks, back, forward, fold, export); see glow config keys
+# for the current bindings
+# keys:
+#   quit: ["q", "ctrl+q"]
+#   copy: ["c", "ctrl+y"]
+# sets of settings to switch to with --profile, or by default with profile
+# profiles:
+#   presentation:
+#     style: "dark"
+#     width: 60
+#     pager: true
+# profile: "presentation"
 `
 
-var configCmd = &cobra.Command{
-	Use:     "config",
-	Hidden:  false,
-	Short:   "Edit the glow config file",
-	Long:    paragraph(fmt.Sprintf("\n%s the glow config file. We’ll use EDITOR to determine which editor to use. If the config file doesn't exist, it will be created.", keyword("Edit"))),
-	Example: paragraph("glow config\nglow config --config path/to/config.yml"),
-	Args:    cobra.NoArgs,
-	RunE: func(*cobra.Command, []string) error {
-		if err := ensureConfigFile(); err != nil {
-			return err
+var (
+	configCmd = &cobra.Command{
+		Use:     "config",
+		Hidden:  false,
+		Short:   "Edit the glow config file",
+		Long:    paragraph(fmt.Sprintf("\n%s the glow config file. We’ll use EDITOR to determine which editor to use. If the config file doesn't exist, it will be created.", keyword("Edit"))),
+		Example: paragraph("glow config\nglow config --config path/to/config.yml\nglow config show\nglow config set width 100"),
+		Args:    cobra.NoArgs,
+		RunE:    editConfig,
+	}
+
+	configEditCmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the glow config file",
+		Long:  configCmd.Long,
+		Args:  cobra.NoArgs,
+		RunE:  editConfig,
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Show the current settings",
+		Long:  paragraph(fmt.Sprintf("\n%s the value of every setting, and whether it comes from the config file, the environment or the defaults.", keyword("Show"))),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			r, _, err := setupRenderer(&source{URL: "config.md"})
+			if err != nil {
+				return err
+			}
+			out, err := renderDocument(r, configMarkdown(cmd))
+			if err != nil {
+				return fmt.Errorf("unable to render config: %w", err)
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), out)
+			return err //nolint:wrapcheck
+		},
+	}
+
+	configGetCmd = &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print the value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			s, err := lookupSetting(args[0])
+			if err != nil {
+				return err
+			}
+			if s.kind == kindMap {
+				b, err := yaml.Marshal(viper.Get(s.key))
+				if err != nil {
+					return fmt.Errorf("unable to encode %s: %w", s.key, err)
+				}
+				fmt.Print(string(b))
+				return nil
+			}
+			if s.kind == kindList {
+				fmt.Println(strings.Join(viper.GetStringSlice(s.key), ","))
+				return nil
+			}
+			fmt.Println(viper.Get(s.key))
+			return nil
+		},
+	}
+
+	configSetCmd = &cobra.Command{
+		Use:     "set KEY VALUE",
+		Short:   "Change a setting in the config file",
+		Long:    paragraph(fmt.Sprintf("\n%s a setting in the config file, after checking the value. Comments and other settings are kept.", keyword("Change"))),
+		Example: paragraph("glow config set width 100\nglow config set style dracula\nglow config set docRoots docs,wiki"),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			s, err := lookupSetting(args[0])
+			if err != nil {
+				return err
+			}
+			value, err := s.parse(args[1])
+			if err != nil {
+				return err
+			}
+			if err := ensureConfigFile(); err != nil {
+				return err
+			}
+			if err := setConfigValue(configFile, s.key, value); err != nil {
+				return err
+			}
+			fmt.Printf("Set %s to %v in %s\n", s.key, value, configFile)
+			return nil
+		},
+	}
+)
+
+// isConfigCmd reports whether cmd is one of the config commands, which work
+// with broken config files so they can be fixed.
+func isConfigCmd(cmd *cobra.Command) bool {
+	return cmd == configCmd || cmd.Parent() == configCmd
+}
+
+func editConfig(*cobra.Command, []string) error {
+	if err := ensureConfigFile(); err != nil {
+		return err
+	}
+
+	c, err := editor.Cmd("Glow", configFile)
+	if err != nil {
+		return fmt.Errorf("unable to set config file: %w", err)
+	}
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("unable to run command: %w", err)
+	}
+
+	fmt.Println("Wrote config file to:", configFile)
+	return checkConfigFile(configFile)
+}
+
+// configMarkdown describes the current settings of cmd as a markdown table.
+func configMarkdown(cmd *cobra.Command) string {
+	var b strings.Builder
+	b.WriteString("# Glow config\n\n")
+	used := viper.ConfigFileUsed()
+	if used != "" {
+		fmt.Fprintf(&b, "Read from `%s`.\n\n", used)
+	} else {
+		b.WriteString("No config file was found.\n\n")
+	}
+
+	// Keys set in the file, lowercased like viper does.
+	inFile := map[string]bool{}
+	if raw, err := os.ReadFile(used); err == nil {
+		var values map[string]any
+		_ = yaml.Unmarshal(raw, &values)
+		for k := range values {
+			inFile[strings.ToLower(k)] = true
 		}
+	}
 
-		c, err := editor.Cmd("Glow", configFile)
-		if err != nil {
-			return fmt.Errorf("unable to set config file: %w", err)
+	b.WriteString("| Setting | Value | From |\n|:--|:--|:--|\n")
+	for _, s := range settings {
+		from := "default"
+		_, inEnv := os.LookupEnv("GLOW_" + strings.ToUpper(s.key))
+		switch {
+		case flagChanged(cmd, s.flag):
+			from = "flag"
+		case inEnv:
+			from = "environment"
+		case profileKeys[s.key]:
+			from = "profile " + viper.GetString("profile")
+		case inFile[strings.ToLower(s.key)]:
+			from = "config file"
 		}
-		c.Stdin = os.Stdin
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-		if err := c.Run(); err != nil {
-			return fmt.Errorf("unable to run command: %w", err)
+
+		var value string
+		switch v := viper.Get(s.key); {
+		case v == nil || fmt.Sprint(v) == "":
+		case s.kind == kindMap:
+			j, _ := json.Marshal(v)
+			value = "`" + string(j) + "`"
+		case s.kind == kindList:
+			value = "`" + strings.Join(viper.GetStringSlice(s.key), ", ") + "`"
+		default:
+			value = "`" + fmt.Sprint(v) + "`"
 		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", s.key, strings.ReplaceAll(value, "|", `\|`), from)
+	}
+	return b.String()
+}
 
-		fmt.Println("Wrote config file to:", configFile)
+var configKeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Print the TUI key bindings",
+	Long:  paragraph(fmt.Sprintf("\n%s the keys bound to TUI actions, including those set in the %s section of the config file.", keyword("Print"), keyword("keys"))),
+	Args:  cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		bindings, err := ui.KeyBindings(viper.GetStringMapStringSlice("keys"))
+		if err != nil {
+			return fmt.Errorf("invalid keys in config: %w", err)
+		}
+		for _, b := range bindings {
+			keys := strings.Join(b.Keys, ", ")
+			if keys == "" {
+				keys = "(disabled)"
+			}
+			fmt.Printf("%-12s %s\n", b.Action, keys)
+		}
 		return nil
 	},
 }
 
+// configDir returns the directory of the config file, where files like
+// bookmarks and custom styles are kept.
+func configDir() string {
+	if used := viper.ConfigFileUsed(); used != "" {
+		return filepath.Dir(used)
+	}
+	return filepath.Dir(configFile)
+}
+
+// ensureConfigFile writes the default config file if there's none yet, to
+// the path of --config, or else to the file read or created on first run.
 func ensureConfigFile() error {
 	if configFile == "" {
-		configFile = viper.GetViper().ConfigFileUsed()
-		if err := os.MkdirAll(filepath.Dir(configFile), 0o755); err != nil { //nolint:gosec
-			return fmt.Errorf("could not write configuration file: %w", err)
-		}
+		configFile = cmp.Or(viper.ConfigFileUsed(), defaultConfigFile)
 	}
 
 	if ext := path.Ext(configFile); ext != ".yaml" && ext != ".yml" {
@@ -76,15 +370,16 @@ func ensureConfigFile() error {
 			return fmt.Errorf("unable create directory: %w", err)
 		}
 
-		f, err := os.Create(configFile)
-		if err != nil {
-			return fmt.Errorf("unable to create config file: %w", err)
-		}
-		defer func() { _ = f.Close() }()
-
-		if _, err := f.WriteString(defaultConfig); err != nil {
+		if err := os.WriteFile(configFile, []byte(defaultConfig), 0o600); err != nil {
 			return fmt.Errorf("unable to write config file: %w", err)
 		}
+		// Read the new file, so that its settings apply from now on.
+		if viper.ConfigFileUsed() == "" {
+			viper.SetConfigFile(configFile)
+			if err := viper.ReadInConfig(); err != nil {
+				return fmt.Errorf("unable to read config file: %w", err)
+			}
+		}
 	} else if err != nil { // some other error occurred
 		return fmt.Errorf("unable to stat config file: %w", err)
 	}
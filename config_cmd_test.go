@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestSetConfigValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glow.yml")
+	if err := os.WriteFile(path, []byte(defaultConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kv := range [][2]string{{"width", "100"}, {"Style", "dracula"}, {"logFile", "/tmp/glow.log"}} {
+		s, err := lookupSetting(kv[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := s.parse(kv[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := setConfigValue(path, s.key, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer("width: 90", "width: 100", `style: "auto"`, `style: "dracula"`).Replace(defaultConfig)
+	if got := string(b); !strings.HasPrefix(got, strings.TrimSuffix(want, "\n")) || !strings.HasSuffix(got, "\nlogFile: /tmp/glow.log\n") {
+		t.Errorf("expected the comments and other settings to be kept, got:\n%s", got)
+	}
+	if err := checkConfigFile(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	for _, tt := range []struct {
+		config string
+		err    string
+	}{
+		{"width: 80\ndebug: true\nkeys:\n  quit: q\n", ""},
+		{"Width: 80\n", ""},
+		{"wdth: 80\n", `unknown setting "wdth"`},
+		{"width: wide\n", "width must be a positive number"},
+		{"debug: yes please\n", "debug must be true or false"},
+		{"keys: q\n", "keys must be a map"},
+		{"width: [80]\n", "width must be"},
+		{"docRoots: [docs, wiki]\n", ""},
+		{"docRoots: docs\n", "docRoots must be a list"},
+		{"profiles:\n  work:\n    width: 80\n    style: dark\n", ""},
+		{"profiles:\n  work:\n    wdth: 80\n", `profile "work": unknown setting "wdth"`},
+		{"profiles:\n  work: 80\n", `profile "work" must be a map`},
+		{"profiles:\n  work:\n    security:\n      allowNetwork: true\n", `profile "work" can't set security`},
+		{"security:\n  allowNetwork: false\n  allowfileuris: false\n", ""},
+		{"security:\n  allowNetwrk: false\n", `unknown setting "allowNetwrk" in security`},
+		{"security:\n  allowNetwork: no thanks\n", "security.allowNetwork must be true or false"},
+	} {
+		path := filepath.Join(t.TempDir(), "glow.yml")
+		if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		err := checkConfigFile(path)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: unexpected error: %v", tt.config, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q: expected an error containing %q, got %v", tt.config, tt.err, err)
+		}
+	}
+
+	if _, err := lookupSetting("keys"); err != nil {
+		t.Error(err)
+	}
+	s, _ := lookupSetting("keys")
+	if _, err := s.parse("q"); err == nil {
+		t.Error("expected maps to be set by editing the file")
+	}
+	s, _ = lookupSetting("readmeNames")
+	if v, err := s.parse("README.md, index.md,"); err != nil || !slices.Equal(v.([]string), []string{"README.md", "index.md"}) {
+		t.Errorf("expected lists to be comma separated, got %v, %v", v, err)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	viper.Set("profiles", map[string]any{
+		"work": map[string]any{"width": 72, "style": "dracula"},
+	})
+	viper.Set("profile", "Work")
+	t.Cleanup(func() {
+		for _, key := range []string{"profiles", "profile", "width", "style"} {
+			viper.Set(key, nil)
+		}
+	})
+
+	cmd := &cobra.Command{}
+	cmd.Flags().Uint("width", 0, "")
+	if err := cmd.Flags().Set("width", "50"); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProfile(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if got := viper.GetString("style"); got != "dracula" {
+		t.Errorf("expected the style of the profile, got %q", got)
+	}
+	if viper.IsSet("width") && viper.GetInt("width") == 72 {
+		t.Error("expected the width flag to take precedence over the profile")
+	}
+
+	viper.Set("profile", "home")
+	if err := applyProfile(cmd); err == nil || !strings.Contains(err.Error(), "expected one of: work") {
+		t.Errorf("expected an error for an unknown profile, got %v", err)
+	}
+}
+
+// TestGlowCommand runs glow with the arguments of GLOW_TEST_ARGS, for tests
+// that need the configuration of a fresh process.
+func TestGlowCommand(t *testing.T) {
+	args := os.Getenv("GLOW_TEST_ARGS")
+	if args == "" {
+		t.Skip("only run by other tests")
+	}
+	rootCmd.SetArgs(strings.Fields(args))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigOnFirstRun(t *testing.T) {
+	home := t.TempDir()
+	glow := func(args string) string {
+		t.Helper()
+		cmd := exec.Command(os.Args[0], "-test.run=^TestGlowCommand$") //nolint:gosec
+		cmd.Env = append(os.Environ(), "GLOW_TEST_ARGS="+args, "HOME="+home, "XDG_CONFIG_HOME="+filepath.Join(home, "config"), "GLOW_CONFIG_HOME=")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("glow %s: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+
+	if out := glow("config get width"); !strings.HasPrefix(out, "90\n") {
+		t.Errorf("expected the width of the default config, got:\n%s", out)
+	}
+	if err := os.RemoveAll(filepath.Join(home, "config")); err != nil {
+		t.Fatal(err)
+	}
+	if out := glow("config set width 70"); !strings.Contains(out, "Set width to 70") {
+		t.Errorf("expected the width to be set, got:\n%s", out)
+	}
+	if out := glow("config get width"); !strings.HasPrefix(out, "70\n") {
+		t.Errorf("expected the width set, got:\n%s", out)
+	}
+}
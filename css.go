@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+)
+
+// styleCSS converts a glamour style into a stylesheet for the HTML preview.
+func styleCSS(s ansi.StyleConfig) string {
+	var b strings.Builder
+
+	b.WriteString(cssRule("body", s.Document.StylePrimitive,
+		"font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace",
+		"line-height: 1.5",
+		"margin: 0",
+	))
+	if s.Document.BackgroundColor == nil {
+		// Terminal styles rely on the terminal background, so pick one that
+		// contrasts with the text color.
+		bg := "#ffffff"
+		if s.Document.Color != nil && isLightColor(cssColor(*s.Document.Color)) {
+			bg = "#1b1b1b"
+		}
+		b.WriteString(cssRule("body", ansi.StylePrimitive{}, "background-color: "+bg))
+	}
+	b.WriteString(cssRule("p", s.Paragraph.StylePrimitive))
+	b.WriteString(cssRule("blockquote", s.BlockQuote.StylePrimitive,
+		"border-left: 2px solid currentColor",
+		"margin-left: 0",
+		"padding-left: 1em",
+	))
+	b.WriteString(cssRule("h1, h2, h3, h4, h5, h6", s.Heading.StylePrimitive))
+	for i, h := range []ansi.StyleBlock{s.H1, s.H2, s.H3, s.H4, s.H5, s.H6} {
+		extra := []string{}
+		if h.BackgroundColor != nil {
+			extra = append(extra, "display: inline-block", "padding: 0 .5em")
+		}
+		b.WriteString(cssRule(fmt.Sprintf("h%d", i+1), h.StylePrimitive, extra...))
+	}
+	b.WriteString(cssRule("strong", s.Strong))
+	b.WriteString(cssRule("em", s.Emph))
+	b.WriteString(cssRule("del", s.Strikethrough))
+	b.WriteString(cssRule("hr", s.HorizontalRule, "border: 0", "border-top: 1px solid currentColor"))
+	b.WriteString(cssRule("li", s.Item))
+	b.WriteString(cssRule("a", s.Link))
+	b.WriteString(cssRule("a:not([href^=http])", s.LinkText))
+	b.WriteString(cssRule("img", s.Image, "max-width: 100%"))
+	b.WriteString(cssRule(":not(pre) > code", s.Code.StylePrimitive, "padding: 0 .25em"))
+
+	var chroma ansi.StylePrimitive
+	if s.CodeBlock.Chroma != nil {
+		chroma = s.CodeBlock.Chroma.Text
+		if s.CodeBlock.Chroma.Background.BackgroundColor != nil {
+			chroma.BackgroundColor = s.CodeBlock.Chroma.Background.BackgroundColor
+		}
+	}
+	b.WriteString(cssRule("pre", s.CodeBlock.StylePrimitive, "padding: 1em", "overflow-x: auto"))
+	b.WriteString(cssRule("pre", chroma))
+	b.WriteString(cssRule("table", s.Table.StylePrimitive, "border-collapse: collapse"))
+	b.WriteString(cssRule("th, td", ansi.StylePrimitive{}, "border: 1px solid #555", "padding: .25em .5em"))
+	b.WriteString(cssRule("dt", s.DefinitionTerm))
+	b.WriteString(cssRule("dd", s.DefinitionDescription))
+
+	return b.String()
+}
+
+// cssRule returns a CSS rule for the given selector. Empty rules are
+// omitted.
+func cssRule(selector string, p ansi.StylePrimitive, extra ...string) string {
+	decls := extra
+	if p.Color != nil {
+		decls = append(decls, "color: "+cssColor(*p.Color))
+	}
+	if p.BackgroundColor != nil {
+		decls = append(decls, "background-color: "+cssColor(*p.BackgroundColor))
+	}
+	if p.Bold != nil {
+		decls = append(decls, "font-weight: "+map[bool]string{true: "bold", false: "normal"}[*p.Bold])
+	}
+	if p.Italic != nil && *p.Italic {
+		decls = append(decls, "font-style: italic")
+	}
+	var deco []string
+	if p.Underline != nil && *p.Underline {
+		deco = append(deco, "underline")
+	}
+	if p.CrossedOut != nil && *p.CrossedOut {
+		deco = append(deco, "line-through")
+	}
+	if p.Overlined != nil && *p.Overlined {
+		deco = append(deco, "overline")
+	}
+	if len(deco) > 0 {
+		decls = append(decls, "text-decoration: "+strings.Join(deco, " "))
+	}
+	if p.Faint != nil && *p.Faint {
+		decls = append(decls, "opacity: .7")
+	}
+	if p.Upper != nil && *p.Upper {
+		decls = append(decls, "text-transform: uppercase")
+	}
+	if p.Lower != nil && *p.Lower {
+		decls = append(decls, "text-transform: lowercase")
+	}
+	if p.Title != nil && *p.Title {
+		decls = append(decls, "text-transform: capitalize")
+	}
+	if len(decls) == 0 {
+		return ""
+	}
+	return selector + " { " + strings.Join(decls, "; ") + " }\n"
+}
+
+// ansi16 are the xterm defaults for the first 16 palette entries.
+var ansi16 = []string{
+	"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
+	"#808080", "#ff0000", "#00ff00", "#ffff00", "#0000ff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// cssColor converts a glamour color, which is either a hex value or an
+// ANSI 256 palette index, to a CSS color.
+func cssColor(c string) string {
+	n, err := strconv.Atoi(c)
+	if err != nil || n < 0 || n > 255 {
+		return c
+	}
+	if n < 16 {
+		return ansi16[n]
+	}
+	if n >= 232 {
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+	n -= 16
+	level := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		return 55 + i*40
+	}
+	return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+}
+
+// isLightColor reports whether a hex color is closer to white than black.
+func isLightColor(hex string) bool {
+	var r, g, b int
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return false
+	}
+	return r*299+g*587+b*114 > 128*1000
+}
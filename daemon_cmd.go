@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/passthrough"
+	"github.com/douglas-larocca/glow/v2/tables"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// formatHTML is the format the daemon renders for browsers and editor
+// previews, besides those of --format.
+const formatHTML = "html"
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcRenderError    = -32000
+)
+
+// How many renderers, one per style and width, the daemon keeps warm.
+const maxDaemonRenderers = 32
+
+var (
+	daemonSocket string
+
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Render markdown for other programs over a local socket",
+		Long: paragraph(fmt.Sprintf("\n%s markdown sent over a Unix socket, so editors and other tools can reuse a running glow instead of starting one per document. "+
+			"Requests are JSON-RPC 2.0 calls of the %s method, one per line, with the %s to render and optionally its %s, %s and %s (ansi, text or html). "+
+			"Renderers are kept for each style and width, so styles are only loaded once.",
+			keyword("Render"), keyword("render"), keyword("content"), keyword("style"), keyword("width"), keyword("format"))),
+		Example: paragraph("glow daemon\nglow daemon --socket /tmp/glow.sock\n" +
+			`echo '{"jsonrpc":"2.0","id":1,"method":"render","params":{"content":"# Hi","width":60}}' | nc -U /tmp/glow.sock`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path := daemonSocket
+			if path == "" {
+				var err error
+				if path, err = defaultDaemonSocket(); err != nil {
+					return err
+				}
+			}
+			return runDaemon(cmd.Context(), path)
+		},
+	}
+)
+
+// defaultDaemonSocket returns the path of the socket of the daemon, which is
+// per user. Outside of XDG_RUNTIME_DIR, the socket goes in a directory of the
+// temp directory that only the user can enter, which is created if needed.
+func defaultDaemonSocket() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "glow.sock"), nil
+	}
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("glow-%d", os.Getuid()))
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("unable to create %s: %w", dir, err)
+	}
+	// The directory may have been there already, made by someone else.
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", fmt.Errorf("unable to create %s: %w", dir, err)
+	}
+	if !info.IsDir() || info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("%s is open to other users", dir)
+	}
+	return filepath.Join(dir, "glow.sock"), nil
+}
+
+// rpcRequest is a JSON-RPC 2.0 request. Requests without an id are
+// notifications, which aren't answered.
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// renderParams are the parameters of the render method.
+type renderParams struct {
+	Content string `json:"content"`
+	// Style name or JSON path, defaulting to the configured style.
+	Style string `json:"style"`
+	// Width to wrap at, defaulting to the configured width.
+	Width uint `json:"width"`
+	// ansi, text or html, defaulting to ansi.
+	Format string `json:"format"`
+	// Path of the document, whose extension tells code from markdown.
+	Path string `json:"path"`
+}
+
+type renderResult struct {
+	Output string `json:"output"`
+	// CSS of the style, for HTML output.
+	CSS string `json:"css,omitempty"`
+}
+
+// rendererKey identifies the renderers a daemon keeps.
+type rendererKey struct {
+	style string
+	width uint
+	code  bool
+}
+
+// renderDaemon renders documents with renderers and styles kept between
+// requests.
+type renderDaemon struct {
+	md goldmark.Markdown
+
+	// Renderers aren't safe for concurrent use, so requests are rendered
+	// one at a time.
+	mu        sync.Mutex
+	renderers map[rendererKey]*glamour.TermRenderer
+	styles    map[string]ansi.StyleConfig
+}
+
+func newRenderDaemon() *renderDaemon {
+	return &renderDaemon{
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+		renderers: map[rendererKey]*glamour.TermRenderer{},
+		styles:    map[string]ansi.StyleConfig{},
+	}
+}
+
+// runDaemon serves render requests on a Unix socket at path until
+// interrupted.
+func runDaemon(ctx context.Context, path string) error {
+	// A socket left behind by a daemon that didn't shut down is replaced,
+	// but a running daemon isn't.
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("glow daemon is already running on %s", path)
+	}
+	_ = os.Remove(path)
+
+	l, err := listenUnix(path)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path) //nolint:errcheck
+
+	// Glamour renders with true color unless told otherwise, whatever the
+	// daemon's own terminal supports.
+	if colorProfile == "" {
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	d := newRenderDaemon()
+	log.Info("serving", "socket", path)
+	fmt.Fprintf(os.Stderr, "Rendering on %s\n", path) //nolint:errcheck
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("unable to accept connection: %w", err)
+		}
+		go func() {
+			defer conn.Close() //nolint:errcheck
+			if err := d.serve(conn); err != nil {
+				log.Debug("connection closed", "error", err)
+			}
+		}()
+	}
+}
+
+// serve answers the requests read from rw until it's closed.
+func (d *renderDaemon) serve(rw io.ReadWriter) error {
+	dec := json.NewDecoder(rw)
+	enc := json.NewEncoder(rw)
+	for {
+		var req rpcRequest
+		err := dec.Decode(&req)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			// The rest of the stream can't be read after malformed JSON.
+			_ = enc.Encode(rpcResponse{Version: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}})
+			return err //nolint:wrapcheck
+		}
+
+		result, rerr := d.call(req)
+		if len(req.ID) == 0 {
+			continue
+		}
+		resp := rpcResponse{Version: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if err := enc.Encode(resp); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+}
+
+// call runs the method of a request.
+func (d *renderDaemon) call(req rpcRequest) (any, *rpcError) {
+	if req.Version != "2.0" {
+		return nil, &rpcError{rpcInvalidRequest, `expected jsonrpc "2.0"`}
+	}
+	if req.Method != "render" {
+		return nil, &rpcError{rpcMethodNotFound, fmt.Sprintf("unknown method %q", req.Method)}
+	}
+	var p renderParams
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		return nil, &rpcError{rpcInvalidParams, err.Error()}
+	}
+	p.Format = cmp.Or(p.Format, formatANSI)
+	if p.Format != formatANSI && p.Format != formatText && p.Format != formatHTML {
+		return nil, &rpcError{rpcInvalidParams, fmt.Sprintf("invalid format %q, expected one of: ansi, text, html", p.Format)}
+	}
+	p.Style = cmp.Or(resolveStyle(p.Style), style)
+	if err := validateStyle(p.Style); err != nil {
+		return nil, &rpcError{rpcInvalidParams, err.Error()}
+	}
+	p.Width = cmp.Or(p.Width, width)
+
+	result, err := d.render(p)
+	if err != nil {
+		return nil, &rpcError{rpcRenderError, err.Error()}
+	}
+	return result, nil
+}
+
+// render renders a document as asked.
+func (d *renderDaemon) render(p renderParams) (renderResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	styleConfig, err := d.style(p.Style)
+	if err != nil {
+		return renderResult{}, err
+	}
+	if p.Format == formatHTML {
+		var b bytes.Buffer
+		if err := d.md.Convert(utils.RemoveFrontmatter([]byte(p.Content)), &b); err != nil {
+			return renderResult{}, fmt.Errorf("unable to convert markdown: %w", err)
+		}
+		return renderResult{Output: b.String(), CSS: styleCSS(styleConfig)}, nil
+	}
+
+	src := &source{URL: p.Path, markdown: p.Path == ""}
+	r, err := d.renderer(rendererKey{p.Style, p.Width, !src.isMarkdown()})
+	if err != nil {
+		return renderResult{}, err
+	}
+	out, err := utils.RenderMarkdown(r, prepareMarkdown(src, []byte(p.Content)), utils.RenderOptions{
+		Style:      p.Style,
+		Width:      int(p.Width), //nolint:gosec
+		Tables:     tables.Mode(wideTablesMode),
+		RawANSI:    passthrough.Mode(rawANSIMode),
+		CodeThemes: codeThemes,
+		Glamour: []glamour.TermRendererOption{
+			glamour.WithColorProfile(lipgloss.ColorProfile()),
+			glamour.WithPreservedNewLines(),
+		},
+	})
+	if err != nil {
+		return renderResult{}, fmt.Errorf("unable to render markdown: %w", err)
+	}
+	if p.Format == formatText {
+		out = utils.PlainText(out)
+	}
+	return renderResult{Output: out}, nil
+}
+
+// style returns the configuration of a style, loading it once.
+func (d *renderDaemon) style(name string) (ansi.StyleConfig, error) {
+	if cfg, ok := d.styles[name]; ok {
+		return cfg, nil
+	}
+	cfg, err := utils.StyleConfig(name)
+	if err != nil {
+		return cfg, err //nolint:wrapcheck
+	}
+	d.styles[name] = cfg
+	return cfg, nil
+}
+
+// renderer returns a renderer for a style and width, creating it once.
+func (d *renderDaemon) renderer(key rendererKey) (*glamour.TermRenderer, error) {
+	if r, ok := d.renderers[key]; ok {
+		return r, nil
+	}
+	if len(d.renderers) >= maxDaemonRenderers {
+		// Editors resized often would otherwise grow the daemon without
+		// end; dropping a renderer only costs creating it again.
+		for k := range d.renderers {
+			delete(d.renderers, k)
+			break
+		}
+	}
+	r, err := glamour.NewTermRenderer(
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		glamourStyleNamed(key.style, key.code),
+		glamour.WithWordWrap(int(key.width)), //nolint:gosec
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create renderer: %w", err)
+	}
+	d.renderers[key] = r
+	return r, nil
+}
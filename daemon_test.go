@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRenderDaemon(t *testing.T) {
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"render","params":{"content":"# Hello\n\nWorld","style":"notty","width":40,"format":"text"}}`,
+		`{"jsonrpc":"2.0","method":"render","params":{"content":"not answered","style":"notty"}}`,
+		`{"jsonrpc":"2.0","id":"html","method":"render","params":{"content":"*hi*","style":"dark","format":"html"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"render","params":{"content":"x","style":"notty","format":"pdf"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"shutdown"}`,
+		`{"id":5,"method":"render"}`,
+		`{"jsonrpc":`,
+	}, "\n")
+	var out bytes.Buffer
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader(requests), &out}
+	if err := newRenderDaemon().serve(rw); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result *renderResult   `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	var responses []response
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses, got %d:\n%s", len(responses), out.String())
+	}
+
+	if r := responses[0]; r.Error != nil || r.Result == nil || !strings.Contains(r.Result.Output, "Hello") || strings.Contains(r.Result.Output, "\x1b[") {
+		t.Errorf("expected plain text, got %+v", r)
+	}
+	if r := responses[1]; string(r.ID) != `"html"` || r.Result == nil || r.Result.Output != "<p><em>hi</em></p>\n" || r.Result.CSS == "" {
+		t.Errorf("expected HTML with the CSS of the style, got %+v", r)
+	}
+	for i, code := range []int{rpcInvalidParams, rpcMethodNotFound, rpcInvalidRequest, rpcParseError} {
+		if r := responses[i+2]; r.Error == nil || r.Error.Code != code {
+			t.Errorf("response %s: expected error %d, got %+v", r.ID, code, r.Error)
+		}
+	}
+}
+
+func TestDaemonSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't restrict access on Windows")
+	}
+	tmp := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tmp)
+
+	path, err := defaultDaemonSocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected a private directory for the socket, got %v (%v)", info.Mode(), err)
+	}
+
+	l, err := listenUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close() //nolint:errcheck
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a private socket, got %v (%v)", info.Mode(), err)
+	}
+
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := defaultDaemonSocket(); err == nil {
+		t.Error("expected an error for a directory open to others")
+	}
+}
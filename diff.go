@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// diffOp is the change status of a markdown block.
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffBlock is a markdown block with its change status.
+type diffBlock struct {
+	op   diffOp
+	text string
+}
+
+// splitBlocks splits markdown into blocks separated by blank lines. Fenced
+// code blocks are kept in one piece, even if they contain blank lines.
+func splitBlocks(s string) []string {
+	var (
+		blocks []string
+		cur    []string
+		fence  string
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			blocks = append(blocks, strings.Join(cur, "\n"))
+			cur = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case trimmed == "":
+			flush()
+			continue
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return blocks
+}
+
+// diffBlocks returns the changes turning the blocks in a into the blocks in
+// b, based on their longest common subsequence.
+func diffBlocks(a, b []string) []diffBlock {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var (
+		blocks []diffBlock
+		i, j   int
+	)
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			blocks = append(blocks, diffBlock{diffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			blocks = append(blocks, diffBlock{diffDelete, a[i]})
+			i++
+		default:
+			blocks = append(blocks, diffBlock{diffInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		blocks = append(blocks, diffBlock{diffDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		blocks = append(blocks, diffBlock{diffInsert, b[j]})
+	}
+	return blocks
+}
+
+// patchFile is a file changed by a unified diff.
+type patchFile struct {
+	name  string
+	hunks []patchHunk
+}
+
+// patchHunk holds both sides of a hunk of a unified diff.
+type patchHunk struct {
+	before, after string
+}
+
+// hunkHeader matches the line ranges of a hunk: @@ -1,5 +1,6 @@.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// parsePatch reads the files and hunks of a unified diff, such as the output
+// of git diff.
+func parsePatch(r io.Reader) ([]patchFile, error) {
+	var (
+		files         []patchFile
+		before, after []string
+		// Lines left in the current hunk, on each side.
+		oldLeft, newLeft int
+	)
+	addHunk := func() {
+		if len(files) == 0 {
+			return
+		}
+		f := &files[len(files)-1]
+		f.hunks = append(f.hunks, patchHunk{
+			before: strings.Join(before, "\n"),
+			after:  strings.Join(after, "\n"),
+		})
+		before, after = nil, nil
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1<<20)
+	for s.Scan() {
+		line := s.Text()
+
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				after = append(after, line[1:])
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				before = append(before, line[1:])
+				oldLeft--
+			default:
+				// Context; some tools strip the space from empty lines.
+				line = strings.TrimPrefix(line, " ")
+				before = append(before, line)
+				after = append(after, line)
+				oldLeft--
+				newLeft--
+			}
+			if oldLeft <= 0 && newLeft <= 0 {
+				addHunk()
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			files = append(files, patchFile{})
+		case strings.HasPrefix(line, "--- "):
+			// Plain diffs have no "diff" line before the file names.
+			if len(files) == 0 || len(files[len(files)-1].hunks) > 0 {
+				files = append(files, patchFile{})
+			}
+			files[len(files)-1].name = patchFileName(line[4:])
+		case strings.HasPrefix(line, "+++ ") && len(files) > 0:
+			if name := patchFileName(line[4:]); name != "" {
+				files[len(files)-1].name = name
+			}
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header: %q", line)
+			}
+			oldLeft, newLeft = hunkLength(m[1]), hunkLength(m[2])
+			if oldLeft == 0 && newLeft == 0 {
+				addHunk()
+			}
+		}
+		// Anything else is an extended header, like "index" or "new file
+		// mode", or a "\ No newline at end of file" marker.
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read diff: %w", err)
+	}
+	return files, nil
+}
+
+// hunkLength parses the optional line count of a hunk range, which defaults
+// to 1.
+func hunkLength(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// patchFileName returns the file name from a ---/+++ line, without the a/ or
+// b/ prefix git adds. It returns an empty string for /dev/null.
+func patchFileName(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffSideBySide bool
+
+	diffInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	diffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+	diffFaintStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#5C5C5C"})
+
+	diffCmd = &cobra.Command{
+		Use:   "diff OLD NEW | diff PATCH",
+		Short: "Render the changes between two markdown documents",
+		Long: paragraph(fmt.Sprintf("\n%s the changes between two markdown documents. Changed blocks are rendered and marked as added or removed. Given a single file, or - for stdin, a unified diff like the output of %s is rendered instead.",
+			keyword("Render"), keyword("git diff"))),
+		Example: paragraph("glow diff OLD.md NEW.md\nglow diff --side-by-side OLD.md NEW.md\ngit diff | glow diff -"),
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				out string
+				err error
+			)
+			if len(args) == 1 {
+				out, err = renderPatchDiff(args[0])
+			} else {
+				out, err = renderFileDiff(cmd.Context(), args[0], args[1])
+			}
+			if err != nil {
+				return err
+			}
+
+			if pager {
+				return runPager(out)
+			}
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), out); err != nil {
+				return fmt.Errorf("unable to write to writer: %w", err)
+			}
+			return nil
+		},
+	}
+)
+
+// renderFileDiff renders the changes between two markdown sources.
+func renderFileDiff(ctx context.Context, oldArg, newArg string) (string, error) {
+	before, err := readSource(ctx, oldArg)
+	if err != nil {
+		return "", err
+	}
+	after, err := readSource(ctx, newArg)
+	if err != nil {
+		return "", err
+	}
+	return renderDiff(diffBlocks(splitBlocks(before), splitBlocks(after)))
+}
+
+// renderPatchDiff renders the markdown files changed by a unified diff.
+// Other files in the diff are skipped.
+func renderPatchDiff(arg string) (string, error) {
+	r := os.Stdin
+	if arg != "-" {
+		f, err := os.Open(arg)
+		if err != nil {
+			return "", fmt.Errorf("unable to open file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		r = f
+	}
+
+	files, err := parsePatch(r)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, f := range files {
+		if !utils.IsMarkdownFile(f.name) || len(f.hunks) == 0 {
+			continue
+		}
+		b.WriteString(recursiveHeader(f.name))
+		for i, h := range f.hunks {
+			if i > 0 {
+				b.WriteString(diffFaintStyle.Render("  ⋯") + "\n")
+			}
+			out, err := renderDiff(diffBlocks(splitBlocks(h.before), splitBlocks(h.after)))
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(out)
+		}
+	}
+	if b.Len() == 0 {
+		return "", errors.New("no markdown changes found")
+	}
+	return b.String(), nil
+}
+
+// readSource reads a markdown source and applies the usual transforms.
+func readSource(ctx context.Context, arg string) (string, error) {
+	src, err := sourceFromArg(ctx, arg)
+	if err != nil {
+		return "", err
+	}
+	defer src.reader.Close() //nolint:errcheck
+
+	b, err := io.ReadAll(src.reader)
+	if err != nil {
+		return "", fmt.Errorf("unable to read from reader: %w", err)
+	}
+	return prepareMarkdown(src, b), nil
+}
+
+// renderDiff renders the blocks with a gutter marking their change status,
+// either one after another or, with --side-by-side, in two columns.
+func renderDiff(blocks []diffBlock) (string, error) {
+	const gutter = 2
+
+	colWidth := int(width) //nolint:gosec
+	if diffSideBySide {
+		// Leave room for the separator between the columns.
+		colWidth = (colWidth - 3) / 2
+	}
+	r, err := glamour.NewTermRenderer(
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		glamourStyle(false),
+		glamour.WithWordWrap(colWidth-gutter),
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to create renderer: %w", err)
+	}
+
+	render := func(b diffBlock) ([]string, error) {
+		out, err := r.Render(b.text)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render markdown: %w", err)
+		}
+		marker := diffFaintStyle.Render("  ")
+		switch b.op {
+		case diffInsert:
+			marker = diffInsertStyle.Render("+ ")
+		case diffDelete:
+			marker = diffDeleteStyle.Render("- ")
+		case diffEqual:
+		}
+		lines := trimBlankLines(strings.Split(out, "\n"))
+		for i, l := range lines {
+			lines[i] = marker + l
+		}
+		return append(lines, ""), nil
+	}
+
+	var out []string
+	if !diffSideBySide {
+		for _, b := range blocks {
+			lines, err := render(b)
+			if err != nil {
+				return "", err
+			}
+			out = append(out, lines...)
+		}
+		return "\n" + strings.Join(out, "\n") + "\n", nil
+	}
+
+	// Changed blocks are collected until the next unchanged block, so
+	// removals and the additions replacing them end up next to each other.
+	var left, right []string
+	flush := func() {
+		out = append(out, joinColumns(left, right, colWidth)...)
+		left, right = nil, nil
+	}
+	for _, b := range blocks {
+		lines, err := render(b)
+		if err != nil {
+			return "", err
+		}
+		switch b.op {
+		case diffDelete:
+			left = append(left, lines...)
+		case diffInsert:
+			right = append(right, lines...)
+		case diffEqual:
+			flush()
+			left, right = lines, lines
+			flush()
+		}
+	}
+	flush()
+	return "\n" + strings.Join(out, "\n") + "\n", nil
+}
+
+// joinColumns places two lists of lines next to each other, padding each
+// line of the left column to width.
+func joinColumns(left, right []string, width int) []string {
+	sep := diffFaintStyle.Render(" │ ")
+	lines := make([]string, max(len(left), len(right)))
+	for i := range lines {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		pad := max(width-lipgloss.Width(l), 0)
+		lines[i] = l + strings.Repeat(" ", pad) + sep + r
+	}
+	return lines
+}
+
+// trimBlankLines removes leading and trailing lines that only contain
+// whitespace.
+func trimBlankLines(lines []string) []string {
+	blank := func(s string) bool {
+		return strings.TrimSpace(ansi.Strip(s)) == ""
+	}
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
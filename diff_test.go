@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	md := "# Title\n\nSome text\nwrapped.\n\n```go\nfunc a() {}\n\nfunc b() {}\n```\n\n\n- item"
+	expected := []string{
+		"# Title",
+		"Some text\nwrapped.",
+		"```go\nfunc a() {}\n\nfunc b() {}\n```",
+		"- item",
+	}
+	if got := splitBlocks(md); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	got := diffBlocks(
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c", "d"},
+	)
+	expected := []diffBlock{
+		{diffEqual, "a"},
+		{diffDelete, "b"},
+		{diffInsert, "x"},
+		{diffEqual, "c"},
+		{diffInsert, "d"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	patch := `diff --git a/README.md b/README.md
+index 1234567..89abcde 100644
+--- a/README.md
++++ b/README.md
+@@ -1,4 +1,4 @@
+ # Title
+
+--- old
++-- new
+ end
+@@ -10 +10,2 @@ Section
+ ten
++eleven
+diff --git a/new.md b/new.md
+new file mode 100644
+--- /dev/null
++++ b/new.md
+@@ -0,0 +1 @@
++hello
+`
+	files, err := parsePatch(strings.NewReader(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []patchFile{
+		{
+			name: "README.md",
+			hunks: []patchHunk{
+				{before: "# Title\n\n-- old\nend", after: "# Title\n\n-- new\nend"},
+				{before: "ten", after: "ten\neleven"},
+			},
+		},
+		{
+			name:  "new.md",
+			hunks: []patchHunk{{before: "", after: "hello"}},
+		},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %+v, got %+v", expected, files)
+	}
+}
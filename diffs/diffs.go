@@ -0,0 +1,258 @@
+// Package diffs colors the fenced code blocks of unified diffs: added lines
+// in green, removed lines in red and hunk headers in blue, with the words
+// that changed within a line highlighted, like code review tools do.
+package diffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/douglas-larocca/glow/v2/fences"
+	"github.com/muesli/termenv"
+)
+
+// Style is how diffs are colored, set in glamour style files under a "diff"
+// key, e.g. {"diff": {"inserted": {"color": "#00AA00"}}}. The elements are
+// styled like those of glamour styles.
+type Style struct {
+	// Added and removed lines.
+	Inserted ansi.StylePrimitive `json:"inserted"`
+	Deleted  ansi.StylePrimitive `json:"deleted"`
+	// The words that changed within added and removed lines, over the style
+	// of their line.
+	InsertedWord ansi.StylePrimitive `json:"inserted_word"`
+	DeletedWord  ansi.StylePrimitive `json:"deleted_word"`
+	// Hunk headers, as in @@ -1,4 +1,5 @@.
+	Hunk ansi.StylePrimitive `json:"hunk"`
+	// File headers, as in diff --git and +++ b/main.go.
+	Header ansi.StylePrimitive `json:"header"`
+	// Whether the words that changed within lines are highlighted.
+	Words *bool `json:"words,omitempty"`
+}
+
+func stringPtr(s string) *string { return &s }
+func boolPtr(b bool) *bool       { return &b }
+
+// DefaultStyle is the style of diffs unless a style file sets its own.
+var DefaultStyle = Style{
+	Inserted:     ansi.StylePrimitive{Color: stringPtr("#3FB950")},
+	Deleted:      ansi.StylePrimitive{Color: stringPtr("#F85149")},
+	InsertedWord: ansi.StylePrimitive{Inverse: boolPtr(true)},
+	DeletedWord:  ansi.StylePrimitive{Inverse: boolPtr(true)},
+	Hunk:         ansi.StylePrimitive{Color: stringPtr("#4493F8")},
+	Header:       ansi.StylePrimitive{Bold: boolPtr(true)},
+	Words:        boolPtr(true),
+}
+
+// ParseStyle reads the diff style of a glamour style file over s, so that
+// style files extending others only change what they set.
+func ParseStyle(s Style, styleJSON []byte) (Style, error) {
+	var cfg struct {
+		Diff Style `json:"diff"`
+	}
+	if err := json.Unmarshal(styleJSON, &cfg); err != nil {
+		return s, fmt.Errorf("unable to parse diff style: %w", err)
+	}
+	d := cfg.Diff
+	s.Inserted = overlay(s.Inserted, d.Inserted)
+	s.Deleted = overlay(s.Deleted, d.Deleted)
+	s.InsertedWord = overlay(s.InsertedWord, d.InsertedWord)
+	s.DeletedWord = overlay(s.DeletedWord, d.DeletedWord)
+	s.Hunk = overlay(s.Hunk, d.Hunk)
+	s.Header = overlay(s.Header, d.Header)
+	if d.Words != nil {
+		s.Words = d.Words
+	}
+	return s, nil
+}
+
+// isDiff reports whether the info string of a fence names a diff.
+func isDiff(language string) bool {
+	return strings.EqualFold(language, "diff") || strings.EqualFold(language, "patch")
+}
+
+// Highlight colors the fenced code blocks of diffs with style, for a color
+// profile, and turns them into ```ansi fences so that their colors are passed
+// through rather than replaced by those of the document style.
+func Highlight(markdown string, style Style, profile termenv.Profile) string {
+	if profile == termenv.Ascii {
+		return markdown
+	}
+	return fences.Replace(markdown, func(b fences.Block) string {
+		if !isDiff(b.Language) {
+			return b.String()
+		}
+		return b.Fence + "ansi\n" + colorDiff(b.Body, style, profile) + b.Fence + "\n"
+	})
+}
+
+// kind is the kind of a line of a diff.
+type kind int
+
+const (
+	unchanged kind = iota
+	inserted
+	deleted
+	hunk
+	header
+)
+
+// lineKinds returns the kinds of the lines of a diff. File headers are told
+// apart from lines added or removed starting with ++ or -- by coming in
+// pairs.
+func lineKinds(lines []string) []kind {
+	kinds := make([]kind, len(lines))
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "),
+			strings.HasPrefix(l, "+++ ") && i > 0 && strings.HasPrefix(lines[i-1], "--- "),
+			strings.HasPrefix(l, "diff "), strings.HasPrefix(l, "index "):
+			kinds[i] = header
+		case strings.HasPrefix(l, "@@"):
+			kinds[i] = hunk
+		case strings.HasPrefix(l, "+"):
+			kinds[i] = inserted
+		case strings.HasPrefix(l, "-"):
+			kinds[i] = deleted
+		}
+	}
+	return kinds
+}
+
+// colorDiff colors the lines of a diff. Runs of removed lines followed by as
+// many added lines are compared line by line, to highlight the words that
+// changed.
+func colorDiff(body string, style Style, profile termenv.Profile) string {
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	kinds := lineKinds(lines)
+
+	colored := make([]string, len(lines))
+	for i := 0; i < len(lines); i++ {
+		switch kinds[i] {
+		case hunk:
+			colored[i] = render(lines[i], style.Hunk, profile)
+			continue
+		case header:
+			colored[i] = render(lines[i], style.Header, profile)
+			continue
+		case unchanged:
+			colored[i] = lines[i]
+			continue
+		}
+
+		// A run of removed lines and the added lines following it.
+		del := i
+		for del < len(lines) && kinds[del] == deleted {
+			del++
+		}
+		ins := del
+		for ins < len(lines) && kinds[ins] == inserted {
+			ins++
+		}
+		words := style.Words == nil || *style.Words
+		for j := i; j < ins; j++ {
+			lineStyle, wordStyle := style.Inserted, style.InsertedWord
+			if kinds[j] == deleted {
+				lineStyle, wordStyle = style.Deleted, style.DeletedWord
+			}
+			var changed []bool
+			if n := del - i; words && n == ins-del {
+				if kinds[j] == deleted {
+					changed, _ = changedWords(lines[j][1:], lines[j+n][1:])
+				} else {
+					_, changed = changedWords(lines[j-n][1:], lines[j][1:])
+				}
+			}
+			colored[j] = renderLine(lines[j], changed, lineStyle, overlay(lineStyle, wordStyle), profile)
+		}
+		i = max(ins, i+1) - 1
+	}
+	return strings.Join(colored, "\n") + "\n"
+}
+
+// renderLine renders a line added or removed, highlighting the changed
+// tokens of its content, after the + or - marker, if any.
+func renderLine(line string, changed []bool, lineStyle, wordStyle ansi.StylePrimitive, profile termenv.Profile) string {
+	if changed == nil {
+		return render(line, lineStyle, profile)
+	}
+	var b strings.Builder
+	tokens := append([]string{line[:1]}, tokenize(line[1:])...)
+	changed = append([]bool{false}, changed...)
+	for i := 0; i < len(tokens); {
+		j := i
+		var run strings.Builder
+		for j < len(tokens) && changed[j] == changed[i] {
+			run.WriteString(tokens[j])
+			j++
+		}
+		s := lineStyle
+		if changed[i] {
+			s = wordStyle
+		}
+		b.WriteString(render(run.String(), s, profile))
+		i = j
+	}
+	return b.String()
+}
+
+// overlay returns base with the attributes set in top.
+func overlay(base, top ansi.StylePrimitive) ansi.StylePrimitive {
+	if top.Color != nil {
+		base.Color = top.Color
+	}
+	if top.BackgroundColor != nil {
+		base.BackgroundColor = top.BackgroundColor
+	}
+	if top.Bold != nil {
+		base.Bold = top.Bold
+	}
+	if top.Italic != nil {
+		base.Italic = top.Italic
+	}
+	if top.Underline != nil {
+		base.Underline = top.Underline
+	}
+	if top.Inverse != nil {
+		base.Inverse = top.Inverse
+	}
+	if top.Faint != nil {
+		base.Faint = top.Faint
+	}
+	return base
+}
+
+// render renders s in the colors and attributes of a style element.
+func render(s string, p ansi.StylePrimitive, profile termenv.Profile) string {
+	if s == "" {
+		return s
+	}
+	out := profile.String(s)
+	if p.Color != nil {
+		out = out.Foreground(profile.Color(*p.Color))
+	}
+	if p.BackgroundColor != nil {
+		out = out.Background(profile.Color(*p.BackgroundColor))
+	}
+	if p.Bold != nil && *p.Bold {
+		out = out.Bold()
+	}
+	if p.Italic != nil && *p.Italic {
+		out = out.Italic()
+	}
+	if p.Underline != nil && *p.Underline {
+		out = out.Underline()
+	}
+	if p.Inverse != nil && *p.Inverse {
+		out = out.Reverse()
+	}
+	if p.Faint != nil && *p.Faint {
+		out = out.Faint()
+	}
+	return out.String()
+}
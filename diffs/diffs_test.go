@@ -0,0 +1,79 @@
+package diffs
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+const patch = "diff --git a/main.go b/main.go\n" +
+	"--- a/main.go\n" +
+	"+++ b/main.go\n" +
+	"@@ -1,3 +1,3 @@\n" +
+	" package main\n" +
+	"-func greet() { println(\"hello\") }\n" +
+	"+func greet() { println(\"hello, world\") }\n" +
+	"--- a removed line starting with dashes\n"
+
+func TestHighlight(t *testing.T) {
+	md := "# Change\n\n```diff\n" + patch + "```\n\n```go\nfunc main() {}\n```\n"
+	out := Highlight(md, DefaultStyle, termenv.ANSI256)
+	if !strings.HasPrefix(out, "# Change\n\n```ansi\n") || !strings.HasSuffix(out, "```\n\n```go\nfunc main() {}\n```\n") {
+		t.Fatalf("expected the diff to become an ansi fence, got:\n%s", out)
+	}
+	if got := xansi.Strip(out); got != "# Change\n\n```ansi\n"+patch+"```\n\n```go\nfunc main() {}\n```\n" {
+		t.Errorf("expected the text of the diff to be kept, got:\n%s", got)
+	}
+	if Highlight(md, DefaultStyle, termenv.Ascii) != md {
+		t.Error("expected diffs to be left as they are without colors")
+	}
+}
+
+func TestColorDiff(t *testing.T) {
+	lines := strings.Split(colorDiff(patch, DefaultStyle, termenv.ANSI256), "\n")
+	green := termenv.ANSI256.Color("#3FB950").Sequence(false)
+	red := termenv.ANSI256.Color("#F85149").Sequence(false)
+	if lines[4] != " package main" {
+		t.Errorf("expected context lines to be left as they are, got %q", lines[4])
+	}
+	if !strings.Contains(lines[5], red) || !strings.Contains(lines[6], green) || !strings.Contains(lines[7], red) {
+		t.Errorf("expected removed lines in red and added lines in green, got %q", lines[5:8])
+	}
+	// The changed words are reversed.
+	if !strings.Contains(lines[6], ";7m,") || strings.Contains(lines[5], ";7m") {
+		t.Errorf("expected the added words to be highlighted, got %q and %q", lines[5], lines[6])
+	}
+
+	off := false
+	style := DefaultStyle
+	style.Words = &off
+	if lines := strings.Split(colorDiff(patch, style, termenv.ANSI256), "\n"); strings.Contains(lines[6], ";7m") {
+		t.Errorf("expected words not to be highlighted, got %q", lines[6])
+	}
+}
+
+func TestChangedWords(t *testing.T) {
+	del, ins := changedWords("return a + b", "return a - b")
+	if !slices.Equal(del, []bool{false, false, false, false, true, false, false}) || !slices.Equal(ins, del) {
+		t.Errorf("expected the operator to change, got %v and %v", del, ins)
+	}
+	if del, ins := changedWords("entirely different", "nothing alike here"); del != nil || ins != nil {
+		t.Errorf("expected lines too different not to be compared, got %v and %v", del, ins)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	s, err := ParseStyle(DefaultStyle, []byte(`{"diff": {"inserted": {"color": "#00AA00"}, "words": false}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s.Inserted.Color != "#00AA00" || *s.Deleted.Color != "#F85149" || *s.Words {
+		t.Errorf("unexpected style %+v", s)
+	}
+	if *DefaultStyle.Inserted.Color != "#3FB950" || !*DefaultStyle.Words {
+		t.Error("expected the default style to be left as it was")
+	}
+}
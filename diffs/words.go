@@ -0,0 +1,94 @@
+package diffs
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Lines with more tokens than this aren't compared word by word.
+	maxTokens = 400
+	// Lines changed more than this fraction aren't highlighted word by
+	// word, as nearly everything would be.
+	maxChanged = 0.5
+)
+
+// tokenize splits a line into words, runs of spaces, and single other
+// characters.
+func tokenize(s string) []string {
+	var tokens []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		class := tokenClass(r)
+		end := size
+		for class != 0 && end < len(s) {
+			next, n := utf8.DecodeRuneInString(s[end:])
+			if tokenClass(next) != class {
+				break
+			}
+			end += n
+		}
+		tokens = append(tokens, s[:end])
+		s = s[end:]
+	}
+	return tokens
+}
+
+// tokenClass returns 1 for word characters, 2 for spaces, and 0 for the
+// rest, which make tokens of their own.
+func tokenClass(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 2
+	}
+	return 0
+}
+
+// changedWords compares a removed line with the added line replacing it,
+// and returns which of the tokens of each changed. It returns nil for both
+// when the lines are too long or too different for the changes to be worth
+// highlighting.
+func changedWords(removed, added string) (del, ins []bool) {
+	a, b := tokenize(removed), tokenize(added)
+	if len(a) > maxTokens || len(b) > maxTokens || len(a) == 0 || len(b) == 0 {
+		return nil, nil
+	}
+
+	// Longest common subsequence of the tokens.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	del, ins = make([]bool, len(a)), make([]bool, len(b))
+	var same, total int
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			same += 2 * len(a[i])
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			del[i] = true
+			i++
+		default:
+			ins[j] = true
+			j++
+		}
+	}
+	total = len(removed) + len(added)
+	if float64(total-same) > maxChanged*float64(total) {
+		return nil, nil
+	}
+	return del, ins
+}
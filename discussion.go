@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Comments are listed this many at a time.
+const commentsPerPage = 100
+
+// discussion is an issue or pull request on GitHub, or an issue or merge
+// request on GitLab, rendered with its comments.
+type discussion struct {
+	forge forge
+	// The repository, as owner/name, or a group path on GitLab.
+	project string
+	number  int
+	pull    bool
+	// Base URLs of the API and of the website.
+	api, web string
+}
+
+// parseDiscussionURL returns the issue or pull request a URL points to, with
+// or without its protocol, e.g. github.com/charmbracelet/glow/issues/1 or
+// gitlab.com/group/project/-/merge_requests/2. It reports false for anything
+// else.
+func parseDiscussionURL(arg string) (*discussion, bool) {
+	if !strings.Contains(arg, "://") {
+		arg = protoHTTPS + arg
+	}
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, false
+	}
+	path := strings.Trim(u.Path, "/")
+
+	switch u.Hostname() {
+	case githubURL.Hostname():
+		// owner/repo/issues/1, or owner/repo/pull/1 followed by a tab such
+		// as /files.
+		segments := strings.Split(path, "/")
+		if len(segments) < 4 || (segments[2] != "issues" && segments[2] != "pull") {
+			return nil, false
+		}
+		n, err := strconv.Atoi(segments[3])
+		if err != nil || n <= 0 {
+			return nil, false
+		}
+		return &discussion{
+			forge:   githubForge{},
+			project: segments[0] + "/" + segments[1],
+			number:  n,
+			pull:    segments[2] == "pull",
+			api:     "https://api." + githubURL.Hostname(),
+			web:     githubURL.String(),
+		}, true
+	case gitlabURL.Hostname():
+		// group/project/-/issues/1 or group/project/-/merge_requests/1,
+		// where groups can be nested.
+		project, rest, ok := strings.Cut(path, "/-/")
+		if !ok || !strings.Contains(project, "/") {
+			return nil, false
+		}
+		segments := strings.Split(rest, "/")
+		if len(segments) < 2 || (segments[0] != "issues" && segments[0] != "merge_requests") {
+			return nil, false
+		}
+		n, err := strconv.Atoi(segments[1])
+		if err != nil || n <= 0 {
+			return nil, false
+		}
+		return &discussion{
+			forge:   gitlabForge{},
+			project: project,
+			number:  n,
+			pull:    segments[0] == "merge_requests",
+			api:     gitlabURL.String() + "/api/v4",
+			web:     gitlabURL.String(),
+		}, true
+	}
+	return nil, false
+}
+
+// source fetches the discussion and composes it into a document.
+func (d *discussion) source(ctx context.Context) (*source, error) {
+	var (
+		t   *thread
+		err error
+	)
+	switch d.forge.(type) {
+	case githubForge:
+		t, err = d.fetchGitHub(ctx)
+	case gitlabForge:
+		t, err = d.fetchGitLab(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &source{reader: io.NopCloser(strings.NewReader(t.markdown())), URL: t.url, markdown: true}, nil
+}
+
+// thread is a discussion as it is rendered: what opened it, then the
+// comments in the order they were posted.
+type thread struct {
+	title  string
+	number int
+	// "issue", "pull request" or "merge request".
+	kind  string
+	state string
+	// The discussion's page, and the repository's name and page.
+	url, project, projectURL string
+	labels                   []string
+	// For pull and merge requests, the branches to merge.
+	head, base string
+	opened     comment
+	comments   []comment
+}
+
+// comment is a post of a discussion.
+type comment struct {
+	author  string
+	created time.Time
+	body    string
+}
+
+// markdown composes the document of a thread.
+func (t *thread) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s #%d\n\n", t.title, t.number)
+	fmt.Fprintf(&b, "**%s** · %s opened by **@%s** on %s in [%s](%s)\n",
+		capitalize(t.state), t.kind, t.opened.author, formatCommentTime(t.opened.created), t.project, t.projectURL)
+	if t.head != "" && t.base != "" {
+		fmt.Fprintf(&b, "\nMerging `%s` into `%s`\n", t.head, t.base)
+	}
+	if len(t.labels) > 0 {
+		fmt.Fprintf(&b, "\nLabels: `%s`\n", strings.Join(t.labels, "` `"))
+	}
+
+	b.WriteString("\n---\n\n")
+	if body := normalizeCommentBody(t.opened.body); body != "" {
+		b.WriteString(body + "\n")
+	} else {
+		b.WriteString("*No description provided.*\n")
+	}
+
+	if len(t.comments) > 0 {
+		fmt.Fprintf(&b, "\n## Comments (%d)\n", len(t.comments))
+	}
+	for _, c := range t.comments {
+		fmt.Fprintf(&b, "\n### @%s · %s\n\n", c.author, formatCommentTime(c.created))
+		if body := normalizeCommentBody(c.body); body != "" {
+			b.WriteString(body + "\n")
+		}
+	}
+	return b.String()
+}
+
+// normalizeCommentBody trims a post and turns its CRLF line endings, common
+// in text typed in browsers, into LF.
+func normalizeCommentBody(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// formatCommentTime formats the time a post was made, in the time zone the
+// API gave it in.
+func formatCommentTime(t time.Time) string {
+	return t.Format("January 2, 2006 15:04 MST")
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// githubUser is the author of a post on GitHub.
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// fetchGitHub fetches an issue or pull request and its comments from the
+// GitHub API. Pull requests are issues to the API, with the branches they
+// merge looked up separately.
+func (d *discussion) fetchGitHub(ctx context.Context) (*thread, error) {
+	base := fmt.Sprintf("%s/repos/%s/issues/%d", d.api, d.project, d.number)
+	var issue struct {
+		Title       string     `json:"title"`
+		Body        string     `json:"body"`
+		State       string     `json:"state"`
+		StateReason string     `json:"state_reason"`
+		HTMLURL     string     `json:"html_url"`
+		User        githubUser `json:"user"`
+		CreatedAt   time.Time  `json:"created_at"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		PullRequest *struct {
+			MergedAt *time.Time `json:"merged_at"`
+		} `json:"pull_request"`
+	}
+	if _, err := fetchJSON(ctx, base, &issue); err != nil {
+		return nil, fmt.Errorf("unable to get GitHub issue: %w", err)
+	}
+
+	t := &thread{
+		title:      issue.Title,
+		number:     d.number,
+		kind:       "issue",
+		state:      issue.State,
+		url:        issue.HTMLURL,
+		project:    d.project,
+		projectURL: d.web + "/" + d.project,
+		opened:     comment{author: issue.User.Login, created: issue.CreatedAt, body: issue.Body},
+	}
+	for _, l := range issue.Labels {
+		t.labels = append(t.labels, l.Name)
+	}
+	if issue.StateReason == "not_planned" {
+		t.state = "closed as not planned"
+	}
+	if issue.PullRequest != nil {
+		t.kind = "pull request"
+		if issue.PullRequest.MergedAt != nil {
+			t.state = "merged"
+		}
+		var pull struct {
+			Draft bool `json:"draft"`
+			Head  struct {
+				Label string `json:"label"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		}
+		if _, err := fetchJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls/%d", d.api, d.project, d.number), &pull); err != nil {
+			return nil, fmt.Errorf("unable to get GitHub pull request: %w", err)
+		}
+		t.head, t.base = pull.Head.Label, pull.Base.Ref
+		if pull.Draft && t.state == "open" {
+			t.state = "draft"
+		}
+	}
+
+	// Comments are listed a page at a time, until a page isn't full.
+	for page := 1; ; page++ {
+		var comments []struct {
+			Body      string     `json:"body"`
+			User      githubUser `json:"user"`
+			CreatedAt time.Time  `json:"created_at"`
+		}
+		u := fmt.Sprintf("%s/comments?per_page=%d&page=%d", base, commentsPerPage, page)
+		if _, err := fetchJSON(ctx, u, &comments); err != nil {
+			return nil, fmt.Errorf("unable to get GitHub comments: %w", err)
+		}
+		for _, c := range comments {
+			t.comments = append(t.comments, comment{author: c.User.Login, created: c.CreatedAt, body: c.Body})
+		}
+		if len(comments) < commentsPerPage {
+			break
+		}
+	}
+	return t, nil
+}
+
+// gitlabUser is the author of a post on GitLab.
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+// fetchGitLab fetches an issue or merge request and its comments from the
+// GitLab API, leaving out the notes GitLab adds itself, e.g. about labels
+// changing.
+func (d *discussion) fetchGitLab(ctx context.Context) (*thread, error) {
+	kind, path := "issue", "issues"
+	if d.pull {
+		kind, path = "merge request", "merge_requests"
+	}
+	base := fmt.Sprintf("%s/projects/%s/%s/%d", d.api, url.PathEscape(d.project), path, d.number)
+	var issue struct {
+		Title        string     `json:"title"`
+		Description  string     `json:"description"`
+		State        string     `json:"state"`
+		WebURL       string     `json:"web_url"`
+		Author       gitlabUser `json:"author"`
+		CreatedAt    time.Time  `json:"created_at"`
+		Labels       []string   `json:"labels"`
+		Draft        bool       `json:"draft"`
+		SourceBranch string     `json:"source_branch"`
+		TargetBranch string     `json:"target_branch"`
+	}
+	if _, err := fetchJSON(ctx, base, &issue); err != nil {
+		return nil, fmt.Errorf("unable to get GitLab %s: %w", kind, err)
+	}
+
+	t := &thread{
+		title:      issue.Title,
+		number:     d.number,
+		kind:       kind,
+		state:      issue.State,
+		url:        issue.WebURL,
+		project:    d.project,
+		projectURL: d.web + "/" + d.project,
+		labels:     issue.Labels,
+		head:       issue.SourceBranch,
+		base:       issue.TargetBranch,
+		opened:     comment{author: issue.Author.Username, created: issue.CreatedAt, body: issue.Description},
+	}
+	// GitLab's states are opened, closed, merged and locked.
+	if t.state == "opened" {
+		t.state = "open"
+	}
+	if issue.Draft && t.state == "open" {
+		t.state = "draft"
+	}
+
+	for page := "1"; page != ""; {
+		var notes []struct {
+			Body      string     `json:"body"`
+			Author    gitlabUser `json:"author"`
+			CreatedAt time.Time  `json:"created_at"`
+			System    bool       `json:"system"`
+		}
+		u := fmt.Sprintf("%s/notes?sort=asc&order_by=created_at&per_page=%d&page=%s", base, commentsPerPage, page)
+		header, err := fetchJSON(ctx, u, &notes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get GitLab comments: %w", err)
+		}
+		for _, n := range notes {
+			if !n.System {
+				t.comments = append(t.comments, comment{author: n.Author.Username, created: n.CreatedAt, body: n.Body})
+			}
+		}
+		page = header.Get("X-Next-Page")
+	}
+	return t, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseDiscussionURL(t *testing.T) {
+	for arg, want := range map[string]string{
+		"github.com/charmbracelet/glow/issues/12":                 "github charmbracelet/glow 12 false",
+		"https://github.com/charmbracelet/glow/pull/34/files":     "github charmbracelet/glow 34 true",
+		"gitlab.com/gitlab-org/cli/-/issues/5":                    "gitlab gitlab-org/cli 5 false",
+		"https://gitlab.com/group/sub/project/-/merge_requests/6": "gitlab group/sub/project 6 true",
+		"github.com/charmbracelet/glow":                           "",
+		"github.com/charmbracelet/glow/issues":                    "",
+		"github.com/charmbracelet/glow/issues/new":                "",
+		"gitlab.com/gitlab-org/cli/-/blob/main/README.md":         "",
+		"example.com/o/r/issues/1":                                "",
+	} {
+		var got string
+		if d, ok := parseDiscussionURL(arg); ok {
+			name := "github"
+			if _, ok := d.forge.(gitlabForge); ok {
+				name = "gitlab"
+			}
+			got = fmt.Sprintf("%s %s %d %t", name, d.project, d.number, d.pull)
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", arg, want, got)
+		}
+	}
+}
+
+func TestGitHubDiscussion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/issues/7":
+			_, _ = io.WriteString(w, `{
+				"title": "Add a flag",
+				"body": "Please.\r\nThanks!",
+				"state": "open",
+				"html_url": "https://github.com/o/r/pull/7",
+				"user": {"login": "alice"},
+				"created_at": "2024-01-02T15:04:05Z",
+				"labels": [{"name": "enhancement"}],
+				"pull_request": {"merged_at": "2024-01-05T10:00:00Z"}
+			}`)
+		case "/repos/o/r/pulls/7":
+			_, _ = io.WriteString(w, `{"head": {"label": "alice:flag"}, "base": {"ref": "main"}}`)
+		case "/repos/o/r/issues/7/comments":
+			_, _ = io.WriteString(w, `[{"body": "LGTM", "user": {"login": "bob"}, "created_at": "2024-01-03T09:30:00Z"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d, _ := parseDiscussionURL("github.com/o/r/pull/7")
+	d.api = srv.URL
+	src, err := d.source(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(src.reader)
+	if !src.isMarkdown() || src.URL != "https://github.com/o/r/pull/7" {
+		t.Errorf("expected a markdown document named after the pull request, got %q", src.URL)
+	}
+	for _, want := range []string{
+		"# Add a flag #7\n",
+		"**Merged** · pull request opened by **@alice** on January 2, 2024 15:04 UTC in [o/r](https://github.com/o/r)",
+		"Merging `alice:flag` into `main`",
+		"Labels: `enhancement`",
+		"Please.\nThanks!\n",
+		"## Comments (1)\n\n### @bob · January 3, 2024 09:30 UTC\n\nLGTM\n",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %q in:\n%s", want, b)
+		}
+	}
+}
+
+func TestGitLabDiscussion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/projects/g%2Fp/issues/3":
+			_, _ = io.WriteString(w, `{
+				"title": "Crash",
+				"description": "",
+				"state": "opened",
+				"web_url": "https://gitlab.com/g/p/-/issues/3",
+				"author": {"username": "carol"},
+				"created_at": "2024-02-01T08:00:00Z"
+			}`)
+		case "/projects/g%2Fp/issues/3/notes":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				_, _ = io.WriteString(w, `[{"body": "added ~bug label", "author": {"username": "carol"}, "system": true}]`)
+				return
+			}
+			_, _ = io.WriteString(w, `[{"body": "Fixed", "author": {"username": "dave"}, "created_at": "2024-02-02T08:00:00Z"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d, _ := parseDiscussionURL("gitlab.com/g/p/-/issues/3")
+	d.api = srv.URL
+	src, err := d.source(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(src.reader)
+	for _, want := range []string{
+		"**Open** · issue opened by **@carol**",
+		"*No description provided.*",
+		"## Comments (1)\n\n### @dave · February 2, 2024 08:00 UTC\n\nFixed\n",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %q in:\n%s", want, b)
+		}
+	}
+	if strings.Contains(string(b), "added ~bug label") {
+		t.Errorf("expected system notes to be left out:\n%s", b)
+	}
+}
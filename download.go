@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/dustin/go-humanize"
+	"golang.org/x/term"
+)
+
+// Downloads finishing before this don't show a loader at all.
+const loaderDelay = 200 * time.Millisecond
+
+// downloadProgress is whether downloads show a loader, which they don't while
+// several documents are downloaded at once.
+var downloadProgress = true
+
+// progressReader reports the progress of a download on stderr while its body
+// is read.
+type progressReader struct {
+	io.ReadCloser
+
+	total int64 // -1 if unknown
+	read  atomic.Int64
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// downloadBody returns the body of resp, showing a loader while it's read,
+// unless spinners are disabled. The loader is only shown if stderr is a
+// terminal.
+func downloadBody(resp *http.Response) io.ReadCloser {
+	if spinnerName == spinnerNone || !downloadProgress || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return resp.Body
+	}
+
+	r := &progressReader{
+		ReadCloser: resp.Body,
+		total:      resp.ContentLength,
+		done:       make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go r.show(os.Stderr)
+	return r
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read.Add(int64(n))
+	if err != nil {
+		r.finish()
+	}
+	return n, err //nolint:wrapcheck
+}
+
+func (r *progressReader) Close() error {
+	r.finish()
+	return r.ReadCloser.Close() //nolint:wrapcheck
+}
+
+// finish stops the loader and waits for it to be cleared.
+func (r *progressReader) finish() {
+	r.once.Do(func() { close(r.done) })
+	r.wg.Wait()
+}
+
+// show draws a progress bar, or the configured spinner when the size of the
+// download is unknown, until the download finishes.
+func (r *progressReader) show(w io.Writer) {
+	defer r.wg.Done()
+
+	var (
+		bar    = progress.New(progress.WithDefaultGradient(), progress.WithWidth(40))
+		def, _ = stream.LookupSpinner(GetSpinnerType(spinnerName))
+		style  = stream.SpinnerStyle.Foreground(lipgloss.Color(spinnerColorStr))
+		frame  int
+		shown  bool
+		start  = time.Now()
+		ticker = time.NewTicker(def.Interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			if shown {
+				fmt.Fprint(w, "\r\033[K")
+			}
+			return
+		case <-ticker.C:
+		}
+		if time.Since(start) < loaderDelay {
+			continue
+		}
+		shown = true
+
+		read := r.read.Load()
+		if r.total > 0 {
+			percent := min(float64(read)/float64(r.total), 1)
+			fmt.Fprintf(w, "\r\033[K%s %s / %s", bar.ViewAs(percent),
+				humanize.Bytes(uint64(read)), humanize.Bytes(uint64(r.total))) //nolint:gosec
+			continue
+		}
+		frame = (frame + 1) % len(def.Frames)
+		fmt.Fprintf(w, "\r\033[K%s %s", style.Render(def.Frames[frame]), humanize.Bytes(uint64(read))) //nolint:gosec
+	}
+}
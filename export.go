@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var exportTemplate = template.Must(template.New("export").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+main { margin: 0 auto; padding: 1em 2em; max-width: 50em; }
+{{.CSS}}
+</style>
+</head>
+<body>
+<main>{{.Body}}</main>
+</body>
+</html>
+`))
+
+// exportDocument renders a document of the TUI for a file, as html, text or
+// ansi: HTML is a page of its own, styled like the preview of glow serve,
+// while text and ansi are what the CLI writes with --format. Documents are
+// read from path, which is empty for those that aren't files.
+func exportDocument(path, body, format string) (string, error) {
+	src := &source{URL: path, markdown: path == ""}
+	if format == formatHTML {
+		return exportHTML(src, body)
+	}
+	if format != formatANSI && format != formatText {
+		return "", fmt.Errorf("invalid export format %q, expected one of: html, text, ansi", format)
+	}
+
+	md := prepareMarkdown(src, []byte(body))
+	return renderFormatAs(format, md, func() (string, error) {
+		if !src.isMarkdown() {
+			return renderCode(src.URL, body)
+		}
+		r, _, err := setupRenderer(src)
+		if err != nil {
+			return "", err
+		}
+		out, err := renderDocument(r, md)
+		if err != nil {
+			return "", fmt.Errorf("unable to render markdown: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// exportHTML converts a document into an HTML page styled with the current
+// style. Code files are shown in a code block.
+func exportHTML(src *source, body string) (string, error) {
+	content := string(utils.RemoveFrontmatter([]byte(body)))
+	if !src.isMarkdown() {
+		content = utils.WrapCodeBlock(body, filepath.Ext(src.URL))
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	var converted bytes.Buffer
+	if err := md.Convert([]byte(content), &converted); err != nil {
+		return "", fmt.Errorf("unable to convert markdown: %w", err)
+	}
+	styleConfig, err := utils.StyleConfig(style)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	title := "Pasted markdown"
+	if src.URL != "" {
+		title = filepath.Base(src.URL)
+	}
+	var b bytes.Buffer
+	err = exportTemplate.Execute(&b, page{
+		Title: title,
+		CSS:   template.CSS(styleCSS(styleConfig)), //nolint:gosec
+		Body:  template.HTML(converted.String()),   //nolint:gosec
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to render page: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,68 @@
+// Package fences finds the fenced code blocks of markdown documents, for
+// transforms that rewrite some of them before the document is rendered.
+package fences
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The opening fence, and the language of the info string.
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^ \t`{]*)")
+
+// Block is a fenced code block, as written.
+type Block struct {
+	// Run of backticks or tildes of the opening fence, e.g. ```.
+	Fence string
+	// First word of the info string, e.g. go.
+	Language string
+	// Opening line, the lines of code and the closing line, with their line
+	// endings. Close is empty for blocks that run to the end of the
+	// document.
+	Open, Body, Close string
+}
+
+// String returns the block as written.
+func (b Block) String() string {
+	return b.Open + b.Body + b.Close
+}
+
+// Replace replaces each fenced code block of a markdown document with what
+// replace returns for it. Blocks that aren't closed run to the end of the
+// document.
+func Replace(markdown string, replace func(Block) string) string {
+	if !strings.Contains(markdown, "```") && !strings.Contains(markdown, "~~~") {
+		return markdown
+	}
+
+	var out strings.Builder
+	lines := strings.SplitAfter(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := fencePattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r\n"))
+		if m == nil {
+			out.WriteString(lines[i])
+			continue
+		}
+
+		// Find the closing fence, or the end of the document.
+		end := i + 1
+		for ; end < len(lines); end++ {
+			closing := strings.TrimSpace(lines[end])
+			if strings.HasPrefix(closing, m[1]) && strings.Trim(closing, m[1][:1]) == "" {
+				break
+			}
+		}
+		b := Block{
+			Fence:    m[1],
+			Language: m[2],
+			Open:     lines[i],
+			Body:     strings.Join(lines[i+1:min(end, len(lines))], ""),
+		}
+		if end < len(lines) {
+			b.Close = lines[end]
+		}
+		out.WriteString(replace(b))
+		i = end
+	}
+	return out.String()
+}
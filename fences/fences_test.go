@@ -0,0 +1,27 @@
+package fences
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	md := "Text\n\n````go {.x}\nfunc f() {}\n```\n````\n\n~~~\nopen\n"
+	var blocks []Block
+	out := Replace(md, func(b Block) string {
+		blocks = append(blocks, b)
+		return strings.ToUpper(b.String())
+	})
+	if want := "Text\n\n````GO {.X}\nFUNC F() {}\n```\n````\n\n~~~\nOPEN\n"; out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	if b := blocks[0]; b.Fence != "````" || b.Language != "go" || b.Body != "func f() {}\n```\n" || b.Close != "````\n" {
+		t.Errorf("unexpected block %+v", b)
+	}
+	if b := blocks[1]; b.Language != "" || b.Body != "open\n" || b.Close != "" {
+		t.Errorf("expected a block running to the end, got %+v", b)
+	}
+}
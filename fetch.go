@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/htmlmd"
+	"github.com/douglas-larocca/glow/v2/httpcache"
+	"github.com/dustin/go-humanize"
+	gap "github.com/muesli/go-app-paths"
+)
+
+// Defaults of the HTTP client used to fetch remote documents.
+const (
+	defaultHTTPTimeout = 30 * time.Second
+	defaultHTTPRetries = 2
+	defaultMaxDownload = "10MB"
+
+	maxRedirects = 10
+
+	// Delay before the first retry, doubled for every further one.
+	retryBackoff = 500 * time.Millisecond
+	// Longest wait honored from a Retry-After header.
+	maxRetryAfter = 30 * time.Second
+)
+
+var (
+	httpTimeout    = defaultHTTPTimeout
+	httpRetries    = defaultHTTPRetries
+	maxDownloadStr = defaultMaxDownload
+	maxDownload, _ = parseMaxDownload(defaultMaxDownload)
+)
+
+var (
+	// Cache of fetched documents, if enabled, and whether documents are
+	// only read from it.
+	httpCache *httpcache.Store
+	offline   bool
+)
+
+var (
+	errTooLarge       = errors.New("download exceeds the maximum size")
+	errHTTPSDowngrade = errors.New("refusing to follow redirect from https to http")
+	errNotCached      = errors.New("not available offline")
+)
+
+// parseMaxDownload parses the value given for --max-download. Zero means no
+// limit.
+func parseMaxDownload(s string) (int64, error) {
+	n, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max download size %q: %w", s, err)
+	}
+	return int64(n), nil //nolint:gosec
+}
+
+// openHTTPCache returns the cache of fetched documents in the cache
+// directory, or nil if there's none.
+func openHTTPCache() *httpcache.Store {
+	dir, err := gap.NewScope(gap.User, "glow").CacheDir()
+	if err != nil {
+		log.Debug("unable to get cache dir", "error", err)
+		return nil
+	}
+	return httpcache.Open(filepath.Join(dir, "http"))
+}
+
+// newHTTPClient returns the client remote documents are fetched with. Proxies
+// are taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: httpTimeout, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Timeout: httpTimeout,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: httpTimeout,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme == "http" {
+				return errHTTPSDowngrade
+			}
+			// Tokens only follow redirects to the hosts they're for.
+			req.Header.Del("Authorization")
+			req.Header.Del("PRIVATE-TOKEN")
+			authorize(req)
+			return nil
+		},
+	}
+}
+
+// fetch GETs a URL, retrying with backoff on network errors and on responses
+// that suggest trying again later. The download is canceled by ctx or by
+// Ctrl-C until the body is closed, and reading more than the maximum download
+// size fails. The caller must close the body.
+//
+// With the cache enabled, documents fetched before are revalidated, and
+// served from the cache if unchanged or if the server can't be reached.
+// Offline, documents are only served from the cache. The security policy
+// can rule out the network altogether.
+func fetch(ctx context.Context, url string) (*http.Response, error) {
+	if !security.network {
+		return nil, disallowed("network access is", "allowNetwork")
+	}
+	if offline {
+		return cachedResponse(url)
+	}
+	var cached *httpcache.Entry
+	if httpCache != nil {
+		if e, ok := httpCache.Get(url); ok {
+			cached = &e
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	client := newHTTPClient()
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = get(ctx, client, url, cached)
+		if attempt >= httpRetries || !retryable(resp, err) {
+			break
+		}
+
+		wait := retryBackoff << attempt
+		if resp != nil {
+			if s, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil {
+				wait = min(time.Duration(s)*time.Second, maxRetryAfter)
+			}
+			_ = resp.Body.Close()
+		}
+		log.Debug("retrying request", "url", url, "attempt", attempt+1, "wait", wait, "err", err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			stop()
+			return nil, fmt.Errorf("download canceled: %w", ctx.Err())
+		}
+	}
+	if err != nil {
+		// Stopping cancels the context, so check whether it was canceled
+		// before.
+		canceled := ctx.Err()
+		stop()
+		if canceled != nil {
+			return nil, fmt.Errorf("download canceled: %w", canceled)
+		}
+		if cached != nil {
+			log.Warn("unable to get url, using cached copy", "url", url, "fetched", cached.Fetched, "error", err)
+			return cachedResponse(url)
+		}
+		return nil, fmt.Errorf("unable to get url: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		_ = resp.Body.Close()
+		stop()
+		if err := httpCache.Touch(url); err != nil {
+			log.Debug("unable to update cache entry", "url", url, "error", err)
+		}
+		return cachedResponse(url)
+	}
+
+	if maxDownload > 0 && resp.ContentLength > maxDownload {
+		_ = resp.Body.Close()
+		stop()
+		return nil, fmt.Errorf("%w of %s (%s)", errTooLarge, humanize.Bytes(uint64(maxDownload)), humanize.Bytes(uint64(resp.ContentLength))) //nolint:gosec
+	}
+	resp.Body = &fetchBody{ReadCloser: resp.Body, ctx: ctx, stop: stop, limit: maxDownload}
+	if httpCache != nil && resp.StatusCode == http.StatusOK {
+		resp.Body = &cachingBody{ReadCloser: resp.Body, entry: httpcache.FromResponse(url, resp)}
+	}
+	return resp, nil
+}
+
+// get sends a request for a URL, asking for it only if it changed when a
+// copy is cached.
+func get(ctx context.Context, client *http.Client, url string, cached *httpcache.Entry) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "glow/"+Version)
+	authorize(req)
+	if cached != nil {
+		cached.Revalidate(req.Header)
+	}
+	return client.Do(req) //nolint:wrapcheck
+}
+
+// authorize adds the token for the GitHub or GitLab API from the environment
+// to a request for it, to read private repositories and raise rate limits.
+// Tokens are only sent over HTTPS.
+func authorize(req *http.Request) {
+	if req.URL.Scheme != "https" {
+		return
+	}
+	switch host := req.URL.Hostname(); {
+	case host == "api."+githubURL.Hostname():
+		if token := cmp.Or(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case host == gitlabURL.Hostname() && strings.HasPrefix(req.URL.Path, "/api/"):
+		if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	}
+}
+
+// cachedResponse returns the copy of a URL in the cache as a response.
+func cachedResponse(url string) (*http.Response, error) {
+	if httpCache == nil {
+		return nil, fmt.Errorf("%w: %s", errNotCached, url)
+	}
+	e, ok := httpCache.Get(url)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNotCached, url)
+	}
+	f, err := httpCache.Body(url)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	var size int64 = -1
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil) //nolint:noctx
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	log.Debug("using cached copy", "url", url, "fetched", e.Fetched)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {e.ContentType}},
+		Body:          f,
+		ContentLength: size,
+		Request:       req,
+	}, nil
+}
+
+// retryable reports whether a request is worth trying again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// fetchBody is the body of a fetched response. It enforces the maximum
+// download size and stops listening for Ctrl-C once closed.
+type fetchBody struct {
+	io.ReadCloser
+	ctx   context.Context
+	stop  context.CancelFunc
+	limit int64 // 0 if unlimited
+	read  int64
+}
+
+func (b *fetchBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.limit > 0 && b.read > b.limit {
+		return n, fmt.Errorf("%w of %s", errTooLarge, humanize.Bytes(uint64(b.limit))) //nolint:gosec
+	}
+	if err != nil && b.ctx.Err() != nil {
+		return n, fmt.Errorf("download canceled: %w", b.ctx.Err())
+	}
+	return n, err //nolint:wrapcheck
+}
+
+func (b *fetchBody) Close() error {
+	b.stop()
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
+
+// cachingBody caches the body of a response once it's read in full.
+type cachingBody struct {
+	io.ReadCloser
+	entry httpcache.Entry
+	buf   bytes.Buffer
+	done  bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if errors.Is(err, io.EOF) && !b.done {
+		b.done = true
+		if err := httpCache.Put(b.entry, b.buf.Bytes()); err != nil {
+			log.Debug("unable to cache document", "url", b.entry.URL, "error", err)
+		}
+	}
+	return n, err //nolint:wrapcheck
+}
+
+// Media types of text documents that aren't text/*.
+var textMediaTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"application/toml":       true,
+	"application/x-sh":       true,
+	"application/x-yaml":     true,
+	"application/xml":        true,
+	"application/yaml":       true,
+}
+
+// fetchedSource returns the source of a fetched document, depending on its
+// Content-Type: HTML pages are converted to markdown, text is rendered as
+// is, and binary files are rejected. Documents served without a useful type,
+// like application/octet-stream, are sniffed.
+func fetchedSource(resp *http.Response, u string) (*source, error) {
+	return fetchedPage(resp, u, htmlmd.Convert)
+}
+
+// fetchedPage is like fetchedSource, converting HTML pages with convert.
+func fetchedPage(resp *http.Response, u string, convert func(io.Reader, string, *url.URL) (string, error)) (*source, error) {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	body := bufio.NewReader(resp.Body)
+	if !strings.HasPrefix(mediaType, "text/") && !textMediaTypes[mediaType] &&
+		mediaType != "application/xhtml+xml" {
+		head, _ := body.Peek(512)
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+		if !strings.HasPrefix(mediaType, "text/") {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("%s is not a text document (%s)", u, cmp.Or(contentType, mediaType))
+		}
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}
+
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return &source{reader: downloadBody(resp), URL: u}, nil
+	}
+
+	r := downloadBody(resp)
+	defer r.Close() //nolint:errcheck
+	md, err := convert(r, contentType, resp.Request.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert page: %w", err)
+	}
+	log.Debug("converted html page to markdown", "url", u)
+	return &source{reader: io.NopCloser(strings.NewReader(md)), URL: u, markdown: true}, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/douglas-larocca/glow/v2/httpcache"
+)
+
+func TestFetch(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case r.URL.Path == "/flaky" && requests == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/large":
+			w.Header().Set("Content-Length", "2000")
+			_, _ = io.WriteString(w, strings.Repeat("#", 2000))
+		case r.URL.Path == "/stream":
+			// No Content-Length, so the size is only known while reading.
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, strings.Repeat("#", 2000))
+		default:
+			_, _ = io.WriteString(w, "# Hello")
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := fetch(context.Background(), srv.URL+"/flaky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil || string(body) != "# Hello" || requests != 2 {
+		t.Errorf("expected the request to be retried, got %q after %d requests (%v)", body, requests, err)
+	}
+
+	defer func(limit int64) { maxDownload = limit }(maxDownload)
+	maxDownload = 1000
+
+	if _, err := fetch(context.Background(), srv.URL+"/large"); !errors.Is(err, errTooLarge) {
+		t.Errorf("expected the download to be refused, got %v", err)
+	}
+
+	resp, err = fetch(context.Background(), srv.URL+"/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !errors.Is(err, errTooLarge) {
+		t.Errorf("expected the download to be cut off, got %v", err)
+	}
+}
+
+func TestFetchedSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = io.WriteString(w, `<title>Page</title><p>See <a href="docs">the docs</a></p>`)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, "\x89PNG\r\n\x1a\n\x00\x00")
+		case "/blob":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = io.WriteString(w, "# Hello")
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, "<b>not html</b>")
+		}
+	}))
+	defer srv.Close()
+
+	read := func(path string) (*source, string, error) {
+		resp, err := fetch(context.Background(), srv.URL+path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := fetchedSource(resp, srv.URL+path)
+		if err != nil {
+			return nil, "", err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		b, err := io.ReadAll(src.reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src, string(b), nil
+	}
+
+	src, md, err := read("/page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Page\n\nSee [the docs](" + srv.URL + "/docs)\n"; md != want || !src.isMarkdown() {
+		t.Errorf("expected the page to be converted to %q, got %q", want, md)
+	}
+
+	if _, md, err := read("/plain"); err != nil || md != "<b>not html</b>" {
+		t.Errorf("expected text to be kept, got %q (%v)", md, err)
+	}
+	if _, md, err := read("/blob"); err != nil || md != "# Hello" {
+		t.Errorf("expected text served as binary to be kept, got %q (%v)", md, err)
+	}
+	if _, _, err := read("/image"); err == nil || !strings.Contains(err.Error(), "not a text document") {
+		t.Errorf("expected binary files to be rejected, got %v", err)
+	}
+}
+
+func TestFetchCache(t *testing.T) {
+	var full, revalidated int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/markdown")
+		_, _ = io.WriteString(w, "# Cached")
+	}))
+	defer srv.Close()
+
+	defer func() { httpCache, offline = nil, false }()
+	httpCache = httpcache.Open(t.TempDir())
+
+	read := func(u string) (string, error) {
+		resp, err := fetch(context.Background(), u)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	for range 2 {
+		if body, err := read(srv.URL + "/doc.md"); err != nil || body != "# Cached" {
+			t.Fatalf("expected the document, got %q (%v)", body, err)
+		}
+	}
+	if full != 1 || revalidated != 1 {
+		t.Errorf("expected the document to be revalidated, got %d downloads and %d revalidations", full, revalidated)
+	}
+
+	offline = true
+	if body, err := read(srv.URL + "/doc.md"); err != nil || body != "# Cached" {
+		t.Errorf("expected the cached document offline, got %q (%v)", body, err)
+	}
+	if _, err := read(srv.URL + "/other.md"); !errors.Is(err, errNotCached) {
+		t.Errorf("expected uncached documents to be unavailable offline, got %v", err)
+	}
+	if full+revalidated != 2 {
+		t.Errorf("expected no requests offline, got %d", full+revalidated-2)
+	}
+}
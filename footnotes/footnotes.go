@@ -0,0 +1,216 @@
+// Package footnotes renders the footnotes of markdown documents, which the
+// terminal renderer leaves as they're written: references become superscript
+// markers, and definitions are collected at the end of the sections
+// referencing them.
+package footnotes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// [^1]: text, [^note]: text.
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]?(.*)$`)
+	referencePattern  = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	headingPattern    = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+	fencePattern      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	codePattern       = regexp.MustCompile("`+")
+)
+
+// Superscript digits, by value.
+const superscripts = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+
+// Note is a footnote of a document.
+type Note struct {
+	// Number of the footnote, in the order footnotes are first referenced.
+	Number int
+	Label  string
+	Text   string
+}
+
+// Marker returns the superscript marker of the footnote, e.g. ¹².
+func (n Note) Marker() string {
+	return Marker(n.Number)
+}
+
+// Marker returns the superscript marker of footnote n.
+func Marker(n int) string {
+	var b strings.Builder
+	for _, d := range strconv.Itoa(n) {
+		b.WriteString(string([]rune(superscripts)[d-'0']))
+	}
+	return b.String()
+}
+
+// Markers returns the numbers of the footnote markers in text.
+func Markers(text string) []int {
+	var (
+		numbers []int
+		n       = -1
+	)
+	for _, r := range text + " " {
+		if d := strings.IndexRune(superscripts, r); d >= 0 {
+			n = max(n, 0)*10 + len([]rune(superscripts[:d]))
+			continue
+		}
+		if n > 0 {
+			numbers = append(numbers, n)
+		}
+		n = -1
+	}
+	return numbers
+}
+
+// Transform replaces the footnote references of a document, outside of code,
+// with superscript markers numbered in the order they're referenced, and
+// moves the definitions to the end of the section referencing them first.
+// References to footnotes that aren't defined are left as they are, and
+// definitions that aren't referenced are left out. It returns the document
+// and its footnotes.
+func Transform(md string) (string, []Note) {
+	if !strings.Contains(md, "[^") {
+		return md, nil
+	}
+	lines, code, defs := definitions(strings.Split(md, "\n"))
+	if len(defs) == 0 {
+		return md, nil
+	}
+
+	var (
+		out     []string
+		notes   []Note
+		pending []Note
+		numbers = map[string]int{}
+	)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		for i, n := range pending {
+			line := n.Marker() + " " + n.Text
+			if i < len(pending)-1 {
+				// Hard line break.
+				line += "  "
+			}
+			out = append(out, line)
+		}
+		out = append(out, "")
+		pending = nil
+	}
+
+	for i, line := range lines {
+		if code[i] {
+			out = append(out, line)
+			continue
+		}
+		if headingPattern.MatchString(line) {
+			flush()
+			out = append(out, line)
+			continue
+		}
+		out = append(out, outsideCode(line, func(s string) string {
+			return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+				label := strings.ToLower(ref[2 : len(ref)-1])
+				text, ok := defs[label]
+				if !ok {
+					return ref
+				}
+				n, ok := numbers[label]
+				if !ok {
+					n = len(notes) + 1
+					numbers[label] = n
+					note := Note{n, label, text}
+					notes = append(notes, note)
+					pending = append(pending, note)
+				}
+				return Marker(n)
+			})
+		}))
+	}
+	flush()
+	return strings.Join(out, "\n"), notes
+}
+
+// definitions takes the footnote definitions out of the lines of a document,
+// and returns the lines left, whether each of them is code, and the text of
+// the definitions by their lower case label.
+func definitions(lines []string) ([]string, []bool, map[string]string) {
+	var (
+		out   []string
+		code  []bool
+		defs  = map[string]string{}
+		fence string
+	)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			out, code = append(out, line), append(code, true)
+			continue
+		}
+		m := definitionPattern.FindStringSubmatch(line)
+		if fence != "" || m == nil {
+			out, code = append(out, line), append(code, fence != "")
+			continue
+		}
+
+		// Definitions go on over the indented lines after them, blank
+		// lines between them included.
+		text := []string{strings.TrimSpace(m[2])}
+		for i+1 < len(lines) {
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) || !indented(lines[next]) {
+				break
+			}
+			text = append(text, strings.TrimSpace(lines[next]))
+			i = next
+		}
+		label := strings.ToLower(m[1])
+		if _, ok := defs[label]; !ok {
+			defs[label] = strings.Join(strings.Fields(strings.Join(text, " ")), " ")
+		}
+	}
+	return out, code, defs
+}
+
+func indented(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// outsideCode applies fn to the parts of a line outside of code spans.
+func outsideCode(line string, fn func(string) string) string {
+	var b strings.Builder
+	for line != "" {
+		// Code spans end at the next run of as many backticks.
+		loc := codePattern.FindStringIndex(line)
+		if loc == nil {
+			b.WriteString(fn(line))
+			break
+		}
+		b.WriteString(fn(line[:loc[0]]))
+		ticks := line[loc[0]:loc[1]]
+		rest := line[loc[1]:]
+		end := strings.Index(rest, ticks)
+		if end < 0 {
+			b.WriteString(ticks)
+			line = rest
+			continue
+		}
+		b.WriteString(ticks + rest[:end+len(ticks)])
+		line = rest[end+len(ticks):]
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package footnotes
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	src := "# One\n\nA claim[^b] and another[^a], the first[^B] again.\n\n" +
+		"[^a]: The second note,\n    over two lines.\n[^b]: The first note.\n\n" +
+		"## Two\n\nMore[^c] and `code[^a]`, and a [^missing] note.\n\n" +
+		"```\n[^a]\n```\n\n[^c]: Third.\n[^unused]: Left out.\n"
+	want := "# One\n\nA claim¹ and another², the first¹ again.\n\n\n" +
+		"¹ The first note.  \n² The second note, over two lines.\n\n" +
+		"## Two\n\nMore³ and `code[^a]`, and a [^missing] note.\n\n" +
+		"```\n[^a]\n```\n\n\n³ Third.\n"
+
+	got, notes := Transform(src)
+	if got != want {
+		t.Errorf("unexpected document:\n%q\nwant:\n%q", got, want)
+	}
+	wantNotes := []Note{
+		{1, "b", "The first note."},
+		{2, "a", "The second note, over two lines."},
+		{3, "c", "Third."},
+	}
+	if !slices.Equal(notes, wantNotes) {
+		t.Errorf("expected notes %v, got %v", wantNotes, notes)
+	}
+}
+
+func TestTransformWithoutFootnotes(t *testing.T) {
+	src := "# Title\n\nAn array[^0] with no definitions.\n"
+	if got, notes := Transform(src); got != src || notes != nil {
+		t.Errorf("expected the document unchanged, got %q, %v", got, notes)
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	if got := Marker(12); got != "¹²" {
+		t.Errorf("expected ¹², got %q", got)
+	}
+	if got := Markers("A claim¹, another¹² and x⁰."); !slices.Equal(got, []int{1, 12}) {
+		t.Errorf("expected [1 12], got %v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/charmbracelet/log"
+)
+
+// forge is a code hosting site Glow can find the README of a repository on,
+// given the repository's URL, e.g. github.com/owner/repo, or a shorthand like
+// github://owner/repo.
+type forge interface {
+	// hostname of the site, e.g. "github.com".
+	hostname() string
+	// scheme of the shorthand for the site's repositories, e.g. "github://".
+	scheme() string
+	// findREADME finds the README of a repository.
+	findREADME(ctx context.Context, owner, repo string) (*source, error)
+}
+
+// forges are the sites whose repositories' READMEs can be read.
+var forges = []forge{
+	githubForge{},
+	gitlabForge{},
+	codebergForge,
+	sourcehutForge,
+	bitbucketForge,
+}
+
+// probingForge is a forge whose READMEs are found by trying the usual names
+// on the default branch of a repository.
+type probingForge struct {
+	name   string
+	host   string
+	prefix string
+
+	// defaultBranch looks up the default branch of a repository. If it's nil
+	// or fails, main and master are tried.
+	defaultBranch func(ctx context.Context, owner, repo string) (string, error)
+
+	// rawURL returns the URL of the content of a file on a branch.
+	rawURL func(owner, repo, branch, file string) string
+}
+
+func (f probingForge) hostname() string { return f.host }
+
+func (f probingForge) scheme() string { return f.prefix }
+
+func (f probingForge) findREADME(ctx context.Context, owner, repo string) (*source, error) {
+	branches := []string{"main", "master"}
+	if f.defaultBranch != nil {
+		branch, err := f.defaultBranch(ctx, owner, repo)
+		switch {
+		case err != nil:
+			log.Debug("unable to get default branch", "forge", f.name, "error", err)
+		case branch != "":
+			branches = []string{branch}
+		}
+	}
+
+	for _, branch := range branches {
+		for _, name := range readmeNames {
+			u := f.rawURL(owner, repo, branch, name)
+			//nolint:bodyclose
+			// it is closed on the caller
+			resp, err := fetch(ctx, u)
+			if errors.Is(err, errNotCached) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode == http.StatusOK {
+				return &source{reader: downloadBody(resp), URL: u}, nil
+			}
+			_ = resp.Body.Close()
+		}
+	}
+	return nil, fmt.Errorf("can't find README in %s repository", f.name)
+}
+
+// codebergForge is codeberg.org, which runs Forgejo.
+var codebergForge = probingForge{
+	name:   "Codeberg",
+	host:   "codeberg.org",
+	prefix: protoCodeberg,
+	defaultBranch: func(ctx context.Context, owner, repo string) (string, error) {
+		var result struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		_, err := fetchJSON(ctx, fmt.Sprintf("https://codeberg.org/api/v1/repos/%s/%s", owner, repo), &result)
+		return result.DefaultBranch, err
+	},
+	rawURL: func(owner, repo, branch, file string) string {
+		return fmt.Sprintf("https://codeberg.org/%s/%s/raw/branch/%s/%s", owner, repo, url.PathEscape(branch), file)
+	},
+}
+
+// sourcehutForge is git.sr.ht, where owners are written with a tilde, e.g.
+// git.sr.ht/~sircmpwn/scdoc. HEAD stands for the default branch.
+var sourcehutForge = probingForge{
+	name:   "sourcehut",
+	host:   "git.sr.ht",
+	prefix: protoSourcehut,
+	defaultBranch: func(context.Context, string, string) (string, error) {
+		return "HEAD", nil
+	},
+	rawURL: func(owner, repo, branch, file string) string {
+		return fmt.Sprintf("https://git.sr.ht/%s/%s/blob/%s/%s", owner, repo, url.PathEscape(branch), file)
+	},
+}
+
+// bitbucketForge is bitbucket.org.
+var bitbucketForge = probingForge{
+	name:   "Bitbucket",
+	host:   "bitbucket.org",
+	prefix: protoBitbucket,
+	defaultBranch: func(ctx context.Context, owner, repo string) (string, error) {
+		var result struct {
+			MainBranch struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		}
+		_, err := fetchJSON(ctx, fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s", owner, repo), &result)
+		return result.MainBranch.Name, err
+	},
+	rawURL: func(owner, repo, branch, file string) string {
+		return fmt.Sprintf("https://bitbucket.org/%s/%s/raw/%s/%s", owner, repo, url.PathEscape(branch), file)
+	},
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/manpage"
+	"github.com/douglas-larocca/glow/v2/mdfmt"
+	"github.com/douglas-larocca/glow/v2/stats"
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+// What --format writes: styled output, plain text without escape codes, or
+// normalized markdown.
+const (
+	formatANSI     = "ansi"
+	formatText     = "text"
+	formatMarkdown = "md"
+)
+
+var (
+	outputFormat string
+	formats      = []string{formatANSI, formatText, formatMarkdown}
+)
+
+// validateFormat checks the value given for --format.
+func validateFormat(format string) error {
+	if !slices.Contains(formats, format) {
+		return fmt.Errorf("invalid format %q, expected one of: %s", format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// renderFormat renders a document as --format asks: render styles it, and
+// its output is stripped of escape codes for plain text, then moved right by
+// --margin or --center. Markdown is normalized from the source of the
+// document, see formatSource, and wrapped instead of rendered.
+func renderFormat(source string, render func() (string, error)) (string, error) {
+	return renderFormatAs(outputFormat, source, render)
+}
+
+// renderFormatAs renders a document in format, as renderFormat does for
+// --format.
+func renderFormatAs(format, source string, render func() (string, error)) (string, error) {
+	if format == formatMarkdown {
+		// The frontmatter is kept as written.
+		_, body := utils.SplitFrontmatter([]byte(source))
+		return source[:len(source)-len(body)] + mdfmt.Format(body, int(width)), nil //nolint:gosec
+	}
+	out, err := render()
+	if err != nil {
+		return out, err
+	}
+	if format == formatText {
+		out = utils.PlainText(out)
+	}
+	return utils.Indent(out, leftMargin()), nil
+}
+
+// formatSource returns the markdown of a document normalized by --format md:
+// its source as written, rather than as prepared for rendering, so that
+// diagrams, math and footnotes stay markdown. Code files are in a code block.
+func formatSource(src *source, content []byte) string {
+	if src.URL == "" && manpage.IsRoff(content) {
+		content = []byte(manpage.ToMarkdown(string(content)))
+	}
+	front, body := utils.SplitFrontmatter(content)
+	if !src.isMarkdown() {
+		return utils.WrapCodeBlock(selectedLines.apply(string(body)), filepath.Ext(src.URL))
+	}
+	if front == nil {
+		return selectedLines.apply(string(body))
+	}
+	return string(content[:len(content)-len(body)]) + selectedLines.apply(string(body))
+}
+
+// statsFooter returns the line of counts written after a document with
+// --stats. Markdown is written without it, to stay a document of its own.
+func statsFooter(markdown string) string {
+	if outputFormat == formatMarkdown {
+		return ""
+	}
+	line := stats.Count([]byte(markdown)).String()
+	if outputFormat == formatANSI {
+		line = faint(line)
+	}
+	return utils.Indent("\n  "+line+"\n", leftMargin())
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestRenderFormat(t *testing.T) {
+	defer func(format string, w uint) { outputFormat, width = format, w }(outputFormat, width)
+	width = 80
+
+	render := func() (string, error) {
+		return "\x1b[1mTitle\x1b[0m   \n\n  \x1b]8;;https://example.com\x07link\x1b]8;;\x07  \n\n", nil
+	}
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{formatANSI, "\x1b[1mTitle\x1b[0m   \n\n  \x1b]8;;https://example.com\x07link\x1b]8;;\x07  \n\n"},
+		{formatText, "Title\n\n  link\n"},
+		{formatMarkdown, "# Title\n\n[link](https://example.com)\n"},
+	} {
+		outputFormat = tc.format
+		got, err := renderFormat("Title\n=====\n[link](https://example.com)\n", render)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+
+	if err := validateFormat("html"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFormatMarkdownRoundTrip(t *testing.T) {
+	defer func(w uint) { width = w }(width)
+	width = 80
+
+	doc := "---\ntitle: Notes\n---\n# Notes\n\n> [!WARNING]\n> Be careful.\n\n" +
+		"```mermaid\ngraph TD\n  A --> B\n```\n\nEnergy is $E = mc^2$.[^1]\n\n[^1]: Einstein.\n"
+	src := &source{URL: "notes.md"}
+	once, err := renderFormatAs(formatMarkdown, formatSource(src, []byte(doc)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if once != doc {
+		t.Errorf("expected the document as written, got %q", once)
+	}
+	twice, err := renderFormatAs(formatMarkdown, formatSource(src, []byte(once)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twice != once {
+		t.Errorf("expected formatting to be stable, got %q then %q", once, twice)
+	}
+}
+
+func TestStatsFooter(t *testing.T) {
+	defer func(format string) { outputFormat = format }(outputFormat)
+
+	outputFormat = formatText
+	if got, want := statsFooter("# Title\n\nTwo words.\n"), "\n  3 words · 1 min read · 1 heading · 0 links · 0 code blocks\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	outputFormat = formatMarkdown
+	if got := statsFooter("# Title\n"); got != "" {
+		t.Errorf("expected no footer for markdown, got %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+// Package frontmatter reads the YAML front matter of markdown documents and
+// formats it for display.
+package frontmatter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode controls how front matter is displayed.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeShow renders the front matter as a table above the document.
+	ModeShow Mode = "show"
+	// ModeHide removes the front matter from the document.
+	ModeHide Mode = "hide"
+	// ModeOnly renders the front matter table without the document.
+	ModeOnly Mode = "only"
+)
+
+// Modes lists all valid modes.
+var Modes = []Mode{ModeShow, ModeHide, ModeOnly}
+
+// ParseMode validates a mode string.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid frontmatter mode %q: use show, hide or only", s)
+}
+
+// ErrNotFound is returned by Get for fields that aren't set.
+var ErrNotFound = errors.New("field not found")
+
+// Field is a top-level front matter entry.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// parse decodes front matter into its top-level mapping node. Empty front
+// matter yields nil.
+func parse(front []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(front, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("unable to parse frontmatter: not a mapping")
+	}
+	return root, nil
+}
+
+// Fields returns the top-level front matter entries in document order.
+func Fields(front []byte) ([]Field, error) {
+	root, err := parse(front)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		fields = append(fields, Field{
+			Key:   root.Content[i].Value,
+			Value: inline(root.Content[i+1]),
+		})
+	}
+	return fields, nil
+}
+
+// Get returns the value of a field. Nested fields are addressed with dots,
+// list items by their index, e.g. "author.name" or "tags.0". Scalars are
+// returned as-is, anything else as YAML.
+func Get(front []byte, key string) (string, error) {
+	n, err := parse(front)
+	if err != nil {
+		return "", err
+	}
+
+	for _, k := range strings.Split(key, ".") {
+		if n = child(n, k); n == nil {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+	}
+
+	if n.Kind == yaml.ScalarNode {
+		return n.Value, nil
+	}
+	b, err := yaml.Marshal(n)
+	if err != nil {
+		return "", fmt.Errorf("unable to format field: %w", err)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// Tags returns the tags of a document, given as a list or separated by
+// commas or spaces, as in Obsidian vaults. Leading #s are dropped, and
+// repeated tags are left out.
+func Tags(front []byte) []string {
+	root, err := parse(front)
+	if err != nil {
+		return nil
+	}
+	n := child(root, "tags")
+	if n == nil {
+		n = child(root, "tag")
+	}
+	var values []string
+	switch {
+	case n == nil:
+	case n.Kind == yaml.ScalarNode:
+		values = strings.FieldsFunc(n.Value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	case n.Kind == yaml.SequenceNode:
+		for _, c := range n.Content {
+			if c.Kind == yaml.ScalarNode {
+				values = append(values, c.Value)
+			}
+		}
+	}
+
+	var tags []string
+	for _, v := range values {
+		if v = strings.TrimLeft(strings.TrimSpace(v), "#"); v != "" && !slices.Contains(tags, v) {
+			tags = append(tags, v)
+		}
+	}
+	return tags
+}
+
+func child(n *yaml.Node, key string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				return n.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(n.Content) {
+			return n.Content[i]
+		}
+	case yaml.AliasNode:
+		return child(n.Alias, key)
+	}
+	return nil
+}
+
+// inline formats a value on a single line. Lists of scalars are joined with
+// commas; other collections use YAML's flow style.
+func inline(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return strings.Join(strings.Fields(n.Value), " ")
+	case yaml.AliasNode:
+		return inline(n.Alias)
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(n.Content))
+		for _, c := range n.Content {
+			if c.Kind != yaml.ScalarNode {
+				items = nil
+				break
+			}
+			items = append(items, inline(c))
+		}
+		if items != nil {
+			return strings.Join(items, ", ")
+		}
+	}
+
+	flow := *n
+	flow.Style = yaml.FlowStyle
+	b, err := yaml.Marshal(&flow)
+	if err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(string(b)), " ")
+}
+
+// Table formats fields as a markdown table.
+func Table(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("| Field | Value |\n| --- | --- |\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "| **%s** | %s |\n", escape(f.Key), escape(f.Value))
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// Apply combines a document body with its front matter according to mode.
+// Front matter that can't be parsed is left out.
+func Apply(front []byte, body string, mode Mode) string {
+	if mode == ModeHide {
+		return body
+	}
+
+	fields, _ := Fields(front)
+	table := Table(fields)
+	switch {
+	case mode == ModeOnly:
+		return table
+	case table == "":
+		return body
+	}
+	return table + "\n" + body
+}
@@ -0,0 +1,102 @@
+package frontmatter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const front = `title: Hello | World
+tags: [go, cli]
+author:
+  name: Ada
+  links:
+    - intro.md
+`
+
+func TestFields(t *testing.T) {
+	fields, err := Fields([]byte(front))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Field{
+		{"title", "Hello | World"},
+		{"tags", "go, cli"},
+		{"author", "{name: Ada, links: [intro.md]}"},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %d fields, got %v", len(expected), fields)
+	}
+	for i, f := range fields {
+		if f != expected[i] {
+			t.Errorf("field %d: expected %v, got %v", i, expected[i], f)
+		}
+	}
+
+	if _, err := Fields([]byte("- not\n- a mapping\n")); err == nil {
+		t.Error("expected an error for a list")
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := map[string]string{
+		"title":          "Hello | World",
+		"tags.1":         "cli",
+		"author.name":    "Ada",
+		"tags":           "[go, cli]",
+		"author.links":   "- intro.md",
+		"author.links.0": "intro.md",
+	}
+	for key, expected := range tests {
+		got, err := Get([]byte(front), key)
+		if err != nil {
+			t.Errorf("%s: %v", key, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+
+	for _, key := range []string{"date", "tags.2", "title.x"} {
+		if _, err := Get([]byte(front), key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", key, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	table := "| Field | Value |\n| --- | --- |\n| **title** | Hi |\n"
+	tests := []struct {
+		front    string
+		mode     Mode
+		expected string
+	}{
+		{"title: Hi\n", ModeShow, table + "\n# Body\n"},
+		{"title: Hi\n", ModeHide, "# Body\n"},
+		{"title: Hi\n", ModeOnly, table},
+		{"", ModeShow, "# Body\n"},
+		{"", ModeOnly, ""},
+	}
+	for _, tc := range tests {
+		if got := Apply([]byte(tc.front), "# Body\n", tc.mode); got != tc.expected {
+			t.Errorf("%s %q: expected %q, got %q", tc.mode, tc.front, tc.expected, got)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	for front, want := range map[string][]string{
+		front:                          {"go", "cli"},
+		"tags:\n  - '#idea'\n  - go\n": {"idea", "go"},
+		"tags: idea, go idea\n":        {"idea", "go"},
+		"tag: single\n":                {"single"},
+		"title: untagged\n":            nil,
+		"tags: {not: tags}\n":          nil,
+		"- not\n- a mapping\n":         nil,
+	} {
+		if got := Tags([]byte(front)); !slices.Equal(got, want) {
+			t.Errorf("%q: expected %q, got %q", front, want, got)
+		}
+	}
+}
@@ -1,34 +1,39 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
 )
 
-// findGitHubREADME tries to find the correct README filename in a repository using GitHub API.
-func findGitHubREADME(u *url.URL) (*source, error) {
-	owner, repo, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
-	if !ok {
-		return nil, fmt.Errorf("invalid url: %s", u.String())
-	}
+// githubForge is github.com, whose API tells where the README of a
+// repository is.
+type githubForge struct{}
+
+func (githubForge) hostname() string { return githubURL.Hostname() }
 
+func (githubForge) scheme() string { return protoGithub }
+
+// findREADME tries to find the correct README filename in a repository using GitHub API.
+func (f githubForge) findREADME(ctx context.Context, owner, repo string) (*source, error) {
 	type readme struct {
 		DownloadURL string `json:"download_url"`
 	}
 
-	apiURL := fmt.Sprintf("https://api.%s/repos/%s/%s/readme", u.Hostname(), owner, repo)
+	apiURL := fmt.Sprintf("https://api.%s/repos/%s/%s/readme", f.hostname(), owner, repo)
 
-	//nolint:bodyclose
-	// it is closed on the caller
-	res, err := http.Get(apiURL) //nolint: gosec,noctx
+	res, err := fetch(ctx, apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get url: %w", err)
+		return nil, err
 	}
+	defer res.Body.Close() //nolint:errcheck
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -43,15 +48,100 @@ func findGitHubREADME(u *url.URL) (*source, error) {
 	if res.StatusCode == http.StatusOK {
 		//nolint:bodyclose
 		// it is closed on the caller
-		resp, err := http.Get(result.DownloadURL) //nolint: noctx
+		resp, err := fetch(ctx, result.DownloadURL)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get url: %w", err)
+			return nil, err
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			return &source{resp.Body, result.DownloadURL}, nil
+			return &source{reader: downloadBody(resp), URL: result.DownloadURL}, nil
 		}
+		_ = resp.Body.Close()
 	}
 
 	return nil, errors.New("can't find README in GitHub repository")
 }
+
+// githubRepo is a GitHub repository browsed in the TUI. Files are listed with
+// the API and fetched from the default branch.
+type githubRepo struct {
+	// Base URLs of the API, raw files and web pages.
+	api, raw, web string
+	owner, name   string
+
+	mu     sync.Mutex
+	branch string
+}
+
+func newGitHubRepo(owner, name string) *githubRepo {
+	return &githubRepo{
+		api:   "https://api.github.com",
+		raw:   "https://raw.githubusercontent.com",
+		web:   githubURL.String(),
+		owner: owner,
+		name:  name,
+	}
+}
+
+// defaultBranch returns the repository's default branch, looked up once.
+func (r *githubRepo) defaultBranch(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.branch != "" {
+		return r.branch, nil
+	}
+
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := fetchJSON(ctx, fmt.Sprintf("%s/repos/%s/%s", r.api, r.owner, r.name), &repo); err != nil {
+		return "", fmt.Errorf("unable to get GitHub repository: %w", err)
+	}
+	r.branch = repo.DefaultBranch
+	return r.branch, nil
+}
+
+func (r *githubRepo) Files(ctx context.Context) ([]string, error) {
+	branch, err := r.defaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1", r.api, r.owner, r.name, url.PathEscape(branch))
+	if _, err := fetchJSON(ctx, u, &tree); err != nil {
+		return nil, fmt.Errorf("unable to list GitHub repository: %w", err)
+	}
+	if tree.Truncated {
+		log.Warn("GitHub repository listing is incomplete", "repo", r.owner+"/"+r.name)
+	}
+
+	var files []string
+	for _, e := range tree.Tree {
+		if e.Type == "blob" {
+			files = append(files, e.Path)
+		}
+	}
+	return files, nil
+}
+
+func (r *githubRepo) Fetch(ctx context.Context, path string) ([]byte, error) {
+	branch, err := r.defaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, _, err := fetchBytes(ctx, fmt.Sprintf("%s/%s/%s/%s/%s", r.raw, r.owner, r.name, url.PathEscape(branch), escapePath(path)))
+	return body, err
+}
+
+func (r *githubRepo) URL(path string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("%s/%s/%s/blob/%s/%s", r.web, r.owner, r.name, url.PathEscape(r.branch), escapePath(path))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,29 +9,32 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 )
 
-// findGitLabREADME tries to find the correct README filename in a repository using GitLab API.
-func findGitLabREADME(u *url.URL) (*source, error) {
-	owner, repo, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
-	if !ok {
-		return nil, fmt.Errorf("invalid url: %s", u.String())
-	}
+// gitlabForge is gitlab.com, whose API tells where the README of a
+// repository is.
+type gitlabForge struct{}
+
+func (gitlabForge) hostname() string { return gitlabURL.Hostname() }
 
+func (gitlabForge) scheme() string { return protoGitlab }
+
+// findREADME tries to find the correct README filename in a repository using GitLab API.
+func (f gitlabForge) findREADME(ctx context.Context, owner, repo string) (*source, error) {
 	projectPath := url.QueryEscape(owner + "/" + repo)
 
 	type readme struct {
 		ReadmeURL string `json:"readme_url"`
 	}
 
-	apiURL := fmt.Sprintf("https://%s/api/v4/projects/%s", u.Hostname(), projectPath)
+	apiURL := fmt.Sprintf("https://%s/api/v4/projects/%s", f.hostname(), projectPath)
 
-	//nolint:bodyclose
-	// it is closed on the caller
-	res, err := http.Get(apiURL) //nolint: gosec,noctx
+	res, err := fetch(ctx, apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get url: %w", err)
+		return nil, err
 	}
+	defer res.Body.Close() //nolint:errcheck
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -47,15 +51,97 @@ func findGitLabREADME(u *url.URL) (*source, error) {
 	if res.StatusCode == http.StatusOK {
 		//nolint:bodyclose
 		// it is closed on the caller
-		resp, err := http.Get(readmeRawURL) //nolint: gosec,noctx
+		resp, err := fetch(ctx, readmeRawURL)
 		if err != nil {
-			return nil, fmt.Errorf("unable to get url: %w", err)
+			return nil, err
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			return &source{resp.Body, readmeRawURL}, nil
+			return &source{reader: downloadBody(resp), URL: readmeRawURL}, nil
 		}
+		_ = resp.Body.Close()
 	}
 
 	return nil, errors.New("can't find README in GitLab repository")
 }
+
+// gitlabRepo is a GitLab repository browsed in the TUI. Files are listed and
+// fetched from the default branch with the API.
+type gitlabRepo struct {
+	// Base URL of the GitLab instance.
+	base        string
+	owner, name string
+
+	mu     sync.Mutex
+	branch string
+}
+
+func newGitLabRepo(owner, name string) *gitlabRepo {
+	return &gitlabRepo{base: gitlabURL.String(), owner: owner, name: name}
+}
+
+// project returns the base URL of the repository's API.
+func (r *gitlabRepo) project() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", r.base, url.PathEscape(r.owner+"/"+r.name))
+}
+
+// defaultBranch returns the repository's default branch, looked up once.
+func (r *gitlabRepo) defaultBranch(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.branch != "" {
+		return r.branch, nil
+	}
+
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := fetchJSON(ctx, r.project(), &project); err != nil {
+		return "", fmt.Errorf("unable to get GitLab project: %w", err)
+	}
+	r.branch = project.DefaultBranch
+	return r.branch, nil
+}
+
+func (r *gitlabRepo) Files(ctx context.Context) ([]string, error) {
+	branch, err := r.defaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tree is listed a page at a time.
+	var files []string
+	for page := "1"; page != ""; {
+		var tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		}
+		u := fmt.Sprintf("%s/repository/tree?recursive=true&per_page=100&ref=%s&page=%s", r.project(), url.QueryEscape(branch), page)
+		header, err := fetchJSON(ctx, u, &tree)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list GitLab repository: %w", err)
+		}
+		for _, e := range tree {
+			if e.Type == "blob" {
+				files = append(files, e.Path)
+			}
+		}
+		page = header.Get("X-Next-Page")
+	}
+	return files, nil
+}
+
+func (r *gitlabRepo) Fetch(ctx context.Context, path string) ([]byte, error) {
+	branch, err := r.defaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, _, err := fetchBytes(ctx, fmt.Sprintf("%s/repository/files/%s/raw?ref=%s", r.project(), url.PathEscape(path), url.QueryEscape(branch)))
+	return body, err
+}
+
+func (r *gitlabRepo) URL(path string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("%s/%s/%s/-/blob/%s/%s", r.base, r.owner, r.name, url.PathEscape(r.branch), escapePath(path))
+}
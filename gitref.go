@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// splitGitRef splits an argument like HEAD~3:README.md into a git ref and a
+// path. Existing files and Windows paths with a drive letter aren't refs.
+func splitGitRef(arg string) (ref, file string, ok bool) {
+	if filepath.VolumeName(arg) != "" {
+		return "", "", false
+	}
+	if _, err := os.Stat(arg); err == nil {
+		return "", "", false
+	}
+	ref, file, ok = strings.Cut(arg, ":")
+	if !ok || ref == "" || strings.ContainsAny(ref, " \t\n") {
+		return "", "", false
+	}
+	return ref, file, true
+}
+
+// gitSource returns the document at path in the given git ref, or the
+// README of the directory at path. Paths are relative to dir, the current
+// directory if empty.
+func gitSource(ctx context.Context, dir, ref, file string) (*source, error) {
+	// Paths in git object names are relative to the root of the repository,
+	// unless they start with ./ or ../
+	if !strings.HasPrefix(file, "./") && !strings.HasPrefix(file, "../") {
+		file = "./" + file
+	}
+	object := ref + ":" + file
+
+	typ, err := git(ctx, dir, "cat-file", "-t", object)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(typ)) == "tree" {
+		names, err := git(ctx, dir, "ls-tree", "--name-only", "--full-tree", object)
+		if err != nil {
+			return nil, err
+		}
+		readme := readmeInTree(strings.Split(strings.TrimSpace(string(names)), "\n"))
+		if readme == "" {
+			return nil, errors.New("missing markdown source")
+		}
+		file = path.Join(file, readme)
+		if !strings.HasPrefix(file, ".") {
+			file = "./" + file
+		}
+		object = ref + ":" + file
+	}
+
+	content, err := git(ctx, dir, "cat-file", "blob", object)
+	if err != nil {
+		return nil, err
+	}
+	return &source{reader: io.NopCloser(bytes.NewReader(content)), URL: ref + ":" + path.Clean(file)}, nil
+}
+
+// readmeInTree returns the first of names that is a README, or "".
+func readmeInTree(names []string) string {
+	for _, v := range readmeNames {
+		for _, name := range names {
+			if strings.EqualFold(name, v) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// git runs a git command in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("unable to read from git: %s", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to run git: %w", err)
+	}
+	return out, nil
+}
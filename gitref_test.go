@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitGitRef(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"HEAD~3:README.md":   true,
+		"v1.0:docs":          true,
+		"main:":              true,
+		"README.md":          false,
+		":README.md":         false,
+		"some ref:file.md":   false,
+		"testdata/none.md":   false,
+		"glow_test.go":       false,
+		"origin/main:a/b.md": true,
+	} {
+		if _, _, ok := splitGitRef(arg); ok != want {
+			t.Errorf("%s: expected %v, got %v", arg, want, ok)
+		}
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	write("docs/README.md", "# Old\n")
+	run("add", ".")
+	run("-c", "user.name=glow", "-c", "user.email=glow@example.com", "commit", "-qm", "old")
+	write("docs/README.md", "# New\n")
+
+	for _, file := range []string{"docs/README.md", "docs"} {
+		src, err := gitSource(context.Background(), dir, "HEAD", file)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		b, _ := io.ReadAll(src.reader)
+		if string(b) != "# Old\n" {
+			t.Errorf("%s: expected the committed document, got %q", file, b)
+		}
+		if src.URL != "HEAD:docs/README.md" {
+			t.Errorf("%s: unexpected URL %s", file, src.URL)
+		}
+	}
+
+	// The root of the repository has no README.
+	if _, err := gitSource(context.Background(), dir, "HEAD", ""); err == nil {
+		t.Error("expected an error for a directory without a README")
+	}
+	if _, err := gitSource(context.Background(), dir, "nope", "docs/README.md"); err == nil {
+		t.Error("expected an error for an unknown ref")
+	}
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
 )
 
 func TestGlowFlags(t *testing.T) {
@@ -39,3 +46,88 @@ func TestGlowFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestStyleExtends(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("base.json", `{"extends": "dracula", "heading": {"color": "#ff0000"}}`)
+	custom := write("custom.json", `{"extends": "base.json", "heading": {"bold": false}, "callouts": {"note": {"color": "#00ff00"}}}`)
+
+	if err := validateStyle(custom); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := utils.StyleConfig(custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cfg.Heading.Color; c == nil || *c != "#ff0000" {
+		t.Errorf("expected the color of the extended file, got %v", c)
+	}
+	if b := cfg.Heading.Bold; b == nil || *b {
+		t.Errorf("expected the override of bold, got %v", b)
+	}
+	if cfg.H1.Prefix != styles.DraculaStyleConfig.H1.Prefix || cfg.Document.Margin == nil {
+		t.Errorf("expected the rest of dracula, got %+v", cfg)
+	}
+	if *styles.DraculaStyleConfig.Heading.Color == "#ff0000" {
+		t.Error("expected the built-in style to be left alone")
+	}
+	if s := utils.CalloutStyles(custom); s["note"].Color != "#00ff00" {
+		t.Errorf("expected the callout styles of the file, got %+v", s)
+	}
+
+	loop := write("loop.json", `{"extends": "loop.json"}`)
+	if err := validateStyle(loop); err == nil {
+		t.Error("expected an error for a style extending itself")
+	}
+	missing := write("missing.json", `{"extends": "nope.json"}`)
+	if err := validateStyle(missing); err == nil {
+		t.Error("expected an error for a missing base style")
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	defer func(out, format string, p bool, stdin *os.File) {
+		outputFile, outputFormat, pager, os.Stdin = out, format, p, stdin
+	}(outputFile, outputFormat, pager, os.Stdin)
+	pager = false
+
+	// Nothing is piped to glow.
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close() //nolint:errcheck
+	os.Stdin = null
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(doc, []byte("# Rendered\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outputFile = filepath.Join(dir, "out.txt")
+	outputFormat = formatText
+	if err := os.WriteFile(outputFile, []byte("kept"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := execute(&cobra.Command{}, []string{filepath.Join(dir, "missing.md")}); err == nil {
+		t.Fatal("expected an error for a missing document")
+	}
+	if b, _ := os.ReadFile(outputFile); string(b) != "kept" {
+		t.Errorf("expected the output file to be left alone, got %q", b)
+	}
+
+	if err := execute(&cobra.Command{}, []string{doc}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(outputFile); !strings.Contains(string(b), "Rendered") {
+		t.Errorf("expected the rendered document in the output file, got %q", b)
+	}
+}
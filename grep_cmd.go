@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/search"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grepFlags struct {
+		json       bool
+		ignoreCase bool
+	}
+
+	grepMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#1A1A1A")).Background(lipgloss.Color("#F1FA8C"))
+
+	grepCmd = &cobra.Command{
+		Use:   "grep PATTERN [FILE|DIR]...",
+		Short: "Search markdown documents",
+		Long: paragraph(fmt.Sprintf("\n%s markdown documents for a regular expression. Each match is rendered with the paragraph, list or code block it's in, below the headings leading to it. Directories are searched recursively, the current one if none is given. Use --json for one JSON object per match, for use in tools. Exits with status 1 if nothing matches.",
+			keyword("Search"))),
+		Example: paragraph("glow grep TODO\nglow grep -i 'install(ing)?' docs\nglow grep --json deprecated README.md"),
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expr := args[0]
+			if grepFlags.ignoreCase {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid pattern: %w", err)
+			}
+
+			paths := args[1:]
+			if len(paths) == 0 {
+				paths = []string{"."}
+			}
+			var files []string
+			for _, arg := range paths {
+				info, err := os.Stat(arg)
+				if err != nil {
+					return fmt.Errorf("unable to stat file: %w", err)
+				}
+				if !info.IsDir() {
+					files = append(files, arg)
+					continue
+				}
+				found, err := findMarkdownFiles(arg)
+				if err != nil {
+					return err
+				}
+				files = append(files, found...)
+			}
+
+			found := 0
+			for _, path := range files {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("unable to read file: %w", err)
+				}
+				matches := search.Find(grepBody(content), re)
+				if len(matches) == 0 {
+					continue
+				}
+				found += len(matches)
+
+				if grepFlags.json {
+					err = writeGrepJSON(cmd, path, matches)
+				} else {
+					err = writeGrepMatches(cmd, path, matches, re)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			if found == 0 {
+				return errors.New("no matches found")
+			}
+			return nil
+		},
+	}
+)
+
+// grepBody returns a document with its frontmatter blanked out, keeping the
+// line numbers of the body.
+func grepBody(content []byte) string {
+	_, body := utils.SplitFrontmatter(content)
+	skipped := bytes.Count(content[:len(content)-len(body)], []byte("\n"))
+	return strings.Repeat("\n", skipped) + string(body)
+}
+
+// writeGrepJSON writes the matches of a document as JSON lines.
+func writeGrepJSON(cmd *cobra.Command, path string, matches []search.Match) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	for _, m := range matches {
+		err := enc.Encode(struct {
+			Path string `json:"path"`
+			search.Match
+		}{path, m})
+		if err != nil {
+			return fmt.Errorf("unable to write match: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeGrepMatches renders the matches of a document, each below its
+// location and the headings leading to it.
+func writeGrepMatches(cmd *cobra.Command, path string, matches []search.Match, re *regexp.Regexp) error {
+	r, _, err := setupRendererWidth(&source{URL: path}, width)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(recursiveHeader(path))
+	highlight := func(s string) string {
+		return grepMatchStyle.Render(s)
+	}
+	for _, m := range matches {
+		location := faint(fmt.Sprintf("%s:%d", path, m.Lines[0]))
+		if len(m.Headings) > 0 {
+			location += " " + keyword(strings.Join(m.Headings, " › "))
+		}
+		b.WriteString("\n  " + location + "\n")
+
+		out, err := renderDocument(r, m.Block)
+		if err != nil {
+			return fmt.Errorf("unable to render markdown: %w", err)
+		}
+		for _, line := range trimBlankLines(strings.Split(out, "\n")) {
+			b.WriteString(search.Highlight(line, re, highlight) + "\n")
+		}
+	}
+	if _, err := fmt.Fprint(cmd.OutOrStdout(), b.String()); err != nil {
+		return fmt.Errorf("unable to write to writer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package history remembers the documents opened most recently.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the history file in the data directory.
+const FileName = "history.json"
+
+// DefaultSize is the number of documents remembered unless configured
+// otherwise.
+const DefaultSize = 50
+
+// Entry is an opened document.
+type Entry struct {
+	// Absolute path or URL of the document.
+	Path   string    `json:"path"`
+	Opened time.Time `json:"opened"`
+}
+
+// IsLocal reports whether the document is a local file, rather than a URL
+// or a file at a git ref.
+func (e Entry) IsLocal() bool {
+	return filepath.IsAbs(e.Path)
+}
+
+// Store is a list of documents persisted to a JSON file, most recently
+// opened first. It's safe for concurrent use.
+type Store struct {
+	path string
+	size int
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Load reads the history stored at path, keeping up to size documents. A
+// missing file is treated as an empty history.
+func Load(path string, size int) (*Store, error) {
+	s := &Store{path: path, size: max(size, 0)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read history: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.entries); err != nil {
+		return nil, fmt.Errorf("unable to parse history: %w", err)
+	}
+	s.entries = s.entries[:min(len(s.entries), s.size)]
+	return s, nil
+}
+
+// List returns the documents, most recently opened first.
+func (s *Store) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.entries)
+}
+
+// Add moves a document to the top of the history, or adds it there, and
+// saves the store. The least recently opened documents are forgotten once
+// there are more than the size of the history.
+func (s *Store) Add(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size == 0 {
+		return nil
+	}
+	s.entries = slices.DeleteFunc(s.entries, func(e Entry) bool {
+		return e.Path == path
+	})
+	s.entries = slices.Insert(s.entries, 0, Entry{Path: path, Opened: time.Now()})
+	s.entries = s.entries[:min(len(s.entries), s.size)]
+	return s.save()
+}
+
+// Clear forgets all documents and saves the store.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+	return s.save()
+}
+
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, b, 0o600); err != nil {
+		return fmt.Errorf("unable to write history: %w", err)
+	}
+	return nil
+}
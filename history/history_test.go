@@ -0,0 +1,68 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func paths(entries []Entry) []string {
+	var p []string
+	for _, e := range entries {
+		p = append(p, e.Path)
+	}
+	return p
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glow", FileName)
+
+	s, err := Load(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []string{"/docs/a.md", "https://example.com/b.md", "/docs/c.md", "/docs/a.md", "/docs/d.md"} {
+		if err := s.Add(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err = Load(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/docs/d.md", "/docs/a.md", "/docs/c.md"}
+	if got := paths(s.List()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// A smaller history forgets the oldest documents.
+	s, err = Load(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(s.List()); !reflect.DeepEqual(got, want[:1]) {
+		t.Fatalf("expected %v, got %v", want[:1], got)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Load(path, 3); err != nil || len(s.List()) != 0 {
+		t.Fatalf("expected an empty history: %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s, err := Load(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("/docs/a.md"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.List()) != 0 {
+		t.Error("expected nothing to be remembered")
+	}
+}
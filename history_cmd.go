@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/history"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// recent starts the TUI on the recently opened documents.
+var recent bool
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "List recently opened documents",
+		Long: paragraph(fmt.Sprintf("\n%s the documents opened most recently, local or remote. %s opens the TUI on those that are local files.",
+			keyword("List"), keyword("glow --recent"))),
+		Example: paragraph("glow history\nglow history open 2\nglow history clear"),
+		Args:    cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			s, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			list := s.List()
+			if len(list) == 0 {
+				fmt.Println("No documents opened yet.")
+				return nil
+			}
+			for i, e := range list {
+				fmt.Printf("%3d  %s  %s\n", i+1, e.Path, faint(humanize.Time(e.Opened)))
+			}
+			return nil
+		},
+	}
+
+	historyOpenCmd = &cobra.Command{
+		Use:   "open N",
+		Short: "Render a recently opened document",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return errors.New("documents are referred to by number, see glow history")
+			}
+			list := s.List()
+			if n < 1 || n > len(list) {
+				return fmt.Errorf("no document #%d", n)
+			}
+			e := list[n-1]
+			if e.IsLocal() && (tui || cmd.Flags().Changed("tui")) {
+				return runTUI(e.Path, "")
+			}
+			return executeArg(cmd, e.Path, cmd.OutOrStdout())
+		},
+	}
+
+	historyClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Forget the recently opened documents",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			s, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			if err := s.Clear(); err != nil {
+				return err
+			}
+			fmt.Println("Cleared history.")
+			return nil
+		},
+	}
+)
+
+// historySize returns the number of documents kept in the history, which is
+// disabled by a size of 0.
+func historySize() int {
+	return viper.GetInt("historySize")
+}
+
+// loadHistory reads the history of recently opened documents from the data
+// directory.
+func loadHistory() (*history.Store, error) {
+	return history.Load(dataPath(history.FileName), historySize()) //nolint:wrapcheck
+}
+
+// addToHistory remembers that the document of src was opened. Documents
+// read from stdin have no path and aren't remembered.
+func addToHistory(src *source) {
+	if src.URL == "" || historySize() == 0 {
+		return
+	}
+	s, err := loadHistory()
+	if err == nil {
+		err = s.Add(src.URL)
+	}
+	if err != nil {
+		log.Debug("unable to save history", "err", err)
+	}
+}
@@ -0,0 +1,356 @@
+package htmlmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/douglas-larocca/glow/v2/stats"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Patterns of classes and ids telling what part of a page an element is,
+// after those of Mozilla's Readability.
+var (
+	unlikelyPattern = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cookie|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|newsletter|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybePattern    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativePattern = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	sentencePattern = regexp.MustCompile(`\.( |$)`)
+)
+
+// Article is the article of a web page, without the navigation, sidebars,
+// comments and ads around it.
+type Article struct {
+	Title     string
+	Byline    string
+	SiteName  string
+	Published time.Time
+	// Content is the markdown of the article, without its title.
+	Content string
+}
+
+// Read reads an HTML page and finds its article, by scoring the elements
+// holding its paragraphs like Mozilla's Readability does. The page is decoded
+// from the charset given in contentType, or declared by the page itself.
+// Links and images are resolved against base, or else the URL the page gives
+// for itself.
+func Read(r io.Reader, contentType string, base *url.URL) (*Article, error) {
+	doc, err := parse(r, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Article{
+		Title:    pageTitle(doc),
+		Byline:   byline(doc),
+		SiteName: metaContent(doc, "og:site_name", "application-name"),
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, metaContent(doc, "article:published_time", "date", "dc.date")); err == nil {
+			a.Published = t
+			break
+		}
+	}
+
+	// Saved pages are resolved against the URL they were saved from.
+	if base == nil {
+		base, _ = url.Parse(metaContent(doc, "og:url"))
+	}
+	removeClutter(doc)
+	c := converter{base: base}
+	var parts []string
+	for _, n := range articleNodes(doc) {
+		if s := c.block(n); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	content := strings.TrimSpace(blankPattern.ReplaceAllString(strings.Join(parts, "\n\n"), "\n\n"))
+
+	// The article often repeats its title in a heading of its own.
+	if first, rest, _ := strings.Cut(content, "\n"); strings.HasPrefix(first, "#") &&
+		strings.EqualFold(strings.TrimSpace(strings.TrimLeft(first, "#")), Escape(a.Title)) {
+		content = strings.TrimSpace(rest)
+	}
+	a.Content = content + "\n"
+	return a, nil
+}
+
+// ReadingTime estimates how long reading the article takes.
+func (a *Article) ReadingTime() time.Duration {
+	return stats.ReadingTime(len(strings.Fields(a.Content)))
+}
+
+// Markdown returns the article as a markdown document, titled and followed by
+// a line with its author, site, publication date and reading time.
+func (a *Article) Markdown() string {
+	var b strings.Builder
+	if a.Title != "" {
+		b.WriteString("# " + Escape(a.Title) + "\n\n")
+	}
+	var details []string
+	for _, s := range []string{a.Byline, a.SiteName} {
+		if s != "" {
+			details = append(details, Escape(s))
+		}
+	}
+	if !a.Published.IsZero() {
+		details = append(details, a.Published.Format("January 2, 2006"))
+	}
+	details = append(details, fmt.Sprintf("%d min read", int(a.ReadingTime().Minutes())))
+	b.WriteString("*" + strings.Join(details, " · ") + "*\n\n")
+	b.WriteString(a.Content)
+	return b.String()
+}
+
+// pageTitle returns the title of a page, preferring the one given for
+// sharing it, which usually leaves the name of the site out.
+func pageTitle(doc *html.Node) string {
+	if t := metaContent(doc, "og:title", "twitter:title"); t != "" {
+		return t
+	}
+	title := ""
+	if n := find(doc, atom.Title); n != nil {
+		title = OneLine(text(n))
+	}
+	// A heading that's part of the title is the title without the site.
+	if n := find(doc, atom.H1); n != nil {
+		if h := OneLine(text(n)); h != "" && (title == "" || strings.Contains(title, h)) {
+			return h
+		}
+	}
+	return title
+}
+
+// byline returns the author of a page.
+func byline(doc *html.Node) string {
+	if s := metaContent(doc, "author", "article:author"); s != "" && !strings.Contains(s, "://") {
+		return s
+	}
+	var found string
+	walk(doc, func(n *html.Node) bool {
+		if found != "" {
+			return false
+		}
+		if attr(n, "rel") == "author" || attr(n, "itemprop") == "author" ||
+			strings.Contains(strings.ToLower(attr(n, "class")), "byline") {
+			if s := OneLine(text(n)); s != "" && len(s) < 100 {
+				found = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(s, "By "), "by "))
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// metaContent returns the content of the first meta element with one of the
+// given names or properties.
+func metaContent(doc *html.Node, names ...string) string {
+	values := map[string]string{}
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Meta {
+			for _, key := range []string{attr(n, "name"), attr(n, "property")} {
+				if key = strings.ToLower(key); key != "" && values[key] == "" {
+					values[key] = strings.TrimSpace(attr(n, "content"))
+				}
+			}
+		}
+		return n.DataAtom != atom.Body
+	})
+	for _, name := range names {
+		if v := values[name]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// removeClutter removes the elements that are left out of documents, hidden
+// ones, and those whose classes or ids tell they aren't part of an article.
+func removeClutter(doc *html.Node) {
+	var clutter []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom { //nolint:exhaustive
+		case atom.Html, atom.Body, atom.Article, atom.Main, atom.A:
+			return true
+		}
+		match := attr(n, "class") + " " + attr(n, "id")
+		if skipped[n.DataAtom] || hidden(n) ||
+			(unlikelyPattern.MatchString(match) && !maybePattern.MatchString(match)) {
+			clutter = append(clutter, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range clutter {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// hidden reports whether an element isn't shown.
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "hidden",
+			a.Key == "aria-hidden" && a.Val == "true",
+			a.Key == "style" && strings.Contains(strings.ReplaceAll(a.Val, " ", ""), "display:none"):
+			return true
+		}
+	}
+	return false
+}
+
+// articleNodes returns the elements making up the article of a page: the one
+// holding the paragraphs that score best, and the siblings that seem to be
+// part of the same article.
+func articleNodes(doc *html.Node) []*html.Node {
+	body := find(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+
+	// Paragraphs score by their length and number of commas. Their parent
+	// gets their score, their grandparent half of it, and their further
+	// ancestors less.
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	walk(body, func(n *html.Node) bool {
+		if !scored(n) {
+			return true
+		}
+		t := OneLine(text(n))
+		if len(t) < 25 {
+			return true
+		}
+		score := 1 + float64(strings.Count(t, ",")) + min(float64(len(t)/100), 3)
+		level := 0
+		for p := n.Parent; p != nil && p.Type == html.ElementNode && level < 3; p = p.Parent {
+			if _, ok := scores[p]; !ok {
+				scores[p] = initialScore(p)
+				candidates = append(candidates, p)
+			}
+			divider := 1.0
+			switch {
+			case level == 1:
+				divider = 2
+			case level > 1:
+				divider = float64(level * 3)
+			}
+			scores[p] += score / divider
+			level++
+		}
+		return true
+	})
+
+	var top *html.Node
+	for _, n := range candidates {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+	if top == nil || top.Parent == nil {
+		return []*html.Node{body}
+	}
+
+	threshold := max(10, scores[top]*0.2)
+	var nodes []*html.Node
+	for sib := top.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+		if sib.Type != html.ElementNode {
+			continue
+		}
+		include := sib == top
+		if score, ok := scores[sib]; ok && score >= threshold {
+			include = true
+		}
+		if sib.DataAtom == atom.P {
+			t, density := OneLine(text(sib)), linkDensity(sib)
+			if (len(t) > 80 && density < 0.25) ||
+				(len(t) > 0 && density == 0 && sentencePattern.MatchString(t)) {
+				include = true
+			}
+		}
+		if include {
+			nodes = append(nodes, sib)
+		}
+	}
+	return nodes
+}
+
+// scored reports whether an element is scored like a paragraph: paragraphs,
+// code blocks, table cells, and containers holding text but no blocks.
+func scored(n *html.Node) bool {
+	switch n.DataAtom { //nolint:exhaustive
+	case atom.P, atom.Pre, atom.Td:
+		return true
+	case atom.Div, atom.Section:
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			if ch.Type == html.ElementNode && blocks[ch.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore scores an element by its type, and by whether its class and
+// id are those of article content.
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom { //nolint:exhaustive
+	case atom.Div, atom.Article, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, s := range []string{attr(n, "class"), attr(n, "id")} {
+		if s == "" {
+			continue
+		}
+		if negativePattern.MatchString(s) {
+			score -= 25
+		}
+		if positivePattern.MatchString(s) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity returns the part of the text of an element that is in links.
+func linkDensity(n *html.Node) float64 {
+	total := len(OneLine(text(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(ch *html.Node) bool {
+		if ch.DataAtom == atom.A {
+			links += len(OneLine(text(ch)))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// walk calls f with the elements below n, in document order, skipping the
+// elements below those f returns false for.
+func walk(n *html.Node, f func(*html.Node) bool) {
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && !f(ch) {
+			continue
+		}
+		walk(ch, f)
+	}
+}
@@ -0,0 +1,434 @@
+// Package htmlmd converts HTML pages to markdown, so that web pages can be
+// rendered like any other document. The main content of a page is kept, and
+// navigation, scripts and forms are left out.
+package htmlmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	spacePattern  = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankPattern  = regexp.MustCompile(`\n{3,}`)
+	langPattern   = regexp.MustCompile(`(?:^|\s)(?:language|lang)-(\S+)`)
+	escapePattern = regexp.MustCompile("([\\\\`*_\\[\\]<])")
+)
+
+// Elements left out of the document, with their content.
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Nav: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Select: true, atom.Input: true,
+	atom.Textarea: true, atom.Svg: true, atom.Iframe: true, atom.Object: true,
+	atom.Canvas: true, atom.Dialog: true,
+}
+
+// Elements starting a block of their own.
+var blocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Body: true,
+	atom.Dd: true, atom.Details: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figcaption: true, atom.Figure: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Summary: true, atom.Table: true,
+	atom.Ul: true,
+}
+
+// Convert reads an HTML page and returns its main content as markdown. The
+// page is decoded from the charset given in contentType, or declared by the
+// page itself. Links and images are resolved against base, if given.
+func Convert(r io.Reader, contentType string, base *url.URL) (string, error) {
+	doc, err := parse(r, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	c := converter{base: base}
+	root := find(doc, atom.Main)
+	if root == nil {
+		root = find(doc, atom.Article)
+	}
+	if root == nil {
+		root = doc
+	}
+
+	// Pages without a heading of their own are titled after the page.
+	md := c.blocks(root)
+	if title := find(doc, atom.Title); title != nil && find(root, atom.H1) == nil {
+		if t := strings.TrimSpace(spacePattern.ReplaceAllString(text(title), " ")); t != "" {
+			md = "# " + Escape(t) + "\n\n" + md
+		}
+	}
+	return strings.TrimSpace(blankPattern.ReplaceAllString(md, "\n\n")) + "\n", nil
+}
+
+// parse decodes and parses an HTML page.
+func parse(r io.Reader, contentType string) (*html.Node, error) {
+	r, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode page: %w", err)
+	}
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse html: %w", err)
+	}
+	return doc, nil
+}
+
+type converter struct {
+	base *url.URL
+}
+
+// blocks converts the children of n, separating blocks by blank lines.
+// Consecutive inline content makes up a paragraph.
+func (c converter) blocks(n *html.Node) string {
+	var (
+		parts []string
+		para  strings.Builder
+	)
+	flush := func() {
+		if s := Paragraph(para.String()); s != "" {
+			parts = append(parts, s)
+		}
+		para.Reset()
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && (blocks[ch.DataAtom] || skipped[ch.DataAtom]) {
+			flush()
+			if s := c.block(ch); s != "" {
+				parts = append(parts, s)
+			}
+			continue
+		}
+		if ch.Type == html.DocumentNode || (ch.Type == html.ElementNode && ch.DataAtom == atom.Html) {
+			flush()
+			parts = append(parts, c.blocks(ch))
+			continue
+		}
+		para.WriteString(c.inline(ch))
+	}
+	flush()
+	return strings.Join(parts, "\n\n")
+}
+
+// block converts a block element.
+func (c converter) block(n *html.Node) string {
+	switch n.DataAtom { //nolint:exhaustive
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		title := OneLine(c.inlines(n))
+		if title == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + title
+	case atom.Pre:
+		return codeBlock(n)
+	case atom.Blockquote:
+		return Prefix(c.blocks(n), "> ", "> ")
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+	case atom.Hr:
+		return "---"
+	case atom.Table:
+		return c.table(n)
+	case atom.Dt:
+		if s := strings.TrimSpace(c.inlines(n)); s != "" {
+			return "**" + s + "**"
+		}
+		return ""
+	case atom.Dd:
+		return Prefix(c.blocks(n), "  ", "  ")
+	}
+	if skipped[n.DataAtom] {
+		return ""
+	}
+	return c.blocks(n)
+}
+
+// list converts the items of a list.
+func (c converter) list(n *html.Node) string {
+	var items []string
+	num := 1
+	if s, err := strconv.Atoi(attr(n, "start")); err == nil {
+		num = s
+	}
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		content := c.blocks(li)
+		if content == "" {
+			continue
+		}
+		items = append(items, Prefix(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts a table to a GFM table, its first row being the header.
+func (c converter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			if ch.Type != html.ElementNode {
+				continue
+			}
+			switch ch.DataAtom { //nolint:exhaustive
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(ch)
+			case atom.Tr:
+				var row []string
+				for cell := ch.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Th || cell.DataAtom == atom.Td) {
+						row = append(row, strings.ReplaceAll(OneLine(c.inlines(cell)), "|", `\|`))
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	walk(n)
+	return Table(rows)
+}
+
+// inlines converts the children of n as inline content.
+func (c converter) inlines(n *html.Node) string {
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(c.inline(ch))
+	}
+	return b.String()
+}
+
+// inline converts an inline node.
+func (c converter) inline(n *html.Node) string {
+	switch n.Type { //nolint:exhaustive
+	case html.TextNode:
+		return Escape(spacePattern.ReplaceAllString(n.Data, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if skipped[n.DataAtom] {
+		return ""
+	}
+
+	switch n.DataAtom { //nolint:exhaustive
+	case atom.Br:
+		return "\\\n"
+	case atom.A:
+		s := c.inlines(n)
+		href := c.resolve(attr(n, "href"))
+		if strings.TrimSpace(s) == "" || href == "" || strings.HasPrefix(href, "javascript:") {
+			return s
+		}
+		return "[" + strings.TrimSpace(s) + "](" + destination(href) + ")"
+	case atom.Img:
+		src := c.resolve(attr(n, "src"))
+		if src == "" {
+			return ""
+		}
+		return "![" + Escape(attr(n, "alt")) + "](" + destination(src) + ")"
+	case atom.Strong, atom.B:
+		return Wrap(c.inlines(n), "**")
+	case atom.Em, atom.I, atom.Cite:
+		return Wrap(c.inlines(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return Wrap(c.inlines(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return CodeSpan(spacePattern.ReplaceAllString(text(n), " "))
+	}
+	return c.inlines(n)
+}
+
+// resolve resolves a link against the base URL of the page.
+func (c converter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || c.base == nil {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// codeBlock converts a pre element to a fenced code block, taking its
+// language from a language-* class.
+func codeBlock(n *html.Node) string {
+	lang := ""
+	for _, el := range []*html.Node{n, find(n, atom.Code)} {
+		if el == nil {
+			continue
+		}
+		if m := langPattern.FindStringSubmatch(attr(el, "class")); m != nil {
+			lang = m[1]
+			break
+		}
+	}
+	return CodeFence(strings.Trim(text(n), "\n"), lang)
+}
+
+// CodeFence formats code as a fenced code block, its fence longer than the
+// runs of backticks of code.
+func CodeFence(code, lang string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// Table formats rows as a GFM table, its first row being the header.
+func Table(rows [][]string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.WriteString("| " + cell + " ")
+		}
+		b.WriteString("|\n")
+	}
+	writeRow(rows[0])
+	for i := 0; i < cols; i++ {
+		b.WriteString("| --- ")
+	}
+	b.WriteString("|\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// CodeSpan wraps text in enough backticks to hold the ones it contains.
+func CodeSpan(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	ticks := "`"
+	for strings.Contains(s, ticks) {
+		ticks += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return ticks + s + ticks
+}
+
+// Wrap surrounds inline content with a delimiter, keeping the whitespace
+// around it outside.
+func Wrap(s, delim string) string {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return s
+	}
+	i := strings.Index(s, t)
+	return s[:i] + delim + t + delim + s[i+len(t):]
+}
+
+// Paragraph trims inline content, including the lines after line breaks.
+func Paragraph(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimLeft(l, " ")
+	}
+	return strings.TrimSuffix(strings.Join(lines, "\n"), "\\")
+}
+
+// Prefix prefixes the first line of s with first, and the others with rest,
+// without leaving trailing spaces on blank lines.
+func Prefix(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if l == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + l
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Escape escapes the characters of text that markdown would take as syntax.
+func Escape(s string) string {
+	return escapePattern.ReplaceAllString(s, `\$1`)
+}
+
+// destination formats a URL as a link destination.
+func destination(u string) string {
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
+
+// find returns the first element of the given type below n.
+func find(n *html.Node, a atom.Atom) *html.Node {
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && ch.DataAtom == a {
+			return ch
+		}
+		if found := find(ch, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// text returns the text below n.
+func text(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(text(ch))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// OneLine joins the lines of inline content, for headings and table cells.
+func OneLine(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\\\n", " ")), " ")
+}
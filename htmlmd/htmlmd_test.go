@@ -0,0 +1,119 @@
+package htmlmd
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const page = `<!doctype html>
+<html>
+<head><title>Ignored</title><style>p { color: red }</style></head>
+<body>
+<nav><a href="/">Home</a></nav>
+<main>
+  <h1>Guide <small>v2</small></h1>
+  <p>Read   the <a href="intro.html">intro</a>, <em>then</em> run <code>make *</code>.<br>
+  Or ask [us].</p>
+  <img src="/logo.png" alt="Logo">
+  <ol start="3"><li>three</li><li><p>four</p><ul><li>nested</li></ul></li></ol>
+  <pre class="language-go"><code>fmt.Println("` + "```" + `")
+</code></pre>
+  <blockquote><p>One</p><p>Two</p></blockquote>
+  <table><thead><tr><th>Key</th><th>Value</th></tr></thead>
+  <tbody><tr><td>a|b</td><td><b>c</b></td></tr><tr><td>d</td></tr></tbody></table>
+  <script>alert(1)</script>
+</main>
+<footer>Copyright</footer>
+</body>
+</html>`
+
+func TestConvert(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/guide.html")
+	got, err := Convert(strings.NewReader(page), "text/html; charset=utf-8", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Guide v2\n\n" +
+		"Read the [intro](https://example.com/docs/intro.html), *then* run `make *`.\\\n" +
+		"Or ask \\[us\\].\n\n" +
+		"![Logo](https://example.com/logo.png)\n\n" +
+		"3. three\n" +
+		"4. four\n\n" +
+		"   - nested\n\n" +
+		"````go\nfmt.Println(\"```\")\n````\n\n" +
+		"> One\n>\n> Two\n\n" +
+		"| Key | Value |\n| --- | --- |\n| a\\|b | **c** |\n| d |  |\n"
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestConvertTitle(t *testing.T) {
+	got, err := Convert(strings.NewReader("<title>A &amp; B</title><p>Text</p>"), "text/html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# A & B\n\nText\n"; got != want {
+		t.Errorf("expected the page to be titled, got %q", got)
+	}
+}
+
+func TestConvertCharset(t *testing.T) {
+	got, err := Convert(strings.NewReader("<p>caf\xe9</p>"), "text/html; charset=iso-8859-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "café\n" {
+		t.Errorf("expected the page to be decoded, got %q", got)
+	}
+}
+
+const articlePage = `<!doctype html>
+<html>
+<head>
+<title>Why Terminals Matter | The Blog</title>
+<meta property="og:site_name" content="The Blog">
+<meta name="author" content="Jane Doe">
+<meta property="article:published_time" content="2024-05-01T09:00:00Z">
+</head>
+<body>
+<div class="site-header"><a href="/">The Blog</a> <a href="/about">About</a></div>
+<div id="layout">
+  <div class="sidebar"><p>Popular posts, tags, archives and everything else, sorted by date.</p></div>
+  <div class="post-body">
+    <h1>Why Terminals Matter</h1>
+    <p>Terminals are fast, scriptable, and everywhere, which is why so many tools still live in them.</p>
+    <div class="share-buttons"><a href="/share">Share this, please, on every network</a></div>
+    <p>Rendering markdown in a terminal, with colors, tables and code, makes <a href="/docs">documentation</a> pleasant to read.</p>
+    <p hidden>Hidden text that should never be shown, even though it is long enough.</p>
+  </div>
+  <div class="comments"><p>Great post, thanks, I agree with everything, really, truly.</p></div>
+</div>
+<div class="footer">Copyright, all rights reserved, and so on and so forth.</div>
+</body>
+</html>`
+
+func TestRead(t *testing.T) {
+	base, _ := url.Parse("https://blog.example.com/posts/terminals")
+	a, err := Read(strings.NewReader(articlePage), "text/html", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Title != "Why Terminals Matter" || a.Byline != "Jane Doe" || a.SiteName != "The Blog" ||
+		a.Published.Format(time.DateOnly) != "2024-05-01" {
+		t.Errorf("unexpected metadata: %+v", a)
+	}
+	want := "Terminals are fast, scriptable, and everywhere, which is why so many tools still live in them.\n\n" +
+		"Rendering markdown in a terminal, with colors, tables and code, makes " +
+		"[documentation](https://blog.example.com/docs) pleasant to read.\n"
+	if a.Content != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, a.Content)
+	}
+	if md := a.Markdown(); !strings.HasPrefix(md, "# Why Terminals Matter\n\n*Jane Doe · The Blog · May 1, 2024 · 1 min read*\n\n") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+}
@@ -0,0 +1,138 @@
+// Package httpcache keeps copies of fetched documents on disk, so that they
+// can be revalidated with their ETag or Last-Modified date instead of being
+// downloaded again, and read without a network connection.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry describes a cached response.
+type Entry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// FromResponse returns the entry for a response, fetched now.
+func FromResponse(url string, resp *http.Response) Entry {
+	return Entry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		Fetched:      time.Now(),
+	}
+}
+
+// Revalidate sets the headers asking the server to only send the document
+// again if it changed since it was cached. It reports false if the entry
+// has nothing to revalidate with.
+func (e Entry) Revalidate(h http.Header) bool {
+	if e.ETag != "" {
+		h.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		h.Set("If-Modified-Since", e.LastModified)
+	}
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// Store is a cache of documents in a directory. Each document is stored in a
+// file named after the hash of its URL, next to a JSON file describing it.
+type Store struct {
+	dir string
+}
+
+// Open returns the cache in dir, which is created once something is cached.
+func Open(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16]))
+}
+
+// Get returns the entry of the document cached for a URL.
+func (s *Store) Get(url string) (Entry, bool) {
+	var e Entry
+	b, err := os.ReadFile(s.path(url) + ".json")
+	if err != nil || json.Unmarshal(b, &e) != nil || e.URL != url {
+		return Entry{}, false
+	}
+	if _, err := os.Stat(s.path(url)); err != nil {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Body opens the document cached for a URL. The caller must close it.
+func (s *Store) Body(url string) (*os.File, error) {
+	f, err := os.Open(s.path(url))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open cached document: %w", err)
+	}
+	return f, nil
+}
+
+// Put caches a document, replacing any earlier copy.
+func (s *Store) Put(e Entry, body []byte) error {
+	meta, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	p := s.path(e.URL)
+	if err := writeFile(p, body); err != nil {
+		return err
+	}
+	return writeFile(p+".json", meta)
+}
+
+// Touch records that the document cached for a URL was found to be up to
+// date.
+func (s *Store) Touch(url string) error {
+	e, ok := s.Get(url)
+	if !ok {
+		return fs.ErrNotExist
+	}
+	e.Fetched = time.Now()
+	meta, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode cache entry: %w", err)
+	}
+	return writeFile(s.path(url)+".json", meta)
+}
+
+// writeFile replaces a file at once, so that readers never see it half
+// written.
+func writeFile(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("unable to create cache file: %w", err)
+	}
+	_, err = f.Write(b)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("unable to write cache file: %w", err)
+	}
+	return nil
+}
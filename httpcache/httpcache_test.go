@@ -0,0 +1,54 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	s := Open(t.TempDir())
+	const u = "https://example.com/README.md"
+	if _, ok := s.Get(u); ok {
+		t.Fatal("expected an empty cache")
+	}
+
+	e := Entry{URL: u, ETag: `"abc"`, ContentType: "text/markdown"}
+	if err := s.Put(e, []byte("# Hello")); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := s.Get(u)
+	if !ok || got.ETag != e.ETag || got.ContentType != e.ContentType {
+		t.Fatalf("expected %+v, got %+v", e, got)
+	}
+	f, err := s.Body(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	_ = f.Close()
+	if string(b) != "# Hello" {
+		t.Errorf("expected the cached body, got %q", b)
+	}
+
+	if err := s.Touch(u); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get(u); !got.Fetched.After(e.Fetched) {
+		t.Errorf("expected the entry to be touched, got %v", got.Fetched)
+	}
+	if _, ok := s.Get("https://example.com/other.md"); ok {
+		t.Error("expected other URLs not to be cached")
+	}
+}
+
+func TestRevalidate(t *testing.T) {
+	h := http.Header{}
+	if (Entry{}).Revalidate(h) || len(h) != 0 {
+		t.Errorf("expected nothing to revalidate with, got %v", h)
+	}
+	e := Entry{ETag: `"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	if !e.Revalidate(h) || h.Get("If-None-Match") != e.ETag || h.Get("If-Modified-Since") != e.LastModified {
+		t.Errorf("expected conditional headers, got %v", h)
+	}
+}
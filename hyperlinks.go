@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/links"
+	"golang.org/x/term"
+)
+
+// Whether rendered links are made clickable with OSC 8 escape sequences.
+const (
+	hyperlinksAuto   = "auto"
+	hyperlinksAlways = "always"
+	hyperlinksNever  = "never"
+)
+
+var hyperlinksModes = []string{hyperlinksAuto, hyperlinksAlways, hyperlinksNever}
+
+// validateHyperlinks checks the value given for --hyperlinks.
+func validateHyperlinks(mode string) error {
+	if !slices.Contains(hyperlinksModes, mode) {
+		return fmt.Errorf("invalid hyperlinks mode %q, expected one of: %s", mode, strings.Join(hyperlinksModes, ", "))
+	}
+	return nil
+}
+
+// useHyperlinks reports whether links written to w are made clickable. In
+// auto mode, they are when w is a terminal known to support them, but not
+// when the output is paged, as pagers may show the escape sequences. Plain
+// text and markdown never have them.
+func useHyperlinks(w io.Writer, paged bool) bool {
+	if outputFormat != formatANSI {
+		return false
+	}
+	switch hyperlinksMode {
+	case hyperlinksAlways:
+		return true
+	case hyperlinksNever:
+		return false
+	}
+	if paged {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd())) && terminalSupportsHyperlinks(os.Getenv)
+}
+
+// terminalSupportsHyperlinks guesses from the environment whether the
+// terminal supports OSC 8 hyperlinks. FORCE_HYPERLINK=1 or 0 overrides the
+// guess.
+func terminalSupportsHyperlinks(getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		return force != "0"
+	}
+
+	termName := getenv("TERM")
+	if termName == "dumb" {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio":
+		return true
+	}
+	for _, v := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM"} {
+		if getenv(v) != "" {
+			return true
+		}
+	}
+	// VTE based terminals, like GNOME Terminal, since 0.50
+	if v, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	for _, name := range []string{"kitty", "alacritty", "foot", "wezterm", "ghostty"} {
+		if strings.Contains(termName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hyperlink makes the links in rendered output of src clickable. Relative
+// links of local documents, shown resolved against baseURL, point to the
+// files they refer to.
+func hyperlink(out string, src *source, markdown, baseURL string) string {
+	info, err := os.Stat(src.URL)
+	if err != nil || info.IsDir() {
+		return links.Hyperlink(out, nil)
+	}
+
+	base, _ := url.Parse(baseURL)
+	dir := filepath.Dir(src.URL)
+	targets := map[string]string{}
+	for _, l := range links.Extract(markdown) {
+		u, err := url.Parse(l.URL)
+		if err != nil || u.IsAbs() || u.Path == "" {
+			continue
+		}
+		// As shown by glamour
+		shown := l.URL
+		if baseURL != "" {
+			u.Path = strings.TrimPrefix(u.Path, "/")
+			shown = base.ResolveReference(u).String()
+		}
+		path := u.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		target := url.URL{Scheme: "file", Path: filepath.ToSlash(path), Fragment: u.Fragment}
+		targets[shown] = target.String()
+	}
+	return links.Hyperlink(out, targets)
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestTerminalSupportsHyperlinks(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"TERM": "xterm-256color"}, false},
+		{map[string]string{"TERM": "xterm-kitty"}, true},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, true},
+		{map[string]string{"VTE_VERSION": "7600"}, true},
+		{map[string]string{"VTE_VERSION": "4205"}, false},
+		{map[string]string{"TERM": "dumb", "WT_SESSION": "1"}, false},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "FORCE_HYPERLINK": "0"}, false},
+		{map[string]string{"FORCE_HYPERLINK": "1"}, true},
+	} {
+		getenv := func(k string) string { return tc.env[k] }
+		if got := terminalSupportsHyperlinks(getenv); got != tc.want {
+			t.Errorf("%v: expected %v, got %v", tc.env, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Characters of increasing density, for ASCII halftones. Brighter parts of
+// the image are drawn denser, as terminals are mostly dark.
+const ramp = " .:-=+*#%@"
+
+// Most rows a halftone takes.
+const maxHalftoneRows = 32
+
+// Halftone draws an image in lines of at most width characters, keeping its
+// aspect ratio. In color, each character is two pixels, one above the other,
+// drawn as a half block; otherwise each is a character of the brightness of
+// the pixels under it.
+func Halftone(img image.Image, width int, color bool) []string {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 || width <= 0 {
+		return nil
+	}
+	// Characters are about twice as tall as they're wide.
+	cols := min(width, b.Dx())
+	rows := max(cols*b.Dy()/b.Dx()/2, 1)
+	if rows > maxHalftoneRows {
+		rows = maxHalftoneRows
+		cols = max(rows*2*b.Dx()/b.Dy(), 1)
+	}
+
+	lines := make([]string, rows)
+	if !color {
+		for y := range rows {
+			var line strings.Builder
+			for x := range cols {
+				c := average(img, cell(b, x, y, cols, rows))
+				line.WriteByte(ramp[int(luminance(c)*float64(len(ramp)-1)+0.5)])
+			}
+			lines[y] = strings.TrimRight(line.String(), " ")
+		}
+		return lines
+	}
+
+	for y := range rows {
+		var line strings.Builder
+		for x := range cols {
+			top := average(img, cell(b, x, 2*y, cols, 2*rows))
+			bottom := average(img, cell(b, x, 2*y+1, cols, 2*rows))
+			line.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color(hex(top))).
+				Background(lipgloss.Color(hex(bottom))).
+				Render("▀"))
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
+
+// rgb is a color with 8 bits per channel.
+type rgb struct{ r, g, b uint32 }
+
+// cell returns the pixels of the cell at x, y of a grid of cols and rows
+// over b.
+func cell(b image.Rectangle, x, y, cols, rows int) image.Rectangle {
+	r := image.Rect(
+		b.Min.X+x*b.Dx()/cols, b.Min.Y+y*b.Dy()/rows,
+		b.Min.X+(x+1)*b.Dx()/cols, b.Min.Y+(y+1)*b.Dy()/rows,
+	)
+	// Grids finer than the image repeat its pixels.
+	if r.Dx() == 0 {
+		r.Max.X = r.Min.X + 1
+	}
+	if r.Dy() == 0 {
+		r.Max.Y = r.Min.Y + 1
+	}
+	return r.Intersect(b)
+}
+
+// average returns the average color of the pixels in r, blended over black
+// where they're transparent.
+func average(img image.Image, r image.Rectangle) rgb {
+	var sr, sg, sb, n uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cr, cg, cb, _ := img.At(x, y).RGBA()
+			sr, sg, sb = sr+uint64(cr), sg+uint64(cg), sb+uint64(cb)
+			n++
+		}
+	}
+	if n == 0 {
+		return rgb{}
+	}
+	return rgb{uint32(sr / n >> 8), uint32(sg / n >> 8), uint32(sb / n >> 8)} //nolint:gosec
+}
+
+// luminance returns the perceived brightness of c, from 0 to 1.
+func luminance(c rgb) float64 {
+	return (0.2126*float64(c.r) + 0.7152*float64(c.g) + 0.0722*float64(c.b)) / 255
+}
+
+func hex(c rgb) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
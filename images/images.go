@@ -0,0 +1,248 @@
+// Package images shows the images standing alone in their paragraph, which a
+// terminal can't display, as boxes with their alt text and dimensions, and
+// optionally a halftone of the picture drawn with characters.
+package images
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // Decode GIF images.
+	_ "image/jpeg" // Decode JPEG images.
+	_ "image/png"  // Decode PNG images.
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/douglas-larocca/glow/v2/placeholder"
+	_ "golang.org/x/image/webp" // Decode WebP images.
+)
+
+// Mode controls how images are shown.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeBox shows images as boxes with their alt text and dimensions.
+	ModeBox Mode = "box"
+	// ModeASCII also draws a halftone of the image in the box.
+	ModeASCII Mode = "ascii"
+	// ModeLink leaves images as the renderer shows them, as links.
+	ModeLink Mode = "link"
+)
+
+// Modes lists all valid modes.
+var Modes = []Mode{ModeBox, ModeASCII, ModeLink}
+
+// ParseMode validates a mode string.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid images mode %q: use box, ascii or link", s)
+}
+
+// Image is an image taken out of a document.
+type Image struct {
+	Alt   string
+	Src   string
+	Title string
+}
+
+var marker = placeholder.New("GLOWIMAGE")
+
+var (
+	// ![alt](src), ![alt](<src> "title").
+	imagePattern = regexp.MustCompile(`^ {0,3}!\[([^\]]*)\]\(\s*<?([^\s<>()]+)>?(?:\s+(?:"([^"]*)"|'([^']*)'))?\s*\)\s*$`)
+	fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Extract takes the images that are a paragraph of their own out of a
+// markdown document, leaving a placeholder paragraph for each that Render
+// replaces with the image. Images within text are left as they are.
+func Extract(markdown string, mode Mode) (string, []Image) {
+	if mode == ModeLink {
+		return markdown, nil
+	}
+	var images []Image
+	md := standalone(markdown, func(img Image, _ string) string {
+		images = append(images, img)
+		return marker.Text(len(images) - 1)
+	})
+	return md, images
+}
+
+// Resolve rewrites the sources of the images Extract takes out of a
+// document with resolve, e.g. to make them absolute.
+func Resolve(markdown string, resolve func(src string) string) string {
+	return standalone(markdown, func(img Image, line string) string {
+		i := strings.Index(line, "](") + 2
+		j := i + strings.Index(line[i:], img.Src)
+		return line[:j] + resolve(img.Src) + line[j+len(img.Src):]
+	})
+}
+
+// From returns a resolver for Resolve making the sources of the images of
+// the document at base, a path or a URL, absolute.
+func From(base string) func(src string) string {
+	return func(src string) string {
+		if base == "" || strings.Contains(src, "://") || strings.HasPrefix(src, "data:") || path.IsAbs(src) {
+			return src
+		}
+		if u, err := url.Parse(base); err == nil && u.Scheme != "" && u.Host != "" {
+			ref, err := url.Parse(src)
+			if err != nil {
+				return src
+			}
+			return u.ResolveReference(ref).String()
+		}
+		if s, err := url.PathUnescape(src); err == nil {
+			src = s
+		}
+		return filepath.Join(filepath.Dir(base), filepath.FromSlash(src))
+	}
+}
+
+// standalone replaces the lines of a document that are an image alone in
+// their paragraph with what fn returns for them.
+func standalone(markdown string, fn func(img Image, line string) string) string {
+	if !strings.Contains(markdown, "![") {
+		return markdown
+	}
+
+	var (
+		lines = strings.Split(markdown, "\n")
+		fence string
+	)
+	blank := func(i int) bool {
+		return i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == ""
+	}
+	for i, line := range lines {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" || !blank(i-1) || !blank(i+1) {
+			continue
+		}
+		m := imagePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		lines[i] = fn(Image{Alt: strings.TrimSpace(m[1]), Src: m[2], Title: m[3] + m[4]}, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Options configure how images are shown.
+type Options struct {
+	// Width the document is rendered at. Defaults to 80.
+	Width int
+	Mode  Mode
+	// Style of the document, whose image and image text colors the boxes
+	// are drawn in.
+	Style ansi.StyleConfig
+	// Open opens the image at src, as written in the document. Images that
+	// can't be opened are shown without their dimensions or halftone.
+	Open func(src string) (io.ReadCloser, error)
+	// Whether the halftone is drawn with colored blocks, rather than ASCII
+	// characters of the brightness of the image.
+	Color bool
+}
+
+// Render replaces the placeholders left by Extract in rendered output with
+// the images, drawn as boxes indented as their placeholder.
+func Render(rendered string, images []Image, opts Options) string {
+	if len(images) == 0 {
+		return rendered
+	}
+	if opts.Width <= 0 {
+		opts.Width = 80
+	}
+
+	return marker.Replace(rendered, len(images), func(n, indent int) string {
+		width := opts.Width - indent
+		if opts.Style.Document.Margin != nil {
+			width -= int(*opts.Style.Document.Margin) //nolint:gosec
+		}
+		return renderImage(images[n], max(width, 16), opts)
+	})
+}
+
+// renderImage draws an image as a box at most width wide.
+func renderImage(img Image, width int, opts Options) string {
+	var (
+		pic    image.Image
+		config image.Config
+		known  bool
+	)
+	if opts.Open != nil {
+		if r, err := opts.Open(img.Src); err == nil {
+			if opts.Mode == ModeASCII {
+				if pic, _, err = image.Decode(r); err == nil {
+					b := pic.Bounds()
+					config, known = image.Config{Width: b.Dx(), Height: b.Dy()}, true
+				}
+			} else {
+				config, _, err = image.DecodeConfig(r)
+				known = err == nil
+			}
+			_ = r.Close()
+		}
+	}
+
+	alt := img.Alt
+	if alt == "" {
+		alt = img.Title
+	}
+	if alt == "" {
+		alt = "Image"
+	}
+	meta := path.Base(img.Src)
+	if known {
+		meta = fmt.Sprintf("%d×%d · %s", config.Width, config.Height, meta)
+	}
+
+	inner := width - 4
+	lines := []string{
+		primitive(opts.Style.ImageText).Bold(true).Render(truncate(alt, inner)),
+		primitive(opts.Style.Image).Render(truncate(meta, inner)),
+	}
+	if pic != nil {
+		lines = append(lines, "")
+		lines = append(lines, Halftone(pic, min(inner, 60), opts.Color)...)
+	}
+
+	border := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(0, 1)
+	if c := opts.Style.Image.Color; c != nil {
+		border = border.BorderForeground(lipgloss.Color(*c))
+	}
+	return border.Render(strings.Join(lines, "\n"))
+}
+
+// primitive returns the lipgloss style of the colors of a style element.
+func primitive(p ansi.StylePrimitive) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	if p.Color != nil {
+		s = s.Foreground(lipgloss.Color(*p.Color))
+	}
+	return s
+}
+
+func truncate(s string, width int) string {
+	return xansi.Truncate(s, width, "…")
+}
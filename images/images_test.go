@@ -0,0 +1,104 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestExtract(t *testing.T) {
+	src := "# Title\n\n![A diagram](img/diagram.png \"The flow\")\n\nText with ![inline](a.png) image.\n\n" +
+		"```\n![in code](b.png)\n```\n\n![alt](<c d.png>)\nand text\n"
+	md, images := Extract(src, ModeBox)
+	want := "# Title\n\nGLOWIMAGE0X\n\nText with ![inline](a.png) image.\n\n" +
+		"```\n![in code](b.png)\n```\n\n![alt](<c d.png>)\nand text\n"
+	if md != want {
+		t.Errorf("unexpected document:\n%q\nwant:\n%q", md, want)
+	}
+	if !slices.Equal(images, []Image{{"A diagram", "img/diagram.png", "The flow"}}) {
+		t.Errorf("unexpected images %v", images)
+	}
+
+	if md, images := Extract(src, ModeLink); md != src || images != nil {
+		t.Error("expected images to be left as links")
+	}
+}
+
+func testImage(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for x := range w {
+		for y := range h {
+			img.SetGray(x, y, color.Gray{uint8(x * 255 / (w - 1))})
+		}
+	}
+	return img
+}
+
+func TestRender(t *testing.T) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, testImage(40, 20)); err != nil {
+		t.Fatal(err)
+	}
+	open := func(src string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b.Bytes())), nil
+	}
+
+	rendered := "\n  Text\n\n  GLOWIMAGE0X\n"
+	images := []Image{{Alt: "A gradient", Src: "img/gradient.png"}}
+	out := xansi.Strip(Render(rendered, images, Options{Width: 40, Mode: ModeBox, Open: open}))
+	if !strings.Contains(out, "  │ A gradient") || !strings.Contains(out, "│ 40×20 · gradient.png") {
+		t.Errorf("expected the alt text and dimensions in a box, got:\n%s", out)
+	}
+
+	out = xansi.Strip(Render(rendered, images, Options{Width: 40, Mode: ModeASCII, Open: open}))
+	if !strings.Contains(out, "...::::----===++++****####%%%@@") {
+		t.Errorf("expected a halftone of the gradient, got:\n%s", out)
+	}
+
+	out = xansi.Strip(Render(rendered, images, Options{Width: 40, Mode: ModeBox}))
+	if !strings.Contains(out, "│ gradient.png") {
+		t.Errorf("expected the file name without dimensions, got:\n%s", out)
+	}
+}
+
+func TestHalftone(t *testing.T) {
+	lines := Halftone(testImage(100, 10), 10, false)
+	if !slices.Equal(lines, []string{" .:-=+*#%@"}) {
+		t.Errorf("unexpected halftone %q", lines)
+	}
+	if lines := Halftone(testImage(10, 1000), 40, false); len(lines) != maxHalftoneRows {
+		t.Errorf("expected the halftone to be %d rows, got %d", maxHalftoneRows, len(lines))
+	}
+	if lines := Halftone(testImage(10, 10), 10, true); len(lines) != 5 {
+		t.Errorf("expected 5 rows of half blocks, got %d", len(lines))
+	}
+}
+
+func TestResolve(t *testing.T) {
+	src := "![img](img.png)\n\n![Alt img](<img.png> \"img\")\n\nText ![img](img.png)\n"
+	want := "![img](/docs/img.png)\n\n![Alt img](</docs/img.png> \"img\")\n\nText ![img](img.png)\n"
+	if got := Resolve(src, func(s string) string { return "/docs/" + s }); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	for _, tc := range []struct{ base, src, want string }{
+		{"/docs/guide.md", "img/a%20b.png", filepath.Join("/docs", "img", "a b.png")},
+		{"/docs/guide.md", "https://example.com/a.png", "https://example.com/a.png"},
+		{"https://example.com/docs/guide.md", "../a.png", "https://example.com/a.png"},
+		{"", "a.png", "a.png"},
+	} {
+		if got := From(tc.base)(tc.src); got != tc.want {
+			t.Errorf("%s from %s: expected %q, got %q", tc.src, tc.base, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/douglas-larocca/glow/v2/chunks"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+const defaultMaxSize = "64MB"
+
+// chunkedSize is the size above which documents written to the terminal or a
+// file are rendered a part at a time, holding only one part's output in
+// memory.
+const chunkedSize = 4 * chunks.Size
+
+var (
+	maxSizeStr = defaultMaxSize
+	maxSize, _ = parseMaxSize(defaultMaxSize)
+)
+
+var errTooBig = errors.New("document is larger than --max-size")
+
+// parseMaxSize parses the value given for --max-size. Zero means no limit.
+func parseMaxSize(s string) (int64, error) {
+	n, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max size %q: %w", s, err)
+	}
+	return int64(n), nil //nolint:gosec
+}
+
+// tooBig returns the error for a document of size bytes over --max-size.
+func tooBig(name string, size int64) error {
+	if name == "" {
+		name = "stdin"
+	}
+	return fmt.Errorf("%w of %s: %s is %s, set --max-size=0 to render it anyway", errTooBig,
+		humanize.Bytes(uint64(maxSize)), name, humanize.Bytes(uint64(size))) //nolint:gosec
+}
+
+// readContent reads a document, up to --max-size.
+func readContent(src *source) ([]byte, error) {
+	r := io.Reader(src.reader)
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read from reader: %w", err)
+	}
+	if maxSize > 0 && int64(len(b)) > maxSize {
+		size := int64(len(b))
+		if f, ok := src.reader.(*os.File); ok {
+			if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+				size = info.Size()
+			}
+		}
+		return nil, tooBig(src.URL, size)
+	}
+	return b, nil
+}
+
+// readFile reads a local document, up to --max-size.
+func readFile(path string) ([]byte, error) {
+	if info, err := os.Stat(path); err == nil && maxSize > 0 && info.Size() > maxSize {
+		return nil, tooBig(path, info.Size())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file: %w", err)
+	}
+	return b, nil
+}
+
+// renderChunked reports whether a document is written a part at a time,
+// rather than rendered whole first. Output that's paged, copied, or has its
+// links rewritten needs the whole of it.
+func renderChunked(cmd *cobra.Command, src *source, md string) bool {
+	return len(md) > chunkedSize &&
+		src.isMarkdown() &&
+		outputFormat != formatMarkdown &&
+		!pager && !cmd.Flags().Changed("pager") &&
+		!tui && !cmd.Flags().Changed("tui") &&
+		copyMode == ""
+}
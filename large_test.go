@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadContent(t *testing.T) {
+	defer func(size int64) { maxSize = size }(maxSize)
+	maxSize = 10
+
+	src := &source{reader: io.NopCloser(strings.NewReader("0123456789")), URL: "short.md"}
+	if b, err := readContent(src); err != nil || string(b) != "0123456789" {
+		t.Errorf("expected the whole document, got %q, %v", b, err)
+	}
+
+	src = &source{reader: io.NopCloser(strings.NewReader("0123456789a"))}
+	if _, err := readContent(src); !errors.Is(err, errTooBig) || !strings.Contains(err.Error(), "stdin") {
+		t.Errorf("expected a document too big to read from stdin, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "long.md")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFile(path); !errors.Is(err, errTooBig) || !strings.Contains(err.Error(), "100 B") {
+		t.Errorf("expected a file too big to read, got %v", err)
+	}
+
+	maxSize = 0
+	if b, err := readFile(path); err != nil || len(b) != 100 {
+		t.Errorf("expected no limit, got %d bytes, %v", len(b), err)
+	}
+}
@@ -0,0 +1,275 @@
+// Package latex converts LaTeX math in markdown documents into Unicode text
+// suitable for display in a terminal.
+package latex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+// Mode controls how math is treated.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeUnicode converts math into Unicode approximations, showing the
+	// source of math that can't be converted.
+	ModeUnicode Mode = "unicode"
+	// ModeSource shows the source of math, framed.
+	ModeSource Mode = "source"
+	// ModeOff leaves math untouched.
+	ModeOff Mode = "off"
+)
+
+// Modes lists all valid modes.
+var Modes = []Mode{ModeUnicode, ModeSource, ModeOff}
+
+// ParseMode validates a mode string.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid math mode %q: use unicode, source or off", s)
+}
+
+// ErrUnsupported is returned for math that can't be converted.
+var ErrUnsupported = errors.New("unsupported math")
+
+// Render converts LaTeX math into Unicode text, with superscripts,
+// subscripts, fractions and symbols approximated by Unicode characters.
+// Line breaks (\\) start a new line.
+func Render(src string) (string, error) {
+	p := parser{toks: tokenize(src)}
+	out, err := p.expr(false)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(out, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n"), nil
+}
+
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+
+// Transform rewrites the math in a markdown document according to mode:
+// inline math between single dollars, display math between double dollars
+// and fenced math blocks. Code is left alone.
+func Transform(markdown string, mode Mode) string {
+	if mode == ModeOff || !strings.Contains(markdown, "$") && !strings.Contains(markdown, "math") {
+		return markdown
+	}
+
+	var (
+		out     strings.Builder
+		fence   string
+		math    bool
+		display bool
+		body    []string
+	)
+	lines := strings.SplitAfter(markdown, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		if display {
+			body = append(body, line)
+			if strings.HasSuffix(strings.TrimSpace(trimmed), "$$") {
+				display = false
+				src := strings.Join(body, "")
+				src = strings.TrimSpace(src)
+				out.WriteString(block(src[2:len(src)-2], mode))
+			}
+			continue
+		}
+
+		if fence == "" {
+			if m := fencePattern.FindStringSubmatch(trimmed); m != nil {
+				fence = m[1]
+				math = m[2] == "math"
+				body = []string{line}
+				if math {
+					continue
+				}
+				out.WriteString(line)
+				continue
+			}
+			if s := strings.TrimSpace(trimmed); strings.HasPrefix(s, "$$") && len(trimmed)-len(strings.TrimLeft(trimmed, " ")) <= 3 {
+				if len(s) >= 4 && strings.HasSuffix(s, "$$") && !strings.Contains(s[2:len(s)-2], "$$") {
+					out.WriteString(block(s[2:len(s)-2], mode))
+					continue
+				}
+				display = true
+				body = []string{line}
+				continue
+			}
+			out.WriteString(inline(line, mode))
+			continue
+		}
+
+		closing := strings.TrimSpace(trimmed)
+		isClose := strings.HasPrefix(closing, fence[:1]) &&
+			strings.Trim(closing, fence[:1]) == "" &&
+			len(closing) >= len(fence)
+		if !math {
+			out.WriteString(line)
+			if isClose {
+				fence = ""
+			}
+			continue
+		}
+
+		if !isClose {
+			body = append(body, line)
+			continue
+		}
+		fence = ""
+		out.WriteString(block(strings.Join(body[1:], ""), mode))
+	}
+
+	// Unterminated math at the end of the document is left as is.
+	if display || fence != "" && math {
+		out.WriteString(strings.Join(body, ""))
+	}
+	return out.String()
+}
+
+// block returns the code block display math is shown as.
+func block(src string, mode Mode) string {
+	src = strings.Trim(src, "\n")
+	if mode == ModeUnicode {
+		if out, err := Render(src); err == nil {
+			return "```\n" + out + "\n```\n"
+		}
+	}
+	return "```\n" + frame(src) + "\n```\n"
+}
+
+// frame draws a box around the source of math.
+func frame(src string) string {
+	lines := strings.Split(strings.TrimRight(src, " \n"), "\n")
+	width := 0
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \r")
+		width = max(width, utils.Width(lines[i]))
+	}
+
+	const label = " math "
+	width = max(width, len(label))
+	var b strings.Builder
+	b.WriteString("┌─" + label + strings.Repeat("─", width-len(label)+1) + "┐\n")
+	for _, l := range lines {
+		b.WriteString("│ " + l + strings.Repeat(" ", width-utils.Width(l)) + " │\n")
+	}
+	b.WriteString("└" + strings.Repeat("─", width+2) + "┘")
+	return b.String()
+}
+
+// inline rewrites the inline math of a line, skipping code spans and
+// escaped dollars. Dollars only delimit math when the opening one isn't
+// followed by a space, and the closing one isn't preceded by a space or
+// followed by a digit, so amounts like $5 and $10 stay as they are.
+func inline(line string, mode Mode) string {
+	if !strings.Contains(line, "$") {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			b.WriteString(line[i : i+2])
+			i += 2
+			continue
+		case c == '`':
+			n := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+			ticks := line[i : i+n]
+			if end := strings.Index(line[i+n:], ticks); end >= 0 {
+				b.WriteString(line[i : i+n+end+n])
+				i += n + end + n
+				continue
+			}
+			b.WriteString(ticks)
+			i += n
+			continue
+		case c != '$':
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		delim := "$"
+		if strings.HasPrefix(line[i:], "$$") {
+			delim = "$$"
+		}
+		start := i + len(delim)
+		if end := closingDollar(line, start, delim); end > start {
+			b.WriteString(inlineMath(line[start:end], mode))
+			i = end + len(delim)
+			continue
+		}
+		b.WriteString(delim)
+		i = start
+	}
+	return b.String()
+}
+
+// closingDollar returns the position of the delimiter closing math opened
+// at start, or -1. Math can't contain unescaped dollars or code spans, so
+// only the next dollar can close it.
+func closingDollar(line string, start int, delim string) int {
+	if start >= len(line) || line[start] == ' ' || line[start] == '\t' {
+		return -1
+	}
+	for i := start; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '`':
+			return -1
+		case line[i] == '$':
+			next := i + len(delim)
+			if !strings.HasPrefix(line[i:], delim) || line[i-1] == ' ' || line[i-1] == '\t' ||
+				next < len(line) && line[next] >= '0' && line[next] <= '9' {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// inlineMath returns the markdown inline math is shown as.
+func inlineMath(src string, mode Mode) string {
+	if mode == ModeUnicode {
+		if out, err := Render(src); err == nil {
+			return escape(strings.ReplaceAll(out, "\n", " "))
+		}
+	}
+	ticks := "`"
+	for strings.Contains(src, ticks) {
+		ticks += "`"
+	}
+	if ticks != "`" {
+		return ticks + " " + src + " " + ticks
+	}
+	return ticks + src + ticks
+}
+
+// escape escapes the characters of text that would be taken for markdown.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>|#!", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package latex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tt := []struct {
+		src, want string
+	}{
+		{`x^2 + y^2 = z^2`, "x² + y² = z²"},
+		{`\frac{1}{2} + \frac{12}{17} + \frac{a+b}{c}`, "½ + ¹²⁄₁₇ + (a+b)/c"},
+		{`\sum_{i=1}^{n} i`, "∑ᵢ₌₁ⁿ i"},
+		{`e^{-x^2}`, "e⁻ˣ²"},
+		{`e^{i\pi}`, "e^(iπ)"},
+		{`\alpha \to \infty`, "α → ∞"},
+		{`\sqrt{x+1} \sqrt[3]{8}`, "√(x+1) ∛8"},
+		{`\mathbb{R}^n`, "ℝⁿ"},
+		{`f'(x) \leq \left| \frac{a}{b} \right.`, "f′(x) ≤ | a/b"},
+		{`\text{if } x > 0`, "if x > 0"},
+		{`\begin{aligned} a &= b \\ c &= d \end{aligned}`, "a = b\nc = d"},
+	}
+	for _, tc := range tt {
+		got, err := Render(tc.src)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.src, tc.want, got)
+		}
+	}
+
+	for _, src := range []string{`\unknown{x}`, `\frac{1}`, `\begin{tikzpicture}`, `x}`} {
+		if _, err := Render(src); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	doc := "Euler: $e^{i\\pi} + 1 = 0$, costs $5 and $10, `$x$` and \\$y$.\n\n" +
+		"$$\n\\int_0^1 x\\,dx\n$$\n\n" +
+		"```math\n\\weird\n```\n\n" +
+		"```go\n// $a$\n```\n"
+
+	got := Transform(doc, ModeUnicode)
+	for _, s := range []string{"e^(iπ) + 1 = 0,", "costs $5 and $10", "`$x$` and \\$y$", "∫₀¹ x dx", "│ \\weird │", "// $a$"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("expected unicode output to contain %q, got:\n%s", s, got)
+		}
+	}
+
+	got = Transform(doc, ModeSource)
+	for _, s := range []string{"`e^{i\\pi} + 1 = 0`", "┌─ math ─────────┐\n│ \\int_0^1 x\\,dx │\n└────────────────┘"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("expected source output to contain %q, got:\n%s", s, got)
+		}
+	}
+
+	if got := Transform(doc, ModeOff); got != doc {
+		t.Errorf("expected the document to be unchanged, got:\n%s", got)
+	}
+}
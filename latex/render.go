@@ -0,0 +1,514 @@
+package latex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokChar tokenKind = iota
+	tokCommand
+	tokSpace
+	tokOpen
+	tokClose
+	tokSup
+	tokSub
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// tokenize splits LaTeX source into tokens. Commands are given without
+// their backslash.
+func tokenize(src string) []token {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\\':
+			j := i + 1
+			for j < len(rs) && unicode.IsLetter(rs[j]) && rs[j] < unicode.MaxASCII {
+				j++
+			}
+			if j == i+1 && j < len(rs) {
+				// A command made of a single symbol, like \{ or \,
+				j++
+			}
+			toks = append(toks, token{tokCommand, string(rs[i+1 : j])})
+			i = j - 1
+		case unicode.IsSpace(r):
+			for i+1 < len(rs) && unicode.IsSpace(rs[i+1]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokSpace})
+		case r == '{':
+			toks = append(toks, token{kind: tokOpen})
+		case r == '}':
+			toks = append(toks, token{kind: tokClose})
+		case r == '^':
+			toks = append(toks, token{kind: tokSup})
+		case r == '_':
+			toks = append(toks, token{kind: tokSub})
+		default:
+			toks = append(toks, token{tokChar, string(r)})
+		}
+	}
+	return toks
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) skipSpace() {
+	for t, ok := p.peek(); ok && t.kind == tokSpace; t, ok = p.peek() {
+		p.pos++
+	}
+}
+
+// expr converts tokens up to the end of the current group.
+func (p *parser) expr(nested bool) (string, error) {
+	var b strings.Builder
+	for {
+		t, ok := p.peek()
+		if !ok {
+			if nested {
+				return "", fmt.Errorf("%w: missing }", ErrUnsupported)
+			}
+			return b.String(), nil
+		}
+		if t.kind == tokClose {
+			if !nested {
+				return "", fmt.Errorf("%w: unexpected }", ErrUnsupported)
+			}
+			p.pos++
+			return b.String(), nil
+		}
+
+		if t.kind == tokSpace {
+			p.pos++
+			if s := b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+				b.WriteByte(' ')
+			}
+			continue
+		}
+		if t.kind == tokSup || t.kind == tokSub {
+			p.pos++
+			arg, err := p.arg()
+			if err != nil {
+				return "", err
+			}
+			if t.kind == tokSup {
+				b.WriteString(script(arg, superscripts, "^"))
+			} else {
+				b.WriteString(script(arg, subscripts, "_"))
+			}
+			continue
+		}
+
+		s, err := p.atom()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+}
+
+// arg converts the argument of a command or script: a group, or a single
+// token.
+func (p *parser) arg() (string, error) {
+	p.skipSpace()
+	t, ok := p.peek()
+	if !ok || t.kind == tokClose || t.kind == tokSup || t.kind == tokSub {
+		return "", fmt.Errorf("%w: missing argument", ErrUnsupported)
+	}
+	return p.atom()
+}
+
+// atom converts a group, a command or a character.
+func (p *parser) atom() (string, error) {
+	t, _ := p.peek()
+	p.pos++
+	switch t.kind {
+	case tokOpen:
+		return p.expr(true)
+	case tokCommand:
+		return p.command(t.val)
+	}
+	switch t.val {
+	case "'":
+		return "′", nil
+	case "&":
+		// Alignment points of multi-line environments
+		return "", nil
+	case "~":
+		return " ", nil
+	}
+	return t.val, nil
+}
+
+// optional returns the optional argument of a command given in brackets,
+// if there's one.
+func (p *parser) optional() (string, bool, error) {
+	p.skipSpace()
+	if t, ok := p.peek(); !ok || t.kind != tokChar || t.val != "[" {
+		return "", false, nil
+	}
+	p.pos++
+	var b strings.Builder
+	for {
+		t, ok := p.peek()
+		if !ok {
+			return "", false, fmt.Errorf("%w: missing ]", ErrUnsupported)
+		}
+		if t.kind == tokChar && t.val == "]" {
+			p.pos++
+			return b.String(), true, nil
+		}
+		s, err := p.atom()
+		if err != nil {
+			return "", false, err
+		}
+		b.WriteString(s)
+	}
+}
+
+// name returns the text of a group of plain characters, like the name of an
+// environment.
+func (p *parser) name() (string, error) {
+	p.skipSpace()
+	if t, ok := p.peek(); !ok || t.kind != tokOpen {
+		return "", fmt.Errorf("%w: missing name", ErrUnsupported)
+	}
+	p.pos++
+	var b strings.Builder
+	for {
+		t, ok := p.peek()
+		if !ok {
+			return "", fmt.Errorf("%w: missing }", ErrUnsupported)
+		}
+		p.pos++
+		if t.kind == tokClose {
+			return b.String(), nil
+		}
+		b.WriteString(t.val)
+	}
+}
+
+func (p *parser) command(name string) (string, error) {
+	if s, ok := symbols[name]; ok {
+		return s, nil
+	}
+	if functions[name] {
+		return name, nil
+	}
+	if mark, ok := accents[name]; ok {
+		arg, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		return accent(arg, mark), nil
+	}
+
+	switch name {
+	case "text", "textrm", "textit", "textbf", "mathrm", "mathit", "mathbf",
+		"mathsf", "mathtt", "boldsymbol", "operatorname", "mbox":
+		return p.arg()
+	case "mathbb":
+		arg, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		return doubleStruck(arg), nil
+	case "frac", "dfrac", "tfrac":
+		num, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		den, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		return fraction(num, den), nil
+	case "sqrt":
+		n, _, err := p.optional()
+		if err != nil {
+			return "", err
+		}
+		arg, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		return root(n) + group(arg), nil
+	case "binom":
+		n, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		k, err := p.arg()
+		if err != nil {
+			return "", err
+		}
+		return "C(" + n + ", " + k + ")", nil
+	case "left", "right", "bigl", "bigr", "Bigl", "Bigr", "biggl", "biggr", "Biggl", "Biggr",
+		"big", "Big", "bigg", "Bigg":
+		// Sized delimiters are shown at their normal size; "." is an
+		// invisible one.
+		if t, ok := p.peek(); ok && t.kind == tokChar && t.val == "." {
+			p.pos++
+		}
+		return "", nil
+	case "begin", "end":
+		env, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		if !environments[env] {
+			return "", fmt.Errorf("%w: environment %s", ErrUnsupported, env)
+		}
+		return "", nil
+	case "\\":
+		return "\n", nil
+	}
+	return "", fmt.Errorf("%w: \\%s", ErrUnsupported, name)
+}
+
+// script sets s in superscript or subscript, using the Unicode characters
+// for it if there are ones for every character of s.
+func script(s string, chars map[rune]rune, op string) string {
+	if s == "′" && op == "^" {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := chars[r]
+		if !ok && !isScript(r, chars) {
+			if len([]rune(s)) == 1 {
+				return op + s
+			}
+			return op + "(" + s + ")"
+		}
+		if !ok {
+			c = r
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// isScript reports whether r is one of the characters of a script, like the
+// superscript of a nested superscript.
+func isScript(r rune, chars map[rune]rune) bool {
+	for _, c := range chars {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+// group wraps s in parentheses unless it's a single term.
+func group(s string) string {
+	rs := []rune(s)
+	if len(rs) <= 1 {
+		return s
+	}
+	for _, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+// fraction sets a fraction, using a vulgar fraction or super- and
+// subscript digits when possible.
+func fraction(num, den string) string {
+	if f, ok := vulgarFractions[num+"/"+den]; ok {
+		return f
+	}
+	if isDigits(num) && isDigits(den) {
+		return script(num, superscripts, "^") + "⁄" + script(den, subscripts, "_")
+	}
+	return group(num) + "/" + group(den)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// root returns the radical sign for the nth root.
+func root(n string) string {
+	switch n {
+	case "", "2":
+		return "√"
+	case "3":
+		return "∛"
+	case "4":
+		return "∜"
+	}
+	return script(n, superscripts, "^") + "√"
+}
+
+// accent puts a combining mark on every character of s.
+func accent(s string, mark rune) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(r)
+		if !unicode.IsSpace(r) {
+			b.WriteRune(mark)
+		}
+	}
+	return b.String()
+}
+
+// doubleStruck returns the blackboard bold version of letters and digits.
+func doubleStruck(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("CHNPQRZ", r):
+			b.WriteRune([]rune("ℂℍℕℙℚℝℤ")[strings.IndexRune("CHNPQRZ", r)])
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(0x1D538 + r - 'A')
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(0x1D552 + r - 'a')
+		case r >= '0' && r <= '9':
+			b.WriteRune(0x1D7D8 + r - '0')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var symbols = map[string]string{
+	// Greek letters
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ",
+	"varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ", "varphi": "φ",
+	"chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ",
+	"Pi": "Π", "Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ",
+	"Omega": "Ω",
+
+	// Operators
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬",
+	"iiint": "∭", "oint": "∮", "bigcup": "⋃", "bigcap": "⋂",
+	"pm": "±", "mp": "∓", "times": "×", "cdot": "·", "div": "÷", "ast": "∗",
+	"star": "⋆", "circ": "∘", "bullet": "•", "oplus": "⊕", "otimes": "⊗",
+	"cup": "∪", "cap": "∩", "setminus": "∖", "wedge": "∧", "land": "∧",
+	"vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬", "nabla": "∇",
+	"partial": "∂",
+
+	// Relations
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠",
+	"ll": "≪", "gg": "≫", "approx": "≈", "equiv": "≡", "sim": "∼",
+	"simeq": "≃", "cong": "≅", "propto": "∝", "in": "∈", "notin": "∉",
+	"ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "perp": "⊥", "parallel": "∥", "mid": "∣",
+	"models": "⊨", "vdash": "⊢",
+
+	// Arrows
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←",
+	"leftrightarrow": "↔", "Rightarrow": "⇒", "Leftarrow": "⇐",
+	"Leftrightarrow": "⇔", "iff": "⇔", "implies": "⇒", "mapsto": "↦",
+	"uparrow": "↑", "downarrow": "↓", "longrightarrow": "⟶",
+	"longleftarrow": "⟵", "hookrightarrow": "↪",
+
+	// Other symbols
+	"infty": "∞", "forall": "∀", "exists": "∃", "nexists": "∄",
+	"emptyset": "∅", "varnothing": "∅", "aleph": "ℵ", "hbar": "ℏ",
+	"ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "wp": "℘", "angle": "∠",
+	"triangle": "△", "prime": "′", "degree": "°", "ldots": "…",
+	"dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋",
+	"lceil": "⌈", "rceil": "⌉", "vert": "|", "lvert": "|", "rvert": "|",
+	"Vert": "‖", "lVert": "‖", "rVert": "‖", "|": "‖",
+
+	// Spacing and escaped characters
+	"quad": "  ", "qquad": "    ", ",": " ", ":": " ", ";": " ", "!": "",
+	" ": " ", "{": "{", "}": "}", "%": "%", "$": "$", "_": "_", "&": "&",
+	"#": "#",
+
+	// Style changes shown as is
+	"displaystyle": "", "textstyle": "", "limits": "", "nolimits": "",
+}
+
+// Functions are written upright in LaTeX, and as their name here.
+var functions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true,
+	"csc": true, "arcsin": true, "arccos": true, "arctan": true,
+	"sinh": true, "cosh": true, "tanh": true, "log": true, "ln": true,
+	"lg": true, "exp": true, "lim": true, "max": true, "min": true,
+	"sup": true, "inf": true, "det": true, "dim": true, "gcd": true,
+	"arg": true, "deg": true, "ker": true, "Pr": true, "mod": true,
+}
+
+// Combining marks for accents.
+var accents = map[string]rune{
+	"hat": '̂', "widehat": '̂', "bar": '̄',
+	"overline": '̅', "vec": '⃗', "dot": '̇',
+	"ddot": '̈', "tilde": '̃', "widetilde": '̃',
+	"underline": '̲',
+}
+
+// Environments whose lines are shown one after another.
+var environments = map[string]bool{
+	"aligned": true, "align": true, "align*": true, "gathered": true,
+	"gather": true, "gather*": true, "split": true, "equation": true,
+	"equation*": true,
+}
+
+var vulgarFractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾",
+	"1/5": "⅕", "2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙",
+	"5/6": "⅚", "1/7": "⅐", "1/8": "⅛", "3/8": "⅜", "5/8": "⅝",
+	"7/8": "⅞", "1/9": "⅑", "1/10": "⅒",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶',
+	'7': '⁷', '8': '⁸', '9': '⁹', '+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽',
+	')': '⁾', 'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ',
+	'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ',
+	'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ',
+	'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ', 'A': 'ᴬ', 'B': 'ᴮ',
+	'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ', 'J': 'ᴶ', 'K': 'ᴷ',
+	'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ', 'R': 'ᴿ', 'T': 'ᵀ',
+	'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ', 'α': 'ᵅ', 'β': 'ᵝ', 'γ': 'ᵞ', 'δ': 'ᵟ',
+	'ε': 'ᵋ', 'θ': 'ᶿ', 'ι': 'ᶥ', 'φ': 'ᵠ', 'χ': 'ᵡ', '∗': '*', '′': '′',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆',
+	'7': '₇', '8': '₈', '9': '₉', '+': '₊', '-': '₋', '=': '₌', '(': '₍',
+	')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ',
+	'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ',
+	't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ', 'β': 'ᵦ', 'γ': 'ᵧ', 'ρ': 'ᵨ',
+	'φ': 'ᵩ', 'χ': 'ᵪ',
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lineRange is an inclusive, 1-based range of source lines. A zero start or
+// end leaves that side of the range open.
+type lineRange struct {
+	start, end int
+}
+
+// parseLineRange parses a range given as START:END, START:, :END or a single
+// line number.
+func parseLineRange(s string) (lineRange, error) {
+	var r lineRange
+	if s == "" {
+		return r, nil
+	}
+
+	from, to, isRange := strings.Cut(s, ":")
+	if !isRange {
+		to = from
+	}
+
+	var err error
+	if from != "" {
+		if r.start, err = strconv.Atoi(from); err != nil || r.start < 1 {
+			return r, fmt.Errorf("invalid line range %q: start must be a positive line number", s)
+		}
+	}
+	if to != "" {
+		if r.end, err = strconv.Atoi(to); err != nil || r.end < 1 {
+			return r, fmt.Errorf("invalid line range %q: end must be a positive line number", s)
+		}
+	}
+	if r.end > 0 && r.start > r.end {
+		return r, fmt.Errorf("invalid line range %q: start is after end", s)
+	}
+	return r, nil
+}
+
+// isSet reports whether the range selects anything other than the whole
+// document.
+func (r lineRange) isSet() bool {
+	return r.start > 1 || r.end > 0
+}
+
+// apply returns the lines of s that fall within the range.
+func (r lineRange) apply(s string) string {
+	if !r.isSet() {
+		return s
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	start, end := max(r.start, 1)-1, len(lines)
+	if r.end > 0 {
+		end = min(r.end, end)
+	}
+	if start >= end {
+		return ""
+	}
+	return strings.Join(lines[start:end], "")
+}
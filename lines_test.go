@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestLineRange(t *testing.T) {
+	const doc = "one\ntwo\nthree\nfour\n"
+
+	tt := []struct {
+		arg  string
+		want string
+	}{
+		{"", doc},
+		{"2:3", "two\nthree\n"},
+		{"3:", "three\nfour\n"},
+		{":2", "one\ntwo\n"},
+		{"2", "two\n"},
+		{"3:100", "three\nfour\n"},
+		{"10:20", ""},
+	}
+	for _, tc := range tt {
+		r, err := parseLineRange(tc.arg)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.arg, err)
+		}
+		if got := r.apply(doc); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.arg, tc.want, got)
+		}
+	}
+
+	for _, arg := range []string{"x", "0:2", "3:2", "1:-1", "1:2:3"} {
+		if _, err := parseLineRange(arg); err == nil {
+			t.Errorf("%q: expected an error", arg)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package links
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// absoluteURL matches URLs shown in rendered output. Escape sequences end
+// them, as do trailing punctuation and quotes.
+const absoluteURL = `(?:https?|ftp|file)://[^\s\x1b"'<>]*[^\s\x1b"'<>.,;:!?)\]]`
+
+// Hyperlink wraps the URLs shown in rendered output in OSC 8 escape
+// sequences, so that terminals supporting them make the URLs clickable.
+// Absolute URLs are found by themselves; targets maps other text shown for
+// links, like the paths of local files, to the URLs they link to.
+func Hyperlink(rendered string, targets map[string]string) string {
+	shown := make([]string, 0, len(targets))
+	for s := range targets {
+		if s != "" {
+			shown = append(shown, s)
+		}
+	}
+	// Longer text first, so it wins over its prefixes.
+	slices.SortFunc(shown, func(a, b string) int { return len(b) - len(a) })
+
+	alternatives := []string{absoluteURL}
+	for _, s := range shown {
+		alternatives = append(alternatives, regexp.QuoteMeta(s))
+	}
+	re := regexp.MustCompile(strings.Join(alternatives, "|"))
+	return re.ReplaceAllStringFunc(rendered, func(s string) string {
+		target, ok := targets[s]
+		if !ok {
+			target = s
+		}
+		return osc8(target, s)
+	})
+}
+
+// osc8 returns text as a hyperlink to target.
+func osc8(target, text string) string {
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
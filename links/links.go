@@ -0,0 +1,246 @@
+// Package links collects the hyperlinks of markdown documents so they can be
+// listed after the document and opened by number.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Mode controls how links are displayed.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeInline shows link targets next to their text.
+	ModeInline Mode = "inline"
+	// ModeList keeps the links as they are and lists them, numbered, after
+	// the document.
+	ModeList Mode = "list"
+	// ModeFootnote replaces link targets with their number in the list after
+	// the document.
+	ModeFootnote Mode = "footnote"
+)
+
+// Modes lists all valid modes.
+var Modes = []Mode{ModeInline, ModeList, ModeFootnote}
+
+// ParseMode validates a mode string.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid links mode %q: use list, footnote or inline", s)
+}
+
+// Link is a hyperlink of a document. Links are numbered from 1 in the order
+// they first appear; repeated targets share a number.
+type Link struct {
+	Text string
+	URL  string
+}
+
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// occurrence is a link in the source, with the offset of the closing bracket
+// of its text and the end of its target, if known.
+type occurrence struct {
+	index      int
+	start, end int
+}
+
+// scan parses the document and returns its links and where they occur.
+func scan(src []byte) ([]Link, []occurrence) {
+	var (
+		found []Link
+		occs  []occurrence
+		index = map[string]int{}
+	)
+	add := func(txt, dest string) int {
+		if dest == "" || strings.HasPrefix(dest, "#") {
+			return -1
+		}
+		i, ok := index[dest]
+		if !ok {
+			i = len(found)
+			index[dest] = i
+			found = append(found, Link{Text: txt, URL: dest})
+		}
+		return i
+	}
+
+	doc := md.Parser().Parse(text.NewReader(src))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			i := add(plainText(n, src), string(n.Destination))
+			if i < 0 {
+				return ast.WalkSkipChildren, nil
+			}
+			if start, end, ok := target(n, src); ok {
+				occs = append(occs, occurrence{index: i, start: start, end: end})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			add(string(n.Label(src)), string(n.URL(src)))
+		}
+		return ast.WalkContinue, nil
+	})
+	return found, occs
+}
+
+// plainText returns the text of a node's descendants.
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(src))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// target finds the source range from the closing bracket of a link's text
+// to the end of its target, i.e. "](url)", "][ref]" or "]". Links whose
+// text doesn't end in plain text, like image links, aren't located.
+func target(n *ast.Link, src []byte) (start, end int, ok bool) {
+	last := n.LastChild()
+	for last != nil && last.HasChildren() {
+		if last.Kind() == ast.KindImage {
+			return 0, 0, false
+		}
+		last = last.LastChild()
+	}
+	t, isText := last.(*ast.Text)
+	if !isText {
+		return 0, 0, false
+	}
+
+	// Skip the closing delimiters of emphasis and code spans.
+	i := t.Segment.Stop
+	for i < len(src) && strings.IndexByte("*_~`", src[i]) >= 0 {
+		i++
+	}
+	if i >= len(src) || src[i] != ']' {
+		return 0, 0, false
+	}
+	start = i
+	i++
+
+	switch {
+	case i < len(src) && src[i] == '(':
+		depth := 0
+		for ; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return start, i + 1, true
+				}
+			}
+		}
+		return 0, 0, false
+	case i < len(src) && src[i] == '[':
+		j := strings.IndexByte(string(src[i:]), ']')
+		if j < 0 {
+			return 0, 0, false
+		}
+		return start, i + j + 1, true
+	}
+	return start, start + 1, true
+}
+
+// Extract returns the links of a markdown document.
+func Extract(doc string) []Link {
+	found, _ := scan([]byte(doc))
+	return found
+}
+
+// Apply formats the links of a markdown document according to mode and
+// returns the document along with its links.
+func Apply(doc string, mode Mode) (string, []Link) {
+	src := []byte(doc)
+	found, occs := scan(src)
+	if mode == ModeInline || len(found) == 0 {
+		return doc, found
+	}
+
+	if mode == ModeFootnote {
+		// An anchor-only target keeps the link text styled as a link without
+		// showing the target.
+		for _, o := range slices.Backward(occs) {
+			marker := "](#)\\[" + strconv.Itoa(o.index+1) + "\\]"
+			src = slices.Concat(src[:o.start], []byte(marker), src[o.end:])
+		}
+	}
+	return strings.TrimRight(string(src), "\n") + "\n\n" + List(found), found
+}
+
+// List formats links as a numbered markdown list.
+func List(links []Link) string {
+	var b strings.Builder
+	b.WriteString("---\n\n")
+	for i, l := range links {
+		fmt.Fprintf(&b, "%d. ", i+1)
+		u, err := url.Parse(l.URL)
+		switch {
+		case l.Text == "" || l.Text == l.URL:
+			if err == nil && u.Scheme != "" {
+				fmt.Fprintf(&b, "<%s>\n", l.URL)
+			} else {
+				fmt.Fprintf(&b, "[%s](%s)\n", escape(l.URL), destination(l.URL))
+			}
+		default:
+			fmt.Fprintf(&b, "[%s](%s)\n", escape(l.Text), destination(l.URL))
+		}
+	}
+	return b.String()
+}
+
+// destination formats a URL as a link destination, which can't contain
+// spaces or unbalanced parentheses unless enclosed in angle brackets.
+func destination(u string) string {
+	if strings.ContainsAny(u, " ()") {
+		return "<" + u + ">"
+	}
+	return u
+}
+
+// escape escapes characters with a meaning in markdown.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>#", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+const doc = `# Docs
+
+See [the *guide*](https://example.com/guide "Guide") and [notes][n], or
+visit https://example.com/guide again. Jump to [a section](#usage).
+
+[n]: notes.md
+`
+
+func TestExtract(t *testing.T) {
+	got := Extract(doc)
+	want := []Link{
+		{Text: "the guide", URL: "https://example.com/guide"},
+		{Text: "notes", URL: "notes.md"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d links, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("link %d: expected %+v, got %+v", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	out, found := Apply(doc, ModeInline)
+	if out != doc || len(found) != 2 {
+		t.Errorf("expected the document unchanged, got %q", out)
+	}
+
+	out, _ = Apply(doc, ModeList)
+	if !strings.HasPrefix(out, doc[:len(doc)-1]) {
+		t.Errorf("expected the document to be kept, got %q", out)
+	}
+	if !strings.HasSuffix(out, "1. [the guide](https://example.com/guide)\n2. [notes](notes.md)\n") {
+		t.Errorf("expected a numbered list, got %q", out)
+	}
+
+	out, _ = Apply(doc, ModeFootnote)
+	for _, s := range []string{
+		`[the *guide*](#)\[1\] and [notes](#)\[2\], or`,
+		"visit https://example.com/guide again",
+		"[a section](#usage)",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in %q", s, out)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if _, err := ParseMode("footnote"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseMode("endnote"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	rendered := "See \x1b[4mhttps://example.com/guide\x1b[0m and /docs/notes.md (https://example.com/a).\n"
+
+	got := Hyperlink(rendered, map[string]string{"/docs/notes.md": "file:///docs/notes.md"})
+	for _, s := range []string{
+		"\x1b[4m\x1b]8;;https://example.com/guide\x1b\\https://example.com/guide\x1b]8;;\x1b\\\x1b[0m",
+		"\x1b]8;;file:///docs/notes.md\x1b\\/docs/notes.md\x1b]8;;\x1b\\",
+		"(\x1b]8;;https://example.com/a\x1b\\https://example.com/a\x1b]8;;\x1b\\).",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("expected output to contain %q, got %q", s, got)
+		}
+	}
+
+	if got := Hyperlink(rendered, nil); strings.Contains(got, "file://") {
+		t.Errorf("expected local paths to be left alone, got %q", got)
+	}
+}
@@ -0,0 +1,304 @@
+// Package lint checks markdown documents for structural problems.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Rules reported by Check.
+const (
+	RuleBrokenLink         = "broken-link"
+	RuleHeadingIncrement   = "heading-increment"
+	RuleMalformedTable     = "malformed-table"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleDuplicateAnchor    = "duplicate-anchor"
+)
+
+// Diagnostic is a problem found in a document. Lines count from 1.
+type Diagnostic struct {
+	Line    int
+	Rule    string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d: %s: %s", d.Line, d.Rule, d.Message)
+}
+
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Check lints a markdown document read from path. Relative links are
+// resolved against the document's directory. Frontmatter is skipped, and
+// diagnostics are sorted by line.
+func Check(path string, src []byte) []Diagnostic {
+	if _, body := utils.SplitFrontmatter(src); len(body) < len(src) {
+		// Keep the line numbers of the body.
+		skipped := bytes.Count(src[:len(src)-len(body)], []byte("\n"))
+		src = append(bytes.Repeat([]byte("\n"), skipped), body...)
+	}
+
+	c := &checker{
+		dir:   filepath.Dir(path),
+		src:   src,
+		lines: strings.Split(string(src), "\n"),
+	}
+	c.code = codeLines(c.lines)
+
+	doc := md.Parser().Parse(text.NewReader(src))
+	c.headings(doc)
+	c.links(doc)
+	c.tables()
+	c.whitespace()
+
+	sort.SliceStable(c.diags, func(i, j int) bool {
+		return c.diags[i].Line < c.diags[j].Line
+	})
+	return c.diags
+}
+
+type checker struct {
+	dir     string
+	src     []byte
+	lines   []string
+	code    map[int]bool // indexes of lines in fenced code blocks
+	anchors map[string]bool
+	diags   []Diagnostic
+}
+
+func (c *checker) report(line int, rule, format string, args ...any) {
+	c.diags = append(c.diags, Diagnostic{Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// line returns the line number of a byte offset.
+func (c *checker) line(offset int) int {
+	return strings.Count(string(c.src[:min(offset, len(c.src))]), "\n") + 1
+}
+
+// nodeLine returns the line a node starts on.
+func (c *checker) nodeLine(n ast.Node) int {
+	for ; n != nil; n = n.FirstChild() {
+		if t, ok := n.(*ast.Text); ok {
+			return c.line(t.Segment.Start)
+		}
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			return c.line(n.Lines().At(0).Start)
+		}
+	}
+	return 0
+}
+
+// headings checks heading levels and collects the anchors they define.
+func (c *checker) headings(doc ast.Node) {
+	c.anchors = map[string]bool{}
+	level := 0
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		line := c.nodeLine(h)
+		if level > 0 && h.Level > level+1 {
+			c.report(line, RuleHeadingIncrement, "heading level %d follows level %d", h.Level, level)
+		}
+		level = h.Level
+
+		anchor := Anchor(plainText(h, c.src))
+		if c.anchors[anchor] {
+			c.report(line, RuleDuplicateAnchor, "anchor #%s is already defined", anchor)
+		}
+		c.anchors[anchor] = true
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// links checks that relative links point to existing files, and that anchors
+// exist in the document.
+func (c *checker) links(doc ast.Node) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest string
+		switch n := n.(type) {
+		case *ast.Link:
+			dest = string(n.Destination)
+		case *ast.Image:
+			dest = string(n.Destination)
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		u, err := url.Parse(dest)
+		if err != nil || u.Scheme != "" || u.Host != "" || dest == "" {
+			return ast.WalkContinue, nil
+		}
+		line := c.nodeLine(n)
+		if u.Path == "" {
+			if !c.anchors[u.Fragment] {
+				c.report(line, RuleBrokenLink, "no heading for anchor #%s", u.Fragment)
+			}
+			return ast.WalkContinue, nil
+		}
+		target := filepath.FromSlash(u.Path)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(c.dir, target)
+		}
+		if _, err := os.Stat(target); err != nil {
+			c.report(line, RuleBrokenLink, "%s does not exist", u.Path)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+var delimiterRow = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+// tables checks that the rows of tables have as many cells as their header.
+func (c *checker) tables() {
+	for i := 1; i < len(c.lines); i++ {
+		if c.code[i] || !delimiterRow.MatchString(c.lines[i]) || !strings.Contains(c.lines[i-1], "|") {
+			continue
+		}
+		header := len(cells(c.lines[i-1]))
+		if n := len(cells(c.lines[i])); n != header {
+			c.report(i+1, RuleMalformedTable, "delimiter row has %d cells, header has %d", n, header)
+			continue
+		}
+		for i++; i < len(c.lines) && strings.TrimSpace(c.lines[i]) != "" && strings.Contains(c.lines[i], "|"); i++ {
+			if n := len(cells(c.lines[i])); n != header {
+				c.report(i+1, RuleMalformedTable, "row has %d cells, header has %d", n, header)
+			}
+		}
+	}
+}
+
+// cells splits a table row into its cells.
+func cells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+
+	var (
+		out  []string
+		cell strings.Builder
+		code bool
+	)
+	for i := 0; i < len(row); i++ {
+		switch ch := row[i]; {
+		case ch == '\\' && i+1 < len(row):
+			cell.WriteByte(ch)
+			cell.WriteByte(row[i+1])
+			i++
+		case ch == '`':
+			code = !code
+			cell.WriteByte(ch)
+		case ch == '|' && !code:
+			out = append(out, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(ch)
+		}
+	}
+	return append(out, cell.String())
+}
+
+// whitespace reports trailing whitespace. Two trailing spaces are a line
+// break outside of code blocks and allowed there.
+func (c *checker) whitespace() {
+	for i, l := range c.lines {
+		trimmed := strings.TrimRight(l, " \t")
+		if trimmed == l {
+			continue
+		}
+		if !c.code[i] && trimmed != "" && l == trimmed+"  " {
+			continue
+		}
+		c.report(i+1, RuleTrailingWhitespace, "trailing whitespace")
+	}
+}
+
+// codeLines returns the indexes of lines inside fenced code blocks, fences
+// included.
+func codeLines(lines []string) map[int]bool {
+	code := map[int]bool{}
+	var fence string
+	for i, l := range lines {
+		t := strings.TrimLeft(l, " ")
+		switch {
+		case fence != "":
+			// A fence is closed by a line of at least as many of its
+			// characters, and nothing else.
+			code[i] = true
+			if strings.HasPrefix(t, fence) && strings.TrimSpace(strings.TrimLeft(t, fence[:1])) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~"):
+			code[i] = true
+			fence = t[:len(t)-len(strings.TrimLeft(t, t[:1]))]
+		}
+	}
+	return code
+}
+
+// Anchor returns the anchor GitHub generates for a heading.
+func Anchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Anchors numbers the anchors of repeated headings like GitHub does: the
+// second "Usage" heading of a document gets the anchor usage-1, the third
+// usage-2.
+type Anchors map[string]int
+
+// Next returns the anchor of the next heading of a document.
+func (a Anchors) Next(heading string) string {
+	anchor := Anchor(heading)
+	n := a[anchor]
+	a[anchor]++
+	if n == 0 {
+		return anchor
+	}
+	return fmt.Sprintf("%s-%d", anchor, n)
+}
+
+// plainText returns the text of a node's descendants.
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(src))
+		case *ast.String:
+			b.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const doc = `---
+title: Docs
+---
+
+# Docs
+
+See [the guide](guide.md), [missing](missing.md) and [usage](#usage).  
+Jump [nowhere](#nowhere), or [away](https://example.com/missing.md).
+
+### Usage
+
+| a | b |
+|---|---|
+| 1 | 2 |
+| 1 | 2 | 3 |
+| ` + "`a|b`" + ` | c |
+
+## Usage
+
+` + "```" + `
+code  
+| not | a |
+|---|
+` + "```" + `
+`
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(filepath.Join(dir, "guide.md"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Diagnostic{
+		{Line: 7, Rule: RuleBrokenLink, Message: "missing.md does not exist"},
+		{Line: 8, Rule: RuleBrokenLink, Message: "no heading for anchor #nowhere"},
+		{Line: 10, Rule: RuleHeadingIncrement, Message: "heading level 3 follows level 1"},
+		{Line: 15, Rule: RuleMalformedTable, Message: "row has 3 cells, header has 2"},
+		{Line: 18, Rule: RuleDuplicateAnchor, Message: "anchor #usage is already defined"},
+		{Line: 21, Rule: RuleTrailingWhitespace, Message: "trailing whitespace"},
+	}
+	got := Check(path, []byte(doc))
+	if len(got) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diagnostic %d: expected %v, got %v", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestCheckDelimiterRow(t *testing.T) {
+	got := Check("doc.md", []byte("| a | b |\n|---|\n"))
+	if len(got) != 1 || got[0].Rule != RuleMalformedTable || got[0].Line != 2 {
+		t.Errorf("expected a malformed table on line 2, got %v", got)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	for heading, want := range map[string]string{
+		"Usage":               "usage",
+		"The CLI & the TUI":   "the-cli--the-tui",
+		"Build (Go 1.21+)":    "build-go-121",
+		"snake_case-and-dash": "snake_case-and-dash",
+		"Überblick":           "überblick",
+	} {
+		if got := Anchor(heading); got != want {
+			t.Errorf("%q: expected %q, got %q", heading, want, got)
+		}
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	a := Anchors{}
+	var got []string
+	for _, h := range []string{"Usage", "Install", "Usage", "usage"} {
+		got = append(got, a.Next(h))
+	}
+	if want := []string{"usage", "install", "usage-1", "usage-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
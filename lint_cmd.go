@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/lint"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintPathStyle = lipgloss.NewStyle().Bold(true)
+	lintRuleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+
+	lintCmd = &cobra.Command{
+		Use:   "lint FILE...",
+		Short: "Check markdown documents for structural problems",
+		Long: paragraph(fmt.Sprintf("\n%s markdown documents for broken relative links and anchors, skipped heading levels, malformed tables, trailing whitespace and duplicate anchors. Directories are checked recursively. Exits with status 1 if any problems are found, for use in CI.",
+			keyword("Check"))),
+		Example:           paragraph("glow lint README.md\nglow lint docs"),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeMarkdownFiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var files []string
+			for _, arg := range args {
+				info, err := os.Stat(arg)
+				if err != nil {
+					return fmt.Errorf("unable to stat file: %w", err)
+				}
+				if !info.IsDir() {
+					files = append(files, arg)
+					continue
+				}
+				found, err := findMarkdownFiles(arg)
+				if err != nil {
+					return err
+				}
+				files = append(files, found...)
+			}
+
+			problems := 0
+			for _, path := range files {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("unable to read file: %w", err)
+				}
+				for _, d := range lint.Check(path, content) {
+					problems++
+					fmt.Fprintf(cmd.OutOrStdout(), "%s%s %s %s\n", //nolint:errcheck
+						lintPathStyle.Render(path),
+						faint(fmt.Sprintf(":%d:", d.Line)),
+						d.Message,
+						lintRuleStyle.Render(d.Rule))
+				}
+			}
+
+			switch problems {
+			case 0:
+				return nil
+			case 1:
+				return errors.New("1 problem found")
+			default:
+				return fmt.Errorf("%d problems found", problems)
+			}
+		},
+	}
+)
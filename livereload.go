@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// websocketGUID is the magic value from RFC 6455 used to compute the
+// handshake accept key.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// liveReload watches a directory tree and notifies connected browsers over
+// a websocket when anything changes.
+type liveReload struct {
+	watcher *fsnotify.Watcher
+
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func newLiveReload(root string) (*liveReload, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create watcher: %w", err)
+	}
+	lr := &liveReload{
+		watcher: w,
+		clients: map[chan struct{}]struct{}{},
+	}
+
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil //nolint:nilerr
+		}
+		if p != root && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := w.Add(p); err != nil {
+			log.Debug("unable to watch directory", "dir", p, "error", err)
+		}
+		return nil
+	})
+
+	go lr.watch()
+	return lr, nil
+}
+
+func (lr *liveReload) watch() {
+	// Editors often emit several events per save, so coalesce them.
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-lr.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				_ = lr.watcher.Add(event.Name)
+			}
+			log.Debug("live reload event", "file", event.Name, "event", event.Op)
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(100*time.Millisecond, lr.broadcast)
+		case err, ok := <-lr.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Debug("live reload error", "error", err)
+		}
+	}
+}
+
+func (lr *liveReload) broadcast() {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	for c := range lr.clients {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close stops watching for changes.
+func (lr *liveReload) Close() error {
+	return lr.watcher.Close() //nolint:wrapcheck
+}
+
+// ServeHTTP upgrades the connection to a websocket and sends a message
+// whenever the watched tree changes. Only the server-to-client direction is
+// used; anything the browser sends is discarded.
+func (lr *liveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-Websocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "expected websocket upgrade", http.StatusBadRequest)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		log.Debug("unable to hijack connection", "error", err)
+		return
+	}
+	defer conn.Close() //nolint:errcheck
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", websocketAccept(key))
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	c := make(chan struct{}, 1)
+	lr.mu.Lock()
+	lr.clients[c] = struct{}{}
+	lr.mu.Unlock()
+	defer func() {
+		lr.mu.Lock()
+		delete(lr.clients, c)
+		lr.mu.Unlock()
+	}()
+
+	// Read until the browser goes away.
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, rw.Reader)
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-c:
+			if err := writeTextFrame(rw.Writer, "reload"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// websocketAccept computes the Sec-WebSocket-Accept value for a key.
+func websocketAccept(key string) string {
+	h := sha1.New() //nolint:gosec
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// writeTextFrame writes a single unmasked websocket text frame. Payloads
+// are short, so only the 7-bit length encoding is supported.
+func writeTextFrame(w *bufio.Writer, msg string) error {
+	if len(msg) > 125 {
+		return fmt.Errorf("websocket message too long: %d", len(msg))
+	}
+	if _, err := w.Write([]byte{0x81, byte(len(msg))}); err != nil {
+		return fmt.Errorf("unable to write frame: %w", err)
+	}
+	if _, err := w.WriteString(msg); err != nil {
+		return fmt.Errorf("unable to write frame: %w", err)
+	}
+	return w.Flush() //nolint:wrapcheck
+}
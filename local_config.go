@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// localConfigName is the name of project-local config files, which are
+// merged over the global config when rendering documents below them.
+const localConfigName = ".glow.yml"
+
+// localConfigKeys are the settings a project-local config file can change,
+// with the flags that take precedence over them.
+var localConfigKeys = map[string]string{
+	"style":            "style",
+	"width":            "width",
+	"preserveNewLines": "preserve-new-lines",
+}
+
+// findLocalConfig returns the path of the project-local config file closest
+// to dir, walking up to the root, or "" if there's none.
+func findLocalConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, localConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readLocalConfig reads the settings of a project-local config file. Styles
+// given as relative paths are resolved against the file's directory.
+func readLocalConfig(path string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	settings := map[string]any{}
+	for _, key := range v.AllKeys() {
+		name := localConfigKey(key)
+		if name == "" {
+			log.Warn("Ignoring setting in local config", "path", path, "key", key)
+			continue
+		}
+		settings[name] = v.Get(key)
+	}
+
+	if s, ok := settings["style"].(string); ok && s != styles.AutoStyle && styles.DefaultStyles[s] == nil {
+		s = utils.ExpandPath(s)
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(filepath.Dir(path), s)
+		}
+		settings["style"] = s
+	}
+	return settings, nil
+}
+
+// localConfigKey returns the name of a setting local config files can
+// change, given in any case, or "" if it can't be changed.
+func localConfigKey(key string) string {
+	for name := range localConfigKeys {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return ""
+}
+
+// applyLocalConfig merges the project-local config file closest to the
+// document at arg over the global config. Settings given as flags or
+// environment variables take precedence. Documents that aren't local files
+// use the config closest to the working directory when read from stdin,
+// and none otherwise.
+func applyLocalConfig(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 && args[0] != "-" {
+		info, err := os.Stat(args[0])
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return fmt.Errorf("unable to stat file: %w", err)
+		case info.IsDir():
+			dir = args[0]
+		default:
+			dir = filepath.Dir(args[0])
+		}
+	}
+
+	path := findLocalConfig(dir)
+	if path == "" {
+		return nil
+	}
+	settings, err := readLocalConfig(path)
+	if err != nil {
+		return err
+	}
+	log.Debug("Using local configuration file", "path", path)
+	for name, value := range settings {
+		if cmd.Flags().Changed(localConfigKeys[name]) || os.Getenv("GLOW_"+strings.ToUpper(name)) != "" {
+			continue
+		}
+		viper.Set(name, value)
+	}
+	return nil
+}
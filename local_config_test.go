@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "docs", "guide")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, localConfigName)
+	cfg := "style: styles/docs.json\nwidth: 60\npreservenewlines: true\npager: true\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findLocalConfig(sub); got != path {
+		t.Fatalf("expected %s, got %q", path, got)
+	}
+
+	settings, err := readLocalConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := settings["style"], filepath.Join(root, "styles", "docs.json"); got != want {
+		t.Errorf("expected style %q, got %q", want, got)
+	}
+	if settings["width"] != 60 {
+		t.Errorf("expected width 60, got %v", settings["width"])
+	}
+	if settings["preserveNewLines"] != true {
+		t.Errorf("expected preserveNewLines, got %v", settings["preserveNewLines"])
+	}
+	if _, ok := settings["pager"]; ok {
+		t.Error("expected pager to be ignored")
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/charmbracelet/log"
 	gap "github.com/muesli/go-app-paths"
 )
 
+// logOutput is the file the log is written to, if any.
+var logOutput *os.File
+
 func getLogFilePath() (string, error) {
 	dir, err := gap.NewScope(gap.User, "glow").CacheDir()
 	if err != nil {
@@ -20,21 +24,83 @@ func getLogFilePath() (string, error) {
 
 func setupLog() (func() error, error) {
 	log.SetOutput(io.Discard)
+	log.SetLevel(log.InfoLevel)
 	// Log to file, if set
 	logFile, err := getLogFilePath()
 	if err != nil {
 		return nil, err
 	}
-	if err := os.MkdirAll(filepath.Dir(logFile), 0o755); err != nil { //nolint:gosec
-		// log disabled
-		return func() error { return nil }, nil //nolint:nilerr
-	}
-	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644) //nolint:gosec
+	f, err := openLogFile(logFile)
 	if err != nil {
 		// log disabled
-		return func() error { return nil }, nil //nolint:nilerr
+		return closeLog, nil //nolint:nilerr
+	}
+	logOutput = f
+	log.SetOutput(f)
+	return closeLog, nil
+}
+
+// configureLog applies the --debug and --log-file options: debug messages and
+// render timings are only logged with --debug, and --log-file replaces the
+// default log file, or sends the log to stderr if it's "-".
+func configureLog() error {
+	if debug {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.InfoLevel)
+	}
+	if logFile == "" {
+		return nil
+	}
+
+	f := os.Stderr
+	if logFile != "-" {
+		var err error
+		if f, err = openLogFile(logFile); err != nil {
+			return fmt.Errorf("unable to open log file: %w", err)
+		}
 	}
+	_ = closeLog()
+	logOutput = f
 	log.SetOutput(f)
-	log.SetLevel(log.DebugLevel)
-	return f.Close, nil
+	return nil
+}
+
+func openLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec
+		return nil, err //nolint:wrapcheck
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644) //nolint:gosec,wrapcheck
+}
+
+func closeLog() error {
+	if logOutput == nil || logOutput == os.Stderr {
+		return nil
+	}
+	f := logOutput
+	logOutput = nil
+	return f.Close() //nolint:wrapcheck
+}
+
+// renderTimer measures how long the stages of rendering a document take, and
+// logs them with --debug.
+type renderTimer struct {
+	last   time.Time
+	stages []any
+}
+
+func startRenderTimer() *renderTimer {
+	return &renderTimer{last: time.Now()}
+}
+
+// stage records the time since the previous stage ended.
+func (t *renderTimer) stage(name string) {
+	now := time.Now()
+	t.stages = append(t.stages, name, now.Sub(t.last))
+	t.last = now
+}
+
+// log logs the times of the stages of rendering src.
+func (t *renderTimer) log(src *source) {
+	log.Debug("render timings", append([]any{"source", src.URL}, t.stages...)...)
 }
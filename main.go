@@ -2,8 +2,8 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -13,42 +13,104 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/styles"
-	"github.com/charmbracelet/glow/v2/ui"
-	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/annotations"
+	"github.com/douglas-larocca/glow/v2/chunks"
+	"github.com/douglas-larocca/glow/v2/codethemes"
+	"github.com/douglas-larocca/glow/v2/footnotes"
+	"github.com/douglas-larocca/glow/v2/frontmatter"
+	"github.com/douglas-larocca/glow/v2/history"
+	"github.com/douglas-larocca/glow/v2/images"
+	"github.com/douglas-larocca/glow/v2/latex"
+	"github.com/douglas-larocca/glow/v2/links"
+	"github.com/douglas-larocca/glow/v2/manpage"
+	"github.com/douglas-larocca/glow/v2/mermaid"
+	"github.com/douglas-larocca/glow/v2/passthrough"
+	"github.com/douglas-larocca/glow/v2/positions"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/douglas-larocca/glow/v2/tables"
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"github.com/douglas-larocca/glow/v2/ui"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/douglas-larocca/glow/v2/wikilinks"
 	gap "github.com/muesli/go-app-paths"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/term"
 )
 
+var (
+	defaultReadmeNames = []string{"README.md", "README", "Readme.md", "Readme", "readme.md", "readme"}
+	defaultDocRoots    = []string{"docs", "doc", "wiki"}
+)
+
+// defaultMaxWidth is the widest documents are wrapped at to fit the terminal,
+// for readability.
+const defaultMaxWidth = 120
+
 var (
 	// Version as provided by goreleaser.
 	Version = ""
 	// CommitSHA as provided by goreleaser.
 	CommitSHA = ""
 
-	readmeNames      = []string{"README.md", "README", "Readme.md", "Readme", "readme.md", "readme"}
+	readmeNames      = defaultReadmeNames
+	docRoots         []string
 	configFile       string
 	pager            bool
 	tui              bool
 	style            string
 	width            uint
+	widthDetected    bool // whether width is the terminal's
+	termWidth        int  // width of the terminal, if known
+	maxWidth         uint
+	margin           uint
+	center           bool
 	showAllFiles     bool
 	showLineNumbers  bool
 	preserveNewLines bool
 	mouse            bool
 	spinnerName      string
 	spinnerColorStr  string
+	mermaidMode      string
+	mathMode         string
+	wideTablesMode   string
+	rawANSIMode      string
+	imagesMode       string
+	frontmatterMode  string
+	linksMode        string
+	hyperlinksMode   string
+	noResume         bool
+	readOnly         bool
+	foldDocuments    bool
+	recursive        bool
+	forceTTY         bool
+	showStats        bool
+	streamMode       string
+	streamGranular   string
+	streamScreen     string
+	streamDebounce   time.Duration
+	streamMaxFPS     uint
+	termProfileName  string
+	outputFile       string
+	colorProfile     string
+	chromaTheme      string
+	codeThemeFlag    map[string]string
+	codeThemes       codethemes.Themes
+	copyMode         string
+	linesFlag        string
+	selectedLines    lineRange
+	debug            bool
+	logFile          string
+	profile          string
 
 	spinnerFlags struct {
 		duration time.Duration
@@ -62,11 +124,26 @@ var (
 		SilenceErrors:    false,
 		SilenceUsage:     true,
 		TraverseChildren: true,
-		Args:             cobra.MaximumNArgs(1),
-		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
-			return nil, cobra.ShellCompDirectiveDefault
+		Args: func(cmd *cobra.Command, args []string) error {
+			// Several documents can be opened in tabs in the TUI.
+			if viper.GetBool("tui") {
+				return nil
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
 		},
-		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		ValidArgsFunction: completeSource,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Project-local config only applies to rendering documents.
+			if cmd == cmd.Root() {
+				if err := applyLocalConfig(cmd, args); err != nil {
+					return err
+				}
+			}
+			// A profile chosen on the command line wins over the project's
+			// config.
+			if err := applyProfile(cmd); err != nil && !isConfigCmd(cmd) {
+				return err
+			}
 			return validateOptions(cmd)
 		},
 		RunE: execute,
@@ -75,7 +152,7 @@ var (
 	spinnerCmd = &cobra.Command{
 		Use:   "spinner [TYPE]",
 		Short: "Preview available spinner animations",
-		Long:  paragraph(fmt.Sprintf("\n%s the available spinner animations for use with the --spinner flag.", keyword("Preview"))),
+		Long:  paragraph(fmt.Sprintf("\n%s the available spinner animations for use with the --spinner flag. On a terminal, all spinners animate at once in a grid: select one with the arrow keys and press enter to print how to use it.", keyword("Preview"))),
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// If a spinner type is specified as an argument, demonstrate it
@@ -99,42 +176,107 @@ var (
 	}
 )
 
+// What --copy puts on the clipboard.
+const (
+	copyRaw      = "raw"
+	copyRendered = "rendered"
+)
+
 // source provides a readable markdown source.
 type source struct {
 	reader io.ReadCloser
 	URL    string
+	// Whether the content is markdown regardless of the extension of its
+	// URL, e.g. converted from an HTML page.
+	markdown bool
+	// Anchor of the heading to start at, e.g. installation for
+	// README.md#installation.
+	anchor string
 }
 
-// sourceFromArg parses an argument and creates a readable source for it.
-func sourceFromArg(arg string) (*source, error) {
+// isMarkdown reports whether the source is rendered as markdown rather than
+// as code.
+func (s *source) isMarkdown() bool {
+	return s.markdown || utils.IsMarkdownFile(s.URL)
+}
+
+// sourceFromArg parses an argument and creates a readable source for it. A
+// heading anchor at the end of the argument, as in README.md#usage, is kept
+// with the source.
+func sourceFromArg(ctx context.Context, arg string) (*source, error) {
+	arg, anchor := splitAnchor(arg)
+	src, err := openSource(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	src.anchor = anchor
+	return src, nil
+}
+
+// openSource creates a readable source for an argument.
+func openSource(ctx context.Context, arg string) (*source, error) {
 	// from stdin
 	if arg == "-" {
-		return &source{reader: os.Stdin}, nil
+		return stdinSource(), nil
+	}
+
+	// a GitHub or GitLab issue, pull request or merge request:
+	if d, ok := parseDiscussionURL(arg); ok && security.network {
+		return d.source(ctx)
 	}
 
 	// a GitHub or GitLab URL (even without the protocol):
-	src, err := readmeURL(arg)
-	if src != nil && err == nil {
-		// if there's an error, try next methods...
-		return src, nil
+	if security.network {
+		src, err := readmeURL(ctx, arg)
+		if src != nil && err == nil {
+			// if there's an error, try next methods...
+			return src, nil
+		}
 	}
 
-	// HTTP(S) URLs:
-	if u, err := url.ParseRequestURI(arg); err == nil && strings.Contains(arg, "://") { //nolint:nestif
-		if u.Scheme != "" {
-			if u.Scheme != "http" && u.Scheme != "https" {
-				return nil, fmt.Errorf("%s is not a supported protocol", u.Scheme)
-			}
+	// HTTP(S) and file URLs:
+	if u, err := url.ParseRequestURI(arg); err == nil && strings.Contains(arg, "://") {
+		switch u.Scheme {
+		case "http", "https":
 			// consumer of the source is responsible for closing the ReadCloser.
-			resp, err := http.Get(u.String()) //nolint: noctx,bodyclose
+			resp, err := fetch(ctx, u.String()) //nolint:bodyclose
 			if err != nil {
-				return nil, fmt.Errorf("unable to get url: %w", err)
+				return nil, err
 			}
 			if resp.StatusCode != http.StatusOK {
+				_ = resp.Body.Close()
 				return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
 			}
-			return &source{resp.Body, u.String()}, nil
+			return fetchedSource(resp, u.String())
+		case "file":
+			if !security.fileURIs {
+				return nil, disallowed("file URIs are", "allowFileUris")
+			}
+			if u.Host != "" && u.Host != "localhost" {
+				return nil, fmt.Errorf("unable to open %s: only local file URIs are supported", arg)
+			}
+			return openLocalSource(filepath.FromSlash(u.Path))
+		default:
+			return nil, fmt.Errorf("%s is not a supported protocol", u.Scheme)
+		}
+	}
+
+	// a document at a git ref, e.g. HEAD~3:README.md
+	if ref, file, ok := splitGitRef(arg); ok {
+		if !security.gitRefs {
+			return nil, disallowed("documents at git refs are", "allowGitRefs")
 		}
+		return gitSource(ctx, "", ref, file)
+	}
+
+	return openLocalSource(arg)
+}
+
+// openLocalSource creates a readable source for a local file, or the index
+// or README of a directory.
+func openLocalSource(arg string) (*source, error) {
+	if err := checkLocalFiles(); err != nil {
+		return nil, err
 	}
 
 	// a directory:
@@ -144,6 +286,15 @@ func sourceFromArg(arg string) (*source, error) {
 	}
 	st, err := os.Stat(arg)
 	if err == nil && st.IsDir() { //nolint:nestif
+		if index := findDocIndex(arg); index != "" {
+			r, err := os.Open(index)
+			if err != nil {
+				return nil, fmt.Errorf("unable to open file: %w", err)
+			}
+			u, _ := filepath.Abs(index)
+			return &source{reader: r, URL: u}, nil
+		}
+
 		var src *source
 		_ = filepath.Walk(arg, func(path string, _ os.FileInfo, err error) error {
 			if err != nil {
@@ -157,7 +308,7 @@ func sourceFromArg(arg string) (*source, error) {
 					}
 
 					u, _ := filepath.Abs(path)
-					src = &source{r, u}
+					src = &source{reader: r, URL: u}
 
 					// abort filepath.Walk
 					return errors.New("source found")
@@ -181,11 +332,27 @@ func sourceFromArg(arg string) (*source, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to get absolute path: %w", err)
 	}
-	return &source{r, u}, nil
+	return &source{reader: r, URL: u}, nil
+}
+
+// resolveStyle returns the path of a JSON style given by name that isn't in
+// the current directory, but in the config directory.
+func resolveStyle(style string) string {
+	if style == "" || style == styles.AutoStyle || styles.DefaultStyles[style] != nil || filepath.IsAbs(style) {
+		return style
+	}
+	if _, err := os.Stat(utils.ExpandPath(style)); err == nil {
+		return style
+	}
+	p := filepath.Join(configDir(), style)
+	if _, err := os.Stat(p); err != nil {
+		return style
+	}
+	return p
 }
 
 // validateStyle checks if the style is a default style, if not, checks that
-// the custom style exists.
+// the custom style exists and can be read, along with the styles it extends.
 func validateStyle(style string) error {
 	if style != "auto" && styles.DefaultStyles[style] == nil {
 		style = utils.ExpandPath(style)
@@ -194,47 +361,272 @@ func validateStyle(style string) error {
 		} else if err != nil {
 			return fmt.Errorf("unable to stat file: %w", err)
 		}
+		if _, err := utils.StyleConfig(style); err != nil {
+			return fmt.Errorf("invalid style %s: %w", style, err)
+		}
 	}
 	return nil
 }
 
+// termProfile is what the terminal output is written for can do, set with
+// --term-profile or detected from $TERM. Output that isn't written for a
+// terminal can use everything.
+var termProfile = termprofile.Xterm
+
+// colorProfiles maps the values accepted by --color-profile to terminal
+// color profiles.
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+}
+
+// setColorProfile forces the color profile used for rendering. Without an
+// explicit profile, output written to a file uses true color and everything
+// else is detected from the terminal.
+func setColorProfile(name string) error {
+	if name == "" {
+		if outputFile != "" {
+			lipgloss.SetColorProfile(termenv.TrueColor)
+		}
+		return nil
+	}
+	p, ok := colorProfiles[name]
+	if !ok {
+		return fmt.Errorf("invalid color profile %q, must be one of: truecolor, 256, 16", name)
+	}
+	lipgloss.SetColorProfile(p)
+	return nil
+}
+
 func validateOptions(cmd *cobra.Command) error {
+	// The config commands are how a broken config file gets fixed.
+	if !isConfigCmd(cmd) {
+		if err := checkConfigFile(viper.ConfigFileUsed()); err != nil {
+			return err
+		}
+	}
+
 	// grab config values from Viper
 	width = viper.GetUint("width")
+	maxWidth = viper.GetUint("maxWidth")
+	margin = viper.GetUint("margin")
+	center = viper.GetBool("center")
 	mouse = viper.GetBool("mouse")
 	pager = viper.GetBool("pager")
 	tui = viper.GetBool("tui")
 	showAllFiles = viper.GetBool("all")
+	showLineNumbers = viper.GetBool("showLineNumbers")
+	chromaTheme = viper.GetString("chromaTheme")
 	preserveNewLines = viper.GetBool("preserveNewLines")
+	mermaidMode = viper.GetString("mermaid")
+	mathMode = viper.GetString("math")
+	wideTablesMode = viper.GetString("wideTables")
+	rawANSIMode = viper.GetString("rawAnsi")
+	imagesMode = viper.GetString("images")
+	frontmatterMode = viper.GetString("frontmatter")
+	linksMode = viper.GetString("links")
+	hyperlinksMode = viper.GetString("hyperlinks")
+	noResume = viper.GetBool("noResume")
+	readOnly = viper.GetBool("readonly")
+	foldDocuments = viper.GetBool("fold")
+	recursive = viper.GetBool("recursive")
+	forceTTY = viper.GetBool("forceTTY")
+	showStats = viper.GetBool("stats")
+	readmeNames = viper.GetStringSlice("readmeNames")
+	docRoots = viper.GetStringSlice("docRoots")
+	streamMode = viper.GetString("stream")
+	stdinMode = viper.GetString("stdinMode")
+	streamGranular = viper.GetString("streamGranularity")
+	streamScreen = viper.GetString("streamScreen")
+	streamDebounce = viper.GetDuration("streamDebounce")
+	streamMaxFPS = viper.GetUint("streamMaxFps")
+	termProfileName = viper.GetString("termProfile")
+	spinnerName = viper.GetString("spinner")
+	spinnerColorStr = viper.GetString("spinnerColor")
+	httpTimeout = viper.GetDuration("timeout")
+	httpRetries = viper.GetInt("retries")
+	maxDownloadStr = viper.GetString("maxDownload")
+	maxSizeStr = viper.GetString("maxSize")
+	offline = viper.GetBool("offline")
+	security = readSecurityPolicy()
+	debug = viper.GetBool("debug")
+	logFile = viper.GetString("logFile")
+
+	if err := configureLog(); err != nil {
+		return err
+	}
 
 	if pager && tui {
 		return errors.New("cannot use both pager and tui")
 	}
+	if recursive && tui {
+		return errors.New("cannot use both recursive and tui")
+	}
+	if jobs < 0 {
+		return errors.New("jobs can't be negative")
+	}
+	if batchFile != "" && (recursive || tui) {
+		return errors.New("cannot use batch with recursive or tui")
+	}
+	if outputFile != "" && (pager || tui) {
+		return errors.New("cannot use output with pager or tui")
+	}
+	if linesFlag != "" && tui {
+		return errors.New("cannot use both lines and tui")
+	}
+	if outputFormat != formatANSI && (tui || isPDF(outputFile)) {
+		return errors.New("cannot use format with tui or a PDF output")
+	}
+
+	var err error
+	if selectedLines, err = parseLineRange(linesFlag); err != nil {
+		return err
+	}
+
+	if err := setColorProfile(colorProfile); err != nil {
+		return err
+	}
+
+	if _, err := mermaid.ParseMode(mermaidMode); err != nil {
+		return err
+	}
+
+	if _, err := latex.ParseMode(mathMode); err != nil {
+		return err
+	}
+
+	if _, err := tables.ParseMode(wideTablesMode); err != nil {
+		return err
+	}
+
+	if _, err := passthrough.ParseMode(rawANSIMode); err != nil {
+		return err
+	}
+
+	if _, err := images.ParseMode(imagesMode); err != nil {
+		return err
+	}
+
+	if _, err := frontmatter.ParseMode(frontmatterMode); err != nil {
+		return err
+	}
+
+	if _, err := links.ParseMode(linksMode); err != nil {
+		return err
+	}
+
+	if err := validateFormat(outputFormat); err != nil {
+		return err
+	}
+
+	if err := validateHyperlinks(hyperlinksMode); err != nil {
+		return err
+	}
+
+	if err := validateStreamMode(streamMode); err != nil {
+		return err
+	}
+
+	if err := validateStdinMode(stdinMode); err != nil {
+		return err
+	}
+	if _, err := stream.ParseGranularity(streamGranular); err != nil {
+		return err
+	}
+	if _, err := stream.ParseScreen(streamScreen); err != nil {
+		return err
+	}
+	if termProfileName != "" {
+		if termProfile, err = termprofile.Parse(termProfileName); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	if maxDownload, err = parseMaxDownload(maxDownloadStr); err != nil {
+		return err
+	}
+	if maxSize, err = parseMaxSize(maxSizeStr); err != nil {
+		return err
+	}
+	if httpRetries < 0 {
+		return errors.New("retries can't be negative")
+	}
+	httpCache = openHTTPCache()
+
+	if err := validateChromaTheme(chromaTheme); err != nil {
+		return err
+	}
+	// Themes given with --code-theme add to those of the config file, rather
+	// than replacing them all.
+	codeThemes = codethemes.Themes(viper.GetStringMapString("codeThemes")).Merge(codeThemeFlag)
+	for lang, theme := range codeThemes {
+		if err := validateChromaTheme(theme); err != nil {
+			return fmt.Errorf("code theme for %s: %w", lang, err)
+		}
+	}
+
+	if copyMode != "" && copyMode != copyRaw && copyMode != copyRendered {
+		return fmt.Errorf("invalid copy mode %q, expected %s or %s", copyMode, copyRaw, copyRendered)
+	}
+
+	if err := loadCustomSpinners(); err != nil {
+		return err
+	}
+	if err := validateSpinner(spinnerName, spinnerColorStr); err != nil {
+		return err
+	}
 
 	// validate the glamour style
-	style = viper.GetString("style")
+	style = resolveStyle(viper.GetString("style"))
 	if err := validateStyle(style); err != nil {
 		return err
 	}
 
 	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
+	// The terminal output is sized for, stderr's when output piped to a
+	// pager ends up on it.
+	tty := os.Stdout
+	if !isTerminal && outputFile == "" && term.IsTerminal(int(os.Stderr.Fd())) {
+		if forceTTY {
+			isTerminal, tty = true, os.Stderr
+			if colorProfile == "" {
+				lipgloss.SetColorProfile(termenv.NewOutput(os.Stderr).EnvColorProfile())
+			}
+		} else {
+			log.Debug("output is piped, use --force-tty to style it for the terminal")
+		}
+	}
+	if termProfileName == "" && isTerminal {
+		termProfile = termprofile.Detect(os.Getenv)
+	}
+	if termProfile == termprofile.Dumb && colorProfile == "" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
 	// We want to use a special no-TTY style, when stdout is not a terminal
-	// and there was no specific style passed by arg
-	if !isTerminal && !cmd.Flags().Changed("style") {
+	// and there was no specific style passed by arg. Renders to a file, with
+	// a forced color profile, or for the browser or SSH keep the configured
+	// style.
+	forceColor := outputFile != "" || colorProfile != "" || cmd == serveCmd || cmd == sshServeCmd || cmd == daemonCmd
+	if !isTerminal && !forceColor && !cmd.Root().Flags().Changed("style") {
 		style = "notty"
 	}
+	if style == styles.AutoStyle && isPDF(outputFile) {
+		// PDFs are printed on, and mostly viewed as, white pages.
+		style = styles.LightStyle
+	}
+	if style == styles.AutoStyle && (isTerminal || forceColor) {
+		detectBackground()
+	}
 
 	// Detect terminal width
-	if !cmd.Flags().Changed("width") { //nolint:nestif
-		if isTerminal && width == 0 {
-			w, _, err := term.GetSize(int(os.Stdout.Fd()))
-			if err == nil {
-				width = uint(w) //nolint:gosec
-			}
-
-			if width > 120 {
-				width = width / 2
-			}
+	if isTerminal {
+		termWidth, _, _ = term.GetSize(int(tty.Fd()))
+	}
+	if !cmd.Root().Flags().Changed("width") {
+		if termWidth > 0 && width == 0 && !isPDF(outputFile) {
+			width = wrapWidth(termWidth)
+			widthDetected = true
 		}
 		if width == 0 {
 			width = 80
@@ -244,31 +636,111 @@ func validateOptions(cmd *cobra.Command) error {
 	return nil
 }
 
-func stdinIsPipe() (bool, error) {
-	stat, err := os.Stdin.Stat()
-	if err != nil {
-		return false, fmt.Errorf("unable to open file: %w", err)
+// wrapWidth returns the width to wrap at in a terminal of the given width:
+// the width left by --margin, up to --max-width.
+func wrapWidth(cols int) uint {
+	w := uint(max(cols-int(margin), 0)) //nolint:gosec
+	if maxWidth > 0 {
+		w = min(w, maxWidth)
 	}
-	if stat.Mode()&os.ModeCharDevice == 0 || stat.Size() > 0 {
-		return true, nil
+	return w
+}
+
+// leftMargin returns how far rendered documents are moved right: enough to
+// center them in the terminal with --center, or --margin otherwise.
+func leftMargin() int {
+	switch {
+	case isPDF(outputFile):
+		// Pages have margins of their own.
+		return 0
+	case center:
+		return max(termWidth-int(width), 0) / 2 //nolint:gosec
+	default:
+		return int(margin) //nolint:gosec
 	}
-	return false, nil
 }
 
 func execute(cmd *cobra.Command, args []string) error {
-	// if stdin is a pipe then use stdin for input. note that you can also
-	// explicitly use a - to read from stdin.
-	if yes, err := stdinIsPipe(); err != nil {
+	if outputFile == "" {
+		return executeTo(cmd, args, os.Stdout)
+	}
+
+	// The output file is only replaced once the documents are rendered, so
+	// that it's left alone when they can't be.
+	var b strings.Builder
+	if err := executeTo(cmd, args, &b); err != nil {
+		return err
+	}
+	if isPDF(outputFile) {
+		return writePDF(outputFile, b.String())
+	}
+	if err := os.WriteFile(outputFile, []byte(b.String()), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	return nil
+}
+
+// executeTo renders the sources given as arguments, or starts the TUI, and
+// writes the rendered output to w.
+func executeTo(cmd *cobra.Command, args []string, w io.Writer) error {
+	// Documents listed in a file, or on stdin
+	if batchFile != "" {
+		if len(args) > 0 {
+			return errors.New("cannot use batch with arguments")
+		}
+		return executeBatch(cmd, w)
+	}
+
+	// if stdin is piped or redirected then use stdin for input. note that
+	// you can also explicitly use a - to read from stdin.
+	if yes, err := stdinHasInput(); err != nil {
 		return err
 	} else if yes {
-		src := &source{reader: os.Stdin}
+		src := stdinSource()
 		defer src.reader.Close() //nolint:errcheck
-		return executeCLI(cmd, src, os.Stdout)
+		return executeCLI(cmd, src, w)
+	}
+
+	if recursive {
+		if err := checkLocalFiles(); err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		return executeRecursive(cmd, args, w)
+	}
+
+	// TUI with documents in tabs
+	if tui && len(args) > 1 {
+		if err := checkLocalFiles(); err != nil {
+			return err
+		}
+		for _, arg := range args {
+			info, err := os.Stat(arg)
+			if err != nil {
+				return fmt.Errorf("unable to open document in tab: %w", err)
+			}
+			if info.IsDir() {
+				return fmt.Errorf("unable to open %s in tab: it's a directory", arg)
+			}
+		}
+		return runTUI(args[0], "", args[1:]...)
+	}
+
+	if recent {
+		if len(args) > 0 {
+			return errors.New("cannot use recent with arguments")
+		}
+		return runTUI("", "")
 	}
 
 	switch len(args) {
 	// TUI running on cwd
 	case 0:
+		if outputFile != "" {
+			return errors.New("missing markdown source")
+		}
 		return runTUI("", "")
 
 	// TUI with possible dir argument
@@ -276,18 +748,27 @@ func execute(cmd *cobra.Command, args []string) error {
 		// Validate that the argument is a directory. If it's not treat it as
 		// an argument to the non-TUI version of Glow (via fallthrough).
 		info, err := os.Stat(args[0])
-		if err == nil && info.IsDir() {
+		if err == nil && info.IsDir() && outputFile == "" {
+			if err := checkLocalFiles(); err != nil {
+				return err
+			}
 			p, err := filepath.Abs(args[0])
 			if err == nil {
 				return runTUI(p, "")
 			}
 		}
+		// Browse the documents of a remote repository
+		if err != nil && (tui || cmd.Flags().Changed("tui")) {
+			if repo := repoFromArg(args[0]); repo != nil {
+				return runRepoTUI(repo)
+			}
+		}
 		fallthrough
 
 	// CLI
 	default:
 		for _, arg := range args {
-			if err := executeArg(cmd, arg, os.Stdout); err != nil {
+			if err := executeArg(cmd, arg, w); err != nil {
 				return err
 			}
 		}
@@ -298,415 +779,195 @@ func execute(cmd *cobra.Command, args []string) error {
 
 func executeArg(cmd *cobra.Command, arg string, w io.Writer) error {
 	// create an io.Reader from the markdown source in cli-args
-	src, err := sourceFromArg(arg)
+	src, err := sourceFromArg(cmd.Context(), arg)
 	if err != nil {
 		return err
 	}
 	defer src.reader.Close() //nolint:errcheck
+	addToHistory(src)
 	return executeCLI(cmd, src, w)
 }
 
-// terminalPosition tracks the cursor position in the terminal
-type terminalPosition struct {
-	row    int
-	column int
-}
+func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
+	useSpinner := spinnerName != spinnerNone
 
-// getTerminalPosition gets the current terminal cursor position
-// This uses ANSI escape codes to query and parse the cursor position
-func getTerminalPosition(file *os.File) (terminalPosition, error) {
-	// This only works for terminals, so make sure we're dealing with one
-	if !term.IsTerminal(int(file.Fd())) {
-		return terminalPosition{}, fmt.Errorf("not a terminal")
+	// If not reading from stdin, just read all and render once. Plain text
+	// and markdown are written once too, as they can't be repainted.
+	if _, ok := src.reader.(*os.File); !ok || src.reader != os.Stdin || outputFormat != formatANSI {
+		b, err := readContent(src)
+		if err != nil {
+			return err
+		}
+		return renderMarkdown(cmd, src, b, w)
 	}
 
-	// Save current terminal attributes to restore later
-	oldState, err := term.MakeRaw(int(file.Fd()))
-	if err != nil {
-		return terminalPosition{}, err
+	// Terminals and files redirected to stdin are read all at once, unless
+	// --stdin-mode says otherwise
+	if !streamStdin() {
+		b, err := readContent(src)
+		if err != nil {
+			return err
+		}
+		return renderMarkdown(cmd, src, b, w)
 	}
-	defer term.Restore(int(file.Fd()), oldState)
 
-	// Write the ANSI escape code to query the cursor position
-	// ESC [ 6 n
-	_, err = file.Write([]byte("\x1b[6n"))
-	if err != nil {
-		return terminalPosition{}, err
-	}
+	// For stdin from a pipe, we'll read incrementally and render as we go
+	return renderStream(cmd, src, w, useSpinner)
+}
 
-	// Read the response: ESC [ rows ; cols R
-	var buf [32]byte
-	n, err := file.Read(buf[:])
-	if err != nil {
-		return terminalPosition{}, err
-	}
+// setupRenderer creates a glamour renderer with proper configuration
+func setupRenderer(src *source) (*glamour.TermRenderer, string, error) {
+	return setupRendererWidth(src, width)
+}
 
-	// Parse the response
-	response := string(buf[:n])
-	if !strings.HasPrefix(response, "\x1b[") || !strings.HasSuffix(response, "R") {
-		return terminalPosition{}, fmt.Errorf("invalid terminal response: %q", response)
+// setupRendererWidth creates a glamour renderer wrapping at wrap.
+func setupRendererWidth(src *source, wrap uint) (*glamour.TermRenderer, string, error) {
+	var baseURL string
+	u, err := url.ParseRequestURI(src.URL)
+	if err == nil {
+		u.Path = filepath.Dir(u.Path)
+		baseURL = u.String() + "/"
 	}
 
-	// Extract the numbers from the response
-	response = response[2 : len(response)-1] // Remove ESC[ and R
-	parts := strings.Split(response, ";")
-	if len(parts) != 2 {
-		return terminalPosition{}, fmt.Errorf("invalid terminal response format: %q", response)
-	}
+	isCode := !src.isMarkdown()
 
-	// Parse the numbers
-	row, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return terminalPosition{}, err
-	}
-	col, err := strconv.Atoi(parts[1])
+	// Initialize glamour
+	r, err := glamour.NewTermRenderer(
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		glamourStyle(isCode),
+		glamour.WithWordWrap(int(wrap)),
+		glamour.WithBaseURL(baseURL),
+		glamour.WithPreservedNewLines(),
+	)
 	if err != nil {
-		return terminalPosition{}, err
+		return nil, "", fmt.Errorf("unable to create renderer: %w", err)
 	}
 
-	return terminalPosition{row: row, column: col}, nil
+	return r, baseURL, nil
 }
 
-// moveTo generates ANSI escape code to position cursor at specific coordinates
-func (pos terminalPosition) moveTo() string {
-	return fmt.Sprintf("\x1b[%d;%dH", pos.row, pos.column)
+// renderDocument renders prepared markdown with r, drawing callouts as boxes
+// and laying out tables.
+func renderDocument(r *glamour.TermRenderer, markdown string) (string, error) {
+	return renderDocumentWidth(r, markdown, width)
 }
 
-// saveTerminalPosition saves the current terminal position for later restoration
-func saveTerminalPosition(w io.Writer) (terminalPosition, error) {
-	// Try to get terminal position if we're writing to a terminal
-	f, ok := w.(*os.File)
-	if !ok {
-		return terminalPosition{}, fmt.Errorf("output is not a terminal")
-	}
-
-	// Get current position
-	pos, err := getTerminalPosition(f)
+// renderDocumentWidth renders prepared markdown with r, wrapping at wrap.
+func renderDocumentWidth(r *glamour.TermRenderer, markdown string, wrap uint) (string, error) {
+	out, err := utils.RenderMarkdown(r, markdown, utils.RenderOptions{
+		Style:      style,
+		Width:      int(wrap),
+		Tables:     tables.Mode(wideTablesMode),
+		RawANSI:    passthrough.Mode(rawANSIMode),
+		CodeThemes: codeThemes,
+		Images:     images.Mode(imagesMode),
+		OpenImage:  openImage,
+		ASCII:      !termProfile.Unicode,
+		Glamour: []glamour.TermRendererOption{
+			glamour.WithColorProfile(lipgloss.ColorProfile()),
+			glamour.WithPreservedNewLines(),
+		},
+	})
 	if err != nil {
-		return terminalPosition{}, err
+		return "", err //nolint:wrapcheck
 	}
-
-	return pos, nil
+	return termProfile.Text(out), nil
 }
 
-// shouldRenderUpdate determines if we should re-render based on the current line
-// and content seen so far. This helps identify markdown elements that can change
-// the rendering of previous content.
-func shouldRenderUpdate(currentLine string, previousLines []string) bool {
-	// Always render at least every 10 lines to ensure responsiveness
-	if len(previousLines)%10 == 0 {
-		return true
+// prepareMarkdown handles the frontmatter and applies all document transforms
+// to the content. Code files are wrapped in a fenced code block.
+func prepareMarkdown(src *source, content []byte) string {
+	// Man pages piped to stdin
+	if src.URL == "" && manpage.IsRoff(content) {
+		content = []byte(manpage.ToMarkdown(string(content)))
 	}
 
-	// Check for constructs that can affect previous content rendering
-	patterns := []struct {
-		regex *regexp.Regexp
-		desc  string
-	}{
-		{regexp.MustCompile(`^\[.*?\]:\s+`), "reference link"},
-		{regexp.MustCompile(`^\[\^.*?\]:\s+`), "footnote definition"},
-		{regexp.MustCompile(`^<!--`), "HTML comment start"},
-		{regexp.MustCompile(`-->`), "HTML comment end"},
-		{regexp.MustCompile(`^#{1,6}\s+`), "heading"},
-		{regexp.MustCompile(`^(\*\s*){3,}`), "horizontal rule"},
-		{regexp.MustCompile(`^(\-\s*){3,}`), "horizontal rule"},
-		{regexp.MustCompile(`^(\_\s*){3,}`), "horizontal rule"},
-		{regexp.MustCompile(`^:::.*`), "fenced div start/end"},
-		{regexp.MustCompile(`^\|.*\|`), "table line"},
-		{regexp.MustCompile(`^(\s*\*\s+|\s*\d+\.\s+|\s*-\s+)`), "list item"},
-	}
+	front, body := utils.SplitFrontmatter(content)
+	contentStr := selectedLines.apply(string(body))
 
-	for _, pattern := range patterns {
-		if pattern.regex.MatchString(strings.TrimSpace(currentLine)) {
-			return true
-		}
+	// Handle code files
+	if !src.isMarkdown() {
+		return utils.WrapCodeBlock(contentStr, filepath.Ext(src.URL))
 	}
 
-	// Check for the end of a code block which could affect rendering
-	if strings.TrimSpace(currentLine) == "```" {
-		// Look back to find if this is the end of a code block
-		for i := len(previousLines) - 2; i >= 0; i-- {
-			if strings.TrimSpace(previousLines[i]) == "```" {
-				return false // This is a nested ``` within a code block
-			}
-			if strings.HasPrefix(strings.TrimSpace(previousLines[i]), "```") {
-				return true // This is the end of a code block
-			}
-		}
-		return true // Assume it's the end of a code block if we can't determine
-	}
+	contentStr = frontmatter.Apply(front, contentStr, frontmatter.Mode(frontmatterMode))
 
-	// Check for completion of a complex structure
-	if len(previousLines) >= 2 {
-		prevLine := strings.TrimSpace(previousLines[len(previousLines)-2])
-		// If we just completed a multi-line construct like a table
-		if (prevLine == "" && strings.HasPrefix(currentLine, "|")) ||
-			(strings.HasPrefix(prevLine, "|") && currentLine == "") {
-			return true
-		}
-	}
+	// Render mermaid diagrams
+	contentStr = mermaid.Transform(contentStr, mermaid.Mode(mermaidMode))
 
-	return false
-}
+	// Convert LaTeX math
+	contentStr = latex.Transform(contentStr, latex.Mode(mathMode))
 
-func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
-	useSpinner := spinnerName != "none"
+	contentStr = resolveWikiLinks(src, contentStr)
+	contentStr = resolveImages(src, contentStr)
 
-	// If not reading from stdin, just read all and render once
-	if _, ok := src.reader.(*os.File); !ok || src.reader != os.Stdin {
-		b, err := io.ReadAll(src.reader)
-		if err != nil {
-			return fmt.Errorf("unable to read from reader: %w", err)
-		}
-		return renderMarkdown(cmd, src, b, w)
-	}
+	contentStr, _ = footnotes.Transform(contentStr)
 
-	// For stdin, check if it's a terminal or a pipe
-	if file, ok := src.reader.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
-		// If stdin is a terminal and not a pipe, just read all at once
-		b, err := io.ReadAll(src.reader)
-		if err != nil {
-			return fmt.Errorf("unable to read from reader: %w", err)
-		}
-		return renderMarkdown(cmd, src, b, w)
+	if links.Mode(linksMode) != links.ModeInline {
+		contentStr, _ = links.Apply(contentStr, links.Mode(linksMode))
 	}
-
-	// For stdin from a pipe, we'll read incrementally and render as we go
-	return renderIncrementalFromStdin(cmd, src, w, useSpinner)
+	return contentStr
 }
 
-// renderIncrementalFromStdin reads incrementally from stdin and renders
-// the markdown as it becomes available, using the alternate screen for progress
-func renderIncrementalFromStdin(cmd *cobra.Command, src *source, w io.Writer, useSpinner bool) error {
-	// Create a terminal buffer manager
-	tb := newTermbuf(w)
-
-	// Enter alternate screen if we're on a terminal
-	if err := tb.enterAltScreen(); err != nil {
-		// If we can't use the alternate screen, continue without it
-		log.Debug("failed to enter alternate screen", "err", err)
-	}
-
-	// Make sure we always exit the alternate screen
-	defer func() {
-		if err := tb.exitAltScreen(); err != nil {
-			log.Debug("failed to exit alternate screen", "err", err)
-		}
-	}()
-
-	// Create a buffered reader
-	reader := bufio.NewReader(src.reader)
-
-	// Buffer to accumulate content
-	var buffer bytes.Buffer
-	var previousLines []string // Store individual lines for diffing
-	var lastOutput string      // Last output sent to terminal
-	var finalOutput string     // The final rendered output
-	var r *glamour.TermRenderer
-	var err error
-
-	// Setup spinner if enabled and we're in alternate screen
-	var sp *Spinner
-	if useSpinner && tb.isActive {
-		// Get spinner type based on user preference
-		spinnerType := GetSpinnerType(spinnerName)
-
-		// Create and start the spinner
-		sp = NewSpinner(spinnerType)
-
-		// Set custom color if provided
-		if spinnerColorStr != "" {
-			sp.SetColor(spinnerColorStr)
-		}
-
-		sp.Start(w)
-		defer sp.Stop()
+// resolveWikiLinks turns the [[Page]] links of local documents into links to
+// the files they name.
+func resolveWikiLinks(src *source, md string) string {
+	if !strings.Contains(md, "[[") {
+		return md
 	}
-
-	// Setup renderer once
-	if r, _, err = setupRenderer(src); err != nil {
-		return err
+	path, err := filepath.Abs(src.URL)
+	if err != nil {
+		return md
 	}
-
-	// Use a scanner for line-by-line reading
-	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Increase buffer size for large lines
-
-	// Read timeout handling
-	lastActivity := time.Now()
-	inactivityTimeout := 500 * time.Millisecond
-
-	// Channel to handle timeouts
-	timeoutChan := make(chan struct{})
-
-	go func() {
-		for {
-			// If we haven't received input for the timeout duration, send timeout signal
-			if time.Since(lastActivity) > inactivityTimeout {
-				timeoutChan <- struct{}{}
-				time.Sleep(100 * time.Millisecond) // Small sleep to prevent flooding
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
-	}()
-
-	// Process incoming data
-	for {
-		// Check if scanner has more data available
-		hasMore := scanner.Scan()
-		if hasMore {
-			// Update activity timestamp and spinner
-			lastActivity = time.Now()
-			if sp != nil {
-				sp.Update()
-			}
-
-			// Get the new line
-			line := scanner.Text()
-
-			// Add the line to our accumulated content
-			buffer.WriteString(line)
-			buffer.WriteString("\n")
-
-			// Add to our line-by-line tracking
-			previousLines = append(previousLines, line)
-
-			// Only re-render periodically or when we detect certain markdown structures
-			shouldRender := shouldRenderUpdate(line, previousLines)
-
-			if shouldRender {
-				// Generate new full output
-				newOutput, err := renderContentIncremental(r, src, buffer.Bytes(), "")
-				if err != nil {
-					return err
-				}
-
-				// Store the new output
-				finalOutput = newOutput
-
-				// If we're using alternate screen, update it
-				if tb.isActive {
-					// If rendering drastically changed
-					if !strings.HasPrefix(newOutput, lastOutput) {
-						// Clear screen and do a full re-render in alternate buffer
-						tb.clear()
-						if err := tb.writeToAlt(newOutput); err != nil {
-							log.Debug("failed to write to alternate screen", "err", err)
-						}
-					} else {
-						// Get only the new part of the rendered output
-						newContent := strings.TrimPrefix(newOutput, lastOutput)
-						if err := tb.writeToAlt(newContent); err != nil {
-							log.Debug("failed to write to alternate screen", "err", err)
-						}
-					}
-
-					lastOutput = newOutput
-				}
-			}
-		} else if err := scanner.Err(); err != nil {
-			return fmt.Errorf("error reading from stdin: %w", err)
-		} else {
-			// End of input
-			break
-		}
-
-		// Handle timeout - render what we have so far if we haven't received input for a while
-		select {
-		case <-timeoutChan:
-			// If we have content and haven't rendered recently, do a render
-			if buffer.Len() > 0 && time.Since(lastActivity) > inactivityTimeout {
-				newOutput, err := renderContentIncremental(r, src, buffer.Bytes(), "")
-				if err != nil {
-					return err
-				}
-
-				// Store the final output
-				finalOutput = newOutput
-
-				// Update the alternate screen if active
-				if tb.isActive {
-					if newOutput != lastOutput {
-						if !strings.HasPrefix(newOutput, lastOutput) {
-							// Full re-render in alternate buffer
-							tb.clear()
-							if err := tb.writeToAlt(newOutput); err != nil {
-								log.Debug("failed to write to alternate screen", "err", err)
-							}
-						} else {
-							// Incremental update
-							newContent := strings.TrimPrefix(newOutput, lastOutput)
-							if err := tb.writeToAlt(newContent); err != nil {
-								log.Debug("failed to write to alternate screen", "err", err)
-							}
-						}
-						lastOutput = newOutput
-					}
-				}
-			}
-		default:
-			// Continue normally
-		}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return md
 	}
+	dir := filepath.Dir(path)
+	return wikilinks.Transform(md, wikilinks.NewResolver(wikilinks.Root(dir)).Link(dir))
+}
 
-	// Ensure final render happens
-	newOutput, err := renderContentIncremental(r, src, buffer.Bytes(), "")
-	if err != nil {
-		return err
+// resolveImages makes the sources of the images of a document absolute, so
+// they can be opened wherever it's read from.
+func resolveImages(src *source, md string) string {
+	if images.Mode(imagesMode) == images.ModeLink || src.URL == "" {
+		return md
 	}
-
-	// Store the final output
-	finalOutput = newOutput
-
-	// Exit alternate screen and output the final render to normal screen
-	if err := tb.finalOutput(finalOutput); err != nil {
-		return fmt.Errorf("failed to output final content: %w", err)
+	base := src.URL
+	if !isURL(base) {
+		if abs, err := filepath.Abs(base); err == nil {
+			base = abs
+		}
 	}
-
-	return nil
+	return images.Resolve(md, images.From(base))
 }
 
-// setupRenderer creates a glamour renderer with proper configuration
-func setupRenderer(src *source) (*glamour.TermRenderer, string, error) {
-	var baseURL string
-	u, err := url.ParseRequestURI(src.URL)
-	if err == nil {
-		u.Path = filepath.Dir(u.Path)
-		baseURL = u.String() + "/"
+// openImage opens an image of a document, to show its dimensions. Remote
+// images are only fetched for halftones.
+func openImage(src string) (io.ReadCloser, error) {
+	if !isURL(src) {
+		return os.Open(src) //nolint:wrapcheck
 	}
-
-	isCode := !utils.IsMarkdownFile(src.URL)
-
-	// Initialize glamour
-	r, err := glamour.NewTermRenderer(
-		glamour.WithColorProfile(lipgloss.ColorProfile()),
-		utils.GlamourStyle(style, isCode),
-		glamour.WithWordWrap(int(width)),
-		glamour.WithBaseURL(baseURL),
-		glamour.WithPreservedNewLines(),
-	)
+	if images.Mode(imagesMode) != images.ModeASCII {
+		return nil, errors.New("remote images are only fetched for halftones")
+	}
+	resp, err := fetch(context.Background(), src)
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to create renderer: %w", err)
+		return nil, err
 	}
-
-	return r, baseURL, nil
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
 }
 
 // renderContentIncremental renders the provided markdown content and returns the rendered output
 // This is used for incremental rendering to compare with previous output
 func renderContentIncremental(r *glamour.TermRenderer, src *source, content []byte, lastOutput string) (string, error) {
-	// Apply frontmatter removal
-	contentWithoutFrontmatter := utils.RemoveFrontmatter(content)
-
-	// Handle code files
-	contentStr := string(contentWithoutFrontmatter)
-	isCode := !utils.IsMarkdownFile(src.URL)
-	if isCode {
-		contentStr = utils.WrapCodeBlock(contentStr, filepath.Ext(src.URL))
-	}
+	contentStr := prepareMarkdown(src, content)
 
 	// Render the content
-	out, err := r.Render(contentStr)
+	out, err := renderDocument(r, contentStr)
 	if err != nil {
 		return "", fmt.Errorf("unable to render markdown: %w", err)
 	}
@@ -732,61 +993,167 @@ func renderContent(r *glamour.TermRenderer, src *source, content []byte, w io.Wr
 
 // renderMarkdown handles the one-time rendering of markdown content (non-stdin case)
 func renderMarkdown(cmd *cobra.Command, src *source, content []byte, w io.Writer) error {
-	content = utils.RemoveFrontmatter(content)
-
 	// Setup renderer
-	r, _, err := setupRenderer(src)
+	r, baseURL, err := setupRenderer(src)
 	if err != nil {
 		return err
 	}
 
 	// Render
-	contentStr := string(content)
-	isCode := !utils.IsMarkdownFile(src.URL)
-	if isCode {
-		contentStr = utils.WrapCodeBlock(contentStr, filepath.Ext(src.URL))
+	showPager := pager || cmd.Flags().Changed("pager")
+	showTUI := !showPager && (tui || cmd.Flags().Changed("tui"))
+
+	// Render
+	timer := startRenderTimer()
+	contentStr := prepareMarkdown(src, content)
+	source := formatSource(src, content)
+	// The TUI scrolls to the anchor instead, keeping the whole document.
+	if src.anchor != "" && !showTUI {
+		if !src.isMarkdown() {
+			return fmt.Errorf("unable to find #%s: %s is not a markdown document", src.anchor, src.URL)
+		}
+		if contentStr, err = sectionFrom(contentStr, src.anchor); err != nil {
+			return err
+		}
+		if source, err = sectionFrom(source, src.anchor); err != nil {
+			return err
+		}
 	}
+	timer.stage("parse")
 
-	out, err := r.Render(contentStr)
+	// Large documents are written as they're rendered, rather than held in
+	// memory whole.
+	if renderChunked(cmd, src, contentStr) && !useHyperlinks(w, false) {
+		err := chunks.Render(w, contentStr, chunks.Size, func(md string) (string, error) {
+			return renderFormat(md, func() (string, error) {
+				return renderDocument(r, md)
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("unable to render markdown: %w", err)
+		}
+		if showStats {
+			if _, err := fmt.Fprint(w, statsFooter(contentStr)); err != nil {
+				return fmt.Errorf("unable to write to writer: %w", err)
+			}
+		}
+		timer.stage("render")
+		timer.log(src)
+		return nil
+	}
+
+	out, err := renderFormat(source, func() (string, error) {
+		if src.isMarkdown() {
+			return renderDocument(r, contentStr)
+		}
+		return renderCode(src.URL, string(content))
+	})
 	if err != nil {
 		return fmt.Errorf("unable to render markdown: %w", err)
 	}
+	timer.stage("render")
+
+	switch copyMode {
+	case copyRaw:
+		err = utils.CopyToClipboard(string(content))
+	case copyRendered:
+		err = utils.CopyToClipboard(utils.PlainText(out))
+	}
+	if err != nil {
+		return err
+	}
+	if showStats && src.isMarkdown() && !showTUI {
+		out += statsFooter(contentStr)
+	}
 
 	// Display
 	switch {
-	case pager || cmd.Flags().Changed("pager"):
-		pagerCmd := os.Getenv("PAGER")
-		if pagerCmd == "" {
-			pagerCmd = "less -r"
-		}
-
-		pa := strings.Split(pagerCmd, " ")
-		c := exec.Command(pa[0], pa[1:]...)
-		c.Stdin = strings.NewReader(out)
-		c.Stdout = os.Stdout
-		if err := c.Run(); err != nil {
-			return fmt.Errorf("unable to run command: %w", err)
+	case showPager:
+		if useHyperlinks(os.Stdout, true) {
+			out = hyperlink(out, src, contentStr, baseURL)
 		}
-		return nil
-	case tui || cmd.Flags().Changed("tui"):
+		return runPager(out)
+	case showTUI:
+		// Only local files can be reloaded and edited in the TUI.
 		path := ""
-		if !isURL(src.URL) {
+		if info, err := os.Stat(src.URL); err == nil && !info.IsDir() {
 			path = src.URL
 		}
-		return runTUI(path, contentStr)
+		cfg, err := tuiConfig(path)
+		if err != nil {
+			return err
+		}
+		cfg.Anchor = src.anchor
+		if _, err := ui.NewProgram(cfg, contentStr).Run(); err != nil {
+			return fmt.Errorf("unable to run tui program: %w", err)
+		}
+		return nil
 	default:
+		if useHyperlinks(w, false) {
+			out = hyperlink(out, src, contentStr, baseURL)
+		}
 		if _, err = fmt.Fprint(w, out); err != nil {
 			return fmt.Errorf("unable to write to writer: %w", err)
 		}
+		timer.stage("write")
+		timer.log(src)
 		return nil
 	}
 }
 
-func runTUI(path string, content string) error {
+// runPager pipes the rendered output through $PAGER.
+func runPager(out string) error {
+	pagerCmd := os.Getenv("PAGER")
+	if pagerCmd == "" {
+		pagerCmd = "less -r"
+	}
+
+	pa := strings.Split(pagerCmd, " ")
+	c := exec.Command(pa[0], pa[1:]...)
+	c.Stdin = strings.NewReader(out)
+	c.Stdout = os.Stdout
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("unable to run command: %w", err)
+	}
+	return nil
+}
+
+// runTUI starts the TUI on path, with any further documents in tabs.
+func runTUI(path string, content string, tabs ...string) error {
+	cfg, err := tuiConfig(path)
+	if err != nil {
+		return err
+	}
+	cfg.Tabs = tabs
+
+	// Run Bubble Tea program
+	if _, err := ui.NewProgram(cfg, content).Run(); err != nil {
+		return fmt.Errorf("unable to run tui program: %w", err)
+	}
+
+	return nil
+}
+
+// runRepoTUI starts the TUI browsing the documents of a remote repository.
+func runRepoTUI(repo ui.Repo) error {
+	cfg, err := tuiConfig("")
+	if err != nil {
+		return err
+	}
+	cfg.Repo = repo
+
+	if _, err := ui.NewProgram(cfg, "").Run(); err != nil {
+		return fmt.Errorf("unable to run tui program: %w", err)
+	}
+	return nil
+}
+
+// tuiConfig returns the configuration of the TUI for browsing path.
+func tuiConfig(path string) (ui.Config, error) {
 	// Read environment to get debugging stuff
 	cfg, err := env.ParseAs[ui.Config]()
 	if err != nil {
-		return fmt.Errorf("error parsing config: %v", err)
+		return cfg, fmt.Errorf("error parsing config: %v", err)
 	}
 
 	// use style set in env, or auto if unset
@@ -798,15 +1165,40 @@ func runTUI(path string, content string) error {
 	cfg.ShowAllFiles = showAllFiles
 	cfg.ShowLineNumbers = showLineNumbers
 	cfg.GlamourMaxWidth = width
+	cfg.Margin = margin
+	cfg.Center = center
 	cfg.EnableMouse = mouse
 	cfg.PreserveNewLines = preserveNewLines
-
-	// Run Bubble Tea program
-	if _, err := ui.NewProgram(cfg, content).Run(); err != nil {
-		return fmt.Errorf("unable to run tui program: %w", err)
-	}
-
-	return nil
+	cfg.Frontmatter = frontmatterMode
+	cfg.Links = linksMode
+	cfg.Math = mathMode
+	cfg.WideTables = wideTablesMode
+	cfg.RawANSI = rawANSIMode
+	cfg.Images = imagesMode
+	cfg.CodeThemes = codeThemes
+	cfg.ReadOnly = readOnly
+	cfg.Fold = foldDocuments
+	cfg.Export = exportDocument
+	cfg.BookmarksFile = bookmarksFile()
+	if historySize() > 0 {
+		cfg.HistoryFile = dataPath(history.FileName)
+		cfg.HistorySize = historySize()
+	}
+	cfg.ShowRecent = recent
+	cfg.StashDir = stashDir()
+	cfg.TermProfile = termProfile
+	cfg.StashIdentity = expandHome(viper.GetString("stashIdentity"))
+	cfg.MaxSize = maxSize
+	cfg.ShowStats = showStats
+	if !noResume {
+		cfg.PositionsFile = dataPath(positions.FileName)
+	}
+	cfg.AnnotationsFile = dataPath(annotations.FileName)
+	cfg.Keys = viper.GetStringMapStringSlice("keys")
+	if _, err := ui.KeyBindings(cfg.Keys); err != nil {
+		return cfg, fmt.Errorf("invalid keys in config: %w", err)
+	}
+	return cfg, nil
 }
 
 func main() {
@@ -838,18 +1230,111 @@ func init() {
 	spinnerCmd.Flags().BoolVarP(&spinnerFlags.autoQuit, "auto-quit", "q", false, "automatically quit after showing all frames once")
 	spinnerCmd.AddCommand(spinnerAllCmd)
 
+	bookmarksCmd.AddCommand(bookmarksOpenCmd, bookmarksRmCmd)
+	historyCmd.AddCommand(historyOpenCmd, historyClearCmd)
+	configCmd.AddCommand(configKeysCmd, configEditCmd, configShowCmd, configGetCmd, configSetCmd)
+
+	styleEditCmd.Flags().StringVar(&styleEditFrom, "from", "", "style a new style extends (default the configured style)")
+	_ = styleEditCmd.RegisterFlagCompletionFunc("from", completeStyles)
+	styleCmd.AddCommand(styleEditCmd)
+
+	testRenderCmd.Flags().StringVar(&testRenderFlags.golden, "golden", "", "directory of the golden files")
+	testRenderCmd.Flags().BoolVar(&testRenderFlags.update, "update", false, "write the golden files instead of comparing to them")
+	testRenderCmd.Flags().StringVar(&testRenderFlags.format, "format", formatANSI, "what to compare: ansi (styled), text (plain text), md (normalized markdown)")
+	testRenderCmd.Flags().StringVarP(&testRenderFlags.style, "style", "s", styles.DarkStyle, "style name or JSON path")
+	testRenderCmd.Flags().UintVarP(&testRenderFlags.width, "width", "w", 80, "word-wrap at width")
+	_ = testRenderCmd.RegisterFlagCompletionFunc("style", completeStyles)
+
+	stashCmd.Flags().StringVarP(&stashFlags.memo, "memo", "m", "", "memo to describe the document")
+	stashCmd.PersistentFlags().StringSliceVar(&stashFlags.tags, "tag", nil, "tag the document (or, with list, only show documents with the tag)")
+	stashCmd.Flags().BoolVar(&stashFlags.encrypt, "encrypt", false, "encrypt the stored copy for --recipient, or with a passphrase")
+	stashCmd.Flags().StringSliceVar(&stashFlags.recipients, "recipient", nil, "age public key to encrypt for (default: stashRecipients)")
+	stashShowCmd.Flags().StringVar(&stashFlags.identity, "identity", "", "age identity file to decrypt with (default: stashIdentity)")
+	stashCmd.AddCommand(stashListCmd, stashShowCmd, stashRmCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:6419", "address to listen on")
+
+	sshServeCmd.Flags().StringVar(&sshServeFlags.addr, "addr", "localhost:23234", "address to listen on")
+	sshServeCmd.Flags().StringVar(&sshServeFlags.hostKey, "host-key", "", "path to the server's host key, created if missing (default: in the data directory)")
+	sshServeCmd.Flags().StringVar(&sshServeFlags.authorizedKeys, "authorized-keys", "", "only let in the public keys listed in this file (default: anyone)")
+
+	daemonCmd.Flags().StringVar(&daemonSocket, "socket", "", "path of the socket to listen on (default: glow.sock in XDG_RUNTIME_DIR, or glow-UID.sock in the temp directory)")
+
+	diffCmd.Flags().BoolVarP(&diffSideBySide, "side-by-side", "y", false, "show the old and new document in two columns")
+
+	runCmd.Flags().BoolVarP(&runYes, "yes", "y", false, "run blocks without asking for confirmation")
+
+	metaCmd.Flags().StringVar(&metaField, "get", "", "only print the given field, e.g. title or author.name")
+
+	benchCmd.Flags().IntVarP(&benchFlags.iterations, "iterations", "n", 10, "number of times to render the document")
+	benchCmd.Flags().StringVar(&benchFlags.cpuProfile, "cpuprofile", "", "write a CPU profile of the runs to a file")
+	benchCmd.Flags().StringVar(&benchFlags.memProfile, "memprofile", "", "write a profile of the allocations to a file")
+
+	presentCmd.Flags().DurationVar(&presentAuto, "auto", 0, "move on to the next slide after this long, e.g. 30s")
+	releaseCmd.Flags().BoolVar(&releaseAll, "all", false, "render the whole release history, newest first")
+
+	grepCmd.Flags().BoolVar(&grepFlags.json, "json", false, "print one JSON object per match")
+	grepCmd.Flags().BoolVarP(&grepFlags.ignoreCase, "ignore-case", "i", false, "match regardless of case")
+
 	// "Glow Classic" cli arguments
-	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("config file (default %s)", viper.GetViper().ConfigFileUsed()))
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("config file (default %s)", cmp.Or(viper.ConfigFileUsed(), defaultConfigFile)))
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "log debug messages and render timings")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "use the settings of a profile of the config file")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "write the log to a file, or - for stderr (default: glow.log in the cache dir)")
 	rootCmd.Flags().BoolVarP(&pager, "pager", "p", false, "display with pager")
 	rootCmd.Flags().BoolVarP(&tui, "tui", "t", false, "display with tui")
 	rootCmd.Flags().StringVarP(&style, "style", "s", styles.AutoStyle, "style name or JSON path")
+	_ = rootCmd.RegisterFlagCompletionFunc("style", completeStyles)
 	rootCmd.Flags().UintVarP(&width, "width", "w", 0, "word-wrap at width (set to 0 to disable)")
+	rootCmd.Flags().UintVar(&maxWidth, "max-width", defaultMaxWidth, "widest the terminal's width is wrapped at (set to 0 to use all of it)")
+	rootCmd.Flags().UintVar(&margin, "margin", 0, "columns of space left of documents")
+	rootCmd.Flags().BoolVar(&center, "center", false, "center documents narrower than the terminal")
 	rootCmd.Flags().BoolVarP(&showAllFiles, "all", "a", false, "show system files and directories (TUI-mode only)")
-	rootCmd.Flags().BoolVarP(&showLineNumbers, "line-numbers", "l", false, "show line numbers (TUI-mode only)")
+	rootCmd.Flags().BoolVarP(&showLineNumbers, "line-numbers", "l", false, "show line numbers (TUI-mode and code files only)")
 	rootCmd.Flags().BoolVarP(&preserveNewLines, "preserve-new-lines", "n", false, "preserve newlines in the output")
 	rootCmd.Flags().BoolVarP(&mouse, "mouse", "m", false, "enable mouse wheel (TUI-mode only)")
-	rootCmd.Flags().StringVar(&spinnerName, "spinner", "bouncingBall", "loading animation style: braille, dots, none")
-	rootCmd.Flags().StringVar(&spinnerColorStr, "spinner-color", "#FFFFFF", "color for spinner (any valid hex color like #FF0000)")
+	rootCmd.Flags().StringVar(&spinnerName, "spinner", string(stream.SpinnerBouncingBall), "loading animation for streams and downloads (see glow spinner), or none")
+	rootCmd.Flags().StringVar(&spinnerColorStr, "spinner-color", "#FFFFFF", "color for spinner (a hex color like #FF0000 or an ANSI color)")
+	rootCmd.Flags().DurationVar(&httpTimeout, "timeout", defaultHTTPTimeout, "timeout for fetching remote documents (0 to disable)")
+	rootCmd.Flags().IntVar(&httpRetries, "retries", defaultHTTPRetries, "times to retry failed downloads")
+	rootCmd.Flags().StringVar(&maxDownloadStr, "max-download", defaultMaxDownload, "largest remote document to download (0 to disable)")
+	rootCmd.Flags().StringVar(&maxSizeStr, "max-size", defaultMaxSize, "largest document to read (0 to disable)")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "only read remote documents from the cache of documents fetched before")
+	rootCmd.Flags().Bool("allow-network", true, "allow fetching documents and images over the network")
+	rootCmd.Flags().Bool("allow-file-uris", true, "allow documents given as file:// URIs")
+	rootCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "render all markdown files in a directory tree")
+	rootCmd.Flags().StringVar(&batchFile, "batch", "", "render the documents listed one per line in a file, or - for stdin")
+	rootCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "how many documents to render at once with --batch or --recursive (default: the number of CPUs)")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write rendered output to a file instead of stdout; files ending in .pdf are exported as PDF")
+	rootCmd.Flags().StringVar(&outputFormat, "format", formatANSI, "what to write: ansi (styled), text (plain text without escape codes), md (normalized markdown)")
+	rootCmd.Flags().StringVar(&linesFlag, "lines", "", "only render the given source lines, e.g. 40:120 (after frontmatter)")
+	rootCmd.Flags().BoolVar(&showStats, "stats", false, "show the word count, reading time, and counts of headings, links and code blocks of documents")
+	rootCmd.Flags().BoolVar(&forceTTY, "force-tty", false, "style output piped to a pager for the terminal of stderr, e.g. glow --force-tty README.md | less -R")
+	rootCmd.Flags().StringVar(&colorProfile, "color-profile", "", "force a color profile: truecolor, 256, 16 (default: detect, or truecolor with --output)")
+	rootCmd.Flags().StringVar(&streamMode, "stream", streamLine, "how to render piped input as it arrives: line, llm")
+	rootCmd.Flags().StringVar(&stdinMode, "stdin-mode", stdinAuto, "how to read stdin: auto (stream pipes, read files whole), stream, buffer")
+	rootCmd.Flags().StringVar(&stdinFilename, "filename", "", "name of the document read from stdin, to detect code and resolve relative links")
+	rootCmd.Flags().StringVar(&streamGranular, "stream-granularity", string(stream.GranularityLine), "how much piped input to wait for before rendering it: line, word, chunk")
+	rootCmd.Flags().StringVar(&termProfileName, "term-profile", "", "what the terminal can do: "+strings.Join(termprofile.Names(), ", ")+" (default: detect from $TERM)")
+	rootCmd.Flags().DurationVar(&streamDebounce, "stream-debounce", 0, "how long piped input must pause before partial lines are rendered (default: 75ms with --stream=llm, 250ms with word granularity, never otherwise)")
+	rootCmd.Flags().UintVar(&streamMaxFPS, "stream-max-fps", 0, "most renders per second of piped input as it arrives (0 for no limit)")
+	rootCmd.Flags().StringVar(&streamScreen, "stream-screen", string(stream.ScreenAlt), "where to show piped input as it's rendered: alt (alternate screen), inline (below the prompt), append (only ever adding lines)")
+	rootCmd.Flags().StringVar(&copyMode, "copy", "", "copy the document to the clipboard: raw, rendered")
+	rootCmd.Flags().Lookup("copy").NoOptDefVal = copyRaw
+	rootCmd.Flags().StringVar(&chromaTheme, "chroma-theme", "", "syntax highlighting theme for code (default: from the style)")
+	rootCmd.Flags().StringToStringVar(&codeThemeFlag, "code-theme", nil, "syntax highlighting theme for the code of a language, e.g. go=monokai,diff=github")
+	rootCmd.Flags().StringVar(&mermaidMode, "mermaid", string(mermaid.ModeASCII), "how to display mermaid diagrams: ascii, code, skip")
+	rootCmd.Flags().StringVar(&mathMode, "math", string(latex.ModeUnicode), "how to display LaTeX math: unicode, source, off")
+	rootCmd.Flags().StringVar(&wideTablesMode, "wide-tables", string(tables.ModeWrap), "how to display tables wider than the output: wrap, scroll, records")
+	rootCmd.Flags().StringVar(&rawANSIMode, "raw-ansi", string(passthrough.ModeFence), "which code blocks keep their ANSI colors: fence (ansi code blocks only), all, off")
+	rootCmd.Flags().StringVar(&imagesMode, "images", string(images.ModeBox), "how to display images: box (alt text and dimensions), ascii (with a halftone), link")
+	rootCmd.Flags().StringVar(&frontmatterMode, "frontmatter", string(frontmatter.ModeHide), "how to display YAML frontmatter: show, hide, only")
+	rootCmd.Flags().StringVar(&linksMode, "links", string(links.ModeInline), "how to display links: inline, list, footnote")
+	rootCmd.Flags().StringVar(&hyperlinksMode, "hyperlinks", hyperlinksAuto, "make links clickable in terminals: auto, always, never")
+	rootCmd.Flags().BoolVar(&recent, "recent", false, "start with the recently opened documents (TUI-mode only)")
+	rootCmd.Flags().BoolVar(&noResume, "no-resume", false, "don't resume documents where you left off (TUI-mode only)")
+	rootCmd.Flags().BoolVar(&readOnly, "readonly", false, "don't write changes, like ticked off tasks, back to documents (TUI-mode only)")
+	rootCmd.Flags().BoolVar(&foldDocuments, "fold", false, "open documents with every section folded under its heading (TUI-mode only)")
 	_ = rootCmd.Flags().MarkHidden("mouse")
 
 	// Config bindings
@@ -857,23 +1342,86 @@ func init() {
 	_ = viper.BindPFlag("tui", rootCmd.Flags().Lookup("tui"))
 	_ = viper.BindPFlag("style", rootCmd.Flags().Lookup("style"))
 	_ = viper.BindPFlag("width", rootCmd.Flags().Lookup("width"))
-	_ = viper.BindPFlag("debug", rootCmd.Flags().Lookup("debug"))
+	_ = viper.BindPFlag("maxWidth", rootCmd.Flags().Lookup("max-width"))
+	_ = viper.BindPFlag("margin", rootCmd.Flags().Lookup("margin"))
+	_ = viper.BindPFlag("center", rootCmd.Flags().Lookup("center"))
+	_ = viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
+	_ = viper.BindPFlag("logFile", rootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	_ = viper.BindPFlag("mouse", rootCmd.Flags().Lookup("mouse"))
 	_ = viper.BindPFlag("preserveNewLines", rootCmd.Flags().Lookup("preserve-new-lines"))
 	_ = viper.BindPFlag("showLineNumbers", rootCmd.Flags().Lookup("line-numbers"))
 	_ = viper.BindPFlag("all", rootCmd.Flags().Lookup("all"))
 	_ = viper.BindPFlag("spinner", rootCmd.Flags().Lookup("spinner"))
 	_ = viper.BindPFlag("spinnerColor", rootCmd.Flags().Lookup("spinner-color"))
+	_ = viper.BindPFlag("timeout", rootCmd.Flags().Lookup("timeout"))
+	_ = viper.BindPFlag("retries", rootCmd.Flags().Lookup("retries"))
+	_ = viper.BindPFlag("maxDownload", rootCmd.Flags().Lookup("max-download"))
+	_ = viper.BindPFlag("maxSize", rootCmd.Flags().Lookup("max-size"))
+	_ = viper.BindPFlag("offline", rootCmd.Flags().Lookup("offline"))
+	for key, flag := range securityKeys {
+		if flag != "" {
+			_ = viper.BindPFlag("security."+key, rootCmd.Flags().Lookup(flag))
+		}
+		viper.SetDefault("security."+key, true)
+	}
+	_ = viper.BindPFlag("mermaid", rootCmd.Flags().Lookup("mermaid"))
+	_ = viper.BindPFlag("math", rootCmd.Flags().Lookup("math"))
+	_ = viper.BindPFlag("wideTables", rootCmd.Flags().Lookup("wide-tables"))
+	_ = viper.BindPFlag("rawAnsi", rootCmd.Flags().Lookup("raw-ansi"))
+	_ = viper.BindPFlag("images", rootCmd.Flags().Lookup("images"))
+	_ = viper.BindPFlag("frontmatter", rootCmd.Flags().Lookup("frontmatter"))
+	_ = viper.BindPFlag("links", rootCmd.Flags().Lookup("links"))
+	_ = viper.BindPFlag("hyperlinks", rootCmd.Flags().Lookup("hyperlinks"))
+	_ = viper.BindPFlag("noResume", rootCmd.Flags().Lookup("no-resume"))
+	_ = viper.BindPFlag("readonly", rootCmd.Flags().Lookup("readonly"))
+	_ = viper.BindPFlag("fold", rootCmd.Flags().Lookup("fold"))
+	_ = viper.BindPFlag("chromaTheme", rootCmd.Flags().Lookup("chroma-theme"))
+	_ = viper.BindPFlag("recursive", rootCmd.Flags().Lookup("recursive"))
+	_ = viper.BindPFlag("forceTTY", rootCmd.Flags().Lookup("force-tty"))
+	_ = viper.BindPFlag("stats", rootCmd.Flags().Lookup("stats"))
+	_ = viper.BindPFlag("stream", rootCmd.Flags().Lookup("stream"))
+	_ = viper.BindPFlag("stdinMode", rootCmd.Flags().Lookup("stdin-mode"))
+	_ = viper.BindPFlag("streamGranularity", rootCmd.Flags().Lookup("stream-granularity"))
+	_ = viper.BindPFlag("streamScreen", rootCmd.Flags().Lookup("stream-screen"))
+	_ = viper.BindPFlag("streamDebounce", rootCmd.Flags().Lookup("stream-debounce"))
+	_ = viper.BindPFlag("streamMaxFps", rootCmd.Flags().Lookup("stream-max-fps"))
+	_ = viper.BindPFlag("termProfile", rootCmd.Flags().Lookup("term-profile"))
 
 	viper.SetDefault("style", styles.AutoStyle)
 	viper.SetDefault("width", 0)
+	viper.SetDefault("maxWidth", defaultMaxWidth)
 	viper.SetDefault("all", true)
-	viper.SetDefault("spinner", "braille")
+	viper.SetDefault("spinner", string(stream.SpinnerBouncingBall))
 	viper.SetDefault("spinnerColor", "#FFFFFF")
-
-	rootCmd.AddCommand(configCmd, manCmd, spinnerCmd)
+	viper.SetDefault("timeout", defaultHTTPTimeout)
+	viper.SetDefault("retries", defaultHTTPRetries)
+	viper.SetDefault("maxDownload", defaultMaxDownload)
+	viper.SetDefault("maxSize", defaultMaxSize)
+	viper.SetDefault("mermaid", string(mermaid.ModeASCII))
+	viper.SetDefault("math", string(latex.ModeUnicode))
+	viper.SetDefault("wideTables", string(tables.ModeWrap))
+	viper.SetDefault("rawAnsi", string(passthrough.ModeFence))
+	viper.SetDefault("images", string(images.ModeBox))
+	viper.SetDefault("frontmatter", string(frontmatter.ModeHide))
+	viper.SetDefault("links", string(links.ModeInline))
+	viper.SetDefault("hyperlinks", hyperlinksAuto)
+	viper.SetDefault("stream", streamLine)
+	viper.SetDefault("stdinMode", stdinAuto)
+	viper.SetDefault("historySize", history.DefaultSize)
+	viper.SetDefault("readmeNames", defaultReadmeNames)
+	viper.SetDefault("docRoots", defaultDocRoots)
+	viper.SetDefault("streamGranularity", string(stream.GranularityLine))
+	viper.SetDefault("streamScreen", string(stream.ScreenAlt))
+	viper.SetDefault("streamMaxFps", 0)
+
+	rootCmd.AddCommand(configCmd, manCmd, spinnerCmd, serveCmd, bookmarksCmd, diffCmd, stashCmd, metaCmd, lintCmd, sshServeCmd, annotationsCmd, grepCmd, benchCmd, presentCmd, readCmd, historyCmd, rfcCmd, releaseCmd, modCmd, pkgCmd, daemonCmd, runCmd, styleCmd, testRenderCmd)
 }
 
+// defaultConfigFile is the config file created on first run, where no config
+// file was found.
+var defaultConfigFile string
+
 func tryLoadConfigFromDefaultPlaces() {
 	scope := gap.NewScope(gap.User, "glow")
 	dirs, err := scope.ConfigDirs()
@@ -910,9 +1458,7 @@ func tryLoadConfigFromDefaultPlaces() {
 		return
 	}
 
-	if viper.ConfigFileUsed() == "" {
-		configFile = filepath.Join(dirs[0], "glow.yml")
-	}
+	defaultConfigFile = filepath.Join(dirs[0], "glow.yml")
 	if err := ensureConfigFile(); err != nil {
 		log.Error("Could not create default configuration", "error", err)
 	}
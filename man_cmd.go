@@ -1,29 +1,174 @@
 package main
 
 import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/douglas-larocca/glow/v2/manpage"
 	mcobra "github.com/muesli/mango-cobra"
 	"github.com/muesli/roff"
 	"github.com/spf13/cobra"
 )
 
+// Directories searched for man pages when neither MANPATH nor man(1) are
+// available.
+var defaultManPath = []string{
+	"/usr/local/share/man",
+	"/usr/share/man",
+	"/opt/homebrew/share/man",
+	"/usr/local/man",
+	"/usr/X11R6/man",
+}
+
+// Sections searched when none is given, in the order man(1) uses.
+var manSections = []string{"1", "n", "l", "8", "3", "0", "2", "5", "4", "9", "6", "7"}
+
 var manCmd = &cobra.Command{
-	Use:                   "man",
-	Short:                 "Generates manpages",
+	Use:   "man [SECTION] NAME",
+	Short: "Render a man page",
+	Long: paragraph(fmt.Sprintf("\n%s a man page with Glow's styles. Man pages piped to Glow are detected and rendered too. Without arguments, prints Glow's own man page.",
+		keyword("Render"))),
+	Example:               paragraph("glow man ls\nglow man 5 crontab\nzcat ls.1.gz | glow"),
 	SilenceUsage:          true,
 	DisableFlagsInUseLine: true,
-	Hidden:                true,
-	Args:                  cobra.NoArgs,
-	RunE: func(*cobra.Command, []string) error {
-		manPage, err := mcobra.NewManPage(1, rootCmd)
+	Args:                  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			manPage, err := mcobra.NewManPage(1, rootCmd)
+			if err != nil {
+				return fmt.Errorf("unable to instantiate man page: %w", err)
+			}
+			if _, err := fmt.Fprint(os.Stdout, manPage.Build(roff.NewDocument())); err != nil {
+				return fmt.Errorf("unable to build man page: %w", err)
+			}
+			return nil
+		}
+
+		section, name := "", args[0]
+		if len(args) == 2 {
+			section, name = args[0], args[1]
+		}
+		path, err := findManPage(section, name)
 		if err != nil {
-			return fmt.Errorf("unable to instantiate man page: %w", err)
+			return err
 		}
-		if _, err := fmt.Fprint(os.Stdout, manPage.Build(roff.NewDocument())); err != nil {
-			return fmt.Errorf("unable to build man page: %w", err)
+		content, err := readManPage(path)
+		if err != nil {
+			return err
 		}
-		return nil
+
+		md := manpage.ToMarkdown(string(content))
+		return renderMarkdown(cmd, &source{URL: ""}, []byte(md), os.Stdout)
 	},
 }
+
+// findManPage returns the path of a man page. The name may also be the path
+// of a man page file.
+func findManPage(section, name string) (string, error) {
+	if strings.ContainsRune(name, os.PathSeparator) {
+		if _, err := os.Stat(name); err != nil {
+			return "", fmt.Errorf("unable to find man page: %w", err)
+		}
+		return name, nil
+	}
+
+	dirs := manPath()
+	if dirs == nil {
+		// Let man(1) resolve it, which knows about the system's configuration.
+		a := []string{"-w", name}
+		if section != "" {
+			a = []string{"-w", section, name}
+		}
+		if out, err := exec.Command("man", a...).Output(); err == nil {
+			if path := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]); path != "" {
+				return path, nil
+			}
+		}
+		dirs = defaultManPath
+	}
+
+	sections := manSections
+	if section != "" {
+		sections = []string{section}
+	}
+	for _, s := range sections {
+		for _, dir := range dirs {
+			matches, _ := filepath.Glob(filepath.Join(dir, "man"+s[:1], name+"."+s+"*"))
+			if len(matches) > 0 {
+				return matches[0], nil
+			}
+		}
+	}
+
+	if section != "" {
+		return "", fmt.Errorf("no manual entry for %s in section %s", name, section)
+	}
+	return "", fmt.Errorf("no manual entry for %s", name)
+}
+
+// manPath returns the directories listed in MANPATH. Empty entries stand for
+// the default directories. It returns nil if MANPATH isn't set.
+func manPath() []string {
+	env := os.Getenv("MANPATH")
+	if env == "" {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(env) {
+		if dir == "" {
+			dirs = append(dirs, defaultManPath...)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// readManPage reads a man page, decompressing it if needed. Pages that only
+// include another page with .so are resolved.
+func readManPage(path string) ([]byte, error) {
+	for range 5 {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open man page: %w", err)
+		}
+		var r io.Reader = f
+		switch filepath.Ext(path) {
+		case ".gz":
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("unable to decompress man page: %w", err)
+			}
+			r = gz
+		case ".bz2":
+			r = bzip2.NewReader(f)
+		}
+		content, err := io.ReadAll(r)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read man page: %w", err)
+		}
+
+		include, ok := bytes.CutPrefix(bytes.TrimSpace(content), []byte(".so "))
+		if !ok || bytes.ContainsRune(include, '\n') {
+			return content, nil
+		}
+
+		// Included pages are relative to the root of the man directory.
+		root := filepath.Dir(filepath.Dir(path))
+		path = filepath.Join(root, string(include))
+		if matches, _ := filepath.Glob(path + "*"); len(matches) > 0 {
+			path = matches[0]
+		}
+	}
+	return nil, errors.New("unable to read man page: too many includes")
+}
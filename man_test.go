@@ -0,0 +1,47 @@
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindManPage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "man1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, "man1", "greet.1.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	_, _ = gz.Write([]byte(".TH GREET 1\n.SH NAME\ngreet\n"))
+	_ = gz.Close()
+	_ = f.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "man1", "hello.1"), []byte(".so man1/greet.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MANPATH", dir)
+
+	path, err := findManPage("", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := readManPage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), ".TH GREET 1") {
+		t.Errorf("expected the included page, got %q", content)
+	}
+
+	if _, err := findManPage("5", "greet"); err == nil {
+		t.Error("expected no page in section 5")
+	}
+}
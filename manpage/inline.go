@@ -0,0 +1,193 @@
+package manpage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Special characters, as used in \(xx and \[xx] escapes.
+var specialChars = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "−", "pl": "+", "eq": "=",
+	"bu": "•", "pc": "·", "ci": "○", "sq": "□", "dg": "†", "sc": "§", "ps": "¶",
+	"aq": "'", "dq": `"`, "lq": "“", "rq": "”", "oq": "‘", "cq": "’",
+	"Fo": "«", "Fc": "»", "fo": "‹", "fc": "›",
+	"co": "©", "rg": "®", "tm": "™", "de": "°", "ct": "¢", "Do": "$",
+	"mu": "×", "di": "÷", "+-": "±", "<=": "≤", ">=": "≥", "!=": "≠", "==": "≡",
+	"->": "→", "<-": "←", "ua": "↑", "da": "↓", "<>": "↔", "rA": "⇒", "lA": "⇐",
+	"ti": "~", "ha": "^", "rs": `\`, "sl": "/", "ba": "|", "br": "│", "ul": "_",
+	"lB": "[", "rB": "]", "lC": "{", "rC": "}", "at": "@", "sh": "#",
+	"fm": "′", "aa": "´", "ga": "`", "a\"": "\"", "a~": "~",
+}
+
+// Predefined strings, as used in \*x, \*(xx and \*[xx] escapes.
+var predefinedStrings = map[string]string{
+	"R": "®", "Tm": "™", "lq": "“", "rq": "”", "Aq": "'", "Lq": "“", "Rq": "”",
+	"L\"": "“", "R\"": "”", "C+": "C++", "--": "—", "PI": "π",
+}
+
+// inline converts roff escapes in a line of text. Font changes become
+// emphasis, unless the text is code.
+func inline(s string, code bool) string {
+	var (
+		out  strings.Builder
+		seg  strings.Builder
+		font byte = 'R'
+	)
+
+	// flush writes the text since the last font change, moving surrounding
+	// spaces outside of the emphasis so that markdown recognizes it.
+	flush := func() {
+		text := seg.String()
+		seg.Reset()
+		marker := ""
+		if !code {
+			switch font {
+			case 'B':
+				marker = "**"
+			case 'I':
+				marker = "*"
+			}
+		}
+		trimmed := strings.TrimSpace(text)
+		if marker == "" || trimmed == "" {
+			out.WriteString(text)
+			return
+		}
+		lead := text[:len(text)-len(strings.TrimLeft(text, " "))]
+		trail := text[len(strings.TrimRight(text, " ")):]
+		out.WriteString(lead + marker + trimmed + marker + trail)
+	}
+	write := func(text string) {
+		if code {
+			seg.WriteString(text)
+			return
+		}
+		seg.WriteString(escape(text))
+	}
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch != '\\' || i+1 == len(s) {
+			write(s[i : i+1])
+			continue
+		}
+
+		i++
+		switch e := s[i]; e {
+		case 'f':
+			name, n := escapeName(s[i+1:])
+			i += n
+			flush()
+			switch name {
+			case "B", "3", "CB":
+				font = 'B'
+			case "I", "2", "CI":
+				font = 'I'
+			case "BI":
+				font = 'B'
+			default:
+				font = 'R'
+			}
+		case '(', '[':
+			name, n := escapeName(s[i:])
+			i += n - 1
+			write(specialChar(name))
+		case '*':
+			name, n := escapeName(s[i+1:])
+			i += n
+			write(predefinedStrings[name])
+		case 'n':
+			_, n := escapeName(s[i+1:])
+			i += n
+		case 's':
+			// Point size: \sN, \s±N, \s(NN or \s[N]
+			j := i + 1
+			if j < len(s) && (s[j] == '+' || s[j] == '-') {
+				j++
+			}
+			if j < len(s) && (s[j] == '(' || s[j] == '[') {
+				_, n := escapeName(s[j:])
+				j += n
+			} else if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			i = j - 1
+		case 'h', 'v', 'w', 'o', 'X', 'N', 'm', 'l', 'L', 'D', 'b', 'x', 'k', 'A', 'Z', 'g', 'V', 'Y', 'M':
+			// Escapes with an argument that isn't text
+			if i+1 < len(s) && (s[i+1] == '\'' || s[i+1] == '[' || s[i+1] == '(') {
+				_, n := escapeName(s[i+1:])
+				i += n
+			} else if i+1 < len(s) {
+				i++
+			}
+		case '"':
+			i = len(s)
+		case '-':
+			write("-")
+		case 'e', '\\':
+			write(`\`)
+		case ' ', '~', '0', '_':
+			write(" ")
+		case '\'':
+			write("´")
+		case '`':
+			write("`")
+		case '.':
+			write(".")
+		case '&', '|', '^', '%', ':', 'c', ')', 'z', '/', ',', 'p', 'a', 't', '{', '}':
+		default:
+			write(s[i : i+1])
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// escapeName returns the name of an escape's argument, given the text that
+// follows the escape character, and how many bytes it takes up. Names are
+// either one character, two characters after a '(', or enclosed in brackets
+// or quotes.
+func escapeName(s string) (string, int) {
+	switch {
+	case s == "":
+		return "", 0
+	case s[0] == '(':
+		if len(s) < 3 {
+			return s[1:], len(s)
+		}
+		return s[1:3], 3
+	case s[0] == '[':
+		if end := strings.IndexByte(s, ']'); end > 0 {
+			return s[1:end], end + 1
+		}
+		return s[1:], len(s)
+	case s[0] == '\'':
+		if end := strings.IndexByte(s[1:], '\''); end >= 0 {
+			return s[1 : end+1], end + 2
+		}
+		return s[1:], len(s)
+	}
+	return s[:1], 1
+}
+
+func specialChar(name string) string {
+	if c, ok := specialChars[name]; ok {
+		return c
+	}
+	// Unicode characters like \[u00E9]
+	if strings.HasPrefix(name, "u") {
+		if r, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return string(rune(r))
+		}
+	}
+	return ""
+}
+
+// escape escapes characters markdown would otherwise interpret.
+func escape(s string) string {
+	switch s {
+	case `\`, "*", "_", "`", "[", "]", "<":
+		return `\` + s
+	}
+	return s
+}
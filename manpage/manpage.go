@@ -0,0 +1,463 @@
+// Package manpage converts man(7) and mdoc(7) pages to markdown so they can be
+// rendered like any other document.
+package manpage
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// IsRoff reports whether content looks like the source of a man page: it
+// starts with a request and has a title or section macro near the top.
+func IsRoff(content []byte) bool {
+	s := bufio.NewScanner(bytes.NewReader(content))
+	first := true
+	for n := 0; s.Scan() && n < 500; n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, `\"`) {
+			continue
+		}
+		if first && !strings.HasPrefix(line, ".") && !strings.HasPrefix(line, "'") {
+			return false
+		}
+		first = false
+		if !strings.HasPrefix(line, ".") {
+			continue
+		}
+		switch name, _ := splitRequest(line); name {
+		case "TH", "SH", "Dd", "Dt", "Sh":
+			return true
+		}
+	}
+	return false
+}
+
+// continued reports whether a line ends with an escaped newline.
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// ToMarkdown converts man or mdoc source to markdown. Requests and macros it
+// doesn't know are dropped.
+func ToMarkdown(src string) string {
+	c := &converter{}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + lines[i]
+		}
+
+		// Definitions and conditionals are skipped, including blocks that
+		// continue over several lines.
+		if c.skipTo != "" {
+			if strings.TrimSpace(line) == c.skipTo {
+				c.skipTo = ""
+			}
+			continue
+		}
+		if c.skipDepth > 0 {
+			c.skipDepth += strings.Count(line, `\{`) - strings.Count(line, `\}`)
+			continue
+		}
+
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			c.request(line)
+			continue
+		}
+		c.text(line)
+	}
+	c.endCode()
+	return strings.TrimSpace(c.out.String()) + "\n"
+}
+
+type listKind int
+
+const (
+	listTag listKind = iota
+	listBullet
+	listEnum
+)
+
+type converter struct {
+	out strings.Builder
+
+	skipTo    string // line ending the block being skipped
+	skipDepth int    // nesting of skipped conditional blocks
+
+	code        bool   // in a literal block
+	expect      string // macro waiting for the next text line
+	prefix      string // written before the next text line
+	indent      string // written before text lines
+	lists       []listKind
+	name        string // document name, for mdoc's Nm
+	linkURL     string
+	linkText    []string
+	blankNeeded bool
+}
+
+// splitRequest splits a request line into its name and the rest of the line.
+func splitRequest(line string) (string, string) {
+	line = strings.TrimLeft(line[1:], " \t")
+	if strings.HasPrefix(line, `\"`) {
+		return "", ""
+	}
+	end := strings.IndexAny(line, " \t\\")
+	if end < 0 {
+		return line, ""
+	}
+	return line[:end], strings.TrimSpace(line[end:])
+}
+
+// args splits the arguments of a request, honouring double quotes.
+func args(s string) []string {
+	var (
+		out    []string
+		cur    strings.Builder
+		quoted bool
+		inArg  bool
+	)
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\\' && i+1 < len(s) && s[i+1] == '"':
+			// A comment ends the arguments.
+			s = s[:i]
+		case ch == '"' && quoted && i+1 < len(s) && s[i+1] == '"':
+			cur.WriteByte('"')
+			i++
+		case ch == '"' && (quoted || !inArg):
+			quoted = !quoted
+			inArg = true
+		case (ch == ' ' || ch == '\t') && !quoted:
+			if inArg {
+				out = append(out, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		case ch == '\\' && i+1 < len(s):
+			cur.WriteByte(ch)
+			cur.WriteByte(s[i+1])
+			i++
+			inArg = true
+		default:
+			cur.WriteByte(ch)
+			inArg = true
+		}
+	}
+	if inArg {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+func (c *converter) request(line string) {
+	name, rest := splitRequest(line)
+	if name == "" {
+		return
+	}
+	a := args(rest)
+
+	switch name {
+	// Definitions, conditionals and other roff requests
+	case "de", "de1", "am", "ig":
+		c.skipTo = ".."
+		if name == "ig" && len(a) > 0 {
+			c.skipTo = "." + a[0]
+		}
+	case "if", "ie", "el":
+		c.skipDepth = strings.Count(rest, `\{`) - strings.Count(rest, `\}`)
+	case "br", "sp", "Sp":
+		if c.code {
+			c.out.WriteString("\n")
+			return
+		}
+		c.paragraph()
+
+	// man(7)
+	case "TH":
+		c.paragraph()
+		if len(a) > 0 {
+			c.name = a[0]
+			title := a[0]
+			if len(a) > 1 {
+				title += "(" + a[1] + ")"
+			}
+			c.block("# " + inline(title, false))
+		}
+	case "SH", "SS":
+		c.heading(name == "SS", a)
+	case "PP", "LP", "P", "HP":
+		c.paragraph()
+		c.indent = ""
+	case "TP", "TQ":
+		c.paragraph()
+		c.expect = "TP"
+	case "IP":
+		c.paragraph()
+		if len(a) > 0 && a[0] != "" {
+			c.item(inline(a[0], false))
+		}
+	case "RS", "RE":
+		c.paragraph()
+	case "nf", "EX", "Vb":
+		c.startCode()
+	case "fi", "EE", "Ve":
+		c.endCode()
+	case "B", "I", "SB":
+		if len(a) == 0 {
+			c.expect = name
+			return
+		}
+		c.text(font(name, strings.Join(a, " ")))
+	case "SM":
+		c.text(strings.Join(a, " "))
+	case "BR", "BI", "IB", "IR", "RB", "RI":
+		var b strings.Builder
+		for i, arg := range a {
+			b.WriteString(`\f` + string(name[i%2]) + arg)
+		}
+		b.WriteString(`\fR`)
+		c.text(b.String())
+	case "UR", "MT":
+		c.linkURL = strings.Join(a, "")
+		if name == "MT" {
+			c.linkURL = "mailto:" + c.linkURL
+		}
+		c.linkText = nil
+	case "UE", "ME":
+		text := strings.Join(c.linkText, " ")
+		link := "<" + c.linkURL + ">"
+		if text != "" {
+			link = "[" + text + "](" + c.linkURL + ")"
+		}
+		c.linkURL = ""
+		c.write(link + inline(strings.Join(a, ""), false))
+
+	// mdoc(7)
+	case "Dt":
+		c.paragraph()
+		if len(a) > 0 {
+			title := a[0]
+			if len(a) > 1 {
+				title += "(" + a[1] + ")"
+			}
+			c.block("# " + inline(title, false))
+		}
+	case "Sh", "Ss":
+		c.heading(name == "Ss", a)
+	case "Pp", "Lp":
+		c.paragraph()
+	case "Nm":
+		if c.name == "" && len(a) > 0 {
+			c.name = a[0]
+		}
+		c.write(c.mdoc(line[1:]))
+	case "Nd":
+		c.write("— " + inline(strings.Join(a, " "), false))
+	case "Bl":
+		c.paragraph()
+		kind := listTag
+		for _, arg := range a {
+			switch arg {
+			case "-bullet", "-dash", "-hyphen", "-item":
+				kind = listBullet
+			case "-enum":
+				kind = listEnum
+			}
+		}
+		c.lists = append(c.lists, kind)
+	case "El":
+		c.paragraph()
+		if len(c.lists) > 0 {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+		c.indent = strings.Repeat("  ", len(c.lists))
+	case "It":
+		c.paragraph()
+		kind := listTag
+		if len(c.lists) > 0 {
+			kind = c.lists[len(c.lists)-1]
+		}
+		switch kind {
+		case listTag:
+			c.item(c.mdoc(rest))
+		case listBullet:
+			c.prefix = "- "
+		case listEnum:
+			c.prefix = "1. "
+		}
+		c.indent = strings.Repeat("  ", len(c.lists))
+	case "Bd":
+		c.paragraph()
+		for _, arg := range a {
+			if arg == "-literal" || arg == "-unfilled" {
+				c.startCode()
+			}
+		}
+	case "Ed":
+		if c.code {
+			c.endCode()
+		} else {
+			c.paragraph()
+		}
+	case "D1", "Dl":
+		c.paragraph()
+		text := c.mdoc(rest)
+		if name == "Dl" {
+			text = "`" + inline(strings.Join(a, " "), true) + "`"
+		}
+		c.block(text)
+	case "Dd", "Os", "Bf", "Ef", "Rs", "Re":
+
+	default:
+		if isMdocMacro(name) {
+			c.write(c.mdoc(line[1:]))
+		}
+	}
+}
+
+// text writes a line of text, which may be the argument a preceding macro
+// waits for.
+func (c *converter) text(line string) {
+	if c.code {
+		c.out.WriteString(c.indent + inline(line, true) + "\n")
+		return
+	}
+	if strings.TrimSpace(line) == "" {
+		c.paragraph()
+		return
+	}
+	line = strings.TrimLeft(line, " \t")
+
+	switch c.expect {
+	case "TP":
+		c.expect = ""
+		c.item(inline(line, false))
+		return
+	case "SH", "SS":
+		c.expect = ""
+		c.heading(false, []string{line})
+		return
+	case "B", "I", "SB":
+		line = font(c.expect, line)
+		c.expect = ""
+	}
+
+	if c.linkURL != "" {
+		c.linkText = append(c.linkText, inline(line, false))
+		return
+	}
+	c.write(inline(line, false))
+}
+
+// write writes a line of text in the current paragraph.
+func (c *converter) write(s string) {
+	c.writeRaw(c.prefix + escapeLineStart(s))
+	c.prefix = ""
+}
+
+func (c *converter) writeRaw(s string) {
+	if c.blankNeeded {
+		c.out.WriteString("\n")
+		c.blankNeeded = false
+	}
+	c.out.WriteString(c.indentFor() + s + "\n")
+}
+
+func (c *converter) indentFor() string {
+	if c.prefix != "" && len(c.indent) >= 2 {
+		return c.indent[2:]
+	}
+	return c.indent
+}
+
+// item starts a list item with a tag. The text that follows is indented
+// below it.
+func (c *converter) item(tag string) {
+	depth := max(len(c.lists), 1)
+	c.indent = strings.Repeat("  ", depth-1)
+	c.writeRaw("- " + tag)
+	c.indent = strings.Repeat("  ", depth)
+}
+
+func (c *converter) heading(sub bool, a []string) {
+	c.endCode()
+	c.paragraph()
+	c.indent = ""
+	c.lists = nil
+	if len(a) == 0 {
+		c.expect = "SH"
+		if sub {
+			c.expect = "SS"
+		}
+		return
+	}
+	level := "## "
+	if sub {
+		level = "### "
+	}
+	c.block(level + inline(strings.Join(a, " "), false))
+}
+
+// block writes a line as a block of its own.
+func (c *converter) block(s string) {
+	c.paragraph()
+	c.writeRaw(s)
+	c.paragraph()
+}
+
+// paragraph ends the current paragraph.
+func (c *converter) paragraph() {
+	if c.code {
+		return
+	}
+	if c.out.Len() > 0 {
+		c.blankNeeded = true
+	}
+}
+
+func (c *converter) startCode() {
+	if c.code {
+		return
+	}
+	c.paragraph()
+	if c.blankNeeded {
+		c.out.WriteString("\n")
+		c.blankNeeded = false
+	}
+	c.out.WriteString(c.indent + "```\n")
+	c.code = true
+}
+
+func (c *converter) endCode() {
+	if !c.code {
+		return
+	}
+	c.out.WriteString(c.indent + "```\n")
+	c.code = false
+	c.paragraph()
+}
+
+// font wraps text in a font change for a B, I or SB macro.
+func font(macro, text string) string {
+	f := "B"
+	if macro == "I" {
+		f = "I"
+	}
+	return `\f` + f + text + `\fR`
+}
+
+// escapeLineStart escapes text that markdown would read as a block marker.
+func escapeLineStart(s string) string {
+	trimmed := strings.TrimLeft(s, " ")
+	for _, p := range []string{"- ", "+ ", "#", ">", "=", "|"} {
+		if strings.HasPrefix(trimmed, p) {
+			return s[:len(s)-len(trimmed)] + `\` + trimmed
+		}
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package manpage
+
+import "testing"
+
+const manSource = `.\" A comment
+.de XX
+.ft B
+..
+.TH GREET 1 "2024-01-01" "greet 1.0"
+.SH NAME
+greet \- say hello
+.SH SYNOPSIS
+.B greet
+[\fB\-n\fR \fIname\fR]
+.SH OPTIONS
+.TP
+.BR \-n ", " \-\-name
+Who to greet, e.g.
+.IR world .
+.SH EXAMPLE
+.EX
+$ greet -n *world*
+.EE
+See
+.UR https://example.com
+the website
+.UE .
+`
+
+const manMarkdown = `# GREET(1)
+
+## NAME
+
+greet - say hello
+
+## SYNOPSIS
+
+**greet**
+\[**-n** *name*\]
+
+## OPTIONS
+
+- **-n**, **--name**
+  Who to greet, e.g.
+  *world*.
+
+## EXAMPLE
+
+` + "```" + `
+$ greet -n *world*
+` + "```" + `
+
+See
+[the website](https://example.com).
+`
+
+const mdocSource = `.Dd January 1, 2024
+.Dt GREET 1
+.Os
+.Sh NAME
+.Nm greet
+.Nd say hello
+.Sh SYNOPSIS
+.Nm
+.Op Fl n Ar name
+.Sh OPTIONS
+.Bl -tag -width Ds
+.It Fl n Ar name
+Who to greet, see
+.Xr hello 1 .
+.El
+.Bl -bullet
+.It
+One
+.El
+`
+
+const mdocMarkdown = `# GREET(1)
+
+## NAME
+
+**greet**
+— say hello
+
+## SYNOPSIS
+
+**greet**
+[**-n** *name*]
+
+## OPTIONS
+
+- **-n** *name*
+  Who to greet, see
+  **hello**(1).
+
+- One
+`
+
+func TestToMarkdown(t *testing.T) {
+	for name, tc := range map[string][2]string{
+		"man":  {manSource, manMarkdown},
+		"mdoc": {mdocSource, mdocMarkdown},
+	} {
+		if !IsRoff([]byte(tc[0])) {
+			t.Errorf("%s: expected source to be detected as roff", name)
+		}
+		if got := ToMarkdown(tc[0]); got != tc[1] {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, tc[1], got)
+		}
+	}
+}
+
+func TestIsRoff(t *testing.T) {
+	for _, s := range []string{
+		"# Heading\n\n.TH not a man page\n",
+		".. a note\n\ntext\n",
+		"",
+	} {
+		if IsRoff([]byte(s)) {
+			t.Errorf("expected %q not to be detected as roff", s)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package manpage
+
+import "strings"
+
+// Inline mdoc macros and how their arguments are formatted.
+var mdocStyles = map[string]string{
+	// bold
+	"Nm": "**", "Fl": "**", "Cm": "**", "Ic": "**", "Sy": "**", "Fn": "**",
+	"Fd": "**", "Er": "**", "Dv": "**", "In": "**", "Cd": "**",
+	// italic
+	"Ar": "*", "Em": "*", "Va": "*", "Fa": "*", "Vt": "*", "Ft": "*", "Ev": "*",
+	// code
+	"Pa": "`", "Li": "`", "Ql": "`",
+	// plain
+	"No": "", "Tn": "", "St": "", "Ns": "", "Pf": "", "Ux": "", "Bx": "",
+	"An": "", "Mt": "", "Xr": "", "Lk": "",
+	"At": "", "Nx": "", "Fx": "", "Ox": "", "Bsx": "", "Ta": "",
+}
+
+// Enclosing mdoc macros, which wrap the rest of the line.
+var mdocEnclosures = map[string][2]string{
+	"Op": {"[", "]"}, "Oo": {"[", ""}, "Oc": {"", "]"},
+	"Pq": {"(", ")"}, "Po": {"(", ""}, "Pc": {"", ")"},
+	"Bq": {"[", "]"}, "Bo": {"[", ""}, "Bc": {"", "]"},
+	"Brq": {"{", "}"}, "Bro": {"{", ""}, "Brc": {"", "}"},
+	"Aq": {"⟨", "⟩"}, "Ao": {"⟨", ""}, "Ac": {"", "⟩"},
+	"Dq": {"“", "”"}, "Do": {"“", ""}, "Dc": {"", "”"},
+	"Sq": {"‘", "’"}, "So": {"‘", ""}, "Sc": {"", "’"},
+	"Qq": {`"`, `"`}, "Qo": {`"`, ""}, "Qc": {"", `"`},
+}
+
+// Names of the systems some macros stand for.
+var mdocSystems = map[string]string{
+	"Ux": "UNIX", "Bx": "BSD", "At": "AT&T UNIX", "Nx": "NetBSD",
+	"Fx": "FreeBSD", "Ox": "OpenBSD", "Bsx": "BSD/OS",
+}
+
+func isMdocMacro(name string) bool {
+	_, style := mdocStyles[name]
+	_, enclosure := mdocEnclosures[name]
+	return style || enclosure
+}
+
+// isDelimiter reports whether an argument is punctuation that attaches to
+// the text before it.
+func isDelimiter(s string) bool {
+	switch s {
+	case ".", ",", ";", ":", "?", "!", ")", "]", "|":
+		return true
+	}
+	return false
+}
+
+// mdoc formats a line of mdoc macros and their arguments.
+func (c *converter) mdoc(line string) string {
+	var (
+		b       strings.Builder
+		noSpace bool
+	)
+	add := func(s string, attach bool) {
+		if s == "" {
+			return
+		}
+		if b.Len() > 0 && !noSpace && !attach {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s)
+		noSpace = strings.HasSuffix(s, "(") || strings.HasSuffix(s, "[")
+	}
+
+	a := args(line)
+	for i := 0; i < len(a); {
+		name := a[i]
+		if open, ok := mdocEnclosures[name]; ok {
+			// Closing macros end the text before them, opening ones the
+			// text after them.
+			i++
+			switch {
+			case open[0] == "":
+				add(open[1], true)
+			case open[1] == "":
+				add(open[0], false)
+				noSpace = true
+			default:
+				end := len(a)
+				for end > i && isDelimiter(a[end-1]) {
+					end--
+				}
+				add(open[0]+c.mdoc(strings.Join(quoteArgs(a[i:end]), " "))+open[1], false)
+				for _, d := range a[end:] {
+					add(d, true)
+				}
+				i = len(a)
+			}
+			continue
+		}
+
+		style, ok := mdocStyles[name]
+		if !ok {
+			add(inline(name, false), isDelimiter(name))
+			i++
+			continue
+		}
+		i++
+
+		// Arguments up to the next macro
+		j := i
+		for j < len(a) && !isMdocMacro(a[j]) {
+			j++
+		}
+		words := a[i:j]
+		i = j
+
+		var trailing []string
+		for len(words) > 0 && isDelimiter(words[len(words)-1]) {
+			trailing = append([]string{words[len(words)-1]}, trailing...)
+			words = words[:len(words)-1]
+		}
+
+		switch name {
+		case "Ns":
+			noSpace = true
+		case "Pf":
+			if len(words) > 0 {
+				add(inline(words[0], false), false)
+				noSpace = true
+				words = words[1:]
+			}
+			for _, w := range words {
+				add(inline(w, false), false)
+			}
+		case "Nm":
+			if len(words) == 0 {
+				words = []string{c.name}
+			}
+			add(emphasize(style, words), false)
+		case "Fl":
+			if len(words) == 0 {
+				words = []string{""}
+			}
+			for k, w := range words {
+				words[k] = "-" + w
+			}
+			add(emphasize(style, words), false)
+		case "Ar":
+			if len(words) == 0 {
+				words = []string{"file ..."}
+			}
+			add(emphasize(style, words), false)
+		case "Xr":
+			if len(words) > 0 {
+				s := "**" + inline(words[0], false) + "**"
+				if len(words) > 1 {
+					s += "(" + inline(words[1], false) + ")"
+				}
+				add(s, false)
+			}
+		case "Lk":
+			if len(words) > 0 {
+				text := words[0]
+				if len(words) > 1 {
+					text = strings.Join(words[1:], " ")
+				}
+				add("["+inline(text, false)+"]("+words[0]+")", false)
+			}
+		case "Mt":
+			for _, w := range words {
+				add("<mailto:"+w+">", false)
+			}
+		case "Ux", "Bx", "At", "Nx", "Fx", "Ox", "Bsx":
+			add(mdocSystems[name]+strings.Join(words, " "), false)
+		case "Ta":
+			add("|", false)
+			for _, w := range words {
+				add(inline(w, false), false)
+			}
+		default:
+			if len(words) > 0 {
+				add(emphasize(style, words), false)
+			}
+		}
+		for _, d := range trailing {
+			add(d, true)
+		}
+	}
+	return b.String()
+}
+
+// emphasize formats words with a markdown emphasis marker.
+func emphasize(marker string, words []string) string {
+	if marker == "`" {
+		return "`" + inline(strings.Join(words, " "), true) + "`"
+	}
+	return marker + inline(strings.Join(words, " "), false) + marker
+}
+
+// quoteArgs quotes arguments that contain spaces so they survive being
+// joined and split again.
+func quoteArgs(a []string) []string {
+	out := make([]string, len(a))
+	for i, s := range a {
+		if strings.ContainsAny(s, " \t") {
+			s = `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		}
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,278 @@
+// Package mdfmt normalizes markdown documents: paragraphs are reflowed to a
+// width, headings are written with hashes, bullets with dashes, code blocks
+// with fences, and blank lines are collapsed. Inline markup is kept as
+// written.
+package mdfmt
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	md = goldmark.New()
+
+	// Words that would start a block, like a list or a quote, if a
+	// paragraph was wrapped before them.
+	blockStartPattern = regexp.MustCompile("^(#{1,6}$|[-+*]$|\\d{1,9}[.)]$|=+$|-+$|>|```|~~~|<[a-zA-Z/!?])")
+	delimiterPattern  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	fenceRunPattern   = regexp.MustCompile("(?m)^\\s*(`{3,})")
+	alertPattern      = regexp.MustCompile(`^\[![A-Za-z]+\]$`)
+)
+
+// Format normalizes a markdown document, wrapping paragraphs at width, or
+// not at all if it's zero. Link reference definitions are moved to the end.
+func Format(src []byte, width int) string {
+	ctx := parser.NewContext()
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	f := &formatter{src: src, width: width}
+	f.blocks(doc, "", "", false)
+
+	refs := ctx.References()
+	if len(refs) > 0 {
+		defs := make([]string, 0, len(refs))
+		for _, r := range refs {
+			def := fmt.Sprintf("[%s]: %s", r.Label(), r.Destination())
+			if len(r.Title()) > 0 {
+				def += fmt.Sprintf(" %q", r.Title())
+			}
+			defs = append(defs, def)
+		}
+		slices.Sort(defs)
+		if f.out.Len() > 0 {
+			f.out.WriteString("\n")
+		}
+		f.out.WriteString(strings.Join(defs, "\n") + "\n")
+	}
+	return f.out.String()
+}
+
+type formatter struct {
+	src   []byte
+	width int
+	out   strings.Builder
+}
+
+// blocks writes the blocks below parent. The first line is prefixed with
+// first, and the others with rest, e.g. a list marker and its indentation.
+// Tight blocks aren't separated by blank lines.
+func (f *formatter) blocks(parent ast.Node, first, rest string, tight bool) {
+	prefix, written := first, false
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		// Paragraphs of link reference definitions are left as empty
+		// text blocks.
+		switch n.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			if n.Lines().Len() == 0 {
+				continue
+			}
+		}
+		if written {
+			if !tight {
+				f.out.WriteString(strings.TrimRight(rest, " ") + "\n")
+			}
+			prefix = rest
+		}
+		f.block(n, prefix, rest)
+		written = true
+	}
+}
+
+func (f *formatter) block(n ast.Node, first, rest string) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		f.lines(first, rest, []string{strings.Repeat("#", n.Level) + " " + strings.Join(f.text(n), " ")})
+	case *ast.Paragraph, *ast.TextBlock:
+		lines := f.text(n)
+		if isTable(lines) {
+			f.lines(first, rest, lines)
+			return
+		}
+		raw := f.raw(n)
+		if isAlert(n, lines) {
+			// The marker of an alert stays on its own line, or the text
+			// after it would become its title.
+			f.lines(first, rest, lines[:1])
+			first, raw = rest, raw[1:]
+			if len(raw) == 0 {
+				return
+			}
+		}
+		f.lines(first, rest, f.wrap(hardBreaks(raw), runewidth.StringWidth(rest)))
+	case *ast.ThematicBreak:
+		f.lines(first, rest, []string{"---"})
+	case *ast.FencedCodeBlock:
+		f.code(n, first, rest, string(n.Language(f.src)))
+	case *ast.CodeBlock:
+		f.code(n, first, rest, "")
+	case *ast.HTMLBlock:
+		lines := f.raw(n)
+		if n.HasClosure() {
+			lines = append(lines, strings.TrimRight(string(n.ClosureLine.Value(f.src)), "\r\n"))
+		}
+		f.lines(first, rest, lines)
+	case *ast.Blockquote:
+		if n.ChildCount() == 0 {
+			f.lines(first, rest, []string{">"})
+			return
+		}
+		f.blocks(n, first+"> ", rest+"> ", false)
+	case *ast.List:
+		start := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			if item != n.FirstChild() {
+				if !n.IsTight {
+					f.out.WriteString(strings.TrimRight(rest, " ") + "\n")
+				}
+				first = rest
+			}
+			marker := bullet(n)
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d%c", start, n.Marker)
+				start++
+			}
+			if item.ChildCount() == 0 {
+				f.lines(first, rest, []string{marker})
+				continue
+			}
+			f.blocks(item, first+marker+" ", rest+strings.Repeat(" ", len(marker)+1), n.IsTight)
+		}
+	default:
+		f.lines(first, rest, f.raw(n))
+	}
+}
+
+// bullet returns the marker of the items of a bullet list: a dash, or a star
+// for a list right after another, which would otherwise continue it.
+func bullet(list *ast.List) string {
+	marker := "-"
+	for prev := list.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+		l, ok := prev.(*ast.List)
+		if !ok || l.IsOrdered() {
+			break
+		}
+		if marker == "-" {
+			marker = "*"
+		} else {
+			marker = "-"
+		}
+	}
+	return marker
+}
+
+// lines writes lines, prefixed with first and then rest.
+func (f *formatter) lines(first, rest string, lines []string) {
+	prefix := first
+	for _, l := range lines {
+		f.out.WriteString(strings.TrimRight(prefix+l, " \t") + "\n")
+		prefix = rest
+	}
+}
+
+// code writes a code block with fences longer than any line of backticks
+// in the code.
+func (f *formatter) code(n ast.Node, first, rest, info string) {
+	lines := f.raw(n)
+	fence := 3
+	for _, m := range fenceRunPattern.FindAllStringSubmatch(strings.Join(lines, "\n"), -1) {
+		fence = max(fence, len(m[1])+1)
+	}
+	ticks := strings.Repeat("`", fence)
+	f.lines(first, rest, append(append([]string{ticks + info}, lines...), ticks))
+}
+
+// raw returns the lines of a block as written, without their line endings.
+func (f *formatter) raw(n ast.Node) []string {
+	var lines []string
+	for i := range n.Lines().Len() {
+		seg := n.Lines().At(i)
+		l := strings.Repeat(" ", seg.Padding) + string(seg.Value(f.src))
+		lines = append(lines, strings.TrimRight(l, "\r\n"))
+	}
+	return lines
+}
+
+// text returns the lines of a block, trimmed.
+func (f *formatter) text(n ast.Node) []string {
+	lines := f.raw(n)
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return lines
+}
+
+// hardBreaks returns the text of the lines of a paragraph split at its hard
+// line breaks, which are kept as trailing backslashes.
+func hardBreaks(lines []string) []string {
+	var (
+		parts   []string
+		current []string
+	)
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		last := i == len(lines)-1
+		switch {
+		case !last && strings.HasSuffix(l, "  "):
+			current = append(current, trimmed+"\\")
+		case !last && strings.HasSuffix(trimmed, "\\"):
+			current = append(current, trimmed)
+		default:
+			current = append(current, trimmed)
+			continue
+		}
+		parts = append(parts, strings.Join(current, " "))
+		current = nil
+	}
+	if len(current) > 0 {
+		parts = append(parts, strings.Join(current, " "))
+	}
+	return parts
+}
+
+// wrap wraps paragraphs of text at the width, less that of their indent.
+func (f *formatter) wrap(parts []string, indent int) []string {
+	width := 0
+	if f.width > 0 {
+		width = max(f.width-indent, 20)
+	}
+	var lines []string
+	for _, p := range parts {
+		var line string
+		for _, word := range strings.Fields(p) {
+			switch {
+			case line == "":
+				line = word
+			case width > 0 && runewidth.StringWidth(line)+1+runewidth.StringWidth(word) > width &&
+				!blockStartPattern.MatchString(word):
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// isAlert reports whether a paragraph starts a GitHub alert: it's the first
+// in a quote, and its first line is a marker like [!WARNING].
+func isAlert(n ast.Node, lines []string) bool {
+	_, quoted := n.Parent().(*ast.Blockquote)
+	return quoted && n.PreviousSibling() == nil && alertPattern.MatchString(lines[0])
+}
+
+// isTable reports whether the lines of a paragraph are a table, whose rows
+// are kept as written.
+func isTable(lines []string) bool {
+	return len(lines) > 1 && strings.Contains(lines[0], "|") && delimiterPattern.MatchString(lines[1])
+}
@@ -0,0 +1,72 @@
+package mdfmt
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		src   string
+		width int
+		want  string
+	}{
+		{
+			name: "headings and blank lines",
+			src:  "Title\n=====\n\n\n\nSome *text*.\n",
+			want: "# Title\n\nSome *text*.\n",
+		},
+		{
+			name:  "reflow",
+			src:   "one two three\nfour five six seven\n",
+			width: 20,
+			want:  "one two three four\nfive six seven\n",
+		},
+		{
+			name:  "no wrap before a list marker",
+			src:   "aaaa bbbb cccc dddd eeee ffff gggg -\n",
+			width: 20,
+			want:  "aaaa bbbb cccc dddd\neeee ffff gggg -\n",
+		},
+		{
+			name: "bullets and quotes",
+			src:  "* one\n+ two\n\n> quoted\n> text\n",
+			want: "- one\n\n* two\n\n> quoted text\n",
+		},
+		{
+			name: "alerts",
+			src:  "> [!WARNING]\n> Be\n> careful.\n\n> [!NOTE]\n",
+			want: "> [!WARNING]\n> Be careful.\n\n> [!NOTE]\n",
+		},
+		{
+			name: "ordered list",
+			src:  "3) three\n4) four\n",
+			want: "3) three\n4) four\n",
+		},
+		{
+			name: "code fences",
+			src:  "    indented\n\n~~~go\n```\n~~~\n",
+			want: "```\nindented\n```\n\n````go\n```\n````\n",
+		},
+		{
+			name: "hard breaks",
+			src:  "one  \ntwo\\\nthree\n",
+			want: "one\\\ntwo\\\nthree\n",
+		},
+		{
+			name:  "tables",
+			src:   "| a | b |\n|---|---|\n| 1 | 2 |\n",
+			width: 5,
+			want:  "| a | b |\n|---|---|\n| 1 | 2 |\n",
+		},
+		{
+			name: "references",
+			src:  "[b]: /b\n\nSee [a] and [b].\n\n[a]: /a \"A\"\n",
+			want: "See [a] and [b].\n\n[a]: /a \"A\"\n[b]: /b\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Format([]byte(tc.src), tc.width); got != tc.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tc.want)
+			}
+		})
+	}
+}
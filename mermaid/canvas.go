@@ -0,0 +1,217 @@
+package mermaid
+
+import (
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// Line directions are tracked per cell as a bitmask so that crossing and
+// joining lines can be resolved to the proper box-drawing junction when the
+// canvas is printed.
+const (
+	up uint8 = 1 << iota
+	down
+	left
+	right
+)
+
+var junctions = map[uint8]rune{
+	up:                       '│',
+	down:                     '│',
+	up | down:                '│',
+	left:                     '─',
+	right:                    '─',
+	left | right:             '─',
+	down | right:             '┌',
+	down | left:              '┐',
+	up | right:               '└',
+	up | left:                '┘',
+	up | down | right:        '├',
+	up | down | left:         '┤',
+	down | left | right:      '┬',
+	up | left | right:        '┴',
+	up | down | left | right: '┼',
+}
+
+// wide marks the second cell of a double-width rune.
+const wide = -1
+
+// canvas is a growable grid of terminal cells.
+type canvas struct {
+	runes [][]rune
+	lines [][]uint8
+}
+
+func (c *canvas) grow(x, y int) {
+	for len(c.runes) <= y {
+		c.runes = append(c.runes, nil)
+		c.lines = append(c.lines, nil)
+	}
+	for len(c.runes[y]) <= x {
+		c.runes[y] = append(c.runes[y], 0)
+		c.lines[y] = append(c.lines[y], 0)
+	}
+}
+
+// set places a rune at the given position, overriding any line drawn there.
+func (c *canvas) set(x, y int, r rune) {
+	if x < 0 || y < 0 {
+		return
+	}
+	c.grow(x, y)
+	c.runes[y][x] = r
+}
+
+// text writes s starting at the given position and returns its display width.
+func (c *canvas) text(x, y int, s string) int {
+	w := 0
+	for _, r := range s {
+		rw := runewidth.RuneWidth(r)
+		if rw == 0 {
+			continue
+		}
+		c.set(x+w, y, r)
+		if rw == 2 {
+			c.set(x+w+1, y, wide)
+		}
+		w += rw
+	}
+	return w
+}
+
+func (c *canvas) mark(x, y int, dir uint8) {
+	if x < 0 || y < 0 {
+		return
+	}
+	c.grow(x, y)
+	c.lines[y][x] |= dir
+}
+
+// hline draws a horizontal line between x1 and x2 (inclusive) on row y.
+func (c *canvas) hline(x1, x2, y int) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	for x := x1; x <= x2; x++ {
+		var d uint8
+		if x > x1 {
+			d |= left
+		}
+		if x < x2 {
+			d |= right
+		}
+		c.mark(x, y, d)
+	}
+}
+
+// vline draws a vertical line between y1 and y2 (inclusive) on column x.
+func (c *canvas) vline(x, y1, y2 int) {
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	for y := y1; y <= y2; y++ {
+		var d uint8
+		if y > y1 {
+			d |= up
+		}
+		if y < y2 {
+			d |= down
+		}
+		c.mark(x, y, d)
+	}
+}
+
+// flip mirrors everything drawn so far across the vertical axis when
+// horizontal is set, or across the horizontal one otherwise, so that a
+// canvas spanning width w and height h reads from the other side.
+func (c *canvas) flip(horizontal bool, w, h int) {
+	c.grow(0, h-1)
+	for y := range c.runes {
+		c.grow(w-1, y)
+	}
+	if !horizontal {
+		for i, j := 0, len(c.runes)-1; i < j; i, j = i+1, j-1 {
+			c.runes[i], c.runes[j] = c.runes[j], c.runes[i]
+			c.lines[i], c.lines[j] = c.lines[j], c.lines[i]
+		}
+	}
+	for y := range c.runes {
+		if horizontal {
+			for i, j := 0, len(c.runes[y])-1; i < j; i, j = i+1, j-1 {
+				c.runes[y][i], c.runes[y][j] = c.runes[y][j], c.runes[y][i]
+				c.lines[y][i], c.lines[y][j] = c.lines[y][j], c.lines[y][i]
+			}
+		}
+		for x, d := range c.lines[y] {
+			if horizontal {
+				c.lines[y][x] = d&^(left|right) | d&left<<1 | d&right>>1
+			} else {
+				c.lines[y][x] = d&^(up|down) | d&up<<1 | d&down>>1
+			}
+		}
+		for x, r := range c.runes[y] {
+			if f, ok := flipped[r]; ok {
+				c.runes[y][x] = f
+			}
+		}
+	}
+}
+
+var flipped = map[rune]rune{'▶': '◀', '▼': '▲'}
+
+// box draws a frame using the given corner set and writes the label lines
+// centered inside of it.
+func (c *canvas) box(x, y, w int, label []string, corners [4]rune) {
+	h := len(label) + 2
+	c.set(x, y, corners[0])
+	c.set(x+w-1, y, corners[1])
+	c.set(x, y+h-1, corners[2])
+	c.set(x+w-1, y+h-1, corners[3])
+	for i := x + 1; i < x+w-1; i++ {
+		c.set(i, y, '─')
+		c.set(i, y+h-1, '─')
+	}
+	for i, l := range label {
+		c.set(x, y+1+i, '│')
+		for j := x + 1; j < x+w-1; j++ {
+			c.set(j, y+1+i, ' ')
+		}
+		c.set(x+w-1, y+1+i, '│')
+		lw := runewidth.StringWidth(l)
+		c.text(x+(w-lw)/2, y+1+i, l)
+	}
+}
+
+func (c *canvas) String() string {
+	var b strings.Builder
+	for y := range c.runes {
+		var line strings.Builder
+		for x, r := range c.runes[y] {
+			switch {
+			case r == wide:
+				continue
+			case r != 0:
+				line.WriteRune(r)
+			case c.lines[y][x] != 0:
+				line.WriteRune(junctions[c.lines[y][x]])
+			default:
+				line.WriteRune(' ')
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		if y < len(c.runes)-1 {
+			b.WriteRune('\n')
+		}
+	}
+	return b.String()
+}
+
+// labelWidth returns the display width of the widest line.
+func labelWidth(lines []string) int {
+	w := 0
+	for _, l := range lines {
+		w = max(w, runewidth.StringWidth(l))
+	}
+	return w
+}
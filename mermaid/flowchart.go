@@ -0,0 +1,487 @@
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type node struct {
+	id    string
+	label []string
+	shape [4]rune
+	layer int
+	order int
+	x, y  int
+	w, h  int
+}
+
+type edge struct {
+	from, to string
+	label    string
+	dotted   bool
+}
+
+var (
+	squareCorners  = [4]rune{'┌', '┐', '└', '┘'}
+	roundCorners   = [4]rune{'╭', '╮', '╰', '╯'}
+	diamondCorners = [4]rune{'╱', '╲', '╲', '╱'}
+)
+
+// shapes maps opening delimiters to their closing counterpart and corners.
+var shapes = []struct {
+	open, close string
+	corners     [4]rune
+}{
+	{"((", "))", roundCorners},
+	{"([", "])", roundCorners},
+	{"[(", ")]", roundCorners},
+	{"[[", "]]", squareCorners},
+	{"{{", "}}", diamondCorners},
+	{"[", "]", squareCorners},
+	{"(", ")", roundCorners},
+	{"{", "}", diamondCorners},
+	{">", "]", squareCorners},
+}
+
+var (
+	linkPattern = regexp.MustCompile(`\s*(?:(--|==|-\.)\s+([^|>]+?)\s+)?(<?-{2,}>|<?={2,}>|<?-\.+->|-{3,}|={3,}|-\.+-|--[xo]|==[xo])\s*(?:\|([^|]*)\|)?\s*`)
+	idPattern   = regexp.MustCompile(`^[\p{L}\p{N}_\-.:]+`)
+)
+
+type flowchart struct {
+	nodes map[string]*node
+	order []string
+	edges []edge
+}
+
+func (f *flowchart) node(spec string) *node {
+	spec = strings.TrimSpace(spec)
+	spec = strings.TrimSuffix(spec, ";")
+	id := idPattern.FindString(spec)
+	if id == "" {
+		return nil
+	}
+	rest := strings.TrimSpace(spec[len(id):])
+
+	n, ok := f.nodes[id]
+	if !ok {
+		n = &node{id: id, label: []string{id}, shape: squareCorners, order: len(f.order)}
+		f.nodes[id] = n
+		f.order = append(f.order, id)
+	}
+
+	// Strip class shorthand, e.g. A:::someclass
+	if i := strings.Index(rest, ":::"); i >= 0 {
+		rest = rest[:i]
+	}
+	for _, s := range shapes {
+		if strings.HasPrefix(rest, s.open) && strings.HasSuffix(rest, s.close) && len(rest) >= len(s.open)+len(s.close) {
+			n.label = cleanLabel(rest[len(s.open) : len(rest)-len(s.close)])
+			n.shape = s.corners
+			break
+		}
+	}
+	return n
+}
+
+// nodes returns all nodes of a "A & B" group.
+func (f *flowchart) group(spec string) []*node {
+	var nodes []*node
+	for _, part := range strings.Split(spec, "&") {
+		if n := f.node(part); n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+func parseFlowchart(lines []string) *flowchart {
+	f := &flowchart{nodes: map[string]*node{}}
+	for _, line := range lines {
+		for _, stmt := range strings.Split(line, ";") {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" || ignoredStatement(stmt) {
+				continue
+			}
+
+			links := linkPattern.FindAllStringSubmatchIndex(stmt, -1)
+			if len(links) == 0 {
+				f.group(stmt)
+				continue
+			}
+
+			prev := f.group(stmt[:links[0][0]])
+			for i, l := range links {
+				end := len(stmt)
+				if i+1 < len(links) {
+					end = links[i+1][0]
+				}
+				next := f.group(stmt[l[1]:end])
+
+				var label string
+				if l[4] >= 0 {
+					label = stmt[l[4]:l[5]]
+				}
+				if l[8] >= 0 {
+					label = stmt[l[8]:l[9]]
+				}
+				op := stmt[l[6]:l[7]]
+				for _, a := range prev {
+					for _, b := range next {
+						f.edges = append(f.edges, edge{
+							from:   a.id,
+							to:     b.id,
+							label:  strings.Join(cleanLabel(label), " "),
+							dotted: strings.Contains(op, "."),
+						})
+					}
+				}
+				prev = next
+			}
+		}
+	}
+	return f
+}
+
+func ignoredStatement(stmt string) bool {
+	word := strings.Fields(stmt)[0]
+	switch word {
+	case "subgraph", "end", "direction", "classDef", "class", "style", "linkStyle", "click":
+		return true
+	}
+	return false
+}
+
+// assignLayers places every node on the layer of its longest incoming path,
+// ignoring edges that would close a cycle.
+func (f *flowchart) assignLayers() [][]*node {
+	back := f.backEdges()
+	for i := 0; i < len(f.order); i++ {
+		changed := false
+		for j, e := range f.edges {
+			if back[j] {
+				continue
+			}
+			a, b := f.nodes[e.from], f.nodes[e.to]
+			if b.layer < a.layer+1 {
+				b.layer = a.layer + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	var layers [][]*node
+	for _, id := range f.order {
+		n := f.nodes[id]
+		for len(layers) <= n.layer {
+			layers = append(layers, nil)
+		}
+		layers[n.layer] = append(layers[n.layer], n)
+	}
+	for _, l := range layers {
+		sort.SliceStable(l, func(i, j int) bool { return l[i].order < l[j].order })
+	}
+	return layers
+}
+
+// backEdges returns the indexes of edges that point back to a node that is
+// still being visited in a depth-first walk, i.e. edges that close a cycle.
+func (f *flowchart) backEdges() map[int]bool {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	back := map[int]bool{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for i, e := range f.edges {
+			if e.from != id {
+				continue
+			}
+			switch state[e.to] {
+			case visiting:
+				back[i] = true
+			case unvisited:
+				visit(e.to)
+			}
+		}
+		state[id] = done
+	}
+	for _, id := range f.order {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return back
+}
+
+// bus is a set of edges between two adjacent layers joined on one line.
+type bus struct {
+	edges    []edge
+	min, max int
+	track    int
+}
+
+// buses groups edges between two adjacent layers into connected components
+// and assigns each a track so that overlapping components don't share a
+// line.
+func (f *flowchart) buses(edges []edge, pos func(*node, bool) int) ([]*bus, int) {
+	var groups []*bus
+	owner := map[string]*bus{}
+	for _, e := range edges {
+		ga, gb := owner["s"+e.from], owner["t"+e.to]
+		switch {
+		case ga == nil && gb == nil:
+			g := &bus{}
+			groups = append(groups, g)
+			ga = g
+		case ga == nil:
+			ga = gb
+		case gb != nil && ga != gb:
+			ga.edges = append(ga.edges, gb.edges...)
+			for k, v := range owner {
+				if v == gb {
+					owner[k] = ga
+				}
+			}
+			gb.edges = nil
+		}
+		ga.edges = append(ga.edges, e)
+		owner["s"+e.from], owner["t"+e.to] = ga, ga
+	}
+
+	var result []*bus
+	for _, g := range groups {
+		if len(g.edges) == 0 {
+			continue
+		}
+		g.min, g.max = 1<<31, -1
+		for _, e := range g.edges {
+			for _, p := range []int{pos(f.nodes[e.from], true), pos(f.nodes[e.to], false)} {
+				g.min, g.max = min(g.min, p), max(g.max, p)
+			}
+		}
+		result = append(result, g)
+	}
+
+	var tracks [][2]int
+	for _, g := range result {
+		g.track = -1
+		for t, r := range tracks {
+			if g.min > r[1]+1 || g.max < r[0]-1 {
+				g.track = t
+				tracks[t] = [2]int{min(r[0], g.min), max(r[1], g.max)}
+				break
+			}
+		}
+		if g.track < 0 {
+			g.track = len(tracks)
+			tracks = append(tracks, [2]int{g.min, g.max})
+		}
+	}
+	return result, len(tracks)
+}
+
+func renderFlowchart(dir string, lines []string) (string, error) {
+	f := parseFlowchart(lines)
+	if len(f.order) == 0 {
+		return "", fmt.Errorf("%w: empty flowchart", ErrUnsupported)
+	}
+	layers := f.assignLayers()
+	for _, n := range f.nodes {
+		n.w = labelWidth(n.label) + 4
+		n.h = len(n.label) + 2
+	}
+
+	// Split edges into those drawn between adjacent layers and the rest,
+	// which are listed below the diagram.
+	adjacent := make([][]edge, len(layers))
+	for _, e := range f.edges {
+		a, b := f.nodes[e.from], f.nodes[e.to]
+		if b.layer == a.layer+1 {
+			adjacent[a.layer] = append(adjacent[a.layer], e)
+		}
+	}
+
+	c := &canvas{}
+	horizontal := dir == "LR" || dir == "RL"
+	if horizontal {
+		layoutHorizontal(c, f, layers, adjacent)
+	} else {
+		layoutVertical(c, f, layers, adjacent)
+	}
+	if dir == "RL" || dir == "BT" {
+		reverse(c, f, horizontal)
+	}
+
+	for _, n := range f.nodes {
+		c.box(n.x, n.y, n.w, n.label, n.shape)
+	}
+
+	var b strings.Builder
+	b.WriteString(c.String())
+
+	var notes []string
+	for _, e := range f.edges {
+		a, z := f.nodes[e.from], f.nodes[e.to]
+		drawn := z.layer == a.layer+1
+		if drawn && e.label == "" {
+			continue
+		}
+		arrow := "──▶"
+		if e.dotted {
+			arrow = "╌╌▶"
+		}
+		if e.label != "" {
+			arrow = "── " + e.label + " ──▶"
+		}
+		notes = append(notes, fmt.Sprintf("%s %s %s", strings.Join(a.label, " "), arrow, strings.Join(z.label, " ")))
+	}
+	if len(notes) > 0 {
+		b.WriteString("\n\n" + strings.Join(notes, "\n"))
+	}
+	return b.String(), nil
+}
+
+const nodeGap = 3
+
+// layoutVertical stacks layers top to bottom, connecting them with
+// horizontal buses.
+func layoutVertical(c *canvas, f *flowchart, layers [][]*node, adjacent [][]edge) {
+	widest := 0
+	for _, l := range layers {
+		w := -nodeGap
+		for _, n := range l {
+			w += n.w + nodeGap
+		}
+		widest = max(widest, w)
+	}
+	for _, l := range layers {
+		w := -nodeGap
+		for _, n := range l {
+			w += n.w + nodeGap
+		}
+		x := (widest - w) / 2
+		for _, n := range l {
+			n.x = x
+			x += n.w + nodeGap
+		}
+	}
+
+	center := func(n *node, _ bool) int { return n.x + n.w/2 }
+	y := 0
+	for i, l := range layers {
+		h := 0
+		for _, n := range l {
+			n.y = y
+			h = max(h, n.h)
+		}
+		y += h
+		if i == len(layers)-1 {
+			break
+		}
+
+		buses, tracks := f.buses(adjacent[i], center)
+		if len(buses) == 0 {
+			y++
+			continue
+		}
+		arrowY := y + 1 + tracks
+		for _, g := range buses {
+			busY := y + 1 + g.track
+			for _, e := range g.edges {
+				a, b := f.nodes[e.from], f.nodes[e.to]
+				c.vline(center(a, true), a.y+a.h, busY)
+				c.vline(center(b, false), busY, arrowY)
+				c.set(center(b, false), arrowY, '▼')
+			}
+			c.hline(g.min, g.max, busY)
+		}
+		y = arrowY + 1
+	}
+}
+
+// reverse turns a laid out diagram around so that its layers run right to
+// left or bottom to top.
+func reverse(c *canvas, f *flowchart, horizontal bool) {
+	w, h := 0, len(c.runes)
+	for _, r := range c.runes {
+		w = max(w, len(r))
+	}
+	for _, n := range f.nodes {
+		w = max(w, n.x+n.w)
+		h = max(h, n.y+n.h)
+	}
+	c.flip(horizontal, w, h)
+	for _, n := range f.nodes {
+		if horizontal {
+			n.x = w - n.x - n.w
+		} else {
+			n.y = h - n.y - n.h
+		}
+	}
+}
+
+// layoutHorizontal places layers left to right, connecting them with
+// vertical buses.
+func layoutHorizontal(c *canvas, f *flowchart, layers [][]*node, adjacent [][]edge) {
+	tallest := 0
+	for _, l := range layers {
+		h := -1
+		for _, n := range l {
+			h += n.h + 1
+		}
+		tallest = max(tallest, h)
+	}
+	for _, l := range layers {
+		h := -1
+		for _, n := range l {
+			h += n.h + 1
+		}
+		y := (tallest - h) / 2
+		for _, n := range l {
+			n.y = y
+			y += n.h + 1
+		}
+	}
+
+	middle := func(n *node, _ bool) int { return n.y + n.h/2 }
+	x := 0
+	for i, l := range layers {
+		w := 0
+		for _, n := range l {
+			n.x = x
+			w = max(w, n.w)
+		}
+		if i == len(layers)-1 {
+			break
+		}
+
+		buses, tracks := f.buses(adjacent[i], middle)
+		if len(buses) == 0 {
+			x += w + nodeGap
+			continue
+		}
+		arrowX := x + w + 2 + tracks
+		for _, g := range buses {
+			busX := x + w + 1 + g.track
+			for _, e := range g.edges {
+				a, b := f.nodes[e.from], f.nodes[e.to]
+				c.hline(a.x+a.w, busX, middle(a, true))
+				c.hline(busX, arrowX, middle(b, false))
+				c.set(arrowX, middle(b, false), '▶')
+			}
+			c.vline(busX, g.min, g.max)
+		}
+		x = arrowX + 1
+	}
+}
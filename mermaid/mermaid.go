@@ -0,0 +1,154 @@
+// Package mermaid converts mermaid diagrams into Unicode box-drawing art
+// suitable for display in a terminal.
+package mermaid
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mode controls how fenced mermaid blocks are treated.
+type Mode string
+
+// Supported modes.
+const (
+	// ModeASCII renders diagrams as box-drawing art.
+	ModeASCII Mode = "ascii"
+	// ModeCode leaves diagrams untouched so they render as code.
+	ModeCode Mode = "code"
+	// ModeSkip removes diagrams from the document.
+	ModeSkip Mode = "skip"
+)
+
+// Modes lists all valid modes.
+var Modes = []Mode{ModeASCII, ModeCode, ModeSkip}
+
+// ParseMode validates a mode string.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid mermaid mode %q: use ascii, code or skip", s)
+}
+
+// ErrUnsupported is returned for diagram types that can't be rendered.
+var ErrUnsupported = errors.New("unsupported mermaid diagram")
+
+var commentPattern = regexp.MustCompile(`%%.*$`)
+
+// Render converts the source of a single mermaid diagram into text art.
+func Render(src string) (string, error) {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		l = strings.TrimSpace(commentPattern.ReplaceAllString(l, ""))
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) == 0 {
+		return "", ErrUnsupported
+	}
+
+	header := strings.Fields(lines[0])
+	switch header[0] {
+	case "graph", "flowchart":
+		dir := "TD"
+		if len(header) > 1 {
+			dir = strings.ToUpper(header[1])
+		}
+		return renderFlowchart(dir, lines[1:])
+	case "sequenceDiagram":
+		return renderSequence(lines[1:])
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupported, header[0])
+}
+
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+
+// Transform rewrites every fenced mermaid block in a markdown document
+// according to mode. Diagrams that can't be rendered are left as code.
+func Transform(markdown string, mode Mode) string {
+	if mode == ModeCode || !strings.Contains(markdown, "mermaid") {
+		return markdown
+	}
+
+	var (
+		out     strings.Builder
+		fence   string
+		mermaid bool
+		body    []string
+	)
+	lines := strings.SplitAfter(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		if fence == "" {
+			if m := fencePattern.FindStringSubmatch(trimmed); m != nil {
+				fence = m[1]
+				mermaid = m[2] == "mermaid"
+				body = []string{line}
+				if mermaid {
+					continue
+				}
+			}
+			out.WriteString(line)
+			continue
+		}
+
+		closing := strings.TrimSpace(trimmed)
+		isClose := strings.HasPrefix(closing, fence[:1]) &&
+			strings.Trim(closing, fence[:1]) == "" &&
+			len(closing) >= len(fence)
+		if !mermaid {
+			out.WriteString(line)
+			if isClose {
+				fence = ""
+			}
+			continue
+		}
+
+		body = append(body, line)
+		if !isClose && i < len(lines)-1 {
+			continue
+		}
+
+		fence = ""
+		if mode == ModeSkip {
+			continue
+		}
+		src := strings.Join(body[1:len(body)-1], "")
+		if !isClose {
+			src = strings.Join(body[1:], "")
+		}
+		art, err := Render(src)
+		if err != nil {
+			out.WriteString(strings.Join(body, ""))
+			continue
+		}
+		out.WriteString("```\n" + art + "\n```\n")
+	}
+
+	// An unterminated mermaid fence at the end of the document
+	if fence != "" && mermaid && mode != ModeSkip {
+		out.WriteString(strings.Join(body, ""))
+	}
+	return out.String()
+}
+
+// cleanLabel strips quotes and turns line breaks into separate lines.
+func cleanLabel(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.Trim(s, `"`)
+	for _, br := range []string{"<br/>", "<br />", "<br>", `\n`} {
+		s = strings.ReplaceAll(s, br, "\n")
+	}
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,111 @@
+package mermaid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFlowchart(t *testing.T) {
+	out, err := Render("graph LR\nA[one] --> B(two)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"┌─────┐    ╭─────╮\n" +
+		"│ one │───▶│ two │\n" +
+		"└─────┘    ╰─────╯"
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestRenderFlowchartReversed(t *testing.T) {
+	tt := []struct {
+		diagram  string
+		expected string
+	}{
+		{
+			"graph RL\nA[one] --> B(two)",
+			"" +
+				"╭─────╮    ┌─────┐\n" +
+				"│ two │◀───│ one │\n" +
+				"╰─────╯    └─────┘",
+		},
+		{
+			"graph BT\nA[one] --> B(two)",
+			"" +
+				"╭─────╮\n" +
+				"│ two │\n" +
+				"╰─────╯\n" +
+				"   ▲\n" +
+				"   │\n" +
+				"   │\n" +
+				"┌─────┐\n" +
+				"│ one │\n" +
+				"└─────┘",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.diagram[:8], func(t *testing.T) {
+			out, err := Render(tc.diagram)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestRenderSequence(t *testing.T) {
+	out, err := Render("sequenceDiagram\nAlice->>Bob: Hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Alice", "Bob", "Hi", "▶"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected output to contain %q, got:\n%s", s, out)
+		}
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	if _, err := Render("gantt\ntitle A Gantt Diagram"); err == nil {
+		t.Error("expected an error for unsupported diagram types")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	doc := "# Title\n\n```mermaid\ngraph TD\nA-->B\n```\n\ntext\n"
+
+	tt := []struct {
+		mode     Mode
+		contains string
+		missing  string
+	}{
+		{ModeCode, "```mermaid", ""},
+		{ModeSkip, "text", "graph TD"},
+		{ModeASCII, "▼", "```mermaid"},
+	}
+
+	for _, tc := range tt {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			out := Transform(doc, tc.mode)
+			if !strings.Contains(out, tc.contains) {
+				t.Errorf("expected output to contain %q, got:\n%s", tc.contains, out)
+			}
+			if tc.missing != "" && strings.Contains(out, tc.missing) {
+				t.Errorf("expected output not to contain %q, got:\n%s", tc.missing, out)
+			}
+		})
+	}
+}
+
+func TestTransformNestedFence(t *testing.T) {
+	doc := "````md\n```mermaid\ngraph TD\nA-->B\n```\n````\n"
+	if out := Transform(doc, ModeASCII); out != doc {
+		t.Errorf("expected nested fence to be left alone, got:\n%s", out)
+	}
+}
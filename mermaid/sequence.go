@@ -0,0 +1,278 @@
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+type participant struct {
+	id     string
+	label  []string
+	w      int
+	center int
+}
+
+type seqStep struct {
+	kind     string // message, note or block
+	from, to int
+	text     string
+	dashed   bool
+	head     rune
+	position string // left of, right of, over
+}
+
+var (
+	participantPattern = regexp.MustCompile(`^(?:participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+	messagePattern     = regexp.MustCompile(`^(.+?)\s*(-{1,2}>>|-{1,2}>|-{1,2}x|-{1,2}\))\s*([+-]?)(.+?)\s*:\s*(.*)$`)
+	notePattern        = regexp.MustCompile(`^(?i:note)\s+(left of|right of|over)\s+([^:]+):\s*(.*)$`)
+	blockPattern       = regexp.MustCompile(`^(loop|alt|else|opt|par|and|critical|break|rect|end)\b\s*(.*)$`)
+)
+
+type sequence struct {
+	participants []*participant
+	index        map[string]int
+	steps        []seqStep
+}
+
+func (s *sequence) participant(id string) int {
+	id = strings.TrimSpace(id)
+	if i, ok := s.index[id]; ok {
+		return i
+	}
+	s.index[id] = len(s.participants)
+	s.participants = append(s.participants, &participant{id: id, label: []string{id}})
+	return len(s.participants) - 1
+}
+
+func parseSequence(lines []string) *sequence {
+	s := &sequence{index: map[string]int{}}
+	for _, line := range lines {
+		if m := participantPattern.FindStringSubmatch(line); m != nil {
+			p := s.participants[s.participant(m[1])]
+			if m[2] != "" {
+				p.label = cleanLabel(m[2])
+			}
+			continue
+		}
+		if m := notePattern.FindStringSubmatch(line); m != nil {
+			ids := strings.Split(m[2], ",")
+			from := s.participant(ids[0])
+			to := from
+			if len(ids) > 1 {
+				to = s.participant(ids[1])
+			}
+			s.steps = append(s.steps, seqStep{
+				kind:     "note",
+				from:     min(from, to),
+				to:       max(from, to),
+				text:     strings.TrimSpace(m[3]),
+				position: strings.ToLower(m[1]),
+			})
+			continue
+		}
+		if m := blockPattern.FindStringSubmatch(line); m != nil {
+			text := m[1]
+			if m[2] != "" {
+				text += " " + m[2]
+			}
+			s.steps = append(s.steps, seqStep{kind: "block", text: text})
+			continue
+		}
+		if m := messagePattern.FindStringSubmatch(line); m != nil {
+			arrow := m[2]
+			head := '▶'
+			switch {
+			case strings.HasSuffix(arrow, "x"):
+				head = '×'
+			case strings.HasSuffix(arrow, ")"):
+				head = '▷'
+			case !strings.HasSuffix(arrow, ">>"):
+				head = '>'
+			}
+			s.steps = append(s.steps, seqStep{
+				kind:   "message",
+				from:   s.participant(m[1]),
+				to:     s.participant(m[4]),
+				text:   strings.TrimSpace(m[5]),
+				dashed: strings.HasPrefix(arrow, "--"),
+				head:   head,
+			})
+		}
+		// Anything else (activate, autonumber, ...) is ignored.
+	}
+	return s
+}
+
+// layout computes the center column of every participant, making sure
+// boxes don't overlap and message labels fit between lifelines.
+func (s *sequence) layout() {
+	for i, p := range s.participants {
+		p.w = labelWidth(p.label) + 4
+		if i == 0 {
+			p.center = p.w / 2
+			continue
+		}
+		prev := s.participants[i-1]
+		p.center = prev.center + (prev.w+p.w)/2 + nodeGap
+	}
+
+	shift := func(from, n int) {
+		for _, p := range s.participants[from:] {
+			p.center += n
+		}
+	}
+	for _, st := range s.steps {
+		if st.kind != "message" {
+			continue
+		}
+		w := runewidth.StringWidth(st.text) + 4
+		a, b := min(st.from, st.to), max(st.from, st.to)
+		if a == b {
+			// Self messages are drawn to the right of the lifeline.
+			if b+1 < len(s.participants) {
+				if d := s.participants[b].center + w + 3 - s.participants[b+1].center; d > 0 {
+					shift(b+1, d)
+				}
+			}
+			continue
+		}
+		if d := w - (s.participants[b].center - s.participants[a].center); d > 0 {
+			shift(b, d)
+		}
+	}
+}
+
+func renderSequence(lines []string) (string, error) {
+	s := parseSequence(lines)
+	if len(s.participants) == 0 {
+		return "", fmt.Errorf("%w: empty sequence diagram", ErrUnsupported)
+	}
+	s.layout()
+
+	last := s.participants[len(s.participants)-1]
+	width := last.center + last.w/2 + 1
+	for _, st := range s.steps {
+		if st.kind == "message" && st.from == st.to {
+			width = max(width, s.participants[st.from].center+runewidth.StringWidth(st.text)+6)
+		}
+	}
+
+	c := &canvas{}
+	header := func(y int) int {
+		h := 0
+		for _, p := range s.participants {
+			c.box(p.center-p.w/2, y, p.w, p.label, squareCorners)
+			h = max(h, len(p.label)+2)
+		}
+		return h
+	}
+
+	top := header(0)
+	y := top
+	for _, st := range s.steps {
+		switch st.kind {
+		case "message":
+			y = s.drawMessage(c, st, y)
+		case "note":
+			y = s.drawNote(c, st, y)
+		case "block":
+			label := "╌ " + st.text + " "
+			c.text(0, y, label)
+			for x := runewidth.StringWidth(label); x < width; x++ {
+				c.set(x, y, '╌')
+			}
+			y++
+		}
+	}
+
+	for _, p := range s.participants {
+		c.vline(p.center, top, y)
+		c.set(p.center, top-1, '┬')
+	}
+	header(y + 1)
+	for _, p := range s.participants {
+		c.set(p.center, y+1, '┴')
+	}
+	return c.String(), nil
+}
+
+func (s *sequence) drawMessage(c *canvas, st seqStep, y int) int {
+	from, to := s.participants[st.from], s.participants[st.to]
+
+	if st.from == st.to {
+		c.text(from.center+2, y, st.text)
+		c.hline(from.center, from.center+3, y+1)
+		c.vline(from.center+3, y+1, y+2)
+		c.hline(from.center, from.center+3, y+2)
+		c.set(from.center+1, y+2, '◀')
+		return y + 3
+	}
+
+	x1, x2 := from.center, to.center
+	lw := runewidth.StringWidth(st.text)
+	c.text(min(x1, x2)+(abs(x2-x1)-lw)/2+1, y, st.text)
+
+	step := 1
+	if x2 < x1 {
+		step = -1
+	}
+	for x := x1 + step; x != x2-step; x += step {
+		if st.dashed {
+			c.set(x, y+1, '╌')
+		} else {
+			c.mark(x, y+1, left|right)
+		}
+	}
+	c.mark(x1, y+1, dirOf(step))
+
+	head := st.head
+	if step < 0 {
+		switch head {
+		case '▶':
+			head = '◀'
+		case '▷':
+			head = '◁'
+		case '>':
+			head = '<'
+		}
+	}
+	c.set(x2-step, y+1, head)
+	return y + 2
+}
+
+func (s *sequence) drawNote(c *canvas, st seqStep, y int) int {
+	label := cleanLabel(st.text)
+	w := labelWidth(label) + 4
+	from, to := s.participants[st.from], s.participants[st.to]
+
+	var x int
+	switch st.position {
+	case "left of":
+		x = from.center - w - 1
+	case "right of":
+		x = from.center + 2
+	default:
+		span := to.center - from.center
+		w = max(w, span+4)
+		x = from.center + span/2 - w/2
+	}
+	c.box(max(0, x), y, w, label, squareCorners)
+	return y + len(label) + 2
+}
+
+func dirOf(step int) uint8 {
+	if step > 0 {
+		return right
+	}
+	return left
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/douglas-larocca/glow/v2/frontmatter"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metaField string
+
+	metaCmd = &cobra.Command{
+		Use:   "meta SOURCE",
+		Short: "Print the frontmatter of a document",
+		Long: paragraph(fmt.Sprintf("\n%s the YAML frontmatter of a document. Use --get to print a single field, for use in scripts; nested fields are separated by dots.",
+			keyword("Print"))),
+		Example:           paragraph("glow meta README.md\nglow meta post.md --get title\nglow meta post.md --get author.name"),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMarkdownFiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := sourceFromArg(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			defer src.reader.Close() //nolint:errcheck
+			content, err := io.ReadAll(src.reader)
+			if err != nil {
+				return fmt.Errorf("unable to read from reader: %w", err)
+			}
+
+			front, _ := utils.SplitFrontmatter(content)
+			if front == nil {
+				return errors.New("document has no frontmatter")
+			}
+
+			if metaField == "" {
+				_, err = cmd.OutOrStdout().Write(front)
+				return err //nolint:wrapcheck
+			}
+			value, err := frontmatter.Get(front, metaField)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), value)
+			return err //nolint:wrapcheck
+		},
+	}
+)
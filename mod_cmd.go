@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var modCmd = &cobra.Command{
+	Use:   "mod MODULE[@VERSION]",
+	Short: "Render the README of a Go module",
+	Long: paragraph(fmt.Sprintf("\n%s the README of a Go module, downloaded from the module proxy named by GOPROXY, or proxy.golang.org. The latest version is rendered unless one is given. Modules are cached like other downloads.",
+		keyword("Render"))),
+	Example: paragraph("glow mod github.com/pkg/errors\nglow mod github.com/pkg/errors@v0.9.1"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		module, version := splitVersion(args[0])
+		src, err := newGoProxy().readme(cmd.Context(), module, version)
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		return executeCLI(cmd, src, cmd.OutOrStdout())
+	},
+}
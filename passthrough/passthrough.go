@@ -0,0 +1,212 @@
+// Package passthrough shows terminal output embedded in markdown, like
+// colored test logs, with its original colors: fenced code blocks with the
+// info string "ansi" keep their ANSI escapes instead of being rendered as
+// plain code.
+package passthrough
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/douglas-larocca/glow/v2/fences"
+	"github.com/douglas-larocca/glow/v2/placeholder"
+)
+
+// Mode is which fenced code blocks keep their ANSI escapes.
+type Mode string
+
+// Modes of passing ANSI escapes through.
+const (
+	// ModeFence passes the escapes of ```ansi fences through.
+	ModeFence Mode = "fence"
+	// ModeAll passes the escapes of any fenced code block containing them
+	// through.
+	ModeAll Mode = "all"
+	// ModeOff shows ```ansi fences as plain code, without their escapes.
+	ModeOff Mode = "off"
+)
+
+var modes = []Mode{ModeFence, ModeAll, ModeOff}
+
+// ParseMode returns the mode of the given name.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("invalid raw ANSI mode %q, expected one of: %s", s, strings.Join(names, ", "))
+}
+
+const reset = "\x1b[0m"
+
+var marker = placeholder.New("GLOWANSI")
+
+var sgrPattern = regexp.MustCompile(`\x1b\[([0-9;:]*)m`)
+
+// Extract takes the fenced code blocks whose escapes are passed through out
+// of a markdown document, leaving a placeholder paragraph for each that
+// Render replaces with the content of the block. Blocks are returned without
+// their fences. With ModeOff, the escapes are removed from ```ansi fences,
+// which are left in place.
+func Extract(markdown string, mode Mode) (string, []string) {
+	var blocks []string
+	md := fences.Replace(markdown, func(b fences.Block) string {
+		isANSI := strings.EqualFold(b.Language, "ansi")
+		switch {
+		case mode == ModeOff && isANSI:
+			return b.Open + xansi.Strip(b.Body) + b.Close
+		case isANSI, mode == ModeAll && strings.Contains(b.Body, "\x1b"):
+			blocks = append(blocks, strings.TrimSuffix(b.Body, "\n"))
+			return marker.Paragraph(len(blocks) - 1)
+		}
+		return b.String()
+	})
+	return md, blocks
+}
+
+// Options configure how blocks are rendered.
+type Options struct {
+	// Width the document is rendered at. Lines of blocks are wrapped to fit
+	// within its margins, unless it's zero.
+	Width int
+	// Style of the document, for the margins of code blocks.
+	Style ansi.StyleConfig
+	// Plain removes the escapes, for output without colors.
+	Plain bool
+}
+
+// Render replaces the placeholders left by Extract in rendered output with
+// the blocks, indented like code blocks.
+func Render(rendered string, blocks []string, opts Options) string {
+	if len(blocks) == 0 {
+		return rendered
+	}
+
+	margin := 0
+	if opts.Style.CodeBlock.Margin != nil {
+		margin = int(*opts.Style.CodeBlock.Margin) //nolint:gosec
+	}
+	prefix := strings.Repeat(" ", margin)
+	return marker.Replace(rendered, len(blocks), func(n, indent int) string {
+		width := 0
+		if opts.Width > 0 {
+			width = opts.Width - indent - margin
+			if opts.Style.Document.Margin != nil {
+				width -= int(*opts.Style.Document.Margin) //nolint:gosec
+			}
+			width = max(width, 10)
+		}
+		return prefix + strings.Join(blockLines(blocks[n], width, opts.Plain), "\n"+prefix)
+	})
+}
+
+// blockLines sanitizes and wraps the lines of a block. Colors still set at
+// the end of a line are reset, and set again on the next, so that every line
+// can be drawn on its own, as the pager does.
+func blockLines(block string, width int, plain bool) []string {
+	var (
+		out    []string
+		active string
+	)
+	for _, l := range strings.Split(block, "\n") {
+		l = sanitize(strings.TrimSuffix(l, "\r"))
+		if plain {
+			l = xansi.Strip(l)
+		}
+		if width > 0 {
+			l = xansi.Hardwrap(l, width, true)
+		}
+		for _, w := range strings.Split(l, "\n") {
+			line := active + w
+			if active = carry(active, w); active != "" {
+				line += reset
+			}
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// carry returns the SGR sequences still in effect after a line, given the
+// ones in effect before it.
+func carry(active, line string) string {
+	for _, m := range sgrPattern.FindAllStringSubmatch(line, -1) {
+		switch {
+		case m[1] == "" || m[1] == "0":
+			active = ""
+		case strings.HasPrefix(m[1], "0;"):
+			active = m[0]
+		default:
+			active += m[0]
+		}
+	}
+	return active
+}
+
+// sanitize keeps the SGR sequences setting colors and text styles, and OSC 8
+// hyperlinks, and removes other escape sequences and control characters,
+// which would move the cursor and break the layout of the document. Tabs are
+// expanded to four spaces.
+func sanitize(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\x1b':
+			n, keep := escapeSequence(s[i:])
+			if keep {
+				b.WriteString(s[i : i+n])
+			}
+			i += n
+		case c == '\t':
+			b.WriteString("    ")
+			i++
+		case c < 0x20 || c == 0x7f:
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// escapeSequence returns the length of the escape sequence s starts with, and
+// whether it's kept.
+func escapeSequence(s string) (int, bool) {
+	if len(s) < 2 {
+		return len(s), false
+	}
+	switch s[1] {
+	case '[':
+		// Control sequence: parameters, intermediate bytes and a final byte.
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1, s[i] == 'm'
+			}
+			if s[i] < 0x20 || s[i] > 0x3f {
+				return i, false
+			}
+		}
+		return len(s), false
+	case ']', 'P', 'X', '^', '_':
+		// String terminated by BEL or ST.
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\a':
+				return i + 1, s[1] == ']' && strings.HasPrefix(s[2:], "8;")
+			case s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\':
+				return i + 2, s[1] == ']' && strings.HasPrefix(s[2:], "8;")
+			}
+		}
+		return len(s), false
+	}
+	return 2, false
+}
@@ -0,0 +1,82 @@
+package passthrough
+
+import (
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const doc = "# Log\n\n" +
+	"```ansi\n\x1b[32mok\x1b[0m  pkg/a\n\x1b[31mFAIL pkg/b\n--- test\x1b[0m\n```\n\n" +
+	"```sh\necho \x1b[1mbold\x1b[0m\n```\n"
+
+func TestExtract(t *testing.T) {
+	out, blocks := Extract(doc, ModeFence)
+	if len(blocks) != 1 || blocks[0] != "\x1b[32mok\x1b[0m  pkg/a\n\x1b[31mFAIL pkg/b\n--- test\x1b[0m" {
+		t.Fatalf("expected the ansi fence, got %q", blocks)
+	}
+	for _, s := range []string{marker.Text(0), "```sh\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in %q", s, out)
+		}
+	}
+
+	if _, blocks := Extract(doc, ModeAll); len(blocks) != 2 {
+		t.Errorf("expected every fence with escapes, got %q", blocks)
+	}
+
+	out, blocks = Extract(doc, ModeOff)
+	if len(blocks) != 0 {
+		t.Errorf("expected no blocks, got %q", blocks)
+	}
+	if !strings.Contains(out, "```ansi\nok  pkg/a\n") || !strings.Contains(out, "\x1b[1mbold") {
+		t.Errorf("expected the escapes of the ansi fence to be removed, got %q", out)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"fence", "all", "off"} {
+		if m, err := ParseMode(s); err != nil || string(m) != s {
+			t.Errorf("expected mode %q, got %q (%v)", s, m, err)
+		}
+	}
+	if _, err := ParseMode("raw"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestRender(t *testing.T) {
+	_, blocks := Extract(doc, ModeFence)
+	out := Render("  # Log\n\n  "+marker.Text(0)+"  \n", blocks, Options{})
+	lines := strings.Split(out, "\n")
+	want := []string{
+		"  # Log",
+		"",
+		"  \x1b[32mok\x1b[0m  pkg/a",
+		"  \x1b[31mFAIL pkg/b\x1b[0m",
+		"  \x1b[31m--- test\x1b[0m",
+		"",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected colors to be carried over lines, got %q", lines)
+	}
+
+	plain := Render(marker.Text(0), blocks, Options{Plain: true})
+	if plain != xansi.Strip(plain) || !strings.HasPrefix(plain, "ok  pkg/a\n") {
+		t.Errorf("expected plain text, got %q", plain)
+	}
+
+	wrapped := Render(marker.Text(0), []string{strings.Repeat("x", 25)}, Options{Width: 10})
+	if wrapped != "xxxxxxxxxx\nxxxxxxxxxx\nxxxxx" {
+		t.Errorf("expected lines wrapped at the width, got %q", wrapped)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	in := "\x1b[2J\x1b[H\x1b]8;;https://example.com\x07link\x1b]8;;\x07 \x1b]0;title\x07\x1b[1mbold\x1b[0m\tend\a"
+	want := "\x1b]8;;https://example.com\x07link\x1b]8;;\x07 \x1b[1mbold\x1b[0m    end"
+	if got := sanitize(in); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
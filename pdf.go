@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/pdf"
+)
+
+// isPDF reports whether output written to path is exported as PDF.
+func isPDF(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pdf")
+}
+
+// writePDF lays out the rendered output as PDF and writes it to path.
+func writePDF(path, out string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create output file: %w", err)
+	}
+	opts := pdf.Options{
+		Columns: int(width), //nolint:gosec
+		Title:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+	if err := pdf.Write(f, out, opts); err != nil {
+		_ = f.Close()
+		return err //nolint:wrapcheck
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write output file: %w", err)
+	}
+	return nil
+}
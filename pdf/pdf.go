@@ -0,0 +1,265 @@
+// Package pdf lays out documents rendered for the terminal as PDF, keeping
+// their colors and text attributes.
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/go-pdf/fpdf"
+	"github.com/muesli/termenv"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+)
+
+const (
+	family      = "mono"
+	margin      = 15.0 // mm
+	maxFontSize = 10.0 // pt
+	lineSpacing = 1.25
+	ptToMM      = 25.4 / 72
+)
+
+// Go Mono covers the box-drawing characters tables are drawn with.
+var fonts = map[string][]byte{
+	"":   gomono.TTF,
+	"B":  gomonobold.TTF,
+	"I":  gomonoitalic.TTF,
+	"BI": gomonobolditalic.TTF,
+}
+
+// Options configure the layout of a document.
+type Options struct {
+	// Number of columns the page is at least wide enough for. The font is
+	// sized so that this many columns, or the widest line, fit the page.
+	Columns int
+
+	// Title stored in the document's metadata.
+	Title string
+}
+
+// Write lays out a document on A4 pages in a monospaced font. The document
+// may contain ANSI escape sequences; colors and text attributes are kept,
+// other sequences are dropped.
+func Write(w io.Writer, doc string, opts Options) error {
+	doc = strings.ReplaceAll(doc, "\t", "    ")
+	lines := strings.Split(strings.TrimRight(doc, "\n"), "\n")
+	cols := max(opts.Columns, 1)
+	for _, l := range lines {
+		cols = max(cols, utils.Width(l))
+	}
+
+	f := fpdf.New("P", "mm", "A4", "")
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(false, 0)
+	f.SetCellMargin(0)
+	f.SetCreator("glow", false)
+	if opts.Title != "" {
+		f.SetTitle(opts.Title, true)
+	}
+	for style, ttf := range fonts {
+		f.AddUTF8FontFromBytes(family, style, ttf)
+	}
+
+	pageWidth, pageHeight := f.GetPageSize()
+	size := maxFontSize
+	f.SetFont(family, "", size)
+	if width := f.GetStringWidth("0") * float64(cols); width > pageWidth-2*margin {
+		size *= (pageWidth - 2*margin) / width
+		f.SetFont(family, "", size)
+	}
+	cell := f.GetStringWidth("0")
+	lineHeight := size * lineSpacing * ptToMM
+
+	var a attrs
+	y := pageHeight // start a page with the first line
+	for _, line := range lines {
+		if y+lineHeight > pageHeight-margin {
+			f.AddPage()
+			y = margin
+		}
+		x := margin
+		for _, s := range a.spans(line) {
+			width := cell * float64(utils.Width(s.text))
+			fg, bg := s.colors()
+			f.SetFont(family, s.fontStyle(), size)
+			f.SetTextColor(fg[0], fg[1], fg[2])
+			if bg != nil {
+				f.SetFillColor(bg[0], bg[1], bg[2])
+			}
+			f.SetXY(x, y)
+			f.CellFormat(width, lineHeight, s.text, "", 0, "L", bg != nil, 0, "")
+			x += width
+		}
+		y += lineHeight
+	}
+
+	if err := f.Output(w); err != nil {
+		return fmt.Errorf("unable to write pdf: %w", err)
+	}
+	return nil
+}
+
+// attrs are the text attributes set by SGR sequences.
+type attrs struct {
+	fg, bg                                  termenv.Color // nil for the default
+	bold, faint, italic, underline, reverse bool
+}
+
+// span is a run of text with the same attributes.
+type span struct {
+	attrs
+	text string
+}
+
+var escapeSequence = regexp.MustCompile(`\x1b(?:\[([0-9;:?]*)[ -/]*([@-~])|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// spans splits a line into runs of text, applying the SGR sequences in it
+// to a. Attributes carry over to the following lines.
+func (a *attrs) spans(line string) []span {
+	var out []span
+	add := func(text string) {
+		if text != "" {
+			out = append(out, span{attrs: *a, text: text})
+		}
+	}
+	last := 0
+	for _, m := range escapeSequence.FindAllStringSubmatchIndex(line, -1) {
+		add(line[last:m[0]])
+		last = m[1]
+		if m[4] >= 0 && line[m[4]:m[5]] == "m" {
+			a.sgr(line[m[2]:m[3]])
+		}
+	}
+	add(line[last:])
+	return out
+}
+
+// sgr applies the parameters of an SGR sequence.
+func (a *attrs) sgr(params string) {
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		n, err := strconv.Atoi(p[i])
+		if p[i] != "" && err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			*a = attrs{}
+		case n == 1:
+			a.bold = true
+		case n == 2:
+			a.faint = true
+		case n == 3:
+			a.italic = true
+		case n == 4:
+			a.underline = true
+		case n == 7:
+			a.reverse = true
+		case n == 22:
+			a.bold, a.faint = false, false
+		case n == 23:
+			a.italic = false
+		case n == 24:
+			a.underline = false
+		case n == 27:
+			a.reverse = false
+		case n >= 30 && n <= 37:
+			a.fg = termenv.ANSIColor(n - 30)
+		case n >= 90 && n <= 97:
+			a.fg = termenv.ANSIColor(n - 90 + 8)
+		case n == 39:
+			a.fg = nil
+		case n >= 40 && n <= 47:
+			a.bg = termenv.ANSIColor(n - 40)
+		case n >= 100 && n <= 107:
+			a.bg = termenv.ANSIColor(n - 100 + 8)
+		case n == 49:
+			a.bg = nil
+		case n == 38 || n == 48:
+			c, skip := extendedColor(p[i+1:])
+			i += skip
+			if n == 38 {
+				a.fg = c
+			} else {
+				a.bg = c
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments of a 256 color or true color
+// parameter. It returns the color and the number of arguments used.
+func extendedColor(p []string) (termenv.Color, int) {
+	arg := func(i int) int {
+		if i >= len(p) {
+			return 0
+		}
+		n, _ := strconv.Atoi(p[i])
+		return min(max(n, 0), 255)
+	}
+	switch {
+	case len(p) >= 2 && p[0] == "5":
+		return termenv.ANSI256Color(arg(1)), 2
+	case len(p) >= 4 && p[0] == "2":
+		return termenv.RGBColor(fmt.Sprintf("#%02x%02x%02x", arg(1), arg(2), arg(3))), 4
+	}
+	return nil, len(p)
+}
+
+// colors returns the text color and the background color of a span. The
+// background is nil if the page shows through.
+func (a attrs) colors() ([3]int, *[3]int) {
+	fg, bg := a.fg, a.bg
+	if a.reverse {
+		fg, bg = bg, fg
+		if bg == nil {
+			bg = termenv.RGBColor("#000000")
+		}
+		if fg == nil {
+			fg = termenv.RGBColor("#ffffff")
+		}
+	}
+
+	text := [3]int{0, 0, 0}
+	if fg != nil {
+		text = rgb(fg)
+	}
+	if a.faint {
+		// Fade towards the page.
+		for i := range text {
+			text[i] = (text[i] + 255) / 2
+		}
+	}
+	if bg == nil {
+		return text, nil
+	}
+	back := rgb(bg)
+	return text, &back
+}
+
+func rgb(c termenv.Color) [3]int {
+	r, g, b := termenv.ConvertToRGB(c).RGB255()
+	return [3]int{int(r), int(g), int(b)}
+}
+
+// fontStyle returns the fpdf style string of a span.
+func (a attrs) fontStyle() string {
+	var s string
+	if a.bold {
+		s += "B"
+	}
+	if a.italic {
+		s += "I"
+	}
+	if a.underline {
+		s += "U"
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestSpans(t *testing.T) {
+	var a attrs
+	got := a.spans("plain \x1b[1;38;2;255;0;135mbold\x1b[22m pink\x1b]8;;https://example.com\x07 \x1b[0;48;5;236;3mcode")
+	want := []span{
+		{text: "plain "},
+		{attrs: attrs{bold: true, fg: termenv.RGBColor("#ff0087")}, text: "bold"},
+		{attrs: attrs{fg: termenv.RGBColor("#ff0087")}, text: " pink"},
+		{attrs: attrs{fg: termenv.RGBColor("#ff0087")}, text: " "},
+		{attrs: attrs{italic: true, bg: termenv.ANSI256Color(236)}, text: "code"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d spans, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("span %d: expected %+v, got %+v", i+1, want[i], got[i])
+		}
+	}
+
+	// Attributes carry over to the next line.
+	if got := a.spans("more"); len(got) != 1 || !got[0].italic {
+		t.Errorf("expected attributes to carry over, got %+v", got)
+	}
+}
+
+func TestColors(t *testing.T) {
+	fg, bg := attrs{fg: termenv.ANSIColor(1), faint: true}.colors()
+	if fg != [3]int{191, 127, 127} || bg != nil {
+		t.Errorf("expected faint maroon on the page, got %v and %v", fg, bg)
+	}
+	fg, bg = attrs{reverse: true}.colors()
+	if fg != [3]int{255, 255, 255} || bg == nil || *bg != [3]int{0, 0, 0} {
+		t.Errorf("expected white on black, got %v and %v", fg, bg)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	pages := func(doc string) int {
+		var b bytes.Buffer
+		if err := Write(&b, doc, Options{Columns: 80, Title: "test"}); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(b.Bytes(), []byte("%PDF-")) {
+			t.Fatalf("expected a PDF, got %q", b.String()[:min(b.Len(), 20)])
+		}
+		return bytes.Count(b.Bytes(), []byte("/Type /Page\n"))
+	}
+
+	if n := pages("\x1b[1m# Title\x1b[0m\n\n  │ a │ b │\n  ├───┼───┤\n"); n != 1 {
+		t.Errorf("expected 1 page, got %d", n)
+	}
+	if n := pages(strings.Repeat("line\n", 100)); n != 2 {
+		t.Errorf("expected 2 pages, got %d", n)
+	}
+	if n := pages(strings.Repeat("wide", 100)); n != 1 {
+		t.Errorf("expected a long line to be shrunk onto 1 page, got %d", n)
+	}
+}
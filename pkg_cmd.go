@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var pkgCmd = &cobra.Command{
+	Use:   "pkg REGISTRY:PACKAGE[@VERSION]",
+	Short: "Render the README of a package",
+	Long: paragraph(fmt.Sprintf("\n%s the README of a package of npm, PyPI or Go, named with the registry as a prefix. The latest version is rendered unless one is given. Descriptions of Python packages written in reStructuredText or plain text are shown as they are.",
+		keyword("Render"))),
+	Example: paragraph("glow pkg npm:left-pad\nglow pkg npm:@types/node@20.1.0\nglow pkg pypi:requests\nglow pkg go:github.com/pkg/errors@v0.9.1"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, pkg, ok := strings.Cut(args[0], ":")
+		if !ok || pkg == "" {
+			return fmt.Errorf("%s must name a registry, one of: %s", args[0], strings.Join(registryNames, ", "))
+		}
+		reg, ok := newRegistry(name)
+		if !ok {
+			return fmt.Errorf("unknown registry %q, must be one of: %s", name, strings.Join(registryNames, ", "))
+		}
+		pkg, version := splitVersion(pkg)
+		src, err := reg.readme(cmd.Context(), pkg, version)
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		return executeCLI(cmd, src, cmd.OutOrStdout())
+	},
+}
@@ -0,0 +1,64 @@
+// Package placeholder stands in for blocks taken out of markdown documents
+// to be drawn apart from them, like tables or callouts: each block is left
+// as a word glamour renders as it is, which is then replaced with the drawn
+// block in the rendered document.
+package placeholder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// Marker names the placeholders of a kind of block, e.g. GLOWTABLE for
+// GLOWTABLE0X, GLOWTABLE1X and so on.
+type Marker struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// New returns the marker of placeholders named name, which must be a word
+// markdown leaves as it is.
+func New(name string) Marker {
+	return Marker{name: name, pattern: regexp.MustCompile(name + `(\d+)X`)}
+}
+
+// Text returns the placeholder of the nth block.
+func (m Marker) Text(n int) string {
+	return fmt.Sprintf("%s%dX", m.name, n)
+}
+
+// Paragraph returns the placeholder of the nth block as a paragraph of its
+// own, to put in place of the lines of the block.
+func (m Marker) Paragraph(n int) string {
+	return "\n" + m.Text(n) + "\n\n"
+}
+
+// Replace replaces the placeholders of the first count blocks in rendered
+// output with the blocks drawn by draw, given their index and the width of
+// the indent of their placeholder. Blocks are indented as their
+// placeholder.
+func (m Marker) Replace(rendered string, count int, draw func(n, indent int) string) string {
+	if count == 0 {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		plain := xansi.Strip(line)
+		loc := m.pattern.FindStringSubmatchIndex(plain)
+		if loc == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(plain[loc[2]:loc[3]])
+		if n >= count {
+			continue
+		}
+		indent := xansi.StringWidth(plain[:loc[0]])
+		prefix := strings.Repeat(" ", indent)
+		lines[i] = prefix + strings.ReplaceAll(draw(n, indent), "\n", "\n"+prefix)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,21 @@
+package placeholder
+
+import "testing"
+
+func TestReplace(t *testing.T) {
+	m := New("GLOWTEST")
+	if got := m.Paragraph(1); got != "\nGLOWTEST1X\n\n" {
+		t.Errorf("unexpected paragraph %q", got)
+	}
+
+	rendered := "  Before\n  \x1b[1m" + m.Text(0) + "\x1b[0m  \n" + m.Text(1) + "\n" + m.Text(2)
+	got := m.Replace(rendered, 2, func(n, indent int) string {
+		if n == 0 && indent != 2 {
+			t.Errorf("expected an indent of 2, got %d", indent)
+		}
+		return []string{"a\nb", "c"}[n]
+	})
+	if want := "  Before\n  a\n  b\nc\n" + m.Text(2); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,126 @@
+// Package positions remembers how far documents have been read.
+package positions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the positions file in the data directory.
+const FileName = "positions.json"
+
+// maxPositions is the number of documents positions are kept for. The least
+// recently read documents are forgotten first.
+const maxPositions = 1000
+
+// Position is the first line shown of a rendered document.
+type Position struct {
+	Line int `json:"line"`
+	// Number of lines the document had when rendered. Used to scale the
+	// position when the document is rendered at another width.
+	Lines   int       `json:"lines"`
+	Updated time.Time `json:"updated"`
+}
+
+// LineIn returns the position in a rendering of the document with the given
+// number of lines.
+func (p Position) LineIn(lines int) int {
+	if p.Lines <= 0 || lines == p.Lines {
+		return p.Line
+	}
+	return p.Line * lines / p.Lines
+}
+
+// Store is a set of positions persisted to a JSON file, keyed by a hash of
+// the documents' absolute paths. It's safe for concurrent use.
+type Store struct {
+	path string
+
+	mu        sync.Mutex
+	positions map[string]Position
+}
+
+// Load reads the positions stored at path. A missing file is treated as an
+// empty set.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, positions: map[string]Position{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read positions: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.positions); err != nil {
+		return nil, fmt.Errorf("unable to parse positions: %w", err)
+	}
+	return s, nil
+}
+
+// key returns the key a document is stored under.
+func key(doc string) string {
+	if abs, err := filepath.Abs(doc); err == nil {
+		doc = abs
+	}
+	sum := sha256.Sum256([]byte(doc))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Get returns the position of a document.
+func (s *Store) Get(doc string) (Position, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.positions[key(doc)]
+	return p, ok
+}
+
+// Set records the position of a document and saves the store. Documents
+// read to the top are forgotten.
+func (s *Store) Set(doc string, line, lines int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(doc)
+	if line <= 0 {
+		if _, ok := s.positions[k]; !ok {
+			return nil
+		}
+		delete(s.positions, k)
+		return s.save()
+	}
+
+	s.positions[k] = Position{Line: line, Lines: lines, Updated: time.Now()}
+	if n := len(s.positions) - maxPositions; n > 0 {
+		keys := slices.SortedFunc(maps.Keys(s.positions), func(a, b string) int {
+			return s.positions[a].Updated.Compare(s.positions[b].Updated)
+		})
+		for _, k := range keys[:n] {
+			delete(s.positions, k)
+		}
+	}
+	return s.save()
+}
+
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.positions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode positions: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, b, 0o600); err != nil {
+		return fmt.Errorf("unable to write positions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package positions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", FileName)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("/docs/README.md", 40, 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("/docs/CHANGELOG.md", 10, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("/docs/CHANGELOG.md", 0, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := s.Get("/docs/README.md")
+	if !ok {
+		t.Fatal("expected a position")
+	}
+	if p.LineIn(200) != 40 || p.LineIn(100) != 20 {
+		t.Errorf("unexpected position %+v", p)
+	}
+	if _, ok := s.Get("/docs/CHANGELOG.md"); ok {
+		t.Error("expected the position at the top to be forgotten")
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/slides"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxSlideWidth is the widest slides are rendered, so that text doesn't
+// stretch across wide terminals.
+const maxSlideWidth = 100
+
+var (
+	presentAuto time.Duration
+
+	presentCmd = &cobra.Command{
+		Use:   "present SOURCE",
+		Short: "Show a document as a slideshow",
+		Long: paragraph(fmt.Sprintf("\n%s a document one slide at a time, full-screen. Slides are separated by lines of three dashes, or start at every level 1 or 2 heading if there are none. Use the arrow keys, space or h/l to move between slides, g/G to jump to the first or last one, and q to quit. --auto moves on to the next slide after the given time.",
+			keyword("Present"))),
+		Example:           paragraph("glow present talk.md\nglow present --auto 30s talk.md"),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMarkdownFiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return errors.New("presenting needs a terminal")
+			}
+			if !termProfile.CursorMovement {
+				return errors.New("presenting needs a terminal that can move the cursor")
+			}
+			src, err := sourceFromArg(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			content, err := io.ReadAll(src.reader)
+			_ = src.reader.Close()
+			if err != nil {
+				return fmt.Errorf("unable to read from reader: %w", err)
+			}
+
+			p := &presenter{
+				src:      src,
+				slides:   slides.Split(prepareMarkdown(src, content)),
+				rendered: map[int]string{},
+			}
+			if len(p.slides) == 0 {
+				return errors.New("document has no slides")
+			}
+			return p.run()
+		},
+	}
+)
+
+// presentKey is what a key does in a presentation.
+type presentKey int
+
+const (
+	presentNone presentKey = iota
+	presentNext
+	presentPrev
+	presentFirst
+	presentLast
+	presentQuit
+)
+
+// parsePresentKey returns what the bytes read for a key press do.
+func parsePresentKey(b []byte) presentKey {
+	switch string(b) {
+	case " ", "l", "n", "j", "\r", "\x1b[C", "\x1b[B", "\x1b[6~":
+		return presentNext
+	case "h", "p", "k", "\x7f", "\x1b[D", "\x1b[A", "\x1b[5~":
+		return presentPrev
+	case "g", "\x1b[H", "\x1b[1~":
+		return presentFirst
+	case "G", "\x1b[F", "\x1b[4~":
+		return presentLast
+	case "q", "\x03", "\x1b":
+		return presentQuit
+	}
+	return presentNone
+}
+
+// presenter shows the slides of a document on the alternate screen.
+type presenter struct {
+	src     *source
+	slides  []string
+	current int
+
+	term *stream.Terminal
+	// Rendered slides, for the width they were rendered at.
+	rendered map[int]string
+	width    int
+}
+
+// run shows the slides until the presentation is quit.
+func (p *presenter) run() error {
+	p.term = stream.NewTerminal(os.Stdout)
+	p.term.SetProfile(termProfile)
+	if err := p.term.EnterAltScreen(); err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer p.term.ExitAltScreen() //nolint:errcheck
+
+	keys := make(chan presentKey)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			if k := parsePresentKey(buf[:n]); k != presentNone {
+				keys <- k
+			}
+		}
+	}()
+
+	resized := make(chan struct{}, 1)
+	stop := onResize(func() {
+		select {
+		case resized <- struct{}{}:
+		default:
+		}
+	})
+	defer stop()
+
+	var (
+		timer *time.Timer
+		tick  <-chan time.Time
+	)
+	for {
+		if err := p.show(); err != nil {
+			return err
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		tick = nil
+		if presentAuto > 0 && p.current < len(p.slides)-1 {
+			timer = time.NewTimer(presentAuto)
+			tick = timer.C
+		}
+
+		select {
+		case k, ok := <-keys:
+			if !ok || k == presentQuit {
+				return nil
+			}
+			p.move(k)
+		case <-resized:
+			p.term.Resize()
+		case <-tick:
+			p.move(presentNext)
+		}
+	}
+}
+
+// move moves to another slide.
+func (p *presenter) move(k presentKey) {
+	switch k { //nolint:exhaustive
+	case presentNext:
+		p.current = min(p.current+1, len(p.slides)-1)
+	case presentPrev:
+		p.current = max(p.current-1, 0)
+	case presentFirst:
+		p.current = 0
+	case presentLast:
+		p.current = len(p.slides) - 1
+	}
+}
+
+// show paints the current slide, centered on the screen, above a status
+// line counting the slides.
+func (p *presenter) show() error {
+	cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return fmt.Errorf("unable to get terminal size: %w", err)
+	}
+	// Leave the last row to the status line, and the last column empty so
+	// that lines don't wrap.
+	cols, rows = max(cols-1, 1), max(rows-1, 1)
+
+	wrap := min(cols, maxSlideWidth)
+	if wrap != p.width {
+		p.rendered = map[int]string{}
+		p.width = wrap
+	}
+	out, ok := p.rendered[p.current]
+	if !ok {
+		r, _, err := setupRendererWidth(p.src, uint(wrap)) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		out, err = renderDocumentWidth(r, p.slides[p.current], uint(wrap)) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("unable to render markdown: %w", err)
+		}
+		out = strings.Join(trimBlankLines(strings.Split(out, "\n")), "\n")
+		p.rendered[p.current] = out
+	}
+	if lines := strings.Split(out, "\n"); len(lines) > rows {
+		out = strings.Join(lines[:rows], "\n")
+	}
+
+	name := filepath.Base(p.src.URL)
+	counter := fmt.Sprintf("%d/%d", p.current+1, len(p.slides))
+	gap := max(cols-lipgloss.Width(name)-lipgloss.Width(counter)-2, 1)
+	status := " " + faint(name) + strings.Repeat(" ", gap) + keyword(counter)
+
+	frame := lipgloss.Place(cols, rows, lipgloss.Center, lipgloss.Center, out) + "\n" + status
+	return p.term.Update(frame) //nolint:wrapcheck
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestParsePresentKey(t *testing.T) {
+	for in, want := range map[string]presentKey{
+		" ":       presentNext,
+		"\x1b[C":  presentNext,
+		"h":       presentPrev,
+		"\x1b[5~": presentPrev,
+		"G":       presentLast,
+		"g":       presentFirst,
+		"\x03":    presentQuit,
+		"\x1b":    presentQuit,
+		"x":       presentNone,
+	} {
+		if got := parsePresentKey([]byte(in)); got != want {
+			t.Errorf("expected %q to be %d, got %d", in, want, got)
+		}
+	}
+}
+
+func TestPresenterMove(t *testing.T) {
+	p := &presenter{slides: []string{"a", "b", "c"}}
+	for _, step := range []struct {
+		key  presentKey
+		want int
+	}{
+		{presentPrev, 0},
+		{presentNext, 1},
+		{presentLast, 2},
+		{presentNext, 2},
+		{presentFirst, 0},
+	} {
+		p.move(step.key)
+		if p.current != step.want {
+			t.Fatalf("expected slide %d after %d, got %d", step.want, step.key, p.current)
+		}
+	}
+}
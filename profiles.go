@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileKeys are the settings applied from the profile chosen with
+// --profile, to tell where their values come from.
+var profileKeys = map[string]bool{}
+
+// profileSettings checks the settings of a profile of the config file and
+// returns them by the name of the setting.
+func profileSettings(name string, profile any) (map[string]any, error) {
+	values, ok := profile.(map[string]any)
+	if !ok && profile != nil {
+		return nil, fmt.Errorf("profile %q must be a map of settings", name)
+	}
+	settings := make(map[string]any, len(values))
+	for key, value := range values {
+		s, err := lookupSetting(key)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		if s.key == "profile" || s.key == "profiles" || s.key == "security" {
+			return nil, fmt.Errorf("profile %q can't set %s", name, s.key)
+		}
+		if !s.matches(value) {
+			return nil, fmt.Errorf("profile %q: %s must be %s, got %v", name, s.key, s.kind, value)
+		}
+		settings[s.key] = value
+	}
+	return settings, nil
+}
+
+// applyProfile merges the settings of the profile chosen with --profile, or
+// the profile setting, over the config file. Flags and environment variables
+// take precedence.
+func applyProfile(cmd *cobra.Command) error {
+	name := viper.GetString("profile")
+	if name == "" {
+		return nil
+	}
+	profiles := viper.GetStringMap("profiles")
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		names := slices.Sorted(maps.Keys(profiles))
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q, the config file has no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q, expected one of: %s", name, strings.Join(names, ", "))
+	}
+	settings, err := profileSettings(name, profile)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Using profile", "name", name)
+	for key, value := range settings {
+		s, _ := lookupSetting(key)
+		if flagChanged(cmd, s.flag) || os.Getenv("GLOW_"+strings.ToUpper(key)) != "" {
+			continue
+		}
+		viper.Set(key, value)
+		profileKeys[key] = true
+	}
+	return nil
+}
+
+// flagChanged reports whether a flag of cmd or the root command was given.
+func flagChanged(cmd *cobra.Command, name string) bool {
+	if name == "" {
+		return false
+	}
+	if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+		return true
+	}
+	f := cmd.Root().Flags().Lookup(name)
+	return f != nil && f.Changed
+}
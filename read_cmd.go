@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/htmlmd"
+	"github.com/spf13/cobra"
+)
+
+var readCmd = &cobra.Command{
+	Use:   "read URL|FILE",
+	Short: "Read the article of a web page",
+	Long: paragraph(fmt.Sprintf("\n%s the article of a web page, leaving out the navigation, sidebars, comments and ads around it, and render it under its title, author, date and reading time. Pages are cached like other downloads, so they can be read again with --offline. Saved HTML files can be read too.",
+		keyword("Read"))),
+	Example: paragraph("glow read https://go.dev/blog/loopvar-preview\nglow read go.dev/blog/loopvar-preview\nglow read saved-page.html"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := articleSource(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		return executeCLI(cmd, src, cmd.OutOrStdout())
+	},
+}
+
+// articleSource returns the source of the article of a web page, or of a
+// saved HTML file.
+func articleSource(ctx context.Context, arg string) (*source, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		f, err := os.Open(arg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		md, err := readArticle(f, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		return &source{reader: io.NopCloser(strings.NewReader(md)), URL: arg, markdown: true}, nil
+	}
+
+	if !strings.Contains(arg, "://") {
+		arg = protoHTTPS + arg
+	}
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%s is neither a web page nor a file", arg)
+	}
+	resp, err := fetch(ctx, u.String()) //nolint:bodyclose
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	return fetchedPage(resp, u.String(), readArticle)
+}
+
+// readArticle finds the article of an HTML page and returns it as markdown.
+func readArticle(r io.Reader, contentType string, base *url.URL) (string, error) {
+	a, err := htmlmd.Read(r, contentType, base)
+	if err != nil {
+		return "", fmt.Errorf("unable to read article: %w", err)
+	}
+	return a.Markdown(), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/gitcha"
+	"github.com/spf13/cobra"
+)
+
+var (
+	markdownGlobs = []string{
+		"*.md", "*.mdown", "*.mkdn", "*.mkd", "*.markdown",
+	}
+
+	// Paths skipped when rendering a directory tree.
+	recursiveIgnorePatterns = []string{
+		"node_modules",
+		".*",
+	}
+
+	fileHeader = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#04B575")).
+			Bold(true).
+			Render
+)
+
+// findMarkdownFiles returns all markdown files below dir, sorted by path.
+// Files ignored by git are skipped unless --all is set.
+func findMarkdownFiles(dir string) ([]string, error) {
+	var (
+		ch  chan gitcha.SearchResult
+		err error
+	)
+	if showAllFiles {
+		ch, err = gitcha.FindAllFilesExcept(dir, markdownGlobs, nil)
+	} else {
+		ch, err = gitcha.FindFilesExcept(dir, markdownGlobs, recursiveIgnorePatterns)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to find files: %w", err)
+	}
+	if ch == nil {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	var files []string
+	for res := range ch {
+		if res.Info.IsDir() {
+			continue
+		}
+		files = append(files, res.Path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// executeRecursive renders every markdown file below the given directories,
+// one after another, each preceded by a header naming the file.
+func executeRecursive(cmd *cobra.Command, dirs []string, w io.Writer) error {
+	var paths, names []string
+	for _, dir := range dirs {
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("unable to get absolute path: %w", err)
+		}
+		if root, err = filepath.EvalSymlinks(root); err != nil {
+			return fmt.Errorf("unable to resolve path: %w", err)
+		}
+		files, err := findMarkdownFiles(root)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range files {
+			name, err := filepath.Rel(root, path)
+			if err != nil {
+				name = path
+			}
+			if len(dirs) > 1 {
+				name = filepath.Join(dir, name)
+			}
+			paths = append(paths, path)
+			names = append(names, name)
+		}
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no markdown files found in %s", strings.Join(dirs, ", "))
+	}
+	outs, err := renderEach(len(paths), func(i int) (string, error) {
+		return renderFile(paths[i])
+	})
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for i, out := range outs {
+		b.WriteString(recursiveHeader(names[i]))
+		b.WriteString(out)
+	}
+
+	if pager || cmd.Flags().Changed("pager") {
+		return runPager(b.String())
+	}
+	if _, err := fmt.Fprint(w, b.String()); err != nil {
+		return fmt.Errorf("unable to write to writer: %w", err)
+	}
+	return nil
+}
+
+// renderFile renders a single markdown file from disk.
+func renderFile(path string) (string, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+	src := &source{URL: path}
+	md := prepareMarkdown(src, content)
+	return renderFormat(formatSource(src, content), func() (string, error) {
+		r, _, err := setupRenderer(src)
+		if err != nil {
+			return "", err
+		}
+		out, err := renderDocument(r, md)
+		if err != nil {
+			return "", fmt.Errorf("unable to render markdown: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// recursiveHeader returns a horizontal rule labelled with the file name,
+// spanning the word-wrap width. It's only styled for styled output.
+func recursiveHeader(name string) string {
+	label := "── " + name + " "
+	rule := int(width) - lipgloss.Width(label) //nolint:gosec
+	if rule < 3 {
+		rule = 3
+	}
+	header := label + strings.Repeat("─", rule)
+	if outputFormat == formatANSI {
+		header = fileHeader(header)
+	}
+	return "\n" + header + "\n"
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExecuteRecursive(t *testing.T) {
+	defer func(format string, w uint, p, all bool) {
+		outputFormat, width, pager, showAllFiles = format, w, p, all
+	}(outputFormat, width, pager, showAllFiles)
+	outputFormat, width, pager = formatText, 40, false
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"README.md":            "# Readme",
+		"docs/guide.md":        "# Guide",
+		"docs/notes.txt":       "not markdown",
+		"build/out.md":         "# Built",
+		"node_modules/pkg.md":  "# Package",
+		".gitignore":           "build/\n",
+		".git/HEAD":            "ref: refs/heads/main\n",
+		".hidden/secret.md":    "# Secret",
+		"docs/deep/further.md": "# Further",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	headerPattern := regexp.MustCompile(`(?m)^── (\S+) ─+$`)
+	for _, tc := range []struct {
+		all  bool
+		want []string
+	}{
+		{false, []string{"README.md", "docs/deep/further.md", "docs/guide.md"}},
+		{true, []string{".hidden/secret.md", "README.md", "build/out.md", "docs/deep/further.md", "docs/guide.md", "node_modules/pkg.md"}},
+	} {
+		showAllFiles = tc.all
+		var out bytes.Buffer
+		if err := executeRecursive(&cobra.Command{}, []string{dir}, &out); err != nil {
+			t.Fatal(err)
+		}
+
+		var headers []string
+		for _, m := range headerPattern.FindAllStringSubmatch(out.String(), -1) {
+			headers = append(headers, filepath.ToSlash(m[1]))
+		}
+		if strings.Join(headers, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("all %v: expected headers for %q, got %q", tc.all, tc.want, headers)
+		}
+
+		// Each file is rendered under its own header.
+		sections := headerPattern.Split(out.String(), -1)[1:]
+		for i, s := range sections {
+			if i >= len(tc.want) {
+				break
+			}
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tc.want[i])))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title := strings.TrimPrefix(string(content), "# "); !strings.Contains(s, title) {
+				t.Errorf("all %v: expected %s under its header, got %q", tc.all, title, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// registry is a package registry whose packages' READMEs are rendered.
+type registry interface {
+	// readme returns the README of a package at a version, or at its latest
+	// version if version is empty.
+	readme(ctx context.Context, pkg, version string) (*source, error)
+}
+
+// registryNames are the registries packages are looked up in, as they
+// prefix packages, e.g. npm:left-pad.
+var registryNames = []string{"go", "npm", "pypi"}
+
+// newRegistry returns the registry named by the prefix of a package.
+func newRegistry(name string) (registry, bool) {
+	switch name {
+	case "go":
+		return newGoProxy(), true
+	case "npm":
+		return npmRegistry{url: defaultNPMRegistry}, true
+	case "pypi":
+		return pypiRegistry{url: defaultPyPI}, true
+	}
+	return nil, false
+}
+
+// splitVersion splits a package and its version, as in
+// github.com/pkg/errors@v0.9.1. The version is empty if none is given or if
+// it's "latest".
+func splitVersion(arg string) (pkg, version string) {
+	i := strings.LastIndex(arg, "@")
+	// Scoped npm packages start with @.
+	if i <= 0 {
+		return arg, ""
+	}
+	pkg, version = arg[:i], arg[i+1:]
+	if version == "latest" {
+		version = ""
+	}
+	return pkg, version
+}
+
+// defaultGoProxy is the Go module proxy used unless GOPROXY names another.
+const defaultGoProxy = "https://proxy.golang.org"
+
+// goProxy is a Go module proxy, which serves the source of every version of
+// a module as a zip file.
+type goProxy struct {
+	url string
+}
+
+// newGoProxy returns the first proxy of GOPROXY that's served over HTTP, or
+// the default proxy.
+func newGoProxy() goProxy {
+	for _, p := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://") {
+			return goProxy{url: strings.TrimSuffix(p, "/")}
+		}
+	}
+	return goProxy{url: defaultGoProxy}
+}
+
+func (p goProxy) readme(ctx context.Context, module, version string) (*source, error) {
+	escaped := escapeModulePath(module)
+	if version == "" {
+		var latest struct {
+			Version string `json:"Version"`
+		}
+		if _, err := fetchJSON(ctx, fmt.Sprintf("%s/%s/@latest", p.url, escaped), &latest); err != nil {
+			return nil, fmt.Errorf("unable to find module %s: %w", module, err)
+		}
+		version = latest.Version
+	}
+
+	resp, err := fetch(ctx, fmt.Sprintf("%s/%s/@v/%s.zip", p.url, escaped, escapeModulePath(version))) //nolint:bodyclose
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unable to find module %s@%s: HTTP status %d", module, version, resp.StatusCode)
+	}
+	body := downloadBody(resp)
+	content, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read http response body: %w", err)
+	}
+
+	readme, err := moduleREADME(content, module+"@"+version)
+	if err != nil {
+		return nil, err
+	}
+	return &source{
+		reader:   io.NopCloser(bytes.NewReader(readme)),
+		URL:      fmt.Sprintf("https://pkg.go.dev/%s@%s", module, version),
+		markdown: true,
+	}, nil
+}
+
+// moduleREADME returns the README at the root of the zip file of a module,
+// whose files are all under a directory named after the module and version.
+func moduleREADME(content []byte, root string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read module zip: %w", err)
+	}
+	for _, name := range readmeNames {
+		f, err := zr.Open(root + "/" + name)
+		if err != nil {
+			continue
+		}
+		defer f.Close() //nolint:errcheck
+		readme, err := io.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", name, err)
+		}
+		return readme, nil
+	}
+	return nil, fmt.Errorf("can't find README in %s", root)
+}
+
+// escapeModulePath escapes a module path or version for the proxy, which
+// writes upper case letters as ! followed by the letter in lower case, so
+// that paths differing only in case don't clash on case-insensitive file
+// systems.
+func escapeModulePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// defaultNPMRegistry is the registry of npm packages.
+const defaultNPMRegistry = "https://registry.npmjs.org"
+
+// npmRegistry is an npm registry, which serves every version of a package
+// in one document, with the README of the latest version.
+type npmRegistry struct {
+	url string
+}
+
+func (r npmRegistry) readme(ctx context.Context, pkg, version string) (*source, error) {
+	var doc struct {
+		Readme   string            `json:"readme"`
+		DistTags map[string]string `json:"dist-tags"`
+		Versions map[string]struct {
+			Readme string `json:"readme"`
+		} `json:"versions"`
+	}
+	// Scoped packages keep their @ but escape their slash.
+	if _, err := fetchJSON(ctx, r.url+"/"+strings.Replace(url.PathEscape(pkg), "%40", "@", 1), &doc); err != nil {
+		return nil, fmt.Errorf("unable to find npm package %s: %w", pkg, err)
+	}
+
+	latest := doc.DistTags["latest"]
+	if version == "" {
+		version = latest
+	}
+	v, ok := doc.Versions[version]
+	if !ok {
+		return nil, fmt.Errorf("npm package %s has no version %s", pkg, version)
+	}
+	readme := v.Readme
+	if readme == "" && version == latest {
+		readme = doc.Readme
+	}
+	if readme == "" {
+		return nil, fmt.Errorf("can't find README of npm package %s@%s", pkg, version)
+	}
+	return &source{
+		reader:   io.NopCloser(strings.NewReader(readme)),
+		URL:      fmt.Sprintf("https://www.npmjs.com/package/%s/v/%s", pkg, version),
+		markdown: true,
+	}, nil
+}
+
+// defaultPyPI is the registry of Python packages.
+const defaultPyPI = "https://pypi.org"
+
+// pypiRegistry is the Python Package Index, whose JSON API gives the
+// description of a package, which is its README.
+type pypiRegistry struct {
+	url string
+}
+
+func (r pypiRegistry) readme(ctx context.Context, pkg, version string) (*source, error) {
+	u := fmt.Sprintf("%s/pypi/%s/json", r.url, url.PathEscape(pkg))
+	if version != "" {
+		u = fmt.Sprintf("%s/pypi/%s/%s/json", r.url, url.PathEscape(pkg), url.PathEscape(version))
+	}
+	var doc struct {
+		Info struct {
+			Description            string `json:"description"`
+			DescriptionContentType string `json:"description_content_type"`
+			Version                string `json:"version"`
+			PackageURL             string `json:"package_url"`
+		} `json:"info"`
+	}
+	if _, err := fetchJSON(ctx, u, &doc); err != nil {
+		return nil, fmt.Errorf("unable to find PyPI package %s: %w", pkg, err)
+	}
+	info := doc.Info
+	if strings.TrimSpace(info.Description) == "" {
+		return nil, fmt.Errorf("can't find README of PyPI package %s@%s", pkg, info.Version)
+	}
+
+	// Descriptions in reStructuredText or plain text are shown as they
+	// are, in a code block.
+	readme := info.Description
+	mediaType, _, _ := strings.Cut(info.DescriptionContentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "text/markdown":
+	case "text/x-rst", "":
+		readme = codeFence(readme, "rst")
+	default:
+		readme = codeFence(readme, "text")
+	}
+	return &source{
+		reader:   io.NopCloser(strings.NewReader(readme)),
+		URL:      strings.TrimSuffix(info.PackageURL, "/") + "/" + info.Version + "/",
+		markdown: true,
+	}, nil
+}
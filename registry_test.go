@@ -0,0 +1,128 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSplitVersion(t *testing.T) {
+	for arg, want := range map[string][2]string{
+		"github.com/pkg/errors@v0.9.1": {"github.com/pkg/errors", "v0.9.1"},
+		"github.com/pkg/errors":        {"github.com/pkg/errors", ""},
+		"github.com/pkg/errors@latest": {"github.com/pkg/errors", ""},
+		"@types/node@20.1.0":           {"@types/node", "20.1.0"},
+		"@types/node":                  {"@types/node", ""},
+	} {
+		if pkg, version := splitVersion(arg); pkg != want[0] || version != want[1] {
+			t.Errorf("%s: expected %q, got %q", arg, want, [2]string{pkg, version})
+		}
+	}
+}
+
+func TestGoProxy(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	// Files are under the module's path, while URLs escape it.
+	for name, content := range map[string]string{
+		"github.com/BurntSushi/toml@v1.3.2/README.md":      "# TOML\n",
+		"github.com/BurntSushi/toml@v1.3.2/docs/README.md": "# Docs\n",
+	} {
+		w, _ := zw.Create(name)
+		_, _ = io.WriteString(w, content)
+	}
+	_ = zw.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/!burnt!sushi/toml/@latest":
+			_, _ = io.WriteString(w, `{"Version": "v1.3.2"}`)
+		case "/github.com/!burnt!sushi/toml/@v/v1.3.2.zip":
+			_, _ = w.Write(zipped.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("GOPROXY", "off,"+srv.URL+"/,direct")
+
+	src, err := newGoProxy().readme(context.Background(), "github.com/BurntSushi/toml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(src.reader)
+	if string(b) != "# TOML\n" || src.URL != "https://pkg.go.dev/github.com/BurntSushi/toml@v1.3.2" {
+		t.Errorf("expected the README of the latest version, got %q from %s", b, src.URL)
+	}
+	if _, err := newGoProxy().readme(context.Background(), "github.com/BurntSushi/toml", "v0.1.0"); err == nil {
+		t.Error("expected an error for a missing version")
+	}
+}
+
+func TestNPMRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/@types%2Fleft-pad" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{
+			"readme": "# left-pad\n",
+			"dist-tags": {"latest": "1.3.0"},
+			"versions": {"1.3.0": {}, "1.2.0": {"readme": "# Old\n"}, "1.0.0": {}}
+		}`)
+	}))
+	defer srv.Close()
+
+	reg := npmRegistry{url: srv.URL}
+	for version, want := range map[string]string{"": "# left-pad\n", "1.2.0": "# Old\n"} {
+		src, err := reg.readme(context.Background(), "@types/left-pad", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := io.ReadAll(src.reader); string(b) != want {
+			t.Errorf("%q: expected %q, got %q", version, want, b)
+		}
+	}
+	for _, version := range []string{"1.0.0", "9.9.9"} {
+		if _, err := reg.readme(context.Background(), "@types/left-pad", version); err == nil {
+			t.Errorf("%s: expected an error", version)
+		}
+	}
+}
+
+func TestPyPIRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/requests/json":
+			_, _ = io.WriteString(w, `{"info": {"description": "# Requests\n", "description_content_type": "text/markdown; charset=UTF-8",
+				"version": "2.31.0", "package_url": "https://pypi.org/project/requests/"}}`)
+		case "/pypi/requests/1.0.0/json":
+			_, _ = io.WriteString(w, `{"info": {"description": "Requests\n========\n", "version": "1.0.0",
+				"package_url": "https://pypi.org/project/requests/"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	reg := pypiRegistry{url: srv.URL}
+	src, err := reg.readme(context.Background(), "requests", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(src.reader); string(b) != "# Requests\n" || src.URL != "https://pypi.org/project/requests/2.31.0/" {
+		t.Errorf("expected the markdown description, got %q from %s", b, src.URL)
+	}
+
+	src, err = reg.readme(context.Background(), "requests", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(src.reader); string(b) != "```rst\nRequests\n========\n```\n" {
+		t.Errorf("expected the reStructuredText description in a code block, got %q", b)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Releases are listed this many at a time.
+const releasesPerPage = 100
+
+var (
+	releaseAll bool
+
+	releaseCmd = &cobra.Command{
+		Use:   "release REPO [TAG]",
+		Short: "Render the release notes of a repository",
+		Long: paragraph(fmt.Sprintf("\n%s the release notes of a repository on GitHub or GitLab, given by its URL, with or without https://, or as owner/repo for GitHub. The latest release is rendered unless a tag is given. --all renders the whole release history, newest first, in the TUI when writing to a terminal.",
+			keyword("Render"))),
+		Example: paragraph("glow release charmbracelet/glow\nglow release charmbracelet/glow v2.0.0\nglow release --all gitlab.com/gitlab-org/cli"),
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if releaseAll && len(args) > 1 {
+				return errors.New("cannot use both a tag and --all")
+			}
+			repo, err := parseReleaseRepo(args[0])
+			if err != nil {
+				return err
+			}
+
+			var src *source
+			if releaseAll {
+				src, err = repo.historySource(cmd.Context())
+			} else {
+				tag := ""
+				if len(args) > 1 {
+					tag = args[1]
+				}
+				src, err = repo.releaseSource(cmd.Context(), tag)
+			}
+			if err != nil {
+				return err
+			}
+			defer src.reader.Close() //nolint:errcheck
+
+			// The release history is paged through in the TUI, unless
+			// it's written elsewhere.
+			if releaseAll && !pager && outputFile == "" && outputFormat == formatANSI && term.IsTerminal(int(os.Stdout.Fd())) {
+				tui = true
+			}
+			return executeCLI(cmd, src, cmd.OutOrStdout())
+		},
+	}
+)
+
+// release is a release of a repository.
+type release struct {
+	tag, name, body string
+	// The release's web page.
+	url        string
+	author     string
+	published  time.Time
+	prerelease bool
+}
+
+// title returns the name of a release, followed by its tag unless the name
+// already says it.
+func (r release) title() string {
+	switch {
+	case r.name == "":
+		return r.tag
+	case strings.Contains(r.name, r.tag):
+		return r.name
+	}
+	return fmt.Sprintf("%s (%s)", r.name, r.tag)
+}
+
+// details returns the line under the title of a release saying when and by
+// whom it was published.
+func (r release) details() string {
+	s := "Released"
+	if r.prerelease {
+		s = "Pre-released"
+	}
+	if !r.published.IsZero() {
+		s += " on " + r.published.Format("January 2, 2006")
+	}
+	if r.author != "" {
+		s += " by **@" + r.author + "**"
+	}
+	return s
+}
+
+// releaseRepo is a repository whose releases are read from the API of its
+// forge.
+type releaseRepo struct {
+	forge forge
+	// owner/name
+	project string
+	// Base URLs of the API and of the website.
+	api, web string
+}
+
+// parseReleaseRepo returns the repository an argument of glow release
+// names: the URL of a repository on GitHub or GitLab, or owner/repo for
+// GitHub.
+func parseReleaseRepo(arg string) (*releaseRepo, error) {
+	f, owner, name, ok := parseForgeURL(arg)
+	if !ok {
+		owner, name, ok = strings.Cut(arg, "/")
+		if !ok || owner == "" || name == "" || strings.ContainsAny(owner, ".:") || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%s is not a GitHub or GitLab repository", arg)
+		}
+		f = githubForge{}
+	}
+	switch f.(type) {
+	case githubForge:
+		return &releaseRepo{forge: f, project: owner + "/" + name, api: "https://api." + githubURL.Hostname(), web: githubURL.String()}, nil
+	case gitlabForge:
+		return &releaseRepo{forge: f, project: owner + "/" + name, api: gitlabURL.String() + "/api/v4", web: gitlabURL.String()}, nil
+	}
+	return nil, fmt.Errorf("releases of repositories on %s are not supported", f.hostname())
+}
+
+// releaseSource returns the document of the release with a tag, or of the
+// latest release if tag is empty.
+func (r *releaseRepo) releaseSource(ctx context.Context, tag string) (*source, error) {
+	rel, err := r.release(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", rel.title())
+	fmt.Fprintf(&b, "%s in [%s](%s/%s)\n\n", rel.details(), r.project, r.web, r.project)
+	if body := normalizeCommentBody(rel.body); body != "" {
+		b.WriteString(body + "\n")
+	} else {
+		b.WriteString("*No release notes.*\n")
+	}
+	return &source{reader: io.NopCloser(strings.NewReader(b.String())), URL: rel.url, markdown: true}, nil
+}
+
+// historySource returns the document of every release, newest first, each
+// under a heading of its own.
+func (r *releaseRepo) historySource(ctx context.Context) (*source, error) {
+	releases, err := r.releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("%s has no releases", r.project)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Releases of %s\n", r.project)
+	for _, rel := range releases {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n\n", rel.title(), rel.details())
+		// Headings of the notes go under the release's.
+		if body := demoteHeadings(normalizeCommentBody(rel.body), 2); body != "" {
+			b.WriteString(body + "\n")
+		} else {
+			b.WriteString("*No release notes.*\n")
+		}
+	}
+	return &source{reader: io.NopCloser(strings.NewReader(b.String())), URL: r.web + "/" + r.project + "/releases", markdown: true}, nil
+}
+
+// release fetches the release with a tag, or the latest one if tag is
+// empty.
+func (r *releaseRepo) release(ctx context.Context, tag string) (release, error) {
+	switch r.forge.(type) {
+	case gitlabForge:
+		u := fmt.Sprintf("%s/projects/%s/releases/permalink/latest", r.api, url.PathEscape(r.project))
+		if tag != "" {
+			u = fmt.Sprintf("%s/projects/%s/releases/%s", r.api, url.PathEscape(r.project), url.PathEscape(tag))
+		}
+		var rel gitlabRelease
+		if _, err := fetchJSON(ctx, u, &rel); err != nil {
+			return release{}, fmt.Errorf("unable to get GitLab release: %w", err)
+		}
+		return rel.release(), nil
+	default:
+		u := fmt.Sprintf("%s/repos/%s/releases/latest", r.api, r.project)
+		if tag != "" {
+			u = fmt.Sprintf("%s/repos/%s/releases/tags/%s", r.api, r.project, url.PathEscape(tag))
+		}
+		var rel githubRelease
+		if _, err := fetchJSON(ctx, u, &rel); err != nil {
+			return release{}, fmt.Errorf("unable to get GitHub release: %w", err)
+		}
+		return rel.release(), nil
+	}
+}
+
+// releases fetches every release, newest first, a page at a time.
+func (r *releaseRepo) releases(ctx context.Context) ([]release, error) {
+	var releases []release
+	switch r.forge.(type) {
+	case gitlabForge:
+		for page := "1"; page != ""; {
+			var rels []gitlabRelease
+			u := fmt.Sprintf("%s/projects/%s/releases?per_page=%d&page=%s", r.api, url.PathEscape(r.project), releasesPerPage, page)
+			header, err := fetchJSON(ctx, u, &rels)
+			if err != nil {
+				return nil, fmt.Errorf("unable to list GitLab releases: %w", err)
+			}
+			for _, rel := range rels {
+				releases = append(releases, rel.release())
+			}
+			page = header.Get("X-Next-Page")
+		}
+	default:
+		// GitHub lists pages until one isn't full.
+		for page := 1; ; page++ {
+			var rels []githubRelease
+			u := fmt.Sprintf("%s/repos/%s/releases?per_page=%d&page=%d", r.api, r.project, releasesPerPage, page)
+			if _, err := fetchJSON(ctx, u, &rels); err != nil {
+				return nil, fmt.Errorf("unable to list GitHub releases: %w", err)
+			}
+			for _, rel := range rels {
+				if !rel.Draft {
+					releases = append(releases, rel.release())
+				}
+			}
+			if len(rels) < releasesPerPage {
+				break
+			}
+		}
+	}
+	return releases, nil
+}
+
+// githubRelease is a release as the GitHub API returns it.
+type githubRelease struct {
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Body        string     `json:"body"`
+	HTMLURL     string     `json:"html_url"`
+	Author      githubUser `json:"author"`
+	PublishedAt time.Time  `json:"published_at"`
+	Prerelease  bool       `json:"prerelease"`
+	Draft       bool       `json:"draft"`
+}
+
+func (r githubRelease) release() release {
+	return release{
+		tag:        r.TagName,
+		name:       r.Name,
+		body:       r.Body,
+		url:        r.HTMLURL,
+		author:     r.Author.Login,
+		published:  r.PublishedAt,
+		prerelease: r.Prerelease,
+	}
+}
+
+// gitlabRelease is a release as the GitLab API returns it.
+type gitlabRelease struct {
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Author      gitlabUser `json:"author"`
+	ReleasedAt  time.Time  `json:"released_at"`
+	Upcoming    bool       `json:"upcoming_release"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+func (r gitlabRelease) release() release {
+	return release{
+		tag:        r.TagName,
+		name:       r.Name,
+		body:       r.Description,
+		url:        r.Links.Self,
+		author:     r.Author.Username,
+		published:  r.ReleasedAt,
+		prerelease: r.Upcoming,
+	}
+}
+
+// demoteHeadings moves the ATX headings of a document down by levels, up to
+// level 6, leaving fenced code alone.
+func demoteHeadings(md string, levels int) string {
+	lines := strings.Split(md, "\n")
+	var fence string
+	for i, l := range lines {
+		trimmed := strings.TrimLeft(l, " ")
+		if len(l)-len(trimmed) > 3 {
+			continue
+		}
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		if level == 0 || level > 6 || (len(trimmed) > level && trimmed[level] != ' ' && trimmed[level] != '\t') {
+			continue
+		}
+		lines[i] = strings.Repeat("#", min(level+levels, 6)) + trimmed[level:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseReleaseRepo(t *testing.T) {
+	for arg, want := range map[string]string{
+		"charmbracelet/glow":                    "charmbracelet/glow https://api.github.com",
+		"https://github.com/charmbracelet/glow": "charmbracelet/glow https://api.github.com",
+		"gitlab.com/gitlab-org/cli":             "gitlab-org/cli https://gitlab.com/api/v4",
+		"codeberg.org/forgejo/forgejo":          "",
+		"example.com/glow":                      "",
+		"charmbracelet/glow/releases":           "",
+		"charmbracelet":                         "",
+	} {
+		var got string
+		if r, err := parseReleaseRepo(arg); err == nil {
+			got = r.project + " " + r.api
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", arg, want, got)
+		}
+	}
+}
+
+func TestGitHubReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases/latest":
+			_, _ = io.WriteString(w, `{"tag_name": "v2.0.0", "name": "Glow 2", "body": "## Changes\r\n- New TUI",
+				"html_url": "https://github.com/o/r/releases/tag/v2.0.0", "author": {"login": "alice"},
+				"published_at": "2024-05-01T12:00:00Z"}`)
+		case "/repos/o/r/releases":
+			_, _ = io.WriteString(w, `[
+				{"tag_name": "v2.1.0", "draft": true},
+				{"tag_name": "v2.0.0", "name": "v2.0.0", "body": "## Changes\n- New TUI", "published_at": "2024-05-01T12:00:00Z"},
+				{"tag_name": "v1.0.0", "prerelease": true}
+			]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	repo, _ := parseReleaseRepo("o/r")
+	repo.api = srv.URL
+
+	src, err := repo.releaseSource(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(src.reader)
+	want := "# Glow 2 (v2.0.0)\n\nReleased on May 1, 2024 by **@alice** in [o/r](https://github.com/o/r)\n\n## Changes\n- New TUI\n"
+	if string(b) != want || src.URL != "https://github.com/o/r/releases/tag/v2.0.0" {
+		t.Errorf("expected the latest release, got %q from %s", b, src.URL)
+	}
+	if _, err := repo.releaseSource(context.Background(), "v0.1.0"); err == nil {
+		t.Error("expected an error for a missing release")
+	}
+
+	src, err = repo.historySource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = io.ReadAll(src.reader)
+	want = "# Releases of o/r\n\n## v2.0.0\n\nReleased on May 1, 2024\n\n#### Changes\n- New TUI\n\n## v1.0.0\n\nPre-released\n\n*No release notes.*\n"
+	if string(b) != want {
+		t.Errorf("expected the release history without drafts, got %q", b)
+	}
+}
+
+func TestDemoteHeadings(t *testing.T) {
+	md := "# One\n\n```sh\n# comment\n```\n\n##### Five\n#hashtag\n"
+	want := "### One\n\n```sh\n# comment\n```\n\n###### Five\n#hashtag\n"
+	if got := demoteHeadings(md, 2); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/ui"
+)
+
+// repoFromArg returns the remote repository a GitHub or GitLab URL without
+// a file path points to, e.g. github.com/charmbracelet/glow, to browse in the
+// TUI. It returns nil for any other argument.
+func repoFromArg(arg string) ui.Repo {
+	f, owner, name, ok := parseForgeURL(arg)
+	if !ok {
+		return nil
+	}
+	switch f.(type) {
+	case githubForge:
+		return newGitHubRepo(owner, name)
+	case gitlabForge:
+		return newGitLabRepo(owner, name)
+	}
+	return nil
+}
+
+// fetchBytes GETs a URL, failing unless the response is OK.
+func fetchBytes(ctx context.Context, u string) ([]byte, http.Header, error) {
+	resp, err := fetch(ctx, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read http response body: %w", err)
+	}
+	return body, resp.Header, nil
+}
+
+// fetchJSON GETs a URL and parses the JSON response into v.
+func fetchJSON(ctx context.Context, u string, v any) (http.Header, error) {
+	body, header, err := fetchBytes(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("unable to parse json: %w", err)
+	}
+	return header, nil
+}
+
+// escapePath escapes each segment of a slash separated path for use in a
+// URL.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRepoFromArg(t *testing.T) {
+	for arg, want := range map[string]string{
+		"github.com/charmbracelet/glow":           "github charmbracelet/glow",
+		"https://github.com/charmbracelet/glow/":  "github charmbracelet/glow",
+		"github://charmbracelet/glow.git":         "github charmbracelet/glow",
+		"gitlab.com/caarlos0/test":                "gitlab caarlos0/test",
+		"gitlab://caarlos0/test":                  "gitlab caarlos0/test",
+		"github.com/charmbracelet":                "",
+		"github.com/charmbracelet/glow/README.md": "",
+		"example.com/charmbracelet/glow":          "",
+		"codeberg.org/forgejo/forgejo":            "",
+		"docs":                                    "",
+	} {
+		var got string
+		switch r := repoFromArg(arg).(type) {
+		case *githubRepo:
+			got = "github " + r.owner + "/" + r.name
+		case *gitlabRepo:
+			got = "gitlab " + r.owner + "/" + r.name
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", arg, want, got)
+		}
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r":
+			_, _ = io.WriteString(w, `{"default_branch": "trunk"}`)
+		case "/repos/o/r/git/trees/trunk":
+			_, _ = io.WriteString(w, `{"tree": [
+				{"path": "README.md", "type": "blob"},
+				{"path": "docs", "type": "tree"},
+				{"path": "docs/a b.md", "type": "blob"}
+			]}`)
+		case "/o/r/trunk/docs/a b.md":
+			_, _ = io.WriteString(w, "# A")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	repo := newGitHubRepo("o", "r")
+	repo.api, repo.raw, repo.web = srv.URL, srv.URL, "https://github.com"
+
+	files, err := repo.Files(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(files, []string{"README.md", "docs/a b.md"}) {
+		t.Errorf("unexpected files %q", files)
+	}
+	body, err := repo.Fetch(context.Background(), "docs/a b.md")
+	if err != nil || string(body) != "# A" {
+		t.Errorf("expected the file's content, got %q (%v)", body, err)
+	}
+	if _, err := repo.Fetch(context.Background(), "missing.md"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if u := repo.URL("docs/a b.md"); u != "https://github.com/o/r/blob/trunk/docs/a%20b.md" {
+		t.Errorf("unexpected url %q", u)
+	}
+}
+
+func TestGitLabRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/o%2Fr":
+			_, _ = io.WriteString(w, `{"default_branch": "main"}`)
+		case "/api/v4/projects/o%2Fr/repository/tree":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				_, _ = io.WriteString(w, `[{"path": "README.md", "type": "blob"}, {"path": "docs", "type": "tree"}]`)
+				return
+			}
+			_, _ = io.WriteString(w, `[{"path": "docs/guide.md", "type": "blob"}]`)
+		case "/api/v4/projects/o%2Fr/repository/files/docs%2Fguide.md/raw":
+			_, _ = io.WriteString(w, "# Guide")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	repo := newGitLabRepo("o", "r")
+	repo.base = srv.URL
+
+	files, err := repo.Files(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(files, []string{"README.md", "docs/guide.md"}) {
+		t.Errorf("unexpected files %q", files)
+	}
+	body, err := repo.Fetch(context.Background(), "docs/guide.md")
+	if err != nil || string(body) != "# Guide" {
+		t.Errorf("expected the file's content, got %q (%v)", body, err)
+	}
+	if u := repo.URL("docs/guide.md"); u != srv.URL+"/o/r/-/blob/main/docs/guide.md" {
+		t.Errorf("unexpected url %q", u)
+	}
+}
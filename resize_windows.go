@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package main
+
+// onResize does nothing on Windows, which has no signal for terminal
+// resizes.
+func onResize(func()) (stop func()) {
+	return func() {}
+}
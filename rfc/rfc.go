@@ -0,0 +1,98 @@
+// Package rfc converts RFCs and Internet-Drafts to markdown so they can be
+// rendered like any other document. Both the XML source of a document and
+// its paginated text format are understood. Sections become headings with
+// anchors, references to sections and other RFCs become links, and a table
+// of contents linking to the sections is added.
+package rfc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/htmlmd"
+	"github.com/douglas-larocca/glow/v2/lint"
+)
+
+// URL is the address of an RFC on the RFC Editor's site, formatted with its
+// number.
+const URL = "https://www.rfc-editor.org/rfc/rfc%d"
+
+// Convert converts an RFC or Internet-Draft to markdown, in whichever of the
+// XML and text formats it is.
+func Convert(content []byte) (string, error) {
+	if IsXML(content) {
+		return FromXML(bytes.NewReader(content))
+	}
+	return FromText(string(content)), nil
+}
+
+// IsXML reports whether content is the XML source of a document rather than
+// its text format.
+func IsXML(content []byte) bool {
+	content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\ufeff")))
+	return bytes.HasPrefix(content, []byte("<?xml")) || bytes.HasPrefix(content, []byte("<rfc"))
+}
+
+// heading is a heading of a converted document.
+type heading struct {
+	level  int
+	text   string
+	anchor string
+}
+
+// outline collects the headings of a document, giving them the anchors
+// they get once rendered, so references can link to them before they are
+// written.
+type outline struct {
+	headings []heading
+	anchors  lint.Anchors
+	// Anchors of the sections by number, e.g. 1.2 or A.
+	sections map[string]string
+}
+
+func newOutline() *outline {
+	return &outline{anchors: lint.Anchors{}, sections: map[string]string{}}
+}
+
+// add adds a heading, numbered with number if it isn't empty, and returns
+// its anchor.
+func (o *outline) add(level int, number, title string) string {
+	text := title
+	if number != "" {
+		text = number + ". " + title
+		if len(number) == 1 && number[0] >= 'A' && number[0] <= 'Z' {
+			text = "Appendix " + text
+		}
+	}
+	h := heading{level: min(level, 6), text: text, anchor: o.anchors.Next(text)}
+	if number != "" {
+		o.sections[number] = h.anchor
+	}
+	o.headings = append(o.headings, h)
+	return h.anchor
+}
+
+// markdown returns the i-th heading.
+func (o *outline) markdown(i int) string {
+	h := o.headings[i]
+	return strings.Repeat("#", h.level) + " " + htmlmd.Escape(h.text)
+}
+
+// toc returns a table of contents linking to the headings from the i-th
+// on, down to the third level of sections.
+func (o *outline) toc(i int) string {
+	var b strings.Builder
+	for _, h := range o.headings[i:] {
+		if h.level < 2 || h.level > 4 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", h.level-2), htmlmd.Escape(h.text), h.anchor)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// link formats a link to url.
+func link(text, url string) string {
+	return "[" + text + "](" + url + ")"
+}
@@ -0,0 +1,212 @@
+package rfc
+
+import (
+	"strings"
+	"testing"
+)
+
+const textRFC = `Internet Engineering Task Force (IETF)                        J. Doe, Ed.
+Request for Comments: 9999                                       Example
+Category: Standards Track                                   January 2024
+
+
+                         The Example Protocol
+
+Abstract
+
+   This document defines the *Example* protocol.
+
+Table of Contents
+
+   1.  Introduction  . . . . . . . . . . . . . . . . . . . . . . . .   2
+
+1.  Introduction
+
+   The protocol is described in Section 2, and builds on Section 3.1
+   of [RFC9110] and RFC 3986.
+
+   *  first item
+      continued
+
+      -  nested item
+
+   *  second item
+
+   A paragraph split across
+
+Doe                          Standards Track                    [Page 1]
+` + "\f" + `
+RFC 9999                  The Example Protocol              January 2024
+
+
+   pages after this page-
+   break.
+
+     GET / HTTP/1.1
+     Host: example.com
+
+2.  Messages
+
+2.1.  Format
+
+   message = header body
+
+Appendix A.  Acknowledgements
+
+   Thanks to <https://example.com>.
+
+Author's Address
+
+   J. Doe
+   Example
+`
+
+const textMarkdown = "# The Example Protocol\n\n" +
+	"```\n" +
+	"Internet Engineering Task Force (IETF)                        J. Doe, Ed.\n" +
+	"Request for Comments: 9999                                       Example\n" +
+	"Category: Standards Track                                   January 2024\n" +
+	"```\n\n" +
+	"## Abstract\n\n" +
+	"This document defines the \\*Example\\* protocol.\n\n" +
+	"## Table of Contents\n\n" +
+	"- [1. Introduction](#1-introduction)\n" +
+	"- [2. Messages](#2-messages)\n" +
+	"  - [2.1. Format](#21-format)\n" +
+	"- [Appendix A. Acknowledgements](#appendix-a-acknowledgements)\n" +
+	"- [Author's Address](#authors-address)\n\n" +
+	"## 1. Introduction\n\n" +
+	"The protocol is described in [Section 2](#2-messages), and builds on " +
+	"[Section 3.1 of \\[RFC9110\\]](https://www.rfc-editor.org/rfc/rfc9110#section-3.1) " +
+	"and [RFC 3986](https://www.rfc-editor.org/rfc/rfc3986).\n\n" +
+	"- first item continued\n\n" +
+	"  - nested item\n\n" +
+	"- second item\n\n" +
+	"A paragraph split across pages after this page-break.\n\n" +
+	"```\nGET / HTTP/1.1\nHost: example.com\n```\n\n" +
+	"## 2. Messages\n\n" +
+	"### 2.1. Format\n\n" +
+	"```\nmessage = header body\n```\n\n" +
+	"## Appendix A. Acknowledgements\n\n" +
+	"Thanks to <https://example.com>.\n\n" +
+	"## Author's Address\n\n" +
+	"J. Doe\\\nExample\n"
+
+func TestFromText(t *testing.T) {
+	if got := FromText(textRFC); got != textMarkdown {
+		t.Errorf("expected\n%s\ngot\n%s", textMarkdown, got)
+	}
+}
+
+const xmlRFC = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE rfc [<!ENTITY nbsp "&#160;">]>
+<rfc number="9999" category="std" obsoletes="1111, 2222" version="3">
+<front>
+  <title abbrev="Example">The Example Protocol</title>
+  <seriesInfo name="RFC" value="9999"/>
+  <author initials="J." surname="Doe" fullname="Jane Doe" role="editor">
+    <organization>Example</organization>
+  </author>
+  <date year="2024" month="January"/>
+  <abstract><t>This document defines the <em>Example</em> protocol.</t></abstract>
+</front>
+<middle>
+  <section anchor="intro"><name>Introduction</name>
+    <t>See <xref target="format"/>, <xref target="HTTP" section="3.1"/>
+    and <xref target="URI"/>.</t>
+    <ul><li>one</li><li><t>two</t></li></ul>
+    <dl><dt>Term</dt><dd>Its definition.</dd></dl>
+  </section>
+  <section anchor="messages"><name>Messages</name>
+    <section anchor="format"><name>Format</name>
+      <figure><name>A message</name>
+        <sourcecode type="abnf"><![CDATA[message = header body]]></sourcecode>
+      </figure>
+      <table><thead><tr><th>Field</th><th>Value</th></tr></thead>
+      <tbody><tr><td>a|b</td><td><tt>c</tt></td></tr></tbody></table>
+    </section>
+  </section>
+</middle>
+<back>
+  <displayreference target="HTTP" to="SEMANTICS"/>
+  <references><name>Normative References</name>
+    <reference anchor="HTTP" target="https://www.rfc-editor.org/info/rfc9110">
+      <front><title>HTTP Semantics</title>
+        <author initials="R." surname="Fielding" role="editor"/>
+        <date year="2022" month="June"/>
+      </front>
+      <seriesInfo name="RFC" value="9110"/>
+    </reference>
+    <reference anchor="URI">
+      <front><title>Uniform Resource Identifier (URI): Generic Syntax</title>
+        <author initials="T." surname="Berners-Lee"/>
+      </front>
+      <seriesInfo name="RFC" value="3986"/>
+    </reference>
+  </references>
+  <section anchor="acks" numbered="false"><name>Acknowledgements</name>
+    <t>Thanks.</t>
+  </section>
+</back>
+</rfc>`
+
+const xmlMarkdown = "# The Example Protocol\n\n" +
+	"[RFC 9999](https://www.rfc-editor.org/rfc/rfc9999) · Standards Track · January 2024\\\n" +
+	"Obsoletes: [1111](https://www.rfc-editor.org/rfc/rfc1111), [2222](https://www.rfc-editor.org/rfc/rfc2222)\\\n" +
+	"J. Doe, Ed. (Example)\n\n" +
+	"## Abstract\n\n" +
+	"This document defines the *Example* protocol.\n\n" +
+	"## Table of Contents\n\n" +
+	"- [1. Introduction](#1-introduction)\n" +
+	"- [2. Messages](#2-messages)\n" +
+	"  - [2.1. Format](#21-format)\n" +
+	"- [3. Normative References](#3-normative-references)\n" +
+	"- [Acknowledgements](#acknowledgements)\n\n" +
+	"## 1. Introduction\n\n" +
+	"See [Section 2.1](#21-format), " +
+	"[Section 3.1 of \\[SEMANTICS\\]](https://www.rfc-editor.org/rfc/rfc9110#section-3.1) " +
+	"and [\\[URI\\]](https://www.rfc-editor.org/rfc/rfc3986).\n\n" +
+	"- one\n- two\n\n" +
+	"**Term**\\\nIts definition.\n\n" +
+	"## 2. Messages\n\n" +
+	"### 2.1. Format\n\n" +
+	"```abnf\nmessage = header body\n```\n\n" +
+	"*Figure 1: A message*\n\n" +
+	"| Field | Value |\n| --- | --- |\n| a\\|b | `c` |\n\n" +
+	"*Table 1*\n\n" +
+	"## 3. Normative References\n\n" +
+	"**\\[SEMANTICS\\]** Fielding, R., Ed., \"HTTP Semantics\", RFC 9110, June 2022, " +
+	"<https://www.rfc-editor.org/info/rfc9110>.\n\n" +
+	"**\\[URI\\]** Berners-Lee, T., \"Uniform Resource Identifier (URI): Generic Syntax\", RFC 3986, " +
+	"<https://www.rfc-editor.org/rfc/rfc3986>.\n\n" +
+	"## Acknowledgements\n\n" +
+	"Thanks.\n"
+
+func TestFromXML(t *testing.T) {
+	got, err := FromXML(strings.NewReader(xmlRFC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != xmlMarkdown {
+		t.Errorf("expected\n%s\ngot\n%s", xmlMarkdown, got)
+	}
+
+	if _, err := FromXML(strings.NewReader("<html></html>")); err == nil {
+		t.Error("expected an error for a document that isn't an RFC")
+	}
+}
+
+func TestIsXML(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		want    bool
+	}{
+		{xmlRFC, true},
+		{"\ufeff<rfc version=\"3\">", true},
+		{textRFC, false},
+	} {
+		if got := IsXML([]byte(tc.content)); got != tc.want {
+			t.Errorf("%.20q: expected %v, got %v", tc.content, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package rfc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/htmlmd"
+)
+
+var (
+	footerPattern  = regexp.MustCompile(`\[Page \d+\]\s*$`)
+	headerPattern  = regexp.MustCompile(`^(?:RFC|Internet[- ]Draft|INTERNET[- ]DRAFT)\b`)
+	headingPattern = regexp.MustCompile(`^(Appendix\s+[A-Z]\.?|\d+(?:\.\d+)*\.?|[A-Z](?:\.\d+)+\.?|[A-Z]\.)\s+(\S.*)$`)
+	bulletPattern  = regexp.MustCompile(`^([*o+-]|\d+\.|\(\d+\)|\(?[a-z]\))\s+`)
+	tagPattern     = regexp.MustCompile(`^\[[^\]\s]+\]\s+`)
+	abnfPattern    = regexp.MustCompile(`^[A-Za-z][\w-]*\s+=/?\s`)
+	startPattern   = regexp.MustCompile(`^(?:[#>+=-]|\d+[.)](?:\s|$))`)
+	numberPattern  = regexp.MustCompile(`Request for Comments:\s*(\d+)`)
+
+	// References to sections of the document, to sections of other RFCs and
+	// to other RFCs.
+	refPattern = regexp.MustCompile(`<(https?://[^>\s]+)>|\[RFC ?(\d+)\]|\b(Section|Appendix) ((?:\d+|[A-Z])(?:\.\d+)*)(?: of (?:\[RFC ?(\d+)\]|RFC ?(\d+)))?|\bRFC ?(\d{1,5})\b`)
+)
+
+// textSection is a section of a document in the text format.
+type textSection struct {
+	number, title string
+	lines         []string
+	// Whether it's the table of contents, which is generated rather than
+	// converted.
+	toc bool
+	// Index of its heading in the outline, or -1 for text before the first
+	// heading.
+	heading int
+}
+
+// FromText converts an RFC or Internet-Draft in the text format to
+// markdown. Page headers and footers are left out, the title block becomes
+// the title of the document, and the table of contents is replaced by one
+// linking to the sections.
+func FromText(src string) string {
+	src = strings.NewReplacer("\r\n", "\n", "\t", "        ").Replace(src)
+	lines := unpaginate(strings.Split(src, "\n"))
+	front, body := splitFront(lines)
+
+	o := newOutline()
+	blocks := o.front(front)
+	sections := textSections(body)
+	for _, s := range sections {
+		switch {
+		case s.title == "":
+			s.heading = -1
+		case s.number == "":
+			s.heading = len(o.headings)
+			o.add(2, "", s.title)
+		default:
+			s.heading = len(o.headings)
+			o.add(strings.Count(s.number, ".")+2, s.number, s.title)
+		}
+	}
+
+	for _, s := range sections {
+		if s.heading >= 0 {
+			blocks = append(blocks, o.markdown(s.heading))
+		}
+		if s.toc {
+			if toc := o.toc(s.heading + 1); toc != "" {
+				blocks = append(blocks, toc)
+			}
+			continue
+		}
+		blocks = append(blocks, o.textBlocks(s.lines)...)
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// unpaginate removes the page breaks of a document with the headers and
+// footers around them. Paragraphs split across pages are joined again.
+func unpaginate(lines []string) []string {
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.Contains(line, "\f") {
+			out = append(out, strings.TrimRight(line, " "))
+			continue
+		}
+		out = trimBlank(out)
+		if n := len(out); n > 0 && footerPattern.MatchString(out[n-1]) {
+			out = trimBlank(out[:n-1])
+		}
+
+		// The header of the next page is either on the line of the form
+		// feed or the first line after it.
+		j := i + 1
+		if header := strings.TrimSpace(strings.ReplaceAll(line, "\f", "")); header == "" {
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+			if j < len(lines) && headerPattern.MatchString(lines[j]) {
+				j++
+			}
+		}
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		i = j - 1
+		if j < len(lines) && len(out) > 0 && !continues(out[len(out)-1], lines[j]) {
+			out = append(out, "")
+		}
+	}
+	out = trimBlank(out)
+	if n := len(out); n > 0 && footerPattern.MatchString(out[n-1]) {
+		out = trimBlank(out[:n-1])
+	}
+	return out
+}
+
+// continues reports whether next continues the paragraph of line across a
+// page break.
+func continues(line, next string) bool {
+	return indent(next) > 0 && !endsSentence(line)
+}
+
+// splitFront splits the title block off a document, which ends at the
+// first heading.
+func splitFront(lines []string) ([]string, []string) {
+	for i := 1; i < len(lines); i++ {
+		if lines[i-1] == "" && isHeading(lines[i]) {
+			return lines[:i], lines[i:]
+		}
+	}
+	return nil, lines
+}
+
+// isHeading reports whether a line is a heading, which unlike the text of
+// a section isn't indented.
+func isHeading(line string) bool {
+	return line != "" && line[0] != ' '
+}
+
+// front converts the title block of a document: the table of authors and
+// document information, then the centered title.
+func (o *outline) front(lines []string) []string {
+	groups := paragraphs(lines)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	var title string
+	if len(groups) > 1 {
+		title = joinLines(groups[1])
+	} else if m := numberPattern.FindStringSubmatch(strings.Join(groups[0], "\n")); m != nil {
+		title = "RFC " + m[1]
+	}
+	var blocks []string
+	if title != "" {
+		o.add(1, "", title)
+		blocks = append(blocks, o.markdown(0))
+	}
+	blocks = append(blocks, htmlmd.CodeFence(strings.Join(groups[0], "\n"), ""))
+	for _, g := range groups[min(len(groups), 2):] {
+		for i, l := range g {
+			g[i] = o.inline(strings.TrimSpace(l))
+		}
+		blocks = append(blocks, strings.Join(g, "\\\n"))
+	}
+	return blocks
+}
+
+// textSections splits the body of a document into its sections. A table of
+// contents is added before the first numbered section if the document has
+// none.
+func textSections(lines []string) []*textSection {
+	var sections []*textSection
+	toc := false
+	for _, l := range lines {
+		if !isHeading(l) {
+			if len(sections) == 0 {
+				sections = append(sections, &textSection{})
+			}
+			s := sections[len(sections)-1]
+			s.lines = append(s.lines, l)
+			continue
+		}
+
+		s := &textSection{title: strings.TrimSpace(l)}
+		if m := headingPattern.FindStringSubmatch(l); m != nil {
+			s.number = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(m[1], "Appendix")), ".")
+			s.title = m[2]
+		}
+		s.toc = s.number == "" && strings.EqualFold(s.title, "Table of Contents")
+		toc = toc || s.toc
+		sections = append(sections, s)
+	}
+	if toc {
+		return sections
+	}
+	for i, s := range sections {
+		if s.number != "" {
+			return append(sections[:i:i], append([]*textSection{{title: "Table of Contents", toc: true}}, sections[i:]...)...)
+		}
+	}
+	return sections
+}
+
+// item is an open item of a list.
+type item struct {
+	// Column of the text of the item in the document.
+	text int
+	// Column of the content of the item in markdown.
+	offset int
+}
+
+// textBlocks converts the text of a section to markdown blocks. Paragraphs
+// are joined into single lines, lists and definitions are recognized by
+// their indentation, and figures and code are kept as they are.
+func (o *outline) textBlocks(lines []string) []string {
+	var (
+		blocks []string
+		items  []item
+	)
+	for _, g := range paragraphs(lines) {
+		ind := minIndent(g)
+		for len(items) > 0 && ind < items[len(items)-1].text {
+			items = items[:len(items)-1]
+		}
+		base, pad := 3, ""
+		if n := len(items); n > 0 {
+			base, pad = items[n-1].text, strings.Repeat(" ", items[n-1].offset)
+		}
+
+		first := strings.TrimSpace(g[0])
+		switch {
+		case isArt(g) || (ind > base && !endsSentence(g[len(g)-1])):
+			for i, l := range g {
+				g[i] = l[min(ind, len(l)):]
+			}
+			blocks = append(blocks, htmlmd.Prefix(htmlmd.CodeFence(strings.Join(g, "\n"), ""), pad, pad))
+		case bulletPattern.MatchString(first):
+			var list []string
+			for _, l := range g {
+				t := strings.TrimSpace(l)
+				m := bulletPattern.FindStringSubmatch(t)
+				if m == nil {
+					list[len(list)-1] += " " + t
+					continue
+				}
+				for len(items) > 0 && indent(l) < items[len(items)-1].text {
+					items = items[:len(items)-1]
+				}
+				marker, label := listMarker(m[1])
+				offset := 0
+				if n := len(items); n > 0 {
+					offset = items[n-1].offset
+				}
+				items = append(items, item{text: indent(l) + len(m[0]), offset: offset + len(marker)})
+				list = append(list, strings.Repeat(" ", offset)+marker+label+t[len(m[0]):])
+			}
+			for i, l := range list {
+				j := len(l) - len(strings.TrimLeft(l, " "))
+				list[i] = l[:j] + o.inline(l[j:])
+			}
+			blocks = append(blocks, strings.Join(list, "\n"))
+		case len(g) > 1 && minIndent(g[1:]) > indent(g[0]) && len(first) <= 40 &&
+			!endsSentence(first) && !tagPattern.MatchString(first+" "):
+			term := "**" + o.inline(first) + "**"
+			blocks = append(blocks, htmlmd.Prefix(term+"\\\n"+o.inline(joinLines(g[1:])), pad, pad))
+		case len(g) > 1 && ind <= base && !endsSentence(g[len(g)-1]) && maxWidth(g) < 45:
+			for i, l := range g {
+				g[i] = escapeStart(o.inline(strings.TrimSpace(l)))
+			}
+			blocks = append(blocks, htmlmd.Prefix(strings.Join(g, "\\\n"), pad, pad))
+		default:
+			blocks = append(blocks, htmlmd.Prefix(escapeStart(o.inline(joinLines(g))), pad, pad))
+		}
+	}
+	return blocks
+}
+
+// listMarker returns the markdown marker of a list item from its bullet in
+// the document. Items labeled with letters keep their label, as markdown
+// only numbers lists.
+func listMarker(bullet string) (string, string) {
+	n := strings.Trim(bullet, "().")
+	if _, err := strconv.Atoi(n); err == nil {
+		return n + ". ", ""
+	}
+	if len(bullet) > 1 {
+		return "- ", htmlmd.Escape(bullet) + " "
+	}
+	return "- ", ""
+}
+
+// isArt reports whether the lines of a paragraph are a figure or code:
+// they are aligned in columns, draw boxes, or define ABNF rules.
+func isArt(lines []string) bool {
+	for _, l := range lines {
+		t := strings.TrimSpace(l)
+		t = bulletPattern.ReplaceAllString(t, "")
+		t = tagPattern.ReplaceAllString(t, "")
+		switch {
+		case strings.Contains(t, "   "),
+			strings.Contains(t, "+--"), strings.Contains(t, "--+"), strings.Contains(t, "+=="),
+			strings.Contains(t, "|"), strings.Contains(t, "::="),
+			abnfPattern.MatchString(t), t == "{", t == "}":
+			return true
+		}
+	}
+	return false
+}
+
+// inline converts a line of text, escaping what markdown would take as
+// syntax and linking references to sections and other RFCs.
+func (o *outline) inline(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range refPattern.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(htmlmd.Escape(s[last:m[0]]))
+		last = m[1]
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return s[m[2*i]:m[2*i+1]]
+		}
+		text := htmlmd.Escape(s[m[0]:m[1]])
+		switch {
+		case group(1) != "":
+			b.WriteString(s[m[0]:m[1]])
+		case group(2) != "":
+			b.WriteString(link(text, rfcURL(group(2), "")))
+		case group(3) != "" && group(5)+group(6) != "":
+			fragment := "section-" + group(4)
+			if group(3) == "Appendix" {
+				fragment = "appendix-" + group(4)
+			}
+			b.WriteString(link(text, rfcURL(group(5)+group(6), fragment)))
+		case group(3) != "":
+			if anchor := o.sections[group(4)]; anchor != "" {
+				b.WriteString(link(text, "#"+anchor))
+			} else {
+				b.WriteString(text)
+			}
+		default:
+			b.WriteString(link(text, rfcURL(group(7), "")))
+		}
+	}
+	b.WriteString(htmlmd.Escape(s[last:]))
+	return b.String()
+}
+
+// rfcURL returns the address of an RFC, or of a fragment of it.
+func rfcURL(number, fragment string) string {
+	n, _ := strconv.Atoi(number)
+	u := fmt.Sprintf(URL, n)
+	if fragment != "" {
+		u += "#" + fragment
+	}
+	return u
+}
+
+// paragraphs splits lines into runs of non-blank lines.
+func paragraphs(lines []string) [][]string {
+	var groups [][]string
+	var g []string
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			if g != nil {
+				groups = append(groups, g)
+			}
+			g = nil
+			continue
+		}
+		g = append(g, l)
+	}
+	if g != nil {
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// joinLines joins the lines of a paragraph. Words hyphenated at the end of
+// a line are joined without a space.
+func joinLines(lines []string) string {
+	var b strings.Builder
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if i > 0 {
+			s := b.String()
+			if n := len(s); n < 2 || s[n-1] != '-' || s[n-2] == ' ' {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// escapeStart escapes the start of a paragraph that markdown would take as
+// a heading, a list item, a quote or a thematic break.
+func escapeStart(s string) string {
+	if startPattern.MatchString(s) {
+		if s[0] >= '0' && s[0] <= '9' {
+			i := strings.IndexAny(s, ".)")
+			return s[:i] + `\` + s[i:]
+		}
+		return `\` + s
+	}
+	return s
+}
+
+// endsSentence reports whether a line ends a sentence.
+func endsSentence(line string) bool {
+	line = strings.TrimRight(line, " ")
+	return strings.HasSuffix(line, ".") || strings.HasSuffix(line, ":") ||
+		strings.HasSuffix(line, "?") || strings.HasSuffix(line, "!")
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func minIndent(lines []string) int {
+	n := -1
+	for _, l := range lines {
+		if i := indent(l); n < 0 || i < n {
+			n = i
+		}
+	}
+	return max(n, 0)
+}
+
+func maxWidth(lines []string) int {
+	w := 0
+	for _, l := range lines {
+		w = max(w, len(strings.TrimSpace(l)))
+	}
+	return w
+}
+
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
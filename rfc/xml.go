@@ -0,0 +1,827 @@
+package rfc
+
+import (
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/htmlmd"
+)
+
+var (
+	spacePattern   = regexp.MustCompile(`[ \t\r\n]+`)
+	blankPattern   = regexp.MustCompile(`\n{3,}`)
+	rfcListPattern = regexp.MustCompile(`\d+`)
+)
+
+// Entities defined by the DTD of the RFC format, on top of those of HTML.
+var entities = func() map[string]string {
+	m := maps.Clone(xml.HTMLEntity)
+	m["nbhy"] = "-"
+	m["wj"] = ""
+	m["zwsp"] = ""
+	return m
+}()
+
+// Categories of RFCs, as named on their first page.
+var categories = map[string]string{
+	"std":      "Standards Track",
+	"bcp":      "Best Current Practice",
+	"info":     "Informational",
+	"exp":      "Experimental",
+	"historic": "Historic",
+}
+
+// node is an element of an XML document, or text if it has no name.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+// children returns the children of n, which may be nil.
+func children(n *node) []*node {
+	if n == nil {
+		return nil
+	}
+	return n.children
+}
+
+// child returns the first child element with the given name.
+func (n *node) child(name string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, ch := range n.children {
+		if ch.name == name {
+			return ch
+		}
+	}
+	return nil
+}
+
+// target is something in a document that cross-references point to.
+type target struct {
+	// How references without text of their own refer to it, e.g.
+	// "Section 1.2" or "Figure 3".
+	label  string
+	number string
+	title  string
+	// Anchor of its heading, if it's a section.
+	anchor string
+}
+
+// reference is an entry of the references of a document.
+type reference struct {
+	tag string
+	url string
+	// Number of the RFC it is, if it is one.
+	rfc string
+}
+
+type xmlConverter struct {
+	o *outline
+	// Headings of the sections in the outline.
+	headings map[*node]int
+	// Labels of the figures and tables.
+	captions   map[*node]string
+	targets    map[string]target
+	references map[string]reference
+}
+
+// FromXML converts the XML source of an RFC or Internet-Draft to markdown.
+// Both versions 2 and 3 of the format are understood. Sections are numbered
+// like in the published document, and cross-references link to the
+// sections and references they point to.
+func FromXML(r io.Reader) (string, error) {
+	root, err := parseXML(r)
+	if err != nil {
+		return "", err
+	}
+	c := &xmlConverter{
+		o:          newOutline(),
+		headings:   map[*node]int{},
+		captions:   map[*node]string{},
+		targets:    map[string]target{},
+		references: map[string]reference{},
+	}
+	front, middle, back := root.child("front"), root.child("middle"), root.child("back")
+
+	// Collect the headings and targets first so cross-references can link
+	// to what comes after them.
+	var blocks []string
+	if title := c.title(front); title != "" {
+		c.o.add(1, "", title)
+		blocks = append(blocks, c.o.markdown(0))
+	}
+	if meta := c.meta(root, front); meta != "" {
+		blocks = append(blocks, meta)
+	}
+	var notes []*node
+	for _, ch := range children(front) {
+		if ch.name == "abstract" || ch.name == "note" {
+			c.headings[ch] = len(c.o.headings)
+			c.o.add(2, "", cmp.Or(c.title(ch), "Abstract"))
+			notes = append(notes, ch)
+		}
+	}
+	toc := len(c.o.headings)
+	c.o.add(2, "", "Table of Contents")
+	c.addSections(middle, back)
+	c.addCaptions(root, new(int), new(int))
+	c.addReferences(back)
+
+	for _, n := range notes {
+		blocks = append(blocks, c.section(n))
+	}
+	blocks = append(blocks, c.o.markdown(toc), c.o.toc(toc+1))
+	for _, n := range []*node{middle, back} {
+		if s := c.blocks(n); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	md := strings.Join(blocks, "\n\n")
+	return strings.TrimSpace(blankPattern.ReplaceAllString(md, "\n\n")) + "\n", nil
+}
+
+// parseXML parses an XML document into a tree, returning its rfc element.
+func parseXML(r io.Reader) (*node, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = entities
+	root := &node{}
+	stack := []*node{root}
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse XML: %w", err)
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: map[string]string{}}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &node{text: string(t)})
+		}
+	}
+	rfc := root.child("rfc")
+	if rfc == nil {
+		return nil, errors.New("not an RFC: missing rfc element")
+	}
+	return rfc, nil
+}
+
+// addSections adds the sections of a document to the outline. Sections of
+// the back matter are appendices, except for the references, which are
+// numbered after the sections of the middle.
+func (c *xmlConverter) addSections(middle, back *node) {
+	count, appendices := 0, 0
+	for _, n := range []*node{middle, back} {
+		if n == nil {
+			continue
+		}
+		for _, ch := range n.children {
+			if ch.name != "section" && ch.name != "references" {
+				continue
+			}
+			number := ""
+			switch {
+			case ch.attrs["numbered"] == "false":
+			case ch.name == "section" && n == back:
+				number = string(rune('A' + appendices))
+				appendices++
+			default:
+				count++
+				number = strconv.Itoa(count)
+			}
+			c.addSection(ch, number, 2)
+		}
+	}
+}
+
+// addSection adds a section and its subsections to the outline.
+func (c *xmlConverter) addSection(n *node, number string, level int) {
+	title := c.title(n)
+	if n.name == "references" && title == "" {
+		title = "References"
+	}
+	c.headings[n] = len(c.o.headings)
+	anchor := c.o.add(level, number, title)
+	if id := n.attrs["anchor"]; id != "" {
+		label := "Section " + number
+		if number == "" {
+			label = "\"" + title + "\""
+		} else if number[0] >= 'A' && number[0] <= 'Z' {
+			label = "Appendix " + number
+		}
+		c.targets[id] = target{label: label, number: number, title: title, anchor: anchor}
+	}
+
+	i := 0
+	for _, ch := range n.children {
+		if ch.name != "section" && ch.name != "references" {
+			continue
+		}
+		sub := ""
+		if number != "" && ch.attrs["numbered"] != "false" {
+			i++
+			sub = number + "." + strconv.Itoa(i)
+		}
+		c.addSection(ch, sub, level+1)
+	}
+}
+
+// addCaptions numbers the figures and tables below n.
+func (c *xmlConverter) addCaptions(n *node, figures, tables *int) {
+	for _, ch := range n.children {
+		var label string
+		switch ch.name {
+		case "figure":
+			*figures++
+			label = "Figure " + strconv.Itoa(*figures)
+		case "table", "texttable":
+			*tables++
+			label = "Table " + strconv.Itoa(*tables)
+		}
+		if label != "" {
+			c.captions[ch] = label
+			if id := ch.attrs["anchor"]; id != "" {
+				c.targets[id] = target{label: label}
+			}
+		}
+		c.addCaptions(ch, figures, tables)
+	}
+}
+
+// addReferences collects the entries of the references, with the tags
+// they are displayed with.
+func (c *xmlConverter) addReferences(n *node) {
+	if n == nil {
+		return
+	}
+	for _, ch := range n.children {
+		switch ch.name {
+		case "reference", "referencegroup":
+			ref := reference{tag: ch.attrs["anchor"], url: ch.attrs["target"]}
+			for _, info := range seriesInfo(ch) {
+				switch info.attrs["name"] {
+				case "RFC":
+					ref.rfc = info.attrs["value"]
+					if ref.url == "" {
+						ref.url = rfcURL(ref.rfc, "")
+					}
+				case "Internet-Draft":
+					if ref.url == "" {
+						ref.url = "https://datatracker.ietf.org/doc/html/" + info.attrs["value"]
+					}
+				}
+			}
+			if old, ok := c.references[ch.attrs["anchor"]]; ok {
+				ref.tag = old.tag
+			}
+			c.references[ch.attrs["anchor"]] = ref
+		case "displayreference":
+			ref := c.references[ch.attrs["target"]]
+			ref.tag = ch.attrs["to"]
+			c.references[ch.attrs["target"]] = ref
+		default:
+			c.addReferences(ch)
+		}
+	}
+}
+
+// seriesInfo returns the series a reference belongs to, e.g. RFC 9110.
+func seriesInfo(n *node) []*node {
+	var infos []*node
+	for _, ch := range n.children {
+		switch ch.name {
+		case "seriesInfo":
+			infos = append(infos, ch)
+		case "front":
+			infos = append(infos, seriesInfo(ch)...)
+		}
+	}
+	return infos
+}
+
+// title returns the title of a document or section, from its name or title
+// element, or its title attribute.
+func (c *xmlConverter) title(n *node) string {
+	if n == nil {
+		return ""
+	}
+	for _, name := range []string{"name", "title"} {
+		if ch := n.child(name); ch != nil {
+			return htmlmd.OneLine(text(ch))
+		}
+	}
+	return htmlmd.OneLine(n.attrs["title"])
+}
+
+// meta returns the information about a document under its title: its number
+// or name, category and date, the RFCs it obsoletes or updates, and its
+// authors.
+func (c *xmlConverter) meta(root, front *node) string {
+	var info []string
+	number := root.attrs["number"]
+	name := root.attrs["docName"]
+	for _, s := range seriesInfo(front) {
+		switch s.attrs["name"] {
+		case "RFC":
+			number = s.attrs["value"]
+		case "Internet-Draft":
+			name = s.attrs["value"]
+		}
+	}
+	switch {
+	case number != "":
+		info = append(info, link("RFC "+number, rfcURL(number, "")))
+	case name != "":
+		info = append(info, "Internet-Draft "+htmlmd.Escape(name))
+	}
+	if cat := categories[root.attrs["category"]]; cat != "" {
+		info = append(info, cat)
+	}
+	if d := front.child("date"); d != nil {
+		if date := strings.TrimSpace(d.attrs["month"] + " " + d.attrs["year"]); date != "" {
+			info = append(info, htmlmd.Escape(date))
+		}
+	}
+
+	var lines []string
+	if len(info) > 0 {
+		lines = append(lines, strings.Join(info, " · "))
+	}
+	for _, rel := range []string{"obsoletes", "updates"} {
+		if nums := rfcListPattern.FindAllString(root.attrs[rel], -1); len(nums) > 0 {
+			for i, n := range nums {
+				nums[i] = link(n, rfcURL(n, ""))
+			}
+			lines = append(lines, strings.ToUpper(rel[:1])+rel[1:]+": "+strings.Join(nums, ", "))
+		}
+	}
+	var authors []string
+	for _, a := range children(front) {
+		if a.name != "author" {
+			continue
+		}
+		s := authorName(a)
+		if org := htmlmd.OneLine(text(a.child("organization"))); org != "" {
+			if s == "" {
+				s = org
+			} else {
+				s += " (" + org + ")"
+			}
+		}
+		if s != "" {
+			authors = append(authors, htmlmd.Escape(s))
+		}
+	}
+	if len(authors) > 0 {
+		lines = append(lines, strings.Join(authors, ", "))
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+// authorName returns the name of an author like the RFC Editor writes it,
+// e.g. R. Fielding, Ed.
+func authorName(a *node) string {
+	s := a.attrs["fullname"]
+	if a.attrs["surname"] != "" {
+		s = strings.TrimSpace(a.attrs["initials"] + " " + a.attrs["surname"])
+	}
+	if s != "" && a.attrs["role"] == "editor" {
+		s += ", Ed."
+	}
+	return s
+}
+
+// section converts a section with its heading.
+func (c *xmlConverter) section(n *node) string {
+	parts := []string{c.o.markdown(c.headings[n])}
+	if s := c.blocks(n); s != "" {
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// inlineElements are the elements making up the text of a paragraph.
+var inlineElements = map[string]bool{
+	"xref": true, "relref": true, "eref": true, "em": true, "strong": true,
+	"b": true, "i": true, "tt": true, "code": true, "bcp14": true, "br": true,
+	"vspace": true, "sub": true, "sup": true, "iref": true, "cref": true,
+	"spanx": true, "contact": true, "u": true,
+}
+
+// blocks converts the children of n, separating blocks by blank lines.
+// Consecutive inline content makes up a paragraph.
+func (c *xmlConverter) blocks(n *node) string {
+	if n == nil {
+		return ""
+	}
+	var (
+		parts []string
+		para  strings.Builder
+	)
+	flush := func() {
+		if s := htmlmd.Paragraph(para.String()); s != "" {
+			parts = append(parts, escapeStart(s))
+		}
+		para.Reset()
+	}
+	for _, ch := range n.children {
+		if ch.name == "" || inlineElements[ch.name] {
+			para.WriteString(c.inline(ch))
+			continue
+		}
+		flush()
+		if s := c.block(ch); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	flush()
+	return strings.Join(parts, "\n\n")
+}
+
+// block converts a block element.
+func (c *xmlConverter) block(n *node) string {
+	switch n.name {
+	case "section", "references":
+		return c.section(n)
+	case "ul":
+		return c.list(n, false)
+	case "ol":
+		return c.list(n, true)
+	case "list":
+		switch n.attrs["style"] {
+		case "symbols":
+			return c.list(n, false)
+		case "numbers":
+			return c.list(n, true)
+		}
+		return c.blocks(n)
+	case "dl":
+		return c.definitions(n)
+	case "artset":
+		var art *node
+		for _, ch := range n.children {
+			if ch.name == "artwork" && (art == nil || ch.attrs["type"] == "ascii-art") {
+				art = ch
+			}
+		}
+		if art == nil {
+			return ""
+		}
+		return c.block(art)
+	case "artwork":
+		if n.attrs["type"] == "svg" || n.child("svg") != nil {
+			return ""
+		}
+		return htmlmd.CodeFence(strings.Trim(text(n), "\n"), "")
+	case "sourcecode":
+		lang := n.attrs["type"]
+		if lang == "http-message" {
+			lang = "http"
+		}
+		return htmlmd.CodeFence(strings.Trim(text(n), "\n"), lang)
+	case "figure", "table", "texttable":
+		return c.figure(n)
+	case "blockquote", "aside":
+		return htmlmd.Prefix(c.blocks(n), "> ", "> ")
+	case "reference", "referencegroup":
+		return c.reference(n)
+	case "t":
+		if hang := n.attrs["hangText"]; hang != "" {
+			return "**" + htmlmd.Escape(htmlmd.OneLine(hang)) + "**\\\n" + c.blocks(n)
+		}
+		return c.blocks(n)
+	case "name", "title", "seriesInfo", "author", "date", "area", "workgroup",
+		"keyword", "displayreference", "boilerplate", "toc", "link", "front",
+		"ttcol", "c", "svg":
+		return ""
+	}
+	return c.blocks(n)
+}
+
+// list converts the items of a list.
+func (c *xmlConverter) list(n *node, ordered bool) string {
+	var items []string
+	num := 1
+	if s, err := strconv.Atoi(n.attrs["start"]); err == nil {
+		num = s
+	}
+	for _, li := range n.children {
+		if li.name != "li" && li.name != "t" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		content := c.blocks(li)
+		if content == "" {
+			continue
+		}
+		items = append(items, htmlmd.Prefix(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// definitions converts a definition list. A definition starting with a
+// paragraph is put on the line after its term.
+func (c *xmlConverter) definitions(n *node) string {
+	var parts []string
+	term := false
+	for _, ch := range n.children {
+		switch ch.name {
+		case "dt":
+			if s := htmlmd.OneLine(c.blocks(ch)); s != "" {
+				parts = append(parts, "**"+s+"**")
+				term = true
+			}
+		case "dd":
+			s := c.blocks(ch)
+			if s == "" {
+				continue
+			}
+			if term && !strings.HasPrefix(s, "`") && !strings.HasPrefix(s, "|") && !startPattern.MatchString(s) {
+				parts[len(parts)-1] += "\\\n" + s
+			} else {
+				parts = append(parts, s)
+			}
+			term = false
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// figure converts a figure or table, followed by its caption.
+func (c *xmlConverter) figure(n *node) string {
+	var parts []string
+	switch n.name {
+	case "table":
+		parts = append(parts, c.table(n))
+	case "texttable":
+		parts = append(parts, c.textTable(n))
+	default:
+		if s := c.blocks(n); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	caption := c.captions[n]
+	if title := c.title(n); title != "" {
+		caption += ": " + title
+	}
+	if caption != "" && parts != nil {
+		parts = append(parts, "*"+htmlmd.Escape(caption)+"*")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// table converts a table to a GFM table, its first row being the header.
+func (c *xmlConverter) table(n *node) string {
+	var rows [][]string
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, ch := range n.children {
+			switch ch.name {
+			case "thead", "tbody", "tfoot":
+				walk(ch)
+			case "tr":
+				var row []string
+				for _, cell := range ch.children {
+					if cell.name == "th" || cell.name == "td" {
+						row = append(row, c.cell(cell))
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	walk(n)
+	return htmlmd.Table(rows)
+}
+
+// textTable converts a table of version 2 of the format, which lists its
+// columns, then all its cells.
+func (c *xmlConverter) textTable(n *node) string {
+	var header, cells []string
+	for _, ch := range n.children {
+		switch ch.name {
+		case "ttcol":
+			header = append(header, c.cell(ch))
+		case "c":
+			cells = append(cells, c.cell(ch))
+		}
+	}
+	if len(header) == 0 {
+		return ""
+	}
+	rows := [][]string{header}
+	for i := 0; i < len(cells); i += len(header) {
+		rows = append(rows, cells[i:min(i+len(header), len(cells))])
+	}
+	return htmlmd.Table(rows)
+}
+
+func (c *xmlConverter) cell(n *node) string {
+	return strings.ReplaceAll(htmlmd.OneLine(c.blocks(n)), "|", `\|`)
+}
+
+// reference converts an entry of the references: its tag, then its
+// authors, title, series, date and address.
+func (c *xmlConverter) reference(n *node) string {
+	ref := c.references[n.attrs["anchor"]]
+	var fields []string
+	front := n.child("front")
+	var authors []string
+	for _, a := range children(front) {
+		if a.name != "author" {
+			continue
+		}
+		name := a.attrs["fullname"]
+		if a.attrs["surname"] != "" {
+			name = strings.TrimSpace(a.attrs["surname"] + ", " + a.attrs["initials"])
+		}
+		if name != "" && a.attrs["role"] == "editor" {
+			name += ", Ed."
+		}
+		if name == "" {
+			name = htmlmd.OneLine(text(a.child("organization")))
+		}
+		if name != "" {
+			authors = append(authors, name)
+		}
+	}
+	if len(authors) > 0 {
+		fields = append(fields, strings.Join(authors, ", "))
+	}
+	if title := c.title(front); title != "" {
+		fields = append(fields, "\""+title+"\"")
+	}
+	for _, ch := range n.children {
+		if ch.name == "reference" {
+			fields = append(fields, "\""+c.title(ch.child("front"))+"\"")
+		}
+	}
+	for _, s := range seriesInfo(n) {
+		fields = append(fields, strings.TrimSpace(s.attrs["name"]+" "+s.attrs["value"]))
+	}
+	if d := front.child("date"); d != nil {
+		if date := strings.TrimSpace(d.attrs["month"] + " " + d.attrs["year"]); date != "" {
+			fields = append(fields, date)
+		}
+	}
+
+	s := "**" + htmlmd.Escape("["+cmp.Or(ref.tag, n.attrs["anchor"])+"]") + "** " + htmlmd.Escape(strings.Join(fields, ", "))
+	if ref.url != "" {
+		s += ", <" + ref.url + ">"
+	}
+	return s + "."
+}
+
+// inlines converts the children of n as inline content.
+func (c *xmlConverter) inlines(n *node) string {
+	var b strings.Builder
+	for _, ch := range n.children {
+		b.WriteString(c.inline(ch))
+	}
+	return b.String()
+}
+
+// inline converts an inline node.
+func (c *xmlConverter) inline(n *node) string {
+	switch n.name {
+	case "":
+		return htmlmd.Escape(spacePattern.ReplaceAllString(n.text, " "))
+	case "xref", "relref":
+		return c.xref(n)
+	case "eref":
+		s := strings.TrimSpace(c.inlines(n))
+		if s == "" {
+			return "<" + n.attrs["target"] + ">"
+		}
+		return link(s, n.attrs["target"])
+	case "em", "i":
+		return htmlmd.Wrap(c.inlines(n), "*")
+	case "strong", "b":
+		return htmlmd.Wrap(c.inlines(n), "**")
+	case "tt", "code":
+		return htmlmd.CodeSpan(spacePattern.ReplaceAllString(text(n), " "))
+	case "spanx":
+		switch n.attrs["style"] {
+		case "verb":
+			return htmlmd.CodeSpan(spacePattern.ReplaceAllString(text(n), " "))
+		case "strong":
+			return htmlmd.Wrap(c.inlines(n), "**")
+		}
+		return htmlmd.Wrap(c.inlines(n), "*")
+	case "br", "vspace":
+		return "\\\n"
+	case "iref", "cref":
+		return ""
+	case "contact":
+		return htmlmd.Escape(cmp.Or(n.attrs["fullname"], n.attrs["asciiFullname"]))
+	}
+	return c.inlines(n)
+}
+
+// xref converts a cross-reference to a section, figure or reference,
+// linking to it if possible.
+func (c *xmlConverter) xref(n *node) string {
+	id := n.attrs["target"]
+	content := strings.TrimSpace(c.inlines(n))
+	if section := n.attrs["section"]; section != "" {
+		ref := c.references[id]
+		label := htmlmd.Escape("[" + cmp.Or(ref.tag, id) + "]")
+		kind := "Section"
+		if section[0] >= 'A' && section[0] <= 'Z' {
+			kind = "Appendix"
+		}
+		s := content
+		if s == "" {
+			switch n.attrs["sectionFormat"] {
+			case "comma":
+				s = label + ", " + kind + " " + section
+			case "parens":
+				s = label + " (" + kind + " " + section + ")"
+			case "bare":
+				s = section
+			default:
+				s = kind + " " + section + " of " + label
+			}
+		}
+		u := ref.url
+		switch {
+		case n.attrs["relative"] != "" && u != "":
+			u += n.attrs["relative"]
+		case ref.rfc != "":
+			u = rfcURL(ref.rfc, strings.ToLower(kind)+"-"+section)
+		}
+		if u == "" {
+			return s
+		}
+		return link(s, u)
+	}
+
+	if t, ok := c.targets[id]; ok {
+		s := content
+		if s == "" {
+			switch n.attrs["format"] {
+			case "title":
+				s = htmlmd.Escape(t.title)
+			case "counter":
+				s = t.number
+			default:
+				s = htmlmd.Escape(t.label)
+			}
+		}
+		if t.anchor == "" || s == "" {
+			return s
+		}
+		return link(s, "#"+t.anchor)
+	}
+	if ref, ok := c.references[id]; ok {
+		s := cmp.Or(content, htmlmd.Escape("["+cmp.Or(ref.tag, id)+"]"))
+		if ref.url == "" {
+			return s
+		}
+		return link(s, ref.url)
+	}
+	return cmp.Or(content, htmlmd.Escape(id))
+}
+
+// text returns the text below n.
+func text(n *node) string {
+	if n == nil {
+		return ""
+	}
+	if n.name == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, ch := range n.children {
+		b.WriteString(text(ch))
+	}
+	return b.String()
+}
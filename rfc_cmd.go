@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/rfc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rfcNumberPattern = regexp.MustCompile(`^(?i:rfc)?\s*0*(\d{1,5})$`)
+	draftRevPattern  = regexp.MustCompile(`-\d{2}$`)
+)
+
+var rfcCmd = &cobra.Command{
+	Use:   "rfc NUMBER|DRAFT|FILE",
+	Short: "Render an RFC or Internet-Draft",
+	Long: paragraph(fmt.Sprintf("\n%s an RFC or Internet-Draft, downloaded from the RFC Editor or the IETF. Its sections become headings, references to sections and other RFCs become links, and a table of contents links to the sections. Drafts are rendered at their latest revision unless one is given. Documents are cached like other downloads, and saved XML or text files can be rendered too.",
+		keyword("Render"))),
+	Example: paragraph("glow rfc 9110\nglow rfc draft-ietf-httpbis-cache-groups\nglow rfc rfc9110.xml"),
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := rfcSource(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		defer src.reader.Close() //nolint:errcheck
+		return executeCLI(cmd, src, cmd.OutOrStdout())
+	},
+}
+
+// rfcSource returns the source of an RFC given by number, a draft given by
+// name, or a saved document. RFCs and drafts are downloaded as XML if they
+// were written in it, and as text otherwise.
+func rfcSource(ctx context.Context, arg string) (*source, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file: %w", err)
+		}
+		return rfcMarkdown(content, arg)
+	}
+
+	var base string
+	switch m := rfcNumberPattern.FindStringSubmatch(arg); {
+	case m != nil:
+		n, _ := strconv.Atoi(m[1])
+		base = fmt.Sprintf(rfc.URL, n)
+	case strings.HasPrefix(arg, "draft-"):
+		name := arg
+		if !draftRevPattern.MatchString(name) {
+			rev, err := latestDraftRevision(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			name += "-" + rev
+		}
+		base = "https://www.ietf.org/archive/id/" + name
+	default:
+		return nil, fmt.Errorf("%s is neither an RFC number, a draft nor a file", arg)
+	}
+
+	for _, ext := range []string{".xml", ".txt"} {
+		resp, err := fetch(ctx, base+ext) //nolint:bodyclose
+		if errors.Is(err, errNotCached) && ext == ".xml" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound && ext == ".xml" {
+			_ = resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+		}
+		body := downloadBody(resp)
+		content, err := io.ReadAll(body)
+		_ = body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read http response body: %w", err)
+		}
+		return rfcMarkdown(content, base)
+	}
+	return nil, fmt.Errorf("can't find %s", arg)
+}
+
+// latestDraftRevision looks up the latest revision of a draft on the IETF
+// datatracker.
+func latestDraftRevision(ctx context.Context, name string) (string, error) {
+	var result struct {
+		Rev string `json:"rev"`
+	}
+	u := "https://datatracker.ietf.org/api/v1/doc/document/" + name + "/"
+	if _, err := fetchJSON(ctx, u, &result); err != nil {
+		return "", fmt.Errorf("unable to find draft %s: %w", name, err)
+	}
+	if result.Rev == "" {
+		return "", fmt.Errorf("unable to find draft %s", name)
+	}
+	return result.Rev, nil
+}
+
+// rfcMarkdown converts a document to markdown and returns it as a source.
+func rfcMarkdown(content []byte, u string) (*source, error) {
+	md, err := rfc.Convert(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert document: %w", err)
+	}
+	return &source{reader: io.NopCloser(strings.NewReader(md)), URL: u, markdown: true}, nil
+}
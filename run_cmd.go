@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/douglas-larocca/glow/v2/runbook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runYes bool
+
+	runCmd = &cobra.Command{
+		Use:   "run FILE",
+		Short: "Run the shell blocks of a runbook",
+		Long: paragraph(fmt.Sprintf("\n%s the fenced shell blocks of a document one at a time, picked from a numbered menu. Each block is shown and confirmed before it runs, and its output is kept under it, so runbooks can be followed step by step. Press enter to pick the block after the last one run, and q to quit.",
+			keyword("Run"))),
+		Example:           paragraph("glow run deploy.md\nglow run --yes RELEASING.md"),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeMarkdownFiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := sourceFromArg(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			content, err := readContent(src)
+			_ = src.reader.Close()
+			if err != nil {
+				return err
+			}
+
+			blocks := runbook.Blocks(string(content))
+			if len(blocks) == 0 {
+				return errors.New("document has no shell blocks")
+			}
+			r, _, err := setupRenderer(&source{URL: src.URL, markdown: true})
+			if err != nil {
+				return err
+			}
+			s := &runbookSession{
+				blocks:  blocks,
+				outputs: make([]*blockOutput, len(blocks)),
+				in:      cmd.InOrStdin(),
+				out:     cmd.OutOrStdout(),
+				r:       r,
+			}
+			return s.run(cmd.Context())
+		},
+	}
+)
+
+// blockOutput is what a block printed when it was last run.
+type blockOutput struct {
+	output string
+	err    error
+}
+
+// runbookSession runs the blocks of a runbook picked from a menu.
+type runbookSession struct {
+	blocks  []runbook.Block
+	outputs []*blockOutput
+	// The block picked when none is given.
+	next int
+
+	// The answers to the prompts, then the input of the blocks, read
+	// unbuffered so that the blocks get what the prompts left.
+	in  io.Reader
+	out io.Writer
+	r   *glamour.TermRenderer
+}
+
+// run shows the menu and runs the blocks picked until the session is quit.
+func (s *runbookSession) run(ctx context.Context) error {
+	for {
+		s.printMenu()
+		i, ok, err := s.pick()
+		if err != nil || !ok {
+			return err
+		}
+		if err := s.show(i); err != nil {
+			return err
+		}
+		if !runYes {
+			answer, err := s.ask("Run it? [y/N] ")
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if a := strings.ToLower(answer); a != "y" && a != "yes" {
+				continue
+			}
+		}
+		s.execute(ctx, i)
+		s.next = min(i+1, len(s.blocks)-1)
+	}
+}
+
+// printMenu lists the blocks with their first command, marking those that
+// were run.
+func (s *runbookSession) printMenu() {
+	fmt.Fprintln(s.out) //nolint:errcheck
+	heading := ""
+	for i, b := range s.blocks {
+		if b.Heading != heading {
+			heading = b.Heading
+			fmt.Fprintln(s.out, faint(heading)) //nolint:errcheck
+		}
+		mark := " "
+		switch o := s.outputs[i]; {
+		case o == nil:
+		case o.err != nil:
+			mark = "✗"
+		default:
+			mark = keyword("✓")
+		}
+		fmt.Fprintf(s.out, "%s %s %s\n", mark, keyword(fmt.Sprintf("%*d", len(strconv.Itoa(len(s.blocks))), i+1)), b.Summary()) //nolint:errcheck
+	}
+}
+
+// pick asks which block to run. It reports false once the session is quit.
+func (s *runbookSession) pick() (int, bool, error) {
+	for {
+		answer, err := s.ask(fmt.Sprintf("Run block (1-%d, q to quit) [%d]: ", len(s.blocks), s.next+1))
+		if errors.Is(err, io.EOF) {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		switch answer {
+		case "q", "quit":
+			return 0, false, nil
+		case "":
+			return s.next, true, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(s.blocks) {
+			return n - 1, true, nil
+		}
+		fmt.Fprintf(s.out, "There's no block %q.\n", answer) //nolint:errcheck
+	}
+}
+
+// ask prints a prompt and reads the answer.
+func (s *runbookSession) ask(prompt string) (string, error) {
+	fmt.Fprint(s.out, prompt) //nolint:errcheck
+	line, err := readLine(s.in)
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err //nolint:wrapcheck
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readLine reads a line from r a byte at a time, so that nothing after it is
+// read.
+func readLine(r io.Reader) (string, error) {
+	var (
+		line []byte
+		b    [1]byte
+	)
+	for {
+		n, err := r.Read(b[:])
+		if n > 0 {
+			line = append(line, b[0])
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+		}
+		if err != nil {
+			return string(line), err //nolint:wrapcheck
+		}
+	}
+}
+
+// show renders a block, with its output if it was run before.
+func (s *runbookSession) show(i int) error {
+	b := s.blocks[i]
+	md := codeFence(b.Code, b.Lang)
+	if o := s.outputs[i]; o != nil {
+		md += "\n" + codeFence(o.output, "output")
+	}
+	out, err := renderDocument(s.r, md)
+	if err != nil {
+		return fmt.Errorf("unable to render markdown: %w", err)
+	}
+	_, err = fmt.Fprint(s.out, out)
+	return err //nolint:wrapcheck
+}
+
+// execute runs a block, showing its output as it's printed and keeping it
+// under the block.
+func (s *runbookSession) execute(ctx context.Context, i int) {
+	b := s.blocks[i]
+	shell := b.Shell()
+	if shell == "" {
+		shell = os.Getenv("SHELL")
+	}
+	if shell == "" {
+		shell = "sh"
+	}
+
+	// Interrupting a command shouldn't quit the runbook too.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, shell, "-c", b.Script()) //nolint:gosec
+	cmd.Stdin = s.in
+	cmd.Stdout = io.MultiWriter(s.out, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+
+	start := time.Now()
+	err := cmd.Run()
+	s.outputs[i] = &blockOutput{output: output.String(), err: err}
+	if err != nil {
+		fmt.Fprintln(s.out, "✗", err) //nolint:errcheck
+		return
+	}
+	fmt.Fprintln(s.out, keyword("✓"), faint(time.Since(start).Round(time.Millisecond).String())) //nolint:errcheck
+}
+
+// codeFence wraps code in a fence longer than any line of backticks in it.
+func codeFence(code, lang string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if code != "" && !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	return fence + lang + "\n" + code + fence + "\n"
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/douglas-larocca/glow/v2/runbook"
+)
+
+func TestRunbookPick(t *testing.T) {
+	var out bytes.Buffer
+	s := &runbookSession{
+		blocks: make([]runbook.Block, 3),
+		in:     strings.NewReader("4\nx\n2\n\nq\n"),
+		out:    &out,
+		next:   2,
+	}
+	for _, want := range []int{1, 2} {
+		i, ok, err := s.pick()
+		if err != nil || !ok || i != want {
+			t.Fatalf("expected block %d, got %d, %v, %v", want, i, ok, err)
+		}
+	}
+	if _, ok, err := s.pick(); ok || err != nil {
+		t.Errorf("expected q to quit, got %v, %v", ok, err)
+	}
+	if _, ok, err := s.pick(); ok || err != nil {
+		t.Errorf("expected the end of the input to quit, got %v, %v", ok, err)
+	}
+	if got := strings.Count(out.String(), "There's no block"); got != 2 {
+		t.Errorf("expected 2 invalid answers, got %d:\n%s", got, out.String())
+	}
+}
+
+func TestRunbookExecute(t *testing.T) {
+	var out bytes.Buffer
+	s := &runbookSession{
+		blocks: []runbook.Block{
+			{Lang: "sh", Code: "echo hello"},
+			{Lang: "sh", Code: "exit 3"},
+		},
+		outputs: make([]*blockOutput, 2),
+		out:     &out,
+	}
+	s.execute(context.Background(), 0)
+	s.execute(context.Background(), 1)
+	if o := s.outputs[0]; o == nil || o.err != nil || o.output != "hello\n" {
+		t.Errorf("expected the output to be kept, got %+v", o)
+	}
+	if o := s.outputs[1]; o == nil || o.err == nil {
+		t.Errorf("expected the failure to be kept, got %+v", o)
+	}
+	if !strings.HasPrefix(out.String(), "hello\n") {
+		t.Errorf("expected the output to be shown, got %q", out.String())
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	for _, tc := range []struct{ code, want string }{
+		{"ls", "```sh\nls\n```\n"},
+		{"echo '```'\n", "````sh\necho '```'\n````\n"},
+	} {
+		if got := codeFence(tc.code, "sh"); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.code, tc.want, got)
+		}
+	}
+}
+
+func TestRunbookInput(t *testing.T) {
+	// The prompts and the blocks share the input, each reading its own
+	// lines.
+	in, err := os.CreateTemp(t.TempDir(), "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close() //nolint:errcheck
+	if _, err := in.WriteString("y\nfor the block\nq\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	s := &runbookSession{
+		blocks:  []runbook.Block{{Lang: "sh", Code: `read line; echo "got $line"`}},
+		outputs: make([]*blockOutput, 1),
+		in:      in,
+		out:     &out,
+	}
+	if answer, err := s.ask("Run it? "); err != nil || answer != "y" {
+		t.Fatalf("expected y, got %q, %v", answer, err)
+	}
+	s.execute(context.Background(), 0)
+	if o := s.outputs[0]; o == nil || o.output != "got for the block\n" {
+		t.Errorf("expected the block to read its line, got %+v", o)
+	}
+	if answer, err := s.ask("Run block: "); err != nil || answer != "q" {
+		t.Errorf("expected q after the block's input, got %q, %v", answer, err)
+	}
+}
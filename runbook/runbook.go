@@ -0,0 +1,119 @@
+// Package runbook finds the shell commands of markdown runbooks, documents
+// whose fenced shell blocks are meant to be run one after the other.
+package runbook
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fencePattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s{]*)")
+	headingPattern = regexp.MustCompile(`^ {0,3}#{1,6}[ \t]+(.*?)[ \t#]*$`)
+	promptPattern  = regexp.MustCompile(`^[ \t]*[$%#][ \t]`)
+)
+
+// shells maps the languages of shell blocks to the shell they're run with,
+// or "" for the user's shell.
+var shells = map[string]string{
+	"sh":            "sh",
+	"bash":          "bash",
+	"zsh":           "zsh",
+	"fish":          "fish",
+	"shell":         "",
+	"console":       "",
+	"shell-session": "",
+	"shellsession":  "",
+}
+
+// Block is a fenced shell block of a runbook.
+type Block struct {
+	// Language of the fence, e.g. bash.
+	Lang string
+	// Content of the block, as written.
+	Code string
+	// Text of the heading the block is under, if any.
+	Heading string
+	// Line of the opening fence, counted from 1.
+	Line int
+}
+
+// Blocks returns the fenced shell blocks of a document, in order.
+func Blocks(markdown string) []Block {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var (
+		blocks  []Block
+		heading string
+		fence   string
+		current *Block
+		code    []string
+	)
+	for i, l := range lines {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(l), fence) && strings.Trim(strings.TrimSpace(l), fence[:1]) == "" {
+				if current != nil {
+					current.Code = strings.Join(code, "\n")
+					blocks = append(blocks, *current)
+				}
+				fence, current, code = "", nil, nil
+				continue
+			}
+			code = append(code, l)
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(l); m != nil {
+			fence = m[1]
+			lang := strings.ToLower(m[2])
+			if _, ok := shells[lang]; ok {
+				current = &Block{Lang: lang, Heading: heading, Line: i + 1}
+			}
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(l); m != nil {
+			heading = m[1]
+		}
+	}
+	return blocks
+}
+
+// Shell returns the shell the block is run with, or "" for the user's shell.
+func (b Block) Shell() string {
+	return shells[b.Lang]
+}
+
+// Script returns the commands of the block. Blocks of terminal sessions keep
+// only their prompted lines, without the prompts, and leave out the output
+// shown below them.
+func (b Block) Script() string {
+	if b.Lang != "console" && b.Lang != "shell-session" && b.Lang != "shellsession" {
+		return b.Code
+	}
+	var cmds []string
+	for _, l := range strings.Split(b.Code, "\n") {
+		if loc := promptPattern.FindStringIndex(l); loc != nil {
+			cmds = append(cmds, l[loc[1]:])
+		}
+	}
+	if len(cmds) == 0 {
+		return b.Code
+	}
+	return strings.Join(cmds, "\n")
+}
+
+// Summary returns the first command of the block, to tell it apart from the
+// others, or its first line if it only has comments.
+func (b Block) Summary() string {
+	first := ""
+	for _, l := range strings.Split(b.Script(), "\n") {
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "":
+		case !strings.HasPrefix(l, "#"):
+			return l
+		case first == "":
+			first = l
+		}
+	}
+	return first
+}
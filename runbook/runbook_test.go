@@ -0,0 +1,46 @@
+package runbook
+
+import (
+	"reflect"
+	"testing"
+)
+
+const doc = "# Deploy\n\n```bash\nmake build\n```\n\n## Check\n\n" +
+	"```go\nfmt.Println()\n```\n\n" +
+	"````console\n$ curl localhost\nok\n```\n% echo done\n````\n\n" +
+	"~~~sh\n# only a comment\n~~~\n\n```\nnot shell\n```\n"
+
+func TestBlocks(t *testing.T) {
+	want := []Block{
+		{Lang: "bash", Code: "make build", Heading: "Deploy", Line: 3},
+		{Lang: "console", Code: "$ curl localhost\nok\n```\n% echo done", Heading: "Check", Line: 13},
+		{Lang: "sh", Code: "# only a comment", Heading: "Check", Line: 20},
+	}
+	if got := Blocks(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestScript(t *testing.T) {
+	for _, tc := range []struct {
+		block   Block
+		script  string
+		summary string
+		shell   string
+	}{
+		{Block{Lang: "bash", Code: "# build\nmake build\nmake test"}, "# build\nmake build\nmake test", "make build", "bash"},
+		{Block{Lang: "console", Code: "$ curl localhost\nok\n% echo done"}, "curl localhost\necho done", "curl localhost", ""},
+		{Block{Lang: "console", Code: "ls -l"}, "ls -l", "ls -l", ""},
+		{Block{Lang: "sh", Code: "\n# only a comment\n"}, "\n# only a comment\n", "# only a comment", "sh"},
+	} {
+		if got := tc.block.Script(); got != tc.script {
+			t.Errorf("%q: expected script %q, got %q", tc.block.Code, tc.script, got)
+		}
+		if got := tc.block.Summary(); got != tc.summary {
+			t.Errorf("%q: expected summary %q, got %q", tc.block.Code, tc.summary, got)
+		}
+		if got := tc.block.Shell(); got != tc.shell {
+			t.Errorf("%q: expected shell %q, got %q", tc.block.Code, tc.shell, got)
+		}
+	}
+}
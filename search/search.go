@@ -0,0 +1,155 @@
+// Package search finds the blocks of markdown documents matching a pattern,
+// along with the headings they're under.
+package search
+
+import (
+	"regexp"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// Match is a block of a document with lines matching the pattern: a
+// paragraph, list, heading or code block. Lines count from 1.
+type Match struct {
+	// First line of the block, and the lines that matched.
+	Line  int   `json:"line"`
+	Lines []int `json:"lines"`
+	// Headings the block is under, from the top level down.
+	Headings []string `json:"headings,omitempty"`
+	// Markdown source of the block.
+	Block string `json:"block"`
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextPattern  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fencePattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// block is a run of lines of a document.
+type block struct {
+	start, end int // indexes of the first line and the one after the last
+	heading    int // level if the block is a heading, otherwise 0
+	title      string
+}
+
+// Find returns the blocks of a markdown document with lines matching re, in
+// the order of the document. Frontmatter should be replaced with blank lines
+// beforehand to keep the line numbers of the body.
+func Find(markdown string, re *regexp.Regexp) []Match {
+	lines := strings.Split(markdown, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+
+	var (
+		matches  []Match
+		headings []string
+	)
+	for _, b := range blocks(lines) {
+		var matched []int
+		for i := b.start; i < b.end; i++ {
+			if re.MatchString(lines[i]) {
+				matched = append(matched, i+1)
+			}
+		}
+		if len(matched) > 0 {
+			matches = append(matches, Match{
+				Line:     b.start + 1,
+				Lines:    matched,
+				Headings: append([]string(nil), headings...),
+				Block:    strings.Join(lines[b.start:b.end], "\n"),
+			})
+		}
+		if b.heading > 0 {
+			headings = append(headings[:min(len(headings), b.heading-1)], b.title)
+		}
+	}
+	return matches
+}
+
+// blocks splits the lines of a document into blocks separated by blank
+// lines. Fenced code blocks and headings are blocks of their own.
+func blocks(lines []string) []block {
+	var (
+		out   []block
+		start = -1
+	)
+	flush := func(end int) {
+		if start >= 0 {
+			out = append(out, block{start: start, end: end})
+			start = -1
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			flush(i)
+			end := i + 1
+			for ; end < len(lines); end++ {
+				closing := strings.TrimSpace(lines[end])
+				if strings.HasPrefix(closing, m[1]) && strings.Trim(closing, m[1][:1]) == "" {
+					break
+				}
+			}
+			end = min(end+1, len(lines))
+			out = append(out, block{start: i, end: end})
+			i = end - 1
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			flush(i)
+			out = append(out, block{start: i, end: i + 1, heading: len(m[1]), title: strings.TrimSpace(m[2])})
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush(i)
+			continue
+		}
+		// A single line of text underlined with = or - is a heading.
+		if start == i-1 && start >= 0 && !strings.HasPrefix(strings.TrimSpace(lines[start]), "-") {
+			if m := setextPattern.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				out = append(out, block{start: start, end: i + 1, heading: level, title: strings.TrimSpace(lines[start])})
+				start = -1
+				continue
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(lines))
+	return out
+}
+
+// Highlight applies style to the text matching re in a line of rendered
+// output, keeping the escapes of the rest of the line.
+func Highlight(line string, re *regexp.Regexp, style func(string) string) string {
+	plain := xansi.Strip(line)
+	locs := re.FindAllStringIndex(plain, -1)
+	if len(locs) == 0 {
+		return line
+	}
+
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		start := xansi.StringWidth(plain[:loc[0]])
+		end := start + xansi.StringWidth(plain[loc[0]:loc[1]])
+		b.WriteString(xansi.Cut(line, last, start))
+		b.WriteString(style(plain[loc[0]:loc[1]]))
+		last = end
+	}
+	b.WriteString(xansi.TruncateLeft(line, last, ""))
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const doc = "# Intro\n\n" +
+	"Some text about install.\n\n" +
+	"Setup\n-----\n\n" +
+	"- run `go install`\n- done\n\n" +
+	"### Source\n\n" +
+	"```sh\n# install\ngo install ./...\n```\n\n" +
+	"# Usage\n\nNothing to install.\r\n"
+
+func TestFind(t *testing.T) {
+	got := Find(doc, regexp.MustCompile("install"))
+	want := []Match{
+		{Line: 3, Lines: []int{3}, Headings: []string{"Intro"}, Block: "Some text about install."},
+		{Line: 8, Lines: []int{8}, Headings: []string{"Intro", "Setup"}, Block: "- run `go install`\n- done"},
+		{Line: 13, Lines: []int{14, 15}, Headings: []string{"Intro", "Setup", "Source"}, Block: "```sh\n# install\ngo install ./...\n```"},
+		{Line: 20, Lines: []int{20}, Headings: []string{"Usage"}, Block: "Nothing to install."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%+v\ngot\n%+v", want, got)
+	}
+
+	if got := Find(doc, regexp.MustCompile("Source")); len(got) != 1 || got[0].Line != 11 {
+		t.Errorf("expected the heading to match, got %+v", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	re := regexp.MustCompile("b+")
+	mark := func(s string) string { return "[" + s + "]" }
+
+	line := "\x1b[1mabba\x1b[0m cab"
+	got := Highlight(line, re, mark)
+	if xansi.Strip(got) != "a[bb]a ca[b]" {
+		t.Errorf("expected the matches to be marked, got %q", got)
+	}
+	if got := Highlight(line, regexp.MustCompile("x"), mark); got != line {
+		t.Errorf("expected the line to be kept, got %q", got)
+	}
+}
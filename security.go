@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// securityPolicy is which kinds of sources glow may read documents from, set
+// in the security section of the config file. Scripts passing untrusted
+// arguments to glow can rule out those they don't expect.
+type securityPolicy struct {
+	// Fetching anything over the network, including documents, forge
+	// READMEs and images.
+	network bool
+	// Arguments given as file:// URIs.
+	fileURIs bool
+	// Documents at git refs, e.g. HEAD~3:README.md.
+	gitRefs bool
+	// Arguments naming local files and directories, including file URIs.
+	localFiles bool
+}
+
+// security is the policy in effect. Everything is allowed unless the config
+// or flags say otherwise.
+var security = securityPolicy{network: true, fileURIs: true, gitRefs: true, localFiles: true}
+
+// securityKeys are the settings of the security section, with the flags
+// taking precedence over them, if any.
+var securityKeys = map[string]string{
+	"allowNetwork":    "allow-network",
+	"allowFileUris":   "allow-file-uris",
+	"allowGitRefs":    "",
+	"allowLocalFiles": "",
+}
+
+// errNotAllowed is returned for sources the security policy rules out.
+var errNotAllowed = errors.New("not allowed by the security policy")
+
+// readSecurityPolicy reads the policy from the config and flags.
+func readSecurityPolicy() securityPolicy {
+	return securityPolicy{
+		network:    viper.GetBool("security.allowNetwork"),
+		fileURIs:   viper.GetBool("security.allowFileUris"),
+		gitRefs:    viper.GetBool("security.allowGitRefs"),
+		localFiles: viper.GetBool("security.allowLocalFiles"),
+	}
+}
+
+// disallowed returns the error for a kind of source ruled out by the
+// setting key of the security section.
+func disallowed(what, key string) error {
+	return fmt.Errorf("%s %w (security.%s)", what, errNotAllowed, key)
+}
+
+// checkLocalFiles returns an error if the policy rules out reading local
+// files and directories.
+func checkLocalFiles() error {
+	if !security.localFiles {
+		return disallowed("local files are", "allowLocalFiles")
+	}
+	return nil
+}
+
+// checkSecuritySection reports unknown settings and values that aren't true
+// or false in the security section of the config file.
+func checkSecuritySection(section any) error {
+	values, ok := section.(map[string]any)
+	if !ok && section != nil {
+		return errors.New("security must be a map of settings")
+	}
+	for key, value := range values {
+		if !slices.ContainsFunc(slices.Collect(maps.Keys(securityKeys)), func(k string) bool { return strings.EqualFold(k, key) }) {
+			return fmt.Errorf("unknown setting %q in security, expected allowNetwork, allowFileUris, allowGitRefs or allowLocalFiles", key)
+		}
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("security.%s must be true or false, got %v", key, value)
+		}
+	}
+	return nil
+}
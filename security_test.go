@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSecurityPolicy(t *testing.T) {
+	defer func(p securityPolicy) { security = p }(security)
+
+	path := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(path, []byte("# Doc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	security = securityPolicy{network: true, fileURIs: true, gitRefs: true, localFiles: true}
+	src, err := sourceFromArg(context.Background(), uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(src.reader); string(b) != "# Doc\n" || src.URL != path {
+		t.Errorf("expected the file URI to open %s, got %q from %s", path, b, src.URL)
+	}
+	src.reader.Close() //nolint:errcheck,gosec
+
+	for _, tt := range []struct {
+		policy securityPolicy
+		arg    string
+	}{
+		{securityPolicy{fileURIs: true, localFiles: true}, "https://example.com/README.md"},
+		{securityPolicy{network: true, localFiles: true}, uri},
+		{securityPolicy{network: true, fileURIs: true}, uri},
+		{securityPolicy{network: true, fileURIs: true}, path},
+		{securityPolicy{network: true, localFiles: true}, "HEAD:README.md"},
+	} {
+		security = tt.policy
+		if _, err := sourceFromArg(context.Background(), tt.arg); !errors.Is(err, errNotAllowed) {
+			t.Errorf("%s with %+v: expected it not to be allowed, got %v", tt.arg, tt.policy, err)
+		}
+	}
+}
+
+func TestSecurityPolicyLocalArguments(t *testing.T) {
+	defer func(p securityPolicy, r, tabs bool, out string, stdin *os.File) {
+		security, recursive, tui, outputFile, os.Stdin = p, r, tabs, out, stdin
+	}(security, recursive, tui, outputFile, os.Stdin)
+
+	// Nothing is piped to glow.
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close() //nolint:errcheck
+	os.Stdin = null
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(path, []byte("# Doc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	security = securityPolicy{network: true, fileURIs: true, gitRefs: true}
+	outputFile = ""
+	for _, tt := range []struct {
+		name      string
+		recursive bool
+		tui       bool
+		args      []string
+	}{
+		{"directory", false, false, []string{dir}},
+		{"recursive", true, false, []string{dir}},
+		{"tabs", false, true, []string{path, path}},
+	} {
+		recursive, tui = tt.recursive, tt.tui
+		if err := executeTo(&cobra.Command{}, tt.args, io.Discard); !errors.Is(err, errNotAllowed) {
+			t.Errorf("%s: expected local files not to be allowed, got %v", tt.name, err)
+		}
+	}
+}
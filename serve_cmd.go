@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+const liveReloadPath = "/_glow/livereload"
+
+var (
+	serveAddr string
+
+	serveCmd = &cobra.Command{
+		Use:   "serve [DIR]",
+		Short: "Preview markdown in a browser",
+		Long: paragraph(fmt.Sprintf("\n%s a directory of markdown over HTTP, styled with the current glow style. "+
+			"Pages reload automatically when files change.", keyword("Serve"))),
+		Example: paragraph("glow serve\nglow serve docs --addr localhost:8080"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return serve(dir, serveAddr)
+		},
+	}
+)
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+nav { position: fixed; top: 0; bottom: 0; left: 0; width: 16em; overflow-y: auto; padding: 1em; box-sizing: border-box; font-size: .9em; }
+nav ul { list-style: none; padding: 0; margin: 0; }
+nav li { margin: .25em 0; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+nav a.current { font-weight: bold; }
+main { margin-left: 16em; padding: 1em 2em; max-width: 50em; }
+{{.CSS}}
+</style>
+</head>
+<body>
+<nav><ul>
+{{range .Files}}<li><a href="/{{.}}"{{if eq . $.Current}} class="current"{{end}}>{{.}}</a></li>
+{{end}}</ul></nav>
+<main>{{.Body}}</main>
+<script>
+(function() {
+  var proto = location.protocol === "https:" ? "wss://" : "ws://";
+  function connect() {
+    var ws = new WebSocket(proto + location.host + "{{.LiveReload}}");
+    ws.onmessage = function() { location.reload(); };
+    ws.onclose = function() { setTimeout(connect, 1000); };
+  }
+  connect();
+})();
+</script>
+</body>
+</html>
+`))
+
+type page struct {
+	Title      string
+	CSS        template.CSS
+	Files      []string
+	Current    string
+	Body       template.HTML
+	LiveReload string
+}
+
+// previewServer serves rendered markdown from a directory.
+type previewServer struct {
+	root   string
+	addr   string
+	css    string
+	md     goldmark.Markdown
+	reload *liveReload
+}
+
+func serve(dir, addr string) error {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to get absolute path: %w", err)
+	}
+	if st, err := os.Stat(root); err != nil || !st.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+
+	styleConfig, err := utils.StyleConfig(style)
+	if err != nil {
+		return err
+	}
+
+	reload, err := newLiveReload(root)
+	if err != nil {
+		return err
+	}
+	defer reload.Close() //nolint:errcheck
+
+	s := &previewServer{
+		root:   root,
+		addr:   addr,
+		css:    styleCSS(styleConfig),
+		reload: reload,
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Printf("Serving %s on http://%s\n", root, addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("unable to serve: %w", err)
+	}
+	return nil
+}
+
+func (s *previewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, "unexpected host", http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !s.allowedHost(u.Host) {
+			http.Error(w, "unexpected origin", http.StatusForbidden)
+			return
+		}
+	}
+	if r.URL.Path == liveReloadPath {
+		s.reload.ServeHTTP(w, r)
+		return
+	}
+
+	rel := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if hiddenPath(rel) {
+		http.NotFound(w, r)
+		return
+	}
+	p := filepath.Join(s.root, filepath.FromSlash(rel))
+
+	st, err := os.Stat(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if st.IsDir() {
+		if readme := findReadme(p); readme != "" {
+			s.renderPage(w, readme)
+			return
+		}
+		s.renderIndex(w, rel)
+		return
+	}
+	if utils.IsMarkdownFile(p) && filepath.Ext(p) != "" {
+		s.renderPage(w, p)
+		return
+	}
+	http.ServeFile(w, r, p)
+}
+
+func (s *previewServer) renderPage(w http.ResponseWriter, p string) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := s.md.Convert(utils.RemoveFrontmatter(b), &body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rel, _ := filepath.Rel(s.root, p)
+	s.write(w, page{
+		Title:   filepath.Base(p),
+		Current: filepath.ToSlash(rel),
+		Body:    template.HTML(body.String()), //nolint:gosec
+	})
+}
+
+func (s *previewServer) renderIndex(w http.ResponseWriter, rel string) {
+	title := rel
+	if title == "" {
+		title = filepath.Base(s.root)
+	}
+	s.write(w, page{
+		Title: title,
+		Body:  template.HTML("<p>Select a document from the list.</p>"),
+	})
+}
+
+func (s *previewServer) write(w http.ResponseWriter, p page) {
+	p.CSS = template.CSS(s.css) //nolint:gosec
+	p.Files = markdownFiles(s.root)
+	p.LiveReload = liveReloadPath
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, p); err != nil {
+		log.Error("unable to render page", "error", err)
+	}
+}
+
+// findReadme returns the path of the first README in dir.
+func findReadme(dir string) string {
+	for _, v := range readmeNames {
+		p := filepath.Join(dir, v)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// docIndexName is the name of the index of a documentation directory.
+const docIndexName = "index.md"
+
+// findDocIndex returns the path of the document shown for dir: its README,
+// or else the index or README of the first of its documentation directories
+// that has one.
+func findDocIndex(dir string) string {
+	if readme := findReadme(dir); readme != "" {
+		return readme
+	}
+	for _, root := range docRoots {
+		root = filepath.Join(dir, root)
+		p := filepath.Join(root, docIndexName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p
+		}
+		if readme := findReadme(root); readme != "" {
+			return readme
+		}
+	}
+	return ""
+}
+
+// markdownFiles lists all markdown files below root, skipping hidden
+// directories.
+func markdownFiles(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if d.IsDir() {
+			if p != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != "" && utils.IsMarkdownFile(p) {
+			rel, _ := filepath.Rel(root, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules"
+}
+
+// hiddenPath reports whether a path relative to the root is in a directory
+// markdownFiles skips, or is a hidden file, like .env.
+func hiddenPath(rel string) bool {
+	for _, name := range strings.Split(rel, "/") {
+		if name != "" && skipDir(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedHost reports whether host, as a request or its origin names it, is
+// the address the server listens on, or the loopback address on its port.
+// Pages of other sites are refused, even once their names resolve to the
+// loopback address.
+func (s *previewServer) allowedHost(host string) bool {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, "80"
+	}
+	boundName, boundPort, err := net.SplitHostPort(s.addr)
+	if err != nil || port != boundPort {
+		return false
+	}
+	name = strings.Trim(name, "[]")
+	if name == "localhost" || name == boundName {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebsocketAccept(t *testing.T) {
+	// Example from RFC 6455, section 1.3.
+	if got := websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key: %s", got)
+	}
+}
+
+func TestCSSColor(t *testing.T) {
+	tt := map[string]string{
+		"1":       "#800000",
+		"63":      "#5f5fff",
+		"234":     "#1c1c1c",
+		"#abcdef": "#abcdef",
+	}
+	for in, want := range tt {
+		if got := cssColor(in); got != want {
+			t.Errorf("cssColor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindDocIndex(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"doc/README.md", "docs/index.md", "wiki/Home.md"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { docRoots = nil })
+
+	for _, tc := range []struct {
+		roots []string
+		want  string
+	}{
+		{defaultDocRoots, "docs/index.md"},
+		{[]string{"doc", "docs"}, "doc/README.md"},
+		{[]string{"wiki"}, ""},
+		{nil, ""},
+	} {
+		docRoots = tc.roots
+		want := tc.want
+		if want != "" {
+			want = filepath.Join(dir, filepath.FromSlash(want))
+		}
+		if got := findDocIndex(dir); got != want {
+			t.Errorf("%v: expected %q, got %q", tc.roots, want, got)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	docRoots = defaultDocRoots
+	if got, want := findDocIndex(dir), filepath.Join(dir, "README.md"); got != want {
+		t.Errorf("expected the README to be preferred, got %q", got)
+	}
+}
+
+func TestPreviewServerRefuses(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".env", ".git/config", "notes.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("secret"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &previewServer{root: dir, addr: "localhost:6419"}
+
+	for _, tc := range []struct {
+		path, host, origin string
+		want               int
+	}{
+		{"/notes.txt", "localhost:6419", "", http.StatusOK},
+		{"/notes.txt", "127.0.0.1:6419", "http://localhost:6419", http.StatusOK},
+		{"/notes.txt", "[::1]:6419", "", http.StatusOK},
+		{"/.env", "localhost:6419", "", http.StatusNotFound},
+		{"/.git/config", "localhost:6419", "", http.StatusNotFound},
+		{"/notes.txt", "evil.example:6419", "", http.StatusForbidden},
+		{"/notes.txt", "localhost:8080", "", http.StatusForbidden},
+		{"/notes.txt", "localhost:6419", "http://evil.example:6419", http.StatusForbidden},
+		{liveReloadPath, "localhost:6419", "http://evil.example", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Host = tc.host
+		if tc.origin != "" {
+			r.Header.Set("Origin", tc.origin)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s from %s (origin %q): expected status %d, got %d", tc.path, tc.host, tc.origin, tc.want, w.Code)
+		}
+	}
+}
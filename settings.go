@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/douglas-larocca/glow/v2/frontmatter"
+	"github.com/douglas-larocca/glow/v2/images"
+	"github.com/douglas-larocca/glow/v2/latex"
+	"github.com/douglas-larocca/glow/v2/links"
+	"github.com/douglas-larocca/glow/v2/mermaid"
+	"github.com/douglas-larocca/glow/v2/passthrough"
+	"github.com/douglas-larocca/glow/v2/stash"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/douglas-larocca/glow/v2/tables"
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"gopkg.in/yaml.v3"
+)
+
+// settingKind is the type of the values of a setting.
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindBool
+	kindUint
+	kindInt
+	// Lists are written comma separated on the command line.
+	kindList
+	// Maps, like custom keys, are only changed by editing the config file.
+	kindMap
+)
+
+func (k settingKind) String() string {
+	return [...]string{"a string", "true or false", "a positive number", "a number", "a list", "a map"}[k]
+}
+
+// setting is a key of the config file.
+type setting struct {
+	key string
+	// flag taking precedence over the setting, if any.
+	flag string
+	kind settingKind
+	// check reports invalid values, if the kind of the setting doesn't
+	// already rule them out.
+	check func(string) error
+}
+
+// settings are the keys the config file can set.
+var settings = []setting{
+	{"style", "style", kindString, validateStyle},
+	{"mouse", "mouse", kindBool, nil},
+	{"pager", "pager", kindBool, nil},
+	{"tui", "tui", kindBool, nil},
+	{"width", "width", kindUint, nil},
+	{"maxWidth", "max-width", kindUint, nil},
+	{"margin", "margin", kindUint, nil},
+	{"center", "center", kindBool, nil},
+	{"all", "all", kindBool, nil},
+	{"showLineNumbers", "line-numbers", kindBool, nil},
+	{"preserveNewLines", "preserve-new-lines", kindBool, nil},
+	{"stats", "stats", kindBool, nil},
+	{"noResume", "no-resume", kindBool, nil},
+	{"historySize", "", kindUint, nil},
+	{"readonly", "readonly", kindBool, nil},
+	{"fold", "fold", kindBool, nil},
+	{"recursive", "recursive", kindBool, nil},
+	{"forceTTY", "force-tty", kindBool, nil},
+	{"readmeNames", "", kindList, nil},
+	{"docRoots", "", kindList, nil},
+	{"stashRecipients", "", kindList, func(s string) error {
+		_, err := stash.ParseRecipients(strings.Fields(strings.ReplaceAll(s, ",", " ")))
+		return err
+	}},
+	{"stashIdentity", "", kindString, nil},
+	{"spinner", "spinner", kindString, func(s string) error {
+		if err := loadCustomSpinners(); err != nil {
+			return err
+		}
+		return validateSpinner(s, "#ffffff")
+	}},
+	{"spinnerColor", "spinner-color", kindString, func(s string) error {
+		return validateSpinner(string(stream.SpinnerBouncingBall), s)
+	}},
+	{"spinners", "", kindMap, nil},
+	{"timeout", "timeout", kindString, func(s string) error {
+		_, err := time.ParseDuration(s)
+		return err
+	}},
+	{"retries", "retries", kindInt, func(s string) error {
+		if n, _ := strconv.Atoi(s); n < 0 {
+			return errors.New("retries can't be negative")
+		}
+		return nil
+	}},
+	{"maxDownload", "max-download", kindString, func(s string) error {
+		_, err := parseMaxDownload(s)
+		return err
+	}},
+	{"maxSize", "max-size", kindString, func(s string) error {
+		_, err := parseMaxSize(s)
+		return err
+	}},
+	{"offline", "offline", kindBool, nil},
+	{"security", "", kindMap, nil},
+	{"chromaTheme", "chroma-theme", kindString, validateChromaTheme},
+	{"codeThemes", "code-theme", kindMap, nil},
+	{"mermaid", "mermaid", kindString, func(s string) error {
+		_, err := mermaid.ParseMode(s)
+		return err
+	}},
+	{"math", "math", kindString, func(s string) error {
+		_, err := latex.ParseMode(s)
+		return err
+	}},
+	{"wideTables", "wide-tables", kindString, func(s string) error {
+		_, err := tables.ParseMode(s)
+		return err
+	}},
+	{"rawAnsi", "raw-ansi", kindString, func(s string) error {
+		_, err := passthrough.ParseMode(s)
+		return err
+	}},
+	{"images", "images", kindString, func(s string) error {
+		_, err := images.ParseMode(s)
+		return err
+	}},
+	{"frontmatter", "frontmatter", kindString, func(s string) error {
+		_, err := frontmatter.ParseMode(s)
+		return err
+	}},
+	{"links", "links", kindString, func(s string) error {
+		_, err := links.ParseMode(s)
+		return err
+	}},
+	{"hyperlinks", "hyperlinks", kindString, validateHyperlinks},
+	{"stream", "stream", kindString, validateStreamMode},
+	{"stdinMode", "stdin-mode", kindString, validateStdinMode},
+	{"streamGranularity", "stream-granularity", kindString, func(s string) error {
+		_, err := stream.ParseGranularity(s)
+		return err
+	}},
+	{"streamScreen", "stream-screen", kindString, func(s string) error {
+		_, err := stream.ParseScreen(s)
+		return err
+	}},
+	{"streamDebounce", "stream-debounce", kindString, func(s string) error {
+		_, err := time.ParseDuration(s)
+		return err
+	}},
+	{"streamMaxFps", "stream-max-fps", kindUint, nil},
+	{"termProfile", "term-profile", kindString, func(s string) error {
+		_, err := termprofile.Parse(s)
+		return err
+	}},
+	{"keys", "", kindMap, nil},
+	{"debug", "debug", kindBool, nil},
+	{"logFile", "log-file", kindString, nil},
+	{"profile", "profile", kindString, nil},
+	{"profiles", "", kindMap, nil},
+}
+
+// lookupSetting finds a setting by its key, ignoring case like viper does.
+func lookupSetting(key string) (setting, error) {
+	i := slices.IndexFunc(settings, func(s setting) bool { return strings.EqualFold(s.key, key) })
+	if i < 0 {
+		return setting{}, fmt.Errorf("unknown setting %q, see glow config show", key)
+	}
+	return settings[i], nil
+}
+
+// parse checks a value given on the command line and converts it to the
+// value written to the config file.
+func (s setting) parse(value string) (any, error) {
+	var v any = value
+	switch s.kind {
+	case kindMap:
+		return nil, fmt.Errorf("%s can't be set from the command line, use glow config edit", s.key)
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected true or false", s.key, value)
+		}
+		v = b
+	case kindUint:
+		n, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive number", s.key, value)
+		}
+		v = n
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected a number", s.key, value)
+		}
+		v = n
+	case kindList:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v = list
+	}
+	if s.check != nil {
+		if err := s.check(value); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// matches reports whether a value read from the config file has the type of
+// the setting. Values are checked further when they're used.
+func (s setting) matches(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return s.kind == kindMap
+	case []any:
+		return s.kind == kindList
+	case bool:
+		return s.kind == kindBool
+	case int:
+		return s.kind == kindInt || s.kind == kindString || (s.kind == kindUint && v >= 0)
+	case float64, string:
+		return s.kind == kindString
+	}
+	return false
+}
+
+// checkConfigFile reports unknown settings and values of the wrong type in
+// the config file at path, which viper would otherwise ignore.
+func checkConfigFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+	for key, value := range values {
+		s, err := lookupSetting(key)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if !s.matches(value) {
+			return fmt.Errorf("invalid config file %s: %s must be %s, got %v", path, s.key, s.kind, value)
+		}
+		if s.key == "security" {
+			if err := checkSecuritySection(value); err != nil {
+				return fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+			continue
+		}
+		if s.key != "profiles" || value == nil {
+			continue
+		}
+		for name, profile := range value.(map[string]any) {
+			if _, err := profileSettings(name, profile); err != nil {
+				return fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setConfigValue sets a key of the YAML config file at path, keeping its
+// comments and the order of the other keys.
+func setConfigValue(path, key string, value any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("unable to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return errors.New("unable to set config value: the config file isn't a map")
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("unable to encode config value: %w", err)
+	}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if strings.EqualFold(root.Content[i].Value, key) {
+			old := root.Content[i+1]
+			if old.Tag == node.Tag {
+				// Keep strings quoted as they were.
+				node.Style = old.Style
+			}
+			node.LineComment = old.LineComment
+			root.Content[i+1] = &node
+			found = true
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("unable to encode config file: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Package slides splits markdown documents into the slides of a
+// presentation.
+package slides
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	rulePattern    = regexp.MustCompile(`^ {0,3}-{3,}[ \t]*$`)
+	headingPattern = regexp.MustCompile(`^ {0,3}#{1,2}(?:[ \t]|$)`)
+	fencePattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Split splits a document, without its frontmatter, into slides. Slides are
+// separated by lines of three or more dashes on their own, with a blank line
+// before them; documents without such separators start a slide at every
+// level 1 or 2 heading. Blank slides are left out.
+func Split(markdown string) []string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	code := codeLines(lines)
+
+	var rules, headings []int
+	for i, l := range lines {
+		if code[i] {
+			continue
+		}
+		// A line of dashes right below text underlines a heading.
+		if rulePattern.MatchString(l) && (i == 0 || strings.TrimSpace(lines[i-1]) == "") {
+			rules = append(rules, i)
+		}
+		if headingPattern.MatchString(l) {
+			headings = append(headings, i)
+		}
+	}
+
+	var slides []string
+	add := func(from, to int) {
+		if s := strings.Trim(strings.Join(lines[from:to], "\n"), "\n"); strings.TrimSpace(s) != "" {
+			slides = append(slides, s+"\n")
+		}
+	}
+	start := 0
+	if len(rules) > 0 {
+		for _, r := range rules {
+			add(start, r)
+			start = r + 1
+		}
+	} else {
+		for _, h := range headings {
+			add(start, h)
+			start = h
+		}
+	}
+	add(start, len(lines))
+	return slides
+}
+
+// codeLines reports which lines are part of fenced code blocks.
+func codeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	fence := ""
+	for i, l := range lines {
+		if m := fencePattern.FindStringSubmatch(l); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(l) == m[1]:
+				fence = ""
+			}
+			code[i] = true
+			continue
+		}
+		code[i] = fence != ""
+	}
+	return code
+}
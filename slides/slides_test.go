@@ -0,0 +1,49 @@
+package slides
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "rules",
+			in: "# Talk\n\nBy me\n\n---\n\n## One\n\n# Not a slide\n\n---\n\n" +
+				"Heading\n---\n\n```\n---\n```\n\n---\n\n---\n",
+			want: []string{
+				"# Talk\n\nBy me\n",
+				"## One\n\n# Not a slide\n",
+				"Heading\n---\n\n```\n---\n```\n",
+			},
+		},
+		{
+			name: "headings",
+			in:   "Intro\n\n# One\n\ntext\n\n### Sub\n\n## Two\r\n\n```sh\n# comment\n```\n",
+			want: []string{
+				"Intro\n",
+				"# One\n\ntext\n\n### Sub\n",
+				"## Two\n\n```sh\n# comment\n```\n",
+			},
+		},
+		{
+			name: "single",
+			in:   "Just text\n",
+			want: []string{"Just text\n"},
+		},
+		{
+			name: "empty",
+			in:   "\n\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Split(tc.in); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnix listens on a Unix socket at path that only the user can
+// connect to. The socket is created with a umask that leaves it private,
+// rather than restricted after the fact, so it's never open to others.
+func listenUnix(path string) (net.Listener, error) {
+	// The umask is per process, but glow doesn't create other files while
+	// the daemon starts.
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path) //nolint:wrapcheck
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "net"
+
+// listenUnix listens on a Unix socket at path. Windows restricts access to
+// the socket with the ACL of its directory.
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path) //nolint:wrapcheck
+}
@@ -2,321 +2,111 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/spf13/viper"
 	"golang.org/x/term"
 )
 
-// SpinnerType represents different styles of spinner animations
-type SpinnerType string
-
-const (
-	SpinnerDots          SpinnerType = "dots"
-	SpinnerDots2         SpinnerType = "dots2"
-	SpinnerDots3         SpinnerType = "dots3"
-	SpinnerDots4         SpinnerType = "dots4"
-	SpinnerDots5         SpinnerType = "dots5"
-	SpinnerDots6         SpinnerType = "dots6"
-	SpinnerDots7         SpinnerType = "dots7"
-	SpinnerDots8         SpinnerType = "dots8"
-	SpinnerDots9         SpinnerType = "dots9"
-	SpinnerDots10        SpinnerType = "dots10"
-	SpinnerDots11        SpinnerType = "dots11"
-	SpinnerDots12        SpinnerType = "dots12"
-	SpinnerDots13        SpinnerType = "dots13"
-	SpinnerLine          SpinnerType = "line"
-	SpinnerLine2         SpinnerType = "line2"
-	SpinnerPipe          SpinnerType = "pipe"
-	SpinnerSimpleDots    SpinnerType = "simpleDots"
-	SpinnerStar          SpinnerType = "star"
-	SpinnerStar2         SpinnerType = "star2"
-	SpinnerFlip          SpinnerType = "flip"
-	SpinnerBalloon       SpinnerType = "balloon"
-	SpinnerBalloon2      SpinnerType = "balloon2"
-	SpinnerNoise         SpinnerType = "noise"
-	SpinnerBounce        SpinnerType = "bounce"
-	SpinnerBoxBounce     SpinnerType = "boxBounce"
-	SpinnerCircle        SpinnerType = "circle"
-	SpinnerSquareCorners SpinnerType = "squareCorners"
-	SpinnerCircleHalves  SpinnerType = "circleHalves"
-	SpinnerToggle        SpinnerType = "toggle"
-	SpinnerArrow         SpinnerType = "arrow"
-	SpinnerBouncingBar   SpinnerType = "bouncingBar"
-	SpinnerBouncingBall  SpinnerType = "bouncingBall"
-	SpinnerBinary        SpinnerType = "binary"
-)
-
-// spinnerDefinition defines the appearance and behavior of a spinner
-type spinnerDefinition struct {
-	Interval time.Duration
-	Frames   []string
-}
+// customSpinners lists the names of spinners defined in the config file, in
+// the order they were registered.
+var customSpinners []string
 
-// Available spinner definitions
-var spinnerDefinitions = map[SpinnerType]spinnerDefinition{
-	SpinnerDots: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏",
-		},
-	},
-	SpinnerDots2: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷",
-		},
-	},
-	SpinnerDots3: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"⠋", "⠙", "⠚", "⠞", "⠖", "⠦", "⠴", "⠲", "⠳", "⠓",
-		},
-	},
-	SpinnerDots4: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"⠄", "⠆", "⠇", "⠋", "⠙", "⠸", "⠰", "⠠", "⠰", "⠸", "⠙", "⠋", "⠇", "⠆",
-		},
-	},
-	SpinnerLine: {
-		Interval: 115 * time.Millisecond,
-		Frames: []string{
-			"-", "\\", "|", "/",
-		},
-	},
-	SpinnerLine2: {
-		Interval: 75 * time.Millisecond,
-		Frames: []string{
-			"⠂", "-", "–", "—", "–", "-",
-		},
-	},
-	SpinnerPipe: {
-		Interval: 75 * time.Millisecond,
-		Frames: []string{
-			"┤", "┘", "┴", "└", "├", "┌", "┬", "┐",
-		},
-	},
-	SpinnerSimpleDots: {
-		Interval: 200 * time.Millisecond,
-		Frames: []string{
-			".  ", ".. ", "...", "   ",
-		},
-	},
-	SpinnerStar: {
-		Interval: 80 * time.Millisecond,
-		Frames: []string{
-			"✶", "✸", "✹", "✺", "✹", "✷",
-		},
-	},
-	SpinnerBounce: {
-		Interval: 80 * time.Millisecond,
-		Frames: []string{
-			"⠁", "⠂", "⠄", "⠂",
-		},
-	},
-	SpinnerBoxBounce: {
-		Interval: 80 * time.Millisecond,
-		Frames: []string{
-			"▖", "▘", "▝", "▗",
-		},
-	},
-	SpinnerCircle: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"◡", "⊙", "◠",
-		},
-	},
-	SpinnerCircleHalves: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"◐", "◓", "◑", "◒",
-		},
-	},
-	SpinnerToggle: {
-		Interval: 175 * time.Millisecond,
-		Frames: []string{
-			"⊶", "⊷",
-		},
-	},
-	SpinnerArrow: {
-		Interval: 100 * time.Millisecond,
-		Frames: []string{
-			"←", "↖", "↑", "↗", "→", "↘", "↓", "↙",
-		},
-	},
-	SpinnerBouncingBar: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"[    ]", "[=   ]", "[==  ]", "[=== ]", "[====]", "[ ===]", "[  ==]", "[   =]",
-			"[    ]", "[   =]", "[  ==]", "[ ===]", "[====]", "[=== ]", "[==  ]", "[=   ]",
-		},
-	},
-	SpinnerBouncingBall: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			" ●    ", "  ●   ", "   ●  ", "    ● ", "     ●", "    ● ",
-			"   ●  ", "  ●   ", " ●    ", "●     ",
-		},
-	},
-	SpinnerBinary: {
-		Interval: 60 * time.Millisecond,
-		Frames: []string{
-			"010010", "001100", "100101", "111010", "111101", "010111",
-			"101011", "111000", "110011", "110101",
-		},
-	},
+// customSpinnerConfig is a spinner definition as written in the config file.
+type customSpinnerConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Frames   []string      `mapstructure:"frames"`
 }
 
-// Spinner color options
-var (
-	// Default vibrant color for the spinner
-	spinnerColor = lipgloss.Color("#FFFFFF") // Bright green
+// defaultCustomSpinnerInterval is used for custom spinners that don't set an
+// interval.
+const defaultCustomSpinnerInterval = 80 * time.Millisecond
 
-	// Style for the spinner
-	spinnerStyle = lipgloss.NewStyle().
-			Bold(true). // Make it bold
-			Foreground(spinnerColor)
-)
+// registerSpinner adds a spinner definition under the given name, replacing
+// any existing spinner of the same name.
+func registerSpinner(name string, cfg customSpinnerConfig) error {
+	if name == "" || name == spinnerNone {
+		return fmt.Errorf("invalid spinner name %q", name)
+	}
+	if len(cfg.Frames) == 0 {
+		return fmt.Errorf("spinner %q has no frames", name)
+	}
+	if cfg.Interval < 0 {
+		return fmt.Errorf("spinner %q has a negative interval", name)
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultCustomSpinnerInterval
+	}
 
-// Spinner manages the animation state for spinner indicators
-type Spinner struct {
-	definition spinnerDefinition
-	current    int
-	active     bool
-	lastUpdate time.Time
-	msgChan    chan struct{}
-	stopChan   chan struct{}
-	styled     bool // Whether to apply color styling
+	st := stream.SpinnerType(name)
+	if _, ok := stream.LookupSpinner(st); !ok {
+		customSpinners = append(customSpinners, name)
+	}
+	stream.RegisterSpinner(st, stream.SpinnerDefinition{
+		Interval: cfg.Interval,
+		Frames:   cfg.Frames,
+	})
+	return nil
 }
 
-// NewSpinner creates a new spinner with the specified type
-func NewSpinner(st SpinnerType) *Spinner {
-	def, ok := spinnerDefinitions[st]
-	if !ok {
-		// Default to dots if the specified spinner is not found
-		def = spinnerDefinitions[SpinnerDots]
+// loadCustomSpinners registers the spinners defined under the "spinners" key
+// of the config file.
+func loadCustomSpinners() error {
+	var cfg map[string]customSpinnerConfig
+	if err := viper.UnmarshalKey("spinners", &cfg); err != nil {
+		return fmt.Errorf("unable to parse spinners: %w", err)
 	}
-
-	return &Spinner{
-		definition: def,
-		msgChan:    make(chan struct{}, 1),
-		stopChan:   make(chan struct{}),
-		lastUpdate: time.Now(),
-		styled:     true, // Enable styling by default
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := registerSpinner(name, cfg[name]); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// Start begins the spinner animation in a separate goroutine
-func (s *Spinner) Start(w io.Writer) {
-	s.active = true
+// spinnerNone disables loading animations and download progress.
+const spinnerNone = "none"
 
-	go func() {
-		ticker := time.NewTicker(s.definition.Interval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-s.stopChan:
-				// Clear the spinner animation
-				fmt.Fprint(w, "\r\033[K")
-				return
-
-			case <-s.msgChan:
-				// Message received, reset animation timer
-				s.lastUpdate = time.Now()
-
-			case <-ticker.C:
-				// Only show spinner if we've been waiting for a while (100ms)
-				if time.Since(s.lastUpdate) > 100*time.Millisecond {
-					s.current = (s.current + 1) % len(s.definition.Frames)
-					frame := s.definition.Frames[s.current]
-
-					// Apply styling if enabled
-					if s.styled {
-						frame = spinnerStyle.Render(frame)
-					}
-
-					fmt.Fprintf(w, "\r\033[K%s", frame) // Clear line and print frame
-				}
-			}
-		}
-	}()
-}
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
 
-// Update signals that new data was received
-func (s *Spinner) Update() {
-	if s.active {
-		// Non-blocking send to avoid hangs if channel is full
-		select {
-		case s.msgChan <- struct{}{}:
-		default:
+// validateSpinner checks the values given for --spinner and --spinner-color.
+func validateSpinner(name, color string) error {
+	if _, ok := stream.LookupSpinner(stream.SpinnerType(name)); !ok && name != spinnerNone {
+		names := []string{spinnerNone}
+		for _, st := range stream.Spinners() {
+			names = append(names, string(st))
 		}
+		return fmt.Errorf("invalid spinner %q, expected one of: %s", name, strings.Join(names, ", "))
 	}
-}
-
-// Stop terminates the spinner animation
-func (s *Spinner) Stop() {
-	if s.active {
-		s.active = false
-		close(s.stopChan)
+	if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+		return nil
 	}
-}
-
-// SetColor changes the spinner color
-func (s *Spinner) SetColor(color string) {
-	spinnerStyle = spinnerStyle.Copy().Foreground(lipgloss.Color(color))
-}
-
-// DisableStyling turns off color and bold styling
-func (s *Spinner) DisableStyling() {
-	s.styled = false
-}
-
-// EnableStyling turns on color and bold styling
-func (s *Spinner) EnableStyling() {
-	s.styled = true
+	if !hexColorPattern.MatchString(color) {
+		return fmt.Errorf("invalid spinner color %q, expected a hex color like #FF0000 or an ANSI color from 0 to 255", color)
+	}
+	return nil
 }
 
 // GetSpinnerType returns the appropriate spinner type based on user preference
-func GetSpinnerType(spinnerStyle string) SpinnerType {
-	switch spinnerStyle {
-	case "dots":
-		return SpinnerDots
-	case "dots2":
-		return SpinnerDots2
-	case "dots3":
-		return SpinnerDots3
-	case "dots4":
-		return SpinnerDots4
-	case "line":
-		return SpinnerLine
-	case "simpleDots":
-		return SpinnerSimpleDots
-	case "star":
-		return SpinnerStar
-	case "bounce":
-		return SpinnerBounce
-	case "boxBounce":
-		return SpinnerBoxBounce
-	case "circle":
-		return SpinnerCircle
-	case "arrow":
-		return SpinnerArrow
-	case "binary":
-		return SpinnerBinary
-	case "bouncingBar":
-		return SpinnerBouncingBar
-	case "bouncingBall":
-		return SpinnerBouncingBall
-	default:
-		return SpinnerDots // Default to dots
+func GetSpinnerType(spinnerStyle string) stream.SpinnerType {
+	if _, ok := stream.LookupSpinner(stream.SpinnerType(spinnerStyle)); ok {
+		return stream.SpinnerType(spinnerStyle)
 	}
+	return stream.SpinnerDots // Default to dots
 }
 
 // demonstrateSpinner shows a live animation of a specific spinner type
@@ -324,7 +114,8 @@ func demonstrateSpinner(spinnerName string, colorStr string) error {
 	spinnerType := GetSpinnerType(spinnerName)
 
 	// If spinner type doesn't exist, show error
-	if _, ok := spinnerDefinitions[spinnerType]; !ok {
+	definition, ok := stream.LookupSpinner(spinnerType)
+	if !ok {
 		fmt.Printf("Unknown spinner type: %s\n", spinnerName)
 		fmt.Println("Run 'glow spinner' without arguments to see available spinner types")
 		return nil
@@ -340,16 +131,13 @@ func demonstrateSpinner(spinnerName string, colorStr string) error {
 	}
 
 	// Create and configure the spinner
-	sp := NewSpinner(spinnerType)
+	sp := stream.NewSpinner(spinnerType)
 
 	// Apply custom color if specified
 	if colorStr != "" {
 		sp.SetColor(colorStr)
 	}
 
-	// Get the spinner definition
-	definition, _ := spinnerDefinitions[spinnerType]
-
 	// Start the spinner
 	sp.Start(os.Stdout)
 
@@ -399,8 +187,49 @@ func demonstrateSpinner(spinnerName string, colorStr string) error {
 	return nil
 }
 
-// showSpinnerGallery displays all available spinner animations
-func showSpinnerGallery() error {
+// namedSpinner is a spinner as it is chosen with --spinner.
+type namedSpinner struct {
+	name  string
+	stype stream.SpinnerType
+}
+
+// allSpinners returns the built-in spinners, followed by those defined in
+// the config file.
+func allSpinners() []namedSpinner {
+	spinners := []namedSpinner{
+		{"dots", stream.SpinnerDots},
+		{"dots2", stream.SpinnerDots2},
+		{"dots3", stream.SpinnerDots3},
+		{"dots4", stream.SpinnerDots4},
+		{"line", stream.SpinnerLine},
+		{"line2", stream.SpinnerLine2},
+		{"pipe", stream.SpinnerPipe},
+		{"simpleDots", stream.SpinnerSimpleDots},
+		{"star", stream.SpinnerStar},
+		{"star2", stream.SpinnerStar2},
+		{"flip", stream.SpinnerFlip},
+		{"balloon", stream.SpinnerBalloon},
+		{"balloon2", stream.SpinnerBalloon2},
+		{"bounce", stream.SpinnerBounce},
+		{"boxBounce", stream.SpinnerBoxBounce},
+		{"circle", stream.SpinnerCircle},
+		{"squareCorners", stream.SpinnerSquareCorners},
+		{"circleHalves", stream.SpinnerCircleHalves},
+		{"toggle", stream.SpinnerToggle},
+		{"arrow", stream.SpinnerArrow},
+		{"bouncingBar", stream.SpinnerBouncingBar},
+		{"bouncingBall", stream.SpinnerBouncingBall},
+		{"binary", stream.SpinnerBinary},
+	}
+	for _, name := range customSpinners {
+		spinners = append(spinners, namedSpinner{name, stream.SpinnerType(name)})
+	}
+	return spinners
+}
+
+// printSpinnerGallery lists all available spinner animations with a few of
+// their frames, for when the gallery can't be shown on a terminal.
+func printSpinnerGallery() error {
 	fmt.Println("Available spinner animations for Glow")
 	fmt.Println("Use with --spinner=NAME")
 	fmt.Println("To see a live demo of a specific spinner, run: glow spinner NAME")
@@ -414,35 +243,7 @@ func showSpinnerGallery() error {
 		}
 	}
 
-	// Map of spinner types to preview
-	spinners := []struct {
-		name  string
-		stype SpinnerType
-	}{
-		{"dots", SpinnerDots},
-		{"dots2", SpinnerDots2},
-		{"dots3", SpinnerDots3},
-		{"dots4", SpinnerDots4},
-		{"line", SpinnerLine},
-		{"line2", SpinnerLine2},
-		{"pipe", SpinnerPipe},
-		{"simpleDots", SpinnerSimpleDots},
-		{"star", SpinnerStar},
-		{"star2", SpinnerStar2},
-		{"flip", SpinnerFlip},
-		{"balloon", SpinnerBalloon},
-		{"balloon2", SpinnerBalloon2},
-		{"bounce", SpinnerBounce},
-		{"boxBounce", SpinnerBoxBounce},
-		{"circle", SpinnerCircle},
-		{"squareCorners", SpinnerSquareCorners},
-		{"circleHalves", SpinnerCircleHalves},
-		{"toggle", SpinnerToggle},
-		{"arrow", SpinnerArrow},
-		{"bouncingBar", SpinnerBouncingBar},
-		{"bouncingBall", SpinnerBouncingBall},
-		{"binary", SpinnerBinary},
-	}
+	spinners := allSpinners()
 
 	// Calculate columns for display
 	cols := 3
@@ -464,7 +265,7 @@ func showSpinnerGallery() error {
 
 	// Display each spinner with its name and a preview
 	for i, s := range spinners {
-		def, ok := spinnerDefinitions[s.stype]
+		def, ok := stream.LookupSpinner(s.stype)
 		if !ok {
 			continue
 		}
@@ -478,7 +279,7 @@ func showSpinnerGallery() error {
 		// Apply styling to each frame
 		styledFrames := make([]string, len(previewFrames))
 		for j, frame := range previewFrames {
-			styledFrames[j] = spinnerStyle.Render(frame)
+			styledFrames[j] = stream.SpinnerStyle.Render(frame)
 		}
 
 		preview := strings.Join(styledFrames, sepStyle.Render(" "))
@@ -517,35 +318,7 @@ func demonstrateAllSpinners(colorStr string) error {
 	fmt.Println("Press Ctrl+C at any time to exit")
 	fmt.Println()
 
-	// Create a list of spinner types to demonstrate
-	spinners := []struct {
-		name  string
-		stype SpinnerType
-	}{
-		{"dots", SpinnerDots},
-		{"dots2", SpinnerDots2},
-		{"dots3", SpinnerDots3},
-		{"dots4", SpinnerDots4},
-		{"line", SpinnerLine},
-		{"line2", SpinnerLine2},
-		{"pipe", SpinnerPipe},
-		{"simpleDots", SpinnerSimpleDots},
-		{"star", SpinnerStar},
-		{"star2", SpinnerStar2},
-		{"flip", SpinnerFlip},
-		{"balloon", SpinnerBalloon},
-		{"balloon2", SpinnerBalloon2},
-		{"bounce", SpinnerBounce},
-		{"boxBounce", SpinnerBoxBounce},
-		{"circle", SpinnerCircle},
-		{"squareCorners", SpinnerSquareCorners},
-		{"circleHalves", SpinnerCircleHalves},
-		{"toggle", SpinnerToggle},
-		{"arrow", SpinnerArrow},
-		{"bouncingBar", SpinnerBouncingBar},
-		{"bouncingBall", SpinnerBouncingBall},
-		{"binary", SpinnerBinary},
-	}
+	spinners := allSpinners()
 
 	// Set up signal handling
 	quit := make(chan os.Signal, 1)
@@ -563,7 +336,7 @@ func demonstrateAllSpinners(colorStr string) error {
 		}
 
 		// Create the spinner
-		sp := NewSpinner(s.stype)
+		sp := stream.NewSpinner(s.stype)
 		if colorStr != "" {
 			sp.SetColor(colorStr)
 		}
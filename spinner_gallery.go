@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"golang.org/x/term"
+)
+
+// galleryTick is how often the gallery redraws its spinners. Each spinner
+// still moves at its own interval.
+const galleryTick = 20 * time.Millisecond
+
+// galleryTickMsg asks the gallery to advance its spinners.
+type galleryTickMsg time.Time
+
+// spinnerGallery is a grid of all spinners, animated at once, in which one
+// spinner is selected with the arrow keys.
+type spinnerGallery struct {
+	spinners []namedSpinner
+	defs     []stream.SpinnerDefinition
+	style    lipgloss.Style
+
+	start    time.Time
+	now      time.Time
+	width    int
+	selected int
+	// Whether a spinner was chosen with enter, rather than the gallery being
+	// quit.
+	chosen bool
+	done   bool
+}
+
+func newSpinnerGallery(color string) spinnerGallery {
+	g := spinnerGallery{
+		style: stream.SpinnerStyle,
+		start: time.Now(),
+		width: 80,
+	}
+	if color != "" {
+		g.style = g.style.Foreground(lipgloss.Color(color))
+	}
+	g.now = g.start
+	for _, s := range allSpinners() {
+		def, ok := stream.LookupSpinner(s.stype)
+		if !ok {
+			continue
+		}
+		g.spinners = append(g.spinners, s)
+		g.defs = append(g.defs, def)
+	}
+	return g
+}
+
+func galleryTickCmd() tea.Cmd {
+	return tea.Tick(galleryTick, func(t time.Time) tea.Msg {
+		return galleryTickMsg(t)
+	})
+}
+
+func (g spinnerGallery) Init() tea.Cmd {
+	return galleryTickCmd()
+}
+
+func (g spinnerGallery) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case galleryTickMsg:
+		g.now = time.Time(msg)
+		return g, galleryTickCmd()
+	case tea.WindowSizeMsg:
+		g.width = msg.Width
+	case tea.KeyMsg:
+		cols := g.columns()
+		switch msg.String() {
+		case "left", "h":
+			g.selected = max(g.selected-1, 0)
+		case "right", "l", "tab":
+			g.selected = min(g.selected+1, len(g.spinners)-1)
+		case "up", "k":
+			if g.selected >= cols {
+				g.selected -= cols
+			}
+		case "down", "j":
+			if g.selected+cols < len(g.spinners) {
+				g.selected += cols
+			}
+		case "home", "g":
+			g.selected = 0
+		case "end", "G":
+			g.selected = len(g.spinners) - 1
+		case "enter":
+			g.chosen, g.done = true, true
+			return g, tea.Quit
+		case "q", "esc", "ctrl+c":
+			g.done = true
+			return g, tea.Quit
+		}
+	}
+	return g, nil
+}
+
+// cellWidth returns the width of a grid cell, which fits the longest name
+// and the widest frame of any spinner.
+func (g spinnerGallery) cellWidth() int {
+	var name, frame int
+	for i, s := range g.spinners {
+		name = max(name, lipgloss.Width(s.name))
+		for _, f := range g.defs[i].Frames {
+			frame = max(frame, lipgloss.Width(f))
+		}
+	}
+	// A marker for the selection, a space between the frame and the name,
+	// and a gap before the next cell.
+	return 2 + frame + 1 + name + 3
+}
+
+// columns returns the number of columns of the grid.
+func (g spinnerGallery) columns() int {
+	return max(g.width/g.cellWidth(), 1)
+}
+
+// frame returns the frame a spinner shows at the current time.
+func (g spinnerGallery) frame(i int) string {
+	def := g.defs[i]
+	n := 0
+	if def.Interval > 0 {
+		n = int(g.now.Sub(g.start) / def.Interval)
+	}
+	return def.Frames[n%len(def.Frames)]
+}
+
+func (g spinnerGallery) View() string {
+	if g.done {
+		return ""
+	}
+	var (
+		b          strings.Builder
+		cols       = g.columns()
+		cell       = g.cellWidth()
+		frameWidth = 0
+		nameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+	)
+	for _, def := range g.defs {
+		for _, f := range def.Frames {
+			frameWidth = max(frameWidth, lipgloss.Width(f))
+		}
+	}
+
+	b.WriteString("\n")
+	for i, s := range g.spinners {
+		marker, name := "  ", nameStyle.Render(s.name)
+		if i == g.selected {
+			marker, name = keyword("› "), keyword(s.name)
+		}
+		f := g.frame(i)
+		c := marker + g.style.Render(f) + strings.Repeat(" ", frameWidth-lipgloss.Width(f)+1) + name
+		if i%cols < cols-1 && i < len(g.spinners)-1 {
+			c += strings.Repeat(" ", max(cell-lipgloss.Width(c), 0))
+		} else {
+			c += "\n"
+		}
+		b.WriteString(c)
+	}
+
+	def := g.defs[g.selected]
+	fmt.Fprintf(&b, "\n  %s %s\n", keyword(g.spinners[g.selected].name), //nolint:errcheck
+		faint(fmt.Sprintf("%d frames, every %s", len(def.Frames), def.Interval)))
+	b.WriteString(faint("  ←/↓/↑/→ select • enter choose • q quit") + "\n")
+	return b.String()
+}
+
+// showSpinnerGallery shows all spinners at once and prints how to use the one
+// that was chosen. Without a terminal, it lists them instead.
+func showSpinnerGallery() error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return printSpinnerGallery()
+	}
+
+	m, err := tea.NewProgram(newSpinnerGallery(spinnerColorStr)).Run()
+	if err != nil {
+		return fmt.Errorf("unable to run spinner gallery: %w", err)
+	}
+	g := m.(spinnerGallery) //nolint:forcetypeassert
+	if !g.chosen || len(g.spinners) == 0 {
+		return nil
+	}
+	fmt.Print(spinnerUsage(g.spinners[g.selected].name, g.defs[g.selected], spinnerColorStr))
+	return nil
+}
+
+// spinnerUsage describes a spinner and how to use it, on the command line and
+// in the config file.
+func spinnerUsage(name string, def stream.SpinnerDefinition, color string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Spinner: %s   Frames: %d   Interval: %s   Color: %s\n\n", //nolint:errcheck
+		name, len(def.Frames), def.Interval, color)
+	fmt.Fprintf(&b, "  glow --spinner=%s --spinner-color=%s -\n\n", name, color) //nolint:errcheck
+	b.WriteString("In the config file:\n\n")
+	fmt.Fprintf(&b, "  spinner: %q\n  spinnerColor: %q\n", name, color) //nolint:errcheck
+	return b.String()
+}
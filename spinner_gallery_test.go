@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSpinnerGallery(t *testing.T) {
+	g := newSpinnerGallery("")
+	key := func(k string) {
+		t.Helper()
+		var msg tea.KeyMsg
+		switch k {
+		case "left", "right", "up", "down", "enter":
+			msg = tea.KeyMsg{Type: map[string]tea.KeyType{
+				"left": tea.KeyLeft, "right": tea.KeyRight, "up": tea.KeyUp,
+				"down": tea.KeyDown, "enter": tea.KeyEnter,
+			}[k]}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m, _ := g.Update(msg)
+		g = m.(spinnerGallery) //nolint:forcetypeassert
+	}
+
+	m, _ := g.Update(tea.WindowSizeMsg{Width: 3 * g.cellWidth(), Height: 24})
+	g = m.(spinnerGallery) //nolint:forcetypeassert
+	if cols := g.columns(); cols != 3 {
+		t.Fatalf("expected 3 columns, got %d", cols)
+	}
+
+	for _, tc := range []struct {
+		key  string
+		want int
+	}{
+		{"left", 0},
+		{"up", 0},
+		{"right", 1},
+		{"down", 4},
+		{"down", 7},
+		{"up", 4},
+		{"G", len(g.spinners) - 1},
+		{"down", len(g.spinners) - 1},
+		{"right", len(g.spinners) - 1},
+		{"g", 0},
+	} {
+		key(tc.key)
+		if g.selected != tc.want {
+			t.Fatalf("after %s: expected spinner %d, got %d", tc.key, tc.want, g.selected)
+		}
+	}
+
+	if g.frame(0) != g.defs[0].Frames[0] {
+		t.Errorf("expected the first frame, got %q", g.frame(0))
+	}
+	m, _ = g.Update(galleryTickMsg(g.start.Add(g.defs[0].Interval + time.Millisecond)))
+	g = m.(spinnerGallery) //nolint:forcetypeassert
+	if g.frame(0) != g.defs[0].Frames[1] {
+		t.Errorf("expected the second frame, got %q", g.frame(0))
+	}
+	if view := g.View(); !strings.Contains(view, g.spinners[0].name) || !strings.Contains(view, g.frame(0)) {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+
+	key("right")
+	key("enter")
+	if !g.chosen || g.spinners[g.selected].name != "dots2" {
+		t.Errorf("expected dots2 to be chosen, got %s (chosen: %v)", g.spinners[g.selected].name, g.chosen)
+	}
+	usage := spinnerUsage("dots2", g.defs[1], "#FF0000")
+	if !strings.Contains(usage, "glow --spinner=dots2 --spinner-color=#FF0000 -") ||
+		!strings.Contains(usage, `spinner: "dots2"`) {
+		t.Errorf("unexpected usage:\n%s", usage)
+	}
+}
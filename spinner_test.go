@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/spf13/viper"
+)
+
+func TestCustomSpinners(t *testing.T) {
+	viper.Set("spinners", map[string]any{
+		"pulse":   map[string]any{"interval": "120ms", "frames": []string{"∙", "●"}},
+		"plain":   map[string]any{"frames": []string{"a", "b", "c"}},
+		"myPulse": map[string]any{"frames": []string{"○", "◉"}},
+	})
+	t.Cleanup(func() { viper.Set("spinners", nil) })
+
+	if err := loadCustomSpinners(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := GetSpinnerType("pulse"); st != "pulse" {
+		t.Fatalf("expected custom spinner type, got %s", st)
+	}
+	def, _ := stream.LookupSpinner("pulse")
+	if def.Interval != 120*time.Millisecond || len(def.Frames) != 2 {
+		t.Errorf("unexpected definition: %+v", def)
+	}
+	if def, _ := stream.LookupSpinner("plain"); def.Interval != defaultCustomSpinnerInterval {
+		t.Errorf("expected default interval, got %s", def.Interval)
+	}
+	// Viper lowercases the names, which are matched ignoring case.
+	if err := validateSpinner("myPulse", "#FF0000"); err != nil {
+		t.Errorf("expected the mixed-case name to be valid, got %v", err)
+	}
+	if def, ok := stream.LookupSpinner(GetSpinnerType("myPulse")); !ok || len(def.Frames) != 2 || def.Frames[0] != "○" {
+		t.Errorf("expected the mixed-case spinner, got %+v", def)
+	}
+	if def, ok := stream.LookupSpinner("BOUNCINGBALL"); !ok || len(def.Frames) == 0 {
+		t.Errorf("expected built-in spinners to match ignoring case, got %+v", def)
+	}
+
+	if err := registerSpinner("empty", customSpinnerConfig{}); err == nil {
+		t.Error("expected an error for a spinner without frames")
+	}
+}
+
+func TestValidateSpinner(t *testing.T) {
+	for _, tc := range []struct {
+		name, color string
+		ok          bool
+	}{
+		{"dots", "#FF0000", true},
+		{"none", "#fff", true},
+		{"bouncingBall", "212", true},
+		{"nope", "#FF0000", false},
+		{"dots", "red", false},
+		{"dots", "256", false},
+	} {
+		if err := validateSpinner(tc.name, tc.color); (err == nil) != tc.ok {
+			t.Errorf("%s %s: unexpected error %v", tc.name, tc.color, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"github.com/douglas-larocca/glow/v2/ui"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// How long open sessions are given to finish when the server shuts down.
+const sshShutdownTimeout = 30 * time.Second
+
+var (
+	sshServeFlags struct {
+		addr           string
+		hostKey        string
+		authorizedKeys string
+	}
+
+	sshServeCmd = &cobra.Command{
+		Use:   "ssh-serve [DIR]",
+		Short: "Serve the TUI over SSH",
+		Long: paragraph(fmt.Sprintf("\n%s the TUI over SSH, letting anyone who connects browse a directory of markdown. "+
+			"Actions that would run programs on the server, like editing documents or opening links, are disabled, "+
+			"and bookmarks, the stash and reading positions aren't available.", keyword("Serve"))),
+		Example: paragraph("glow ssh-serve docs --addr :23234\nssh -p 23234 localhost"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return sshServe(cmd.Context(), dir)
+		},
+	}
+)
+
+// sshServe serves the TUI for browsing dir over SSH until interrupted.
+func sshServe(ctx context.Context, dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to get absolute path: %w", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+
+	cfg, err := tuiConfig(dir)
+	if err != nil {
+		return err
+	}
+	cfg.Remote = true
+	cfg.BookmarksFile = ""
+	cfg.HistoryFile = ""
+	cfg.ShowRecent = false
+	cfg.StashDir = ""
+	cfg.PositionsFile = ""
+	cfg.AnnotationsFile = ""
+
+	// Glamour renders with true color, so style the rest of the TUI to
+	// match, whatever the server's own terminal supports.
+	if colorProfile == "" {
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	}
+
+	hostKey := sshServeFlags.hostKey
+	if hostKey == "" {
+		hostKey = dataPath("ssh_host_ed25519")
+	}
+	if err := os.MkdirAll(filepath.Dir(hostKey), 0o700); err != nil {
+		return fmt.Errorf("unable to create host key directory: %w", err)
+	}
+
+	opts := []ssh.Option{
+		wish.WithAddress(sshServeFlags.addr),
+		wish.WithHostKeyPath(hostKey),
+		wish.WithMiddleware(
+			bm.MiddlewareWithProgramHandler(sshProgram(cfg), termenv.Ascii),
+			activeterm.Middleware(),
+			logging.Middleware(),
+		),
+	}
+	if sshServeFlags.authorizedKeys != "" {
+		opts = append(opts, wish.WithAuthorizedKeys(sshServeFlags.authorizedKeys))
+	}
+	s, err := wish.NewServer(opts...)
+	if err != nil {
+		return fmt.Errorf("unable to create ssh server: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Info("serving", "dir", dir, "addr", sshServeFlags.addr)
+		fmt.Fprintf(os.Stderr, "Serving %s on ssh://%s\n", dir, sshServeFlags.addr) //nolint:errcheck
+		errc <- s.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		if errors.Is(err, ssh.ErrServerClosed) {
+			return nil
+		}
+		var opErr *net.OpError
+		if errors.As(err, &opErr) {
+			return fmt.Errorf("unable to listen on %s: %w", sshServeFlags.addr, opErr.Err)
+		}
+		return fmt.Errorf("unable to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), sshShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return fmt.Errorf("unable to shut down ssh server: %w", err)
+	}
+	return nil
+}
+
+// sshProgram returns the handler starting the TUI for an SSH session.
+func sshProgram(cfg ui.Config) bm.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
+		cfg := cfg
+		if cfg.GlamourStyle == styles.AutoStyle {
+			cfg.GlamourStyle = styles.LightStyle
+			if bm.MakeRenderer(s).HasDarkBackground() {
+				cfg.GlamourStyle = styles.DarkStyle
+			}
+		}
+		if termProfileName == "" {
+			// Draw for the terminal of the session, rather than that of the
+			// server.
+			pty, _, _ := s.Pty()
+			cfg.TermProfile = termprofile.Detect(func(key string) string {
+				if key == "TERM" {
+					return pty.Term
+				}
+				return ""
+			})
+		}
+		log.Info("starting session", "user", s.User(), "remote", s.RemoteAddr(), "style", cfg.GlamourStyle)
+
+		// Sessions end with their connection, not with signals sent to
+		// the server.
+		opts := append(bm.MakeOptions(s), tea.WithoutSignalHandler())
+		return ui.NewProgram(cfg, "", opts...)
+	}
+}
@@ -0,0 +1,102 @@
+package stash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// Encrypted documents are stored in the age format, so they can also be
+// decrypted with the age command.
+const ageHeader = "age-encryption.org/v1\n"
+
+// ErrLocked is returned decrypting a document without the key or passphrase
+// it was encrypted with.
+var ErrLocked = errors.New("document is encrypted with another key or passphrase")
+
+// IsEncrypted reports whether content was encrypted with age.
+func IsEncrypted(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(ageHeader))
+}
+
+// Encrypt encrypts content for recipients.
+func Encrypt(content []byte, recipients ...age.Recipient) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := age.Encrypt(&b, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, fmt.Errorf("unable to encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("unable to encrypt: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Decrypt decrypts content encrypted for any of identities. It returns
+// ErrLocked if it wasn't.
+func Decrypt(content []byte, identities ...age.Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, ErrLocked
+	}
+	r, err := age.Decrypt(bytes.NewReader(content), identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt: %w", err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt: %w", err)
+	}
+	return b, nil
+}
+
+// ParseRecipients parses age public keys, as in age1ql3z7hjy54pw3hyww5ay….
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(keys))
+	for _, k := range keys {
+		r, err := age.ParseX25519Recipient(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", k, err)
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// ReadIdentities reads the age identities, or secret keys, of a file like
+// those age-keygen writes.
+func ReadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read identities: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse identities in %s: %w", path, err)
+	}
+	return ids, nil
+}
+
+// Passphrase returns the recipient and the identity of a passphrase.
+func Passphrase(passphrase string) (age.Recipient, age.Identity, error) {
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid passphrase: %w", err)
+	}
+	id, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid passphrase: %w", err)
+	}
+	return r, id, nil
+}
@@ -0,0 +1,198 @@
+// Package stash keeps copies of documents in a local library.
+package stash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+)
+
+// indexFile lists the stashed documents. Their contents are stored next to
+// it, one file per document.
+const indexFile = "index.json"
+
+// Document is a stashed document.
+type Document struct {
+	ID string `json:"id"`
+	// File name of the document when it was stashed.
+	Name string `json:"name"`
+	// Path or URL the document was stashed from.
+	Source  string    `json:"source"`
+	Memo    string    `json:"memo,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	// Whether the stored copy is encrypted, see AddEncrypted.
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Title returns the memo of the document, or its name if it has none.
+func (d Document) Title() string {
+	if d.Memo != "" {
+		return d.Memo
+	}
+	return d.Name
+}
+
+// HasTag reports whether the document is tagged with tag.
+func (d Document) HasTag(tag string) bool {
+	return slices.ContainsFunc(d.Tags, func(t string) bool {
+		return strings.EqualFold(t, tag)
+	})
+}
+
+// Store is a library of stashed documents in a directory. It's safe for
+// concurrent use.
+type Store struct {
+	dir string
+
+	mu   sync.Mutex
+	docs []Document
+}
+
+// Open opens the library in dir. A missing directory is treated as an empty
+// library.
+func Open(dir string) (*Store, error) {
+	s := &Store{dir: dir}
+	b, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read stash: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.docs); err != nil {
+		return nil, fmt.Errorf("unable to parse stash: %w", err)
+	}
+	return s, nil
+}
+
+// List returns all stashed documents in the order they were stashed.
+func (s *Store) List() []Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.docs)
+}
+
+// Path returns the path of the stored copy of the document.
+func (s *Store) Path(d Document) string {
+	return filepath.Join(s.dir, d.ID+filepath.Ext(d.Name))
+}
+
+// Add stashes a copy of a document.
+func (s *Store) Add(source string, content []byte, memo string, tags []string) (Document, error) {
+	return s.add(source, content, memo, tags, false)
+}
+
+// AddEncrypted stashes a copy of a document encrypted for recipients, which
+// only their identities can read.
+func (s *Store) AddEncrypted(source string, content []byte, memo string, tags []string, recipients ...age.Recipient) (Document, error) {
+	content, err := Encrypt(content, recipients...)
+	if err != nil {
+		return Document{}, err
+	}
+	return s.add(source, content, memo, tags, true)
+}
+
+func (s *Store) add(source string, content []byte, memo string, tags []string, encrypted bool) (Document, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return Document{}, fmt.Errorf("unable to generate id: %w", err)
+	}
+
+	name := filepath.Base(source)
+	if name == "." || name == string(filepath.Separator) {
+		name = "stdin.md"
+	}
+	d := Document{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Source:    source,
+		Memo:      memo,
+		Tags:      tags,
+		Created:   time.Now(),
+		Encrypted: encrypted,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return Document{}, fmt.Errorf("unable to create directory: %w", err)
+	}
+	if err := os.WriteFile(s.Path(d), content, 0o600); err != nil {
+		return Document{}, fmt.Errorf("unable to write document: %w", err)
+	}
+	s.docs = append(s.docs, d)
+	return d, s.save()
+}
+
+// Read returns the contents of a stashed document, decrypted with any of
+// identities if it's encrypted.
+func (s *Store) Read(d Document, identities ...age.Identity) ([]byte, error) {
+	content, err := os.ReadFile(s.Path(d))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read document: %w", err)
+	}
+	if !d.Encrypted {
+		return content, nil
+	}
+	return Decrypt(content, identities...)
+}
+
+// Find looks up a document by its 1-based position in the list, or by ID.
+func (s *Store) Find(ref string) (Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n, err := strconv.Atoi(ref); err == nil && len(ref) < 8 {
+		if n < 1 || n > len(s.docs) {
+			return Document{}, fmt.Errorf("no stashed document #%d", n)
+		}
+		return s.docs[n-1], nil
+	}
+	for _, d := range s.docs {
+		if d.ID == ref {
+			return d, nil
+		}
+	}
+	return Document{}, fmt.Errorf("no stashed document %q", ref)
+}
+
+// Remove deletes a document and its stored copy.
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.docs, func(d Document) bool { return d.ID == id })
+	if i < 0 {
+		return fmt.Errorf("no stashed document %q", id)
+	}
+	if err := os.Remove(s.Path(s.docs[i])); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to remove document: %w", err)
+	}
+	s.docs = slices.Delete(s.docs, i, i+1)
+	return s.save()
+}
+
+func (s *Store) save() error {
+	b, err := json.MarshalIndent(s.docs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode stash: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, indexFile), b, 0o600); err != nil {
+		return fmt.Errorf("unable to write stash: %w", err)
+	}
+	return nil
+}
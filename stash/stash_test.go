@@ -0,0 +1,101 @@
+package stash
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "stash")
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.Add("/docs/README.md", []byte("# Hello"), "", []string{"Docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("/src/main.go", []byte("package main"), "entry point", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.List()); got != 2 {
+		t.Fatalf("expected 2 documents, got %d", got)
+	}
+
+	first, err := s.Find("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != d.ID || first.Title() != "README.md" || !first.HasTag("docs") {
+		t.Errorf("unexpected document: %+v", first)
+	}
+	second, err := s.Find("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Title() != "entry point" || filepath.Ext(s.Path(second)) != ".go" {
+		t.Errorf("unexpected document: %+v", second)
+	}
+	if b, err := os.ReadFile(s.Path(first)); err != nil || string(b) != "# Hello" {
+		t.Errorf("unexpected contents %q: %v", b, err)
+	}
+
+	if err := s.Remove(d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Path(d)); !os.IsNotExist(err) {
+		t.Error("expected the stored copy to be removed")
+	}
+	if _, err := s.Find(d.ID); err == nil {
+		t.Error("expected removed document not to be found")
+	}
+	if _, err := s.Find("3"); err == nil {
+		t.Error("expected an error for a document out of range")
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.AddEncrypted("/notes/private.md", []byte("# Secret"), "", nil, id.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Encrypted {
+		t.Error("expected the document to be marked encrypted")
+	}
+	b, err := os.ReadFile(s.Path(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(b) || bytes.Contains(b, []byte("Secret")) {
+		t.Errorf("expected encrypted contents, got %q", b)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	for _, ids := range [][]age.Identity{nil, {other}} {
+		if _, err := s.Read(d, ids...); !errors.Is(err, ErrLocked) {
+			t.Errorf("expected ErrLocked, got %v", err)
+		}
+	}
+	if b, err := s.Read(d, other, id); err != nil || string(b) != "# Secret" {
+		t.Errorf("unexpected contents %q: %v", b, err)
+	}
+}
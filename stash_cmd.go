@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/douglas-larocca/glow/v2/stash"
+	gap "github.com/muesli/go-app-paths"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var (
+	stashFlags struct {
+		memo       string
+		tags       []string
+		encrypt    bool
+		recipients []string
+		identity   string
+	}
+
+	stashCmd = &cobra.Command{
+		Use:   "stash [SOURCE]",
+		Short: "Keep a copy of a document in your stash",
+		Long: paragraph(fmt.Sprintf("\n%s a copy of a document in your local library, with an optional memo and tags. Stashed documents also show up in the TUI. Without a source, lists the stash.",
+			keyword("Keep"))),
+		Example: paragraph("glow stash README.md -m \"project notes\" --tag work\nglow stash --encrypt diary.md\nglow stash list\nglow stash show 2\nglow stash rm 2"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return listStash(cmd.OutOrStdout(), "")
+			}
+
+			s, err := stash.Open(stashDir())
+			if err != nil {
+				return err
+			}
+			src, err := sourceFromArg(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			defer src.reader.Close() //nolint:errcheck
+			content, err := io.ReadAll(src.reader)
+			if err != nil {
+				return fmt.Errorf("unable to read from reader: %w", err)
+			}
+
+			var d stash.Document
+			if stashFlags.encrypt {
+				recipients, err := stashRecipients()
+				if err != nil {
+					return err
+				}
+				d, err = s.AddEncrypted(src.URL, content, stashFlags.memo, stashFlags.tags, recipients...)
+			} else {
+				d, err = s.Add(src.URL, content, stashFlags.memo, stashFlags.tags)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Stashed %s as #%d.\n", d.Title(), len(s.List()))
+			return nil
+		},
+	}
+
+	stashListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List stashed documents",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var tag string
+			if len(stashFlags.tags) > 0 {
+				tag = stashFlags.tags[0]
+			}
+			return listStash(cmd.OutOrStdout(), tag)
+		},
+	}
+
+	stashShowCmd = &cobra.Command{
+		Use:   "show N",
+		Short: "Render a stashed document",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := stash.Open(stashDir())
+			if err != nil {
+				return err
+			}
+			d, err := s.Find(args[0])
+			if err != nil {
+				return err
+			}
+			if tui || cmd.Flags().Changed("tui") {
+				// The TUI asks for the passphrase of encrypted documents.
+				return runTUI(s.Path(d), "")
+			}
+			if !d.Encrypted {
+				return executeArg(cmd, s.Path(d), cmd.OutOrStdout())
+			}
+
+			content, err := readEncrypted(s, d)
+			if err != nil {
+				return err
+			}
+			src := &source{reader: io.NopCloser(bytes.NewReader(content)), URL: d.Name}
+			return executeCLI(cmd, src, cmd.OutOrStdout())
+		},
+	}
+
+	stashRmCmd = &cobra.Command{
+		Use:     "rm N",
+		Aliases: []string{"remove"},
+		Short:   "Remove a document from the stash",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := stash.Open(stashDir())
+			if err != nil {
+				return err
+			}
+			d, err := s.Find(args[0])
+			if err != nil {
+				return err
+			}
+			if err := s.Remove(d.ID); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Removed from stash:", d.Title())
+			return nil
+		},
+	}
+)
+
+// stashDir returns the directory stashed documents are kept in.
+func stashDir() string {
+	return dataPath("stash")
+}
+
+// dataPath returns the path of a file or directory in Glow's data directory.
+func dataPath(name string) string {
+	if dir := os.Getenv("GLOW_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, name)
+	}
+	path, err := gap.NewScope(gap.User, "glow").DataPath(name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// listStash prints the stashed documents, optionally only those with a tag.
+func listStash(w io.Writer, tag string) error {
+	s, err := stash.Open(stashDir())
+	if err != nil {
+		return err
+	}
+	docs := s.List()
+	if len(docs) == 0 {
+		fmt.Fprintln(w, "Your stash is empty. Add documents with glow stash FILE.")
+		return nil
+	}
+	for i, d := range docs {
+		if tag != "" && !d.HasTag(tag) {
+			continue
+		}
+		line := fmt.Sprintf("%3d  %s", i+1, d.Title())
+		if d.Memo != "" {
+			line += "  " + faint(d.Name)
+		}
+		if len(d.Tags) > 0 {
+			line += "  " + keyword("#"+strings.Join(d.Tags, " #"))
+		}
+		if d.Encrypted {
+			line += "  " + faint("encrypted")
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// stashRecipients returns the recipients documents are encrypted for: those
+// of --recipient, or of the stashRecipients setting, or else a passphrase
+// typed twice.
+func stashRecipients() ([]age.Recipient, error) {
+	keys := stashFlags.recipients
+	if len(keys) == 0 {
+		keys = viper.GetStringSlice("stashRecipients")
+	}
+	if len(keys) > 0 {
+		return stash.ParseRecipients(keys) //nolint:wrapcheck
+	}
+
+	passphrase, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	again, err := readPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != again {
+		return nil, errors.New("passphrases don't match")
+	}
+	r, _, err := stash.Passphrase(passphrase)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return []age.Recipient{r}, nil
+}
+
+// readEncrypted decrypts a stashed document with the identities of
+// --identity, or of the stashIdentity setting, or else a passphrase.
+func readEncrypted(s *stash.Store, d stash.Document) ([]byte, error) {
+	path := stashFlags.identity
+	if path == "" {
+		path = viper.GetString("stashIdentity")
+	}
+	if path != "" {
+		ids, err := stash.ReadIdentities(expandHome(path))
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		return s.Read(d, ids...) //nolint:wrapcheck
+	}
+
+	passphrase, err := readPassphrase("Passphrase for " + d.Title() + ": ")
+	if err != nil {
+		return nil, err
+	}
+	_, id, err := stash.Passphrase(passphrase)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return s.Read(d, id) //nolint:wrapcheck
+}
+
+// readPassphrase prompts for a passphrase on the terminal, without echoing
+// it, even if stdin is piped.
+func readPassphrase(prompt string) (string, error) {
+	tty := os.Stdin
+	if f, err := os.Open("/dev/tty"); err == nil {
+		defer f.Close() //nolint:errcheck
+		tty = f
+	}
+	if !term.IsTerminal(int(tty.Fd())) {
+		return "", errors.New("no terminal to read the passphrase from, use --recipient or --identity")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("unable to read passphrase: %w", err)
+	}
+	if len(b) == 0 {
+		return "", errors.New("empty passphrase")
+	}
+	return string(b), nil
+}
+
+// expandHome expands a leading ~/ of a path to the home directory.
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,99 @@
+// Package stats counts the words, headings, links and code blocks of
+// markdown documents, and estimates how long reading them takes.
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// WordsPerMinute is the reading speed reading times are estimated with.
+const WordsPerMinute = 230
+
+// Stats are the counts of a document.
+type Stats struct {
+	Words      int
+	Headings   int
+	Links      int
+	CodeBlocks int
+}
+
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Count counts the words of the text of a document, leaving out code blocks
+// and HTML, and its headings, links and code blocks.
+func Count(src []byte) Stats {
+	var (
+		s     Stats
+		words strings.Builder
+	)
+	doc := md.Parser().Parse(text.NewReader(src))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if n.Type() == ast.TypeBlock {
+			// Blocks end words.
+			words.WriteByte(' ')
+		}
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			s.Headings++
+		case *ast.Link:
+			s.Links++
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			s.CodeBlocks++
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			s.Links++
+			words.Write(n.Label(src))
+		case *ast.Text:
+			words.Write(n.Segment.Value(src))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				words.WriteByte(' ')
+			}
+		case *ast.String:
+			words.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	s.Words = len(strings.Fields(words.String()))
+	return s
+}
+
+// ReadingTime estimates how long reading words takes, to the minute.
+func ReadingTime(words int) time.Duration {
+	return max(time.Duration(words)*time.Minute/WordsPerMinute, time.Minute).Round(time.Minute)
+}
+
+// ReadingTime estimates how long reading the document takes.
+func (s Stats) ReadingTime() time.Duration {
+	return ReadingTime(s.Words)
+}
+
+func (s Stats) String() string {
+	return strings.Join([]string{
+		plural(s.Words, "word"),
+		fmt.Sprintf("%d min read", int(s.ReadingTime().Minutes())),
+		plural(s.Headings, "heading"),
+		plural(s.Links, "link"),
+		plural(s.CodeBlocks, "code block"),
+	}, " · ")
+}
+
+// plural returns a count of things, e.g. 1,024 words.
+func plural(n int, thing string) string {
+	if n != 1 {
+		thing += "s"
+	}
+	return humanize.Comma(int64(n)) + " " + thing
+}
@@ -0,0 +1,40 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCount(t *testing.T) {
+	src := "# Title\n\nSome *emphasized* words,\nover two lines with a [link](https://example.com).\n\n" +
+		"## Code\n\n```go\nfmt.Println(\"not counted\")\n```\n\n    indented code\n\n" +
+		"<div>not counted</div>\n\n| a | b |\n|---|---|\n| c | d |\n\nSee <https://charm.sh>.\n"
+	got := Count([]byte(src))
+	want := Stats{Words: 17, Headings: 2, Links: 2, CodeBlocks: 2}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	for words, want := range map[int]time.Duration{
+		0:    time.Minute,
+		229:  time.Minute,
+		460:  2 * time.Minute,
+		2300: 10 * time.Minute,
+	} {
+		if got := ReadingTime(words); got != want {
+			t.Errorf("%d words: expected %s, got %s", words, want, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	s := Stats{Words: 1200, Headings: 1, Links: 0, CodeBlocks: 3}.String()
+	for _, want := range []string{"1,200 words", "5 min read", "1 heading ·", "0 links", "3 code blocks"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %q in %q", want, s)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// How stdin is read.
+const (
+	// stdinAuto streams pipes and reads files redirected to stdin whole.
+	stdinAuto = "auto"
+	// stdinStream renders input as it arrives.
+	stdinStream = "stream"
+	// stdinBuffer reads all input before rendering it once.
+	stdinBuffer = "buffer"
+)
+
+var stdinModes = []string{stdinAuto, stdinStream, stdinBuffer}
+
+var (
+	stdinMode     string
+	stdinFilename string
+)
+
+// validateStdinMode checks that mode is a supported way of reading stdin.
+func validateStdinMode(mode string) error {
+	for _, m := range stdinModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid stdin mode %q, must be one of: %s", mode, strings.Join(stdinModes, ", "))
+}
+
+// stdinHasInput reports whether stdin is piped or redirected, rather than a
+// terminal waiting for the user to type.
+func stdinHasInput() (bool, error) {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false, fmt.Errorf("unable to open file: %w", err)
+	}
+	return stat.Mode()&os.ModeCharDevice == 0 || stat.Size() > 0, nil
+}
+
+// stdinSource returns the source reading stdin, named after --filename or,
+// when a file is redirected to stdin, after the file, so code is detected
+// and relative links are resolved as for the file itself.
+func stdinSource() *source {
+	src := &source{reader: os.Stdin}
+	name := stdinFilename
+	if name == "" {
+		name = redirectedFile()
+	}
+	if name == "" {
+		return src
+	}
+	if abs, err := filepath.Abs(name); err == nil {
+		src.URL = abs
+	}
+	return src
+}
+
+// redirectedFile returns the path of the file redirected to stdin, where the
+// system tells, or "" if stdin isn't a file or its path is unknown, e.g. for
+// here-documents written to deleted temporary files.
+func redirectedFile() string {
+	info, err := os.Stdin.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	path, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", os.Stdin.Fd()))
+	if err != nil || !filepath.IsAbs(path) {
+		return ""
+	}
+	if linked, err := os.Stat(path); err != nil || !os.SameFile(info, linked) {
+		return ""
+	}
+	return path
+}
+
+// streamStdin reports whether stdin is rendered as it arrives. Pipes are,
+// unless --stdin-mode says otherwise, while files and terminals are read
+// whole.
+func streamStdin() bool {
+	switch stdinMode {
+	case stdinStream:
+		return true
+	case stdinBuffer:
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&(os.ModeNamedPipe|os.ModeSocket) != 0
+}
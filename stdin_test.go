@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStdinSource(t *testing.T) {
+	defer func(f *os.File, name, mode string) {
+		os.Stdin, stdinFilename, stdinMode = f, name, mode
+	}(os.Stdin, stdinFilename, stdinMode)
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close() //nolint:errcheck
+	os.Stdin, stdinFilename, stdinMode = f, "", stdinAuto
+
+	if streamStdin() {
+		t.Error("expected a redirected file to be read whole")
+	}
+	if runtime.GOOS == "linux" {
+		if src := stdinSource(); src.URL != path || src.isMarkdown() {
+			t.Errorf("expected stdin to be named after the redirected file, got %q", src.URL)
+		}
+	}
+
+	stdinFilename = "notes.md"
+	abs, _ := filepath.Abs("notes.md")
+	if src := stdinSource(); src.URL != abs || !src.isMarkdown() {
+		t.Errorf("expected stdin to be named after --filename, got %q", src.URL)
+	}
+
+	stdinMode = stdinStream
+	if !streamStdin() {
+		t.Error("expected --stdin-mode=stream to stream a file")
+	}
+	if err := validateStdinMode("lines"); err == nil {
+		t.Error("expected an invalid stdin mode")
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/stream"
+	"github.com/spf13/cobra"
+)
+
+// Streaming modes for piped stdin.
+const (
+	// streamLine re-renders as complete lines arrive.
+	streamLine = "line"
+	// streamLLM handles token-at-a-time input, as produced by language
+	// models, where lines arrive in many small pieces.
+	streamLLM = "llm"
+)
+
+var streamModes = []string{streamLine, streamLLM}
+
+const (
+	// llmDebounce is how long we wait for more input before rendering a
+	// partial line.
+	llmDebounce = 75 * time.Millisecond
+	// llmMaxDelay caps how long a steady stream of tokens can hold off a
+	// render.
+	llmMaxDelay = 250 * time.Millisecond
+	// idleFlush is how long input can pause before a partial word is
+	// rendered, when rendering words as they arrive.
+	idleFlush = 250 * time.Millisecond
+)
+
+// validateStreamMode checks that mode is a supported streaming mode.
+func validateStreamMode(mode string) error {
+	for _, m := range streamModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid stream mode %q, must be one of: %s", mode, strings.Join(streamModes, ", "))
+}
+
+// streamRender returns the function rendering a stream, wrapping at wrap.
+func streamRender(src *source, wrap uint) (func(string) (string, error), string, error) {
+	r, baseURL, err := setupRendererWidth(src, wrap)
+	if err != nil {
+		return nil, "", err
+	}
+	return func(md string) (string, error) {
+		out, err := renderDocumentWidth(r, md, wrap)
+		if err != nil {
+			return "", fmt.Errorf("unable to render markdown: %w", err)
+		}
+		return out, nil
+	}, baseURL, nil
+}
+
+// enterScreen starts showing frames on the screen chosen with
+// --stream-screen. Frames are appended when the cursor position, which
+// inline frames start at, can't be read. Frames are shown inline on
+// terminals without an alternate screen, and not at all on those that can't
+// move the cursor.
+func enterScreen(t *stream.Terminal, w io.Writer) error {
+	t.SetProfile(termProfile)
+	if !termProfile.CursorMovement {
+		return nil
+	}
+	screen, _ := stream.ParseScreen(streamScreen)
+	if screen == stream.ScreenAlt && !termProfile.AltScreen {
+		screen = stream.ScreenInline
+	}
+	switch screen {
+	case stream.ScreenInline:
+		pos, err := saveTerminalPosition(w)
+		if err != nil {
+			t.EnterAppend()
+			return err
+		}
+		return t.EnterInline(pos.row) //nolint:wrapcheck
+	case stream.ScreenAppend:
+		t.EnterAppend()
+		return nil
+	default:
+		return t.EnterAltScreen() //nolint:wrapcheck
+	}
+}
+
+// renderStream reads piped stdin and renders the document as it grows, on
+// the screen chosen with --stream-screen when writing to a terminal. The
+// final rendering is written once the input ends, or on Ctrl-C.
+func renderStream(_ *cobra.Command, src *source, w io.Writer, useSpinner bool) error {
+	t := stream.NewTerminal(w)
+	if err := enterScreen(t, w); err != nil {
+		// If we can't show frames, continue without them
+		log.Debug("failed to enter stream screen", "err", err)
+	}
+	// Make sure we always restore the terminal
+	defer func() {
+		if err := t.Exit(); err != nil {
+			log.Debug("failed to exit stream screen", "err", err)
+		}
+	}()
+
+	render, baseURL, err := streamRender(src, width)
+	if err != nil {
+		return err
+	}
+	opts := stream.Options{
+		Render: render,
+		Prepare: func(input []byte) string {
+			return prepareMarkdown(src, input)
+		},
+	}
+	if t.Active() {
+		// Only the final rendering is visible when we can't repaint.
+		opts.Frame = t.Update
+	}
+	opts.Granularity, _ = stream.ParseGranularity(streamGranular)
+	if streamMode == streamLLM {
+		opts.Debounce = llmDebounce
+		opts.MaxDelay = llmMaxDelay
+	} else if opts.Granularity == stream.GranularityWord {
+		opts.Debounce = idleFlush
+	}
+	if streamDebounce > 0 {
+		opts.Debounce = streamDebounce
+	}
+	if streamMaxFPS > 0 {
+		opts.MinInterval = time.Second / time.Duration(streamMaxFPS)
+	}
+	s, err := stream.New(opts)
+	if err != nil {
+		return err
+	}
+
+	if t.Active() {
+		// Wrap at the new width after a resize, rather than leaving the
+		// document wrapped for the old one.
+		stop := onResize(func() {
+			wrap := width
+			if cols := t.Resize(); widthDetected {
+				wrap = wrapWidth(cols)
+			}
+			render, _, err := streamRender(src, wrap)
+			if err == nil {
+				err = s.SetRender(render)
+			}
+			if err != nil {
+				log.Debug("unable to render after resize", "err", err)
+			}
+		})
+		defer stop()
+	}
+
+	var dst io.Writer = s
+	if useSpinner && t.Active() {
+		sp := stream.NewSpinner(GetSpinnerType(spinnerName))
+		if spinnerColorStr != "" {
+			sp.SetColor(spinnerColorStr)
+		}
+		sp.Start(w)
+		defer sp.Stop()
+		dst = io.MultiWriter(s, sp)
+	}
+
+	// Ctrl-C ends the stream like the end of the input would, showing what
+	// arrived so far and restoring the terminal.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := stream.Copy(ctx, dst, src.reader); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("unable to stream input: %w", err)
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+
+	// Exit alternate screen and output the final render to normal screen
+	out := s.Output()
+	if useHyperlinks(w, false) {
+		out = hyperlink(out, src, "", baseURL)
+	}
+	if err := t.Finish(out); err != nil {
+		return fmt.Errorf("failed to output final content: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// blockParser parses markdown the way glamour does.
+var blockParser = goldmark.New(goldmark.WithExtensions(extension.GFM, extension.DefinitionList)).Parser()
+
+// blockTracker renders a growing markdown document incrementally. The
+// document is split into top-level blocks; blocks that no further input can
+// change are rendered once and cached, and only the open blocks at the end
+// are rendered again as more input arrives.
+//
+// More input can only change the last block, or turn a paragraph right after
+// a definition list into a term of that list. The exception are reference
+// link definitions, which apply to the whole document: cached blocks using a
+// newly defined label are rendered again.
+type blockTracker struct {
+	render func(string) (string, error)
+
+	// Renderers wrap documents in a prefix and suffix, which are removed
+	// from the blocks before joining them. Blocks can't be joined if the
+	// renderer's wrapping isn't known.
+	prefix, suffix string
+	joinable       bool
+
+	src    string // document the cached blocks were taken from
+	stable int    // length of src made up of cached blocks
+	blocks []renderedBlock
+	refs   map[string]string // definitions of reference links, by label
+
+	// Last rendering of the open blocks.
+	openSrc, openOut string
+}
+
+type renderedBlock struct {
+	src, out string
+	nodes    int // number of top-level nodes in src
+}
+
+// newBlockTracker returns a tracker rendering markdown with render.
+func newBlockTracker(render func(string) (string, error)) (*blockTracker, error) {
+	t := &blockTracker{render: render, refs: map[string]string{}}
+	empty, err := render("")
+	if err != nil {
+		return nil, err
+	}
+	half := len(empty) / 2
+	t.prefix, t.suffix = empty[:half], empty[half:]
+	t.joinable = t.prefix == t.suffix
+	return t, nil
+}
+
+// update renders the document, which is expected to have grown since the
+// last update. Documents that changed otherwise are rendered from scratch.
+func (t *blockTracker) update(md string) (string, error) {
+	if !t.joinable {
+		return t.render(md)
+	}
+	if len(md) < t.stable || md[:t.stable] != t.src[:t.stable] {
+		t.reset()
+	}
+	t.src = md
+
+	boundary, nodes, refs := scanBlocks(md[t.stable:])
+	if t.define(refs) {
+		boundary, nodes, _ = scanBlocks(md[t.stable:])
+	}
+
+	if boundary > 0 {
+		block := md[t.stable : t.stable+boundary]
+		out, err := t.renderBlock(block)
+		if err != nil {
+			return "", err
+		}
+		t.blocks = append(t.blocks, renderedBlock{src: block, out: out, nodes: nodes})
+		t.stable += boundary
+		t.openSrc, t.openOut = "", ""
+	}
+
+	if open := md[t.stable:]; open != t.openSrc || t.openOut == "" {
+		out, err := t.renderBlock(open)
+		if err != nil {
+			return "", err
+		}
+		t.openSrc, t.openOut = open, out
+	}
+
+	var b strings.Builder
+	b.WriteString(t.prefix)
+	for _, block := range t.blocks {
+		b.WriteString(block.out)
+	}
+	b.WriteString(t.openOut)
+	b.WriteString(t.suffix)
+	return b.String(), nil
+}
+
+func (t *blockTracker) reset() {
+	t.stable = 0
+	t.blocks = nil
+	t.refs = map[string]string{}
+	t.openSrc, t.openOut = "", ""
+}
+
+// define records reference link definitions, dropping the cached blocks from
+// the first one that may use a new or changed label. It reports whether any
+// blocks were dropped.
+func (t *blockTracker) define(refs map[string]string) bool {
+	first := len(t.blocks)
+	for label, def := range refs {
+		if t.refs[label] == def {
+			continue
+		}
+		t.refs[label] = def
+		needle := "[" + strings.ToLower(label)
+		for i, block := range t.blocks[:first] {
+			if strings.Contains(strings.ToLower(block.src), needle) {
+				first = i
+				break
+			}
+		}
+	}
+	if first == len(t.blocks) {
+		return false
+	}
+	for _, block := range t.blocks[first:] {
+		t.stable -= len(block.src)
+	}
+	t.blocks = t.blocks[:first]
+	t.openSrc, t.openOut = "", ""
+	return true
+}
+
+// renderBlock renders the part of the document following the cached blocks
+// with all known reference link definitions, without the document's prefix
+// and suffix.
+func (t *blockTracker) renderBlock(md string) (string, error) {
+	var b strings.Builder
+	if t.precededByNodes() {
+		// Paragraphs and headings are spaced differently at the start of a
+		// document, so give them something to follow that renders nothing.
+		b.WriteString(spacer)
+	}
+	b.WriteString(md)
+	if len(t.refs) > 0 {
+		b.WriteString("\n\n")
+		for _, def := range t.refs {
+			b.WriteString(def)
+		}
+	}
+	out, err := t.render(b.String())
+	if err != nil {
+		return "", err
+	}
+	out = strings.TrimPrefix(out, t.prefix)
+	return strings.TrimSuffix(out, t.suffix), nil
+}
+
+// spacer is a block that renders to nothing.
+const spacer = "<!-- -->\n\n"
+
+// precededByNodes reports whether the cached blocks contain anything but
+// reference link definitions, which don't show up in the parsed document.
+func (t *blockTracker) precededByNodes() bool {
+	for _, block := range t.blocks {
+		if block.nodes > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// scanBlocks parses the open part of a document. It returns the length of
+// the leading blocks that are final, ending at the start of a line, the
+// number of top-level nodes in them, and the reference link definitions
+// found.
+func scanBlocks(md string) (int, int, map[string]string) {
+	src := []byte(md)
+	pc := parser.NewContext()
+	doc := blockParser.Parse(text.NewReader(src), parser.WithContext(pc))
+
+	refs := map[string]string{}
+	for _, r := range pc.References() {
+		def := fmt.Sprintf("[%s]: <%s>", r.Label(), r.Destination())
+		if len(r.Title()) > 0 {
+			def += fmt.Sprintf(" (%s)", strings.NewReplacer("(", `\(`, ")", `\)`).Replace(string(r.Title())))
+		}
+		refs[string(r.Label())] = def + "\n"
+	}
+
+	open := doc.LastChild()
+	if open == nil {
+		return 0, 0, refs
+	}
+	if prev := open.PreviousSibling(); prev != nil && prev.Kind() == extast.KindDefinitionList && open.Kind() == ast.KindParagraph {
+		open = prev
+	}
+	for n := open; n != nil; n = n.PreviousSibling() {
+		if start := blockStart(n, src); start >= 0 {
+			nodes := 0
+			for p := n.PreviousSibling(); p != nil; p = p.PreviousSibling() {
+				nodes++
+			}
+			return lineStart(src, start), nodes, refs
+		}
+	}
+	return 0, 0, refs
+}
+
+// blockStart returns the offset of the first line of a block, or -1 if it
+// can't be told from the parsed segments.
+func blockStart(n ast.Node, src []byte) int {
+	start := -1
+	see := func(pos int) {
+		if start < 0 || pos < start {
+			start = pos
+		}
+	}
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.FencedCodeBlock:
+			// The opening fence isn't part of the block's lines.
+			switch {
+			case c.Info != nil:
+				see(c.Info.Segment.Start)
+			case c.Lines().Len() > 0:
+				if ls := lineStart(src, c.Lines().At(0).Start); ls > 0 {
+					see(ls - 1)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			see(c.Segment.Start)
+		default:
+			if c.Type() == ast.TypeBlock && c.Lines().Len() > 0 {
+				see(c.Lines().At(0).Start)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return start
+}
+
+// lineStart returns the offset of the start of the line containing pos.
+func lineStart(src []byte, pos int) int {
+	for pos > 0 && src[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;:]*m`)
+
+// stripSGR removes the styles from s.
+func stripSGR(s string) string {
+	return sgrPattern.ReplaceAllString(s, "")
+}
+
+// screenLines splits a frame into the lines it takes on a screen of the
+// given width. Each line starts with the styles left open by the lines
+// above it, so it can be painted on its own.
+func screenLines(frame string, width int) []string {
+	wrapped, _ := utils.Hardwrap(frame, width, 0)
+	lines := strings.Split(wrapped, "\n")
+	var open string
+	for i, l := range lines {
+		lines[i] = open + l
+		for _, sgr := range sgrPattern.FindAllString(l, -1) {
+			switch params := sgr[2 : len(sgr)-1]; {
+			case params == "" || params == "0":
+				open = ""
+			case strings.HasPrefix(params, "0;"):
+				open = sgr
+			default:
+				open += sgr
+			}
+		}
+	}
+	return lines
+}
+
+// hunk is a run of lines that differ between two versions of a screen:
+// del lines from line a of the old one are replaced by ins lines from line
+// b of the new one.
+type hunk struct {
+	a, b     int
+	del, ins int
+}
+
+// diffLines returns the hunks turning the lines of a into those of b, found
+// with Myers' algorithm so as many lines as possible are kept.
+func diffLines(a, b []string) []hunk {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the edits back from the end, then collect them into hunks from
+	// the top.
+	var kept [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			kept = append(kept, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+
+	var hunks []hunk
+	x, y = 0, 0
+	for i := len(kept) - 1; i >= -1; i-- {
+		nextX, nextY := n, m
+		if i >= 0 {
+			nextX, nextY = kept[i][0], kept[i][1]
+		}
+		if nextX > x || nextY > y {
+			hunks = append(hunks, hunk{a: x, b: y, del: nextX - x, ins: nextY - y})
+		}
+		x, y = nextX+1, nextY+1
+	}
+	return hunks
+}
+
+// repaint returns the escape sequences turning the lines shown from a row of
+// the screen, counting from 0, into next, which must fit below it. Lines
+// that are kept are moved by deleting and inserting lines around them,
+// rather than written again.
+func repaint(shown, next []string, row int) string {
+	hunks := diffLines(shown, next)
+	var b strings.Builder
+	b.WriteString("\033[0m")
+
+	// Delete lines first, from the bottom, so inserting lines further up
+	// never pushes kept lines off the screen.
+	for i := len(hunks) - 1; i >= 0; i-- {
+		h := hunks[i]
+		if h.del > h.ins {
+			fmt.Fprintf(&b, "\033[%d;1H\033[%dM", row+h.a+h.ins+1, h.del-h.ins)
+		}
+	}
+	for _, h := range hunks {
+		for i := range h.ins {
+			fmt.Fprintf(&b, "\033[%d;1H", row+h.b+i+1)
+			if i >= h.del {
+				b.WriteString("\033[L")
+			}
+			b.WriteString("\033[2K" + next[h.b+i] + "\033[0m")
+		}
+	}
+	return b.String()
+}
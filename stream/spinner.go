@@ -0,0 +1,328 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SpinnerType represents different styles of spinner animations
+type SpinnerType string
+
+const (
+	SpinnerDots          SpinnerType = "dots"
+	SpinnerDots2         SpinnerType = "dots2"
+	SpinnerDots3         SpinnerType = "dots3"
+	SpinnerDots4         SpinnerType = "dots4"
+	SpinnerDots5         SpinnerType = "dots5"
+	SpinnerDots6         SpinnerType = "dots6"
+	SpinnerDots7         SpinnerType = "dots7"
+	SpinnerDots8         SpinnerType = "dots8"
+	SpinnerDots9         SpinnerType = "dots9"
+	SpinnerDots10        SpinnerType = "dots10"
+	SpinnerDots11        SpinnerType = "dots11"
+	SpinnerDots12        SpinnerType = "dots12"
+	SpinnerDots13        SpinnerType = "dots13"
+	SpinnerLine          SpinnerType = "line"
+	SpinnerLine2         SpinnerType = "line2"
+	SpinnerPipe          SpinnerType = "pipe"
+	SpinnerSimpleDots    SpinnerType = "simpleDots"
+	SpinnerStar          SpinnerType = "star"
+	SpinnerStar2         SpinnerType = "star2"
+	SpinnerFlip          SpinnerType = "flip"
+	SpinnerBalloon       SpinnerType = "balloon"
+	SpinnerBalloon2      SpinnerType = "balloon2"
+	SpinnerNoise         SpinnerType = "noise"
+	SpinnerBounce        SpinnerType = "bounce"
+	SpinnerBoxBounce     SpinnerType = "boxBounce"
+	SpinnerCircle        SpinnerType = "circle"
+	SpinnerSquareCorners SpinnerType = "squareCorners"
+	SpinnerCircleHalves  SpinnerType = "circleHalves"
+	SpinnerToggle        SpinnerType = "toggle"
+	SpinnerArrow         SpinnerType = "arrow"
+	SpinnerBouncingBar   SpinnerType = "bouncingBar"
+	SpinnerBouncingBall  SpinnerType = "bouncingBall"
+	SpinnerBinary        SpinnerType = "binary"
+)
+
+// SpinnerDefinition defines the appearance and behavior of a spinner
+type SpinnerDefinition struct {
+	Interval time.Duration
+	Frames   []string
+}
+
+var spinnersMu sync.RWMutex
+
+// Available spinner definitions, by type
+var spinners = map[SpinnerType]SpinnerDefinition{
+	SpinnerDots: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏",
+		},
+	},
+	SpinnerDots2: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷",
+		},
+	},
+	SpinnerDots3: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"⠋", "⠙", "⠚", "⠞", "⠖", "⠦", "⠴", "⠲", "⠳", "⠓",
+		},
+	},
+	SpinnerDots4: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"⠄", "⠆", "⠇", "⠋", "⠙", "⠸", "⠰", "⠠", "⠰", "⠸", "⠙", "⠋", "⠇", "⠆",
+		},
+	},
+	SpinnerLine: {
+		Interval: 115 * time.Millisecond,
+		Frames: []string{
+			"-", "\\", "|", "/",
+		},
+	},
+	SpinnerLine2: {
+		Interval: 75 * time.Millisecond,
+		Frames: []string{
+			"⠂", "-", "–", "—", "–", "-",
+		},
+	},
+	SpinnerPipe: {
+		Interval: 75 * time.Millisecond,
+		Frames: []string{
+			"┤", "┘", "┴", "└", "├", "┌", "┬", "┐",
+		},
+	},
+	SpinnerSimpleDots: {
+		Interval: 200 * time.Millisecond,
+		Frames: []string{
+			".  ", ".. ", "...", "   ",
+		},
+	},
+	SpinnerStar: {
+		Interval: 80 * time.Millisecond,
+		Frames: []string{
+			"✶", "✸", "✹", "✺", "✹", "✷",
+		},
+	},
+	SpinnerBounce: {
+		Interval: 80 * time.Millisecond,
+		Frames: []string{
+			"⠁", "⠂", "⠄", "⠂",
+		},
+	},
+	SpinnerBoxBounce: {
+		Interval: 80 * time.Millisecond,
+		Frames: []string{
+			"▖", "▘", "▝", "▗",
+		},
+	},
+	SpinnerCircle: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"◡", "⊙", "◠",
+		},
+	},
+	SpinnerCircleHalves: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"◐", "◓", "◑", "◒",
+		},
+	},
+	SpinnerToggle: {
+		Interval: 175 * time.Millisecond,
+		Frames: []string{
+			"⊶", "⊷",
+		},
+	},
+	SpinnerArrow: {
+		Interval: 100 * time.Millisecond,
+		Frames: []string{
+			"←", "↖", "↑", "↗", "→", "↘", "↓", "↙",
+		},
+	},
+	SpinnerBouncingBar: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"[    ]", "[=   ]", "[==  ]", "[=== ]", "[====]", "[ ===]", "[  ==]", "[   =]",
+			"[    ]", "[   =]", "[  ==]", "[ ===]", "[====]", "[=== ]", "[==  ]", "[=   ]",
+		},
+	},
+	SpinnerBouncingBall: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			" ●    ", "  ●   ", "   ●  ", "    ● ", "     ●", "    ● ",
+			"   ●  ", "  ●   ", " ●    ", "●     ",
+		},
+	},
+	SpinnerBinary: {
+		Interval: 60 * time.Millisecond,
+		Frames: []string{
+			"010010", "001100", "100101", "111010", "111101", "010111",
+			"101011", "111000", "110011", "110101",
+		},
+	},
+}
+
+// SpinnerStyle is the style spinners are drawn in, unless given a color.
+var SpinnerStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("#FFFFFF"))
+
+// RegisterSpinner adds a spinner definition under the given type, replacing
+// any existing spinner of the same type, whatever its case.
+func RegisterSpinner(st SpinnerType, def SpinnerDefinition) {
+	spinnersMu.Lock()
+	defer spinnersMu.Unlock()
+	maps.DeleteFunc(spinners, func(t SpinnerType, _ SpinnerDefinition) bool {
+		return strings.EqualFold(string(t), string(st))
+	})
+	spinners[st] = def
+}
+
+// LookupSpinner returns the definition of the spinner of the given type.
+// Types are matched ignoring case, as the names of spinners defined in the
+// config file are lowercased.
+func LookupSpinner(st SpinnerType) (SpinnerDefinition, bool) {
+	spinnersMu.RLock()
+	defer spinnersMu.RUnlock()
+	if def, ok := spinners[st]; ok {
+		return def, true
+	}
+	for t, def := range spinners {
+		if strings.EqualFold(string(t), string(st)) {
+			return def, true
+		}
+	}
+	return SpinnerDefinition{}, false
+}
+
+// Spinners returns the types of all registered spinners, sorted by name.
+func Spinners() []SpinnerType {
+	spinnersMu.RLock()
+	defer spinnersMu.RUnlock()
+	types := make([]SpinnerType, 0, len(spinners))
+	for st := range spinners {
+		types = append(types, st)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Spinner manages the animation state for spinner indicators
+type Spinner struct {
+	definition SpinnerDefinition
+	current    int
+	active     bool
+	lastUpdate time.Time
+	msgChan    chan struct{}
+	stopChan   chan struct{}
+	style      lipgloss.Style
+	styled     bool // Whether to apply color styling
+}
+
+// NewSpinner creates a new spinner with the specified type
+func NewSpinner(st SpinnerType) *Spinner {
+	def, ok := LookupSpinner(st)
+	if !ok {
+		// Default to dots if the specified spinner is not found
+		def, _ = LookupSpinner(SpinnerDots)
+	}
+
+	return &Spinner{
+		definition: def,
+		msgChan:    make(chan struct{}, 1),
+		stopChan:   make(chan struct{}),
+		lastUpdate: time.Now(),
+		style:      SpinnerStyle,
+		styled:     true, // Enable styling by default
+	}
+}
+
+// Start begins the spinner animation in a separate goroutine
+func (s *Spinner) Start(w io.Writer) {
+	s.active = true
+
+	go func() {
+		ticker := time.NewTicker(s.definition.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopChan:
+				// Clear the spinner animation
+				fmt.Fprint(w, "\r\033[K")
+				return
+
+			case <-s.msgChan:
+				// Message received, reset animation timer
+				s.lastUpdate = time.Now()
+
+			case <-ticker.C:
+				// Only show spinner if we've been waiting for a while (100ms)
+				if time.Since(s.lastUpdate) > 100*time.Millisecond {
+					s.current = (s.current + 1) % len(s.definition.Frames)
+					frame := s.definition.Frames[s.current]
+
+					// Apply styling if enabled
+					if s.styled {
+						frame = s.style.Render(frame)
+					}
+
+					fmt.Fprintf(w, "\r\033[K%s", frame) // Clear line and print frame
+				}
+			}
+		}
+	}()
+}
+
+// Update signals that new data was received
+func (s *Spinner) Update() {
+	if s.active {
+		// Non-blocking send to avoid hangs if channel is full
+		select {
+		case s.msgChan <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Write signals that data was received, so a copy of a stream can be
+// written to a spinner with io.MultiWriter.
+func (s *Spinner) Write(p []byte) (int, error) {
+	s.Update()
+	return len(p), nil
+}
+
+// Stop terminates the spinner animation
+func (s *Spinner) Stop() {
+	if s.active {
+		s.active = false
+		close(s.stopChan)
+	}
+}
+
+// SetColor changes the spinner color
+func (s *Spinner) SetColor(color string) {
+	s.style = s.style.Foreground(lipgloss.Color(color))
+}
+
+// DisableStyling turns off color and bold styling
+func (s *Spinner) DisableStyling() {
+	s.styled = false
+}
+
+// EnableStyling turns on color and bold styling
+func (s *Spinner) EnableStyling() {
+	s.styled = true
+}
@@ -0,0 +1,348 @@
+// Package stream renders markdown incrementally as it's written, the way
+// glow renders piped input.
+//
+// A Streamer is an io.Writer: copy a stream of markdown into it and it
+// passes each rendering of the document, or frame, to a callback. Blocks of
+// the document no further input can change are only rendered once, so
+// rendering stays cheap as the document grows. A Terminal shows the frames
+// on a terminal, on its alternate screen or below the cursor, and a Spinner
+// shows that input is still arriving. Copy feeds a Streamer until the input
+// ends or its context is canceled, e.g. on Ctrl-C:
+//
+//	term := stream.NewTerminal(os.Stdout)
+//	if err := term.EnterAltScreen(); err != nil {
+//		return err
+//	}
+//	defer term.ExitAltScreen()
+//
+//	s, err := stream.New(stream.Options{
+//		Render: r.Render,
+//		Frame:  term.Update,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if err := stream.Copy(ctx, s, os.Stdin); err != nil && ctx.Err() == nil {
+//		return err
+//	}
+//	if err := s.Close(); err != nil {
+//		return err
+//	}
+//	return term.Finish(s.Output())
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when writing to a closed Streamer.
+var ErrClosed = errors.New("stream: write to closed streamer")
+
+// Granularity is how much of the input has to arrive before it's rendered.
+type Granularity string
+
+const (
+	// GranularityLine renders complete lines.
+	GranularityLine Granularity = "line"
+	// GranularityWord renders complete words, so that long lines, like
+	// those of minified content, show up as they arrive.
+	GranularityWord Granularity = "word"
+	// GranularityChunk renders whatever has been written.
+	GranularityChunk Granularity = "chunk"
+)
+
+var granularities = []Granularity{GranularityLine, GranularityWord, GranularityChunk}
+
+// ParseGranularity returns the granularity named s.
+func ParseGranularity(s string) (Granularity, error) {
+	for _, g := range granularities {
+		if string(g) == s {
+			return g, nil
+		}
+	}
+	names := make([]string, len(granularities))
+	for i, g := range granularities {
+		names[i] = string(g)
+	}
+	return "", fmt.Errorf("invalid stream granularity %q, expected one of: %s", s, strings.Join(names, ", "))
+}
+
+// end returns how much of b can be rendered right away: up to the last line
+// or word boundary, or all of it.
+func (g Granularity) end(b []byte) int {
+	switch g {
+	case GranularityChunk:
+		return len(b)
+	case GranularityWord:
+		return bytes.LastIndexAny(b, " \t\n") + 1
+	default:
+		return bytes.LastIndexByte(b, '\n') + 1
+	}
+}
+
+// Options configure a Streamer.
+type Options struct {
+	// Render renders a markdown document, e.g. with a glamour.TermRenderer.
+	// It's required.
+	Render func(md string) (string, error)
+
+	// Prepare turns the input written so far into the markdown document to
+	// render. By default, the input is rendered as is.
+	Prepare func(input []byte) string
+
+	// Frame is called with each rendering of the document as it grows, and
+	// with the final rendering when the Streamer is closed. Calls are never
+	// concurrent; an error stops the stream. If Frame is nil, the document
+	// is only rendered when the Streamer is closed.
+	Frame func(frame string) error
+
+	// Debounce is how long to wait for more input before rendering a
+	// partial line. Zero waits for lines to be complete, which suits input
+	// written a line at a time; token-at-a-time input, as produced by
+	// language models, is better rendered after a short pause.
+	Debounce time.Duration
+
+	// MaxDelay caps how long a steady stream of partial lines can hold off
+	// a render. It only applies with a Debounce; zero means no cap.
+	MaxDelay time.Duration
+
+	// Granularity is how much of the input is rendered as soon as it
+	// arrives, complete lines by default. The rest waits for the Debounce,
+	// or for more input.
+	Granularity Granularity
+
+	// MinInterval is the least time between renders as input arrives, e.g.
+	// a second divided by the most frames per second a slow terminal can
+	// show. Input arriving sooner is rendered together once the interval
+	// has passed. Zero renders input as soon as it's ready.
+	MinInterval time.Duration
+}
+
+// Streamer renders markdown as it's written. It's safe for concurrent use.
+type Streamer struct {
+	opts    Options
+	tracker *blockTracker
+
+	mu         sync.Mutex
+	input      bytes.Buffer
+	pending    bool // whether input hasn't been rendered yet
+	rendered   int  // how much of the input was last rendered
+	lastRender time.Time
+	timer      *time.Timer
+	throttle   *time.Timer
+	throttled  int // how much of the input the throttled render covers
+	out        string
+	err        error // error of a debounced render
+	closed     bool
+}
+
+// New returns a Streamer rendering with the given options.
+func New(opts Options) (*Streamer, error) {
+	if opts.Render == nil {
+		return nil, errors.New("stream: no render function")
+	}
+	if opts.Prepare == nil {
+		opts.Prepare = func(input []byte) string { return string(input) }
+	}
+	tracker, err := newBlockTracker(opts.Render)
+	if err != nil {
+		return nil, err
+	}
+	return &Streamer{opts: opts, tracker: tracker}, nil
+}
+
+// Write adds p to the document, rendering it up to the last complete line,
+// or word with GranularityWord, and the rest once no more input arrives for
+// the Debounce.
+func (s *Streamer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.input.Write(p)
+	s.pending = true
+	if s.opts.Frame == nil {
+		return len(p), nil
+	}
+
+	input := s.input.Bytes()
+	var ready []byte
+	switch {
+	case s.opts.Debounce > 0 && s.opts.MaxDelay > 0 && time.Since(s.lastRender) > s.opts.MaxDelay:
+		ready = input
+	case s.opts.Granularity.end(p) > 0:
+		ready = input[:s.opts.Granularity.end(input)]
+	}
+
+	if s.opts.Debounce > 0 {
+		switch {
+		case len(ready) == len(input):
+			if s.timer != nil {
+				s.timer.Stop()
+			}
+		case s.timer == nil:
+			s.timer = time.AfterFunc(s.opts.Debounce, s.debounced)
+		default:
+			s.timer.Reset(s.opts.Debounce)
+		}
+	}
+	if ready == nil {
+		return len(p), nil
+	}
+	if wait := s.opts.MinInterval - time.Since(s.lastRender); wait > 0 {
+		s.throttled = len(ready)
+		if s.throttle == nil {
+			s.throttle = time.AfterFunc(wait, s.throttledRender)
+		}
+		return len(p), nil
+	}
+	return len(p), s.render(ready)
+}
+
+// throttledRender renders the input held back by the MinInterval.
+func (s *Streamer) throttledRender() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttle = nil
+	if s.closed || s.throttled <= s.rendered || s.err != nil {
+		return
+	}
+	s.err = s.render(s.input.Bytes()[:s.throttled])
+}
+
+// debounced renders input that arrived since the last render, once the
+// input paused.
+func (s *Streamer) debounced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || !s.pending || s.err != nil {
+		return
+	}
+	s.err = s.render(s.input.Bytes())
+}
+
+// Flush renders the document written so far, including partial lines.
+func (s *Streamer) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.err != nil {
+		return s.err
+	}
+	if s.opts.Frame == nil || !s.pending {
+		return nil
+	}
+	return s.render(s.input.Bytes())
+}
+
+// Close renders the whole document from scratch, and passes the final
+// rendering to Frame. Writing to a closed Streamer fails.
+func (s *Streamer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.throttle != nil {
+		s.throttle.Stop()
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	out, err := s.opts.Render(s.opts.Prepare(s.input.Bytes()))
+	if err != nil {
+		return err
+	}
+	s.out = out
+	if s.opts.Frame != nil {
+		return s.opts.Frame(out)
+	}
+	return nil
+}
+
+// SetRender replaces the function rendering the document, e.g. with one
+// wrapping at a new terminal width, and renders the input written so far
+// again from scratch.
+func (s *Streamer) SetRender(render func(md string) (string, error)) error {
+	if render == nil {
+		return errors.New("stream: no render function")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	tracker, err := newBlockTracker(render)
+	if err != nil {
+		return err
+	}
+	s.opts.Render = render
+	s.tracker = tracker
+	if s.err != nil || s.opts.Frame == nil || s.input.Len() == 0 {
+		return s.err
+	}
+
+	input := s.input.Bytes()
+	if s.opts.Debounce == 0 {
+		// Partial lines still wait for the rest of the line.
+		input = input[:s.opts.Granularity.end(input)]
+	}
+	return s.render(input)
+}
+
+// Copy copies r to w, usually a Streamer, until r ends or ctx is canceled,
+// e.g. on Ctrl-C. It returns the error of ctx when canceled, after which
+// whatever was read can still be rendered by closing the Streamer. Reads
+// happen on another goroutine, which exits once a pending read returns.
+func Copy(ctx context.Context, w io.Writer, r io.Reader) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(w, r)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// Output returns the last rendering of the document.
+func (s *Streamer) Output() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out
+}
+
+// render renders input and passes the rendering to Frame. It's called with
+// the lock held.
+func (s *Streamer) render(input []byte) error {
+	s.pending = len(input) < s.input.Len()
+	s.rendered = len(input)
+	s.lastRender = time.Now()
+	out, err := s.tracker.update(s.opts.Prepare(input))
+	if err != nil {
+		return err
+	}
+	s.out = out
+	return s.opts.Frame(out)
+}
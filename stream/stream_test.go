@@ -0,0 +1,468 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+)
+
+const streamedDoc = "# Streaming\n\n" +
+	"A paragraph with a [reference][later] link\nand a footnote[^1].\n\n" +
+	"Setext heading\n--------------\n\n" +
+	"- one\n- two\n\n- loose\n\n" +
+	"```go\nfunc main() {}\n```\n\n" +
+	"| a | b |\n|---|---|\n| 1 | 2 |\n\n" +
+	"Term\n: definition\n\nOther term\n\n: another definition\n\n" +
+	"> quoted\ncontinued lazily\n\n" +
+	"***\n\n" +
+	"[later]: https://example.com\n" +
+	"[^1]: https://example.com/note\n\n" +
+	"Done.\n"
+
+func TestBlockTracker(t *testing.T) {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var renders int
+	render := func(md string) (string, error) {
+		renders++
+		return r.Render(md)
+	}
+	tracker, err := newBlockTracker(render)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc string
+	for _, line := range strings.SplitAfter(streamedDoc, "\n") {
+		doc += line
+		got, err := tracker.update(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := r.Render(doc)
+		if got != want {
+			t.Fatalf("rendering differs after %q:\n%q\n%q", line, got, want)
+		}
+	}
+	if lines := strings.Count(streamedDoc, "\n"); renders >= 2*lines {
+		t.Errorf("expected fewer renders than a full one for each of the %d lines, got %d", lines, renders)
+	}
+
+	// Partial lines, as written by language models.
+	tracker, _ = newBlockTracker(render)
+	for i := 1; i <= 120; i++ {
+		got, err := tracker.update(streamedDoc[:i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _ := r.Render(streamedDoc[:i]); got != want {
+			t.Fatalf("rendering differs after %q:\n%q\n%q", streamedDoc[:i], got, want)
+		}
+	}
+
+	// Changed documents are rendered from scratch.
+	got, _ := tracker.update("# Other\n\ntext\n")
+	if want, _ := r.Render("# Other\n\ntext\n"); got != want {
+		t.Errorf("expected a full render, got %q", got)
+	}
+}
+
+func TestStreamer(t *testing.T) {
+	render := func(md string) (string, error) { return "<" + md + ">", nil }
+
+	var frames []string
+	s, err := New(Options{
+		Render: render,
+		Frame: func(frame string) error {
+			frames = append(frames, frame)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Partial lines wait for the rest of the line.
+	s.Write([]byte("# Title\n\npart")) //nolint:errcheck
+	s.Write([]byte("ial"))             //nolint:errcheck
+	if len(frames) != 1 || frames[0] != "<# Title\n\n>" {
+		t.Fatalf("expected a frame of the complete lines, got %q", frames)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<# Title\n\npartial>"; s.Output() != want || frames[len(frames)-1] != want {
+		t.Errorf("expected the final rendering %q, got %q", want, s.Output())
+	}
+	if _, err := s.Write([]byte("more")); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+
+	// A new render function renders the input so far again.
+	frames = nil
+	s, _ = New(Options{
+		Render: render,
+		Frame: func(frame string) error {
+			frames = append(frames, frame)
+			return nil
+		},
+	})
+	s.Write([]byte("# Title\n\npart")) //nolint:errcheck
+	if err := s.SetRender(func(md string) (string, error) { return "[" + md + "]", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || frames[1] != "[# Title\n\n]" {
+		t.Errorf("expected a frame with the new render function, got %q", frames)
+	}
+	s.Close() //nolint:errcheck
+	if err := s.SetRender(render); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+
+	// Debounced partial lines are rendered once the input pauses.
+	done := make(chan string, 1)
+	s, _ = New(Options{
+		Render:   render,
+		Debounce: 10 * time.Millisecond,
+		Frame: func(frame string) error {
+			done <- frame
+			return nil
+		},
+	})
+	s.Write([]byte("tok")) //nolint:errcheck
+	s.Write([]byte("ens")) //nolint:errcheck
+	select {
+	case frame := <-done:
+		if frame != "<tokens>" {
+			t.Errorf("expected a frame of both tokens, got %q", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a debounced frame")
+	}
+	s.Close() //nolint:errcheck
+
+	// Without a Frame, the document is only rendered when closed.
+	renders := 0
+	s, _ = New(Options{Render: func(md string) (string, error) {
+		renders++
+		return md, nil
+	}})
+	s.Write([]byte("a\nb\n")) //nolint:errcheck
+	if err := s.Close(); err != nil || renders != 2 || s.Output() != "a\nb\n" {
+		t.Errorf("expected a single render when closed, got %d renders and %q", renders, s.Output())
+	}
+}
+
+// writerFunc is an io.Writer calling a function.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestCopy(t *testing.T) {
+	s, _ := New(Options{Render: func(md string) (string, error) { return md, nil }})
+	written := make(chan struct{}, 1)
+	dst := writerFunc(func(p []byte) (int, error) {
+		defer func() { written <- struct{}{} }()
+		return s.Write(p)
+	})
+	r, w := io.Pipe()
+	defer w.Close() //nolint:errcheck
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Copy(ctx, dst, r) }()
+
+	w.Write([]byte("# Title\n")) //nolint:errcheck
+	<-written
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the copy to be canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the copy to stop when canceled")
+	}
+	if err := s.Close(); err != nil || s.Output() != "# Title\n" {
+		t.Errorf("expected the input read before canceling to be rendered, got %q, %v", s.Output(), err)
+	}
+
+	s, _ = New(Options{Render: func(md string) (string, error) { return md, nil }})
+	if err := Copy(context.Background(), s, strings.NewReader("text\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil || s.Output() != "text\n" {
+		t.Errorf("expected the whole input, got %q, %v", s.Output(), err)
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	frames := make(chan string, 10)
+	s, _ := New(Options{
+		Render:      func(md string) (string, error) { return "<" + md + ">", nil },
+		MinInterval: 50 * time.Millisecond,
+		Frame: func(frame string) error {
+			frames <- frame
+			return nil
+		},
+	})
+	for _, line := range []string{"a\n", "b\n", "c\n", "partial"} {
+		s.Write([]byte(line)) //nolint:errcheck
+	}
+	if frame := <-frames; frame != "<a\n>" {
+		t.Errorf("expected the first line to be rendered right away, got %q", frame)
+	}
+	select {
+	case frame := <-frames:
+		if frame != "<a\nb\nc\n>" {
+			t.Errorf("expected the held back lines in one frame, got %q", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a frame once the interval passed")
+	}
+	if err := s.Close(); err != nil || <-frames != "<a\nb\nc\npartial>" {
+		t.Errorf("expected the final frame when closed, got %v", err)
+	}
+	if len(frames) > 0 {
+		t.Errorf("unexpected frames %q", <-frames)
+	}
+}
+
+func TestGranularity(t *testing.T) {
+	render := func(md string) (string, error) { return "<" + md + ">", nil }
+	for _, tt := range []struct {
+		granularity Granularity
+		want        []string
+	}{
+		{GranularityLine, []string{"<a long line\n>"}},
+		{GranularityWord, []string{"<a long >", "<a long line\nthat >", "<a long line\nthat never >"}},
+		{GranularityChunk, []string{"<a long li>", "<a long line\nthat ne>", "<a long line\nthat never ends>"}},
+	} {
+		var frames []string
+		s, err := New(Options{
+			Render:      render,
+			Granularity: tt.granularity,
+			Frame: func(frame string) error {
+				frames = append(frames, frame)
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []string{"a long li", "ne\nthat ne", "ver ends"} {
+			s.Write([]byte(p)) //nolint:errcheck
+		}
+		if strings.Join(frames, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: expected frames %q, got %q", tt.granularity, tt.want, frames)
+		}
+		s.Close() //nolint:errcheck
+	}
+
+	// The rest of the input is rendered once it pauses.
+	done := make(chan string, 2)
+	s, _ := New(Options{
+		Render:      render,
+		Granularity: GranularityWord,
+		Debounce:    10 * time.Millisecond,
+		Frame: func(frame string) error {
+			done <- frame
+			return nil
+		},
+	})
+	s.Write([]byte("minified content")) //nolint:errcheck
+	if frame := <-done; frame != "<minified >" {
+		t.Errorf("expected a frame of the complete word, got %q", frame)
+	}
+	select {
+	case frame := <-done:
+		if frame != "<minified content>" {
+			t.Errorf("expected a frame of the whole input, got %q", frame)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the partial word to be rendered after a pause")
+	}
+	s.Close() //nolint:errcheck
+
+	if _, err := ParseGranularity("byte"); err == nil {
+		t.Error("expected an error for an unknown granularity")
+	}
+}
+
+// screen emulates the escape sequences Terminal writes on a screen of
+// fixed height, keeping the lines scrolled off its top.
+type screen struct {
+	t          *testing.T
+	lines      []string
+	row        int
+	scrollback []string
+}
+
+func newScreen(t *testing.T, height int, lines ...string) *screen {
+	return &screen{t: t, lines: append(lines, make([]string, height-len(lines))...)}
+}
+
+var sequencePattern = regexp.MustCompile(`^\x1b\[\??([0-9;]*)([A-Za-z])`)
+
+func (s *screen) write(out string) {
+	s.t.Helper()
+	height := len(s.lines)
+	for out != "" {
+		switch out[0] {
+		case '\r':
+			out = out[1:]
+			continue
+		case '\n':
+			out = out[1:]
+			if s.row < height-1 {
+				s.row++
+			} else {
+				s.scrollback = append(s.scrollback, s.lines[0])
+				s.lines = append(s.lines[1:], "")
+			}
+			continue
+		}
+		m := sequencePattern.FindStringSubmatch(out)
+		if m == nil {
+			i := strings.IndexAny(out, "\x1b\r\n")
+			if i < 0 {
+				i = len(out)
+			}
+			s.lines[s.row] += out[:i]
+			out = out[i:]
+			continue
+		}
+		out = out[len(m[0]):]
+		n, err := strconv.Atoi(strings.Split(m[1], ";")[0])
+		if err != nil {
+			n = 1
+		}
+		switch m[2] {
+		case "H":
+			s.row = n - 1
+		case "J":
+			from := s.row
+			if n == 2 {
+				from = 0
+			}
+			for i := from; i < height; i++ {
+				s.lines[i] = ""
+			}
+		case "K":
+			s.lines[s.row] = ""
+		case "L":
+			s.lines = append(s.lines[:s.row], append([]string{""}, s.lines[s.row:height-1]...)...)
+		case "M":
+			s.lines = append(append(s.lines[:s.row:s.row], s.lines[s.row+n:]...), make([]string, n)...)
+		case "S":
+			s.lines = append(s.lines[n:], make([]string, n)...)
+		case "T":
+			s.lines = append(make([]string, n), s.lines[:height-n]...)
+		case "m", "h", "l":
+		default:
+			s.t.Fatalf("unexpected sequence %q", m[0])
+		}
+	}
+}
+
+func TestRepaint(t *testing.T) {
+	for _, tc := range []struct {
+		shown, next string
+		written     int
+	}{
+		{"a b c", "a b c d", 1},
+		{"a b c d", "a b", 0},
+		{"a b c", "x a b", 1},
+		{"a b c", "x a c", 1},
+		{"a b c d e", "a x y e", 2},
+		{"a b c d e", "e d c b a", 4},
+		{"", "a b", 2},
+		{"a b", "", 0},
+	} {
+		shown, next := strings.Fields(tc.shown), strings.Fields(tc.next)
+		out := repaint(shown, next, 0)
+		sc := newScreen(t, 5, shown...)
+		sc.write(out)
+		want := append(slices.Clone(next), make([]string, 5-len(next))...)
+		if !slices.Equal(sc.lines, want) {
+			t.Errorf("%q to %q: got %q", tc.shown, tc.next, sc.lines)
+		}
+		// Only lines that changed are written.
+		if got := strings.Count(out, "\x1b[2K"); got != tc.written {
+			t.Errorf("%q to %q: expected %d lines written, got %d", tc.shown, tc.next, tc.written, got)
+		}
+	}
+
+	// Reflowing a paragraph at the top doesn't repaint the lines below it.
+	hunks := diffLines(strings.Fields("p1 p2 x y z"), strings.Fields("p1' p2' p3' x y z"))
+	if len(hunks) != 1 || hunks[0] != (hunk{a: 0, b: 0, del: 2, ins: 3}) {
+		t.Errorf("expected one hunk for the paragraph, got %v", hunks)
+	}
+}
+
+func TestScreenLines(t *testing.T) {
+	got := screenLines("\x1b[1mbold\nstill\x1b[0m\nplain", 0)
+	want := []string{"\x1b[1mbold", "\x1b[1mstill\x1b[0m", "plain"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTerminalScreens(t *testing.T) {
+	frames := []string{"a\n", "a\nb\n", "a\nb b\nc\n", "a\nb b b\nc\nd\ne\n", "a\nb b b\nc\nd\ne\nf\n"}
+	final := frames[len(frames)-1]
+
+	// Frames shown on the alternate screen show the bottom of the
+	// document, with the cursor below it.
+	sc := newScreen(t, 4)
+	term := &Terminal{active: true, screen: ScreenAlt, height: 4}
+	for _, f := range frames {
+		sc.write(term.alt(f))
+	}
+	if want := []string{"d", "e", "f", ""}; !slices.Equal(sc.lines, want) || sc.row != 3 {
+		t.Errorf("alt: expected %q with the cursor on the last line, got %q on line %d", want, sc.lines, sc.row)
+	}
+
+	// Inline frames start below the prompt, and scroll it off the screen
+	// along with the top of the document.
+	sc = newScreen(t, 4, "$ glow")
+	sc.row = 1
+	term = &Terminal{active: true, screen: ScreenInline, height: 4, origin: 1}
+	for _, f := range frames {
+		sc.write(term.inline(f))
+	}
+	if want := []string{"$ glow", "a", "b b b", "c"}; !slices.Equal(sc.scrollback, want) {
+		t.Errorf("inline: expected scrollback %q, got %q", want, sc.scrollback)
+	}
+	if want := []string{"d", "e", "f", ""}; !slices.Equal(sc.lines, want) || sc.row != 3 {
+		t.Errorf("inline: expected %q with the cursor on the last line, got %q on line %d", want, sc.lines, sc.row)
+	}
+
+	// Appended lines are only written once they're settled, and together
+	// they make up the final rendering.
+	term = &Terminal{active: true, screen: ScreenAppend}
+	var out string
+	for _, f := range frames {
+		out += term.append(f)
+	}
+	if want := "\r\x1b[Ka\n\r\x1b[Kb b b\nc\nd\ne\n"; out != want {
+		t.Errorf("append: expected %q, got %q", want, out)
+	}
+	lines := strings.Split(final, "\n")
+	if rest := strings.Join(lines[term.written:], "\n"); stripSGR(strings.ReplaceAll(out, "\r\x1b[K", ""))+rest != final {
+		t.Errorf("append: %q and the rest, %q, don't make up %q", out, rest, final)
+	}
+
+	if _, err := ParseScreen("tab"); err == nil {
+		t.Error("expected an error for an unknown screen")
+	}
+}
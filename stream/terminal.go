@@ -0,0 +1,476 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"golang.org/x/term"
+)
+
+// Screen is where a Terminal shows the frames of a stream.
+type Screen string
+
+const (
+	// ScreenAlt shows frames on the alternate screen, and the final
+	// rendering on the normal screen once the stream ends.
+	ScreenAlt Screen = "alt"
+	// ScreenInline shows frames on the normal screen, from the line the
+	// cursor is on. Lines of frames taller than the screen scroll into the
+	// scrollback, where they're left as they were.
+	ScreenInline Screen = "inline"
+	// ScreenAppend never moves the cursor back: lines are written once the
+	// next frame leaves them unchanged, and the rest of the final rendering
+	// once the stream ends. Lines that change after they're written, like
+	// the rows of a table whose columns widen, are left as they were.
+	ScreenAppend Screen = "append"
+)
+
+var screens = []Screen{ScreenAlt, ScreenInline, ScreenAppend}
+
+// ParseScreen returns the screen named s.
+func ParseScreen(s string) (Screen, error) {
+	for _, sc := range screens {
+		if string(sc) == s {
+			return sc, nil
+		}
+	}
+	names := make([]string, len(screens))
+	for i, sc := range screens {
+		names[i] = string(sc)
+	}
+	return "", fmt.Errorf("invalid stream screen %q, expected one of: %s", s, strings.Join(names, ", "))
+}
+
+// Terminal shows the frames of a stream on a terminal, on the alternate
+// screen or the normal one, and then the final rendering. When not writing
+// to a terminal, only the final rendering is written.
+type Terminal struct {
+	active       bool
+	screen       Screen
+	isTerminal   bool
+	originalTerm *term.State
+	file         *os.File
+	w            io.Writer
+	// What the terminal can do, see SetProfile.
+	profile termprofile.Profile
+
+	// Guards the fields below, as the terminal can be resized while a
+	// frame is shown.
+	mu sync.Mutex
+
+	// Terminal size. Output is wrapped by display width rather than
+	// leaving it to the terminal.
+	width  int
+	height int
+
+	// Last frame, the lines of it on the screen and the first of them, and
+	// whether the next frame must be painted in full.
+	last    string
+	shown   []string
+	top     int
+	repaint bool
+
+	// Row of the screen the first line of frames is on with ScreenInline,
+	// negative once it scrolled off the top.
+	origin int
+
+	// Lines of the last frame and how many of them were written with
+	// ScreenAppend.
+	lines   []string
+	written int
+}
+
+// NewTerminal returns a Terminal writing to w.
+func NewTerminal(w io.Writer) *Terminal {
+	// Check if we're writing to a terminal
+	f, ok := w.(*os.File)
+	isTerminal := ok && term.IsTerminal(int(f.Fd()))
+
+	return &Terminal{
+		screen:     ScreenAlt,
+		isTerminal: isTerminal,
+		file:       f,
+		w:          w,
+		profile:    termprofile.Xterm,
+	}
+}
+
+// SetProfile sets what the terminal can do, which is assumed to be all an
+// xterm does otherwise. Escape sequences the terminal doesn't understand are
+// left out, and frames are shown at once when it can.
+func (t *Terminal) SetProfile(p termprofile.Profile) {
+	t.profile = p
+}
+
+// Active reports whether frames are shown as they arrive.
+func (t *Terminal) Active() bool {
+	return t.active
+}
+
+// EnterAltScreen switches to the alternate screen buffer. It does nothing
+// when not writing to a terminal.
+func (t *Terminal) EnterAltScreen() error {
+	if !t.isTerminal || t.active {
+		return nil
+	}
+
+	// Get current terminal settings
+	var err error
+	t.originalTerm, err = term.MakeRaw(int(t.file.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+
+	// Save current terminal size for proper formatting
+	width, height, err := term.GetSize(int(t.file.Fd()))
+	if err == nil {
+		// Set environment variables for terminal dimensions
+		// This helps glamour render with the correct width
+		os.Setenv("COLUMNS", fmt.Sprintf("%d", width))
+		os.Setenv("LINES", fmt.Sprintf("%d", height))
+		t.width, t.height = width, height
+	}
+
+	// Enter alternate screen buffer (smcup)
+	if t.profile.AltScreen {
+		if _, err := fmt.Fprint(t.file, "\033[?1049h"); err != nil {
+			return fmt.Errorf("failed to enter alternate screen: %w", err)
+		}
+	}
+
+	// Clear screen and move cursor to home position
+	if _, err := fmt.Fprint(t.file, "\033[2J\033[H"); err != nil {
+		return fmt.Errorf("failed to clear screen: %w", err)
+	}
+
+	// Set proper line wrapping mode
+	// Enable line wrapping (DECAWM)
+	if t.profile.AutoWrap {
+		if _, err := fmt.Fprint(t.file, "\033[?7h"); err != nil {
+			return fmt.Errorf("failed to set line wrapping: %w", err)
+		}
+	}
+
+	if err := t.hideCursor(); err != nil {
+		return err
+	}
+
+	t.screen = ScreenAlt
+	t.active = true
+	return nil
+}
+
+// EnterInline shows frames on the normal screen, from the given row the
+// cursor is on, counting from 1. It does nothing when not writing to a
+// terminal.
+func (t *Terminal) EnterInline(row int) error {
+	if !t.isTerminal || t.active {
+		return nil
+	}
+
+	// Keep typed keys from being echoed over the frames
+	var err error
+	t.originalTerm, err = term.MakeRaw(int(t.file.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	width, height, err := term.GetSize(int(t.file.Fd()))
+	if err == nil {
+		os.Setenv("COLUMNS", fmt.Sprintf("%d", width))
+		os.Setenv("LINES", fmt.Sprintf("%d", height))
+		t.width, t.height = width, height
+	}
+
+	if err := t.hideCursor(); err != nil {
+		return err
+	}
+
+	t.origin = max(row-1, 0)
+	if t.height > 0 {
+		t.origin = min(t.origin, t.height-1)
+	}
+	t.screen = ScreenInline
+	t.active = true
+	return nil
+}
+
+// EnterAppend shows frames by appending their lines to the output. It does
+// nothing when not writing to a terminal.
+func (t *Terminal) EnterAppend() {
+	if !t.isTerminal || t.active {
+		return
+	}
+	t.screen = ScreenAppend
+	t.active = true
+}
+
+// ExitAltScreen returns to the normal screen buffer.
+func (t *Terminal) ExitAltScreen() error {
+	if !t.isTerminal || !t.active || t.screen != ScreenAlt {
+		return nil
+	}
+
+	if err := t.showCursor(); err != nil {
+		return err
+	}
+
+	// Leave alternate screen (rmcup)
+	if t.profile.AltScreen {
+		if _, err := fmt.Fprint(t.file, "\033[?1049l"); err != nil {
+			return fmt.Errorf("failed to exit alternate screen: %w", err)
+		}
+	}
+
+	// Restore terminal state
+	if err := term.Restore(int(t.file.Fd()), t.originalTerm); err != nil {
+		return fmt.Errorf("failed to restore terminal state: %w", err)
+	}
+
+	t.active = false
+	return nil
+}
+
+// Exit stops showing frames, leaving the alternate screen or restoring the
+// terminal as it was before.
+func (t *Terminal) Exit() error {
+	if !t.isTerminal || !t.active {
+		return nil
+	}
+	switch t.screen {
+	case ScreenAlt:
+		return t.ExitAltScreen()
+	case ScreenInline:
+		if err := t.showCursor(); err != nil {
+			return err
+		}
+		if err := term.Restore(int(t.file.Fd()), t.originalTerm); err != nil {
+			return fmt.Errorf("failed to restore terminal state: %w", err)
+		}
+	}
+	t.active = false
+	return nil
+}
+
+// hideCursor hides the cursor (civis), if the terminal can.
+func (t *Terminal) hideCursor() error {
+	if !t.profile.HideCursor {
+		return nil
+	}
+	if _, err := fmt.Fprint(t.file, "\033[?25l"); err != nil {
+		return fmt.Errorf("failed to hide cursor: %w", err)
+	}
+	return nil
+}
+
+// showCursor shows the cursor again (cnorm).
+func (t *Terminal) showCursor() error {
+	if !t.profile.HideCursor {
+		return nil
+	}
+	if _, err := fmt.Fprint(t.file, "\033[?25h"); err != nil {
+		return fmt.Errorf("failed to show cursor: %w", err)
+	}
+	return nil
+}
+
+// Update shows a frame. Only the lines that differ from the last frame are
+// written, so content reflowing further up doesn't make the screen flash.
+// It can be used as the Frame of a Streamer.
+func (t *Terminal) Update(frame string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.active || (frame == t.last && !t.repaint) {
+		return nil
+	}
+	t.last = frame
+
+	var out string
+	switch t.screen {
+	case ScreenInline:
+		out = t.inline(frame)
+	case ScreenAppend:
+		out = t.append(frame)
+	default:
+		out = t.alt(frame)
+	}
+	_, err := fmt.Fprint(t.file, t.profile.Synchronized(out))
+	return err
+}
+
+// alt returns the output showing a frame on the alternate screen. The bottom
+// of frames taller than the screen is shown, as if they had been written
+// out in full.
+func (t *Terminal) alt(frame string) string {
+	// Wrap by display width so wide characters and emoji take the rows we
+	// expect.
+	lines := screenLines(frame, t.width)
+	top := 0
+	if t.height > 0 {
+		top = max(len(lines)-t.height, 0)
+	}
+	next := lines[top:]
+
+	var out string
+	if t.shown == nil || t.repaint {
+		// Nothing is shown yet, or the terminal was resized, so paint
+		// everything.
+		out = "\033[0m\033[2J\033[H" + strings.Join(next, "\033[0m\r\n") + "\033[0m"
+	} else {
+		out = t.scroll(top) + repaint(t.shown, next, 0) + cursorAfter(next, 0)
+	}
+	t.shown, t.top, t.repaint = next, top, false
+	return out
+}
+
+// scroll returns the escape sequences scrolling the alternate screen so its
+// first line is the line at top of the frame, and updates the lines shown
+// accordingly.
+func (t *Terminal) scroll(top int) string {
+	switch n := top - t.top; {
+	case n > 0:
+		t.shown = t.shown[min(n, len(t.shown)):]
+		return fmt.Sprintf("\033[%dS", n)
+	case n < 0:
+		t.shown = append(make([]string, -n), t.shown...)
+		t.shown = t.shown[:min(len(t.shown), t.height)]
+		return fmt.Sprintf("\033[%dT", -n)
+	}
+	return ""
+}
+
+// inline returns the output showing a frame from the origin row of the
+// normal screen. Frames taller than the rest of the screen scroll it, and
+// the lines scrolled into the scrollback are left as they were.
+func (t *Terminal) inline(frame string) string {
+	lines := screenLines(frame, t.width)
+	var b strings.Builder
+	if t.repaint {
+		// The terminal was resized: paint everything below the origin.
+		fmt.Fprintf(&b, "\033[%d;1H\033[0m\033[J", max(t.origin, 0)+1)
+		t.shown, t.repaint = nil, false
+	}
+
+	next := lines[min(max(-t.origin, 0), len(lines)):]
+	row := max(t.origin, 0)
+	fit := len(next)
+	if t.height > 0 {
+		fit = min(fit, t.height-row)
+	}
+	b.WriteString(repaint(t.shown, next[:fit], row))
+	if n := len(next) - fit; n > 0 {
+		// Write the rest at the bottom of the screen, where line feeds
+		// scroll the lines above into the scrollback.
+		fmt.Fprintf(&b, "\033[%d;1H", t.height)
+		for _, l := range next[fit:] {
+			b.WriteString("\n\r\033[2K" + l + "\033[0m")
+		}
+		t.origin -= n
+		row = max(t.origin, 0)
+		next = lines[max(-t.origin, 0):]
+	}
+
+	b.WriteString(cursorAfter(next, row))
+	t.shown = next
+	return b.String()
+}
+
+// append returns the lines of a frame that the last frame had too, and
+// haven't been written yet. Blank lines at the end are left for the next
+// frame, as they're usually the margin of the document.
+func (t *Terminal) append(frame string) string {
+	lines := strings.Split(frame, "\n")
+	settled := 0
+	for settled < min(len(lines), len(t.lines)) && lines[settled] == t.lines[settled] {
+		settled++
+	}
+	for settled > 0 && strings.TrimSpace(stripSGR(lines[settled-1])) == "" {
+		settled--
+	}
+	t.lines = lines
+	if settled <= t.written {
+		return ""
+	}
+	// Clear the spinner, if any, from the line written to.
+	out := "\r\033[K" + strings.Join(lines[t.written:settled], "\n") + "\n"
+	t.written = settled
+	return out
+}
+
+// cursorAfter returns the escape sequences moving the cursor to the end of
+// lines shown from row, where more output, like a spinner, is written.
+func cursorAfter(lines []string, row int) string {
+	if len(lines) == 0 {
+		return fmt.Sprintf("\033[%d;1H", row+1)
+	}
+	last := lines[len(lines)-1]
+	out := fmt.Sprintf("\033[%d;1H", row+len(lines))
+	if last != "" {
+		out += "\033[2K" + last + "\033[0m"
+	}
+	return out
+}
+
+// Resize reads the size of the terminal again after it was resized, and
+// returns its new width. The next frame shown is painted in full.
+func (t *Terminal) Resize() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.isTerminal {
+		return t.width
+	}
+	if width, height, err := term.GetSize(int(t.file.Fd())); err == nil {
+		os.Setenv("COLUMNS", fmt.Sprintf("%d", width))
+		os.Setenv("LINES", fmt.Sprintf("%d", height))
+		t.width, t.height = width, height
+	}
+	t.repaint = true
+	return t.width
+}
+
+// Finish stops showing frames and writes the final rendering: on the normal
+// screen after leaving the alternate one, in place of the frames shown
+// inline, or after the lines already appended.
+func (t *Terminal) Finish(content string) error {
+	if !t.isTerminal || !t.active {
+		// For non-terminal output, just write directly
+		_, err := fmt.Fprint(t.w, content)
+		return err
+	}
+
+	switch t.screen {
+	case ScreenInline:
+		t.mu.Lock()
+		out := t.inline(content)
+		t.mu.Unlock()
+		// Leave the cursor below the rendering.
+		if !strings.HasSuffix(content, "\n") {
+			out += "\r\n"
+		}
+		if _, err := fmt.Fprint(t.file, t.profile.Synchronized(out)); err != nil {
+			return err
+		}
+		return t.Exit()
+	case ScreenAppend:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		lines := strings.Split(content, "\n")
+		t.active = false
+		_, err := fmt.Fprint(t.file, "\r\033[K"+strings.Join(lines[min(t.written, len(lines)):], "\n"))
+		return err
+	}
+
+	if err := t.ExitAltScreen(); err != nil {
+		return err
+	}
+
+	// Ensure proper line endings for the normal terminal buffer
+	content = strings.ReplaceAll(content, "\n", "\r\n")
+
+	// Write the final content to the normal screen
+	_, err := fmt.Fprint(t.file, content)
+	return err
+}
@@ -7,6 +7,10 @@ var (
 		Foreground(lipgloss.Color("#04B575")).
 		Render
 
+	faint = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}).
+		Render
+
 	paragraph = lipgloss.NewStyle().
 			Width(78).
 			Padding(0, 0, 0, 2).
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var (
+	styleEditFrom string
+
+	styleCmd = &cobra.Command{
+		Use:   "style",
+		Short: "Manage custom styles",
+		Args:  cobra.NoArgs,
+	}
+
+	styleEditCmd = &cobra.Command{
+		Use:   "edit [NAME]",
+		Short: "Edit a custom style with a live preview",
+		Long: paragraph(fmt.Sprintf("\n%s the colors, margins and prefixes of a custom style in a form, next to a preview of a document in that style. The style is saved as NAME.json in the config directory, extending the style given with --from, or the configured one, and is then used with --style NAME.json. Select a field with the arrow keys, press enter to change it, d to go back to the value of the extended style, s to save and q to quit.",
+			keyword("Edit"))),
+		Example: paragraph("glow style edit\nglow style edit solarized --from light"),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return errors.New("editing styles needs a terminal")
+			}
+			name := "custom"
+			if len(args) > 0 {
+				name = strings.TrimSuffix(args[0], ".json")
+			}
+			from := styleEditFrom
+			if from == "" {
+				from = viper.GetString("style")
+			}
+			e, err := newStyleEditor(filepath.Join(configDir(), name+".json"), resolveStyle(from))
+			if err != nil {
+				return err
+			}
+			var opts []tea.ProgramOption
+			if termProfile.AltScreen {
+				opts = append(opts, tea.WithAltScreen())
+			}
+			if _, err := tea.NewProgram(e, opts...).Run(); err != nil {
+				return fmt.Errorf("unable to run style editor: %w", err)
+			}
+			return nil
+		},
+	}
+)
+
+// styleFieldKind is the type of value of a style field.
+type styleFieldKind int
+
+const (
+	styleString styleFieldKind = iota
+	styleColor
+	styleNumber
+	styleBool
+)
+
+// styleField is an attribute of a markdown element in a style file, e.g. the
+// color of h1.
+type styleField struct {
+	element, attr string
+	kind          styleFieldKind
+}
+
+func (f styleField) String() string {
+	return f.element + "." + f.attr
+}
+
+// styleFields are the fields the style editor edits, those of the elements
+// that most change the look of a document.
+var styleFields = func() []styleField {
+	var fields []styleField
+	add := func(element string, attrs ...string) {
+		for _, attr := range attrs {
+			kind := styleString
+			switch attr {
+			case "color", "background_color":
+				kind = styleColor
+			case "margin", "indent":
+				kind = styleNumber
+			case "bold", "italic", "underline", "faint":
+				kind = styleBool
+			}
+			fields = append(fields, styleField{element, attr, kind})
+		}
+	}
+	add("document", "color", "background_color", "margin")
+	add("heading", "color", "background_color", "bold")
+	for _, h := range []string{"h1", "h2", "h3"} {
+		add(h, "color", "background_color", "prefix", "suffix", "bold")
+	}
+	add("paragraph", "color")
+	add("emph", "color", "italic")
+	add("strong", "color", "bold")
+	add("link", "color", "underline")
+	add("link_text", "color", "bold")
+	add("code", "color", "background_color", "prefix", "suffix")
+	add("code_block", "color", "margin")
+	add("block_quote", "color", "italic", "indent", "indent_token")
+	add("item", "block_prefix")
+	add("enumeration", "block_prefix")
+	add("hr", "color", "format")
+	return fields
+}()
+
+// stylePreview is the document previewed in the style being edited.
+const stylePreview = "# Heading\n\nSome text with *emphasis*, **strong** words, `code` and a [link](https://github.com/charmbracelet/glow).\n\n" +
+	"## Second heading\n\n> A quote,\n> over two lines.\n\n- An item\n- Another item\n\n1. First\n2. Second\n\n" +
+	"### Third heading\n\n```go\nfunc main() {\n\tfmt.Println(\"Hello\")\n}\n```\n\n---\n\nThe end.\n"
+
+// styleEditor edits the fields of a style file, which may extend another
+// style, and previews a document in the resulting style.
+type styleEditor struct {
+	path string
+	// The content of the style file, including any keys the editor doesn't
+	// edit.
+	doc map[string]any
+	// The style the file extends.
+	base ansi.StyleConfig
+
+	selected int
+	input    textinput.Model
+	editing  bool
+	changed  bool
+	// Whether quitting was asked for once with unsaved changes.
+	confirmQuit bool
+	quitting    bool
+	message     string
+
+	width, height int
+}
+
+// newStyleEditor returns an editor of the style file at path, or of a new
+// style extending from if there's none.
+func newStyleEditor(path, from string) (styleEditor, error) {
+	e := styleEditor{path: path, doc: map[string]any{}, width: 80, height: 24}
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := validateStyle(from); err != nil {
+			return e, err
+		}
+		if from != styles.AutoStyle && styles.DefaultStyles[from] == nil {
+			if from, err = filepath.Abs(utils.ExpandPath(from)); err != nil {
+				return e, fmt.Errorf("unable to get absolute path: %w", err)
+			}
+		}
+		e.doc["extends"] = from
+	case err != nil:
+		return e, fmt.Errorf("unable to read style: %w", err)
+	default:
+		if err := json.Unmarshal(b, &e.doc); err != nil {
+			return e, fmt.Errorf("unable to parse style %s: %w", path, err)
+		}
+	}
+
+	if extends, _ := e.doc["extends"].(string); extends != "" {
+		if extends != styles.AutoStyle && styles.DefaultStyles[extends] == nil {
+			extends = utils.ExpandPath(extends)
+			if !filepath.IsAbs(extends) {
+				extends = filepath.Join(filepath.Dir(path), extends)
+			}
+		}
+		if e.base, err = utils.StyleConfig(extends); err != nil {
+			return e, fmt.Errorf("invalid style %s: %w", extends, err)
+		}
+	}
+
+	e.input = textinput.New()
+	e.input.Prompt = ""
+	return e, nil
+}
+
+// config returns the style as edited: the style it extends with the fields
+// of the file set.
+func (e styleEditor) config() (ansi.StyleConfig, error) {
+	var cfg ansi.StyleConfig
+	// Copy the extended style, whose elements may be shared pointers.
+	b, err := json.Marshal(e.base)
+	if err != nil {
+		return cfg, fmt.Errorf("unable to copy style: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("unable to copy style: %w", err)
+	}
+	if b, err = json.Marshal(e.doc); err != nil {
+		return cfg, fmt.Errorf("unable to encode style: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid style: %w", err)
+	}
+	return cfg, nil
+}
+
+// value returns the value of a field set in the style file, if it is.
+func (e styleEditor) value(f styleField) (any, bool) {
+	element, _ := e.doc[f.element].(map[string]any)
+	v, ok := element[f.attr]
+	return v, ok
+}
+
+// values returns the values of all fields of the style as edited, by element
+// and attribute.
+func (e styleEditor) values() map[string]map[string]any {
+	var all map[string]map[string]any
+	cfg, err := e.config()
+	if err != nil {
+		return all
+	}
+	b, _ := json.Marshal(cfg)
+	_ = json.Unmarshal(b, &all)
+	return all
+}
+
+// effective returns the value of a field in the style as edited, as shown in
+// the form, given the values of the style.
+func effective(values map[string]map[string]any, f styleField) string {
+	v, ok := values[f.element][f.attr]
+	if !ok {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// set sets a field of the style file from the text typed for it, or unsets
+// it if the text is empty.
+func (e *styleEditor) set(f styleField, text string) error {
+	var v any
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "":
+		e.unset(f)
+		return nil
+	case f.kind == styleNumber:
+		n, err := strconv.ParseUint(trimmed, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%s must be a positive number", f)
+		}
+		v = n
+	case f.kind == styleBool:
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", f)
+		}
+		v = b
+	case f.kind == styleColor:
+		if !validStyleColor(trimmed) {
+			return fmt.Errorf("%s must be a #rrggbb color or an ANSI color number", f)
+		}
+		v = trimmed
+	default:
+		// Prefixes keep their spaces.
+		v = unquoteStyleString(trimmed, text)
+	}
+
+	element, ok := e.doc[f.element].(map[string]any)
+	if !ok {
+		element = map[string]any{}
+		e.doc[f.element] = element
+	}
+	element[f.attr] = v
+	e.changed = true
+	return nil
+}
+
+// unquoteStyleString lets string fields be typed in quotes, which makes the spaces they
+// start or end with easier to see.
+func unquoteStyleString(trimmed, text string) string {
+	if s, err := strconv.Unquote(trimmed); err == nil && strings.HasPrefix(trimmed, `"`) {
+		return s
+	}
+	return text
+}
+
+// unset removes a field from the style file, so it's that of the extended
+// style again.
+func (e *styleEditor) unset(f styleField) {
+	element, ok := e.doc[f.element].(map[string]any)
+	if !ok {
+		return
+	}
+	if _, ok := element[f.attr]; ok {
+		e.changed = true
+	}
+	delete(element, f.attr)
+	if len(element) == 0 {
+		delete(e.doc, f.element)
+	}
+}
+
+func validStyleColor(c string) bool {
+	if strings.HasPrefix(c, "#") {
+		_, err := strconv.ParseUint(c[1:], 16, 32)
+		return err == nil && (len(c) == 4 || len(c) == 7)
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// save writes the style file.
+func (e *styleEditor) save() error {
+	b, err := json.MarshalIndent(e.doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode style: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to save style: %w", err)
+	}
+	if err := os.WriteFile(e.path, append(b, '\n'), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to save style: %w", err)
+	}
+	e.changed = false
+	return nil
+}
+
+func (e styleEditor) Init() tea.Cmd {
+	return nil
+}
+
+func (e styleEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.width, e.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if e.editing {
+			return e.updateInput(msg)
+		}
+		f := styleFields[e.selected]
+		e.message = ""
+		confirmQuit := e.confirmQuit
+		e.confirmQuit = false
+		switch msg.String() {
+		case "up", "k":
+			e.selected = max(e.selected-1, 0)
+		case "down", "j", "tab":
+			e.selected = min(e.selected+1, len(styleFields)-1)
+		case "home", "g":
+			e.selected = 0
+		case "end", "G":
+			e.selected = len(styleFields) - 1
+		case "enter", " ":
+			if f.kind == styleBool {
+				b, _ := strconv.ParseBool(effective(e.values(), f))
+				_ = e.set(f, strconv.FormatBool(!b))
+				break
+			}
+			e.editing = true
+			e.input.SetValue(effective(e.values(), f))
+			e.input.CursorEnd()
+			return e, e.input.Focus()
+		case "d", "backspace", "delete":
+			e.unset(f)
+		case "s", "ctrl+s":
+			if err := e.save(); err != nil {
+				e.message = err.Error()
+				break
+			}
+			e.message = fmt.Sprintf("Saved %s: use it with glow --style %s", e.path, filepath.Base(e.path))
+		case "q", "esc", "ctrl+c":
+			if e.changed && !confirmQuit && msg.String() != "ctrl+c" {
+				e.confirmQuit = true
+				e.message = "Unsaved changes: press s to save them, or q again to quit"
+				break
+			}
+			e.quitting = true
+			return e, tea.Quit
+		}
+	}
+	return e, nil
+}
+
+// updateInput handles keys while the value of a field is typed.
+func (e styleEditor) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		e.editing = false
+		e.input.Blur()
+		return e, nil
+	case "enter":
+		if err := e.set(styleFields[e.selected], e.input.Value()); err != nil {
+			e.message = err.Error()
+			return e, nil
+		}
+		e.message = ""
+		e.editing = false
+		e.input.Blur()
+		return e, nil
+	}
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return e, cmd
+}
+
+func (e styleEditor) View() string {
+	if e.quitting {
+		return ""
+	}
+	const formWidth = 44
+	var (
+		form   strings.Builder
+		height = max(e.height-3, 1)
+		// Scroll the form to keep the selected field in view.
+		first  = max(min(e.selected-height/2, len(styleFields)-height), 0)
+		values = e.values()
+	)
+	for i := first; i < min(first+height, len(styleFields)); i++ {
+		f := styleFields[i]
+		name := fmt.Sprintf("%-26s", f)
+		value := effective(values, f)
+		if f.kind == styleColor && value != "" {
+			value = lipgloss.NewStyle().Foreground(lipgloss.Color(value)).Render("■") + " " + value
+		}
+		if _, ok := e.value(f); !ok {
+			value = faint(value)
+		}
+		switch {
+		case i == e.selected && e.editing:
+			form.WriteString(keyword("› "+name) + e.input.View())
+		case i == e.selected:
+			form.WriteString(keyword("› "+name) + value)
+		default:
+			form.WriteString("  " + name + value)
+		}
+		form.WriteString("\n")
+	}
+
+	preview := e.preview(max(e.width-formWidth-2, 20))
+	lines := strings.Split(preview, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(formWidth).MaxWidth(formWidth).Render(form.String()),
+		"  ",
+		strings.Join(lines, "\n"),
+	)
+
+	title := keyword(filepath.Base(e.path))
+	if e.changed {
+		title += faint(" (modified)")
+	}
+	status := faint("↑/↓ select • enter change • d reset • s save • q quit")
+	if e.message != "" {
+		status = e.message
+	}
+	return title + "\n\n" + body + "\n" + status
+}
+
+// preview renders the preview document in the style as edited.
+func (e styleEditor) preview(width int) string {
+	cfg, err := e.config()
+	if err != nil {
+		return err.Error()
+	}
+	r, err := glamour.NewTermRenderer(glamour.WithStyles(cfg), glamour.WithWordWrap(width))
+	if err != nil {
+		return err.Error()
+	}
+	out, err := r.Render(stylePreview)
+	if err != nil {
+		return err.Error()
+	}
+	return strings.TrimRight(out, "\n")
+}
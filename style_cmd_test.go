@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+func TestStyleEditor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.json")
+	e, err := newStyleEditor(path, "dark")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h1 := styleField{"h1", "color", styleColor}
+	if err := e.set(h1, "red"); err == nil {
+		t.Error("expected an error for an invalid color")
+	}
+	if err := e.set(h1, " #ff0000 "); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.set(styleField{"h1", "prefix", styleString}, `"» "`); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.set(styleField{"document", "margin", styleNumber}, "-1"); err == nil {
+		t.Error("expected an error for a negative margin")
+	}
+	if err := e.set(styleField{"document", "margin", styleNumber}, "4"); err != nil {
+		t.Fatal(err)
+	}
+	e.unset(styleField{"document", "margin", styleNumber})
+
+	if got := effective(e.values(), h1); got != "#ff0000" {
+		t.Errorf("expected the edited color, got %q", got)
+	}
+	if got := effective(e.values(), styleField{"h2", "prefix", styleString}); got != "## " {
+		t.Errorf("expected the prefix of the dark style, got %q", got)
+	}
+	if !strings.Contains(e.preview(60), "» ") {
+		t.Error("expected the preview in the edited style")
+	}
+
+	m, _ := e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	e = m.(styleEditor) //nolint:forcetypeassert
+	if e.changed {
+		t.Error("expected the style to be saved")
+	}
+	cfg, err := utils.StyleConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.H1.Color != "#ff0000" || cfg.H1.Prefix != "» " || *cfg.Document.Margin != 2 {
+		t.Errorf("unexpected saved style: %+v, margin %d", cfg.H1, *cfg.Document.Margin)
+	}
+
+	// Editing it again keeps what was saved.
+	e, err = newStyleEditor(path, "light")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.doc["extends"] != "dark" {
+		t.Errorf("expected the style to extend dark, got %v", e.doc["extends"])
+	}
+	if got := effective(e.values(), h1); got != "#ff0000" {
+		t.Errorf("expected the saved color, got %q", got)
+	}
+	if !strings.Contains(e.View(), "h1.color") {
+		t.Error("expected the form to show the fields")
+	}
+}
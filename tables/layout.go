@@ -0,0 +1,454 @@
+package tables
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Space between a column and its separator.
+const cellPadding = 1
+
+var cellParser = goldmark.New(goldmark.WithExtensions(extension.Strikethrough)).Parser()
+
+// run is a piece of styled text in a cell.
+type run struct {
+	text  string
+	style lipgloss.Style
+}
+
+// token is a word, or the space between words, made of runs in different
+// styles.
+type token struct {
+	runs  []run
+	space bool
+	width int
+}
+
+// cell is the content of a cell split into tokens for wrapping.
+type cell []token
+
+// width returns the width of the cell on a single line.
+func (c cell) width() int {
+	w := 0
+	for _, t := range c {
+		w += t.width
+	}
+	return w
+}
+
+// minWidth returns the width of the widest word of the cell.
+func (c cell) minWidth() int {
+	w := 0
+	for _, t := range c {
+		if !t.space {
+			w = max(w, t.width)
+		}
+	}
+	return w
+}
+
+// wrap breaks the cell into lines of at most width, breaking words wider
+// than that.
+func (c cell) wrap(width int) []string {
+	var (
+		lines []string
+		line  strings.Builder
+		used  int
+		space *token
+	)
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		used = 0
+	}
+	for i, t := range c {
+		if t.space {
+			// Written before the next word, if it fits on the line
+			space = &c[i]
+			continue
+		}
+		if used > 0 && space != nil && used+space.width+t.width <= width {
+			line.WriteString(space.render())
+			used += space.width
+		} else if used > 0 {
+			flush()
+		}
+		space = nil
+		if t.width <= width {
+			line.WriteString(t.render())
+			used += t.width
+			continue
+		}
+
+		// Break words wider than a line
+		for _, r := range t.runs {
+			var chunk strings.Builder
+			for _, c := range r.text {
+				w := xansi.StringWidth(string(c))
+				if used+w > width && used > 0 {
+					line.WriteString(r.style.Render(chunk.String()))
+					chunk.Reset()
+					flush()
+				}
+				chunk.WriteRune(c)
+				used += w
+			}
+			line.WriteString(r.style.Render(chunk.String()))
+		}
+	}
+	if used > 0 || len(lines) == 0 {
+		flush()
+	}
+	return lines
+}
+
+// render returns the styled text of a token.
+func (t token) render() string {
+	var b strings.Builder
+	for _, r := range t.runs {
+		b.WriteString(r.style.Render(r.text))
+	}
+	return b.String()
+}
+
+// parseCell renders the inline markdown of a cell as styled runs, split into
+// tokens.
+func parseCell(md string, base lipgloss.Style, cfg ansi.StyleConfig) cell {
+	source := []byte(md)
+	doc := cellParser.Parse(text.NewReader(source))
+
+	var runs []run
+	add := func(s string, style lipgloss.Style) {
+		if s != "" {
+			runs = append(runs, run{s, style})
+		}
+	}
+	var walk func(n gast.Node, style lipgloss.Style)
+	walk = func(n gast.Node, style lipgloss.Style) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c := c.(type) {
+			case *gast.Text:
+				add(string(c.Segment.Value(source)), style)
+				if c.SoftLineBreak() || c.HardLineBreak() {
+					add(" ", style)
+				}
+			case *gast.String:
+				add(string(c.Value), style)
+			case *gast.CodeSpan:
+				s := primitiveStyle(cfg.Code.StylePrimitive).Inherit(style)
+				add(cfg.Code.Prefix+string(nodeText(c, source))+cfg.Code.Suffix, s)
+			case *gast.Emphasis:
+				p := cfg.Emph
+				if c.Level > 1 {
+					p = cfg.Strong
+				}
+				walk(c, primitiveStyle(p).Inherit(style))
+			case *east.Strikethrough:
+				walk(c, primitiveStyle(cfg.Strikethrough).Inherit(style))
+			case *gast.Link:
+				walk(c, primitiveStyle(cfg.LinkText).Inherit(style))
+				if dest := string(c.Destination); dest != string(nodeText(c, source)) {
+					add(" ", style)
+					add(dest, primitiveStyle(cfg.Link).Inherit(style))
+				}
+			case *gast.AutoLink:
+				add(string(c.URL(source)), primitiveStyle(cfg.Link).Inherit(style))
+			case *gast.Image:
+				walk(c, primitiveStyle(cfg.ImageText).Inherit(style))
+			case *gast.RawHTML:
+				for i := 0; i < c.Segments.Len(); i++ {
+					seg := c.Segments.At(i)
+					add(string(seg.Value(source)), style)
+				}
+			default:
+				walk(c, style)
+			}
+		}
+	}
+	walk(doc, base)
+
+	// Split the runs into words and spaces
+	var (
+		tokens []token
+		cur    token
+	)
+	for _, r := range runs {
+		for i, part := range strings.Split(r.text, " ") {
+			if i > 0 {
+				if len(cur.runs) > 0 {
+					tokens = append(tokens, cur)
+				}
+				cur = token{}
+				tokens = append(tokens, token{runs: []run{{" ", r.style}}, space: true, width: 1})
+			}
+			if part != "" {
+				cur.runs = append(cur.runs, run{part, r.style})
+				cur.width += xansi.StringWidth(part)
+			}
+		}
+	}
+	if len(cur.runs) > 0 {
+		tokens = append(tokens, cur)
+	}
+	return trimSpace(tokens)
+}
+
+// trimSpace drops the spaces around the words of a cell, and repeated ones.
+func trimSpace(tokens []token) cell {
+	var c cell
+	for _, t := range tokens {
+		if t.space && (len(c) == 0 || c[len(c)-1].space) {
+			continue
+		}
+		c = append(c, t)
+	}
+	for len(c) > 0 && c[len(c)-1].space {
+		c = c[:len(c)-1]
+	}
+	return c
+}
+
+// nodeText returns the text of the children of a node.
+func nodeText(n gast.Node, source []byte) []byte {
+	var b []byte
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*gast.Text); ok {
+			b = append(b, t.Segment.Value(source)...)
+			continue
+		}
+		b = append(b, nodeText(c, source)...)
+	}
+	return b
+}
+
+// primitiveStyle returns the lipgloss style of a glamour style primitive.
+func primitiveStyle(p ansi.StylePrimitive) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	if p.Color != nil {
+		s = s.Foreground(lipgloss.Color(*p.Color))
+	}
+	if p.BackgroundColor != nil {
+		s = s.Background(lipgloss.Color(*p.BackgroundColor))
+	}
+	if p.Bold != nil {
+		s = s.Bold(*p.Bold)
+	}
+	if p.Italic != nil {
+		s = s.Italic(*p.Italic)
+	}
+	if p.Underline != nil {
+		s = s.Underline(*p.Underline)
+	}
+	if p.CrossedOut != nil {
+		s = s.Strikethrough(*p.CrossedOut)
+	}
+	if p.Faint != nil {
+		s = s.Faint(*p.Faint)
+	}
+	return s
+}
+
+// separators returns the separators of columns, rows and where they cross
+// in the given style.
+func separators(cfg ansi.StyleConfig) (column, row, center string) {
+	column, row, center = "│", "─", "┼"
+	if s := cfg.Table.ColumnSeparator; s != nil {
+		column = *s
+	}
+	if s := cfg.Table.RowSeparator; s != nil {
+		row = *s
+	}
+	if s := cfg.Table.CenterSeparator; s != nil {
+		center = *s
+	}
+	return column, row, center
+}
+
+// layout lays out a table to fit width, or as wide as its content if width
+// is 0.
+func layout(t Table, width int, opts Options) string {
+	base := primitiveStyle(opts.Style.Document.StylePrimitive)
+	base = primitiveStyle(opts.Style.Table.StylePrimitive).Inherit(base)
+	header := make([]cell, len(t.Header))
+	for i, h := range t.Header {
+		header[i] = parseCell(h, base, opts.Style)
+	}
+	rows := make([][]cell, len(t.Rows))
+	for i, r := range t.Rows {
+		rows[i] = make([]cell, len(r))
+		for j, c := range r {
+			rows[i][j] = parseCell(c, base, opts.Style)
+		}
+	}
+
+	widths := make([]int, len(header))
+	for _, r := range append([][]cell{header}, rows...) {
+		for i, c := range r {
+			widths[i] = max(widths[i], c.width())
+		}
+	}
+	if width <= 0 || opts.Mode == ModeScroll || tableWidth(widths) <= width {
+		return tableView(t.Align, header, rows, widths, opts.Style)
+	}
+	if opts.Mode == ModeRecords {
+		return recordsView(header, rows, width, opts.Style)
+	}
+	return tableView(t.Align, header, rows, fitColumns(header, rows, widths, width), opts.Style)
+}
+
+// tableWidth returns the width of a table with columns of the given widths.
+func tableWidth(widths []int) int {
+	w := 0
+	for _, cw := range widths {
+		w += cw
+	}
+	return w + max(0, len(widths)-1)*(1+2*cellPadding)
+}
+
+// fitColumns narrows columns so that the table fits width. Columns keep the
+// width of their widest word where they can, and those that can't share the
+// space the others leave. What's left over goes to the columns in proportion
+// to how much wider they would like to be.
+func fitColumns(header []cell, rows [][]cell, natural []int, width int) []int {
+	available := max(len(natural), width-tableWidth(make([]int, len(natural))))
+
+	widths := make([]int, len(natural))
+	for i := range widths {
+		w := header[i].minWidth()
+		for _, r := range rows {
+			w = max(w, r[i].minWidth())
+		}
+		widths[i] = max(1, min(w, natural[i]))
+	}
+
+	// Cap the widest columns until the words fit
+	order := make([]int, len(widths))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return widths[a] - widths[b] })
+	left := available
+	for n, i := range order {
+		share := left / (len(order) - n)
+		widths[i] = max(1, min(widths[i], share))
+		left -= widths[i]
+	}
+
+	want := 0
+	for i, w := range widths {
+		want += natural[i] - w
+	}
+	if left <= 0 || want == 0 {
+		return widths
+	}
+	given := 0
+	for i, w := range widths {
+		grow := (natural[i] - w) * left / want
+		widths[i] += grow
+		given += grow
+	}
+	// Hand out what's left after rounding down, from the first column
+	for i := 0; given < left && i < len(widths); i++ {
+		if widths[i] < natural[i] {
+			widths[i]++
+			given++
+		}
+	}
+	return widths
+}
+
+// tableView draws a table with columns of the given widths.
+func tableView(align []Alignment, header []cell, rows [][]cell, widths []int, cfg ansi.StyleConfig) string {
+	column, row, center := separators(cfg)
+	pad := strings.Repeat(" ", cellPadding)
+
+	var lines []string
+	drawRow := func(cells []cell) {
+		wrapped := make([][]string, len(cells))
+		height := 1
+		for i, c := range cells {
+			wrapped[i] = c.wrap(widths[i])
+			height = max(height, len(wrapped[i]))
+		}
+		for l := 0; l < height; l++ {
+			parts := make([]string, len(cells))
+			for i := range cells {
+				var s string
+				if l < len(wrapped[i]) {
+					s = wrapped[i][l]
+				}
+				parts[i] = alignText(s, widths[i], align[i])
+			}
+			lines = append(lines, strings.TrimRight(strings.Join(parts, pad+column+pad), " "))
+		}
+	}
+
+	drawRow(header)
+	rules := make([]string, len(widths))
+	for i, w := range widths {
+		rules[i] = strings.Repeat(row, w)
+	}
+	lines = append(lines, strings.Join(rules, strings.Repeat(row, cellPadding)+center+strings.Repeat(row, cellPadding)))
+	for _, r := range rows {
+		drawRow(r)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recordsView draws each row of a table as a record, with a line for each
+// column labelled with its header.
+func recordsView(header []cell, rows [][]cell, width int, cfg ansi.StyleConfig) string {
+	column, row, _ := separators(cfg)
+	pad := strings.Repeat(" ", cellPadding)
+
+	labelWidth := 0
+	for _, h := range header {
+		labelWidth = max(labelWidth, h.width())
+	}
+	labelWidth = min(labelWidth, max(1, width/3))
+	valueWidth := max(1, width-labelWidth-1-2*cellPadding)
+
+	var lines []string
+	for n, r := range rows {
+		if n > 0 {
+			lines = append(lines, strings.Repeat(row, width))
+		}
+		for i, c := range r {
+			labels := header[i].wrap(labelWidth)
+			values := c.wrap(valueWidth)
+			for l := 0; l < max(len(labels), len(values)); l++ {
+				var label, value string
+				if l < len(labels) {
+					label = lipgloss.NewStyle().Bold(true).Render(labels[l])
+				}
+				if l < len(values) {
+					value = values[l]
+				}
+				lines = append(lines, strings.TrimRight(alignText(label, labelWidth, AlignLeft)+pad+column+pad+value, " "))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// alignText pads styled text to width.
+func alignText(s string, width int, align Alignment) string {
+	gap := max(0, width-xansi.StringWidth(s))
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case AlignCenter:
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	default:
+		return s + strings.Repeat(" ", gap)
+	}
+}
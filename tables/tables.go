@@ -0,0 +1,208 @@
+// Package tables lays out markdown tables to fit the width of the document,
+// wrapping the content of cells, or showing each row as a record when a table
+// is too wide.
+package tables
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/douglas-larocca/glow/v2/placeholder"
+)
+
+// Mode is how tables wider than the document are shown.
+type Mode string
+
+// Modes of showing wide tables.
+const (
+	// ModeWrap narrows columns to fit, wrapping the content of cells.
+	ModeWrap Mode = "wrap"
+	// ModeScroll keeps tables as wide as their content, to be scrolled
+	// horizontally.
+	ModeScroll Mode = "scroll"
+	// ModeRecords shows each row of a table that doesn't fit as a record,
+	// with a line per column.
+	ModeRecords Mode = "records"
+)
+
+var modes = []Mode{ModeWrap, ModeScroll, ModeRecords}
+
+// ParseMode returns the mode of the given name.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("invalid wide tables mode %q, expected one of: %s", s, strings.Join(names, ", "))
+}
+
+// Alignment is the alignment of a column, set in the delimiter row.
+type Alignment int
+
+// Alignments of columns.
+const (
+	AlignNone Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Table is a table taken out of a document. Cells hold inline markdown.
+type Table struct {
+	Header []string
+	Align  []Alignment
+	Rows   [][]string
+}
+
+var marker = placeholder.New("GLOWTABLE")
+
+var (
+	delimiterPattern = regexp.MustCompile(`^ {0,3}\|?(\s*:?-+:?\s*\|)*\s*:?-+:?\s*\|?\s*$`)
+	fencePattern     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Extract takes the tables out of a markdown document, leaving a placeholder
+// paragraph for each that Render replaces with the laid out table. Tables in
+// lists and block quotes are left in place.
+func Extract(markdown string) (string, []Table) {
+	if !strings.Contains(markdown, "|") {
+		return markdown, nil
+	}
+
+	var (
+		out    strings.Builder
+		tables []Table
+		fence  string
+	)
+	lines := strings.SplitAfter(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		if m := fencePattern.FindStringSubmatch(trimmed); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(trimmed) == m[1]:
+				fence = ""
+			}
+		}
+		if fence != "" || i+1 >= len(lines) || !isRow(trimmed) {
+			out.WriteString(line)
+			continue
+		}
+
+		delimiter := strings.TrimRight(lines[i+1], "\r\n")
+		header := splitRow(trimmed)
+		if !delimiterPattern.MatchString(delimiter) || len(splitRow(delimiter)) != len(header) {
+			out.WriteString(line)
+			continue
+		}
+
+		t := Table{Header: header}
+		for _, d := range splitRow(delimiter) {
+			t.Align = append(t.Align, alignment(d))
+		}
+		i += 2
+		for ; i < len(lines); i++ {
+			row := strings.TrimRight(lines[i], "\r\n")
+			if !isRow(row) || fencePattern.MatchString(row) {
+				break
+			}
+			cells := splitRow(row)
+			for len(cells) < len(header) {
+				cells = append(cells, "")
+			}
+			t.Rows = append(t.Rows, cells[:len(header)])
+		}
+		i--
+
+		tables = append(tables, t)
+		out.WriteString(marker.Paragraph(len(tables) - 1))
+	}
+	return out.String(), tables
+}
+
+// isRow reports whether a line can be a row of a table.
+func isRow(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && strings.Contains(line, "|") &&
+		len(line)-len(strings.TrimLeft(line, " ")) < 4 &&
+		!strings.HasPrefix(trimmed, ">")
+}
+
+// splitRow splits a row of a table into its cells. Escaped pipes are part
+// of the cells.
+func splitRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// alignment returns the alignment set by a cell of the delimiter row.
+func alignment(delimiter string) Alignment {
+	left := strings.HasPrefix(delimiter, ":")
+	right := strings.HasSuffix(delimiter, ":")
+	switch {
+	case left && right:
+		return AlignCenter
+	case left:
+		return AlignLeft
+	case right:
+		return AlignRight
+	}
+	return AlignNone
+}
+
+// Options configure how tables are laid out.
+type Options struct {
+	// Width the document is rendered at, or 0 if it isn't wrapped.
+	Width int
+	Mode  Mode
+	// Style of the document, for the content of cells and the separators.
+	Style ansi.StyleConfig
+}
+
+// Render replaces the placeholders left by Extract in rendered output with
+// the tables, laid out to fit the document's width within its margins.
+// Tables are indented as their placeholder.
+func Render(rendered string, tables []Table, opts Options) string {
+	return marker.Replace(rendered, len(tables), func(n, indent int) string {
+		width := 0
+		if opts.Width > 0 {
+			width = opts.Width - indent
+			if opts.Style.Document.Margin != nil {
+				width -= int(*opts.Style.Document.Margin) //nolint:gosec
+			}
+			width = max(width, 10)
+		}
+		return layout(tables[n], width, opts)
+	})
+}
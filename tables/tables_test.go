@@ -0,0 +1,115 @@
+package tables
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const doc = "# Doc\n\n" +
+	"| Name | Size | Notes |\n" +
+	"|:-----|-----:|:-----:|\n" +
+	"| a\\|b | 1 | one |\n" +
+	"| c |\n\n" +
+	"```\n| not | a table |\n|-----|---------|\n```\n"
+
+func TestExtract(t *testing.T) {
+	out, got := Extract(doc)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 table, got %+v", got)
+	}
+	tbl := got[0]
+	if strings.Join(tbl.Header, ",") != "Name,Size,Notes" {
+		t.Errorf("unexpected header %q", tbl.Header)
+	}
+	if len(tbl.Align) != 3 || tbl.Align[0] != AlignLeft || tbl.Align[1] != AlignRight || tbl.Align[2] != AlignCenter {
+		t.Errorf("unexpected alignment %v", tbl.Align)
+	}
+	if len(tbl.Rows) != 2 || strings.Join(tbl.Rows[0], ",") != "a|b,1,one" || strings.Join(tbl.Rows[1], ",") != "c,," {
+		t.Errorf("unexpected rows %q", tbl.Rows)
+	}
+	for _, s := range []string{marker.Text(0), "| not | a table |"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in %q", s, out)
+		}
+	}
+	if strings.Contains(out, "| Name |") {
+		t.Errorf("expected the table to be taken out, got %q", out)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"wrap", "scroll", "records"} {
+		if m, err := ParseMode(s); err != nil || string(m) != s {
+			t.Errorf("expected mode %q, got %q (%v)", s, m, err)
+		}
+	}
+	if _, err := ParseMode("squash"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+var wide = Table{
+	Header: []string{"Name", "Description"},
+	Align:  []Alignment{AlignNone, AlignRight},
+	Rows: [][]string{
+		{"glow", "Render markdown on the CLI, with pizzazz, in any terminal"},
+	},
+}
+
+func render(t *testing.T, tbl Table, width int, mode Mode) []string {
+	t.Helper()
+	out := Render("  "+marker.Text(0), []Table{tbl}, Options{
+		Width: width,
+		Mode:  mode,
+		Style: styles.NoTTYStyleConfig,
+	})
+	return strings.Split(xansi.Strip(out), "\n")
+}
+
+func TestRender(t *testing.T) {
+	t.Run("fits", func(t *testing.T) {
+		tbl := Table{
+			Header: []string{"A", "B"},
+			Align:  []Alignment{AlignNone, AlignRight},
+			Rows:   [][]string{{"one", "2"}},
+		}
+		lines := render(t, tbl, 80, ModeWrap)
+		want := []string{"  A   | B", "  ----|--", "  one | 2"}
+		if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+			t.Errorf("expected %q, got %q", want, lines)
+		}
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		lines := render(t, wide, 40, ModeWrap)
+		if len(lines) < 4 {
+			t.Fatalf("expected the description to wrap, got %q", lines)
+		}
+		for _, l := range lines {
+			// The width less the indent and the style's margin.
+			if xansi.StringWidth(l) > 40-int(*styles.NoTTYStyleConfig.Document.Margin) {
+				t.Errorf("expected lines to fit, got %q", l)
+			}
+		}
+	})
+
+	t.Run("scroll", func(t *testing.T) {
+		lines := render(t, wide, 40, ModeScroll)
+		if len(lines) != 3 || !strings.Contains(lines[2], wide.Rows[0][1]) {
+			t.Errorf("expected the table at its natural width, got %q", lines)
+		}
+	})
+
+	t.Run("records", func(t *testing.T) {
+		lines := render(t, wide, 40, ModeRecords)
+		if !strings.HasPrefix(lines[0], "  Name") || !strings.Contains(lines[0], "glow") {
+			t.Errorf("expected a line per column, got %q", lines)
+		}
+		if !strings.Contains(strings.Join(lines, "\n"), "Description") {
+			t.Errorf("expected the description's label, got %q", lines)
+		}
+	})
+}
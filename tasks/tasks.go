@@ -0,0 +1,149 @@
+// Package tasks finds the items of GFM task lists in markdown documents,
+// ticks them off in the source, and styles them in rendered output so that
+// done tasks stand out from the open ones.
+package tasks
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// Task is an item of a task list.
+type Task struct {
+	// Line of the source the task is on, counting from 0.
+	Line int
+	Done bool
+	// Text of the item, as written.
+	Text string
+}
+
+// ErrNotFound is returned when toggling a task the document doesn't have.
+var ErrNotFound = errors.New("task not found")
+
+var (
+	taskPattern  = regexp.MustCompile(`^([ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+\[)([ xX])\](.*)$`)
+	fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	itemPattern  = regexp.MustCompile(`^(?:[•*+-]|\d+\.) `)
+
+	doneBoxStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1A7F37", Dark: "#3FB950"})
+	openBoxStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9A6700", Dark: "#D29922"}).Bold(true)
+	doneStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#6C6C6C"})
+)
+
+// Find returns the tasks of a markdown document, leaving out those in code
+// blocks and block quotes.
+func Find(markdown string) []Task {
+	var (
+		tasks []Task
+		fence string
+	)
+	for i, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if m := taskPattern.FindStringSubmatch(line); m != nil {
+			tasks = append(tasks, Task{Line: i, Done: m[2] != " ", Text: strings.TrimSpace(m[3])})
+		}
+	}
+	return tasks
+}
+
+// Toggle ticks the nth task of a markdown document off, or opens it again if
+// it's done, and returns the changed document.
+func Toggle(markdown string, n int) (string, error) {
+	found := Find(markdown)
+	if n < 0 || n >= len(found) {
+		return "", ErrNotFound
+	}
+	lines := strings.Split(markdown, "\n")
+	line := lines[found[n].Line]
+	cr := strings.HasSuffix(line, "\r")
+	m := taskPattern.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+	box := "x"
+	if found[n].Done {
+		box = " "
+	}
+	lines[found[n].Line] = m[1] + box + "]" + m[3]
+	if cr {
+		lines[found[n].Line] += "\r"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Markers are the prefixes a style renders done and open tasks with, like
+// "[✓] " and "[ ] ".
+type Markers struct {
+	Done, Open string
+}
+
+// Lines returns the lines of rendered output tasks are on, in the order of
+// the document.
+func Lines(rendered string, markers Markers) []int {
+	var lines []int
+	for i, line := range strings.Split(rendered, "\n") {
+		if _, _, ok := markers.match(xansi.Strip(line)); ok {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// match reports whether a line of plain rendered output starts a task, and
+// returns its indentation and whether it's done.
+func (m Markers) match(plain string) (indent int, done, ok bool) {
+	body := strings.TrimLeft(plain, " ")
+	indent = len(plain) - len(body)
+	switch {
+	case m.Done != "" && strings.HasPrefix(body, m.Done):
+		return indent, true, true
+	case m.Open != "" && strings.HasPrefix(body, m.Open):
+		return indent, false, true
+	}
+	return indent, false, false
+}
+
+// Style colors the checkboxes of rendered tasks, and dims the text of the
+// done ones, including the lines it's wrapped on.
+func Style(rendered string, markers Markers) string {
+	if markers.Done == "" || markers.Open == "" || markers.Done == markers.Open {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	inDone := false
+	for i, line := range lines {
+		plain := xansi.Strip(line)
+		indent, done, ok := markers.match(plain)
+		body := strings.TrimRight(plain[indent:], " ")
+		switch {
+		case ok && done:
+			text := strings.TrimPrefix(body, strings.TrimRight(markers.Done, " "))
+			text = strings.TrimPrefix(text, " ")
+			lines[i] = plain[:indent] + doneBoxStyle.Render(markers.Done) + doneStyle.Render(text)
+			inDone = true
+		case ok:
+			rest := xansi.TruncateLeft(line, indent+xansi.StringWidth(markers.Open), "")
+			lines[i] = plain[:indent] + openBoxStyle.Render(markers.Open) + rest
+			inDone = false
+		case inDone && body != "" && !itemPattern.MatchString(body):
+			// Text of a done task wrapped onto the next line.
+			lines[i] = plain[:indent] + doneStyle.Render(body)
+		default:
+			inDone = false
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const doc = "# Todo\n\n" +
+	"- [x] buy milk\n" +
+	"- [ ] write code\r\n" +
+	"  1. [ ] nested\n" +
+	"- not a task\n\n" +
+	"```\n- [ ] in code\n```\n\n" +
+	"> - [ ] quoted\n"
+
+func TestFind(t *testing.T) {
+	got := Find(doc)
+	want := []Task{
+		{Line: 2, Done: true, Text: "buy milk"},
+		{Line: 3, Text: "write code"},
+		{Line: 4, Text: "nested"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d tasks, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected task %+v, got %+v", want[i], got[i])
+		}
+	}
+}
+
+func TestToggle(t *testing.T) {
+	out, err := Toggle(doc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "- [x] write code\r\n") {
+		t.Errorf("expected the task to be ticked off, got %q", out)
+	}
+	out, err = Toggle(out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "- [ ] buy milk\n") {
+		t.Errorf("expected the task to be opened again, got %q", out)
+	}
+	if strings.Count(out, "\n") != strings.Count(doc, "\n") {
+		t.Errorf("expected the other lines to be kept, got %q", out)
+	}
+	if _, err := Toggle(doc, 3); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStyle(t *testing.T) {
+	markers := Markers{Done: "[✓] ", Open: "[ ] "}
+	rendered := "  [✓] buy milk and more\n" +
+		"  things\n" +
+		"  [ ] write code\n" +
+		"  • plain\n"
+
+	if got := Lines(rendered, markers); len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("expected tasks on lines 0 and 2, got %v", got)
+	}
+
+	out := Style(rendered, markers)
+	if xansi.Strip(out) != rendered {
+		t.Errorf("expected the text to be kept, got %q", xansi.Strip(out))
+	}
+	if Lines(out, markers)[1] != 2 {
+		t.Errorf("expected styled tasks to be found, got %v", Lines(out, markers))
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// How long to wait for the terminal to report the cursor position. Slow ssh
+// links and multiplexers like tmux can take a while.
+const cursorReportTimeout = 2 * time.Second
+
+var errCursorReportTimeout = errors.New("timed out waiting for the cursor position")
+
+// terminalPosition tracks the cursor position in the terminal
+type terminalPosition struct {
+	row    int
+	column int
+}
+
+// getTerminalPosition gets the current terminal cursor position
+// This uses ANSI escape codes to query and parse the cursor position
+func getTerminalPosition(file *os.File) (terminalPosition, error) {
+	// ESC [ 6 n
+	pos, err := queryTerminal(file, "\x1b[6n", cursorReportTimeout, readCursorReport)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return terminalPosition{}, errCursorReportTimeout
+	}
+	return pos, err
+}
+
+// queryTerminal puts the terminal in raw mode, writes query to it, and
+// reads the answer with read. When the answer doesn't come within timeout,
+// it returns os.ErrDeadlineExceeded.
+func queryTerminal[T any](file *os.File, query string, timeout time.Duration, read func(io.Reader) (T, error)) (T, error) {
+	var zero T
+
+	// This only works for terminals, so make sure we're dealing with one
+	if !term.IsTerminal(int(file.Fd())) {
+		return zero, fmt.Errorf("not a terminal")
+	}
+
+	// Save current terminal attributes to restore later
+	oldState, err := term.MakeRaw(int(file.Fd()))
+	if err != nil {
+		return zero, fmt.Errorf("unable to set terminal to raw mode: %w", err)
+	}
+	defer term.Restore(int(file.Fd()), oldState) //nolint:errcheck
+
+	if _, err := file.WriteString(query); err != nil {
+		return zero, fmt.Errorf("unable to query the terminal: %w", err)
+	}
+
+	// Terminals that support it stop reads at the deadline. Otherwise, the
+	// read is left behind, which is still better than hanging.
+	if err := file.SetReadDeadline(time.Now().Add(timeout)); err == nil {
+		defer file.SetReadDeadline(time.Time{}) //nolint:errcheck
+		return read(file)
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		v, err := read(file)
+		ch <- result{v, err}
+	}()
+	select {
+	case res := <-ch:
+		return res.v, res.err
+	case <-time.After(timeout):
+		return zero, os.ErrDeadlineExceeded
+	}
+}
+
+// readCursorReport reads terminal input until it finds a cursor position
+// report. The report may arrive in pieces, and input typed before it, like
+// keys or other escape sequences, is skipped.
+func readCursorReport(r io.Reader) (terminalPosition, error) {
+	var (
+		p   cursorReportParser
+		buf [64]byte
+	)
+	for {
+		n, err := r.Read(buf[:])
+		for _, b := range buf[:n] {
+			if pos, ok := p.feed(b); ok {
+				return pos, nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return terminalPosition{}, errors.New("no cursor position reported")
+			}
+			return terminalPosition{}, fmt.Errorf("unable to read cursor position: %w", err)
+		}
+	}
+}
+
+// States of a cursorReportParser.
+const (
+	cursorReportGround = iota
+	cursorReportEscape
+	cursorReportRow
+	cursorReportColumn
+)
+
+// cursorReportParser is a state machine that finds a cursor position
+// report, ESC [ row ; column R, in terminal input.
+type cursorReportParser struct {
+	state  int
+	pos    terminalPosition
+	digits int
+}
+
+// feed passes the next byte of input to the parser, which reports true
+// once it has read a whole report.
+func (p *cursorReportParser) feed(b byte) (terminalPosition, bool) {
+	if b == 0x1b {
+		// Escape starts over, whatever came before
+		p.state = cursorReportEscape
+		return terminalPosition{}, false
+	}
+
+	switch p.state {
+	case cursorReportEscape:
+		if b == '[' {
+			p.state = cursorReportRow
+			p.pos = terminalPosition{}
+			p.digits = 0
+			return terminalPosition{}, false
+		}
+	case cursorReportRow, cursorReportColumn:
+		n := &p.pos.row
+		if p.state == cursorReportColumn {
+			n = &p.pos.column
+		}
+		switch {
+		case b >= '0' && b <= '9':
+			*n = *n*10 + int(b-'0')
+			p.digits++
+			return terminalPosition{}, false
+		case b == ';' && p.state == cursorReportRow && p.digits > 0:
+			p.state = cursorReportColumn
+			p.digits = 0
+			return terminalPosition{}, false
+		case b == 'R' && p.state == cursorReportColumn && p.digits > 0:
+			p.state = cursorReportGround
+			return p.pos, true
+		}
+	}
+
+	// Anything else isn't part of a report
+	p.state = cursorReportGround
+	return terminalPosition{}, false
+}
+
+// moveTo generates ANSI escape code to position cursor at specific coordinates
+func (pos terminalPosition) moveTo() string {
+	return fmt.Sprintf("\x1b[%d;%dH", pos.row, pos.column)
+}
+
+// saveTerminalPosition saves the current terminal position for later restoration
+func saveTerminalPosition(w io.Writer) (terminalPosition, error) {
+	// Try to get terminal position if we're writing to a terminal
+	f, ok := w.(*os.File)
+	if !ok {
+		return terminalPosition{}, fmt.Errorf("output is not a terminal")
+	}
+
+	// Get current position
+	pos, err := getTerminalPosition(f)
+	if err != nil {
+		return terminalPosition{}, err
+	}
+
+	return pos, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadCursorReport(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input io.Reader
+		want  terminalPosition
+		ok    bool
+	}{
+		{"whole", strings.NewReader("\x1b[12;40R"), terminalPosition{12, 40}, true},
+		{"split", iotest.OneByteReader(strings.NewReader("\x1b[3;7R")), terminalPosition{3, 7}, true},
+		{"typed before", strings.NewReader("jk\x1b[A\x1b[5;1R"), terminalPosition{5, 1}, true},
+		{"other sequence", strings.NewReader("\x1b[?1;2c\x1b[8;9R"), terminalPosition{8, 9}, true},
+		{"interrupted", strings.NewReader("\x1b[2;\x1b[4;6R"), terminalPosition{4, 6}, true},
+		{"missing column", strings.NewReader("\x1b[2;R"), terminalPosition{}, false},
+		{"no report", strings.NewReader("hello"), terminalPosition{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pos, err := readCursorReport(tc.input)
+			if (err == nil) != tc.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pos != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, pos)
+			}
+		})
+	}
+}
+
+func TestQueryTerminalRefusesFiles(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close() //nolint:errcheck
+	defer w.Close() //nolint:errcheck
+
+	read := func(io.Reader) (terminalPosition, error) {
+		t.Fatal("read from a file that isn't a terminal")
+		return terminalPosition{}, nil
+	}
+	if _, err := queryTerminal(w, "\x1b[6n", cursorReportTimeout, read); err == nil {
+		t.Fatal("expected an error for a pipe")
+	}
+	if _, err := getTerminalBackground(w); err == nil {
+		t.Fatal("expected an error for a pipe")
+	}
+}
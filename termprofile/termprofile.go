@@ -0,0 +1,156 @@
+// Package termprofile describes what terminals can do beyond writing lines of
+// text, so glow can leave out what a serial console or a limited emulator
+// doesn't understand: the alternate screen, hiding the cursor, moving it
+// around to repaint frames, and the box-drawing characters of tables,
+// callouts and diagrams.
+package termprofile
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Profile is what a kind of terminal can do.
+type Profile struct {
+	Name string
+	// Whether the cursor can be moved around the screen, which repainting
+	// frames needs. Without it, output is only ever appended.
+	CursorMovement bool
+	// Whether there's an alternate screen (smcup/rmcup) to show frames on,
+	// leaving the normal screen as it was.
+	AltScreen bool
+	// Whether the cursor can be hidden (civis/cnorm).
+	HideCursor bool
+	// Whether line wrapping can be turned on and off (DECAWM).
+	AutoWrap bool
+	// Whether updates of the screen can be shown at once rather than as
+	// they're written (synchronized output, mode 2026).
+	SyncOutput bool
+	// Whether box-drawing characters, arrows and blocks can be shown, or
+	// must be drawn with ASCII.
+	Unicode bool
+}
+
+var (
+	// Dumb terminals only write lines of text, like serial consoles and the
+	// output panes of editors.
+	Dumb = Profile{Name: "dumb"}
+	// VT100 terminals move the cursor and wrap lines, but have no alternate
+	// screen and only ASCII.
+	VT100 = Profile{Name: "vt100", CursorMovement: true, AutoWrap: true}
+	// Xterm and the terminals compatible with it do all of it, but
+	// synchronized output.
+	Xterm = Profile{Name: "xterm", CursorMovement: true, AltScreen: true, HideCursor: true, AutoWrap: true, Unicode: true}
+	// Kitty and other modern terminals also show updates at once.
+	Kitty = Profile{Name: "kitty", CursorMovement: true, AltScreen: true, HideCursor: true, AutoWrap: true, SyncOutput: true, Unicode: true}
+)
+
+var profiles = []Profile{Dumb, VT100, Xterm, Kitty}
+
+// Parse returns the profile named s.
+func Parse(s string) (Profile, error) {
+	for _, p := range profiles {
+		if p.Name == s {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("invalid terminal profile %q, expected one of: %s", s, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of the profiles.
+func Names() []string {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Detect returns the profile of the terminal described by the environment,
+// looked up with getenv.
+func Detect(getenv func(string) string) Profile {
+	term := getenv("TERM")
+	switch {
+	case term == "" || term == "dumb":
+		return Dumb
+	case term == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != "":
+		return Kitty
+	case strings.HasPrefix(term, "vt"):
+		return VT100
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "WezTerm", "ghostty", "iTerm.app":
+		return Kitty
+	}
+	return Xterm
+}
+
+// Synchronized returns the escape sequences of an update of the screen,
+// shown at once if the terminal can.
+func (p Profile) Synchronized(update string) string {
+	if !p.SyncOutput || update == "" {
+		return update
+	}
+	return "\033[?2026h" + update + "\033[?2026l"
+}
+
+// Text returns s drawn with characters the terminal shows: box-drawing
+// characters, arrows, blocks and bullets are replaced by the closest ASCII
+// ones when it only shows ASCII.
+func (p Profile) Text(s string) string {
+	if p.Unicode || !hasGraphics(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if a, ok := ascii[r]; ok {
+			b.WriteString(a)
+			continue
+		}
+		if isBoxDrawing(r) {
+			// Other lines meeting: corners, junctions and crossings.
+			b.WriteByte('+')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ascii are the ASCII replacements of graphic characters.
+var ascii = map[rune]string{
+	'─': "-", '━': "-", '┄': "-", '┅': "-", '┈': "-", '┉': "-", '╌': "-", '╍': "-", '╴': "-", '╶': "-",
+	'═': "=",
+	'│': "|", '┃': "|", '┆': "|", '┇': "|", '┊': "|", '┋': "|", '╎': "|", '╏': "|", '║': "|", '╵': "|", '╷': "|",
+	'╱': "/", '╲': "\\", '╳': "X",
+	'▶': ">", '▷': ">", '►': ">", '→': "->", '⟶': "-->",
+	'◀': "<", '◁': "<", '◄': "<", '←': "<-", '⟵': "<--",
+	'▼': "v", '▽': "v", '↓': "v",
+	'▲': "^", '△': "^", '↑': "^",
+	'█': "#", '▓': "#", '▒': ":", '░': ".", '▀': "\"", '▄': "_", '▌': "|", '▐': "|",
+	'•': "*", '◦': "o", '●': "*", '○': "o", '■': "#", '□': "[]", '▪': "*", '▫': "-",
+	'…': "...", '⋯': "...", '·': "-", '×': "x",
+	'✓': "v", '✔': "v", '✗': "x", '✘': "x", '✖': "x",
+	'ℹ': "i", '✦': "*", '‼': "!!", '⚠': "!",
+}
+
+func isBoxDrawing(r rune) bool {
+	return r >= 0x2500 && r <= 0x257F
+}
+
+func hasGraphics(s string) bool {
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if _, ok := ascii[r]; ok || isBoxDrawing(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package termprofile
+
+import "testing"
+
+func TestText(t *testing.T) {
+	s := "╭──────╮\n│ \x1b[1mNote\x1b[0m │\n╰──┬───╯\n   ▼\n• item … café ├─┼"
+	want := "+------+\n| \x1b[1mNote\x1b[0m |\n+--+---+\n   v\n* item ... café +-+"
+	if got := VT100.Text(s); got != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Xterm.Text(s); got != s {
+		t.Errorf("expected the text unchanged, got:\n%s", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, "dumb"},
+		{map[string]string{"TERM": "dumb"}, "dumb"},
+		{map[string]string{"TERM": "vt220"}, "vt100"},
+		{map[string]string{"TERM": "xterm-256color"}, "xterm"},
+		{map[string]string{"TERM": "xterm-kitty"}, "kitty"},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, "kitty"},
+	} {
+		if got := Detect(func(k string) string { return tc.env[k] }); got.Name != tc.want {
+			t.Errorf("%v: expected %s, got %s", tc.env, tc.want, got.Name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if p, err := Parse("vt100"); err != nil || p != VT100 {
+		t.Errorf("expected vt100, got %v, %v", p, err)
+	}
+	if _, err := Parse("vt52"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestSynchronized(t *testing.T) {
+	if got := Kitty.Synchronized("x"); got != "\x1b[?2026hx\x1b[?2026l" {
+		t.Errorf("unexpected update %q", got)
+	}
+	if got := Xterm.Synchronized("x"); got != "x" {
+		t.Errorf("unexpected update %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// Lines of context shown around the lines of a rendering that differ from
+// its golden file.
+const goldenContext = 2
+
+// testRenderOptions are the options of glow test-render.
+type testRenderOptions struct {
+	golden string
+	update bool
+	format string
+	style  string
+	width  uint
+}
+
+var (
+	testRenderFlags testRenderOptions
+
+	testRenderCmd = &cobra.Command{
+		Use:   "test-render DIR",
+		Short: "Compare the rendering of documents to golden files",
+		Long: paragraph(fmt.Sprintf("\n%s every markdown file below DIR and compare the output to the golden files in the --golden directory, showing the lines that differ. Golden files are named after the documents and the format, as in guide.md.ansi, and --update writes them. Renderings are made comparable across machines: the style, width and colors are fixed rather than detected, and trailing spaces are ignored. Use it to keep a custom style, or glow itself, from changing how documents look by accident.",
+			keyword("Render"))),
+		Example: paragraph("glow test-render docs --golden testdata/golden --update\nglow test-render docs --golden testdata/golden -s mystyle.json"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return testRender(cmd.OutOrStdout(), args[0], testRenderFlags)
+		},
+	}
+)
+
+// testRender renders the documents below dir and compares them to their
+// golden files, or writes the golden files with opts.update.
+func testRender(w io.Writer, dir string, opts testRenderOptions) error {
+	if opts.golden == "" {
+		return errors.New("no golden directory, set one with --golden")
+	}
+	if err := validateFormat(opts.format); err != nil {
+		return err
+	}
+	// Auto would depend on the background of the terminal.
+	if opts.style == "" || opts.style == styles.AutoStyle {
+		opts.style = styles.DarkStyle
+	}
+	opts.style = resolveStyle(opts.style)
+	if err := validateStyle(opts.style); err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to get absolute path: %w", err)
+	}
+	files, err := findMarkdownFiles(root)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no markdown files found in %s", dir)
+	}
+
+	defer func(s string, wd uint, f string, p termenv.Profile, tp termprofile.Profile) {
+		style, width, outputFormat, termProfile = s, wd, f, tp
+		lipgloss.SetColorProfile(p)
+	}(style, width, outputFormat, lipgloss.ColorProfile(), termProfile)
+	style, width, outputFormat, termProfile = opts.style, opts.width, opts.format, termprofile.Xterm
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	outs, err := renderEach(len(files), func(i int) (string, error) {
+		out, err := renderFile(files[i])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", files[i], err)
+		}
+		return normalizeRendering(out, root), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i, path := range files {
+		name, _ := filepath.Rel(root, path)
+		golden := filepath.Join(opts.golden, name+"."+opts.format)
+		if opts.update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil { //nolint:gosec
+				return fmt.Errorf("unable to write golden file: %w", err)
+			}
+			if err := os.WriteFile(golden, []byte(outs[i]), 0o644); err != nil { //nolint:gosec
+				return fmt.Errorf("unable to write golden file: %w", err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(golden)
+		if errors.Is(err, os.ErrNotExist) {
+			failed++
+			fmt.Fprintf(w, "%s %s: no golden file, write it with --update\n", diffDeleteStyle.Render("✗"), name) //nolint:errcheck
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read golden file: %w", err)
+		}
+		if got := outs[i]; got != normalizeRendering(string(want), root) {
+			failed++
+			fmt.Fprintf(w, "%s %s differs from %s:\n%s", diffDeleteStyle.Render("✗"), name, golden, goldenDiff(string(want), got)) //nolint:errcheck
+		}
+	}
+
+	switch {
+	case opts.update:
+		fmt.Fprintf(w, "Wrote %d golden files to %s\n", len(files), opts.golden) //nolint:errcheck
+	case failed > 0:
+		return fmt.Errorf("%d of %d documents differ from their golden files", failed, len(files))
+	default:
+		fmt.Fprintf(w, "%s %d documents match their golden files\n", diffInsertStyle.Render("✓"), len(files)) //nolint:errcheck
+	}
+	return nil
+}
+
+// trailingBlank matches the spaces a line ends with, and the resets of the
+// styles they're in.
+var trailingBlank = regexp.MustCompile(`(?:\x1b\[[0-9;]*m|[ \t])+$`)
+
+// normalizeRendering makes renderings comparable across machines: line
+// endings, spaces at the end of lines and blank lines at the end of the
+// document don't count, and paths are relative to the corpus.
+func normalizeRendering(out, root string) string {
+	out = strings.ReplaceAll(out, "\r\n", "\n")
+	out = strings.ReplaceAll(out, root+string(filepath.Separator), "")
+	lines := strings.Split(out, "\n")
+	for i, l := range lines {
+		lines[i] = trailingBlank.ReplaceAllString(l, "")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// goldenDiff returns the lines of a rendering that differ from its golden
+// file, with a few lines of context. Escape codes are shown as \e, so
+// changes of style can be told apart.
+func goldenDiff(want, got string) string {
+	changes := diffBlocks(strings.Split(want, "\n"), strings.Split(got, "\n"))
+	show := make([]bool, len(changes))
+	for i, c := range changes {
+		if c.op == diffEqual {
+			continue
+		}
+		for j := max(i-goldenContext, 0); j <= min(i+goldenContext, len(changes)-1); j++ {
+			show[j] = true
+		}
+	}
+
+	var b strings.Builder
+	for i, c := range changes {
+		if !show[i] {
+			if i > 0 && show[i-1] {
+				b.WriteString(diffFaintStyle.Render("  ⋯") + "\n")
+			}
+			continue
+		}
+		line := strings.ReplaceAll(c.text, "\x1b", `\e`)
+		switch c.op {
+		case diffDelete:
+			b.WriteString(diffDeleteStyle.Render("- "+line) + "\n")
+		case diffInsert:
+			b.WriteString(diffInsertStyle.Render("+ "+line) + "\n")
+		default:
+			b.WriteString(diffFaintStyle.Render("  "+line) + "\n")
+		}
+	}
+	return b.String()
+}
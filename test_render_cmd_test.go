@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderGolden(t *testing.T) {
+	var out bytes.Buffer
+	opts := testRenderOptions{golden: filepath.Join("testdata", "golden"), format: formatANSI, width: 60}
+	if err := testRender(&out, filepath.Join("testdata", "render"), opts); err != nil {
+		t.Fatalf("%v\n%s\nupdate the golden files with: go run . test-render testdata/render --golden testdata/golden -w 60 --update", err, out.String())
+	}
+}
+
+func TestRenderGoldenDiff(t *testing.T) {
+	dir, golden := t.TempDir(), t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(doc, []byte("# Title\n\nOne line.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := testRenderOptions{golden: golden, format: formatText, width: 40}
+
+	var out bytes.Buffer
+	if err := testRender(&out, dir, opts); err == nil || !strings.Contains(out.String(), "no golden file") {
+		t.Fatalf("expected a missing golden file, got %v: %s", err, out.String())
+	}
+	opts.update = true
+	if err := testRender(&out, dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.update = false
+	if err := testRender(&out, dir, opts); err != nil {
+		t.Fatalf("expected the golden file to match, got %v", err)
+	}
+
+	if err := os.WriteFile(doc, []byte("# Title\n\nAnother line.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := testRender(&out, dir, opts); err == nil {
+		t.Fatal("expected the rendering to differ")
+	}
+	if !strings.Contains(out.String(), "-   One line.") || !strings.Contains(out.String(), "+   Another line.") {
+		t.Errorf("expected the changed line in the diff, got:\n%s", out.String())
+	}
+}
+
+func TestNormalizeRendering(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "corpus")
+	in := "\x1b[1mTitle\x1b[0m  \x1b[0m\r\nsee " + filepath.Join(root, "a.md") + "  \n\n\n"
+	if got, want := normalizeRendering(in, root), "\x1b[1mTitle\nsee a.md\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
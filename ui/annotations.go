@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/annotations"
+)
+
+const annotationMarker = "▌"
+
+var (
+	annotationMarkerStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D29922", Dark: "#E3B341"}).Render
+	selectionMarkerStyle  = lipgloss.NewStyle().Foreground(fuchsia).Render
+)
+
+// annotationMark is an annotation of the current document and the lines it's
+// on in the rendering shown.
+type annotationMark struct {
+	annotations.Annotation
+	start, end int
+}
+
+// annotationState is the state of annotating lines of the current document.
+type annotationState struct {
+	// Whether lines are being selected, and the first selected line and the
+	// cursor, which ends the selection.
+	selecting      bool
+	anchor, cursor int
+
+	// Whether a note is being written for the selection.
+	writing bool
+	input   textinput.Model
+
+	// Annotations of the current document.
+	marks []annotationMark
+}
+
+func newNoteInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Note:"
+	ti.PromptStyle = stashInputPromptStyle
+	ti.Cursor.Style = stashInputCursorStyle
+	ti.CharLimit = 500
+	return ti
+}
+
+// loadAnnotations opens the annotation store configured in cfg. Annotations
+// are disabled if it can't be read.
+func loadAnnotations(cfg Config) *annotations.Store {
+	if cfg.AnnotationsFile == "" {
+		return nil
+	}
+	s, err := annotations.Load(cfg.AnnotationsFile)
+	if err != nil {
+		log.Error("unable to load annotations", "error", err)
+		return nil
+	}
+	return s
+}
+
+// annotating reports whether the pager takes all keys to annotate lines.
+func (m pagerModel) annotating() bool {
+	return m.annotation.selecting
+}
+
+// showAnnotations shows the rendered document with markers next to the
+// annotated and selected lines, and the selected task.
+func (m *pagerModel) showAnnotations() {
+	a := &m.annotation
+	lines := strings.Split(m.rendered, "\n")
+	a.marks = nil
+	if m.common.annotations != nil && m.rendered != "" {
+		plain := make([]string, len(lines))
+		for i, l := range lines {
+			plain[i] = stripANSI(l)
+		}
+		for _, n := range m.common.annotations.List([]byte(m.currentDocument.Body)) {
+			start, end := n.Locate(plain)
+			a.marks = append(a.marks, annotationMark{n, start, end})
+		}
+	}
+
+	taskLine := m.selectedTaskLine()
+	if len(a.marks) > 0 || a.selecting || taskLine >= 0 {
+		selStart, selEnd := a.selection()
+		for i := range lines {
+			switch {
+			case i == taskLine:
+				lines[i] = withMarker(lines[i], selectionMarkerStyle(annotationMarker))
+			case a.selecting && i >= selStart && i <= selEnd:
+				lines[i] = withMarker(lines[i], selectionMarkerStyle(annotationMarker))
+			case m.annotationAt(i) >= 0:
+				lines[i] = withMarker(lines[i], annotationMarkerStyle(annotationMarker))
+			}
+		}
+	}
+	m.setContent(strings.Join(lines, "\n"))
+}
+
+// refreshAnnotations shows the annotations and selection after they changed.
+func (m *pagerModel) refreshAnnotations() tea.Cmd {
+	m.showAnnotations()
+	if m.viewport.HighPerformanceRendering {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
+
+// withMarker draws a marker in the margin of a rendered line.
+func withMarker(line, marker string) string {
+	return marker + strings.TrimPrefix(line, " ")
+}
+
+// annotationAt returns the index of the annotation on line i, or -1.
+func (m pagerModel) annotationAt(i int) int {
+	for j, n := range m.annotation.marks {
+		if i >= n.start && i <= n.end {
+			return j
+		}
+	}
+	return -1
+}
+
+// selection returns the first and last selected lines.
+func (a annotationState) selection() (int, int) {
+	return min(a.anchor, a.cursor), max(a.anchor, a.cursor)
+}
+
+// startAnnotating starts selecting lines to annotate from the first line of
+// text in view.
+func (m *pagerModel) startAnnotating() tea.Cmd {
+	switch {
+	case m.common.annotations == nil:
+		return m.showStatusMessage(pagerStatusMessage{"Annotations are disabled", true})
+	case m.rendered == "":
+		return nil
+	}
+
+	lines := strings.Split(m.rendered, "\n")
+	line := m.viewport.YOffset
+	for line < len(lines)-1 && line < m.viewport.YOffset+m.viewport.Height-1 &&
+		strings.TrimSpace(stripANSI(lines[line])) == "" {
+		line++
+	}
+	m.state = pagerStateBrowse
+	m.annotation.selecting = true
+	m.annotation.anchor, m.annotation.cursor = line, line
+	return m.refreshAnnotations()
+}
+
+// stopAnnotating leaves the selection of lines.
+func (m *pagerModel) stopAnnotating() tea.Cmd {
+	m.annotation.selecting = false
+	m.annotation.writing = false
+	m.annotation.input.Blur()
+	return m.refreshAnnotations()
+}
+
+// updateAnnotating handles keys while lines are being annotated.
+func (m *pagerModel) updateAnnotating(msg tea.KeyMsg) tea.Cmd {
+	a := &m.annotation
+	if a.writing {
+		switch msg.String() {
+		case keyEsc:
+			a.writing = false
+			a.input.Blur()
+			return nil
+		case keyEnter:
+			return m.addAnnotation()
+		}
+		var cmd tea.Cmd
+		a.input, cmd = a.input.Update(msg)
+		return cmd
+	}
+
+	last := max(0, m.viewport.TotalLineCount()-1)
+	switch msg.String() {
+	case keyEsc, "q", "a":
+		return m.stopAnnotating()
+	case "down", "j":
+		a.cursor = min(last, a.cursor+1)
+	case "up", "k":
+		a.cursor = max(0, a.cursor-1)
+	case "pgdown", "f":
+		a.cursor = min(last, a.cursor+m.viewport.Height)
+	case "pgup":
+		a.cursor = max(0, a.cursor-m.viewport.Height)
+	case " ":
+		// Start the selection over at the cursor.
+		a.anchor = a.cursor
+	case keyEnter:
+		a.writing = true
+		a.input = newNoteInput()
+		if i := m.annotationAt(a.cursor); i >= 0 {
+			a.input.Placeholder = a.marks[i].Note
+		}
+		return a.input.Focus()
+	case "x":
+		return m.removeAnnotation()
+	default:
+		return nil
+	}
+
+	// Keep the cursor in view.
+	if a.cursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(a.cursor)
+	} else if a.cursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(a.cursor - m.viewport.Height + 1)
+	}
+	return m.refreshAnnotations()
+}
+
+// addAnnotation attaches the note written to the selected lines.
+func (m *pagerModel) addAnnotation() tea.Cmd {
+	a := &m.annotation
+	note := strings.TrimSpace(a.input.Value())
+	a.writing = false
+	a.input.Blur()
+	if note == "" {
+		return nil
+	}
+
+	start, end := a.selection()
+	lines := strings.Split(m.rendered, "\n")
+	text := make([]string, 0, end-start+1)
+	for _, l := range lines[start:min(end+1, len(lines))] {
+		text = append(text, strings.TrimRight(stripANSI(l), " "))
+	}
+	err := m.common.annotations.Add(m.annotationPath(), []byte(m.currentDocument.Body), annotations.Annotation{
+		Start: start,
+		End:   end,
+		Lines: len(lines),
+		Text:  strings.Join(text, "\n"),
+		Note:  note,
+	})
+	if err != nil {
+		log.Error("unable to save annotation", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t save annotation", true})
+	}
+	a.selecting = false
+	return tea.Batch(m.refreshAnnotations(), m.showStatusMessage(pagerStatusMessage{"Annotated", false}))
+}
+
+// removeAnnotation removes the annotation under the cursor.
+func (m *pagerModel) removeAnnotation() tea.Cmd {
+	i := m.annotationAt(m.annotation.cursor)
+	if i < 0 {
+		return nil
+	}
+	// Marks are in the order the store lists them.
+	if err := m.common.annotations.Remove([]byte(m.currentDocument.Body), i); err != nil {
+		log.Error("unable to remove annotation", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t remove annotation", true})
+	}
+	return m.refreshAnnotations()
+}
+
+// annotationPath returns the path annotations of the current document are
+// listed under.
+func (m pagerModel) annotationPath() string {
+	if m.currentDocument.localPath != "" {
+		return m.currentDocument.localPath
+	}
+	return m.currentDocument.Note
+}
+
+// annotationStatus returns what the status bar shows while annotating.
+func (m pagerModel) annotationStatus() string {
+	a := m.annotation
+	if a.writing {
+		return a.input.View()
+	}
+	if i := m.annotationAt(a.cursor); i >= 0 {
+		return fmt.Sprintf("“%s” • enter edit • x remove", a.marks[i].Note)
+	}
+	start, end := a.selection()
+	return fmt.Sprintf("%d lines • j/k select • space restart • enter add note • esc done", end-start+1)
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/bookmarks"
+)
+
+// loadBookmarks opens the bookmark store configured in cfg. Bookmarks are
+// disabled if it can't be read.
+func loadBookmarks(cfg Config) *bookmarks.Store {
+	if cfg.BookmarksFile == "" {
+		return nil
+	}
+	s, err := bookmarks.Load(cfg.BookmarksFile)
+	if err != nil {
+		log.Error("unable to load bookmarks", "error", err)
+		return nil
+	}
+	return s
+}
+
+// bookmarkedMarkdowns converts the stored bookmarks into list entries.
+func bookmarkedMarkdowns(s *bookmarks.Store, cwd string) []*markdown {
+	if s == nil {
+		return nil
+	}
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	var mds []*markdown //nolint:prealloc
+	for _, b := range s.List() {
+		md := &markdown{
+			localPath: b.Path,
+			heading:   b.Heading,
+			Note:      stripAbsolutePath(b.Path, cwd),
+		}
+		if b.Heading != "" {
+			md.Note += " › " + b.Heading
+		}
+		if info, err := os.Stat(b.Path); err == nil {
+			md.Modtime = info.ModTime()
+		}
+		mds = append(mds, md)
+	}
+	return mds
+}
+
+// refreshBookmarks reloads the bookmarks list and shows or hides the
+// bookmarks section accordingly.
+func (m *stashModel) refreshBookmarks() {
+	m.bookmarked = bookmarkedMarkdowns(m.common.bookmarks, m.common.cwd)
+	m.setSectionVisible(bookmarksSection, len(m.bookmarked) > 0)
+}
+
+// toggleBookmark bookmarks the selected document, or removes the bookmark if
+// there already is one.
+func (m *stashModel) toggleBookmark() tea.Cmd {
+	md := m.selectedMarkdown()
+	if md == nil || md.localPath == "" || m.common.bookmarks == nil {
+		return nil
+	}
+
+	added, err := m.common.bookmarks.Toggle(md.localPath, md.heading)
+	if err != nil {
+		log.Error("unable to save bookmark", "error", err)
+		return m.newStatusMessage(statusMessage{errorStatusMessage, "Couldn’t save bookmark"})
+	}
+	m.refreshBookmarks()
+
+	if added {
+		return m.newStatusMessage(statusMessage{normalStatusMessage, "Bookmarked"})
+	}
+	return m.newStatusMessage(statusMessage{subtleStatusMessage, "Bookmark removed"})
+}
+
+// currentHeading returns the text of the last heading scrolled past the top
+// of the viewport, or an empty string if we're above the first heading.
+func (m pagerModel) currentHeading() string {
+	var (
+		heading string
+		from    int
+	)
+	lines := strings.Split(stripANSI(m.rendered), "\n")
+	for _, h := range markdownHeadings(m.shownBody()) {
+		needle := foldText(h)
+		for i := from; i < len(lines); i++ {
+			if !strings.Contains(foldText(lines[i]), needle) {
+				continue
+			}
+			if i > m.viewport.YOffset {
+				return heading
+			}
+			heading, from = h, i+1
+			break
+		}
+	}
+	return heading
+}
+
+// headingMarkup strips inline markup that doesn't appear in rendered
+// headings.
+var headingMarkup = strings.NewReplacer("`", "", "**", "", "__", "")
+
+// markdownHeadings returns the text of the ATX headings in a markdown
+// document, skipping fenced code blocks.
+func markdownHeadings(body string) []string {
+	var (
+		headings []string
+		fence    string
+	)
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		if level < 1 || level > 6 || len(trimmed) == level || trimmed[level] != ' ' {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimRight(trimmed[level:], "#"))
+		text = headingMarkup.Replace(text)
+		if text != "" {
+			headings = append(headings, text)
+		}
+	}
+	return headings
+}
+
+// toggleBookmark bookmarks the heading at the top of the viewport, or the
+// whole document when there is none.
+func (m *pagerModel) toggleBookmark() tea.Cmd {
+	if m.common.bookmarks == nil || m.currentDocument.localPath == "" {
+		return m.showStatusMessage(pagerStatusMessage{"Only local files can be bookmarked", true})
+	}
+
+	heading := m.currentHeading()
+	added, err := m.common.bookmarks.Toggle(m.currentDocument.localPath, heading)
+	if err != nil {
+		log.Error("unable to save bookmark", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t save bookmark", true})
+	}
+
+	what := "document"
+	if heading != "" {
+		what = "“" + heading + "”"
+	}
+	if added {
+		return m.showStatusMessage(pagerStatusMessage{"Bookmarked " + what, false})
+	}
+	return m.showStatusMessage(pagerStatusMessage{"Removed bookmark for " + what, false})
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type browserFinishedMsg struct{ err error }
+
+// browserCmd returns the command that opens target in the user's browser:
+// the first entry of $BROWSER, or the system's opener.
+func browserCmd(target string) (*exec.Cmd, error) {
+	if b, _, _ := strings.Cut(os.Getenv("BROWSER"), string(os.PathListSeparator)); b != "" {
+		args := strings.Fields(b)
+		return exec.Command(args[0], append(args[1:], target)...), nil //nolint:gosec
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target), nil
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target), nil
+	default:
+		if _, err := exec.LookPath("xdg-open"); err != nil {
+			return nil, errors.New("no browser found, set $BROWSER")
+		}
+		return exec.Command("xdg-open", target), nil
+	}
+}
+
+// openBrowser opens target in the browser. The terminal is handed over so
+// text-mode browsers work too.
+func openBrowser(target string) tea.Cmd {
+	cb := func(err error) tea.Msg {
+		return browserFinishedMsg{err}
+	}
+	cmd, err := browserCmd(target)
+	if err != nil {
+		return func() tea.Msg { return cb(err) }
+	}
+	return tea.ExecProcess(cmd, cb)
+}
+
+// resolveLink returns the target of a link found in the document at path.
+// Relative links of local documents point to files next to the document.
+func resolveLink(path, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || path == "" {
+		return link
+	}
+	return filepath.Join(filepath.Dir(path), filepath.FromSlash(u.Path))
+}
@@ -1,5 +1,7 @@
 package ui
 
+import "github.com/douglas-larocca/glow/v2/termprofile"
+
 // Config contains TUI-specific configuration.
 type Config struct {
 	ShowAllFiles     bool
@@ -11,9 +13,102 @@ type Config struct {
 	EnableMouse      bool
 	PreserveNewLines bool
 
+	// Columns of space left of documents, and whether documents narrower
+	// than the pager are centered in it instead.
+	Margin uint
+	Center bool
+
+	// How to display YAML frontmatter: show, hide or only.
+	Frontmatter string
+
+	// How to display links: inline, list or footnote.
+	Links string
+
+	// How to display LaTeX math: unicode, source or off.
+	Math string
+
+	// How to display tables wider than the pager: wrap, scroll or records.
+	WideTables string
+
+	// Which code blocks keep their ANSI escapes: fence, all or off.
+	RawANSI string
+
+	// How to display images: box, ascii or link.
+	Images string
+
+	// Chroma themes of the code blocks of some languages.
+	CodeThemes map[string]string
+
+	// Whether changes to documents, like ticking off tasks, aren't written
+	// back to their files.
+	ReadOnly bool
+
+	// Whether documents open with every section folded under its heading.
+	Fold bool
+
+	// Custom keys for TUI actions, see KeyBindings.
+	Keys map[string][]string
+
+	// File bookmarks are stored in. Bookmarks are disabled if empty.
+	BookmarksFile string
+
+	// File the recently opened documents are stored in, and how many are
+	// kept. Documents aren't remembered if it's empty.
+	HistoryFile string
+	HistorySize int
+
+	// Whether the file listing starts with the recently opened documents.
+	ShowRecent bool
+
+	// File reading positions are stored in. Positions aren't restored if
+	// empty.
+	PositionsFile string
+
+	// File annotations are stored in. Annotations are disabled if empty.
+	AnnotationsFile string
+
+	// Directory stashed documents are kept in. The stash is disabled if
+	// empty.
+	StashDir string
+
+	// Age identity file encrypted documents are decrypted with, before
+	// asking for their passphrase.
+	StashIdentity string
+
+	// What the terminal can do: without an alternate screen, the TUI takes
+	// over the normal one, and documents are drawn with ASCII on terminals
+	// that only show ASCII. Defaults to all an xterm does.
+	TermProfile termprofile.Profile
+
+	// Largest local document opened, in bytes, or 0 for no limit.
+	MaxSize int64
+
+	// Whether the counts of words, headings, links and code blocks of
+	// documents, and their reading time, are shown in the status bar.
+	ShowStats bool
+
 	// Working directory or file path
 	Path string
 
+	// Further documents opened in tabs after the one at Path
+	Tabs []string
+
+	// Anchor of the heading the document is scrolled to once it's rendered,
+	// e.g. installation for README.md#installation.
+	Anchor string
+
+	// Remote repository browsed in place of Path, if any.
+	Repo Repo
+
+	// Renders a document read from path, which is empty for documents that
+	// aren't files, as html, text or ansi for a file it's exported to.
+	Export func(path, markdown, format string) (string, error)
+
+	// Whether the TUI is used remotely, e.g. over SSH. Actions that would
+	// run programs on the host, like opening an editor or a browser, are
+	// disabled.
+	Remote bool
+
 	// For debugging the UI
 	HighPerformancePager bool `env:"GLOW_HIGH_PERFORMANCE_PAGER" envDefault:"true"`
 	GlamourEnabled       bool `env:"GLOW_ENABLE_GLAMOUR"         envDefault:"true"`
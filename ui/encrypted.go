@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/stash"
+)
+
+// Keys encrypted documents are decrypted with: those of the identity file
+// of Config.StashIdentity, and the passphrases typed so far.
+var unlockKeys = struct {
+	sync.Mutex
+	identities []age.Identity
+}{}
+
+// loadIdentities reads the identity file configured in cfg, if any.
+func loadIdentities(cfg Config) {
+	if cfg.StashIdentity == "" {
+		return
+	}
+	ids, err := stash.ReadIdentities(cfg.StashIdentity)
+	if err != nil {
+		log.Error("unable to load stash identities", "error", err)
+		return
+	}
+	unlockKeys.Lock()
+	unlockKeys.identities = append(unlockKeys.identities, ids...)
+	unlockKeys.Unlock()
+}
+
+func unlockIdentities() []age.Identity {
+	unlockKeys.Lock()
+	defer unlockKeys.Unlock()
+	return slices.Clone(unlockKeys.identities)
+}
+
+// lockedMsg is sent when an encrypted document can't be decrypted with the
+// keys known so far, or with the passphrase just typed if wrong is set.
+type lockedMsg struct {
+	md    *markdown
+	wrong bool
+}
+
+// decryptMarkdown decrypts the contents of an encrypted document with the
+// keys known so far.
+func decryptMarkdown(md *markdown, data []byte) tea.Msg {
+	data, err := stash.Decrypt(data, unlockIdentities()...)
+	if errors.Is(err, stash.ErrLocked) {
+		return lockedMsg{md: md}
+	}
+	if err != nil {
+		return errMsg{err}
+	}
+	md.Body = string(data)
+	md.encrypted = true
+	return fetchedMarkdownMsg(md)
+}
+
+// unlockMarkdown loads an encrypted document with a passphrase, which is
+// then kept to decrypt other documents.
+func unlockMarkdown(md *markdown, passphrase string) tea.Cmd {
+	return func() tea.Msg {
+		_, id, err := stash.Passphrase(passphrase)
+		if err != nil {
+			return errMsg{err}
+		}
+		data, err := os.ReadFile(md.localPath)
+		if err != nil {
+			return errMsg{err}
+		}
+		data, err = stash.Decrypt(data, id)
+		if errors.Is(err, stash.ErrLocked) {
+			return lockedMsg{md, true}
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+
+		unlockKeys.Lock()
+		unlockKeys.identities = append(unlockKeys.identities, id)
+		unlockKeys.Unlock()
+		md.Body = string(data)
+		md.encrypted = true
+		return fetchedMarkdownMsg(md)
+	}
+}
+
+// unlockState is the prompt for the passphrase of an encrypted document.
+type unlockState struct {
+	md    *markdown
+	input textinput.Model
+}
+
+func newPassphraseInput(wrong bool) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Passphrase:"
+	ti.PromptStyle = stashInputPromptStyle
+	ti.Cursor.Style = stashInputCursorStyle
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.Placeholder = "to open this encrypted document"
+	if wrong {
+		ti.Placeholder = "wrong passphrase, try again"
+	}
+	return ti
+}
+
+func (m stashModel) unlocking() bool {
+	return m.unlock.md != nil
+}
+
+// startUnlocking asks for the passphrase of an encrypted document.
+func (m *stashModel) startUnlocking(md *markdown, wrong bool) tea.Cmd {
+	m.viewState = stashStateReady
+	m.unlock = unlockState{md, newPassphraseInput(wrong)}
+	return m.unlock.input.Focus()
+}
+
+// updateUnlocking handles keys while the passphrase is typed.
+func (m *stashModel) updateUnlocking(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		m.unlock = unlockState{}
+		return nil
+	case keyEnter:
+		md, passphrase := m.unlock.md, m.unlock.input.Value()
+		if passphrase == "" {
+			return nil
+		}
+		m.unlock = unlockState{}
+		m.viewState = stashStateLoadingDocument
+		return tea.Batch(unlockMarkdown(md, passphrase), m.spinner.Tick)
+	}
+	var cmd tea.Cmd
+	m.unlock.input, cmd = m.unlock.input.Update(msg)
+	return cmd
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// exportFormat is a format documents are exported to, with the extension of
+// its files.
+type exportFormat struct {
+	name, ext string
+}
+
+// exportFormats are the formats documents are exported to, in the order tab
+// cycles through them.
+var exportFormats = []exportFormat{
+	{"html", ".html"},
+	{"text", ".txt"},
+	{"ansi", ".ans"},
+}
+
+// exportFormatOf returns the index of the format of a file named name, as
+// told by its extension, or -1 if the extension is none of theirs.
+func exportFormatOf(name string) int {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm":
+		return 0
+	case ".txt":
+		return 1
+	case ".ans", ".ansi":
+		return 2
+	}
+	return -1
+}
+
+// exportState is the state of exporting the current document to a file.
+type exportState struct {
+	// Whether the name of the file is being written.
+	writing bool
+	input   textinput.Model
+	// Index of the format exported to, unless the extension of the file
+	// names another.
+	format int
+}
+
+// exporting reports whether the pager takes all keys to name the file the
+// current document is exported to.
+func (m pagerModel) exporting() bool {
+	return m.export.writing
+}
+
+// startExporting asks for the name of the file to export the current
+// document to.
+func (m *pagerModel) startExporting() tea.Cmd {
+	if m.common.cfg.Remote || m.common.cfg.Export == nil {
+		return m.showStatusMessage(pagerStatusMessage{"Can’t export remotely", true})
+	}
+
+	ti := textinput.New()
+	ti.Prompt = "Export as:"
+	ti.PromptStyle = stashInputPromptStyle
+	ti.Cursor.Style = stashInputCursorStyle
+	ti.Placeholder = m.exportName() + exportFormats[0].ext
+	ti.CharLimit = 4096
+
+	m.state = pagerStateBrowse
+	m.export = exportState{writing: true, input: ti}
+	return m.export.input.Focus()
+}
+
+// exportName returns the name files the current document is exported to are
+// named after, without an extension.
+func (m pagerModel) exportName() string {
+	doc := m.currentDocument
+	switch {
+	case doc.localPath != "":
+		return strings.TrimSuffix(filepath.Base(doc.localPath), filepath.Ext(doc.localPath))
+	case doc.remotePath != "":
+		return strings.TrimSuffix(filepath.Base(doc.remotePath), filepath.Ext(doc.remotePath))
+	case doc.pasted:
+		return "pasted"
+	}
+	return "document"
+}
+
+// updateExporting handles keys while the name of the file is written. Tab
+// switches to the next format, changing the extension of the name.
+func (m *pagerModel) updateExporting(msg tea.KeyMsg) tea.Cmd {
+	e := &m.export
+	switch msg.String() {
+	case keyEsc:
+		m.export = exportState{}
+		return nil
+	case "tab", "shift+tab":
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(exportFormats) - 1
+		}
+		if f := exportFormatOf(e.input.Value()); f >= 0 {
+			e.format = f
+		}
+		prev := exportFormats[e.format].ext
+		e.format = (e.format + step) % len(exportFormats)
+		next := exportFormats[e.format].ext
+		e.input.Placeholder = strings.TrimSuffix(e.input.Placeholder, prev) + next
+		if v := e.input.Value(); v != "" {
+			e.input.SetValue(strings.TrimSuffix(v, filepath.Ext(v)) + next)
+			e.input.CursorEnd()
+		}
+		return nil
+	case keyEnter:
+		name := strings.TrimSpace(e.input.Value())
+		if name == "" {
+			name = e.input.Placeholder
+		}
+		format := e.format
+		if f := exportFormatOf(name); f >= 0 {
+			format = f
+		}
+		m.export = exportState{}
+		return m.exportToFile(name, exportFormats[format].name)
+	}
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return cmd
+}
+
+// exportToFile renders the current document in format and writes it to a
+// new file, relative to the directory of the file listing.
+func (m *pagerModel) exportToFile(name, format string) tea.Cmd {
+	name, cwd := m.resolveFileName(name)
+	out, err := m.common.cfg.Export(m.currentDocument.localPath, m.currentDocument.Body, format)
+	if err != nil {
+		log.Error("unable to export document", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t export document", true})
+	}
+	err = writeNewFile(name, out)
+	if errors.Is(err, fs.ErrExist) {
+		return m.showStatusMessage(pagerStatusMessage{"File already exists", true})
+	}
+	if err != nil {
+		log.Error("unable to export document", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t export document", true})
+	}
+	return m.showStatusMessage(pagerStatusMessage{"Exported " + stripAbsolutePath(name, cwd), false})
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// mtime<7d, mtime>2w, mtime>2024-06-01 and the like.
+	mtimePattern = regexp.MustCompile(`^mtime([<>])(?:(\d+)([smhdwy])|(\d{4}-\d{2}-\d{2}))$`)
+
+	// Lengths of the units of ages.
+	ageUnits = map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+)
+
+// fileFilter narrows the file listing down. Besides text matched against
+// the names and contents of documents, filters can have:
+//
+//   - glob patterns, matched against the paths of documents, or their names
+//     for patterns without slashes, e.g. *.md or docs/*
+//   - the time documents were changed: mtime<7d for those changed in the
+//     last 7 days, mtime>1y for those not changed for a year, and
+//     mtime>2024-06-01 for those changed since a date
+//   - frontmatter tags, as #tag or tag:tag
+//
+// Documents have to match all of them.
+type fileFilter struct {
+	globs []string
+	tags  []string
+	times []func(time.Time) bool
+	text  string
+}
+
+// parseFilter parses the text of the filter bar. Words that aren't patterns,
+// times or tags are kept as text.
+func parseFilter(s string, now time.Time) fileFilter {
+	var (
+		f    fileFilter
+		text []string
+	)
+	for _, word := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			f.tags = append(f.tags, word[1:])
+		case strings.HasPrefix(word, "tag:") && len(word) > 4:
+			f.tags = append(f.tags, word[4:])
+		case strings.ContainsAny(word, "*?["):
+			if _, err := path.Match(word, ""); err != nil {
+				text = append(text, word)
+				break
+			}
+			f.globs = append(f.globs, word)
+		default:
+			m := mtimePattern.FindStringSubmatch(word)
+			if m == nil {
+				text = append(text, word)
+				break
+			}
+			fn, ok := mtimeFilter(m, now)
+			if !ok {
+				text = append(text, word)
+				break
+			}
+			f.times = append(f.times, fn)
+		}
+	}
+	f.text = strings.Join(text, " ")
+	return f
+}
+
+// mtimeFilter returns the test of the modification times of documents for
+// an mtime expression matched by mtimePattern. It reports false for dates
+// that don't exist.
+func mtimeFilter(m []string, now time.Time) (func(time.Time) bool, bool) {
+	newer := m[1] == ">"
+	var since time.Time
+	if m[4] != "" {
+		var err error
+		if since, err = time.ParseInLocation(time.DateOnly, m[4], time.Local); err != nil {
+			return nil, false
+		}
+	} else {
+		// Ages compare the other way around: mtime<7d is newer than a week.
+		n, _ := strconv.Atoi(m[2])
+		since = now.Add(-time.Duration(n) * ageUnits[m[3]])
+		newer = !newer
+	}
+	return func(t time.Time) bool {
+		if t.IsZero() {
+			return false
+		}
+		return t.After(since) == newer
+	}, true
+}
+
+// narrows reports whether the filter has more than text.
+func (f fileFilter) narrows() bool {
+	return len(f.globs) > 0 || len(f.tags) > 0 || len(f.times) > 0
+}
+
+// match reports whether a document matches the patterns, times and tags of
+// the filter.
+func (f fileFilter) match(md *markdown) bool {
+	for _, g := range f.globs {
+		name := filepath.ToSlash(md.Note)
+		if !strings.Contains(g, "/") {
+			name = path.Base(name)
+		}
+		if ok, _ := path.Match(g, name); !ok {
+			return false
+		}
+	}
+	for _, fn := range f.times {
+		if !fn(md.Modtime) {
+			return false
+		}
+	}
+	if len(f.tags) == 0 {
+		return true
+	}
+	tags := documentTags(md)
+	for _, want := range f.tags {
+		if !containsFold(tags, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether a list has a string, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
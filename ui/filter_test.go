@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		input string
+		globs string
+		tags  string
+		times int
+		text  string
+	}{
+		{"release notes", "", "", 0, "release notes"},
+		{"notes *.md docs/*", "*.md,docs/*", "", 0, "notes"},
+		{"#work tag:home plan", "", "work,home", 0, "plan"},
+		{"mtime<7d mtime>2024-06-01 todo", "", "", 2, "todo"},
+		{"# tag: [unclosed", "", "", 0, "# tag: [unclosed"},
+		{"mtime<7x mtime=7d mtime<d", "", "", 0, "mtime<7x mtime=7d mtime<d"},
+		{"mtime>2024-13-45 mtime<2024-02-30", "", "", 0, "mtime>2024-13-45 mtime<2024-02-30"},
+		{"  ", "", "", 0, ""},
+	} {
+		f := parseFilter(tc.input, now)
+		if got := strings.Join(f.globs, ","); got != tc.globs {
+			t.Errorf("%q: expected globs %q, got %q", tc.input, tc.globs, got)
+		}
+		if got := strings.Join(f.tags, ","); got != tc.tags {
+			t.Errorf("%q: expected tags %q, got %q", tc.input, tc.tags, got)
+		}
+		if len(f.times) != tc.times {
+			t.Errorf("%q: expected %d times, got %d", tc.input, tc.times, len(f.times))
+		}
+		if f.text != tc.text {
+			t.Errorf("%q: expected text %q, got %q", tc.input, tc.text, f.text)
+		}
+		if f.narrows() != (tc.globs != "" || tc.tags != "" || tc.times > 0) {
+			t.Errorf("%q: expected narrows to be %v", tc.input, !f.narrows())
+		}
+	}
+}
+
+func TestMtimeFilter(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.Local)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	day := 24 * time.Hour
+	for _, tc := range []struct {
+		expr  string
+		mtime time.Time
+		want  bool
+	}{
+		// Ages: < is newer than, > is older than.
+		{"mtime<7d", ago(3 * day), true},
+		{"mtime<7d", ago(10 * day), false},
+		{"mtime>7d", ago(10 * day), true},
+		{"mtime>7d", ago(3 * day), false},
+		{"mtime<2h", ago(time.Hour), true},
+		{"mtime<2h", ago(3 * time.Hour), false},
+		{"mtime>2w", ago(15 * day), true},
+		{"mtime>1y", ago(100 * day), false},
+		{"mtime<30m", ago(10 * time.Minute), true},
+		{"mtime<30s", ago(time.Minute), false},
+		// Dates: > is since, < is before.
+		{"mtime>2024-06-01", time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local), true},
+		{"mtime>2024-06-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), false},
+		{"mtime<2024-06-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), true},
+		{"mtime<2024-06-01", time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local), false},
+		// Documents without a time never match.
+		{"mtime<7d", time.Time{}, false},
+		{"mtime>2024-06-01", time.Time{}, false},
+	} {
+		m := mtimePattern.FindStringSubmatch(tc.expr)
+		if m == nil {
+			t.Fatalf("%s: expected an mtime expression", tc.expr)
+		}
+		fn, ok := mtimeFilter(m, now)
+		if !ok {
+			t.Fatalf("%s: expected a valid expression", tc.expr)
+		}
+		if got := fn(tc.mtime); got != tc.want {
+			t.Errorf("%s with %s: expected %v, got %v", tc.expr, tc.mtime.Format(time.DateTime), tc.want, got)
+		}
+	}
+}
+
+func TestFilterGlobs(t *testing.T) {
+	md := &markdown{Note: "docs/guide.md"}
+	for pattern, want := range map[string]bool{
+		"*.md":          true,
+		"guide*":        true,
+		"docs/*":        true,
+		"*.txt":         false,
+		"other/*":       false,
+		"docs/g?ide.md": true,
+	} {
+		if got := parseFilter(pattern, time.Now()).match(md); got != want {
+			t.Errorf("%s: expected %v, got %v", pattern, want, got)
+		}
+	}
+}
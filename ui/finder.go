@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	finderMaxWidth  = 80
+	finderMaxHeight = 20
+
+	// Added to the score of paths whose file name matches, so that a query
+	// matching a file name ranks above one spread across directories.
+	finderNameBonus = 10
+)
+
+var (
+	finderBorderStyle = lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(dullFuchsia).
+				Padding(0, 1)
+	finderMatchStyle         = lipgloss.NewStyle().Foreground(fuchsia).Underline(true)
+	finderSelectedStyle      = lipgloss.NewStyle().Foreground(fuchsia)
+	finderSelectedMatchStyle = finderSelectedStyle.Underline(true).Bold(true)
+	finderPathStyle          = lipgloss.NewStyle().Foreground(brightGray)
+)
+
+// finderModel is a ctrl+p style overlay to jump to any document found in the
+// scanned tree by typing part of its path.
+type finderModel struct {
+	open  bool
+	input textinput.Model
+	// The query the matches were found for.
+	query   string
+	matches fuzzy.Matches
+	// The documents the matches index.
+	markdowns []*markdown
+	cursor    int
+	offset    int
+}
+
+func newFinderModel() finderModel {
+	ti := textinput.New()
+	ti.Prompt = "Go to:"
+	ti.PromptStyle = stashInputPromptStyle
+	ti.Cursor.Style = stashInputCursorStyle
+	return finderModel{input: ti}
+}
+
+// rankFiles returns the paths matching query, best first. Paths matching
+// equally well are ordered by length, so that shallower files come first.
+// All paths match an empty query, in order.
+func rankFiles(query string, paths []string) fuzzy.Matches {
+	if query == "" {
+		matches := make(fuzzy.Matches, len(paths))
+		for i, p := range paths {
+			matches[i] = fuzzy.Match{Str: p, Index: i}
+		}
+		return matches
+	}
+
+	matches := fuzzy.FindNoSort(query, paths)
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = filepath.Base(m.Str)
+	}
+	for _, n := range fuzzy.FindNoSort(query, names) {
+		m := &matches[n.Index]
+		m.Score += n.Score + finderNameBonus
+
+		// Highlight the match in the file name rather than the one in the
+		// whole path.
+		start := len(m.Str) - len(n.Str)
+		m.MatchedIndexes = make([]int, len(n.MatchedIndexes))
+		for i, j := range n.MatchedIndexes {
+			m.MatchedIndexes[i] = start + j
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b fuzzy.Match) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		return len(a.Str) - len(b.Str)
+	})
+	return matches
+}
+
+// openFinder shows the finder over the current view, starting a search for
+// documents if there hasn't been one.
+func (m *model) openFinder() tea.Cmd {
+	m.finder.open = true
+	m.finder.input.Reset()
+	m.finder.input.Focus()
+	m.finder.cursor = 0
+	m.filterFinder()
+
+	cmds := []tea.Cmd{textinput.Blink}
+	if m.state == stateShowDocument && m.pager.viewport.HighPerformanceRendering {
+		// The finder is drawn over the viewport, which the high performance
+		// renderer would draw over in turn.
+		m.pager.viewport.HighPerformanceRendering = false
+		cmds = append(cmds, tea.ClearScrollArea) //nolint:staticcheck
+	}
+	if m.localFileFinder == nil {
+		cmds = append(cmds, findLocalFiles(*m.common))
+	}
+	return tea.Batch(cmds...)
+}
+
+// closeFinder hides the finder.
+func (m *model) closeFinder() tea.Cmd {
+	m.finder.open = false
+	m.finder.input.Blur()
+	if m.state == stateShowDocument && m.pager.highPerformance() {
+		m.pager.viewport.HighPerformanceRendering = true
+		return viewport.Sync(m.pager.viewport)
+	}
+	return nil
+}
+
+// filterFinder ranks the documents found so far against the query. As long
+// as the query is the same, the selected document stays selected.
+func (m *model) filterFinder() {
+	f := &m.finder
+	var selected *markdown
+	if f.cursor < len(f.matches) {
+		selected = f.markdowns[f.matches[f.cursor].Index]
+	}
+
+	query := strings.TrimSpace(f.input.Value())
+	f.markdowns = m.stash.markdowns
+	paths := make([]string, len(f.markdowns))
+	for i, md := range f.markdowns {
+		paths[i] = md.Note
+	}
+	f.matches = rankFiles(query, paths)
+
+	i := slices.IndexFunc(f.matches, func(r fuzzy.Match) bool { return f.markdowns[r.Index] == selected })
+	if query != f.query || i < 0 {
+		i = 0
+	}
+	f.query = query
+	f.cursor, f.offset = i, 0
+	m.scrollFinder()
+}
+
+// finderRows is the number of results the finder shows at once.
+func (m model) finderRows() int {
+	return max(1, min(finderMaxHeight, m.common.height-6))
+}
+
+// scrollFinder keeps the selected result in view.
+func (m *model) scrollFinder() {
+	rows := m.finderRows()
+	if m.finder.cursor < m.finder.offset {
+		m.finder.offset = m.finder.cursor
+	}
+	if m.finder.cursor >= m.finder.offset+rows {
+		m.finder.offset = m.finder.cursor - rows + 1
+	}
+}
+
+// updateFinder handles keys while the finder is open, reporting false for
+// those it leaves to the rest of the application.
+func (m *model) updateFinder(msg tea.KeyMsg) (tea.Cmd, bool) {
+	f := &m.finder
+	switch msg.String() {
+	case "ctrl+c":
+		return nil, false
+	case keyEsc:
+		return m.closeFinder(), true
+	case "up", "ctrl+k", "ctrl+p":
+		f.cursor = max(0, f.cursor-1)
+	case "down", "ctrl+j", "ctrl+n":
+		f.cursor = max(0, min(len(f.matches)-1, f.cursor+1))
+	case "pgup":
+		f.cursor = max(0, f.cursor-m.finderRows())
+	case "pgdown":
+		f.cursor = max(0, min(len(f.matches)-1, f.cursor+m.finderRows()))
+	case keyEnter, "ctrl+t":
+		if len(f.matches) == 0 {
+			return nil, true
+		}
+		md := f.markdowns[f.matches[f.cursor].Index]
+		return tea.Batch(m.closeFinder(), m.openFound(md, msg.String() == "ctrl+t")), true
+	default:
+		var cmd tea.Cmd
+		value := f.input.Value()
+		f.input, cmd = f.input.Update(msg)
+		if f.input.Value() != value {
+			m.filterFinder()
+		}
+		return cmd, true
+	}
+	m.scrollFinder()
+	return nil, true
+}
+
+// openFound opens a document picked in the finder in place of the current
+// one, or in a new tab if inTab is set.
+func (m *model) openFound(md *markdown, inTab bool) tea.Cmd {
+	if m.state == stateShowStash {
+		return m.stash.openMarkdown(md, inTab)
+	}
+
+	var cmds []tea.Cmd
+	switch {
+	case inTab && !m.tabbed():
+		// The document shown becomes the first tab.
+		cmds = append(cmds, m.newTab())
+	case inTab:
+	case m.tabbed():
+		cmds = append(cmds, m.leaveTab())
+	default:
+		cmds = append(cmds, m.unloadDocument()...)
+	}
+	if inTab {
+		return tea.Batch(append(cmds, loadLocalMarkdownInTab(md))...)
+	}
+	return tea.Batch(append(cmds, m.stash.openMarkdown(md, false))...)
+}
+
+// finderView draws the finder in the middle of the screen.
+func (m model) finderView() string {
+	f := m.finder
+	width := max(20, min(finderMaxWidth, m.common.width-4))
+	inner := width - 4 // border and padding
+
+	var b strings.Builder
+	b.WriteString(truncate.StringWithTail(f.input.View(), uint(inner), ellipsis)) //nolint:gosec
+	b.WriteString("\n")
+
+	rows := m.finderRows()
+	end := min(len(f.matches), f.offset+rows)
+	for i := f.offset; i < end; i++ {
+		b.WriteString("\n")
+		b.WriteString(finderResultView(f.matches[i], inner, i == f.cursor))
+	}
+	for i := end - f.offset; i < rows; i++ {
+		b.WriteString("\n")
+	}
+
+	status := fmt.Sprintf("%d/%d", len(f.matches), len(f.markdowns))
+	if !m.stash.loaded {
+		status += " searching" + ellipsis
+	}
+	b.WriteString("\n\n" + subtleStyle.Render(status+"  enter open • ctrl+t open in tab • esc close"))
+
+	box := finderBorderStyle.Width(width - 2).Render(b.String())
+	return lipgloss.Place(m.common.width, m.common.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// finderResultView draws a result truncated to width, with the matched
+// characters highlighted.
+func finderResultView(r fuzzy.Match, width int, selected bool) string {
+	gutter, style, matchStyle := "  ", finderPathStyle, finderMatchStyle
+	if selected {
+		gutter = dullFuchsiaFg(verticalLine) + " "
+		style, matchStyle = finderSelectedStyle, finderSelectedMatchStyle
+	}
+
+	s := truncate.String(r.Str, uint(max(0, width-2))) //nolint:gosec
+	var b strings.Builder
+	for i, c := range s {
+		if slices.Contains(r.MatchedIndexes, i) {
+			b.WriteString(matchStyle.Render(string(c)))
+		} else {
+			b.WriteString(style.Render(string(c)))
+		}
+	}
+	return gutter + b.String()
+}
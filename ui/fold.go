@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// foldState is the state of the sections of the current document folded
+// under their headings.
+type foldState struct {
+	// Source lines of the headings whose sections are folded.
+	folded map[int]bool
+	// Whether the fold key was pressed, so that the next key says what to
+	// fold.
+	pending bool
+
+	// The document as shown, without the folded sections, and the line of
+	// the source each of its lines comes from.
+	body  string
+	lines []int
+
+	// Source line of the heading scrolled to once the document is rendered
+	// again, or -1.
+	focus int
+
+	// Whether the document was folded whole on opening, as configured.
+	applied bool
+}
+
+// shownBody returns the source of the current document as it's rendered,
+// without its folded sections.
+func (m pagerModel) shownBody() string {
+	if len(m.fold.folded) == 0 {
+		return m.currentDocument.Body
+	}
+	return m.fold.body
+}
+
+// sourceLineOf returns the line of the document's source that line i of the
+// shown document comes from.
+func (m pagerModel) sourceLineOf(i int) int {
+	if len(m.fold.folded) == 0 || i < 0 || i >= len(m.fold.lines) {
+		return i
+	}
+	return m.fold.lines[i]
+}
+
+// updateFold handles the key after the fold key: a toggles the section at
+// the top of the viewport, o opens it and c closes it, M folds every section
+// and R unfolds them all. Any other key is dropped.
+func (m *pagerModel) updateFold(msg tea.KeyMsg) tea.Cmd {
+	f := &m.fold
+	if !f.pending {
+		f.pending = true
+		return nil
+	}
+	f.pending = false
+
+	switch msg.String() {
+	case "M":
+		m.foldAll()
+		m.viewport.GotoTop()
+		return m.render()
+	case "R":
+		f.folded = nil
+		f.focus = m.currentFoldHeading()
+		return m.render()
+	case "a", "o", "c":
+	default:
+		return nil
+	}
+
+	line := m.currentFoldHeading()
+	if line < 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No heading to fold", true})
+	}
+	fold := !f.folded[line]
+	switch msg.String() {
+	case "o":
+		fold = false
+	case "c":
+		fold = true
+	}
+	if f.folded == nil {
+		f.folded = map[int]bool{}
+	}
+	if fold {
+		f.folded[line] = true
+	} else {
+		delete(f.folded, line)
+	}
+	f.focus = line
+	return m.render()
+}
+
+// foldAll folds the sections of every heading of the current document.
+func (m *pagerModel) foldAll() {
+	m.fold.folded = map[int]bool{}
+	for _, h := range sourceHeadings(m.currentDocument.Body) {
+		m.fold.folded[h.line] = true
+	}
+}
+
+// currentFoldHeading returns the source line of the last heading at or
+// above the top of the viewport, or of the first heading in view, or -1 if
+// there's none.
+func (m pagerModel) currentFoldHeading() int {
+	line := -1
+	for _, h := range documentHeadings(m.shownBody(), m.rendered) {
+		if h.rendered > m.viewport.YOffset && (line >= 0 || h.rendered >= m.viewport.YOffset+m.viewport.Height) {
+			break
+		}
+		line = m.sourceLineOf(h.source)
+	}
+	return line
+}
+
+// scrollToFold scrolls to the heading folded or unfolded last, once the
+// document is rendered again. It reports whether there was one.
+func (m *pagerModel) scrollToFold() bool {
+	focus := m.fold.focus
+	if focus < 0 {
+		return false
+	}
+	m.fold.focus = -1
+	for _, h := range documentHeadings(m.shownBody(), m.rendered) {
+		if m.sourceLineOf(h.source) == focus {
+			m.viewport.SetYOffset(h.rendered)
+			break
+		}
+	}
+	return true
+}
+
+// setFolds works out the document as shown with its sections folded.
+func (m *pagerModel) setFolds() {
+	f := &m.fold
+	if len(f.folded) == 0 {
+		f.body, f.lines = "", nil
+		return
+	}
+	f.body, f.lines = foldSections(m.currentDocument.Body, f.folded)
+}
+
+// sourceHeading is a heading at the top level of a document, with the lines
+// of the source it starts and ends on.
+type sourceHeading struct {
+	level     int
+	line, end int
+}
+
+// sourceHeadings finds the headings at the top level of a document.
+func sourceHeadings(source string) []sourceHeading {
+	src := []byte(source)
+	_, body := utils.SplitFrontmatter(src)
+	offset := len(src) - len(body)
+	doc := splitParser.Parse(text.NewReader(body))
+	lines := strings.Split(source, "\n")
+
+	var headings []sourceHeading
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		line := bytes.Count(src[:offset+h.Lines().At(0).Start], []byte("\n"))
+		end := bytes.Count(src[:offset+h.Lines().At(h.Lines().Len()-1).Start], []byte("\n"))
+		// Setext headings are underlined on the next line.
+		if !strings.HasPrefix(strings.TrimLeft(lines[line], " "), "#") {
+			end++
+		}
+		headings = append(headings, sourceHeading{level: h.Level, line: line, end: end})
+	}
+	return headings
+}
+
+// foldSections leaves out of a document the sections of the headings on the
+// folded lines, up to the next heading of the same or a higher level, with
+// a note of how many lines were left out in their place. It returns the
+// folded document and the line of the source each of its lines comes from.
+func foldSections(source string, folded map[int]bool) (string, []int) {
+	srcLines := strings.Split(source, "\n")
+	headings := sourceHeadings(source)
+
+	var (
+		out   []string
+		lines []int
+	)
+	next := 0
+	for i, h := range headings {
+		if !folded[h.line] || h.line < next {
+			continue
+		}
+		// The section ends at the next heading of the same or a higher
+		// level.
+		end := len(srcLines)
+		for _, o := range headings[i+1:] {
+			if o.level <= h.level {
+				end = o.line
+				break
+			}
+		}
+		for l := next; l <= h.end && l < len(srcLines); l++ {
+			out, lines = append(out, srcLines[l]), append(lines, l)
+		}
+		hidden := 0
+		for l := h.end + 1; l < end; l++ {
+			if strings.TrimSpace(srcLines[l]) != "" {
+				hidden = l - h.end
+			}
+		}
+		if hidden > 0 {
+			note := fmt.Sprintf("*%s %d lines folded*", ellipsis, hidden)
+			if hidden == 1 {
+				note = fmt.Sprintf("*%s 1 line folded*", ellipsis)
+			}
+			out = append(out, "", note, "")
+			lines = append(lines, h.line, h.line, h.line)
+		}
+		next = max(end, h.end+1)
+	}
+	for l := next; l < len(srcLines); l++ {
+		out, lines = append(out, srcLines[l]), append(lines, l)
+	}
+	return strings.Join(out, "\n"), lines
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/douglas-larocca/glow/v2/wikilinks"
+)
+
+// visit is a document the reader followed a link from, and where they were
+// in it.
+type visit struct {
+	doc      markdown
+	position *reflowPosition
+}
+
+// resolveWikiLinks turns the [[Page]] links of the local document at path
+// into links to the files they name. Pages are looked up once per root, see
+// wikilinks.Root.
+func (c commonModel) resolveWikiLinks(path, md string) string {
+	if !strings.Contains(md, "[[") || c.wikiLinks == nil {
+		return md
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return md
+	}
+	dir := filepath.Dir(path)
+	root := wikilinks.Root(dir)
+	r, ok := c.wikiLinks[root]
+	if !ok {
+		r = wikilinks.NewResolver(root)
+		c.wikiLinks[root] = r
+	}
+	return wikilinks.Transform(md, r.Link(dir))
+}
+
+// linkedDocument returns the local markdown file a link of the current
+// document points to, or "" for the current document itself, and the anchor
+// of the heading it points to. It reports false for links to anything else,
+// which are opened in the browser.
+func (m pagerModel) linkedDocument(link string) (path, anchor string, ok bool) {
+	from := m.currentDocument.localPath
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || from == "" {
+		return "", "", false
+	}
+	if u.Path == "" {
+		return "", u.Fragment, u.Fragment != ""
+	}
+	path = resolveLink(from, link)
+	if !utils.IsMarkdownFile(path) {
+		return "", "", false
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", "", false
+	}
+	return path, u.Fragment, true
+}
+
+// followLink opens a local document in place of the current one, scrolled
+// to the heading of anchor if any. As in a browser, the current document is
+// kept to go back to, and documents gone back from can't be gone forward to
+// anymore.
+func (m *pagerModel) followLink(path, anchor string) tea.Cmd {
+	here := m.here()
+	if path == "" || path == m.currentDocument.localPath {
+		line := 0
+		if anchor != "" {
+			line = anchorLine(m.shownBody(), m.rendered, anchor)
+		}
+		if line < 0 {
+			return m.showStatusMessage(pagerStatusMessage{"No heading #" + anchor, true})
+		}
+		m.back, m.forward = append(m.back, here), nil
+		m.viewport.SetYOffset(line)
+		return nil
+	}
+
+	back := append(m.back, here)
+	m.unload()
+	m.back = back
+
+	md := &markdown{
+		localPath: path,
+		Note:      stripAbsolutePath(path, m.common.cwd),
+		anchor:    anchor,
+	}
+	m.currentDocument = *md
+	return loadLocalMarkdown(md)
+}
+
+// here returns the current document, without its contents, and where the
+// reader is in it.
+func (m pagerModel) here() visit {
+	doc := m.currentDocument
+	doc.Body = ""
+	return visit{doc, m.readingPosition()}
+}
+
+// goBack returns to the document the current one was opened from, where the
+// reader left it.
+func (m *pagerModel) goBack() tea.Cmd {
+	if len(m.back) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"Nothing to go back to", false})
+	}
+	v := m.back[len(m.back)-1]
+	m.back, m.forward = m.back[:len(m.back)-1], append(m.forward, m.here())
+	return m.visit(v)
+}
+
+// goForward returns to the document last gone back from.
+func (m *pagerModel) goForward() tea.Cmd {
+	if len(m.forward) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"Nothing to go forward to", false})
+	}
+	v := m.forward[len(m.forward)-1]
+	m.back, m.forward = append(m.back, m.here()), m.forward[:len(m.forward)-1]
+	return m.visit(v)
+}
+
+// visit opens a document of the history of followed links where the reader
+// left it, loading it again unless it's the current one.
+func (m *pagerModel) visit(v visit) tea.Cmd {
+	if v.doc.localPath == m.currentDocument.localPath {
+		m.reflow = v.position
+		m.restoreReflow()
+		return nil
+	}
+
+	back, forward := m.back, m.forward
+	m.unload()
+	m.back, m.forward = back, forward
+	m.reflow = v.position
+
+	md := v.doc
+	m.currentDocument = md
+	return loadLocalMarkdown(&md)
+}
+
+// breadcrumb returns the trail of documents followed to the current one, the
+// last few of them by name.
+func (m pagerModel) breadcrumb() string {
+	const maxCrumbs = 3
+
+	var crumbs []string
+	last := m.currentDocument.localPath
+	for i := len(m.back) - 1; i >= 0; i-- {
+		p := m.back[i].doc.localPath
+		if p == last {
+			// Jumped to a heading of the same document.
+			continue
+		}
+		last = p
+		if len(crumbs) == maxCrumbs {
+			crumbs = append(crumbs, ellipsis)
+			break
+		}
+		crumbs = append(crumbs, filepath.Base(p))
+	}
+	slices.Reverse(crumbs)
+	return strings.Join(append(crumbs, m.currentDocument.Note), " › ")
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/footnotes"
+)
+
+var footnotesStyle = lipgloss.NewStyle().
+	Foreground(brightGray).
+	Background(darkGray).
+	Padding(0, 1)
+
+// toggleFootnotes shows or hides the footnotes of the markers on screen.
+func (m *pagerModel) toggleFootnotes() tea.Cmd {
+	if len(m.notes) == 0 && !m.showFootnotes {
+		return m.showStatusMessage(pagerStatusMessage{"No footnotes in this document", false})
+	}
+	m.showFootnotes = !m.showFootnotes
+	return nil
+}
+
+// footnotesView shows the footnotes of the markers in the view of the
+// viewport over its last lines, so they can be read without scrolling to
+// them.
+func (m pagerModel) footnotesView(view string) string {
+	lines := strings.Split(view, "\n")
+	var shown []int
+	for _, line := range lines {
+		text := strings.TrimSpace(stripANSI(line))
+		for _, n := range footnotes.Markers(text) {
+			if strings.HasPrefix(text, footnotes.Marker(n)+" ") {
+				// The footnote itself.
+				continue
+			}
+			if n <= len(m.notes) && !slices.Contains(shown, n) {
+				shown = append(shown, n)
+			}
+		}
+	}
+	if len(shown) == 0 {
+		return view
+	}
+
+	texts := make([]string, len(shown))
+	for i, n := range shown {
+		note := m.notes[n-1]
+		texts[i] = note.Marker() + " " + note.Text
+	}
+	panel := strings.Split(footnotesStyle.Width(m.viewport.Width).Render(strings.Join(texts, "\n")), "\n")
+	if limit := max(1, m.viewport.Height/3); len(panel) > limit {
+		panel = panel[:limit]
+		panel[limit-1] = footnotesStyle.Width(m.viewport.Width).Render(ellipsis)
+	}
+	if len(panel) > len(lines) {
+		return view
+	}
+	copy(lines[len(lines)-len(panel):], panel)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/history"
+)
+
+// loadHistory opens the history of recently opened documents configured in
+// cfg. Documents aren't remembered if it can't be read, nor when the TUI is
+// used remotely, as the history is the host's.
+func loadHistory(cfg Config) *history.Store {
+	if cfg.HistoryFile == "" || cfg.Remote {
+		return nil
+	}
+	s, err := history.Load(cfg.HistoryFile, cfg.HistorySize)
+	if err != nil {
+		log.Error("unable to load history", "error", err)
+		return nil
+	}
+	return s
+}
+
+// recentMarkdowns converts the local documents of the history into list
+// entries, most recently opened first. Files that no longer exist are
+// skipped.
+func recentMarkdowns(s *history.Store, cwd string) []*markdown {
+	if s == nil {
+		return nil
+	}
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	var mds []*markdown //nolint:prealloc
+	for _, e := range s.List() {
+		if !e.IsLocal() {
+			continue
+		}
+		if _, err := os.Stat(e.Path); err != nil {
+			continue
+		}
+		mds = append(mds, &markdown{
+			localPath: e.Path,
+			Note:      stripAbsolutePath(e.Path, cwd),
+			Modtime:   e.Opened,
+		})
+	}
+	return mds
+}
+
+// refreshRecent reloads the recently opened documents and shows or hides
+// the recent section accordingly.
+func (m *stashModel) refreshRecent() {
+	m.recent = recentMarkdowns(m.common.history, m.common.cwd)
+	m.setSectionVisible(recentSection, len(m.recent) > 0)
+}
+
+// addToHistory remembers that a document was opened.
+func (c commonModel) addToHistory(md *markdown) {
+	if c.history == nil || md.localPath == "" {
+		return
+	}
+	path, err := filepath.Abs(md.localPath)
+	if err != nil {
+		return
+	}
+	if err := c.history.Add(path); err != nil {
+		log.Error("unable to save history", "error", err)
+	}
+}
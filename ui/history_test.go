@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/douglas-larocca/glow/v2/history"
+)
+
+func TestLoadHistory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, history.FileName)
+	s, err := history.Load(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(filepath.Join(dir, "notes.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{HistoryFile: path, HistorySize: 10}
+	if s := loadHistory(cfg); s == nil || len(s.List()) != 1 {
+		t.Error("expected the history to be loaded")
+	}
+	cfg.Remote = true
+	if s := loadHistory(cfg); s != nil {
+		t.Error("expected no history when the TUI is used remotely")
+	}
+}
@@ -1,6 +1,137 @@
 package ui
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
 const (
 	keyEnter = "enter"
 	keyEsc   = "esc"
 )
+
+// keyAction is a TUI action that can be bound to custom keys.
+type keyAction struct {
+	name string
+	// The key the update functions handle for this action.
+	key tea.KeyMsg
+	// The states in which the action is available.
+	states []state
+}
+
+var keyActions = []keyAction{
+	{"open", tea.KeyMsg{Type: tea.KeyEnter}, []state{stateShowStash}},
+	{"search", runeKey('/'), []state{stateShowStash}},
+	{"quit", runeKey('q'), []state{stateShowStash, stateShowDocument}},
+	{"lineNumbers", runeKey('l'), []state{stateShowDocument}},
+	{"copy", runeKey('c'), []state{stateShowDocument}},
+	{"copyRendered", runeKey('y'), []state{stateShowDocument}},
+	{"split", runeKey('v'), []state{stateShowDocument}},
+	{"newTab", runeKey('t'), []state{stateShowStash}},
+	{"nextTab", tea.KeyMsg{Type: tea.KeyTab}, []state{stateShowDocument}},
+	{"prevTab", tea.KeyMsg{Type: tea.KeyShiftTab}, []state{stateShowDocument}},
+	{"closeTab", runeKey('x'), []state{stateShowDocument}},
+	{"finder", tea.KeyMsg{Type: tea.KeyCtrlP}, []state{stateShowStash, stateShowDocument}},
+	{"edit", runeKey('e'), []state{stateShowStash, stateShowDocument}},
+	{"annotate", runeKey('a'), []state{stateShowDocument}},
+	{"outline", runeKey('o'), []state{stateShowDocument}},
+	{"tasks", runeKey('t'), []state{stateShowDocument}},
+	{"back", tea.KeyMsg{Type: tea.KeyBackspace}, []state{stateShowDocument}},
+	{"forward", runeKey(']'), []state{stateShowDocument}},
+	{"fold", runeKey('z'), []state{stateShowDocument}},
+	{"export", runeKey('X'), []state{stateShowDocument}},
+}
+
+func runeKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+// KeyBinding is the list of keys bound to a TUI action.
+type KeyBinding struct {
+	Action string
+	Keys   []string
+}
+
+// KeyBindings returns the keys bound to each action that can be configured,
+// with the custom bindings replacing the defaults. Keys are named as in Bubble
+// Tea, e.g. "ctrl+o" or "enter". An empty list of keys disables the action.
+func KeyBindings(custom map[string][]string) ([]KeyBinding, error) {
+	// Action names are case-insensitive, as viper lowercases config keys.
+	lower := make(map[string][]string, len(custom))
+	for name, keys := range custom {
+		if !slices.ContainsFunc(keyActions, func(a keyAction) bool { return strings.EqualFold(a.name, name) }) {
+			return nil, fmt.Errorf("unknown key action %q", name)
+		}
+		lower[strings.ToLower(name)] = keys
+	}
+
+	bindings := make([]KeyBinding, len(keyActions))
+	for i, a := range keyActions {
+		keys, ok := lower[strings.ToLower(a.name)]
+		if !ok {
+			keys = []string{a.key.String()}
+		}
+		bindings[i] = KeyBinding{Action: a.name, Keys: keys}
+
+		// Actions available at the same time can't share keys.
+		for j, b := range bindings[:i] {
+			if !slices.ContainsFunc(a.states, func(s state) bool { return slices.Contains(keyActions[j].states, s) }) {
+				continue
+			}
+			for _, k := range keys {
+				if slices.Contains(b.Keys, k) {
+					return nil, fmt.Errorf("key %q is bound to both %s and %s", k, b.Action, a.name)
+				}
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// keyMap holds the keys bound to the configurable actions, in the same order
+// as keyActions.
+type keyMap [][]string
+
+func newKeyMap(custom map[string][]string) keyMap {
+	bindings, err := KeyBindings(custom)
+	if err != nil {
+		log.Error("invalid key bindings, using the defaults", "error", err)
+		bindings, _ = KeyBindings(nil)
+	}
+	k := make(keyMap, len(bindings))
+	for i, b := range bindings {
+		k[i] = b.Keys
+	}
+	return k
+}
+
+// translate maps a key bound to an action to the key the update functions
+// handle for it. It reports false for the default key of an action which has
+// been bound to other keys, which should be ignored.
+func (k keyMap) translate(msg tea.KeyMsg, s state) (tea.KeyMsg, bool) {
+	pressed := msg.String()
+	for i, a := range keyActions {
+		if slices.Contains(a.states, s) && slices.Contains(k[i], pressed) {
+			return a.key, true
+		}
+	}
+	for _, a := range keyActions {
+		if slices.Contains(a.states, s) && a.key.String() == pressed {
+			return msg, false
+		}
+	}
+	return msg, true
+}
+
+// help returns the keys bound to an action for display in help views.
+func (k keyMap) help(action string) string {
+	i := slices.IndexFunc(keyActions, func(a keyAction) bool { return a.name == action })
+	if i < 0 || len(k) <= i {
+		return ""
+	}
+	return strings.Join(k[i], "/")
+}
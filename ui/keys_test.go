@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyBindings(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		custom map[string][]string
+		want   map[string]string
+		err    string
+	}{
+		{"defaults", nil, map[string]string{"quit": "q", "copy": "c", "closeTab": "x", "export": "X"}, ""},
+		{"example", map[string][]string{"quit": {"q", "ctrl+q"}, "copy": {"c", "ctrl+y"}}, map[string]string{"quit": "q,ctrl+q", "copy": "c,ctrl+y", "copyRendered": "y"}, ""},
+		{"remapped", map[string][]string{"lineNumbers": {"L"}}, map[string]string{"lineNumbers": "L"}, ""},
+		{"case of the action", map[string][]string{"linenumbers": {"L"}}, map[string]string{"lineNumbers": "L"}, ""},
+		{"disabled", map[string][]string{"finder": {}}, map[string]string{"finder": ""}, ""},
+		{"taken by a default", map[string][]string{"copy": {"y"}}, nil, `key "y" is bound to both copy and copyRendered`},
+		{"taken by another key", map[string][]string{"quit": {"q", "x"}}, nil, `key "x" is bound to both quit and closeTab`},
+		{"swapped", map[string][]string{"open": {"t"}, "newTab": {"enter"}}, map[string]string{"open": "t", "newTab": "enter"}, ""},
+		{"unknown action", map[string][]string{"fly": {"f"}}, nil, `unknown key action "fly"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bindings, err := KeyBindings(tc.custom)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			for _, b := range bindings {
+				got[b.Action] = strings.Join(b.Keys, ",")
+			}
+			for action, keys := range tc.want {
+				if got[action] != keys {
+					t.Errorf("expected %s bound to %q, got %q", action, keys, got[action])
+				}
+			}
+		})
+	}
+}
+
+func TestKeyMapTranslate(t *testing.T) {
+	k := newKeyMap(map[string][]string{"quit": {"ctrl+q"}, "lineNumbers": {"L", "l"}})
+	for _, tc := range []struct {
+		key   tea.KeyMsg
+		state state
+		want  string
+		ok    bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlQ}, stateShowDocument, "q", true},
+		{runeKey('q'), stateShowDocument, "q", false},
+		{runeKey('L'), stateShowDocument, "l", true},
+		{runeKey('l'), stateShowDocument, "l", true},
+		{runeKey('c'), stateShowDocument, "c", true},
+		{runeKey('g'), stateShowDocument, "g", true},
+		{runeKey('L'), stateShowStash, "L", true},
+	} {
+		got, ok := k.translate(tc.key, tc.state)
+		if got.String() != tc.want || ok != tc.ok {
+			t.Errorf("%s in state %d: expected %q, %v, got %q, %v", tc.key, tc.state, tc.want, tc.ok, got, ok)
+		}
+	}
+}
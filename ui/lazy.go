@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/chunks"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/dustin/go-humanize"
+)
+
+// lazyRenderSize is the size above which documents are rendered a part at a
+// time, as the reader scrolls down to them.
+const lazyRenderSize = 16 * chunks.Size
+
+// checkSize returns an error if a file is larger than the largest document
+// that's opened.
+func checkSize(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || config.MaxSize <= 0 || info.Size() <= config.MaxSize {
+		return nil //nolint:nilerr
+	}
+	return fmt.Errorf("%s is larger than --max-size (%s)", path, humanize.Bytes(uint64(config.MaxSize))) //nolint:gosec
+}
+
+// renderLazily reports whether a document is rendered a part at a time.
+// Documents shown with line numbers, and those to be scrolled to a heading
+// or match right away, are rendered whole.
+func (m pagerModel) renderLazily(md string) bool {
+	doc := m.currentDocument
+	return len(md) > lazyRenderSize &&
+		config.GlamourEnabled &&
+		utils.IsMarkdownFile(m.documentName()) &&
+		!m.common.cfg.ShowLineNumbers &&
+		doc.anchor == "" && doc.searchTerm == ""
+}
+
+// renderFirstPart renders the start of a large document, leaving the rest to
+// be rendered as it's scrolled to.
+func renderFirstPart(m pagerModel, md string) tea.Msg {
+	start := time.Now()
+	render, err := markdownRenderer(m, false)
+	if err != nil {
+		return errMsg{err}
+	}
+	more, err := chunks.NewRenderer(md, chunks.Size, render)
+	if err != nil {
+		return errMsg{err}
+	}
+	s, err := more.Next()
+	if err != nil {
+		log.Error("error rendering with Glamour", "error", err)
+		return errMsg{err}
+	}
+	log.Debug("render timings", "document", m.documentName(), "render", time.Since(start), "lazy", true)
+	if more.Done() {
+		more = nil
+	}
+	return contentRenderedMsg{tab: m.tab, id: m.renders, content: s, more: more, stats: documentStats(m, md)}
+}
+
+// renderMore renders the next part of a large document once the reader
+// comes within a screen of the end of what's rendered.
+func (m *pagerModel) renderMore() tea.Cmd {
+	if m.more == nil || m.renderingMore || m.viewport.YOffset+2*m.viewport.Height < m.viewport.TotalLineCount() {
+		return nil
+	}
+	m.renderingMore = true
+	more, tab, id := m.more, m.tab, m.renders
+	return func() tea.Msg {
+		s, err := more.Next()
+		if err != nil {
+			log.Error("error rendering with Glamour", "error", err)
+			return errMsg{err}
+		}
+		return moreRenderedMsg{tab: tab, id: id, content: s}
+	}
+}
+
+// appendRendered adds the next part of a large document to what's shown.
+func (m *pagerModel) appendRendered(s string) {
+	m.rendered += s
+	m.renderingMore = false
+	if m.more.Done() {
+		m.more = nil
+	}
+	m.showAnnotations()
+	if m.outline.shown {
+		m.setOutline()
+	}
+}
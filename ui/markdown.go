@@ -18,11 +18,39 @@ type markdown struct {
 	// those that have been stashed in this session.
 	localPath string
 
+	// Path of a file of the remote repository browsed, see Config.Repo.
+	remotePath string
+
 	// Value we filter against. This exists so that we can maintain positions
 	// of filtered items if notes are edited while a filter is active. This
 	// field is ephemeral, and should only be referenced during filtering.
 	filterValue string
 
+	// Text to scroll to once the document is rendered, if it was opened from
+	// a full-text search.
+	searchTerm string
+
+	// Heading this entry points to, for bookmarked headings.
+	heading string
+
+	// Anchor of the heading to scroll to once the document is rendered.
+	anchor string
+
+	// ID of the document in the stash, for stashed documents.
+	stashID string
+
+	// Whether the document was pasted into the TUI and hasn't been saved.
+	pasted bool
+
+	// Whether the file of the document is encrypted, as documents of the
+	// stash can be.
+	encrypted bool
+
+	// Tag this entry of the tags section stands for, and how many documents
+	// carry it.
+	tag    string
+	tagged int
+
 	Body    string
 	Note    string
 	Modtime time.Time
@@ -40,6 +68,10 @@ func (m *markdown) buildFilterValue() {
 }
 
 func (m markdown) relativeTime() string {
+	if m.Modtime.IsZero() {
+		// Files of remote repositories
+		return ""
+	}
 	return relativeTime(m.Modtime)
 }
 
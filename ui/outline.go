@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+const maxOutlineWidth = 30
+
+var (
+	outlineStyle         = lipgloss.NewStyle().Foreground(brightGray).Render
+	outlineCurrentStyle  = lipgloss.NewStyle().Foreground(dullFuchsia).Render
+	outlineSelectedStyle = lipgloss.NewStyle().Foreground(fuchsia).Bold(true).Render
+)
+
+// outlineState is the state of the outline of the current document's
+// headings, shown next to it.
+type outlineState struct {
+	// Whether the outline is shown, and whether it takes the keys to move
+	// between headings.
+	shown, focused bool
+	cursor         int
+
+	// Headings of the current document.
+	headings []docHeading
+}
+
+// outlineWidth returns the width of the outline on a screen w columns wide.
+func outlineWidth(w int) int {
+	return min(maxOutlineWidth, w/3)
+}
+
+// outlineFocused reports whether the pager takes the keys to move between
+// headings.
+func (m pagerModel) outlineFocused() bool {
+	return m.outline.shown && m.outline.focused
+}
+
+// toggleOutline shows the outline and moves the focus to it, or hides it if
+// it has the focus already.
+func (m *pagerModel) toggleOutline() tea.Cmd {
+	o := &m.outline
+	switch {
+	case o.shown && !o.focused:
+		o.focused = true
+		o.cursor = m.currentHeadingIndex()
+		return nil
+	case o.shown:
+		o.shown, o.focused = false, false
+		m.viewport.HighPerformanceRendering = m.highPerformance()
+	default:
+		o.shown, o.focused = true, true
+		m.split = false
+		o.headings = documentHeadings(m.shownBody(), m.rendered)
+		o.cursor = m.currentHeadingIndex()
+	}
+	m.setSize(m.common.width, m.common.height)
+
+	var cmds []tea.Cmd
+	if o.shown {
+		// The high performance renderer can only draw the viewport across
+		// the whole screen.
+		if m.viewport.HighPerformanceRendering {
+			cmds = append(cmds, tea.ClearScrollArea) //nolint:staticcheck
+		}
+		m.viewport.HighPerformanceRendering = false
+	}
+	return tea.Batch(append(cmds, m.render())...)
+}
+
+// setOutline updates the outline for a new rendering of the document.
+func (m *pagerModel) setOutline() {
+	o := &m.outline
+	o.headings = documentHeadings(m.shownBody(), m.rendered)
+	o.cursor = max(0, min(o.cursor, len(o.headings)-1))
+}
+
+// currentHeadingIndex returns the index of the last heading at or above the
+// top of the viewport.
+func (m pagerModel) currentHeadingIndex() int {
+	current := 0
+	for i, h := range m.outline.headings {
+		if h.rendered > m.viewport.YOffset {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// updateOutline moves between headings while the outline has the focus,
+// scrolling the document to the selected one. It reports whether it handled
+// the key.
+func (m *pagerModel) updateOutline(msg tea.KeyMsg) (tea.Cmd, bool) {
+	o := &m.outline
+	switch msg.String() {
+	case "up", "k":
+		o.cursor--
+	case "down", "j":
+		o.cursor++
+	case "home", "g":
+		o.cursor = 0
+	case "end", "G":
+		o.cursor = len(o.headings) - 1
+	case keyEnter:
+		o.focused = false
+		return nil, true
+	case keyEsc:
+		return m.toggleOutline(), true
+	default:
+		return nil, false
+	}
+
+	o.cursor = max(0, min(o.cursor, len(o.headings)-1))
+	if len(o.headings) > 0 {
+		m.viewport.SetYOffset(o.headings[o.cursor].rendered)
+	}
+	return nil, true
+}
+
+// outlineView draws the outline, scrolled to keep the selected heading in
+// view. Without the focus, the heading at the top of the viewport is
+// highlighted.
+func (m pagerModel) outlineView() string {
+	o := m.outline
+	w, h := outlineWidth(m.common.width), m.viewport.Height
+	if len(o.headings) == 0 {
+		return lipgloss.NewStyle().Width(w).Height(h).Render(" " + grayFg("No headings"))
+	}
+
+	current := o.cursor
+	if !o.focused {
+		current = m.currentHeadingIndex()
+	}
+	top := max(0, min(current-h/2, len(o.headings)-h))
+
+	lines := make([]string, 0, h)
+	for i := top; i < len(o.headings) && len(lines) < h; i++ {
+		hd := o.headings[i]
+		indent := strings.Repeat(" ", min(hd.level-1, 5)*2)
+		s := truncate.StringWithTail(" "+indent+hd.text, uint(max(0, w-1)), ellipsis) //nolint:gosec
+		switch {
+		case i == current && o.focused:
+			s = outlineSelectedStyle(s)
+		case i == current:
+			s = outlineCurrentStyle(s)
+		default:
+			s = outlineStyle(s)
+		}
+		lines = append(lines, s)
+	}
+	return lipgloss.NewStyle().Width(w).Height(h).Render(strings.Join(lines, "\n"))
+}
@@ -1,29 +1,40 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/chunks"
+	"github.com/douglas-larocca/glow/v2/footnotes"
+	"github.com/douglas-larocca/glow/v2/images"
+	"github.com/douglas-larocca/glow/v2/links"
+	"github.com/douglas-larocca/glow/v2/passthrough"
+	"github.com/douglas-larocca/glow/v2/stats"
+	"github.com/douglas-larocca/glow/v2/tables"
+	"github.com/douglas-larocca/glow/v2/utils"
 	"github.com/fsnotify/fsnotify"
-	runewidth "github.com/mattn/go-runewidth"
-	"github.com/muesli/reflow/ansi"
 	"github.com/muesli/reflow/truncate"
-	"github.com/muesli/termenv"
 )
 
 const (
 	statusBarHeight = 1
 	lineNumberWidth = 4
+
+	// How long the window has to keep its size before the document is
+	// rendered again at the new width.
+	resizeDebounce = 150 * time.Millisecond
 )
 
 var (
@@ -78,8 +89,27 @@ var (
 )
 
 type (
-	contentRenderedMsg string
-	reloadMsg          struct{}
+	// Messages about a document carry the tab it's open in, so that those
+	// meant for a tab that's no longer current can be dropped.
+	contentRenderedMsg struct {
+		tab     int
+		id      int
+		content string
+		// Renders the rest of a large document, if it's rendered lazily.
+		more *chunks.Renderer
+		// Counts of the document, if they're shown.
+		stats string
+	}
+	// The next part of a document rendered lazily.
+	moreRenderedMsg struct {
+		tab     int
+		id      int
+		content string
+	}
+	reloadMsg struct{ tab int }
+	// Sent once the window stopped changing size, unless it was resized
+	// again since.
+	resizeRenderMsg struct{ tab, id int }
 )
 
 type pagerState int
@@ -102,6 +132,61 @@ type pagerModel struct {
 	// it here so we can re-render it on resize.
 	currentDocument markdown
 
+	// Rendered output for the current document.
+	rendered string
+
+	// Counts of the current document shown in the status bar, if any.
+	stats string
+
+	// The rest of a large document rendered lazily, and whether its next
+	// part is being rendered.
+	more          *chunks.Renderer
+	renderingMore bool
+
+	// Whether the reading position of the current document was restored.
+	positionRestored bool
+
+	// Number of renders started so far, so that renders overtaken by a later
+	// one can be dropped.
+	renders int
+
+	// Number of resizes so far, and where the reader was when the document
+	// was last rendered again for a new width.
+	resizes int
+	reflow  *reflowPosition
+
+	// Links of the current document, and the digits of a link number typed
+	// so far.
+	links      []links.Link
+	linkNumber string
+
+	// Footnotes of the current document, and whether those of the markers
+	// on screen are shown.
+	notes         []footnotes.Note
+	showFootnotes bool
+
+	// Documents the current one was opened from by following their links,
+	// and those gone back from, the last ones first to go to.
+	back    []visit
+	forward []visit
+
+	// Whether the source of the document is shown next to it, and where
+	// headings are in both.
+	split   bool
+	source  viewport.Model
+	anchors []anchor
+
+	// The tab the pager is shown in, and whether a tab bar is shown above.
+	tab    int
+	tabbed bool
+
+	annotation annotationState
+	outline    outlineState
+	tasks      taskState
+	save       saveState
+	export     exportState
+	fold       foldState
+
 	watcher *fsnotify.Watcher
 }
 
@@ -115,6 +200,8 @@ func newPagerModel(common *commonModel) pagerModel {
 		common:   common,
 		state:    pagerStateBrowse,
 		viewport: vp,
+		source:   viewport.New(0, 0),
+		fold:     foldState{focus: -1},
 	}
 	m.initWatcher()
 	return m
@@ -123,6 +210,9 @@ func newPagerModel(common *commonModel) pagerModel {
 func (m *pagerModel) setSize(w, h int) {
 	m.viewport.Width = w
 	m.viewport.Height = h - statusBarHeight
+	if m.tabbed {
+		m.viewport.Height -= tabBarHeight
+	}
 
 	if m.showHelp {
 		if pagerHelpHeight == 0 {
@@ -130,6 +220,15 @@ func (m *pagerModel) setSize(w, h int) {
 		}
 		m.viewport.Height -= (statusBarHeight + pagerHelpHeight)
 	}
+
+	if m.split {
+		m.source.Width = w / 2
+		m.source.Height = m.viewport.Height
+		m.viewport.Width = w - m.source.Width - splitBorderWidth
+	}
+	if m.outline.shown {
+		m.viewport.Width = w - outlineWidth(w) - splitBorderWidth
+	}
 }
 
 func (m *pagerModel) setContent(s string) {
@@ -166,6 +265,18 @@ func (m *pagerModel) showStatusMessage(msg pagerStatusMessage) tea.Cmd {
 
 func (m *pagerModel) unload() {
 	log.Debug("unload")
+	m.savePosition()
+	m.positionRestored = false
+	m.links = nil
+	m.linkNumber = ""
+	m.notes, m.showFootnotes = nil, false
+	m.annotation = annotationState{}
+	m.tasks = taskState{}
+	m.save = saveState{}
+	m.export = exportState{}
+	m.fold = foldState{focus: -1}
+	m.outline.headings = nil
+	m.outline.cursor = 0
 	if m.showHelp {
 		m.toggleHelp()
 	}
@@ -174,7 +285,14 @@ func (m *pagerModel) unload() {
 	}
 	m.state = pagerStateBrowse
 	m.viewport.SetContent("")
+	m.rendered = ""
+	m.more, m.renderingMore = nil, false
+	m.stats = ""
+	m.source.SetContent("")
+	m.anchors = nil
+	m.reflow = nil
 	m.viewport.YOffset = 0
+	m.back, m.forward = nil, nil
 	m.unwatchFile()
 }
 
@@ -186,6 +304,10 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if cmd, ok := m.handleLinkKey(msg); ok {
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "q", keyEsc:
 			if m.state != pagerStateBrowse {
@@ -216,6 +338,12 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			}
 
 		case "e":
+			if m.common.cfg.Remote {
+				return m, m.showStatusMessage(pagerStatusMessage{"Can’t edit remotely", true})
+			}
+			if m.currentDocument.localPath == "" || m.currentDocument.encrypted {
+				return m, m.showStatusMessage(pagerStatusMessage{"Can’t edit this document", true})
+			}
 			lineno := int(math.RoundToEven(float64(m.viewport.TotalLineCount()) * m.viewport.ScrollPercent()))
 			if m.viewport.AtTop() {
 				lineno = 0
@@ -228,15 +356,58 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			return m, openEditor(m.currentDocument.localPath, lineno)
 
 		case "c":
-			// Copy using OSC 52
-			termenv.Copy(m.currentDocument.Body)
-			// Copy using native system clipboard
-			_ = clipboard.WriteAll(m.currentDocument.Body)
-			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Copied contents", false}))
+			cmds = append(cmds, m.copyToClipboard(m.currentDocument.Body, "Copied contents"))
+
+		case "y":
+			cmds = append(cmds, m.copyToClipboard(utils.PlainText(m.rendered), "Copied rendered text"))
+
+		case "l":
+			m.common.cfg.ShowLineNumbers = !m.common.cfg.ShowLineNumbers
+			return m, m.render()
 
 		case "r":
+			if m.currentDocument.localPath == "" && m.currentDocument.remotePath == "" {
+				// Piped or pasted, so there's nothing to read again.
+				return m, nil
+			}
 			return m, loadLocalMarkdown(&m.currentDocument)
 
+		case "v":
+			return m, m.toggleSplit()
+
+		case "b":
+			// Handled here so the viewport doesn't also page up.
+			return m, m.toggleBookmark()
+
+		case "s":
+			cmds = append(cmds, m.stashDocument())
+
+		case "w":
+			return m, m.startSaving()
+
+		case "X":
+			return m, m.startExporting()
+
+		case "a":
+			return m, m.startAnnotating()
+
+		case "o":
+			return m, m.toggleOutline()
+
+		case "t":
+			return m, m.startTasks()
+
+		case "^":
+			return m, m.toggleFootnotes()
+
+		case "backspace", "]":
+			if m.state == pagerStateBrowse {
+				if msg.String() == "]" {
+					return m, m.goForward()
+				}
+				return m, m.goBack()
+			}
+
 		case "?":
 			m.toggleHelp()
 			if m.viewport.HighPerformanceRendering {
@@ -246,14 +417,61 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 
 	// Glow has rendered the content
 	case contentRenderedMsg:
+		if msg.id != m.renders {
+			// Rendered for a width or document that changed since.
+			return m, nil
+		}
 		log.Info("content rendered", "state", m.state)
 
-		m.setContent(string(msg))
+		m.rendered = msg.content
+		m.more, m.renderingMore = msg.more, false
+		m.stats = msg.stats
+		if m.tasks.selecting {
+			m.setTasks()
+		}
+		m.showAnnotations()
+		if anchor := m.currentDocument.anchor; anchor != "" {
+			// Opened at a heading, as in README.md#installation.
+			m.currentDocument.anchor = ""
+			if line := anchorLine(m.shownBody(), msg.content, anchor); line >= 0 {
+				m.viewport.SetYOffset(line)
+			}
+		} else if m.scrollToFold() {
+			// A section was folded or unfolded, so stay at its heading.
+			m.reflow = nil
+		} else if m.reflow != nil {
+			// Rendered again for a new width, so stay where the reader was.
+			m.restoreReflow()
+		} else if term := m.currentDocument.searchTerm; term != "" {
+			// Opened from a full-text search, so jump to the first match.
+			m.currentDocument.searchTerm = ""
+			if line := findRenderedLine(msg.content, term); line >= 0 {
+				m.viewport.SetYOffset(line)
+			}
+		} else if !m.positionRestored {
+			m.restorePosition()
+		}
+		m.positionRestored = true
+		if m.split {
+			m.setSource()
+		}
+		if m.outline.shown {
+			m.setOutline()
+		}
 		if m.viewport.HighPerformanceRendering {
 			cmds = append(cmds, viewport.Sync(m.viewport))
 		}
 		cmds = append(cmds, m.watchFile)
 
+	case moreRenderedMsg:
+		if msg.id != m.renders {
+			return m, nil
+		}
+		m.appendRendered(msg.content)
+		if m.viewport.HighPerformanceRendering {
+			cmds = append(cmds, viewport.Sync(m.viewport))
+		}
+
 	// The file was changed on disk and we're reloading it
 	case reloadMsg:
 		return m, loadLocalMarkdown(&m.currentDocument)
@@ -262,26 +480,123 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 	// retrieve the latest version of the document so that we display
 	// up-to-date contents.
 	case editorFinishedMsg:
+		if msg.err != nil {
+			log.Error("unable to open editor", "error", msg.err)
+			return m, m.showStatusMessage(pagerStatusMessage{"Couldn’t open editor", true})
+		}
 		return m, loadLocalMarkdown(&m.currentDocument)
 
+	case browserFinishedMsg:
+		if msg.err != nil {
+			log.Error("unable to open link", "error", msg.err)
+			return m, m.showStatusMessage(pagerStatusMessage{"Couldn’t open link", true})
+		}
+
 	// We've received terminal dimensions, either for the first time or
 	// after a resize
 	case tea.WindowSizeMsg:
-		return m, renderWithGlamour(m, m.currentDocument.Body)
+		if m.rendered == "" {
+			return m, m.render()
+		}
+		// Wait for the window to stop changing size before rendering again.
+		m.resizes++
+		tab, id := m.tab, m.resizes
+		return m, tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+			return resizeRenderMsg{tab, id}
+		})
+
+	case resizeRenderMsg:
+		if msg.id != m.resizes {
+			return m, nil
+		}
+		m.reflow = m.readingPosition()
+		return m, m.render()
 
 	case statusMessageTimeoutMsg:
 		m.state = pagerStateBrowse
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
-	cmds = append(cmds, cmd)
+	cmds = append(cmds, cmd, m.renderMore())
+	m.syncSource()
 
 	return m, tea.Batch(cmds...)
 }
 
+// handleLinkKey opens links by number. Digits are collected until the number
+// can't be extended by another one or enter is pressed.
+func (m *pagerModel) handleLinkKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if len(m.links) == 0 {
+		return nil, false
+	}
+	key := msg.String()
+	if key == keyEnter && m.linkNumber != "" {
+		return m.openLink(), true
+	}
+	if len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		m.linkNumber = ""
+		return nil, false
+	}
+	if m.linkNumber == "" && key == "0" {
+		return nil, true
+	}
+
+	m.linkNumber += key
+	n, _ := strconv.Atoi(m.linkNumber)
+	if n*10 > len(m.links) {
+		return m.openLink(), true
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Link %d… (enter to open)", n), false}), true
+}
+
+// openLink opens the link with the number typed so far.
+func (m *pagerModel) openLink() tea.Cmd {
+	n, _ := strconv.Atoi(m.linkNumber)
+	m.linkNumber = ""
+	if n < 1 || n > len(m.links) {
+		return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("No link %d", n), true})
+	}
+	link := m.links[n-1]
+	if m.common.cfg.Remote {
+		return m.showStatusMessage(pagerStatusMessage{link.URL, false})
+	}
+	log.Info("opening link", "number", n, "url", link.URL)
+	if p := m.currentDocument.remotePath; p != "" {
+		return openBrowser(resolveRepoLink(p, link.URL))
+	}
+	if path, anchor, ok := m.linkedDocument(link.URL); ok {
+		return m.followLink(path, anchor)
+	}
+	return openBrowser(resolveLink(m.currentDocument.localPath, link.URL))
+}
+
+// copyToClipboard copies s to the clipboard and reports it in the status bar.
+func (m *pagerModel) copyToClipboard(s, msg string) tea.Cmd {
+	if m.common.cfg.Remote {
+		return m.showStatusMessage(pagerStatusMessage{"Can’t copy remotely", true})
+	}
+	if err := utils.CopyToClipboard(s); err != nil {
+		log.Error("unable to copy", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t copy to clipboard", true})
+	}
+	return m.showStatusMessage(pagerStatusMessage{msg, false})
+}
+
 func (m pagerModel) View() string {
 	var b strings.Builder
-	fmt.Fprint(&b, m.viewport.View()+"\n")
+	view := m.viewport.View()
+	if m.showFootnotes {
+		view = m.footnotesView(view)
+	}
+	if m.split {
+		border := strings.TrimSuffix(strings.Repeat(splitBorderStyle("│")+"\n", m.viewport.Height), "\n")
+		fmt.Fprint(&b, lipgloss.JoinHorizontal(lipgloss.Top, m.source.View(), border, view)+"\n")
+	} else if m.outline.shown {
+		border := strings.TrimSuffix(strings.Repeat(splitBorderStyle("│")+"\n", m.viewport.Height), "\n")
+		fmt.Fprint(&b, lipgloss.JoinHorizontal(lipgloss.Top, m.outlineView(), border, view)+"\n")
+	} else {
+		fmt.Fprint(&b, view+"\n")
+	}
 
 	// Footer
 	m.statusBarView(&b)
@@ -326,14 +641,25 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 	var note string
 	if showStatusMessage {
 		note = m.statusMessage
+	} else if m.annotating() {
+		note = m.annotationStatus()
+	} else if m.selectingTasks() {
+		note = m.taskStatus()
+	} else if m.saving() {
+		note = m.save.input.View()
+	} else if m.exporting() {
+		note = m.export.input.View()
 	} else {
-		note = m.currentDocument.Note
+		note = m.breadcrumb()
+		if m.stats != "" {
+			note = strings.TrimPrefix(note+" · "+m.stats, " · ")
+		}
 	}
 	note = truncate.StringWithTail(" "+note+" ", uint(max(0, //nolint:gosec
 		m.common.width-
-			ansi.PrintableRuneWidth(logo)-
-			ansi.PrintableRuneWidth(scrollPercent)-
-			ansi.PrintableRuneWidth(helpNote),
+			utils.Width(logo)-
+			utils.Width(scrollPercent)-
+			utils.Width(helpNote),
 	)), ellipsis)
 	if showStatusMessage {
 		note = statusBarMessageStyle(note)
@@ -344,10 +670,10 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 	// Empty space
 	padding := max(0,
 		m.common.width-
-			ansi.PrintableRuneWidth(logo)-
-			ansi.PrintableRuneWidth(note)-
-			ansi.PrintableRuneWidth(scrollPercent)-
-			ansi.PrintableRuneWidth(helpNote),
+			utils.Width(logo)-
+			utils.Width(note)-
+			utils.Width(scrollPercent)-
+			utils.Width(helpNote),
 	)
 	emptySpace := strings.Repeat(" ", padding)
 	if showStatusMessage {
@@ -365,27 +691,64 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 	)
 }
 
+// keyHelp returns a help line for an action with configurable keys.
+func (m pagerModel) keyHelp(action, desc string) string {
+	keys := m.common.keys.help(action)
+	return keys + strings.Repeat(" ", max(1, 8-utils.Width(keys))) + desc
+}
+
 func (m pagerModel) helpView() (s string) {
+	col0 := []string{
+		"k/↑      up",
+		"j/↓      down",
+		"pgup     page up",
+		"f/pgdn   page down",
+		"u        ½ page up",
+		"d        ½ page down",
+	}
 	col1 := []string{
 		"g/home  go to top",
 		"G/end   go to bottom",
-		"c       copy contents",
-		"e       edit this document",
+		m.keyHelp("copy", "copy contents"),
+		m.keyHelp("copyRendered", "copy rendered text"),
+		m.keyHelp("lineNumbers", "toggle line numbers"),
+		m.keyHelp("edit", "edit this document"),
+		m.keyHelp("annotate", "annotate lines"),
+		m.keyHelp("tasks", "tick off tasks"),
 		"r       reload this document",
+		m.keyHelp("split", "show source side by side"),
+		m.keyHelp("outline", "outline of headings"),
+		m.keyHelp("fold", "+a fold section (o/c)"),
+		m.keyHelp("fold", "+M/R fold/unfold all"),
+		m.keyHelp("nextTab", "next tab"),
+		m.keyHelp("prevTab", "previous tab"),
+		m.keyHelp("closeTab", "close tab"),
+		m.keyHelp("finder", "find a document"),
+		"b       bookmark heading",
+		"s       stash this document",
+		"w       save pasted document",
+		m.keyHelp("export", "export as HTML, text or ANSI"),
+		"1-9     open numbered link",
+		"^       show footnotes",
+		m.keyHelp("back", "back to the linking document"),
+		m.keyHelp("forward", "forward again"),
 		"esc     back to files",
-		"q       quit",
+		m.keyHelp("quit", "quit"),
 	}
 
 	s += "\n"
-	s += "k/↑      up                  " + col1[0] + "\n"
-	s += "j/↓      down                " + col1[1] + "\n"
-	s += "b/pgup   page up             " + col1[2] + "\n"
-	s += "f/pgdn   page down           " + col1[3] + "\n"
-	s += "u        ½ page up           " + col1[4] + "\n"
-	s += "d        ½ page down         "
-
-	if len(col1) > 5 {
-		s += col1[5]
+	for i := range max(len(col0), len(col1)) {
+		var left, right string
+		if i < len(col0) {
+			left = col0[i]
+		}
+		if i < len(col1) {
+			right = col1[i]
+		}
+		s += left + strings.Repeat(" ", max(0, 28-utils.Width(left))) + right
+		if i < max(len(col0), len(col1))-1 {
+			s += "\n"
+		}
 	}
 
 	s = indent(s, 2)
@@ -394,7 +757,7 @@ func (m pagerModel) helpView() (s string) {
 	if m.common.width > 0 {
 		lines := strings.Split(s, "\n")
 		for i := 0; i < len(lines); i++ {
-			l := runewidth.StringWidth(lines[i])
+			l := utils.Width(lines[i])
 			n := max(m.common.width-l, 0)
 			lines[i] += strings.Repeat(" ", n)
 		}
@@ -407,14 +770,43 @@ func (m pagerModel) helpView() (s string) {
 
 // COMMANDS
 
+// documentName returns the name the type of the current document is detected
+// from. Notes of bookmarks and stashed documents aren't file names.
+func (m pagerModel) documentName() string {
+	if m.currentDocument.localPath != "" {
+		return m.currentDocument.localPath
+	}
+	return m.currentDocument.Note
+}
+
+// render renders the current document, with its frontmatter and links
+// handled as configured.
+func (m *pagerModel) render() tea.Cmd {
+	if m.common.cfg.Fold && !m.fold.applied {
+		m.foldAll()
+	}
+	m.fold.applied = true
+	m.setFolds()
+	body, found, notes := m.common.documentBody(m.documentName(), []byte(m.shownBody()))
+	m.links = found
+	m.notes = notes
+	m.renders++
+	return renderWithGlamour(*m, body)
+}
+
 func renderWithGlamour(m pagerModel, md string) tea.Cmd {
 	return func() tea.Msg {
+		start := time.Now()
+		if m.renderLazily(md) {
+			return renderFirstPart(m, md)
+		}
 		s, err := glamourRender(m, md)
 		if err != nil {
 			log.Error("error rendering with Glamour", "error", err)
 			return errMsg{err}
 		}
-		return contentRenderedMsg(s)
+		log.Debug("render timings", "document", m.documentName(), "render", time.Since(start))
+		return contentRenderedMsg{tab: m.tab, id: m.renders, content: s, stats: documentStats(m, md)}
 	}
 }
 
@@ -426,32 +818,18 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 		return markdown, nil
 	}
 
-	isCode := !utils.IsMarkdownFile(m.currentDocument.Note)
-	width := max(0, min(int(m.common.cfg.GlamourMaxWidth), m.viewport.Width)) //nolint:gosec
-	if isCode {
-		width = 0
-	}
-
-	options := []glamour.TermRendererOption{
-		utils.GlamourStyle(m.common.cfg.GlamourStyle, isCode),
-		glamour.WithWordWrap(width),
-	}
-
-	if m.common.cfg.PreserveNewLines {
-		options = append(options, glamour.WithPreservedNewLines())
-	}
-	r, err := glamour.NewTermRenderer(options...)
+	name := m.documentName()
+	isCode := !utils.IsMarkdownFile(name)
+	render, err := markdownRenderer(m, isCode)
 	if err != nil {
-		return "", fmt.Errorf("error creating glamour renderer: %w", err)
+		return "", err
 	}
-
 	if isCode {
-		markdown = utils.WrapCodeBlock(markdown, filepath.Ext(m.currentDocument.Note))
+		markdown = utils.WrapCodeBlock(markdown, filepath.Ext(name))
 	}
-
-	out, err := r.Render(markdown)
+	out, err := render(markdown)
 	if err != nil {
-		return "", fmt.Errorf("error rendering markdown: %w", err)
+		return "", err
 	}
 
 	if isCode {
@@ -479,6 +857,80 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 	return content.String(), nil
 }
 
+// documentStats returns the counts of a markdown document shown in the
+// status bar, if they're shown.
+func documentStats(m pagerModel, md string) string {
+	if !m.common.cfg.ShowStats || !utils.IsMarkdownFile(m.documentName()) {
+		return ""
+	}
+	return stats.Count([]byte(md)).String()
+}
+
+// markdownRenderer returns a function rendering markdown, or code wrapped in
+// a code block, for the pager. Markdown is moved right by the margin, or
+// centered.
+func markdownRenderer(m pagerModel, isCode bool) (func(string) (string, error), error) {
+	width := max(0, min(int(m.common.cfg.GlamourMaxWidth), m.viewport.Width)) //nolint:gosec
+	margin := 0
+	switch {
+	case isCode:
+		width = 0
+	case m.common.cfg.Center && width > 0:
+		margin = (m.viewport.Width - width) / 2
+	default:
+		margin = min(int(m.common.cfg.Margin), m.viewport.Width/2) //nolint:gosec
+		width = max(0, min(width, m.viewport.Width-margin))
+	}
+
+	// Options shared with the renderer of callouts
+	var shared []glamour.TermRendererOption
+	if m.common.cfg.PreserveNewLines {
+		shared = append(shared, glamour.WithPreservedNewLines())
+	}
+	options := append([]glamour.TermRendererOption{
+		utils.GlamourStyle(m.common.cfg.GlamourStyle, isCode),
+		glamour.WithWordWrap(width),
+	}, shared...)
+	r, err := glamour.NewTermRenderer(options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating glamour renderer: %w", err)
+	}
+
+	return func(md string) (string, error) {
+		var (
+			out string
+			err error
+		)
+		if isCode {
+			out, err = r.Render(md)
+		} else {
+			out, err = utils.RenderMarkdown(r, md, utils.RenderOptions{
+				Style:      m.common.cfg.GlamourStyle,
+				Width:      width,
+				Tables:     tables.Mode(m.common.cfg.WideTables),
+				RawANSI:    passthrough.Mode(m.common.cfg.RawANSI),
+				CodeThemes: m.common.cfg.CodeThemes,
+				Glamour:    shared,
+				Images:     images.Mode(m.common.cfg.Images),
+				OpenImage:  openImage,
+				ASCII:      !config.TermProfile.Unicode,
+			})
+		}
+		if err != nil {
+			return "", fmt.Errorf("error rendering markdown: %w", err)
+		}
+		return utils.Indent(config.TermProfile.Text(out), margin), nil
+	}, nil
+}
+
+// openImage opens a local image of a document, to show its dimensions.
+func openImage(src string) (io.ReadCloser, error) {
+	if strings.Contains(src, "://") {
+		return nil, errors.New("remote images aren't fetched")
+	}
+	return os.Open(src) //nolint:wrapcheck
+}
+
 func (m *pagerModel) initWatcher() {
 	var err error
 	m.watcher, err = fsnotify.NewWatcher()
@@ -500,7 +952,11 @@ func (m *pagerModel) watchFile() tea.Msg {
 	for {
 		select {
 		case event, ok := <-m.watcher.Events:
-			if !ok || event.Name != m.currentDocument.localPath {
+			if !ok {
+				// The watcher was closed with its tab.
+				return nil
+			}
+			if event.Name != m.currentDocument.localPath {
 				continue
 			}
 
@@ -509,10 +965,10 @@ func (m *pagerModel) watchFile() tea.Msg {
 			}
 
 			log.Debug("fsnotify event", "file", event.Name, "event", event.Op)
-			return reloadMsg{}
+			return reloadMsg{m.tab}
 		case err, ok := <-m.watcher.Errors:
 			if !ok {
-				continue
+				return nil
 			}
 			log.Debug("fsnotify error", "dir", dir, "error", err)
 		}
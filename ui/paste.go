@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// pastedNote is what the status bar shows for documents pasted into the TUI
+// until they're saved.
+const pastedNote = "Pasted markdown"
+
+// saveState is the state of saving a pasted document to a file.
+type saveState struct {
+	// Whether the name of the file is being written.
+	writing bool
+	input   textinput.Model
+}
+
+func newFileInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Save as:"
+	ti.PromptStyle = stashInputPromptStyle
+	ti.Cursor.Style = stashInputCursorStyle
+	ti.Placeholder = "pasted.md"
+	ti.CharLimit = 4096
+	return ti
+}
+
+// pastedText returns the text of a paste. Terminals supporting bracketed
+// paste send it as a single key message.
+func pastedText(msg tea.Msg) (string, bool) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !key.Paste {
+		return "", false
+	}
+	s := strings.ReplaceAll(string(key.Runes), "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n"), true
+}
+
+// typing reports whether keys, and so pastes, go to a text input.
+func (m model) typing() bool {
+	switch {
+	case m.finder.open:
+		return true
+	case m.state == stateShowStash:
+		return m.stash.filterState == filtering || m.stash.unlocking()
+	}
+	return m.pager.annotating() || m.pager.selectingTasks() || m.pager.saving() || m.pager.exporting()
+}
+
+// openPasted shows markdown pasted into the TUI as a document of its own,
+// in place of the document shown, if any.
+func (m *model) openPasted(text string) tea.Cmd {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	if m.state == stateShowDocument {
+		m.pager.unload()
+	} else {
+		m.stash.viewState = stashStateLoadingDocument
+	}
+	m.pager.currentDocument = markdown{
+		Body:    text,
+		Note:    pastedNote,
+		Modtime: time.Now(),
+		pasted:  true,
+	}
+	return tea.Batch(m.pager.render(), m.stash.spinner.Tick)
+}
+
+// saving reports whether the pager takes all keys to name the file a
+// pasted document is saved to.
+func (m pagerModel) saving() bool {
+	return m.save.writing
+}
+
+// startSaving asks for the name of the file to save a pasted document to.
+func (m *pagerModel) startSaving() tea.Cmd {
+	switch {
+	case m.common.cfg.Remote:
+		return m.showStatusMessage(pagerStatusMessage{"Can’t save remotely", true})
+	case !m.currentDocument.pasted:
+		return m.showStatusMessage(pagerStatusMessage{"Only pasted documents can be saved", true})
+	}
+	m.state = pagerStateBrowse
+	m.save = saveState{writing: true, input: newFileInput()}
+	return m.save.input.Focus()
+}
+
+// updateSaving handles keys while the name of the file is written.
+func (m *pagerModel) updateSaving(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		m.save = saveState{}
+		return nil
+	case keyEnter:
+		name := strings.TrimSpace(m.save.input.Value())
+		if name == "" {
+			name = m.save.input.Placeholder
+		}
+		m.save = saveState{}
+		return m.saveToFile(name)
+	}
+	var cmd tea.Cmd
+	m.save.input, cmd = m.save.input.Update(msg)
+	return cmd
+}
+
+// saveToFile writes a pasted document to a new file, relative to the
+// directory of the file listing, which the document is then read from.
+func (m *pagerModel) saveToFile(name string) tea.Cmd {
+	name, cwd := m.resolveFileName(name)
+	err := writeNewFile(name, m.currentDocument.Body)
+	if errors.Is(err, fs.ErrExist) {
+		return m.showStatusMessage(pagerStatusMessage{"File already exists", true})
+	}
+	if err != nil {
+		log.Error("unable to save pasted document", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t save document", true})
+	}
+
+	doc := &m.currentDocument
+	doc.localPath = name
+	doc.Note = stripAbsolutePath(name, cwd)
+	doc.pasted = false
+	m.common.addToHistory(doc)
+	return m.showStatusMessage(pagerStatusMessage{"Saved " + doc.Note, false})
+}
+
+// resolveFileName returns the full path of a file named in the TUI, relative
+// to the directory of the file listing, and that directory.
+func (m pagerModel) resolveFileName(name string) (string, string) {
+	if strings.HasPrefix(name, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			name = filepath.Join(home, name[2:])
+		}
+	}
+	cwd := m.common.cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(cwd, name)
+	}
+	return name, cwd
+}
+
+// writeNewFile writes content to a file that doesn't exist yet.
+func writeNewFile(name, content string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644) //nolint:gosec
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	_, err = f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err //nolint:wrapcheck
+}
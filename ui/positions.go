@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/positions"
+)
+
+// loadPositions opens the reading position store configured in cfg.
+// Positions aren't restored if it can't be read.
+func loadPositions(cfg Config) *positions.Store {
+	if cfg.PositionsFile == "" {
+		return nil
+	}
+	s, err := positions.Load(cfg.PositionsFile)
+	if err != nil {
+		log.Error("unable to load reading positions", "error", err)
+		return nil
+	}
+	return s
+}
+
+// restorePosition scrolls to where the current document was last left.
+func (m *pagerModel) restorePosition() {
+	path := m.currentDocument.localPath
+	if m.common.positions == nil || path == "" {
+		return
+	}
+	if p, ok := m.common.positions.Get(path); ok {
+		m.viewport.SetYOffset(p.LineIn(m.viewport.TotalLineCount()))
+	}
+}
+
+// savePosition remembers how far the current document was read.
+func (m *pagerModel) savePosition() {
+	path := m.currentDocument.localPath
+	if m.common.positions == nil || path == "" || m.rendered == "" {
+		return
+	}
+	if err := m.common.positions.Set(path, m.viewport.YOffset, m.viewport.TotalLineCount()); err != nil {
+		log.Error("unable to save reading position", "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+// reflowPosition is where the reader is in a document, kept while the
+// document is rendered again at another width.
+type reflowPosition struct {
+	// Line of the source at the top of the viewport.
+	source int
+	// Whether the viewport was scrolled to the bottom.
+	bottom bool
+}
+
+// readingPosition returns where the reader is in the current document, as
+// the line of its source found between the headings around the top of the
+// viewport, so that it can be found again however the document is wrapped.
+func (m pagerModel) readingPosition() *reflowPosition {
+	return &reflowPosition{
+		source: sourceLine(splitAnchors(m.shownBody(), m.rendered), m.viewport.YOffset),
+		bottom: m.viewport.YOffset > 0 && m.viewport.AtBottom(),
+	}
+}
+
+// restoreReflow scrolls the newly rendered document back to where the reader
+// was before it was rendered again.
+func (m *pagerModel) restoreReflow() {
+	p := m.reflow
+	m.reflow = nil
+	if p.bottom {
+		m.viewport.GotoBottom()
+		return
+	}
+	m.viewport.SetYOffset(renderedLine(splitAnchors(m.shownBody(), m.rendered), p.source))
+}
+
+// renderedLine returns the line that line y of the source is rendered on, by
+// interpolating between the anchors around it.
+func renderedLine(anchors []anchor, y int) int {
+	for i := 1; i < len(anchors); i++ {
+		a, b := anchors[i-1], anchors[i]
+		if y >= b.source && i < len(anchors)-1 {
+			continue
+		}
+		if b.source <= a.source {
+			return a.rendered
+		}
+		return a.rendered + (y-a.source)*(b.rendered-a.rendered)/(b.source-a.source)
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/walker"
+)
+
+// Repo is a remote repository, e.g. on GitHub, whose markdown files are
+// browsed in place of local files. Files are only fetched once opened.
+type Repo interface {
+	// Files lists the paths of the files in the repository, separated by
+	// slashes.
+	Files(ctx context.Context) ([]string, error)
+
+	// Fetch returns the content of the file at path.
+	Fetch(ctx context.Context, path string) ([]byte, error)
+
+	// URL returns the address of the web page of the file at path, which
+	// relative links in the file are resolved against.
+	URL(path string) string
+}
+
+// findRepoFiles lists the markdown files of the repository browsed. They're
+// passed along like files found by a local search.
+func findRepoFiles(m commonModel) tea.Cmd {
+	return func() tea.Msg {
+		log.Info("findRepoFiles")
+		ctx, cancel := context.WithCancel(context.Background())
+		paths, err := m.cfg.Repo.Files(ctx)
+		if err != nil {
+			cancel()
+			log.Error("error listing repository files", "error", err)
+			return errMsg{err}
+		}
+
+		var files []walker.Result
+		for _, p := range paths {
+			if isRepoMarkdown(p, m.cfg.ShowAllFiles) {
+				files = append(files, walker.Result{Path: p})
+			}
+		}
+		ch := make(chan walker.Result, len(files))
+		for _, f := range files {
+			ch <- f
+		}
+		close(ch)
+		return initLocalFileSearchMsg{ch: ch, cancel: cancel}
+	}
+}
+
+// isRepoMarkdown reports whether a file of a repository is a markdown file
+// to list. Files in hidden directories are only listed with showAll.
+func isRepoMarkdown(p string, showAll bool) bool {
+	name := strings.ToLower(path.Base(p))
+	if !showAll {
+		for _, dir := range strings.Split(path.Dir(p), "/") {
+			if strings.HasPrefix(dir, ".") && dir != "." {
+				return false
+			}
+		}
+	}
+	for _, pattern := range markdownExtensions {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func repoFileToMarkdown(p string) *markdown {
+	return &markdown{
+		remotePath: p,
+		Note:       p,
+	}
+}
+
+// loadRepoMarkdown fetches the content of a file of the repository browsed.
+func loadRepoMarkdown(md *markdown) tea.Msg {
+	if config.Repo == nil {
+		return errMsg{errors.New("could not load file: no repository")}
+	}
+	data, err := config.Repo.Fetch(context.Background(), md.remotePath)
+	if err != nil {
+		log.Debug("error fetching repository file", "error", err)
+		return errMsg{fmt.Errorf("could not fetch %s: %w", md.remotePath, err)}
+	}
+	md.Body = string(data)
+	return fetchedMarkdownMsg(md)
+}
+
+// resolveRepoLink returns the target of a link found in a file of the
+// repository browsed. Relative links point to the repository's web pages.
+func resolveRepoLink(p, link string) string {
+	if config.Repo == nil {
+		return link
+	}
+	base, err := url.Parse(config.Repo.URL(p))
+	if err != nil {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" {
+		return link
+	}
+	return base.ResolveReference(u).String()
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	// Queries shorter than this only match file names; single letters would
+	// match nearly every document.
+	minContentSearchLength = 2
+
+	// Files larger than this aren't searched.
+	maxContentSearchSize = 1 << 20
+
+	// How long typing has to pause before documents are searched by content,
+	// which reads every file.
+	contentSearchDebounce = 300 * time.Millisecond
+)
+
+// contentMatch is the first line of a document matching a full-text search.
+type contentMatch struct {
+	term    string
+	line    int // 1-based
+	snippet string
+}
+
+// searchContent returns the first line in the file at path which contains
+// term, ignoring case and diacritics.
+func searchContent(path, term string) (contentMatch, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxContentSearchSize {
+		return contentMatch{}, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return contentMatch{}, false
+	}
+	defer f.Close() //nolint:errcheck
+
+	needle := foldText(term)
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 64*1024), maxContentSearchSize)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if strings.Contains(foldText(line), needle) {
+			return contentMatch{
+				term:    term,
+				line:    n,
+				snippet: strings.TrimSpace(line),
+			}, true
+		}
+	}
+	return contentMatch{}, false
+}
+
+// foldText normalizes text for case- and diacritic-insensitive matching.
+func foldText(s string) string {
+	if n, err := normalize(s); err == nil {
+		s = n
+	}
+	return strings.ToLower(s)
+}
+
+// snippetView renders a search snippet, truncated to width, with the search
+// term highlighted.
+func snippetView(m contentMatch, width uint, style func(...string) string, matched lipgloss.Style) string {
+	s := m.snippet
+	needle := foldText(m.term)
+
+	// Folding can change byte offsets, so only highlight when it didn't.
+	folded := foldText(s)
+	if len(folded) != len(s) {
+		return style(truncate.StringWithTail(s, width, ellipsis))
+	}
+	i := strings.Index(folded, needle)
+	if i < 0 {
+		return style(truncate.StringWithTail(s, width, ellipsis))
+	}
+
+	// Make sure the match is visible in long lines by dropping text before
+	// it, keeping a little context.
+	const context = 10
+	if uint(utils.Width(s[:i+len(needle)])) > width && i > context { //nolint:gosec
+		start := i - context
+		for start < i && !utf8.RuneStart(s[start]) {
+			start++
+		}
+		s = ellipsis + s[start:]
+		i = i - start + len(ellipsis)
+	}
+
+	j := i + len(needle)
+	if uint(utils.Width(s[:j])) > width { //nolint:gosec
+		return style(truncate.StringWithTail(s, width, ellipsis))
+	}
+	rest := truncate.StringWithTail(s[j:], width-uint(utils.Width(s[:j])), ellipsis) //nolint:gosec
+	return style(s[:i]) + matched.Render(s[i:j]) + style(rest)
+}
+
+// findRenderedLine returns the index of the first line of rendered output
+// containing term, or -1.
+func findRenderedLine(rendered, term string) int {
+	needle := foldText(term)
+	for i, line := range strings.Split(rendered, "\n") {
+		if strings.Contains(foldText(stripANSI(line)), needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// stripANSI removes terminal escape sequences from s.
+func stripANSI(s string) string {
+	var b strings.Builder
+	inSeq := false
+	for _, r := range s {
+		switch {
+		case r == ansi.Marker:
+			inSeq = true
+		case inSeq:
+			if ansi.IsTerminator(r) {
+				inSeq = false
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
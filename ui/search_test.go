@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+func TestSearchContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(path, []byte("# Title\n\n  Coffee at the Café Noir\nCAFE again\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	large := filepath.Join(dir, "large.md")
+	if err := os.WriteFile(large, []byte("cafe\n"+strings.Repeat("x", maxContentSearchSize)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path, term string
+		want       contentMatch
+		ok         bool
+	}{
+		{path, "cafe", contentMatch{term: "cafe", line: 3, snippet: "Coffee at the Café Noir"}, true},
+		{path, "Title", contentMatch{term: "Title", line: 1, snippet: "# Title"}, true},
+		{path, "tea", contentMatch{}, false},
+		{large, "cafe", contentMatch{}, false},
+		{filepath.Join(dir, "missing.md"), "cafe", contentMatch{}, false},
+	} {
+		got, ok := searchContent(tc.path, tc.term)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s in %s: expected %+v, %v, got %+v, %v", tc.term, filepath.Base(tc.path), tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestSnippetView(t *testing.T) {
+	plain := func(s ...string) string { return strings.Join(s, "") }
+	for _, tc := range []struct {
+		name    string
+		snippet string
+		term    string
+		width   uint
+		want    string
+	}{
+		{"fits", "Install it with go get", "go", 40, "Install it with go get"},
+		{"cut after the match", "needle and some more text", "needle", 10, "needle an…"},
+		{"match far in the line", strings.Repeat("hay ", 20) + "needle", "NEEDLE", 20, "…y hay hay needle"},
+		{"folded", "Ünïcode everywhere", "unicode", 10, "Ünïcode e…"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := stripANSI(snippetView(contentMatch{term: tc.term, snippet: tc.snippet}, tc.width, plain, lipgloss.NewStyle().Bold(true)))
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+			if w := utils.Width(got); w > int(tc.width) { //nolint:gosec
+				t.Errorf("expected at most %d columns, got %d", tc.width, w)
+			}
+		})
+	}
+}
+
+func TestFindRenderedLine(t *testing.T) {
+	rendered := "\x1b[1m  Title\x1b[0m\n\n  Some \x1b[3mNee\x1b[0mdle here\n  needle again"
+	for term, want := range map[string]int{
+		"needle": 2,
+		"TITLE":  0,
+		"hay":    -1,
+	} {
+		if got := findRenderedLine(rendered, term); got != want {
+			t.Errorf("%s: expected line %d, got %d", term, want, got)
+		}
+	}
+}
+
+func TestFilterResultsInOrder(t *testing.T) {
+	m := newStashModel(&commonModel{})
+	m.filterState = filterApplied
+	m.filterInput.SetValue("notes")
+	m.filterMarkdowns()
+	stale := m.filters
+	m.filterMarkdowns()
+
+	md := &markdown{Note: "notes.md"}
+	m, _ = m.update(filteredMarkdownMsg{id: stale, markdowns: []*markdown{md}})
+	if len(m.filteredMarkdowns) != 0 {
+		t.Errorf("expected the results of an older filter to be dropped, got %d documents", len(m.filteredMarkdowns))
+	}
+	m, _ = m.update(filteredMarkdownMsg{id: m.filters, markdowns: []*markdown{md}})
+	if len(m.filteredMarkdowns) != 1 {
+		t.Errorf("expected the results of the current filter, got %d documents", len(m.filteredMarkdowns))
+	}
+
+	if _, cmd := m.update(searchContentMsg(stale)); cmd != nil {
+		t.Error("expected no content search for an older filter")
+	}
+	if _, cmd := m.update(searchContentMsg(m.filters)); cmd == nil {
+		t.Error("expected the content search of the current filter")
+	}
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/lint"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+var splitBorderStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#DCDCDC", Dark: "#323232"}).
+	Render
+
+const splitBorderWidth = 1
+
+var splitParser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+// anchor pairs a line of a document's source with the line it's rendered
+// on.
+type anchor struct {
+	rendered, source int
+}
+
+// toggleSplit shows or hides the source of the document next to the
+// rendered document.
+func (m *pagerModel) toggleSplit() tea.Cmd {
+	m.split = !m.split
+	if m.split {
+		m.outline.shown, m.outline.focused = false, false
+	}
+	m.setSize(m.common.width, m.common.height)
+
+	var cmds []tea.Cmd
+	if m.split {
+		// The high performance renderer can only draw the viewport across
+		// the whole screen.
+		if m.viewport.HighPerformanceRendering {
+			cmds = append(cmds, tea.ClearScrollArea) //nolint:staticcheck
+		}
+		m.viewport.HighPerformanceRendering = false
+	} else {
+		m.viewport.HighPerformanceRendering = m.highPerformance()
+	}
+	return tea.Batch(append(cmds, m.render())...)
+}
+
+// setSource shows the source of the current document in the split view.
+func (m *pagerModel) setSource() {
+	body := strings.ReplaceAll(m.shownBody(), "\t", "    ")
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	for i, l := range lines {
+		// Lines are numbered as in the file, past folded sections.
+		lines[i] = lineNumberStyle(fmt.Sprintf("%*d ", lineNumberWidth, m.sourceLineOf(i)+1)) + l
+	}
+	m.source.SetContent(strings.Join(lines, "\n"))
+	m.anchors = splitAnchors(m.shownBody(), m.rendered)
+	m.syncSource()
+}
+
+// syncSource scrolls the source to the part of the document in view.
+func (m *pagerModel) syncSource() {
+	if !m.split {
+		return
+	}
+	if m.viewport.AtBottom() {
+		m.source.GotoBottom()
+		return
+	}
+	m.source.SetYOffset(sourceLine(m.anchors, m.viewport.YOffset))
+}
+
+// splitAnchors finds the lines the headings of a document start on, and the
+// lines they're rendered on. The document's start and end are anchors, too.
+func splitAnchors(source, rendered string) []anchor {
+	anchors := []anchor{{0, 0}}
+	for _, h := range documentHeadings(source, rendered) {
+		anchors = append(anchors, anchor{rendered: h.rendered, source: h.source})
+	}
+	return append(anchors, anchor{
+		rendered: strings.Count(rendered, "\n") + 1,
+		source:   strings.Count(source, "\n") + 1,
+	})
+}
+
+// docHeading is a heading of a document, with the line of the source it starts
+// on and the line it's rendered on.
+type docHeading struct {
+	level    int
+	text     string
+	rendered int
+	source   int
+}
+
+// documentHeadings finds the top-level headings of a document in its
+// rendering. Headings that can't be found are left out.
+func documentHeadings(source, rendered string) []docHeading {
+	renderedLines := strings.Split(rendered, "\n")
+	var headings []docHeading
+
+	src := []byte(source)
+	_, body := utils.SplitFrontmatter(src)
+	offset := len(src) - len(body)
+	doc := splitParser.Parse(text.NewReader(body))
+
+	next := 0
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		title := headingText(h, body)
+		needle := foldText(title)
+		if needle == "" {
+			continue
+		}
+		for i := next; i < len(renderedLines); i++ {
+			if strings.Contains(foldText(stripANSI(renderedLines[i])), needle) {
+				headings = append(headings, docHeading{
+					level:    h.Level,
+					text:     title,
+					rendered: i,
+					source:   bytes.Count(src[:offset+h.Lines().At(0).Start], []byte("\n")),
+				})
+				next = i + 1
+				break
+			}
+		}
+	}
+	return headings
+}
+
+// anchorLine returns the line the heading with the given anchor is rendered
+// on, or -1 if there's no such heading.
+func anchorLine(source, rendered, anchor string) int {
+	want := lint.Anchor(anchor)
+	anchors := lint.Anchors{}
+	for _, h := range documentHeadings(source, rendered) {
+		if anchors.Next(h.text) == want {
+			return h.rendered
+		}
+	}
+	return -1
+}
+
+// headingText returns the text of a heading.
+func headingText(h *ast.Heading, src []byte) string {
+	var b strings.Builder
+	for c := h.FirstChild(); c != nil; c = c.NextSibling() {
+		b.Write(c.Text(src)) //nolint:staticcheck
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// sourceLine returns the line of the source rendered on line y, by
+// interpolating between the anchors around it.
+func sourceLine(anchors []anchor, y int) int {
+	for i := 1; i < len(anchors); i++ {
+		a, b := anchors[i-1], anchors[i]
+		if y >= b.rendered && i < len(anchors)-1 {
+			continue
+		}
+		if b.rendered <= a.rendered {
+			return a.source
+		}
+		return a.source + (y-a.rendered)*(b.source-a.source)/(b.rendered-a.rendered)
+	}
+	return 0
+}
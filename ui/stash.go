@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -14,7 +15,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
-	"github.com/muesli/reflow/ansi"
+	"github.com/douglas-larocca/glow/v2/stash"
+	"github.com/douglas-larocca/glow/v2/utils"
 	"github.com/muesli/reflow/truncate"
 	"github.com/sahilm/fuzzy"
 )
@@ -51,8 +53,17 @@ var (
 // MSG
 
 type (
-	filteredMarkdownMsg []*markdown
-	fetchedMarkdownMsg  *markdown
+	filteredMarkdownMsg struct {
+		// The filter the documents were matched against, see
+		// stashModel.filters.
+		id        int
+		markdowns []*markdown
+		matches   map[*markdown]contentMatch
+	}
+	fetchedMarkdownMsg *markdown
+
+	// Sent once typing paused, to search the documents by content.
+	searchContentMsg int
 )
 
 // MODEL
@@ -71,6 +82,10 @@ type sectionKey int
 
 const (
 	documentsSection = iota
+	recentSection
+	bookmarksSection
+	stashedSection
+	tagsSection
 	filterSection
 )
 
@@ -116,6 +131,22 @@ func initSections() {
 			key:       documentsSection,
 			paginator: newStashPaginator(),
 		},
+		recentSection: {
+			key:       recentSection,
+			paginator: newStashPaginator(),
+		},
+		bookmarksSection: {
+			key:       bookmarksSection,
+			paginator: newStashPaginator(),
+		},
+		stashedSection: {
+			key:       stashedSection,
+			paginator: newStashPaginator(),
+		},
+		tagsSection: {
+			key:       tagsSection,
+			paginator: newStashPaginator(),
+		},
 		filterSection: {
 			key:       filterSection,
 			paginator: newStashPaginator(),
@@ -141,6 +172,7 @@ type stashModel struct {
 	err                error
 	spinner            spinner.Model
 	filterInput        textinput.Model
+	unlock             unlockState
 	viewState          stashViewState
 	filterState        filterState
 	showFullHelp       bool
@@ -166,6 +198,26 @@ type stashModel struct {
 	// reason, this field should be considered ephemeral.
 	filteredMarkdowns []*markdown
 
+	// Recently opened documents.
+	recent []*markdown
+
+	// Bookmarked documents and headings.
+	bookmarked []*markdown
+
+	// Documents in the stash.
+	stashed []*markdown
+
+	// Frontmatter tags of the local documents, see tagEntries.
+	tags []*markdown
+
+	// Lines matching the filter in documents found by a full-text search,
+	// rather than by name.
+	contentMatches map[*markdown]contentMatch
+
+	// Number of times the documents were filtered, which tells the results
+	// of the current filter from those of older ones still coming in.
+	filters int
+
 	// Page we're fetching stash items from on the server, which is different
 	// from the local pagination. Generally, the server will return more items
 	// than we can display at a time so we can paginate locally without having
@@ -208,7 +260,7 @@ func (m *stashModel) setSize(width, height int) {
 	m.common.width = width
 	m.common.height = height
 
-	m.filterInput.Width = width - stashViewHorizontalPadding*2 - ansi.PrintableRuneWidth(
+	m.filterInput.Width = width - stashViewHorizontalPadding*2 - utils.Width(
 		m.filterInput.Prompt,
 	)
 
@@ -219,6 +271,8 @@ func (m *stashModel) resetFiltering() {
 	m.filterState = unfiltered
 	m.filterInput.Reset()
 	m.filteredMarkdowns = nil
+	m.contentMatches = nil
+	m.filters++
 
 	sortMarkdowns(m.markdowns)
 
@@ -305,23 +359,75 @@ func (m *stashModel) addMarkdowns(mds ...*markdown) {
 	m.updatePagination()
 }
 
+// setSectionVisible adds or removes a section. Sections are kept in the order
+// of their keys.
+func (m *stashModel) setSectionVisible(key sectionKey, visible bool) {
+	i := slices.IndexFunc(m.sections, func(s section) bool { return s.key == key })
+
+	switch {
+	case visible && i < 0:
+		i = slices.IndexFunc(m.sections, func(s section) bool { return s.key > key })
+		if i < 0 {
+			i = len(m.sections)
+		}
+		m.sections = slices.Insert(m.sections, i, sections[key])
+		if m.sectionIndex >= i && len(m.sections) > 1 {
+			m.sectionIndex++
+		}
+	case !visible && i >= 0:
+		m.sections = slices.Delete(m.sections, i, i+1)
+		if m.sectionIndex >= i && m.sectionIndex > 0 {
+			m.sectionIndex--
+		}
+	}
+
+	m.updatePagination()
+}
+
 // Returns the markdowns that should be currently shown.
 func (m stashModel) getVisibleMarkdowns() []*markdown {
 	if m.filterState == filtering || m.currentSection().key == filterSection {
 		return m.filteredMarkdowns
 	}
+	switch m.currentSection().key { //nolint:exhaustive
+	case recentSection:
+		return m.recent
+	case bookmarksSection:
+		return m.bookmarked
+	case stashedSection:
+		return m.stashed
+	case tagsSection:
+		return m.tags
+	}
 
 	return m.markdowns
 }
 
-// Command for opening a markdown document in the pager. Note that this also
-// alters the model.
-func (m *stashModel) openMarkdown(md *markdown) tea.Cmd {
+// Command for opening a markdown document in the pager, in a new tab if
+// inTab is set. Note that this also alters the model.
+func (m *stashModel) openMarkdown(md *markdown, inTab bool) tea.Cmd {
 	m.viewState = stashStateLoadingDocument
+	md.searchTerm = md.heading
+	if cm, ok := m.contentMatches[md]; ok {
+		md.searchTerm = cm.term
+	}
 	cmd := loadLocalMarkdown(md)
+	if inTab {
+		cmd = loadLocalMarkdownInTab(md)
+	}
 	return tea.Batch(cmd, m.spinner.Tick)
 }
 
+func (m *stashModel) newStatusMessage(msg statusMessage) tea.Cmd {
+	m.showStatusMessage = true
+	m.statusMessage = msg
+	if m.statusMessageTimer != nil {
+		m.statusMessageTimer.Stop()
+	}
+	m.statusMessageTimer = time.NewTimer(statusMessageTimeout)
+	return waitForStatusMessageTimeout(stashContext, m.statusMessageTimer)
+}
+
 func (m *stashModel) hideStatusMessage() {
 	m.showStatusMessage = false
 	m.statusMessage = statusMessage{}
@@ -395,6 +501,12 @@ func newStashModel(common *commonModel) stashModel {
 		serverPage:  1,
 		sections:    s,
 	}
+	m.refreshRecent()
+	m.refreshBookmarks()
+	m.refreshStashed()
+	if common.cfg.ShowRecent && len(m.recent) > 0 {
+		m.sectionIndex = slices.IndexFunc(m.sections, func(s section) bool { return s.key == recentSection })
+	}
 
 	return m
 }
@@ -416,15 +528,36 @@ func (m stashModel) update(msg tea.Msg) (stashModel, tea.Cmd) {
 	case errMsg:
 		m.err = msg
 
+	case editorFinishedMsg:
+		if msg.err != nil {
+			log.Error("unable to open editor", "error", msg.err)
+			cmds = append(cmds, m.newStatusMessage(statusMessage{errorStatusMessage, "Couldn’t open editor"}))
+		}
+
 	case localFileSearchFinished:
 		// We're finished searching for local files
 		m.loaded = true
+		cmds = append(cmds, collectTags(m.markdowns))
+
+	case taggedMarkdownMsg:
+		m.tags = msg
+		m.setSectionVisible(tagsSection, len(m.tags) > 0)
 
 	case filteredMarkdownMsg:
-		m.filteredMarkdowns = msg
+		if msg.id != m.filters {
+			return m, nil
+		}
+		m.filteredMarkdowns = msg.markdowns
+		m.contentMatches = msg.matches
 		m.setCursor(0)
 		return m, nil
 
+	case searchContentMsg:
+		if int(msg) != m.filters {
+			return m, nil
+		}
+		return m, m.searchContents()
+
 	case spinner.TickMsg:
 		if m.shouldSpin() {
 			var cmd tea.Cmd
@@ -515,23 +648,46 @@ func (m *stashModel) handleDocumentBrowsing(msg tea.Msg) tea.Cmd {
 			m.loaded = false
 			return findLocalFiles(*m.common)
 
+		// Bookmark document
+		case "b":
+			return m.toggleBookmark()
+
+		// Stash document
+		case "s":
+			return m.stashSelected()
+
+		// Remove stashed document
+		case "x":
+			return m.removeSelectedFromStash()
+
 		// Edit document in EDITOR
 		case "e":
+			if m.common.cfg.Remote {
+				return m.newStatusMessage(statusMessage{errorStatusMessage, "Can’t edit remotely"})
+			}
 			md := m.selectedMarkdown()
+			if md == nil || md.localPath == "" || md.encrypted {
+				break
+			}
 			return openEditor(md.localPath, 0)
 
-		// Open document
-		case keyEnter:
+		// Open document, possibly in a new tab
+		case keyEnter, "t":
 			m.hideStatusMessage()
 
 			if numDocs == 0 {
 				break
 			}
 
+			// Tags list the documents carrying them.
+			md := m.selectedMarkdown()
+			if md.tag != "" {
+				return m.filterByTag(md.tag)
+			}
+
 			// Load the document from the server. We'll handle the message
 			// that comes back in the main update function.
-			md := m.selectedMarkdown()
-			cmds = append(cmds, m.openMarkdown(md))
+			cmds = append(cmds, m.openMarkdown(md, msg.String() == "t"))
 
 		// Filter your notes
 		case "/":
@@ -575,7 +731,7 @@ func (m *stashModel) handleDocumentBrowsing(msg tea.Msg) tea.Cmd {
 	// Extra paginator keystrokes
 	if key, ok := msg.(tea.KeyMsg); ok {
 		switch key.String() {
-		case "b", "u":
+		case "u":
 			m.paginator().PrevPage()
 		case "f", "d":
 			m.paginator().NextPage()
@@ -621,8 +777,9 @@ func (m *stashModel) handleFiltering(msg tea.Msg) tea.Cmd {
 			// "open" it directly
 			if len(h) == 1 {
 				m.viewState = stashStateReady
+				cmd := m.openMarkdown(h[0], false)
 				m.resetFiltering()
-				cmds = append(cmds, m.openMarkdown(h[0]))
+				cmds = append(cmds, cmd)
 				break
 			}
 
@@ -650,7 +807,7 @@ func (m *stashModel) handleFiltering(msg tea.Msg) tea.Cmd {
 
 	// If the filtering input has changed, request updated filtering
 	if newFilterVal != currentFilterVal {
-		cmds = append(cmds, filterMarkdowns(*m))
+		cmds = append(cmds, m.filterMarkdowns())
 	}
 
 	// Update pagination
@@ -684,6 +841,8 @@ func (m stashModel) view() string {
 			logoOrFilter += m.statusMessage.String()
 		} else if m.filterState == filtering {
 			logoOrFilter += m.filterInput.View()
+		} else if m.unlocking() {
+			logoOrFilter += m.unlock.input.View()
 		} else {
 			logoOrFilter += glowLogoView()
 			if m.showStatusMessage {
@@ -712,7 +871,7 @@ func (m stashModel) view() string {
 
 			// If the dot pagination is wider than the width of the window
 			// use the arabic paginator.
-			if ansi.PrintableRuneWidth(pagination) > m.common.width-stashViewHorizontalPadding {
+			if utils.Width(pagination) > m.common.width-stashViewHorizontalPadding {
 				// Copy the paginator since m.paginator() returns a pointer to
 				// the active paginator and we don't want to mutate it. In
 				// normal cases, where the paginator is not a pointer, we could
@@ -774,6 +933,18 @@ func (m stashModel) headerView() string {
 		case documentsSection:
 			s = fmt.Sprintf("%d documents", localCount)
 
+		case recentSection:
+			s = fmt.Sprintf("%d recent", len(m.recent))
+
+		case bookmarksSection:
+			s = fmt.Sprintf("%d bookmarks", len(m.bookmarked))
+
+		case stashedSection:
+			s = fmt.Sprintf("%d stashed", len(m.stashed))
+
+		case tagsSection:
+			s = fmt.Sprintf("%d tags", len(m.tags))
+
 		case filterSection:
 			s = fmt.Sprintf("%d “%s”", len(m.filteredMarkdowns), m.filterInput.Value())
 		}
@@ -807,6 +978,14 @@ func (m stashModel) populatedView() string {
 			} else {
 				f("Looking for local files...")
 			}
+		case recentSection:
+			f("No recent documents.")
+		case bookmarksSection:
+			f("No bookmarks.")
+		case stashedSection:
+			f("Nothing stashed.")
+		case tagsSection:
+			f("No tags.")
 		case filterSection:
 			return ""
 		}
@@ -845,41 +1024,116 @@ func (m stashModel) populatedView() string {
 
 func loadLocalMarkdown(md *markdown) tea.Cmd {
 	return func() tea.Msg {
+		if md.remotePath != "" {
+			return loadRepoMarkdown(md)
+		}
 		if md.localPath == "" {
 			return errMsg{errors.New("could not load file: missing path")}
 		}
 
+		if err := checkSize(md.localPath); err != nil {
+			return errMsg{err}
+		}
 		data, err := os.ReadFile(md.localPath)
 		if err != nil {
 			log.Debug("error reading local file", "error", err)
 			return errMsg{err}
 		}
+		if stash.IsEncrypted(data) {
+			return decryptMarkdown(md, data)
+		}
 		md.Body = string(data)
 		return fetchedMarkdownMsg(md)
 	}
 }
 
-func filterMarkdowns(m stashModel) tea.Cmd {
+// filterMarkdowns matches the documents against the filter by name. Their
+// contents are only searched once typing pauses, see searchContents.
+func (m *stashModel) filterMarkdowns() tea.Cmd {
+	m.filters++
+	id, query, mds := m.filters, m.filterQuery(), m.markdowns
+	cmd := func() tea.Msg {
+		msg := matchMarkdowns(mds, query, false)
+		msg.id = id
+		return msg
+	}
+	if f := parseFilter(query, time.Now()); len([]rune(f.text)) < minContentSearchLength {
+		return cmd
+	}
+	return tea.Batch(cmd, tea.Tick(contentSearchDebounce, func(time.Time) tea.Msg {
+		return searchContentMsg(id)
+	}))
+}
+
+// searchContents matches the documents against the filter by name and by
+// content. The results by name that may still come in are dropped, as they
+// would replace these.
+func (m *stashModel) searchContents() tea.Cmd {
+	m.filters++
+	id, query, mds := m.filters, m.filterQuery(), m.markdowns
 	return func() tea.Msg {
-		if m.filterInput.Value() == "" || !m.filterApplied() {
-			return filteredMarkdownMsg(m.markdowns) // return everything
-		}
+		msg := matchMarkdowns(mds, query, true)
+		msg.id = id
+		return msg
+	}
+}
+
+// filterQuery returns the filter the documents are matched against, if any.
+func (m stashModel) filterQuery() string {
+	if !m.filterApplied() {
+		return ""
+	}
+	return m.filterInput.Value()
+}
 
-		targets := []string{}
-		mds := m.markdowns
+// matchMarkdowns returns the documents matching query by name and, when
+// contents is set, the documents whose contents match after them.
+func matchMarkdowns(mds []*markdown, query string, contents bool) filteredMarkdownMsg {
+	if query == "" {
+		return filteredMarkdownMsg{markdowns: mds} // return everything
+	}
 
-		for _, t := range mds {
-			targets = append(targets, t.filterValue)
+	f := parseFilter(query, time.Now())
+	if f.narrows() {
+		mds = slices.DeleteFunc(slices.Clone(mds), func(md *markdown) bool {
+			return !f.match(md)
+		})
+		if f.text == "" {
+			return filteredMarkdownMsg{markdowns: mds}
 		}
+	}
 
-		ranks := fuzzy.Find(m.filterInput.Value(), targets)
-		sort.Stable(ranks)
+	targets := []string{}
+	for _, t := range mds {
+		targets = append(targets, t.filterValue)
+	}
 
-		filtered := []*markdown{}
-		for _, r := range ranks {
-			filtered = append(filtered, mds[r.Index])
-		}
+	ranks := fuzzy.Find(f.text, targets)
+	sort.Stable(ranks)
+
+	filtered := []*markdown{}
+	found := map[*markdown]bool{}
+	for _, r := range ranks {
+		filtered = append(filtered, mds[r.Index])
+		found[mds[r.Index]] = true
+	}
 
-		return filteredMarkdownMsg(filtered)
+	// Documents whose contents match are listed after those matching
+	// by name.
+	term := f.text
+	if !contents || len([]rune(term)) < minContentSearchLength {
+		return filteredMarkdownMsg{markdowns: filtered}
 	}
+	matches := map[*markdown]contentMatch{}
+	for _, md := range mds {
+		if found[md] || md.localPath == "" {
+			continue
+		}
+		if cm, ok := searchContent(md.localPath, term); ok {
+			filtered = append(filtered, md)
+			matches[md] = cm
+		}
+	}
+
+	return filteredMarkdownMsg{markdowns: filtered, matches: matches}
 }
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/stash"
+)
+
+// loadStash opens the stash configured in cfg. The stash is disabled if it
+// can't be read.
+func loadStash(cfg Config) *stash.Store {
+	if cfg.StashDir == "" {
+		return nil
+	}
+	s, err := stash.Open(cfg.StashDir)
+	if err != nil {
+		log.Error("unable to load stash", "error", err)
+		return nil
+	}
+	loadIdentities(cfg)
+	return s
+}
+
+// stashedMarkdowns converts the stashed documents into list entries, newest
+// first.
+func stashedMarkdowns(s *stash.Store) []*markdown {
+	if s == nil {
+		return nil
+	}
+
+	docs := s.List()
+	mds := make([]*markdown, 0, len(docs))
+	for i := len(docs) - 1; i >= 0; i-- {
+		d := docs[i]
+		note := d.Title()
+		if len(d.Tags) > 0 {
+			note += " #" + strings.Join(d.Tags, " #")
+		}
+		mds = append(mds, &markdown{
+			localPath: s.Path(d),
+			stashID:   d.ID,
+			encrypted: d.Encrypted,
+			Note:      note,
+			Modtime:   d.Created,
+		})
+	}
+	return mds
+}
+
+// refreshStashed reloads the stashed documents and shows or hides the stash
+// section accordingly.
+func (m *stashModel) refreshStashed() {
+	m.stashed = stashedMarkdowns(m.common.library)
+	m.setSectionVisible(stashedSection, len(m.stashed) > 0)
+}
+
+// stashSelected adds a copy of the selected document to the stash.
+func (m *stashModel) stashSelected() tea.Cmd {
+	md := m.selectedMarkdown()
+	if md == nil || md.stashID != "" || md.localPath == "" || m.common.library == nil {
+		return nil
+	}
+	if err := stashFile(m.common.library, md.localPath); err != nil {
+		log.Error("unable to stash document", "error", err)
+		return m.newStatusMessage(statusMessage{errorStatusMessage, "Couldn’t stash document"})
+	}
+	m.refreshStashed()
+	return m.newStatusMessage(statusMessage{normalStatusMessage, "Stashed"})
+}
+
+// removeSelectedFromStash deletes the selected document from the stash.
+func (m *stashModel) removeSelectedFromStash() tea.Cmd {
+	md := m.selectedMarkdown()
+	if md == nil || md.stashID == "" || m.common.library == nil {
+		return nil
+	}
+	if err := m.common.library.Remove(md.stashID); err != nil {
+		log.Error("unable to remove stashed document", "error", err)
+		return m.newStatusMessage(statusMessage{errorStatusMessage, "Couldn’t remove document"})
+	}
+	m.refreshStashed()
+	return m.newStatusMessage(statusMessage{subtleStatusMessage, "Removed from stash"})
+}
+
+// stashDocument adds a copy of the document in the pager to the stash.
+func (m *pagerModel) stashDocument() tea.Cmd {
+	doc := m.currentDocument
+	if doc.pasted && m.common.library != nil {
+		return m.stashPasted()
+	}
+	switch {
+	case m.common.library == nil || doc.localPath == "":
+		return m.showStatusMessage(pagerStatusMessage{"Only local files can be stashed", true})
+	case doc.stashID != "":
+		return m.showStatusMessage(pagerStatusMessage{"Already stashed", false})
+	}
+	if err := stashFile(m.common.library, doc.localPath); err != nil {
+		log.Error("unable to stash document", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t stash document", true})
+	}
+	return m.showStatusMessage(pagerStatusMessage{"Stashed", false})
+}
+
+// stashPasted adds a pasted document to the stash, which it's then read from.
+func (m *pagerModel) stashPasted() tea.Cmd {
+	d, err := m.common.library.Add("pasted.md", []byte(m.currentDocument.Body), "", nil)
+	if err != nil {
+		log.Error("unable to stash document", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t stash document", true})
+	}
+	doc := &m.currentDocument
+	doc.localPath = m.common.library.Path(d)
+	doc.stashID = d.ID
+	doc.Note = d.Title()
+	doc.pasted = false
+	return m.showStatusMessage(pagerStatusMessage{"Stashed", false})
+}
+
+func stashFile(s *stash.Store, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	_, err = s.Add(path, content, "", nil)
+	return err //nolint:wrapcheck
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/muesli/reflow/ansi"
+	"github.com/douglas-larocca/glow/v2/utils"
 )
 
 // helpEntry is a entry in a help menu containing values for a keystroke and
@@ -52,10 +52,10 @@ func (h helpColumn) render(height int) (rows []string) {
 			}
 		}
 		b.WriteString(k)
-		b.WriteString(strings.Repeat(" ", keyWidth-ansi.PrintableRuneWidth(k))) // pad keys
-		b.WriteString("  ")                                                     // gap
+		b.WriteString(strings.Repeat(" ", keyWidth-utils.Width(k))) // pad keys
+		b.WriteString("  ")                                         // gap
 		b.WriteString(v)
-		b.WriteString(strings.Repeat(" ", valWidth-ansi.PrintableRuneWidth(v))) // pad vals
+		b.WriteString(strings.Repeat(" ", valWidth-utils.Width(v))) // pad vals
 		rows = append(rows, b.String())
 	}
 
@@ -65,8 +65,8 @@ func (h helpColumn) render(height int) (rows []string) {
 // maxWidths returns the widest key and values in the column, respectively.
 func (h helpColumn) maxWidths() (maxKey int, maxVal int) {
 	for _, v := range h {
-		kw := ansi.PrintableRuneWidth(v.key)
-		vw := ansi.PrintableRuneWidth(v.val)
+		kw := utils.Width(v.key)
+		vw := utils.Width(v.val)
 		if kw > maxKey {
 			maxKey = kw
 		}
@@ -95,6 +95,7 @@ func (m stashModel) helpView() (string, int) {
 		default:
 			h = []string{"enter", "confirm", "esc", "cancel", "ctrl+j/ctrl+k ↑/↓", "choose"}
 		}
+		h = append(h, "*.md docs/*", "glob", "mtime<7d", "changed", "#tag", "tag")
 
 		return m.renderHelp(h)
 	}
@@ -109,7 +110,10 @@ func (m stashModel) helpView() (string, int) {
 	)
 
 	if numDocs > 0 && m.showFullHelp {
-		navHelp = []string{"enter", "open", "j/k ↑/↓", "choose"}
+		navHelp = []string{m.common.keys.help("open"), "open", m.common.keys.help("newTab"), "open in tab", "j/k ↑/↓", "choose"}
+		if m.currentSection().key == tagsSection {
+			navHelp = []string{m.common.keys.help("open"), "list documents", "j/k ↑/↓", "choose"}
+		}
 	}
 
 	if len(m.sections) > 1 {
@@ -126,9 +130,12 @@ func (m stashModel) helpView() (string, int) {
 
 	// If we're browsing a filtered set
 	if m.filterApplied() {
-		filterHelp = []string{"/", "edit search", "esc", "clear filter"}
+		filterHelp = []string{m.common.keys.help("search"), "edit search", "esc", "clear filter"}
 	} else {
-		filterHelp = []string{"/", "find"}
+		filterHelp = []string{m.common.keys.help("search"), "find"}
+	}
+	if m.showFullHelp {
+		filterHelp = append(filterHelp, m.common.keys.help("finder"), "go to file")
 	}
 
 	// If there are errors
@@ -137,8 +144,23 @@ func (m stashModel) helpView() (string, int) {
 	}
 
 	appHelp = append(appHelp, "r", "refresh")
-	appHelp = append(appHelp, "e", "edit")
-	appHelp = append(appHelp, "q", "quit")
+	if !m.common.cfg.Remote && m.common.cfg.Repo == nil {
+		appHelp = append(appHelp, m.common.keys.help("edit"), "edit")
+	}
+	if m.common.bookmarks != nil && numDocs > 0 {
+		appHelp = append(appHelp, "b", "bookmark")
+	}
+	if m.common.library != nil && numDocs > 0 {
+		if m.currentSection().key == stashedSection {
+			appHelp = append(appHelp, "x", "remove")
+		} else {
+			appHelp = append(appHelp, "s", "stash")
+		}
+	}
+	if m.showFullHelp {
+		appHelp = append(appHelp, "paste", "render markdown")
+	}
+	appHelp = append(appHelp, m.common.keys.help("quit"), "quit")
 
 	// Detailed help
 	if m.showFullHelp {
@@ -178,7 +200,7 @@ func (m stashModel) miniHelpView(entries ...string) string {
 
 	var (
 		truncationChar  = subtleStyle.Render("…")
-		truncationWidth = ansi.PrintableRuneWidth(truncationChar)
+		truncationWidth = utils.Width(truncationChar)
 	)
 
 	var (
@@ -187,7 +209,7 @@ func (m stashModel) miniHelpView(entries ...string) string {
 		maxWidth   = m.common.width -
 			stashViewHorizontalPadding -
 			truncationWidth -
-			ansi.PrintableRuneWidth(leftGutter)
+			utils.Width(leftGutter)
 		s = leftGutter
 	)
 
@@ -206,7 +228,7 @@ func (m stashModel) miniHelpView(entries ...string) string {
 
 		// Only this (and the following) help text items if we have the
 		// horizontal space
-		if ansi.PrintableRuneWidth(s)+ansi.PrintableRuneWidth(next) >= maxWidth {
+		if utils.Width(s)+utils.Width(next) >= maxWidth {
 			s += truncationChar
 			break
 		}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -27,6 +28,16 @@ func stashItemView(b *strings.Builder, m stashModel, index int, md *markdown) {
 		separator   = ""
 	)
 
+	if md.tag != "" {
+		date = fmt.Sprintf("%d documents", md.tagged)
+		if md.tagged == 1 {
+			date = "1 document"
+		}
+	}
+
+	match, hasMatch := m.contentMatches[md]
+	snippetWidth := max(0, m.common.width-stashViewHorizontalPadding*2-len(strconv.Itoa(match.line))-2)
+
 	isSelected := index == m.cursor()
 	isFiltering := m.filterState == filtering
 	singleFilteredItem := isFiltering && len(m.getVisibleMarkdowns()) == 1
@@ -82,6 +93,17 @@ func stashItemView(b *strings.Builder, m stashModel, index int, md *markdown) {
 		}
 	}
 
+	if hasMatch {
+		// Show where the document matched instead of when it was modified.
+		dateStyle := grayFg
+		matchStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"}).Underline(true)
+		if isSelected && !isFiltering || singleFilteredItem {
+			dateStyle = dimFuchsiaFg
+			matchStyle = lipgloss.NewStyle().Foreground(fuchsia).Underline(true)
+		}
+		date = dateStyle(fmt.Sprintf("%d: ", match.line)) + snippetView(match, uint(snippetWidth), dateStyle, matchStyle)
+	}
+
 	fmt.Fprintf(b, "%s %s%s%s%s\n", gutter, icon, separator, separator, title)
 	fmt.Fprintf(b, "%s %s", gutter, date)
 	if hasEditedBy {
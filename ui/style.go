@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/fsnotify/fsnotify"
+)
+
+// styleChangedMsg is sent when the file of a custom style, or one of the
+// style files it extends, changes.
+type styleChangedMsg struct{}
+
+// newStyleWatcher returns a watcher of the files of a custom style, or nil
+// for built-in styles.
+func newStyleWatcher(style string) *fsnotify.Watcher {
+	if len(utils.StyleFiles(style)) == 0 {
+		return nil
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Error("error creating style watcher", "error", err)
+		return nil
+	}
+	return w
+}
+
+// watchStyle waits for the files of a custom style to change. The files are
+// looked up again every time, as the styles a file extends can change too.
+// Their directories are watched, since editors often replace files rather
+// than write to them.
+func watchStyle(w *fsnotify.Watcher, style string) tea.Cmd {
+	return func() tea.Msg {
+		files := utils.StyleFiles(style)
+		for _, f := range files {
+			if err := w.Add(filepath.Dir(f)); err != nil {
+				log.Error("error watching style", "file", f, "error", err)
+			}
+		}
+		for {
+			select {
+			case event, ok := <-w.Events:
+				if !ok {
+					return nil
+				}
+				changed := slices.ContainsFunc(files, func(f string) bool {
+					return filepath.Clean(f) == filepath.Clean(event.Name)
+				})
+				if !changed || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				log.Debug("style changed", "file", event.Name, "event", event.Op)
+				return styleChangedMsg{}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return nil
+				}
+				log.Debug("style watcher error", "error", err)
+			}
+		}
+	}
+}
+
+// restyle renders the document again with its style changed on disk, where
+// the reader is. Styles that can't be read, as while they're being written,
+// are reported and the document is left as it is.
+func (m *pagerModel) restyle() tea.Cmd {
+	if _, err := utils.StyleConfig(m.common.cfg.GlamourStyle); err != nil {
+		log.Error("unable to load style", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t load the style", true})
+	}
+	if m.rendered == "" {
+		return nil
+	}
+	m.reflow = m.readingPosition()
+	return m.render()
+}
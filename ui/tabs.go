@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/muesli/reflow/truncate"
+)
+
+const tabBarHeight = 1
+
+var (
+	tabBarStyle = lipgloss.NewStyle().
+			Foreground(statusBarNoteFg).
+			Background(statusBarBg).
+			Render
+
+	activeTabBarStyle = lipgloss.NewStyle().
+				Foreground(cream).
+				Background(dullFuchsia).
+				Bold(true).
+				Render
+)
+
+// fetchedTabMsg is a document loaded to be opened in a new tab.
+type fetchedTabMsg *markdown
+
+// loadLocalMarkdownInTab loads a document to be opened in a new tab.
+func loadLocalMarkdownInTab(md *markdown) tea.Cmd {
+	load := loadLocalMarkdown(md)
+	return func() tea.Msg {
+		msg := load()
+		if md, ok := msg.(fetchedMarkdownMsg); ok {
+			return fetchedTabMsg(md)
+		}
+		return msg
+	}
+}
+
+// tabbed reports whether documents are open in tabs. Once a document is
+// opened in a new tab, leaving the pager keeps the open documents until their
+// tabs are closed.
+func (m model) tabbed() bool {
+	return len(m.tabs) > 0
+}
+
+// newTab opens a new empty tab after the current one and makes it current.
+func (m *model) newTab() tea.Cmd {
+	m.tabIDs++
+	if !m.tabbed() {
+		// The pager becomes the first tab.
+		m.pager.tab = m.tabIDs
+		m.tabs = []pagerModel{m.pager}
+		m.tab = 0
+		return m.showTabBar(true)
+	}
+
+	m.pager.state = pagerStateBrowse
+	m.tabs[m.tab] = m.pager
+	p := newPagerModel(m.common)
+	p.tab = m.tabIDs
+	p.tabbed = true
+	p.viewport.HighPerformanceRendering = false
+	m.tab++
+	m.tabs = append(m.tabs[:m.tab], append([]pagerModel{p}, m.tabs[m.tab:]...)...)
+	m.pager = p
+	return nil
+}
+
+// openTabs opens documents in tabs after the current one, to be loaded when
+// they're first shown.
+func (m *model) openTabs(paths []string, cwd string) {
+	m.newTab()
+	for _, path := range paths {
+		m.newTab()
+		m.pager.currentDocument = markdown{
+			localPath: path,
+			Note:      stripAbsolutePath(path, cwd),
+		}
+	}
+	m.tabs[m.tab] = m.pager
+	m.tab = 0
+	m.pager = m.tabs[0]
+}
+
+// switchTab makes the tab at index i, wrapping around, the current one.
+func (m *model) switchTab(i int) tea.Cmd {
+	if len(m.tabs) < 2 {
+		return nil
+	}
+	m.pager.state = pagerStateBrowse
+	m.tabs[m.tab] = m.pager
+	m.tab = (i + len(m.tabs)) % len(m.tabs)
+	m.pager = m.tabs[m.tab]
+	return m.showTab()
+}
+
+// closeTab closes the current tab and switches to the next one. Closing the
+// last tab returns to the file listing.
+func (m *model) closeTab() tea.Cmd {
+	if len(m.tabs) < 2 {
+		batch := m.unloadDocument()
+		m.tabs = nil
+		return tea.Batch(append(batch, m.showTabBar(false))...)
+	}
+
+	m.pager.unload()
+	if m.pager.watcher != nil {
+		_ = m.pager.watcher.Close()
+	}
+	m.tabs = append(m.tabs[:m.tab], m.tabs[m.tab+1:]...)
+	m.tab = min(m.tab, len(m.tabs)-1)
+	m.pager = m.tabs[m.tab]
+	return m.showTab()
+}
+
+// showTab shows the document of the current tab, rendering it again as the
+// window may have been resized since, or loading it if it was never shown.
+func (m *model) showTab() tea.Cmd {
+	m.state = stateShowDocument
+	m.pager.setSize(m.common.width, m.common.height)
+	if m.pager.currentDocument.Body == "" {
+		return loadLocalMarkdown(&m.pager.currentDocument)
+	}
+	return m.pager.render()
+}
+
+// leaveTab shows the file listing, keeping the documents open in tabs.
+func (m *model) leaveTab() tea.Cmd {
+	m.state = stateShowStash
+	m.stash.viewState = stashStateReady
+	m.pager.state = pagerStateBrowse
+	m.pager.savePosition()
+	m.tabs[m.tab] = m.pager
+	m.stash.refreshRecent()
+	m.stash.refreshBookmarks()
+	m.stash.refreshStashed()
+	if !m.stash.shouldSpin() {
+		return m.stash.spinner.Tick
+	}
+	return nil
+}
+
+// showTabBar shows or hides the tab bar above the pager.
+func (m *model) showTabBar(show bool) tea.Cmd {
+	var cmd tea.Cmd
+	if show && m.pager.viewport.HighPerformanceRendering {
+		// The high performance renderer can only draw the viewport from
+		// the top of the screen.
+		cmd = tea.ClearScrollArea //nolint:staticcheck
+	}
+	m.pager.tabbed = show
+	m.pager.viewport.HighPerformanceRendering = m.pager.highPerformance()
+	m.pager.setSize(m.common.width, m.common.height)
+	return cmd
+}
+
+// savePositions saves the reading positions of the documents in all tabs.
+func (m *model) savePositions() {
+	m.pager.savePosition()
+	for i := range m.tabs {
+		if i != m.tab {
+			m.tabs[i].savePosition()
+		}
+	}
+}
+
+// tabBarView shows the names of the documents open in tabs, the current one
+// highlighted.
+func (m model) tabBarView() string {
+	width := m.common.width / len(m.tabs)
+
+	var b strings.Builder
+	for i, p := range m.tabs {
+		if i == m.tab {
+			p = m.pager
+		}
+		name := p.currentDocument.Note
+		if p.currentDocument.localPath != "" {
+			name = filepath.Base(p.currentDocument.localPath)
+		}
+		name = truncate.StringWithTail(" "+name+" ", uint(max(0, width)), ellipsis) //nolint:gosec
+		if i == m.tab {
+			b.WriteString(activeTabBarStyle(name))
+		} else {
+			b.WriteString(tabBarStyle(name))
+		}
+	}
+	if pad := m.common.width - utils.Width(b.String()); pad > 0 {
+		b.WriteString(tabBarStyle(strings.Repeat(" ", pad)))
+	}
+	return b.String()
+}
+
+// highPerformance reports whether the pager can use the high performance
+// renderer, which can only draw the viewport across the whole screen.
+func (m pagerModel) highPerformance() bool {
+	return config.HighPerformancePager && !m.split && !m.outline.shown && !m.tabbed
+}
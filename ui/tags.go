@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/douglas-larocca/glow/v2/frontmatter"
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+// Only the start of documents is read for their frontmatter.
+const maxFrontmatterSize = 64 << 10
+
+// Tags of the documents read so far, by path, as long as they're unchanged.
+var tagCache = struct {
+	sync.Mutex
+	entries map[string]cachedTags
+}{entries: map[string]cachedTags{}}
+
+type cachedTags struct {
+	modtime time.Time
+	tags    []string
+}
+
+// documentTags returns the tags of the frontmatter of a local document.
+func documentTags(md *markdown) []string {
+	if md.localPath == "" {
+		return nil
+	}
+	tagCache.Lock()
+	cached, ok := tagCache.entries[md.localPath]
+	tagCache.Unlock()
+	if ok && cached.modtime.Equal(md.Modtime) {
+		return cached.tags
+	}
+
+	tags := readTags(md.localPath)
+	tagCache.Lock()
+	tagCache.entries[md.localPath] = cachedTags{md.Modtime, tags}
+	tagCache.Unlock()
+	return tags
+}
+
+// readTags reads the tags of the frontmatter of the file at path.
+func readTags(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close() //nolint:errcheck
+	head, err := io.ReadAll(io.LimitReader(f, maxFrontmatterSize))
+	if err != nil {
+		return nil
+	}
+	front, _ := utils.SplitFrontmatter(head)
+	return frontmatter.Tags(front)
+}
+
+// taggedMarkdownMsg has the entries of the tags section.
+type taggedMarkdownMsg []*markdown
+
+// collectTags reads the tags of local documents, and lists them as entries
+// of the tags section, the most used first.
+func collectTags(mds []*markdown) tea.Cmd {
+	mds = slices.Clone(mds)
+	return func() tea.Msg {
+		counts := map[string]int{}
+		names := map[string]string{}
+		for _, md := range mds {
+			for _, tag := range documentTags(md) {
+				// Tags differing in case are the same tag.
+				key := strings.ToLower(tag)
+				if _, ok := names[key]; !ok {
+					names[key] = tag
+				}
+				counts[key]++
+			}
+		}
+
+		entries := make([]*markdown, 0, len(counts))
+		for key, n := range counts {
+			entries = append(entries, &markdown{
+				Note:   "#" + names[key],
+				tag:    names[key],
+				tagged: n,
+			})
+		}
+		slices.SortFunc(entries, func(a, b *markdown) int {
+			return cmp.Or(b.tagged-a.tagged, strings.Compare(strings.ToLower(a.tag), strings.ToLower(b.tag)))
+		})
+		return taggedMarkdownMsg(entries)
+	}
+}
+
+// filterByTag lists the documents carrying a tag, as if they were filtered
+// by it.
+func (m *stashModel) filterByTag(tag string) tea.Cmd {
+	for _, md := range m.markdowns {
+		md.buildFilterValue()
+	}
+	m.filterInput.SetValue("#" + tag)
+	m.filterInput.Blur()
+	m.filterState = filterApplied
+	m.filteredMarkdowns = nil
+	m.contentMatches = nil
+	if m.sections[len(m.sections)-1].key != filterSection {
+		m.sections = append(m.sections, sections[filterSection])
+	}
+	m.sectionIndex = len(m.sections) - 1
+	m.paginator().Page = 0
+	m.setCursor(0)
+	m.updatePagination()
+	return m.filterMarkdowns()
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/douglas-larocca/glow/v2/tasks"
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+// taskState is the state of ticking off the tasks of the current document.
+type taskState struct {
+	// Whether tasks are being selected, and the index of the selected one.
+	selecting bool
+	cursor    int
+
+	// Lines the tasks are rendered on.
+	lines []int
+
+	// Whether writing a change to the file waits for confirmation, and
+	// whether writing to the current document was confirmed.
+	confirming, confirmed bool
+}
+
+// selectingTasks reports whether the pager takes all keys to tick off tasks.
+func (m pagerModel) selectingTasks() bool {
+	return m.tasks.selecting
+}
+
+// selectedTaskLine returns the line the selected task is rendered on, or -1.
+func (m pagerModel) selectedTaskLine() int {
+	t := m.tasks
+	if !t.selecting || t.cursor >= len(t.lines) {
+		return -1
+	}
+	return t.lines[t.cursor]
+}
+
+// taskLines finds the lines the tasks of the current document are rendered
+// on. It returns none if they can't all be found.
+func (m pagerModel) taskLines() []int {
+	cfg, err := utils.StyleConfig(m.common.cfg.GlamourStyle)
+	if err != nil {
+		return nil
+	}
+	lines := tasks.Lines(m.rendered, utils.TaskMarkers(cfg))
+	if len(lines) != len(tasks.Find(m.currentDocument.Body)) {
+		return nil
+	}
+	return lines
+}
+
+// startTasks starts selecting tasks from the first one in view.
+func (m *pagerModel) startTasks() tea.Cmd {
+	t := &m.tasks
+	t.lines = m.taskLines()
+	if len(t.lines) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No tasks in this document", true})
+	}
+	m.state = pagerStateBrowse
+	t.selecting = true
+	t.cursor = len(t.lines) - 1
+	for i, l := range t.lines {
+		if l >= m.viewport.YOffset {
+			t.cursor = i
+			break
+		}
+	}
+	return m.refreshTasks()
+}
+
+// stopTasks leaves the selection of tasks.
+func (m *pagerModel) stopTasks() tea.Cmd {
+	m.tasks.selecting = false
+	m.tasks.confirming = false
+	return m.refreshAnnotations()
+}
+
+// setTasks finds the tasks again in a new rendering of the document.
+func (m *pagerModel) setTasks() {
+	t := &m.tasks
+	t.lines = m.taskLines()
+	if len(t.lines) == 0 {
+		t.selecting = false
+		return
+	}
+	t.cursor = min(t.cursor, len(t.lines)-1)
+}
+
+// refreshTasks scrolls the selected task into view and shows it.
+func (m *pagerModel) refreshTasks() tea.Cmd {
+	if line := m.selectedTaskLine(); line < m.viewport.YOffset {
+		m.viewport.SetYOffset(line)
+	} else if line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+	return m.refreshAnnotations()
+}
+
+// updateTasks handles keys while tasks are being selected.
+func (m *pagerModel) updateTasks(msg tea.KeyMsg) tea.Cmd {
+	t := &m.tasks
+	if t.confirming {
+		t.confirming = false
+		if msg.String() != "y" {
+			return nil
+		}
+		t.confirmed = true
+		return m.toggleTask()
+	}
+
+	switch msg.String() {
+	case keyEsc, "q", "t":
+		return m.stopTasks()
+	case "down", "j":
+		t.cursor = min(len(t.lines)-1, t.cursor+1)
+	case "up", "k":
+		t.cursor = max(0, t.cursor-1)
+	case "home", "g":
+		t.cursor = 0
+	case "end", "G":
+		t.cursor = len(t.lines) - 1
+	case " ", keyEnter:
+		return m.toggleTask()
+	default:
+		return nil
+	}
+	return m.refreshTasks()
+}
+
+// toggleTask ticks the selected task off, or opens it again, in the file of
+// the current document. Writing to a document is confirmed once.
+func (m *pagerModel) toggleTask() tea.Cmd {
+	path := m.currentDocument.localPath
+	switch {
+	case m.common.cfg.Remote:
+		return m.showStatusMessage(pagerStatusMessage{"Can’t edit remotely", true})
+	case m.common.cfg.ReadOnly:
+		return m.showStatusMessage(pagerStatusMessage{"Read-only, tasks can’t be changed", true})
+	case path == "" || m.currentDocument.encrypted:
+		return m.showStatusMessage(pagerStatusMessage{"Can’t change this document", true})
+	case !m.tasks.confirmed:
+		m.tasks.confirming = true
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Error("unable to stat file", "file", path, "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t read the document", true})
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Error("unable to read file", "file", path, "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t read the document", true})
+	}
+
+	// Make sure the file still has the task shown.
+	n := m.tasks.cursor
+	shown, found := tasks.Find(m.currentDocument.Body), tasks.Find(string(b))
+	if len(shown) != len(found) || n >= len(found) || shown[n].Text != found[n].Text || shown[n].Done != found[n].Done {
+		return tea.Batch(
+			loadLocalMarkdown(&m.currentDocument),
+			m.showStatusMessage(pagerStatusMessage{"The document changed, try again", true}),
+		)
+	}
+
+	out, err := tasks.Toggle(string(b), n)
+	if err != nil {
+		log.Error("unable to toggle task", "file", path, "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t change the task", true})
+	}
+	if err := os.WriteFile(path, []byte(out), info.Mode().Perm()); err != nil {
+		log.Error("unable to write file", "file", path, "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn’t save the task", true})
+	}
+	log.Info("toggled task", "file", path, "task", found[n].Text, "done", !found[n].Done)
+
+	m.currentDocument.Body = out
+	what := "Ticked off"
+	if found[n].Done {
+		what = "Reopened"
+	}
+	return tea.Batch(
+		m.render(),
+		m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("%s “%s”", what, found[n].Text), false}),
+	)
+}
+
+// taskStatus returns what the status bar shows while selecting tasks.
+func (m pagerModel) taskStatus() string {
+	t := m.tasks
+	if t.confirming {
+		return fmt.Sprintf("Write changes to %s? y/n", filepath.Base(m.currentDocument.localPath))
+	}
+	return fmt.Sprintf("Task %d of %d • j/k select • space toggle • esc done", t.cursor+1, len(t.lines))
+}
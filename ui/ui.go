@@ -2,6 +2,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,15 +11,29 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour/styles"
-	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
-	"github.com/muesli/gitcha"
-	te "github.com/muesli/termenv"
+	"github.com/douglas-larocca/glow/v2/annotations"
+	"github.com/douglas-larocca/glow/v2/bookmarks"
+	"github.com/douglas-larocca/glow/v2/footnotes"
+	"github.com/douglas-larocca/glow/v2/frontmatter"
+	"github.com/douglas-larocca/glow/v2/history"
+	"github.com/douglas-larocca/glow/v2/images"
+	"github.com/douglas-larocca/glow/v2/latex"
+	"github.com/douglas-larocca/glow/v2/links"
+	"github.com/douglas-larocca/glow/v2/positions"
+	"github.com/douglas-larocca/glow/v2/stash"
+	"github.com/douglas-larocca/glow/v2/termprofile"
+	"github.com/douglas-larocca/glow/v2/utils"
+	"github.com/douglas-larocca/glow/v2/walker"
+	"github.com/douglas-larocca/glow/v2/wikilinks"
+	"github.com/fsnotify/fsnotify"
 )
 
 const (
 	statusMessageTimeout = time.Second * 3 // how long to show status messages like "stashed!"
 	ellipsis             = "…"
+	localFileBatchSize   = 256 // most files added to the listing per update
 )
 
 var (
@@ -29,8 +44,9 @@ var (
 	}
 )
 
-// NewProgram returns a new Tea program.
-func NewProgram(cfg Config, content string) *tea.Program {
+// NewProgram returns a new Tea program. Options are added to the defaults,
+// e.g. to run the program on another terminal.
+func NewProgram(cfg Config, content string, opts ...tea.ProgramOption) *tea.Program {
 	log.Debug(
 		"Starting glow",
 		"high_perf_pager",
@@ -39,8 +55,13 @@ func NewProgram(cfg Config, content string) *tea.Program {
 		cfg.GlamourEnabled,
 	)
 
+	if cfg.TermProfile.Name == "" {
+		cfg.TermProfile = termprofile.Xterm
+	}
 	config = cfg
-	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if cfg.TermProfile.AltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
 	if cfg.EnableMouse {
 		opts = append(opts, tea.WithMouseCellMotion())
 	}
@@ -54,14 +75,20 @@ func (e errMsg) Error() string { return e.err.Error() }
 
 type (
 	initLocalFileSearchMsg struct {
-		cwd string
-		ch  chan gitcha.SearchResult
+		cwd    string
+		ch     <-chan walker.Result
+		cancel context.CancelFunc
 	}
 )
 
 type (
-	foundLocalFileMsg       gitcha.SearchResult
-	localFileSearchFinished struct{}
+	foundLocalFilesMsg struct {
+		ch    <-chan walker.Result
+		files []walker.Result
+	}
+	localFileSearchFinished struct {
+		ch <-chan walker.Result
+	}
 	statusMessageTimeoutMsg applicationContext
 )
 
@@ -91,10 +118,47 @@ func (s state) String() string {
 
 // Common stuff we'll need to access in all models.
 type commonModel struct {
-	cfg    Config
-	cwd    string
-	width  int
-	height int
+	cfg       Config
+	cwd       string
+	width     int
+	height    int
+	bookmarks *bookmarks.Store
+	history   *history.Store
+	library   *stash.Store
+	positions *positions.Store
+	// Annotations of documents, if enabled.
+	annotations *annotations.Store
+	keys        keyMap
+	// Resolvers of wiki links, by the root directory of their pages.
+	wikiLinks map[string]*wikilinks.Resolver
+}
+
+// documentBody returns the markdown to render for a document, with its
+// frontmatter, math and links handled as configured, and the document's links
+// and footnotes. The wiki links of local documents link to the files they
+// name.
+func (c commonModel) documentBody(path string, content []byte) (string, []links.Link, []footnotes.Note) {
+	front, body := utils.SplitFrontmatter(content)
+	if !utils.IsMarkdownFile(path) {
+		return string(body), nil, nil
+	}
+	md := string(body)
+	if c.cfg.Frontmatter != "" {
+		md = frontmatter.Apply(front, md, frontmatter.Mode(c.cfg.Frontmatter))
+	}
+	if c.cfg.Math != "" {
+		md = latex.Transform(md, latex.Mode(c.cfg.Math))
+	}
+	md = c.resolveWikiLinks(path, md)
+	if path != "" && images.Mode(c.cfg.Images) != images.ModeLink {
+		md = images.Resolve(md, images.From(path))
+	}
+	md, notes := footnotes.Transform(md)
+	if c.cfg.Links == "" {
+		return md, links.Extract(md), notes
+	}
+	md, found := links.Apply(md, links.Mode(c.cfg.Links))
+	return md, found, notes
 }
 
 type model struct {
@@ -103,12 +167,24 @@ type model struct {
 	fatalErr error
 
 	// Sub-models
-	stash stashModel
-	pager pagerModel
-
-	// Channel that receives paths to local markdown files
-	// (via the github.com/muesli/gitcha package)
-	localFileFinder chan gitcha.SearchResult
+	stash  stashModel
+	pager  pagerModel
+	finder finderModel
+
+	// Pagers of the documents open in tabs, the index of the current tab,
+	// whose up to date pager is the one above, and the last tab id used.
+	tabs   []pagerModel
+	tab    int
+	tabIDs int
+
+	// Watcher of the files of a custom style, to render documents again
+	// when they change.
+	styleWatcher *fsnotify.Watcher
+
+	// Channel that receives local markdown files as they're found, and a
+	// function to stop the search
+	localFileFinder     <-chan walker.Result
+	stopLocalFileSearch context.CancelFunc
 }
 
 // unloadDocument unloads a document from the pager. Note that while this
@@ -118,6 +194,9 @@ func (m *model) unloadDocument() []tea.Cmd {
 	m.stash.viewState = stashStateReady
 	m.pager.unload()
 	m.pager.showHelp = false
+	m.stash.refreshRecent()
+	m.stash.refreshBookmarks()
+	m.stash.refreshStashed()
 
 	var batch []tea.Cmd
 	if m.pager.viewport.HighPerformanceRendering {
@@ -134,7 +213,7 @@ func newModel(cfg Config, content string) tea.Model {
 	initSections()
 
 	if cfg.GlamourStyle == styles.AutoStyle {
-		if te.HasDarkBackground() {
+		if lipgloss.HasDarkBackground() {
 			cfg.GlamourStyle = styles.DarkStyle
 		} else {
 			cfg.GlamourStyle = styles.LightStyle
@@ -142,7 +221,14 @@ func newModel(cfg Config, content string) tea.Model {
 	}
 
 	common := commonModel{
-		cfg: cfg,
+		cfg:         cfg,
+		bookmarks:   loadBookmarks(cfg),
+		history:     loadHistory(cfg),
+		library:     loadStash(cfg),
+		positions:   loadPositions(cfg),
+		annotations: loadAnnotations(cfg),
+		keys:        newKeyMap(cfg.Keys),
+		wikiLinks:   map[string]*wikilinks.Resolver{},
 	}
 
 	m := model{
@@ -150,12 +236,19 @@ func newModel(cfg Config, content string) tea.Model {
 		state:  stateShowStash,
 		pager:  newPagerModel(&common),
 		stash:  newStashModel(&common),
+		finder: newFinderModel(),
+
+		styleWatcher: newStyleWatcher(cfg.GlamourStyle),
+	}
+
+	if cfg.Repo != nil {
+		return m
 	}
 
 	path := cfg.Path
 	if path == "" && content != "" {
 		m.state = stateShowDocument
-		m.pager.currentDocument = markdown{Body: content}
+		m.pager.currentDocument = markdown{Body: content, anchor: cfg.Anchor}
 		return m
 	}
 
@@ -177,6 +270,10 @@ func newModel(cfg Config, content string) tea.Model {
 			localPath: path,
 			Note:      stripAbsolutePath(path, cwd),
 			Modtime:   info.ModTime(),
+			anchor:    cfg.Anchor,
+		}
+		if len(cfg.Tabs) > 0 {
+			m.openTabs(cfg.Tabs, cwd)
 		}
 	}
 
@@ -185,18 +282,15 @@ func newModel(cfg Config, content string) tea.Model {
 
 func (m model) Init() tea.Cmd {
 	cmds := []tea.Cmd{m.stash.spinner.Tick}
+	if m.styleWatcher != nil {
+		cmds = append(cmds, watchStyle(m.styleWatcher, m.common.cfg.GlamourStyle))
+	}
 
 	switch m.state {
 	case stateShowStash:
 		cmds = append(cmds, findLocalFiles(*m.common))
 	case stateShowDocument:
-		content, err := os.ReadFile(m.common.cfg.Path)
-		if err != nil {
-			log.Error("unable to read file", "file", m.common.cfg.Path, "error", err)
-			return func() tea.Msg { return errMsg{err} }
-		}
-		body := string(utils.RemoveFrontmatter(content))
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		cmds = append(cmds, loadLocalMarkdown(&m.pager.currentDocument))
 	}
 
 	return tea.Batch(cmds...)
@@ -210,12 +304,107 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Markdown pasted outside of text inputs is rendered.
+	if text, ok := pastedText(msg); ok && !m.typing() {
+		return m, m.openPasted(text)
+	}
+
+	// Keys go to the finder while it's open.
+	if m.finder.open {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if cmd, ok := m.updateFinder(msg); ok {
+				return m, cmd
+			}
+		case tea.MouseMsg:
+			return m, nil
+		}
+	}
+
+	// Keys go to the pager while lines are being annotated.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.annotating() && key.String() != "ctrl+c" {
+		if !m.pager.annotation.writing {
+			if key, ok = m.common.keys.translate(key, m.state); !ok {
+				return m, nil
+			}
+		}
+		cmd := m.pager.updateAnnotating(key)
+		return m, cmd
+	}
+
+	// Keys go to the pager while the file a pasted document is saved to is
+	// named.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.saving() && key.String() != "ctrl+c" {
+		cmd := m.pager.updateSaving(key)
+		return m, cmd
+	}
+
+	// Keys go to the pager while the file the document is exported to is
+	// named.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.exporting() && key.String() != "ctrl+c" {
+		cmd := m.pager.updateExporting(key)
+		return m, cmd
+	}
+
+	// Keys go to the file listing while the passphrase of an encrypted
+	// document is typed.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowStash && m.stash.unlocking() && key.String() != "ctrl+c" {
+		cmd := m.stash.updateUnlocking(key)
+		return m, cmd
+	}
+
+	// Keys go to the pager while tasks are being selected.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.selectingTasks() && key.String() != "ctrl+c" {
+		if !m.pager.tasks.confirming {
+			if key, ok = m.common.keys.translate(key, m.state); !ok {
+				return m, nil
+			}
+		}
+		cmd := m.pager.updateTasks(key)
+		return m, cmd
+	}
+
+	// The key after the fold key says what to fold.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.fold.pending && key.String() != "ctrl+c" {
+		return m, m.pager.updateFold(key)
+	}
+
+	// Map custom keys to the keys handled below, unless they're being typed
+	// into the filter.
+	if key, ok := msg.(tea.KeyMsg); ok && (m.state != stateShowStash || m.stash.filterState != filtering) {
+		if key, ok = m.common.keys.translate(key, m.state); !ok {
+			return m, nil
+		}
+		msg = key
+	}
+
+	// Keys moving between headings go to the outline while it has the focus.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && m.pager.outlineFocused() && !m.finder.open {
+		if cmd, ok := m.pager.updateOutline(key); ok {
+			return m, cmd
+		}
+	}
+
+	// The fold key waits for the key saying what to fold.
+	if key, ok := msg.(tea.KeyMsg); ok && m.state == stateShowDocument && key.String() == "z" && !m.finder.open {
+		return m, m.pager.updateFold(key)
+	}
+
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			if m.tabbed() {
+				if m.state == stateShowDocument {
+					return m, m.leaveTab()
+				}
+				if m.stash.viewState != stashStateLoadingDocument && !m.stash.filterApplied() {
+					// Back to the current tab
+					return m, m.showTab()
+				}
+			}
 			if m.state == stateShowDocument || m.stash.viewState == stashStateLoadingDocument {
 				batch := m.unloadDocument()
 				return m, tea.Batch(batch...)
@@ -228,35 +417,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.stash, cmd = m.stash.update(msg)
 					return m, cmd
 				}
+				if m.stopLocalFileSearch != nil {
+					m.stopLocalFileSearch()
+				}
 				m.stash.markdowns = nil
 				return m, m.Init()
 			}
 
 		case "q":
-			var cmd tea.Cmd
-
-			switch m.state { //nolint:exhaustive
-			case stateShowStash:
-				// pass through all keys if we're editing the filter
-				if m.stash.filterState == filtering {
-					m.stash, cmd = m.stash.update(msg)
-					return m, cmd
-				}
+			// pass through all keys if we're editing the filter
+			if m.state == stateShowStash && m.stash.filterState == filtering {
+				var cmd tea.Cmd
+				m.stash, cmd = m.stash.update(msg)
+				return m, cmd
 			}
+			m.savePositions()
 
 			return m, tea.Quit
 
 		case "left", "h", "delete":
 			if m.state == stateShowDocument {
+				if m.tabbed() {
+					return m, m.leaveTab()
+				}
 				cmds = append(cmds, m.unloadDocument()...)
 				return m, tea.Batch(cmds...)
 			}
 
+		case "tab", "shift+tab", "x":
+			if m.state == stateShowDocument && m.tabbed() {
+				switch msg.String() {
+				case "tab":
+					return m, m.switchTab(m.tab + 1)
+				case "shift+tab":
+					return m, m.switchTab(m.tab - 1)
+				default:
+					return m, m.closeTab()
+				}
+			}
+
+		case "ctrl+p":
+			if m.state == stateShowDocument || m.stash.viewState == stashStateReady && m.stash.filterState != filtering {
+				return m, m.openFinder()
+			}
+
 		case "ctrl+z":
+			// Suspending stops the whole process group, which is the
+			// server's when the TUI is served over SSH.
+			if m.common.cfg.Remote {
+				return m, nil
+			}
 			return m, tea.Suspend
 
 		// Ctrl+C always quits no matter where in the application you are.
 		case "ctrl+c":
+			m.savePositions()
 			return m, tea.Quit
 		}
 
@@ -269,19 +484,80 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case initLocalFileSearchMsg:
 		m.localFileFinder = msg.ch
+		m.stopLocalFileSearch = msg.cancel
 		m.common.cwd = msg.cwd
-		cmds = append(cmds, findNextLocalFile(m))
+		cmds = append(cmds, findNextLocalFiles(msg.ch))
 
 	case fetchedMarkdownMsg:
+		if m.tabbed() {
+			if m.state == stateShowStash {
+				// Opened from the file listing in place of the document
+				// of the current tab
+				m.pager.unload()
+			} else if msg.localPath != m.pager.currentDocument.localPath ||
+				msg.remotePath != m.pager.currentDocument.remotePath {
+				// Loaded for a tab that's no longer current
+				return m, nil
+			}
+		}
 		// We've loaded a markdown file's contents for rendering
+		m.common.addToHistory(msg)
+		m.pager.currentDocument = *msg
+		cmds = append(cmds, m.pager.render())
+
+	case lockedMsg:
+		// Encrypted documents open once their passphrase is typed.
+		if m.state == stateShowDocument {
+			cmds = append(cmds, m.unloadDocument()...)
+		}
+		cmds = append(cmds, m.stash.startUnlocking(msg.md, msg.wrong))
+
+	case fetchedTabMsg:
+		cmds = append(cmds, m.newTab())
+		m.common.addToHistory(msg)
 		m.pager.currentDocument = *msg
-		body := string(utils.RemoveFrontmatter([]byte(msg.Body)))
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		cmds = append(cmds, m.pager.render())
 
 	case contentRenderedMsg:
+		if msg.tab != m.pager.tab {
+			return m, nil
+		}
 		m.state = stateShowDocument
 
+	case moreRenderedMsg:
+		if msg.tab != m.pager.tab {
+			// Rendered for a tab since left, which keeps it.
+			for i := range m.tabs {
+				if m.tabs[i].tab == msg.tab && m.tabs[i].renders == msg.id {
+					m.tabs[i].appendRendered(msg.content)
+				}
+			}
+			return m, nil
+		}
+
+	case reloadMsg:
+		if msg.tab != m.pager.tab {
+			return m, nil
+		}
+
+	case styleChangedMsg:
+		// Documents in other tabs are rendered again when they're shown.
+		cmds = append(cmds, watchStyle(m.styleWatcher, m.common.cfg.GlamourStyle))
+		if m.state == stateShowDocument {
+			cmds = append(cmds, m.pager.restyle())
+		}
+
+	case resizeRenderMsg:
+		if msg.tab != m.pager.tab {
+			return m, nil
+		}
+
 	case localFileSearchFinished:
+		if msg.ch != m.localFileFinder {
+			// Left over from a search that was restarted
+			return m, nil
+		}
+
 		// Always pass these messages to the stash so we can keep it updated
 		// about network activity, even if the user isn't currently viewing
 		// the stash.
@@ -289,18 +565,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.stash = stashModel
 		return m, cmd
 
-	case foundLocalFileMsg:
-		newMd := localFileToMarkdown(m.common.cwd, gitcha.SearchResult(msg))
-		m.stash.addMarkdowns(newMd)
-		if m.stash.filterApplied() {
-			newMd.buildFilterValue()
+	case foundLocalFilesMsg:
+		if msg.ch != m.localFileFinder {
+			return m, nil
+		}
+		newMds := make([]*markdown, 0, len(msg.files))
+		for _, f := range msg.files {
+			var newMd *markdown
+			if m.common.cfg.Repo != nil {
+				newMd = repoFileToMarkdown(f.Path)
+			} else {
+				newMd = localFileToMarkdown(m.common.cwd, f)
+			}
+			if m.stash.filterApplied() {
+				newMd.buildFilterValue()
+			}
+			newMds = append(newMds, newMd)
 		}
+		m.stash.addMarkdowns(newMds...)
 		if m.stash.shouldUpdateFilter() {
-			cmds = append(cmds, filterMarkdowns(m.stash))
+			cmds = append(cmds, m.stash.filterMarkdowns())
 		}
-		cmds = append(cmds, findNextLocalFile(m))
+		if m.finder.open {
+			m.filterFinder()
+		}
+		cmds = append(cmds, findNextLocalFiles(msg.ch))
 
-	case filteredMarkdownMsg:
+	case filteredMarkdownMsg, searchContentMsg:
 		if m.state == stateShowDocument {
 			newStashModel, cmd := m.stash.update(msg)
 			m.stash = newStashModel
@@ -329,8 +620,15 @@ func (m model) View() string {
 		return errorView(m.fatalErr, true)
 	}
 
+	if m.finder.open {
+		return m.finderView()
+	}
+
 	switch m.state { //nolint:exhaustive
 	case stateShowDocument:
+		if m.tabbed() {
+			return m.tabBarView() + "\n" + m.pager.View()
+		}
 		return m.pager.View()
 	default:
 		return m.stash.view()
@@ -355,6 +653,9 @@ func errorView(err error, fatal bool) string {
 // COMMANDS
 
 func findLocalFiles(m commonModel) tea.Cmd {
+	if m.cfg.Repo != nil {
+		return findRepoFiles(m)
+	}
 	return func() tea.Msg {
 		log.Info("findLocalFiles")
 		var (
@@ -367,8 +668,12 @@ func findLocalFiles(m commonModel) tea.Cmd {
 		} else {
 			var info os.FileInfo
 			info, err = os.Stat(cwd)
-			if err == nil && info.IsDir() {
+			switch {
+			case err == nil && info.IsDir():
 				cwd, err = filepath.Abs(cwd)
+			case err == nil:
+				// Look for documents next to the one shown
+				cwd, err = filepath.Abs(filepath.Dir(cwd))
 			}
 		}
 
@@ -380,34 +685,50 @@ func findLocalFiles(m commonModel) tea.Cmd {
 
 		log.Debug("local directory is", "cwd", cwd)
 
-		// Switch between FindFiles and FindAllFiles to bypass .gitignore rules
-		var ch chan gitcha.SearchResult
-		if m.cfg.ShowAllFiles {
-			ch, err = gitcha.FindAllFilesExcept(cwd, markdownExtensions, nil)
-		} else {
-			ch, err = gitcha.FindFilesExcept(cwd, markdownExtensions, ignorePatterns(m))
+		// Showing all files bypasses .gitignore rules and ignore patterns
+		opts := walker.Options{Patterns: markdownExtensions}
+		if !m.cfg.ShowAllFiles {
+			opts.Ignore = ignorePatterns(m)
+			opts.GitIgnore = true
 		}
 
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := walker.Walk(ctx, cwd, opts)
 		if err != nil {
+			cancel()
 			log.Error("error finding local files", "error", err)
 			return errMsg{err}
 		}
 
-		return initLocalFileSearchMsg{ch: ch, cwd: cwd}
+		return initLocalFileSearchMsg{ch: ch, cwd: cwd, cancel: cancel}
 	}
 }
 
-func findNextLocalFile(m model) tea.Cmd {
+// findNextLocalFiles waits for the next files found by the search, and
+// passes along any others that are ready so that large trees don't cost one
+// update per file.
+func findNextLocalFiles(ch <-chan walker.Result) tea.Cmd {
 	return func() tea.Msg {
-		res, ok := <-m.localFileFinder
+		res, ok := <-ch
+		if !ok {
+			// We're done
+			log.Debug("local file search finished")
+			return localFileSearchFinished{ch}
+		}
 
-		if ok {
-			// Okay now find the next one
-			return foundLocalFileMsg(res)
+		files := []walker.Result{res}
+		for len(files) < localFileBatchSize {
+			select {
+			case res, ok := <-ch:
+				if !ok {
+					return foundLocalFilesMsg{ch, files}
+				}
+				files = append(files, res)
+			default:
+				return foundLocalFilesMsg{ch, files}
+			}
 		}
-		// We're done
-		log.Debug("local file search finished")
-		return localFileSearchFinished{}
+		return foundLocalFilesMsg{ch, files}
 	}
 }
 
@@ -420,10 +741,10 @@ func waitForStatusMessageTimeout(appCtx applicationContext, t *time.Timer) tea.C
 
 // ETC
 
-// Convert a Gitcha result to an internal representation of a markdown
+// Convert a search result to an internal representation of a markdown
 // document. Note that we could be doing things like checking if the file is
-// a directory, but we trust that gitcha has already done that.
-func localFileToMarkdown(cwd string, res gitcha.SearchResult) *markdown {
+// a directory, but we trust that the walker has already done that.
+func localFileToMarkdown(cwd string, res walker.Result) *markdown {
 	return &markdown{
 		localPath: res.Path,
 		Note:      stripAbsolutePath(res.Path, cwd),
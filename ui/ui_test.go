@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSuspend(t *testing.T) {
+	for _, remote := range []bool{false, true} {
+		m := newModel(Config{GlamourStyle: "dark", Remote: remote}, "# Hello")
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+		if remote && cmd != nil {
+			t.Errorf("expected ctrl+z to be ignored when the TUI is used remotely, got %T", cmd())
+		}
+		if !remote && cmd == nil {
+			t.Error("expected ctrl+z to suspend glow")
+		}
+	}
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"net/url"
 	"strings"
 	"sync"
 )
 
 const (
-	protoGithub = "github://"
-	protoGitlab = "gitlab://"
-	protoHTTPS  = "https://"
+	protoGithub    = "github://"
+	protoGitlab    = "gitlab://"
+	protoCodeberg  = "codeberg://"
+	protoSourcehut = "sourcehut://"
+	protoBitbucket = "bitbucket://"
+	protoHTTPS     = "https://"
 )
 
 var (
@@ -26,58 +29,50 @@ func init() {
 	})
 }
 
-func readmeURL(path string) (*source, error) {
-	switch {
-	case strings.HasPrefix(path, protoGithub):
-		if u := githubReadmeURL(path); u != nil {
-			return readmeURL(u.String())
-		}
-		return nil, nil
-	case strings.HasPrefix(path, protoGitlab):
-		if u := gitlabReadmeURL(path); u != nil {
-			return readmeURL(u.String())
-		}
+// readmeURL finds the README of the repository path points to. It returns
+// nil if path isn't the URL of a repository on one of the forges.
+func readmeURL(ctx context.Context, path string) (*source, error) {
+	f, owner, repo, ok := parseForgeURL(path)
+	if !ok {
 		return nil, nil
 	}
+	return f.findREADME(ctx, owner, repo)
+}
 
-	if !strings.HasPrefix(path, protoHTTPS) {
-		path = protoHTTPS + path
-	}
-	u, err := url.Parse(path)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse url: %w", err)
+// parseForgeURL returns the forge, owner and name of the repository path
+// points to, either a URL of the repository, with or without its protocol,
+// or a shorthand. It reports false for anything else, e.g. URLs of files.
+func parseForgeURL(path string) (f forge, owner, repo string, ok bool) {
+	var host string
+	for _, candidate := range forges {
+		if strings.HasPrefix(path, candidate.scheme()) {
+			// custom hostnames are not supported yet
+			host, path = candidate.hostname(), strings.TrimPrefix(path, candidate.scheme())
+			break
+		}
 	}
-
-	switch {
-	case u.Hostname() == githubURL.Hostname():
-		return findGitHubREADME(u)
-	case u.Hostname() == gitlabURL.Hostname():
-		return findGitLabREADME(u)
+	if host == "" {
+		if !strings.Contains(path, "://") {
+			path = protoHTTPS + path
+		}
+		u, err := url.Parse(path)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, "", "", false
+		}
+		host, path = u.Hostname(), u.Path
 	}
 
-	return nil, nil
-}
-
-func githubReadmeURL(path string) *url.URL {
-	path = strings.TrimPrefix(path, protoGithub)
-	parts := strings.Split(path, "/")
-	if len(parts) != 2 {
-		// custom hostnames are not supported yet
-		return nil
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, repo, ok = strings.Cut(path, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, "", "", false
 	}
-	u, _ := url.Parse(githubURL.String())
-	return u.JoinPath(path)
-}
-
-func gitlabReadmeURL(path string) *url.URL {
-	path = strings.TrimPrefix(path, protoGitlab)
-	parts := strings.Split(path, "/")
-	if len(parts) != 2 {
-		// custom hostnames are not supported yet
-		return nil
+	for _, f := range forges {
+		if f.hostname() == host {
+			return f, owner, repo, true
+		}
 	}
-	u, _ := url.Parse(gitlabURL.String())
-	return u.JoinPath(path)
+	return nil, "", "", false
 }
 
 func isURL(path string) bool {
@@ -1,6 +1,13 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestURLParser(t *testing.T) {
 	for path, url := range map[string]string{
@@ -15,7 +22,7 @@ func TestURLParser(t *testing.T) {
 	} {
 		t.Run(path, func(t *testing.T) {
 			t.Skip("test uses network, sometimes fails for no reason")
-			got, err := readmeURL(path)
+			got, err := readmeURL(context.Background(), path)
 			if err != nil {
 				t.Fatalf("expected no error, got %v", err)
 			}
@@ -28,3 +35,69 @@ func TestURLParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParseForgeURL(t *testing.T) {
+	for path, want := range map[string]string{
+		"github.com/charmbracelet/glow":                             "github.com charmbracelet/glow",
+		"gitlab://caarlos0/test":                                    "gitlab.com caarlos0/test",
+		"codeberg.org/forgejo/forgejo":                              "codeberg.org forgejo/forgejo",
+		"https://codeberg.org/forgejo/forgejo.git":                  "codeberg.org forgejo/forgejo",
+		"codeberg://forgejo/forgejo":                                "codeberg.org forgejo/forgejo",
+		"git.sr.ht/~sircmpwn/scdoc":                                 "git.sr.ht ~sircmpwn/scdoc",
+		"sourcehut://~sircmpwn/scdoc":                               "git.sr.ht ~sircmpwn/scdoc",
+		"https://bitbucket.org/atlassian/python-bitbucket/":         "bitbucket.org atlassian/python-bitbucket",
+		"bitbucket://atlassian/python-bitbucket":                    "bitbucket.org atlassian/python-bitbucket",
+		"codeberg.org/forgejo":                                      "",
+		"codeberg.org/forgejo/forgejo/src/branch/forgejo/README.md": "",
+		"example.com/owner/repo":                                    "",
+		"ftp://github.com/owner/repo":                               "",
+	} {
+		var got string
+		if f, owner, repo, ok := parseForgeURL(path); ok {
+			got = f.hostname() + " " + owner + "/" + repo
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestProbingForge(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/o/r/master/Readme.md" {
+			_, _ = io.WriteString(w, "# Readme")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	f := probingForge{
+		name: "Test",
+		defaultBranch: func(context.Context, string, string) (string, error) {
+			return "", errors.New("no API")
+		},
+		rawURL: func(owner, repo, branch, file string) string {
+			return srv.URL + "/" + owner + "/" + repo + "/" + branch + "/" + file
+		},
+	}
+	src, err := f.findREADME(context.Background(), "o", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.reader.Close() //nolint:errcheck
+	if src.URL != srv.URL+"/o/r/master/Readme.md" {
+		t.Errorf("unexpected README %s", src.URL)
+	}
+	// Every name is tried on main, then on master.
+	if len(requests) != len(readmeNames)+3 {
+		t.Errorf("unexpected requests %q", requests)
+	}
+
+	f.rawURL = func(owner, repo, branch, file string) string { return srv.URL + "/missing/" + file }
+	if _, err := f.findREADME(context.Background(), "o", "r"); err == nil {
+		t.Error("expected an error when there's no README")
+	}
+}
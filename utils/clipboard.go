@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// CopyToClipboard copies s to the clipboard. It asks the terminal to do so
+// with OSC 52, which also works over SSH, and writes to the system clipboard
+// as a fallback for terminals that don't support it.
+func CopyToClipboard(s string) error {
+	osc52 := false
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if term.IsTerminal(int(f.Fd())) {
+			termenv.NewOutput(f).Copy(s)
+			osc52 = true
+			break
+		}
+	}
+
+	// Without a terminal, the system clipboard is all we have.
+	if err := clipboard.WriteAll(s); err != nil && !osc52 {
+		return fmt.Errorf("unable to copy to clipboard: %w", err)
+	}
+	return nil
+}
+
+// PlainText strips the escape sequences and the padding glamour adds to
+// rendered output.
+func PlainText(rendered string) string {
+	lines := strings.Split(xansi.Strip(rendered), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
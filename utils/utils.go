@@ -2,35 +2,49 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/douglas-larocca/glow/v2/callouts"
+	"github.com/douglas-larocca/glow/v2/codethemes"
+	"github.com/douglas-larocca/glow/v2/diffs"
+	"github.com/douglas-larocca/glow/v2/images"
+	"github.com/douglas-larocca/glow/v2/passthrough"
+	"github.com/douglas-larocca/glow/v2/tables"
+	"github.com/douglas-larocca/glow/v2/tasks"
 	"github.com/mitchellh/go-homedir"
+	"github.com/muesli/termenv"
 )
 
 // RemoveFrontmatter removes the front matter header of a markdown file.
 func RemoveFrontmatter(content []byte) []byte {
-	if frontmatterBoundaries := detectFrontmatter(content); frontmatterBoundaries[0] == 0 {
-		return content[frontmatterBoundaries[1]:]
-	}
-	return content
+	_, body := SplitFrontmatter(content)
+	return body
 }
 
-var yamlPattern = regexp.MustCompile(`(?m)^---\r?\n(\s*\r?\n)?`)
-
-func detectFrontmatter(c []byte) []int {
-	if matches := yamlPattern.FindAllIndex(c, 2); len(matches) > 1 {
-		return []int{matches[0][0], matches[1][1]}
+// SplitFrontmatter splits a markdown file into its YAML front matter, without
+// the surrounding delimiters, and the rest of the document. The front matter
+// is nil if the file has none.
+func SplitFrontmatter(content []byte) (front, body []byte) {
+	if matches := yamlPattern.FindAllIndex(content, 2); len(matches) > 1 && matches[0][0] == 0 {
+		return content[matches[0][1]:matches[1][0]], content[matches[1][1]:]
 	}
-	return []int{-1, -1}
+	return nil, content
 }
 
+var yamlPattern = regexp.MustCompile(`(?m)^---\r?\n(\s*\r?\n)?`)
+
 // ExpandPath expands tilde and all environment variables from the given path.
 func ExpandPath(path string) string {
 	s, err := homedir.Expand(path)
@@ -49,6 +63,12 @@ var markdownExtensions = []string{
 	".md", ".mdown", ".mkdn", ".mkd", ".markdown",
 }
 
+// MarkdownExtensions returns the extensions of markdown files, with their
+// leading dots.
+func MarkdownExtensions() []string {
+	return slices.Clone(markdownExtensions)
+}
+
 // IsMarkdownFile returns whether the filename has a markdown extension.
 func IsMarkdownFile(filename string) bool {
 	ext := filepath.Ext(filename)
@@ -73,36 +93,26 @@ func IsMarkdownFile(filename string) bool {
 func GlamourStyle(style string, isCode bool) glamour.TermRendererOption {
 	if !isCode {
 		if style == styles.AutoStyle {
-			return glamour.WithAutoStyle()
+			// Resolve the style ourselves: glamour falls back to its no-TTY
+			// style whenever stdout isn't a terminal, even when rendering
+			// for a file.
+			styleConfig, _ := StyleConfig(style)
+			return glamour.WithStyles(styleConfig)
+		}
+		if styles.DefaultStyles[style] == nil {
+			// Style files may extend other styles, which glamour doesn't
+			// know about.
+			if styleConfig, err := StyleConfig(style); err == nil {
+				return glamour.WithStyles(styleConfig)
+			}
 		}
 		return glamour.WithStylePath(style)
 	}
 
 	// If we are rendering a pure code block, we need to modify the style to
 	// remove the indentation.
-
-	var styleConfig ansi.StyleConfig
-
-	switch style {
-	case styles.AutoStyle:
-		if lipgloss.HasDarkBackground() {
-			styleConfig = styles.DarkStyleConfig
-		} else {
-			styleConfig = styles.LightStyleConfig
-		}
-	case styles.DarkStyle:
-		styleConfig = styles.DarkStyleConfig
-	case styles.LightStyle:
-		styleConfig = styles.LightStyleConfig
-	case styles.PinkStyle:
-		styleConfig = styles.PinkStyleConfig
-	case styles.NoTTYStyle:
-		styleConfig = styles.NoTTYStyleConfig
-	case styles.DraculaStyle:
-		styleConfig = styles.DraculaStyleConfig
-	case styles.TokyoNightStyle:
-		styleConfig = styles.DraculaStyleConfig
-	default:
+	styleConfig, err := StyleConfig(style)
+	if err != nil {
 		return glamour.WithStylesFromJSONFile(style)
 	}
 
@@ -111,3 +121,264 @@ func GlamourStyle(style string, isCode bool) glamour.TermRendererOption {
 
 	return glamour.WithStyles(styleConfig)
 }
+
+// RenderOptions configure how RenderMarkdown renders the parts of a document
+// glamour doesn't.
+type RenderOptions struct {
+	// Style name or JSON path, and the width the document is rendered at.
+	Style string
+	Width int
+	// How tables wider than the document are shown. Defaults to wrapping
+	// their cells.
+	Tables tables.Mode
+	// Which fenced code blocks keep their ANSI escapes. Defaults to ```ansi
+	// fences.
+	RawANSI passthrough.Mode
+	// Chroma themes of the code blocks of some languages, overriding those
+	// set in the style file.
+	CodeThemes codethemes.Themes
+	// Further options of the renderer for the content of callouts, e.g. the
+	// color profile.
+	Glamour []glamour.TermRendererOption
+	// How images standing alone in their paragraph are shown. Defaults to
+	// boxes.
+	Images images.Mode
+	// Opens the images of the document, to show their dimensions and
+	// halftones. Images aren't opened if nil.
+	OpenImage func(src string) (io.ReadCloser, error)
+	// Whether the terminal only shows ASCII, which halftones are then drawn
+	// with.
+	ASCII bool
+}
+
+// RenderMarkdown renders markdown with r, drawing callouts as boxes, laying
+// out tables to fit the width, styling task lists and passing the escapes of
+// terminal output in ```ansi fences through. Other lines wider than the width are wrapped, see
+// FitWidth.
+func RenderMarkdown(r *glamour.TermRenderer, markdown string, opts RenderOptions) (string, error) {
+	// Code blocks with themes of their own are highlighted beforehand, and
+	// passed through like terminal output, as are the other diffs.
+	if profile := lipgloss.ColorProfile(); opts.RawANSI != passthrough.ModeOff && profile != termenv.Ascii {
+		markdown = codethemes.Highlight(markdown, CodeThemes(opts.Style, opts.CodeThemes), codethemes.Formatter(profile))
+		markdown = diffs.Highlight(markdown, DiffStyle(opts.Style), profile)
+	}
+	md, foundBlocks := passthrough.Extract(markdown, opts.RawANSI)
+	md, foundCallouts := callouts.Extract(md)
+	md, foundTables := tables.Extract(md)
+	imagesMode := opts.Images
+	if imagesMode == "" {
+		imagesMode = images.ModeBox
+	}
+	md, foundImages := images.Extract(md, imagesMode)
+	out, err := r.Render(md)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	// Tables left wide to be scrolled are added after fitting the rest.
+	out = FitWidth(out, opts.Width)
+	foundTasks := len(tasks.Find(md)) > 0
+	if len(foundBlocks)+len(foundCallouts)+len(foundTables)+len(foundImages) == 0 && !foundTasks {
+		return out, nil
+	}
+	styleConfig, err := StyleConfig(opts.Style)
+	if err != nil {
+		return "", err
+	}
+	if foundTasks {
+		out = tasks.Style(out, TaskMarkers(styleConfig))
+	}
+
+	mode := opts.Tables
+	if mode == "" {
+		mode = tables.ModeWrap
+	}
+	out = tables.Render(out, foundTables, tables.Options{
+		Width: opts.Width,
+		Mode:  mode,
+		Style: styleConfig,
+	})
+	out = passthrough.Render(out, foundBlocks, passthrough.Options{
+		Width: opts.Width,
+		Style: styleConfig,
+		Plain: lipgloss.ColorProfile() == termenv.Ascii,
+	})
+	out = images.Render(out, foundImages, images.Options{
+		Width: opts.Width,
+		Mode:  imagesMode,
+		Style: styleConfig,
+		Open:  opts.OpenImage,
+		Color: lipgloss.ColorProfile() != termenv.Ascii && !opts.ASCII,
+	})
+	return callouts.Render(out, foundCallouts, callouts.Options{ //nolint:wrapcheck
+		Width:   opts.Width,
+		Style:   styleConfig,
+		Glamour: opts.Glamour,
+		Styles:  CalloutStyles(opts.Style),
+	})
+}
+
+// TaskMarkers returns the checkboxes a style renders tasks with.
+func TaskMarkers(cfg ansi.StyleConfig) tasks.Markers {
+	return tasks.Markers{Done: cfg.Task.Ticked, Open: cfg.Task.Unticked}
+}
+
+// CalloutStyles returns the styles of callouts set in a custom style file,
+// and the style files it extends.
+func CalloutStyles(style string) map[string]callouts.Style {
+	if style == styles.AutoStyle || styles.DefaultStyles[style] != nil {
+		return nil
+	}
+	_, _, layers, err := styleLayers(style)
+	if err != nil {
+		return nil
+	}
+	var merged map[string]callouts.Style
+	for _, b := range layers {
+		s, err := callouts.ParseStyles(b)
+		if err != nil {
+			return nil
+		}
+		if merged == nil {
+			merged = s
+			continue
+		}
+		maps.Copy(merged, s)
+	}
+	return merged
+}
+
+// DiffStyle returns the style of diffs, changed by a custom style file and
+// the style files it extends.
+func DiffStyle(style string) diffs.Style {
+	if style == styles.AutoStyle || styles.DefaultStyles[style] != nil {
+		return diffs.DefaultStyle
+	}
+	_, _, layers, err := styleLayers(style)
+	if err != nil {
+		return diffs.DefaultStyle
+	}
+	merged := diffs.DefaultStyle
+	for _, b := range layers {
+		if merged, err = diffs.ParseStyle(merged, b); err != nil {
+			return diffs.DefaultStyle
+		}
+	}
+	return merged
+}
+
+// CodeThemes returns the chroma themes of the code blocks of some languages,
+// set in a custom style file under a "code_themes" key, and overridden by
+// themes.
+func CodeThemes(style string, themes codethemes.Themes) codethemes.Themes {
+	if style == styles.AutoStyle || styles.DefaultStyles[style] != nil {
+		return themes
+	}
+	_, _, layers, err := styleLayers(style)
+	if err != nil {
+		return themes
+	}
+	var merged codethemes.Themes
+	for _, b := range layers {
+		var cfg struct {
+			CodeThemes codethemes.Themes `json:"code_themes"`
+		}
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return themes
+		}
+		merged = merged.Merge(cfg.CodeThemes)
+	}
+	return merged.Merge(themes)
+}
+
+// maxStyleDepth is how many style files can extend each other in a chain.
+const maxStyleDepth = 8
+
+// styleLayers reads a JSON style file and the style files it extends, with
+// an "extends" key naming a built-in style or the path of another file,
+// relative to the extending one. It returns the built-in style at the root of
+// the chain, if any, and the paths and content of the files, the one extended
+// by all others first.
+func styleLayers(style string) (base string, paths []string, layers [][]byte, err error) {
+	path := ExpandPath(style)
+	for range maxStyleDepth {
+		paths = append([]string{path}, paths...)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", paths, nil, fmt.Errorf("unable to read style: %w", err)
+		}
+		var header struct {
+			Extends string `json:"extends"`
+		}
+		if err := json.Unmarshal(b, &header); err != nil {
+			return "", paths, nil, fmt.Errorf("unable to parse style: %w", err)
+		}
+		layers = append([][]byte{b}, layers...)
+
+		switch extends := header.Extends; {
+		case extends == "":
+			return "", paths, layers, nil
+		case extends == styles.AutoStyle || styles.DefaultStyles[extends] != nil:
+			return extends, paths, layers, nil
+		default:
+			extends = ExpandPath(extends)
+			if !filepath.IsAbs(extends) {
+				extends = filepath.Join(filepath.Dir(path), extends)
+			}
+			path = extends
+		}
+	}
+	return "", paths, nil, fmt.Errorf("style %s extends more than %d styles", style, maxStyleDepth)
+}
+
+// StyleFiles returns the paths of a style file and of the style files it
+// extends, or nothing for built-in styles. Files that can't be read or parsed
+// are included, so they can be watched until they're fixed.
+func StyleFiles(style string) []string {
+	if style == styles.AutoStyle || styles.DefaultStyles[style] != nil {
+		return nil
+	}
+	_, paths, _, _ := styleLayers(style)
+	return paths
+}
+
+// StyleConfig returns the style configuration for a built-in style name or
+// a path to a JSON style file. The auto style resolves to the dark or light
+// style depending on the terminal background. Style files can extend another
+// style, and only set the elements they change, see styleLayers.
+func StyleConfig(style string) (ansi.StyleConfig, error) {
+	if style == styles.AutoStyle {
+		if lipgloss.HasDarkBackground() {
+			return styles.DarkStyleConfig, nil
+		}
+		return styles.LightStyleConfig, nil
+	}
+	if s, ok := styles.DefaultStyles[style]; ok {
+		return *s, nil
+	}
+
+	var styleConfig ansi.StyleConfig
+	base, _, layers, err := styleLayers(style)
+	if err != nil {
+		return styleConfig, err
+	}
+	if base != "" {
+		// Copy the built-in style, whose elements are shared pointers, so
+		// the files can be merged into it.
+		baseConfig, _ := StyleConfig(base)
+		b, err := json.Marshal(baseConfig)
+		if err != nil {
+			return styleConfig, fmt.Errorf("unable to copy style: %w", err)
+		}
+		if err := json.Unmarshal(b, &styleConfig); err != nil {
+			return styleConfig, fmt.Errorf("unable to copy style: %w", err)
+		}
+	}
+	// Elements set in a file override those of the style it extends, down to
+	// single attributes like colors.
+	for _, b := range layers {
+		if err := json.Unmarshal(b, &styleConfig); err != nil {
+			return styleConfig, fmt.Errorf("unable to parse style: %w", err)
+		}
+	}
+	return styleConfig, nil
+}
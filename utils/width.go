@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// Width returns the number of terminal cells s occupies. ANSI escape
+// sequences are ignored and grapheme clusters, such as emoji with modifiers
+// or East Asian wide characters, are measured as the terminal displays them
+// rather than by bytes or runes.
+func Width(s string) int {
+	return xansi.StringWidth(s)
+}
+
+// hangingIndent matches the margin of a rendered line, including a list
+// marker or block quote bar.
+var hangingIndent = regexp.MustCompile(`^ *(?:(?:[•│>*-]|\d+\.) +)?`)
+
+// FitWidth re-wraps rendered lines that are wider than width. Glamour only
+// breaks lines at spaces, so paragraphs of CJK text, which have none, end up
+// as a single line that the terminal would wrap without the margin. Lines
+// that fit are left alone; the others are broken at spaces where possible and
+// between grapheme clusters otherwise, keeping their indentation.
+func FitWidth(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if Width(line) <= width {
+			out = append(out, line)
+			continue
+		}
+
+		// Keep the margin and list markers on the first line and indent the
+		// continuation lines past them, but don't let that eat the whole line.
+		// Block quotes repeat their bar.
+		margin := hangingIndent.FindString(xansi.Strip(line))
+		indent := min(Width(margin), width/2)
+		prefix := xansi.Truncate(line, indent, "")
+		body := xansi.TruncateLeft(line, indent, "")
+		for _, l := range strings.Split(xansi.Wrap(body, width-indent, ""), "\n") {
+			out = append(out, prefix+strings.TrimRight(l, " "))
+			if !strings.Contains(margin, "│") {
+				prefix = strings.Repeat(" ", indent)
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// Hardwrap breaks s into lines of at most width cells, continuing from
+// column col of the current line. It returns the wrapped text and the column
+// the last line ends at, so text written in several parts wraps the same way
+// as if written at once.
+func Hardwrap(s string, width, col int) (string, int) {
+	if width <= 0 {
+		return s, col
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			col = 0
+		}
+		if col >= width && line != "" {
+			line = "\n" + line
+			col = 0
+		}
+		// Pad the first line so it's broken where the cursor will reach the
+		// edge, then drop the padding again.
+		wrapped := xansi.Hardwrap(strings.Repeat(" ", col)+line, width, true)[col:]
+		lines[i] = wrapped
+		last := wrapped[strings.LastIndexByte(wrapped, '\n')+1:]
+		if strings.Contains(wrapped, "\n") {
+			col = Width(last)
+		} else {
+			col += Width(last)
+		}
+	}
+	return strings.Join(lines, "\n"), col
+}
+
+// Indent moves rendered lines right by n cells. Empty lines are left empty.
+func Indent(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
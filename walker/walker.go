@@ -0,0 +1,244 @@
+// Package walker finds files in a directory tree using a pool of workers,
+// streaming results as they're found.
+package walker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/muesli/gitcha"
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// Result is a file that matched one of the patterns.
+type Result struct {
+	Path string
+	Info os.FileInfo
+}
+
+// Options configure a walk.
+type Options struct {
+	// File name patterns to look for, matched case-insensitively.
+	Patterns []string
+
+	// Paths to skip. Patterns without a path separator are matched against
+	// the names of entries in every directory.
+	Ignore []string
+
+	// Whether to respect the .gitignore of the repositories walked.
+	GitIgnore bool
+
+	// Number of directories read concurrently. Defaults to the number of
+	// CPUs.
+	Workers int
+}
+
+// gitRepo is the repository a directory belongs to.
+type gitRepo struct {
+	root   string
+	ignore *ignore.GitIgnore
+}
+
+type walker struct {
+	ctx     context.Context
+	opts    Options
+	results chan Result
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []dirJob
+	pending int // directories queued or being read
+}
+
+type dirJob struct {
+	path string
+	repo *gitRepo
+}
+
+// Walk finds the files matching opts.Patterns below root. Results are sent on
+// the returned channel in no particular order; it's closed once the walk is
+// complete or ctx is cancelled.
+func Walk(ctx context.Context, root string, opts Options) (<-chan Result, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	if !info.IsDir() {
+		return nil, errors.New("not a directory: " + root)
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	w := &walker{
+		ctx:     ctx,
+		opts:    opts,
+		results: make(chan Result, opts.Workers),
+	}
+	w.cond = sync.NewCond(&w.mu)
+
+	var repo *gitRepo
+	if opts.GitIgnore {
+		if dir, _ := gitcha.GitRepoForPath(root); dir != "" {
+			repo = loadRepo(dir)
+		}
+	}
+	w.push(dirJob{path: root, repo: repo})
+
+	// Wake up idle workers when the walk is cancelled.
+	stop := context.AfterFunc(ctx, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.cond.Broadcast()
+	})
+
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := w.pop()
+				if !ok {
+					return
+				}
+				w.readDir(job)
+				w.done()
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		stop()
+		close(w.results)
+	}()
+
+	return w.results, nil
+}
+
+func loadRepo(dir string) *gitRepo {
+	gi, err := ignore.CompileIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		gi = nil
+	}
+	return &gitRepo{root: dir, ignore: gi}
+}
+
+func (w *walker) push(job dirJob) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queue = append(w.queue, job)
+	w.pending++
+	w.cond.Signal()
+}
+
+// pop waits for a directory to read. It returns false once there's no work
+// left or the walk was cancelled.
+func (w *walker) pop() (dirJob, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) == 0 && w.pending > 0 && w.ctx.Err() == nil {
+		w.cond.Wait()
+	}
+	if len(w.queue) == 0 || w.ctx.Err() != nil {
+		return dirJob{}, false
+	}
+	job := w.queue[len(w.queue)-1]
+	w.queue = w.queue[:len(w.queue)-1]
+	return job, true
+}
+
+func (w *walker) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending--; w.pending == 0 {
+		w.cond.Broadcast()
+	}
+}
+
+func (w *walker) readDir(job dirJob) {
+	entries, err := os.ReadDir(job.path)
+	if err != nil {
+		return
+	}
+
+	repo := job.repo
+	if w.opts.GitIgnore {
+		for _, e := range entries {
+			if e.Name() == ".git" && e.IsDir() {
+				repo = loadRepo(job.path)
+				break
+			}
+		}
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(job.path, e.Name())
+		if w.ignored(path, e.IsDir(), repo) {
+			continue
+		}
+		if e.IsDir() {
+			w.push(dirJob{path: path, repo: repo})
+			continue
+		}
+		if !w.matches(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		select {
+		case w.results <- Result{Path: path, Info: info}:
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *walker) ignored(path string, isDir bool, repo *gitRepo) bool {
+	if repo != nil && repo.ignore != nil && path != repo.root {
+		rel := strings.TrimPrefix(path, repo.root)
+		if isDir {
+			rel += string(os.PathSeparator)
+		}
+		if repo.ignore.MatchesPath(rel) {
+			return true
+		}
+	}
+
+	for _, pattern := range w.opts.Ignore {
+		if pattern == "" {
+			continue
+		}
+		if !strings.Contains(pattern, string(os.PathSeparator)) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *walker) matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range w.opts.Patterns {
+		if matched, _ := filepath.Match(strings.ToLower(p), name); matched {
+			return true
+		}
+	}
+	return false
+}
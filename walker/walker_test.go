@@ -0,0 +1,88 @@
+package walker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"README.md",
+		"main.go",
+		"docs/GUIDE.MD",
+		"docs/deep/nested/notes.markdown",
+		"build/out.md",
+		"node_modules/pkg/README.md",
+		"repo/.git/HEAD",
+		"repo/.gitignore",
+		"repo/keep.md",
+		"repo/skip.md",
+	} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "repo/.gitignore"), []byte("skip.md\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := Walk(context.Background(), root, Options{
+		Patterns:  []string{"*.md", "*.markdown"},
+		Ignore:    []string{"node_modules", filepath.Join(root, "build")},
+		GitIgnore: true,
+		Workers:   3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	for res := range ch {
+		rel, _ := filepath.Rel(root, res.Path)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	slices.Sort(found)
+
+	expected := []string{
+		"README.md",
+		"docs/GUIDE.MD",
+		"docs/deep/nested/notes.markdown",
+		"repo/keep.md",
+	}
+	if !slices.Equal(found, expected) {
+		t.Errorf("expected %v, got %v", expected, found)
+	}
+}
+
+func TestWalkCancel(t *testing.T) {
+	root := t.TempDir()
+	for i := range 50 {
+		dir := filepath.Join(root, string(rune('a'+i%26)), string(rune('a'+i/26)))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "doc.md"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := Walk(ctx, root, Options{Patterns: []string{"*.md"}, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	cancel()
+
+	// The walk stops and closes the channel.
+	for range ch {
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/douglas-larocca/glow/v2/utils"
+)
+
+func TestRenderWideCharacters(t *testing.T) {
+	width = 40
+	src := &source{URL: "doc.md"}
+	r, _, err := setupRenderer(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte(strings.Repeat("日本語のテキストは空白なしで続きます。", 5) + "\n\n👩‍💻 emoji 🇯🇵\n")
+	out, err := renderContentIncremental(r, src, content, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if w := utils.Width(line); w > 40 {
+			t.Errorf("line is %d cells wide: %q", w, line)
+		}
+	}
+	if !strings.Contains(out, "👩‍💻") {
+		t.Error("expected the emoji sequence to be kept intact")
+	}
+}
+
+func TestHardwrapContinues(t *testing.T) {
+	tt := []struct {
+		parts []string
+		want  string
+	}{
+		{[]string{"漢字漢字漢"}, "漢字漢字\n漢"},
+		{[]string{"ab", "漢字漢"}, "ab漢字漢"},
+		{[]string{"abc", "漢字漢"}, "abc漢字\n漢"},
+		{[]string{"abcdefgh", "\nxy"}, "abcdefgh\nxy"},
+	}
+
+	for _, tc := range tt {
+		var got strings.Builder
+		col := 0
+		for _, p := range tc.parts {
+			var s string
+			s, col = utils.Hardwrap(p, 8, col)
+			got.WriteString(s)
+		}
+		if got.String() != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.parts, tc.want, got.String())
+		}
+	}
+}
+
+func TestWrapWidth(t *testing.T) {
+	defer func(mw, m, w uint, tw int, c bool) {
+		maxWidth, margin, width, termWidth, center = mw, m, w, tw, c
+	}(maxWidth, margin, width, termWidth, center)
+
+	for _, tc := range []struct {
+		maxWidth, margin uint
+		cols             int
+		want             uint
+	}{
+		{120, 0, 80, 80},
+		{120, 0, 200, 120},
+		{0, 0, 200, 200},
+		{120, 4, 80, 76},
+		{100, 10, 300, 100},
+		{120, 10, 5, 0},
+	} {
+		maxWidth, margin = tc.maxWidth, tc.margin
+		if got := wrapWidth(tc.cols); got != tc.want {
+			t.Errorf("max width %d, margin %d, %d columns: expected %d, got %d", tc.maxWidth, tc.margin, tc.cols, tc.want, got)
+		}
+	}
+
+	margin, width, termWidth = 4, 100, 160
+	if got := leftMargin(); got != 4 {
+		t.Errorf("expected the margin, got %d", got)
+	}
+	center = true
+	if got := leftMargin(); got != 30 {
+		t.Errorf("expected the document centered, got a margin of %d", got)
+	}
+}
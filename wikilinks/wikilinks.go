@@ -0,0 +1,229 @@
+// Package wikilinks turns the [[Page Name]] links of wikis and Obsidian
+// vaults into markdown links to the files they name.
+package wikilinks
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/douglas-larocca/glow/v2/lint"
+)
+
+var (
+	// [[Page]], [[Page|Text]], [[Page#Heading]] and ![[image.png]].
+	linkPattern  = regexp.MustCompile(`(!?)\[\[([^\[\]|#\n]*)(?:#([^\[\]|\n]*))?(?:\|([^\[\]\n]*))?\]\]`)
+	fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	codePattern  = regexp.MustCompile("`+")
+)
+
+// Markers of the root of a vault, or of a repository of documents.
+var rootMarkers = []string{".obsidian", ".git"}
+
+// Transform replaces the wiki links of a document, outside of code, with
+// markdown links. resolve returns the URL of the file a link names, or "" if
+// there's none; links to files that don't exist are left as text.
+func Transform(md string, resolve func(page string) string) string {
+	if !strings.Contains(md, "[[") {
+		return md
+	}
+	lines := strings.SplitAfter(md, "\n")
+	fence := ""
+	for i, line := range lines {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence) && strings.TrimSpace(line) == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence == "" && strings.Contains(line, "[[") {
+			lines[i] = transformLine(line, resolve)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// transformLine replaces the wiki links of a line outside of code spans.
+func transformLine(line string, resolve func(string) string) string {
+	var b strings.Builder
+	for line != "" {
+		// Code spans end at the next run of as many backticks.
+		loc := codePattern.FindStringIndex(line)
+		if loc == nil {
+			b.WriteString(replace(line, resolve))
+			break
+		}
+		b.WriteString(replace(line[:loc[0]], resolve))
+		ticks := line[loc[0]:loc[1]]
+		rest := line[loc[1]:]
+		end := strings.Index(rest, ticks)
+		if end < 0 {
+			b.WriteString(ticks)
+			line = rest
+			continue
+		}
+		b.WriteString(ticks + rest[:end+len(ticks)])
+		line = rest[end+len(ticks):]
+	}
+	return b.String()
+}
+
+func replace(s string, resolve func(string) string) string {
+	return linkPattern.ReplaceAllStringFunc(s, func(link string) string {
+		m := linkPattern.FindStringSubmatch(link)
+		embed, page, heading, text := m[1] != "", strings.TrimSpace(m[2]), strings.TrimSpace(m[3]), strings.TrimSpace(m[4])
+		if text == "" {
+			text = page
+			if heading != "" {
+				text = strings.TrimSpace(page + " › " + heading)
+			}
+		}
+		text = escape(text)
+
+		target := ""
+		if page != "" {
+			if target = resolve(page); target == "" {
+				return text
+			}
+		}
+		if heading != "" {
+			target += "#" + lint.Anchor(heading)
+		}
+		if embed && isImage(page) {
+			return "![" + text + "](<" + target + ">)"
+		}
+		return "[" + text + "](<" + target + ">)"
+	})
+}
+
+// escape escapes the characters of link text markdown would read as markup.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+		return true
+	}
+	return false
+}
+
+// Root returns the directory the pages of a document in dir are looked up
+// in: the closest one above it that's an Obsidian vault or a git repository,
+// or dir itself.
+func Root(dir string) string {
+	for d := dir; ; {
+		for _, marker := range rootMarkers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// Resolver finds the files named by wiki links below a root directory. The
+// tree is read once, the first time a link is resolved.
+type Resolver struct {
+	root  string
+	once  sync.Once
+	files map[string][]string // by lower case name, without .md
+}
+
+// NewResolver returns a resolver of the wiki links of documents below root.
+func NewResolver(root string) *Resolver {
+	return &Resolver{root: root}
+}
+
+// key returns the name pages are looked up by: the lower case name of a file,
+// without the extension of markdown files.
+func key(name string) string {
+	name = strings.ToLower(filepath.ToSlash(name))
+	if ext := path.Ext(name); ext == ".md" || ext == ".markdown" {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+func (r *Resolver) index() {
+	r.files = map[string][]string{}
+	_ = filepath.WalkDir(r.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if d.IsDir() {
+			if p != r.root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		k := key(d.Name())
+		r.files[k] = append(r.files[k], p)
+		return nil
+	})
+}
+
+// Resolve returns the path of the file a wiki link of a document in dir
+// names, or "" if there's none. Pages are found by name anywhere below the
+// root, or by their path from it; of several files of the same name, the
+// one closest to dir wins.
+func (r *Resolver) Resolve(page, dir string) string {
+	r.once.Do(r.index)
+
+	k := key(page)
+	candidates := r.files[path.Base(k)]
+	if strings.Contains(k, "/") {
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(p string) bool {
+			rel, err := filepath.Rel(r.root, p)
+			return err != nil || key(rel) != strings.TrimPrefix(k, "/")
+		})
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return slices.MinFunc(candidates, func(a, b string) int {
+		return distance(dir, a) - distance(dir, b)
+	})
+}
+
+// distance counts the directories between dir and the file at p.
+func distance(dir, p string) int {
+	rel, err := filepath.Rel(dir, filepath.Dir(p))
+	if err != nil {
+		return 1 << 20
+	}
+	if rel == "." {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// Link returns a function resolving the wiki links of a document in dir to
+// URLs relative to dir, for Transform.
+func (r *Resolver) Link(dir string) func(string) string {
+	return func(page string) string {
+		p := r.Resolve(page, dir)
+		if p == "" {
+			return ""
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return ""
+		}
+		return (&url.URL{Path: filepath.ToSlash(rel)}).EscapedPath()
+	}
+}
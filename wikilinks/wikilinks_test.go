@@ -0,0 +1,74 @@
+package wikilinks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	pages := map[string]string{
+		"Page Name": "Page%20Name.md",
+		"other":     "sub/other.md",
+		"logo.png":  "img/logo.png",
+	}
+	resolve := func(page string) string { return pages[page] }
+
+	for src, want := range map[string]string{
+		"See [[Page Name]].":                 "See [Page Name](<Page%20Name.md>).",
+		"[[other|the other one]]":            "[the other one](<sub/other.md>)",
+		"[[other#Some Heading]]":             "[other › Some Heading](<sub/other.md#some-heading>)",
+		"[[#Local heading]]":                 "[› Local heading](<#local-heading>)",
+		"![[logo.png]]":                      "![logo.png](<img/logo.png>)",
+		"[[Missing]] stays text":             "Missing stays text",
+		"`[[Page Name]]` and [[other]]":      "`[[Page Name]]` and [other](<sub/other.md>)",
+		"```\n[[Page Name]]\n```\n[[other]]": "```\n[[Page Name]]\n```\n[other](<sub/other.md>)",
+		"[[snake_case]]":                     "snake\\_case",
+	} {
+		if got := Transform(src, resolve); got != want {
+			t.Errorf("%q: expected %q, got %q", src, want, got)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{".obsidian/app.json", "Home.md", "notes/Ideas.md", "archive/Ideas.md", "archive/old/Ideas.md", "img/logo.png", ".trash/Gone.md"} {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := Root(filepath.Join(root, "notes")); got != root {
+		t.Errorf("expected the vault as the root, got %s", got)
+	}
+
+	r := NewResolver(root)
+	for _, tc := range []struct {
+		page, dir, want string
+	}{
+		{"home", root, "Home.md"},
+		{"Ideas", filepath.Join(root, "archive"), "archive/Ideas.md"},
+		{"Ideas", filepath.Join(root, "notes"), "notes/Ideas.md"},
+		{"archive/old/Ideas", root, "archive/old/Ideas.md"},
+		{"Ideas.md", filepath.Join(root, "archive", "old"), "archive/old/Ideas.md"},
+		{"logo.png", root, "img/logo.png"},
+		{"Gone", root, ""},
+		{"notes/Home", root, ""},
+	} {
+		want := tc.want
+		if want != "" {
+			want = filepath.Join(root, filepath.FromSlash(want))
+		}
+		if got := r.Resolve(tc.page, tc.dir); got != want {
+			t.Errorf("%s from %s: expected %q, got %q", tc.page, tc.dir, want, got)
+		}
+	}
+
+	if got := r.Link(filepath.Join(root, "notes"))("Home"); got != "../Home.md" {
+		t.Errorf("expected a link relative to the document, got %q", got)
+	}
+}